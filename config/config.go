@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -45,6 +46,18 @@ type SSIServiceConfig struct {
 	Services ServicesConfig `toml:"services"`
 }
 
+// FeatureFlags returns the state of the optional behaviors that can be toggled through config, keyed by name.
+func (c SSIServiceConfig) FeatureFlags() map[string]bool {
+	return map[string]bool{
+		"schemaCaching":        c.Server.EnableSchemaCaching,
+		"allowAllCORS":         c.Server.EnableAllowAllCORS,
+		"tracing":              c.Server.JagerEnabled,
+		"keyStoreEncryption":   c.Services.KeyStoreConfig.EncryptionEnabled(),
+		"appLevelEncryption":   c.Services.AppLevelEncryptionConfiguration.EncryptionEnabled(),
+		"customStatusEndpoint": c.Services.StatusEndpoint != "",
+	}
+}
+
 // ServerConfig represents configurable properties for the HTTP server
 type ServerConfig struct {
 	Environment         Environment   `toml:"env" conf:"default:dev"`
@@ -58,6 +71,74 @@ type ServerConfig struct {
 	LogLevel            string        `toml:"log_level" conf:"default:debug"`
 	EnableSchemaCaching bool          `toml:"enable_schema_caching" conf:"default:true"`
 	EnableAllowAllCORS  bool          `toml:"enable_allow_all_cors" conf:"default:false"`
+
+	// IssuerAPIKeys restricts the issuers callers may act as when creating, updating the status of, and deleting
+	// credentials.
+	// When empty, any caller may act as any issuer.
+	IssuerAPIKeys []IssuerAPIKeyConfig `toml:"issuer_api_keys"`
+
+	// PublicCredentialStatus configures the unauthenticated lookup of whether a credential is revoked or suspended.
+	PublicCredentialStatus PublicCredentialStatusConfig `toml:"public_credential_status"`
+
+	// Streaming bounds the NDJSON streams of the credentials, DIDs, and schemas list endpoints, which requests with an
+	// `Accept: application/x-ndjson` header get.
+	Streaming StreamingConfig `toml:"streaming"`
+
+	// MessageCatalogsDir is a directory of message catalogs, such as `de.json`, which add languages to the localized
+	// messages of error responses, or reword the embedded ones.
+	MessageCatalogsDir string `toml:"message_catalogs_dir"`
+}
+
+// PublicCredentialStatusConfig configures `GET /v1/credentials/{id}/status/public`, which lets the subject of a
+// credential, or anyone else who knows its ID, check whether it is revoked or suspended without authenticating.
+type PublicCredentialStatusConfig struct {
+	// Enabled registers the route. It is exempt from the global authentication of AUTH_TOKEN.
+	Enabled bool `toml:"enabled" conf:"default:false"`
+	// MaxAge is how long clients and shared caches may reuse a response, as set in its Cache-Control header.
+	MaxAge time.Duration `toml:"max_age" conf:"default:5m"`
+	// RateLimit is how many lookups each client IP may make per minute. Lookups are not limited when 0.
+	RateLimit int `toml:"rate_limit" conf:"default:60"`
+}
+
+// StreamingConfig bounds the NDJSON streams of list endpoints.
+type StreamingConfig struct {
+	// MaxItems is the hard cap on the items of a stream, which then ends with a truncated trailer. Streams are not
+	// capped when 0.
+	MaxItems int `toml:"max_items" conf:"default:100000"`
+	// PageSize is how many items are read from storage at once.
+	PageSize int `toml:"page_size" conf:"default:100"`
+	// HeartbeatInterval is how often an empty line is written to streams, so that idle proxies do not cut them.
+	HeartbeatInterval time.Duration `toml:"heartbeat_interval" conf:"default:15s"`
+}
+
+// IssuerAPIKeyConfig maps an API key to the issuer DIDs it is permitted to act as.
+type IssuerAPIKeyConfig struct {
+	// KeyHash is the hex encoded sha256 hash of the API key, which callers send in the `X-API-Key` header or as a
+	// Bearer token.
+	KeyHash string `toml:"key_hash" sensitive:"true"`
+
+	// Name identifies the key as the principal recorded on the credentials, DIDs, schemas, and webhooks its callers
+	// create, and on the status updates and deletions they make. Defaults to the first 12 characters of KeyHash.
+	Name string `toml:"name"`
+
+	// Issuers the key is permitted to act as. "*" permits any issuer.
+	Issuers []string `toml:"issuers"`
+
+	// OutputFormat is the format credentials are returned in to callers using the key, when the request does not
+	// override it with the `view` query parameter. One of "container" or "jwt". Defaults to "container".
+	OutputFormat string `toml:"output_format"`
+}
+
+// Principal returns the name recorded as the principal of the requests made with the key.
+func (k IssuerAPIKeyConfig) Principal() string {
+	if k.Name != "" {
+		return k.Name
+	}
+	const keyHashPrefixLength = 12
+	if len(k.KeyHash) > keyHashPrefixLength {
+		return strings.ToLower(k.KeyHash[:keyHashPrefixLength])
+	}
+	return strings.ToLower(k.KeyHash)
 }
 
 // ServicesConfig represents configurable properties for the components of the SSI Service
@@ -74,11 +155,68 @@ type ServicesConfig struct {
 	// configured KV store.
 	AppLevelEncryptionConfiguration EncryptionConfig `toml:"storage_encryption,omitempty"`
 
+	// StorageCompactionInterval is how often the storage is compacted, such as "168h", reclaiming the space of deleted
+	// data for storage providers which need it. Scheduled compaction is disabled when empty.
+	StorageCompactionInterval string `toml:"storage_compaction_interval"`
+
+	// StorageSoftQuotaBytes is a soft quota on the size of the storage: a warning is logged whenever the storage is found
+	// to be larger, such as when its stats are reported or after a scheduled compaction. Nothing is enforced. There is
+	// no quota when 0.
+	StorageSoftQuotaBytes int64 `toml:"storage_soft_quota_bytes"`
+
+	// LeaderElection restricts the background workers to a single replica when several replicas share the storage.
+	LeaderElection LeaderElectionConfig `toml:"leader_election,omitempty"`
+
+	// OfflineVerification makes verification fail closed on any operation which needs network access, such as
+	// resolving DIDs not managed by the service, fetching the status lists of imported credentials, or loading the
+	// JSON-LD contexts of data integrity credentials. DIDs managed by the service, DIDs of methods resolved from the DID
+	// itself, and status lists hosted by the service are still verified.
+	OfflineVerification bool `toml:"offline_verification"`
+
+	// Analytics emits anonymized events about the issuance and verification of credentials.
+	Analytics AnalyticsConfig `toml:"analytics,omitempty"`
+
 	// Embed all service-specific configs here. The order matters: from which should be instantiated first, to last
-	KeyStoreConfig   KeyStoreServiceConfig   `toml:"keystore,omitempty"`
-	DIDConfig        DIDServiceConfig        `toml:"did,omitempty"`
-	CredentialConfig CredentialServiceConfig `toml:"credential,omitempty"`
-	WebhookConfig    WebhookServiceConfig    `toml:"webhook,omitempty"`
+	KeyStoreConfig     KeyStoreServiceConfig     `toml:"keystore,omitempty"`
+	DIDConfig          DIDServiceConfig          `toml:"did,omitempty"`
+	CredentialConfig   CredentialServiceConfig   `toml:"credential,omitempty"`
+	PresentationConfig PresentationServiceConfig `toml:"presentation,omitempty"`
+	ManifestConfig     ManifestServiceConfig     `toml:"manifest,omitempty"`
+	WebhookConfig      WebhookServiceConfig      `toml:"webhook,omitempty"`
+
+	IssuerMetadataConfig   IssuerMetadataServiceConfig   `toml:"issuer_metadata,omitempty"`
+	SSIConfigurationConfig SSIConfigurationServiceConfig `toml:"ssi_configuration,omitempty"`
+}
+
+// LeaderElectionConfig configures the lease which the replica running the background workers holds in the storage.
+// Every replica runs the background workers when leader election is disabled.
+type LeaderElectionConfig struct {
+	Enabled bool `toml:"enabled" conf:"default:false"`
+	// HolderID identifies this replica as the holder of the lease. Defaults to the hostname with a random suffix.
+	HolderID string `toml:"holder_id"`
+	// LeaseDuration is how long the lease is held without being renewed, such as "30s", after which another replica
+	// takes it over.
+	LeaseDuration string `toml:"lease_duration" conf:"default:30s"`
+	// RenewInterval is how often the holder renews the lease, and other replicas try to acquire it, such as "10s". It
+	// must be shorter than LeaseDuration.
+	RenewInterval string `toml:"renew_interval" conf:"default:10s"`
+}
+
+// AnalyticsConfig configures the analytics events emitted about credential issuance and the verification of credentials
+// and presentations. Events hold the type, schema, outcome, and latency bucket of operations, and a salted hash of the
+// issuer, but never any claims or subject data.
+type AnalyticsConfig struct {
+	// Sink is where events are emitted, one of "log", "statsd", or "storage". Analytics are disabled when empty.
+	Sink string `toml:"sink"`
+	// SampleRate is the fraction of events emitted, between 0 and 1.
+	SampleRate float64 `toml:"sample_rate" conf:"default:1"`
+	// Salt keys the hash of the DIDs of issuers, so that they cannot be recovered by hashing known DIDs. It is required
+	// when analytics are enabled, and must be kept secret.
+	Salt string `toml:"salt" sensitive:"true"`
+	// StatsDAddress is the host and port of the StatsD server of the "statsd" sink, such as "localhost:8125".
+	StatsDAddress string `toml:"statsd_address"`
+	// StatsDPrefix prefixes the names of the counters of the "statsd" sink.
+	StatsDPrefix string `toml:"statsd_prefix" conf:"default:ssi_service.analytics"`
 }
 
 type KeyStoreServiceConfig struct {
@@ -91,10 +229,10 @@ type EncryptionConfig struct {
 	// The URI for a master key. We use tink for envelope encryption as described in https://github.com/google/tink/blob/9bc2667963e20eb42611b7581e570f0dddf65a2b/docs/KEY-MANAGEMENT.md#key-management-with-tink
 	// When left empty and DisableEncryption is off, then a random key is generated and used. This random key is persisted unencrypted in the
 	// configured storage. Production deployments should never leave this field empty.
-	MasterKeyURI string `toml:"master_key_uri"`
+	MasterKeyURI string `toml:"master_key_uri" sensitive:"true"`
 
 	// Path for credentials. Required when MasterKeyURI is set. More info at https://github.com/google/tink/blob/9bc2667963e20eb42611b7581e570f0dddf65a2b/docs/KEY-MANAGEMENT.md#credentials
-	KMSCredentialsPath string `toml:"kms_credentials_path"`
+	KMSCredentialsPath string `toml:"kms_credentials_path" sensitive:"true"`
 }
 
 func (e EncryptionConfig) GetMasterKeyURI() string {
@@ -137,6 +275,8 @@ type DIDServiceConfig struct {
 	IONResolverURL           string   `toml:"ion_resolver_url"`
 	// BatchCreateMaxItems set's the maximum amount that can be.
 	BatchCreateMaxItems int `toml:"batch_create_max_items" conf:"default:100"`
+	// BatchResolveMaxItems is the maximum number of DIDs which can be resolved in a batch.
+	BatchResolveMaxItems int `toml:"batch_resolve_max_items" conf:"default:100"`
 }
 
 func (d *DIDServiceConfig) IsEmpty() bool {
@@ -147,14 +287,217 @@ func (d *DIDServiceConfig) IsEmpty() bool {
 }
 
 type CredentialServiceConfig struct {
-	// BatchCreateMaxItems set's the maximum amount of credentials that can be created in a single request.
+	// BatchCreateMaxItems set's the maximum amount of credentials of each issuer, schema, and status purpose that can be
+	// created in a single request.
 	BatchCreateMaxItems int `toml:"batch_create_max_items" conf:"default:100"`
 	// BatchUpdateStatusMaxItems set's the maximum amount of credentials statuses that can be updated in a single request.
 	BatchUpdateStatusMaxItems int `toml:"batch_update_status_max_items" conf:"default:100"`
+	// SubjectCredentialQuota caps how many credentials each subject may hold from an issuer, so that a wallet can't be
+	// flooded with credentials. Schemas may override it. When 0, there is no quota.
+	SubjectCredentialQuota int `toml:"subject_credential_quota" conf:"default:0"`
+
+	// BatchCreateChunkSize splits batches of credentials into transactions of at most this many credentials, which
+	// hold fewer locks and conflict less with concurrent requests. A batch is then no longer created all or nothing:
+	// a failed chunk leaves the chunks created before it. When 0, each batch is created in a single transaction.
+	BatchCreateChunkSize int `toml:"batch_create_chunk_size" conf:"default:0"`
+	// BatchTimeout bounds how long a batch of credentials may take to be created, or to have their statuses updated,
+	// such as "30s". Once it expires, the transaction in progress is rolled back and the rest of the batch is not
+	// attempted. Batches are only bounded by their request when empty.
+	BatchTimeout string `toml:"batch_timeout"`
+
+	// StatusListCapacityThreshold is the number of remaining indexes of a status list below which a warning is logged
+	// and counted each time an index is allocated. Set to 0 to disable.
+	StatusListCapacityThreshold int `toml:"status_list_capacity_threshold" conf:"default:1000"`
+
+	// PromotedWarnings lists the codes of credential creation warnings which block issuance instead, such as
+	// "LONG_EXPIRY", "UNRESOLVABLE_SUBJECT", or "UNDEFINED_CLAIM".
+	PromotedWarnings []string `toml:"promoted_warnings"`
+
+	// StatusListPublisher configures publication of status list credentials to an external store. Publication is
+	// disabled when no type is set.
+	StatusListPublisher StatusListPublisherConfig `toml:"status_list_publisher,omitempty"`
+
+	// StatusListRefreshInterval is how often every status list credential is re-signed with a fresh issuance date,
+	// such as "24h". Re-signing is disabled when empty.
+	StatusListRefreshInterval string `toml:"status_list_refresh_interval"`
+	// StatusListRefreshValidity sets the expiration of re-signed status list credentials relative to the time they are
+	// re-signed, such as "48h". Re-signed status list credentials do not expire when empty.
+	StatusListRefreshValidity string `toml:"status_list_refresh_validity"`
+	// StatusListRefreshExclusions lists the IDs of status list credentials which are never re-signed.
+	StatusListRefreshExclusions []string `toml:"status_list_refresh_exclusions"`
+
+	// StatusListHostingCheck fetches each status list credential from its public URI at startup, and compares it to
+	// the stored one, so that status lists which a CDN serving the status endpoint fails to serve, or serves stale, are
+	// reported. It must stay disabled in air-gapped deployments.
+	StatusListHostingCheck bool `toml:"status_list_hosting_check" conf:"default:false"`
+	// StatusListHostingCheckInterval is how often the hosting check runs again after startup, such as "1h". It only
+	// runs at startup when empty.
+	StatusListHostingCheckInterval string `toml:"status_list_hosting_check_interval"`
+	// StatusListHostingCheckRate bounds how many status list credentials the hosting check fetches per second. Fetches
+	// are not bounded when 0.
+	StatusListHostingCheckRate int `toml:"status_list_hosting_check_rate" conf:"default:5"`
+
+	// StatusConsistencyCheckInterval is how often the stored status of credentials is compared to the bits of their
+	// status lists, such as "24h". The check only runs when triggered through the admin API when empty.
+	StatusConsistencyCheckInterval string `toml:"status_consistency_check_interval"`
+	// StatusConsistencyRepair regenerates the status lists whose bits do not match the stored status of their
+	// credentials when the check runs on a schedule.
+	StatusConsistencyRepair bool `toml:"status_consistency_repair" conf:"default:false"`
+
+	// TrustedSchemaAuthorities are the DIDs trusted to issue JsonSchemaCredential schemas, which the schemas of
+	// credentials verified with requireTrustedSchema must be issued by.
+	TrustedSchemaAuthorities []string `toml:"trusted_schema_authorities"`
+
+	// AllowRevokedSchemaCredentials accepts creating credentials against JsonSchemaCredential schemas whose schema
+	// credential is revoked, or whose revocation status cannot be checked. It is meant for emergencies, such as a
+	// status list which cannot be fetched.
+	AllowRevokedSchemaCredentials bool `toml:"allow_revoked_schema_credentials" conf:"default:false"`
+
+	// RenewalInterval is how often credentials created with an auto-renew policy are checked for renewal, such as
+	// "1h". Credentials are not renewed when empty.
+	RenewalInterval string `toml:"renewal_interval"`
+
+	// IDScheme is how the `id` of created credentials is formed from their UUID. "url" makes it a URL under the
+	// credential service path, "urn" makes it a `urn:uuid:` URN, and any other value is used as a prefix of the UUID.
+	// Credentials keep the ID they were created with when the scheme changes.
+	IDScheme string `toml:"id_scheme" conf:"default:url"`
+
+	// DeterministicID derives the UUID of created credentials from a SHA-256 hash of their issuer, subject, schema, and
+	// data, instead of generating a random one. Creating a credential with the same content again returns the existing
+	// credential, even if it has since been revoked or has expired. The 122 bits of hash kept make accidental collisions
+	// negligible, but since credential IDs are seen by verifiers, anyone who can guess the content of a credential can
+	// confirm it from its ID, so this should not be enabled for credentials with few possible claim values.
+	DeterministicID bool `toml:"deterministic_id" conf:"default:false"`
+
+	// ExternalStatusListCacheTTL is how long status list credentials fetched to check the status of imported
+	// credentials are reused, such as "5m". Status lists are fetched on every check when empty.
+	ExternalStatusListCacheTTL string `toml:"external_status_list_cache_ttl" conf:"default:5m"`
+	// ExternalStatusListMaxStaleness is how long after their cache TTL expires external status lists are still used,
+	// such as "1h", with the status marked as stale, while they are refreshed in the background. Once it expires too,
+	// or when empty, status lists are fetched before being used, and the status is unknown when they cannot be.
+	ExternalStatusListMaxStaleness string `toml:"external_status_list_max_staleness"`
+	// ExternalStatusListNotFoundTTL is how long external status lists which were not found are not fetched again, such
+	// as "10m", the status of their credentials being unknown meanwhile. They are fetched on every check when empty.
+	ExternalStatusListNotFoundTTL string `toml:"external_status_list_not_found_ttl"`
+
+	// SchemaFormatAssertions lists the JSON Schema formats, such as "email" or "date-time", which claims must satisfy
+	// when credentials are created or verified against their schema. Formats are only annotations in schemas from
+	// draft 2019-09 on, unless listed here.
+	SchemaFormatAssertions []string `toml:"schema_format_assertions"`
+
+	// StatusListSigningKeys binds the status lists of a purpose to a verification method other than the one their
+	// credentials were issued with, so that, for instance, the key allowed to suspend credentials cannot revoke them.
+	// Status lists without a binding are signed with the verification method of their credentials.
+	StatusListSigningKeys []StatusListSigningKeyConfig `toml:"status_list_signing_keys"`
+
+	// StatusReasonCodes lists the reason codes status updates may be given, such as "KEY_COMPROMISE". Any reason code
+	// is accepted when empty.
+	StatusReasonCodes []string `toml:"status_reason_codes"`
+
+	// RequireStatusReason rejects revoking or suspending credentials without a reason.
+	RequireStatusReason bool `toml:"require_status_reason" conf:"default:false"`
+
+	// LogCredentialPayloads logs each created credential, with the claims at LogRedactedPaths redacted, for debugging.
+	// Only the ID of created credentials is logged when false, since credentials hold personal data.
+	LogCredentialPayloads bool `toml:"log_credential_payloads" conf:"default:false"`
+	// LogRedactedPaths lists the dot separated JSON paths of the claims redacted from logged credentials, and from the
+	// offending values reported for credentials not complying with their schema, such as "credentialSubject.email". A
+	// path through an array applies to each of its elements.
+	LogRedactedPaths []string `toml:"log_redacted_paths"`
+
+	// EventLogEnabled appends an event to the credential event log in the transaction creating, updating the status of,
+	// or deleting each credential, so that integrations can poll for the events after a cursor.
+	EventLogEnabled bool `toml:"event_log_enabled" conf:"default:false"`
+
+	// SigningPoolSize bounds how many credentials and status list credentials are signed at once. Signing is not
+	// bounded when 0.
+	SigningPoolSize int `toml:"signing_pool_size" conf:"default:0"`
+	// SigningQueueDepth bounds how many signing operations wait for the pool when all of it is busy. Operations beyond
+	// it fail right away with a server busy error, which is responded to with a 503 and a Retry-After header.
+	SigningQueueDepth int `toml:"signing_queue_depth" conf:"default:100"`
+
+	// MaxValidityDuration caps how long after issuance created credentials may expire, such as "8760h" for a year.
+	// Credentials requesting a later expiry are rejected. Validity is not capped when empty.
+	MaxValidityDuration string `toml:"max_validity_duration"`
+	// RequireExpiry rejects the creation of credentials without an expiry.
+	RequireExpiry bool `toml:"require_expiry" conf:"default:false"`
+	// ExpiryClockSkew is how long after their expiration date credentials are still ACTIVE by their lifecycle state,
+	// such as "5m", to allow for clocks running behind the service's. Credentials expire at their expiration date when
+	// empty.
+	ExpiryClockSkew string `toml:"expiry_clock_skew"`
+
+	// JWTClaimsProfile is the preset setting which registered claims of credential JWTs, such as `sub`, `jti`, `iat`,
+	// and `nbf`, are set from the credential: "vc-jwt-1.1", the default when empty, or "jwt-vc-issuance". Requests to
+	// create credentials may override it.
+	JWTClaimsProfile string `toml:"jwt_claims_profile"`
+
+	// RelaxAssertionMethodCheck accepts creating credentials with a verification method which is in the issuer's DID
+	// document, but not referenced by its assertionMethod relationship, for DID methods which don't model relationships.
+	RelaxAssertionMethodCheck bool `toml:"relax_assertion_method_check" conf:"default:false"`
+
+	// VerificationReceiptSigner is the fully qualified verification method of a DID held by the service, such as
+	// "did:key:z6Mk...#z6Mk...", which signs the verification receipts returned to verification requests asking for
+	// one. Receipts cannot be requested when empty.
+	VerificationReceiptSigner string `toml:"verification_receipt_signer"`
 
 	// TODO(gabe) supported key and signature types
 }
 
+// StatusListSigningKeyConfig binds the status lists of an issuer, schema, and purpose to the verification method which
+// signs them. Status updates of credentials whose status list is bound must be requested with that verification method.
+type StatusListSigningKeyConfig struct {
+	// Issuer is the DID of the issuer whose status lists are bound.
+	Issuer string `toml:"issuer"`
+	// Schema is the ID of the schema whose status lists are bound. When empty, the binding applies to every schema of
+	// the issuer which has no binding of its own for the purpose.
+	Schema string `toml:"schema"`
+	// Purpose is either "revocation" or "suspension".
+	Purpose string `toml:"purpose"`
+	// VerificationMethodID of the issuer's key signing the status lists, such as "did:key:z6Mk...#z6Mk...".
+	VerificationMethodID string `toml:"verification_method_id"`
+}
+
+const (
+	URLCredentialIDScheme = "url"
+	URNCredentialIDScheme = "urn"
+)
+
+const (
+	HTTPStatusListPublisher = "http"
+	S3StatusListPublisher   = "s3"
+	GCSStatusListPublisher  = "gcs"
+)
+
+// StatusListPublisherConfig describes where signed status list credentials are written whenever they are regenerated,
+// so that verifiers can fetch them from a CDN or object store instead of from the service.
+type StatusListPublisherConfig struct {
+	// Type is one of "http", "s3", or "gcs". Publication is disabled when empty.
+	Type string `toml:"type"`
+
+	// URL status list credentials are PUT to when Type is "http". The placeholder {id} is replaced with the ID of the
+	// status list credential.
+	URL string `toml:"url"`
+	// Authorization is the value of the Authorization header sent with each request when Type is "http".
+	Authorization string `toml:"authorization" sensitive:"true"`
+
+	// Bucket status list credentials are written to when Type is "s3" or "gcs". Objects are named after the ID of the
+	// status list credential, prefixed with Prefix. Credentials are read from the environment, using each provider's
+	// default credential chain.
+	Bucket string `toml:"bucket"`
+	Prefix string `toml:"prefix"`
+	// Region of the bucket when Type is "s3".
+	Region string `toml:"region"`
+
+	// MaxAttempts is the number of times publication is attempted before giving up.
+	MaxAttempts int `toml:"max_attempts" conf:"default:3"`
+	// RetryBackoff is the delay before the first retry, which doubles with each attempt.
+	RetryBackoff string `toml:"retry_backoff" conf:"default:1s"`
+}
+
+func (c StatusListPublisherConfig) IsEnabled() bool {
+	return c.Type != ""
+}
+
 func (c *CredentialServiceConfig) IsEmpty() bool {
 	if c == nil {
 		return true
@@ -162,8 +505,72 @@ func (c *CredentialServiceConfig) IsEmpty() bool {
 	return reflect.DeepEqual(c, &CredentialServiceConfig{})
 }
 
+type PresentationServiceConfig struct {
+	// ChallengeTTL is how long a challenge created by the service can be used to verify presentations, such as "5m".
+	// When set, presentations can only be verified against challenges created by the service. When empty, challenges
+	// never expire, and any challenge can be used once.
+	ChallengeTTL string `toml:"challenge_ttl"`
+	// ChallengeSweepInterval is how often expired challenges are purged from storage.
+	ChallengeSweepInterval string `toml:"challenge_sweep_interval" conf:"default:1m"`
+}
+
+type ManifestServiceConfig struct {
+	// DenyUnverifiedPresentations denies applications whose verifiable presentation fails verification as soon as
+	// they are submitted. Otherwise, such applications are left pending for review.
+	DenyUnverifiedPresentations bool `toml:"deny_unverified_presentations"`
+}
+
+// IssuerMetadataServiceConfig configures the OpenID4VCI credential issuer metadata served at
+// /.well-known/openid-credential-issuer, as described in
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-issuer-metadata
+type IssuerMetadataServiceConfig struct {
+	// CredentialIssuer is the credential issuer identifier advertised to wallets. Defaults to the service endpoint.
+	CredentialIssuer string `toml:"credential_issuer"`
+	// CredentialEndpoint is the URL wallets request credentials from. Defaults to the credentials API.
+	CredentialEndpoint string `toml:"credential_endpoint"`
+	// CredentialFormats lists the formats every schema is advertised in, such as "jwt_vc_json" or "ldp_vc".
+	CredentialFormats []string `toml:"credential_formats" conf:"default:jwt_vc_json"`
+
+	// DisplayName and DisplayLocale describe the issuer to end users. No display is advertised when DisplayName is empty.
+	DisplayName   string `toml:"display_name"`
+	DisplayLocale string `toml:"display_locale"`
+	// Displays describe the issuer in further locales. Requests with an Accept-Language header are given the display
+	// best matching it.
+	Displays []IssuerDisplayConfig `toml:"displays"`
+}
+
+// SSIConfigurationServiceConfig configures the signed bundle of the issuer DIDs, public keys, schemas, and status list
+// endpoint of the service, served at /v1/.well-known/ssi-configuration for verifiers to fetch periodically.
+type SSIConfigurationServiceConfig struct {
+	// Signer is the fully qualified verification method of the key signing the bundle, such as "did:key:abc#abc". The
+	// bundle is not served when empty.
+	Signer string `toml:"signer"`
+	// MaxAge is how long verifiers may cache the bundle before revalidating it with its ETag, such as "5m".
+	MaxAge string `toml:"max_age" conf:"default:5m"`
+}
+
+// IssuerDisplayConfig is the name of the issuer shown to end users in a locale, such as "es" or "fr-CA".
+type IssuerDisplayConfig struct {
+	Name   string `toml:"name"`
+	Locale string `toml:"locale"`
+}
+
 type WebhookServiceConfig struct {
 	WebhookTimeout string `toml:"webhook_timeout" conf:"default:10s"`
+
+	// When true, failed credential and presentation verifications are published as Credential and Presentation
+	// VerificationFailed webhooks.
+	VerificationFailureEvents bool `toml:"verification_failure_events" conf:"default:false"`
+
+	// When true, Credential Create and StatusUpdate, and DID Create events are written to an outbox along with the change
+	// they are about, and published by a background dispatcher once committed, so that they are delivered at least
+	// once, even when the service restarts before publishing them.
+	OutboxEnabled bool `toml:"outbox_enabled" conf:"default:false"`
+	// How often the outbox is dispatched, such as "1s".
+	OutboxDispatchInterval string `toml:"outbox_dispatch_interval" conf:"default:1s"`
+
+	// How many test events each client IP may send per minute. Test events are not limited when 0.
+	TestRateLimit int `toml:"test_rate_limit" conf:"default:10"`
 }
 
 func (p *WebhookServiceConfig) IsEmpty() bool {
@@ -207,6 +614,13 @@ func LoadConfig(path string, fs fs.FS) (*SSIServiceConfig, error) {
 }
 
 func validateConfig(s *SSIServiceConfig) error {
+	for _, key := range s.Server.IssuerAPIKeys {
+		switch key.OutputFormat {
+		case "", "container", "jwt":
+		default:
+			return fmt.Errorf("invalid output format %q for issuer API key", key.OutputFormat)
+		}
+	}
 	if s.Server.Environment == EnvironmentProd {
 		if s.Services.KeyStoreConfig.DisableEncryption {
 			return errors.New("prod environment cannot disable key encryption")
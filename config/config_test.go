@@ -4,7 +4,10 @@ import (
 	"embed"
 	"testing"
 
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 //go:embed testdata
@@ -30,3 +33,69 @@ func TestLoadConfig(t *testing.T) {
 		assert.ErrorContains(t, err, "prod environment cannot disable key encryption")
 	})
 }
+
+func TestSanitize(t *testing.T) {
+	cfg := SSIServiceConfig{
+		Server: ServerConfig{
+			Environment:         EnvironmentTest,
+			APIHost:             "0.0.0.0:3000",
+			EnableSchemaCaching: true,
+		},
+		Services: ServicesConfig{
+			StorageProvider: "redis",
+			StorageOptions: []storage.Option{
+				{ID: storage.RedisAddressOption, Option: "redis:6379"},
+				{ID: storage.PasswordOption, Option: "super-secret-password"},
+			},
+			ServiceEndpoint: "https://ssi-service.com",
+			KeyStoreConfig: KeyStoreServiceConfig{
+				EncryptionConfig: EncryptionConfig{
+					MasterKeyURI:       "gcp-kms://projects/secret/locations/global/keyRings/ring/cryptoKeys/key",
+					KMSCredentialsPath: "credentials.json",
+				},
+			},
+			CredentialConfig: CredentialServiceConfig{BatchCreateMaxItems: 100},
+			Analytics:        AnalyticsConfig{Sink: "log", Salt: "secret-analytics-salt"},
+		},
+	}
+
+	sanitized, ok := Sanitize(cfg).(map[string]any)
+	assert.True(t, ok)
+
+	sanitizedBytes, err := json.Marshal(sanitized)
+	assert.NoError(t, err)
+	sanitizedJSON := string(sanitizedBytes)
+
+	// secrets are masked
+	assert.NotContains(t, sanitizedJSON, "super-secret-password")
+	assert.NotContains(t, sanitizedJSON, "gcp-kms://projects/secret")
+	assert.NotContains(t, sanitizedJSON, "credentials.json")
+	assert.NotContains(t, sanitizedJSON, "secret-analytics-salt")
+
+	services := sanitized["services"].(map[string]any)
+	keyStore := services["keystore"].(map[string]any)
+	assert.Equal(t, MaskedValue, keyStore["master_key_uri"])
+	assert.Equal(t, MaskedValue, keyStore["kms_credentials_path"])
+	assert.Equal(t, false, keyStore["disable_encryption"])
+	analytics := services["analytics"].(map[string]any)
+	assert.Equal(t, MaskedValue, analytics["salt"])
+	assert.Equal(t, "log", analytics["sink"])
+
+	// unset secrets are reported as empty, not masked
+	appEncryption := services["storage_encryption"].(map[string]any)
+	assert.Equal(t, "", appEncryption["master_key_uri"])
+
+	options := services["storage_option"].([]any)
+	assert.Len(t, options, 2)
+	assert.Equal(t, map[string]any{"id": storage.RedisAddressOption, "option": "redis:6379"}, options[0])
+	assert.Equal(t, map[string]any{"id": storage.PasswordOption, "option": MaskedValue}, options[1])
+
+	// non secrets are present
+	assert.Equal(t, "redis", services["storage"])
+	assert.Equal(t, "https://ssi-service.com", services["service_endpoint"])
+	assert.Equal(t, 100, services["credential"].(map[string]any)["batch_create_max_items"])
+	server := sanitized["server"].(map[string]any)
+	assert.Equal(t, EnvironmentTest, server["env"])
+	assert.Equal(t, "0s", server["read_timeout"])
+	assert.Equal(t, true, server["enable_schema_caching"])
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// SensitiveTag is the struct tag used to mark a config field as holding a secret. Fields tagged with
+	// `sensitive:"true"` are masked by Sanitize.
+	SensitiveTag = "sensitive"
+
+	// MaskedValue replaces the value of any sensitive field in a sanitized config.
+	MaskedValue = "********"
+)
+
+// SensitiveValue is implemented by config values whose sensitivity depends on their contents rather than on the
+// field that holds them, such as a storage option which may carry a password. When a sensitive value is a struct,
+// all of its fields are masked except those explicitly tagged with `sensitive:"false"`.
+type SensitiveValue interface {
+	IsSensitive() bool
+}
+
+// Sanitize converts the given config value into a generic representation suitable for display, masking every
+// value marked as sensitive. Keys are named after the field's `toml` tag so the output mirrors the config file.
+// Masking happens here, rather than in each caller, so that a newly added secret only needs to be tagged to be hidden.
+func Sanitize(v any) any {
+	return sanitizeValue(reflect.ValueOf(v))
+}
+
+func sanitizeValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() {
+		if s, ok := v.Interface().(SensitiveValue); ok && s.IsSensitive() {
+			if v.Kind() != reflect.Struct {
+				return maskValue(v)
+			}
+			out := make(map[string]any)
+			sanitizeStruct(v, out, true)
+			return out
+		}
+		if d, ok := v.Interface().(time.Duration); ok {
+			return d.String()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return sanitizeValue(v.Elem())
+	case reflect.Struct:
+		out := make(map[string]any)
+		sanitizeStruct(v, out, false)
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, sanitizeValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[toString(iter.Key())] = sanitizeValue(iter.Value())
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+func sanitizeStruct(v reflect.Value, out map[string]any, maskAll bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := v.Field(i)
+
+		name, omitted := fieldName(field)
+		if omitted {
+			continue
+		}
+
+		// embedded structs without an explicit name are flattened, matching how the toml decoder treats them
+		if field.Anonymous && name == "" && fieldValue.Kind() == reflect.Struct {
+			sanitizeStruct(fieldValue, out, maskAll)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		sensitive := field.Tag.Get(SensitiveTag)
+		if sensitive == "true" || (maskAll && sensitive != "false") {
+			out[name] = maskValue(fieldValue)
+			continue
+		}
+		out[name] = sanitizeValue(fieldValue)
+	}
+}
+
+// fieldName returns the toml name of a field, falling back to its json name, and whether the field is excluded
+// from serialization.
+func fieldName(field reflect.StructField) (string, bool) {
+	for _, key := range []string{"toml", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if tag == "-" {
+			return "", true
+		}
+		return strings.SplitN(tag, ",", 2)[0], false
+	}
+	return "", false
+}
+
+// maskValue hides a sensitive value, while still making it visible whether the value was set at all.
+func maskValue(v reflect.Value) any {
+	if v.IsZero() {
+		return ""
+	}
+	return MaskedValue
+}
+
+func toString(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -1,10 +1,14 @@
 package credential
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	"github.com/TBD54566975/ssi-sdk/credential/parsing"
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
@@ -37,6 +41,130 @@ type Container struct {
 
 	// Whether this credential is currently suspended.
 	Suspended bool `json:"suspended,omitempty"`
+
+	// Current status value of this credential, e.g. `0x1`, when it uses a message status list.
+	StatusValue string `json:"statusValue,omitempty"`
+
+	// Why the status of this credential was last updated, and the code of that reason, as given with the update.
+	StatusReason     string `json:"statusReason,omitempty"`
+	StatusReasonCode string `json:"statusReasonCode,omitempty"`
+
+	// Whether this credential was issued by another party and imported into the service. The status of an imported
+	// credential is read from the status list credential of its issuer.
+	Imported bool `json:"imported,omitempty"`
+
+	// How wallets should display this credential, taken from the schema it was created against. The credential
+	// model of the sdk has no `renderMethod` property, so it is returned alongside the credential instead of in it.
+	RenderMethod *RenderMethod `json:"renderMethod,omitempty"`
+
+	// Expected claims of the schema which this credential was created without. Unlike claims required by the schema,
+	// they do not prevent a credential from being created.
+	MissingClaims []string `json:"missingClaims,omitempty"`
+
+	// Display name of the issuer, when the issuer is a DID managed by the service which has one. Only set when
+	// requested with `expand=issuer`.
+	IssuerDisplayName string `json:"issuerDisplayName,omitempty"`
+
+	// Receipt with which the subject acknowledged that the credential was delivered to them, if any.
+	Receipt *Receipt `json:"receipt,omitempty"`
+
+	// Version of the schema this credential was validated against when it was created, which it is verified against
+	// from then on. Not set for credentials without a schema, or created before schemas were versioned.
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
+	// Policy with which this credential is re-issued before it expires, carried over to each credential re-issued
+	// under it.
+	AutoRenew *AutoRenewPolicy `json:"autoRenew,omitempty"`
+
+	// ID of the credential this credential was re-issued in place of, under an auto-renew policy.
+	PreviousCredential string `json:"previousCredential,omitempty"`
+
+	// Principal which created this credential: the name of the API key of its caller, or `anonymous`. Not set for
+	// credentials created before principals were recorded.
+	CreatedBy string `json:"createdBy,omitempty"`
+
+	// Whether this credential is currently usable, derived from whether it is revoked, suspended, or expired.
+	LifecycleState LifecycleState `json:"lifecycleState,omitempty"`
+}
+
+// LifecycleState is whether a credential is currently usable, derived from its status, its expiry, and whether it was
+// deleted. When several of them hold, a revoked credential is REVOKED, then a suspended one is SUSPENDED, then an
+// expired one is EXPIRED.
+type LifecycleState string
+
+const (
+	LifecycleActive    LifecycleState = "ACTIVE"
+	LifecycleSuspended LifecycleState = "SUSPENDED"
+	LifecycleRevoked   LifecycleState = "REVOKED"
+	LifecycleExpired   LifecycleState = "EXPIRED"
+	// LifecycleDeleted is only reported by the status of a credential whose deletion is recorded by the event log,
+	// since deleted credentials are not stored.
+	LifecycleDeleted LifecycleState = "DELETED"
+)
+
+// AutoRenewPolicy re-issues a credential with the same data and schema before it expires, and keeps re-issuing each
+// renewed credential, until the policy expires, the maximum number of renewals is reached, the credential is revoked,
+// or the key of its issuer is revoked.
+type AutoRenewPolicy struct {
+	// How long after a credential is issued it is re-issued, such as "144h". Must be shorter than its validity.
+	Every string `json:"every" validate:"required"`
+
+	// Time after which credentials are no longer re-issued, in RFC3339 format. Re-issued indefinitely when empty.
+	Until string `json:"until,omitempty"`
+
+	// Maximum number of times the credential is re-issued. Unbounded when 0.
+	MaxRenewals int `json:"maxRenewals,omitempty" validate:"min=0"`
+
+	// Whether a credential is revoked once it has been re-issued. Requires the credential to be revocable.
+	RevokePrevious bool `json:"revokePrevious,omitempty"`
+}
+
+// Receipt is a JWT signed by the subject of a credential, acknowledging that the credential was delivered to them.
+type Receipt struct {
+	ReceiptJWT keyaccess.JWT `json:"receiptJwt"`
+
+	// Fully qualified ID of the verification method of the subject which the receipt is signed with.
+	VerificationMethodID string `json:"verificationMethodId"`
+
+	// When the receipt was stored, in RFC3339 format.
+	AcknowledgedAt string `json:"acknowledgedAt"`
+}
+
+const (
+	SVGRenderingTemplate = "SvgRenderingTemplate2023"
+	OverlayCaptureBundle = "OverlayCaptureBundle"
+)
+
+// RenderMethod describes how to display a credential, following the `renderMethod` property of the VC Data Model
+// v2.0.
+type RenderMethod struct {
+	// URL of the template. Required for OverlayCaptureBundle.
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type" validate:"required,oneof=SvgRenderingTemplate2023 OverlayCaptureBundle"`
+	Name string `json:"name,omitempty"`
+
+	// Template is an SVG image, in which placeholders such as `{{credentialSubject.firstName}}` are replaced with the
+	// claims of the credential. Only supported for SvgRenderingTemplate2023.
+	Template string `json:"template,omitempty"`
+}
+
+func (r RenderMethod) IsValid() error {
+	switch r.Type {
+	case SVGRenderingTemplate:
+		if r.ID == "" && r.Template == "" {
+			return fmt.Errorf("render method<%s> must have an id or a template", r.Type)
+		}
+	case OverlayCaptureBundle:
+		if r.ID == "" {
+			return fmt.Errorf("render method<%s> must have an id", r.Type)
+		}
+		if r.Template != "" {
+			return fmt.Errorf("render method<%s> cannot have a template", r.Type)
+		}
+	default:
+		return fmt.Errorf("unsupported render method type<%s>", r.Type)
+	}
+	return nil
 }
 
 func (c Container) JWTString() string {
@@ -126,13 +254,51 @@ func NewCredentialContainerFromArray(creds []any) ([]Container, error) {
 	return containers, nil
 }
 
-// CopyCredential copies a credential into a new credential
+// ParseVerifiableCredentialFromJWT parses a VC-JWT like the ssi-sdk function of the same name, except that the claims
+// of the credential subject are decoded with json.Number, so that large integers keep their precision.
+func ParseVerifiableCredentialFromJWT(token string) (*credential.VerifiableCredential, error) {
+	_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("jwt must have three parts")
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding jwt payload")
+	}
+	var payload struct {
+		VC struct {
+			CredentialSubject map[string]any `json:"credentialSubject"`
+		} `json:"vc"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payloadBytes))
+	decoder.UseNumber()
+	if err = decoder.Decode(&payload); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling jwt payload")
+	}
+	if cred.CredentialSubject == nil && len(payload.VC.CredentialSubject) > 0 {
+		cred.CredentialSubject = make(credential.CredentialSubject, len(payload.VC.CredentialSubject))
+	}
+	for claim, value := range payload.VC.CredentialSubject {
+		cred.CredentialSubject[claim] = value
+	}
+	return cred, nil
+}
+
+// CopyCredential copies a credential into a new credential. Numbers are copied as json.Number, so that large integers
+// keep their precision.
 func CopyCredential(c credential.VerifiableCredential) (*credential.VerifiableCredential, error) {
 	var cred credential.VerifiableCredential
 	credBytes, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	err = json.Unmarshal(credBytes, &cred)
+	decoder := json.NewDecoder(bytes.NewReader(credBytes))
+	decoder.UseNumber()
+	err = decoder.Decode(&cred)
 	return &cred, err
 }
@@ -0,0 +1,178 @@
+package i18n
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// DefaultLanguage is the language messages fall back to when none of the languages accepted by a client has them.
+const DefaultLanguage = "en"
+
+//go:embed catalogs/*.json
+var embeddedCatalogs embed.FS
+
+// catalog holds the messages of the service, starting with the embedded catalogs.
+var catalog = mustEmbeddedCatalog()
+
+func mustEmbeddedCatalog() *Catalog {
+	c := NewCatalog()
+	if err := c.Load(embeddedCatalogs, "catalogs"); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// LoadCatalogs adds the catalogs of a directory to the embedded ones. See Catalog.Load.
+func LoadCatalogs(dir string) error {
+	return catalog.Load(os.DirFS(dir), ".")
+}
+
+// Message returns the message of a code in the language best matching an Accept-Language header, along with the
+// language. See Catalog.Message.
+func Message(acceptLanguage, code string) (message string, lang string) {
+	return catalog.Message(acceptLanguage, code)
+}
+
+// Catalog holds messages by language and code. Codes are stable identifiers, such as "NOT_FOUND", which clients can
+// rely on, unlike the messages themselves.
+type Catalog struct {
+	mu sync.RWMutex
+	// messages are keyed by language, then code
+	messages map[string]map[string]string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[string]string)}
+}
+
+// Load adds the catalogs in a directory of a file system. Each catalog is a JSON file named after its language, such
+// as `es.json` or `pt-BR.json`, which maps codes to messages. Messages replace those loaded before with the same code
+// and language, so that the embedded messages can be reworded.
+func (c *Catalog) Load(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return errors.Wrapf(err, "reading catalogs directory<%s>", dir)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		catalogBytes, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "reading catalog<%s>", entry.Name())
+		}
+		var messages map[string]string
+		if err = json.Unmarshal(catalogBytes, &messages); err != nil {
+			return errors.Wrapf(err, "parsing catalog<%s>", entry.Name())
+		}
+		c.add(normalize(strings.TrimSuffix(entry.Name(), ".json")), messages)
+	}
+	return nil
+}
+
+func (c *Catalog) add(lang string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string, len(messages))
+	}
+	for code, message := range messages {
+		c.messages[lang][code] = message
+	}
+}
+
+// Message returns the message of a code in the language best matching an Accept-Language header, along with the
+// language. Codes which are not translated to any accepted language fall back to DefaultLanguage. Empty strings are
+// returned when no catalog has the code.
+func (c *Catalog) Message(acceptLanguage, code string) (message string, lang string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var translated []string
+	for lang, messages := range c.messages {
+		if _, ok := messages[code]; ok {
+			translated = append(translated, lang)
+		}
+	}
+	lang = Negotiate(acceptLanguage, translated)
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	message, ok := c.messages[lang][code]
+	if !ok {
+		return "", ""
+	}
+	return message, lang
+}
+
+// Negotiate returns the available language best matching an Accept-Language header, or an empty string when none
+// matches. The ranges of the header are tried by their quality, each matching a language equal to it first, then one
+// which it is a prefix of, or which is a prefix of it, such as `es` and `es-MX`. The wildcard `*` is ignored, so that
+// callers fall back to their own default.
+func Negotiate(acceptLanguage string, available []string) string {
+	available = append([]string(nil), available...)
+	sort.Strings(available)
+	for _, languageRange := range parseAcceptLanguage(acceptLanguage) {
+		for _, lang := range available {
+			if normalize(lang) == languageRange {
+				return lang
+			}
+		}
+		for _, lang := range available {
+			normalized := normalize(lang)
+			if strings.HasPrefix(normalized, languageRange+"-") || strings.HasPrefix(languageRange, normalized+"-") {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// parseAcceptLanguage returns the normalized language ranges of an Accept-Language header, ordered by quality from
+// highest to lowest. Ranges with a quality of 0 are left out.
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weightedRange struct {
+		languageRange string
+		quality       float64
+	}
+	var ranges []weightedRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		languageRange, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		languageRange = normalize(languageRange)
+		if languageRange == "" || languageRange == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weightedRange{languageRange: languageRange, quality: quality})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	languageRanges := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		languageRanges = append(languageRanges, r.languageRange)
+	}
+	return languageRanges
+}
+
+// normalize lower cases a language tag, and uses hyphens to separate its subtags, so that `pt_BR` matches `pt-br`.
+func normalize(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
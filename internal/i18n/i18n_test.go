@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	available := []string{"en", "es", "fr", "pt-BR"}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "empty header", acceptLanguage: "", expected: ""},
+		{name: "exact match", acceptLanguage: "fr", expected: "fr"},
+		{name: "region of an available language", acceptLanguage: "es-MX", expected: "es"},
+		{name: "language of an available region", acceptLanguage: "pt", expected: "pt-BR"},
+		{name: "case and separator are ignored", acceptLanguage: "PT_br", expected: "pt-BR"},
+		{name: "highest quality wins", acceptLanguage: "en;q=0.5, fr;q=0.9, es;q=0.7", expected: "fr"},
+		{name: "unavailable languages are skipped", acceptLanguage: "de, es;q=0.8", expected: "es"},
+		{name: "zero quality is not acceptable", acceptLanguage: "fr;q=0, de", expected: ""},
+		{name: "wildcard is ignored", acceptLanguage: "*", expected: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, Negotiate(test.acceptLanguage, available))
+		})
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	t.Run("embedded catalogs", func(tt *testing.T) {
+		en, lang := Message("en", "NOT_FOUND")
+		assert.Equal(tt, "en", lang)
+		assert.NotEmpty(tt, en)
+
+		es, lang := Message("es-ES,es;q=0.9", "NOT_FOUND")
+		assert.Equal(tt, "es", lang)
+		assert.NotEqual(tt, en, es)
+
+		fr, lang := Message("fr", "NOT_FOUND")
+		assert.Equal(tt, "fr", lang)
+		assert.NotEqual(tt, en, fr)
+		assert.NotEqual(tt, es, fr)
+	})
+
+	t.Run("loaded catalogs extend and replace messages", func(tt *testing.T) {
+		catalog := NewCatalog()
+		require.NoError(tt, catalog.Load(fstest.MapFS{
+			"en.json":     {Data: []byte(`{"NOT_FOUND": "not found", "CONFLICT": "conflict"}`)},
+			"de.json":     {Data: []byte(`{"NOT_FOUND": "nicht gefunden"}`)},
+			"README.md":   {Data: []byte("not a catalog")},
+			"nested/x.md": {Data: []byte("not a catalog")},
+		}, "."))
+
+		message, lang := catalog.Message("de", "NOT_FOUND")
+		assert.Equal(tt, "nicht gefunden", message)
+		assert.Equal(tt, "de", lang)
+
+		// untranslated codes fall back to english
+		message, lang = catalog.Message("de", "CONFLICT")
+		assert.Equal(tt, "conflict", message)
+		assert.Equal(tt, DefaultLanguage, lang)
+
+		require.NoError(tt, catalog.Load(fstest.MapFS{
+			"de.json": {Data: []byte(`{"NOT_FOUND": "Nicht gefunden."}`)},
+		}, "."))
+		message, _ = catalog.Message("de", "NOT_FOUND")
+		assert.Equal(tt, "Nicht gefunden.", message)
+
+		message, lang = catalog.Message("de", "UNKNOWN")
+		assert.Empty(tt, message)
+		assert.Empty(tt, lang)
+	})
+
+	t.Run("invalid catalog", func(tt *testing.T) {
+		err := NewCatalog().Load(fstest.MapFS{"en.json": {Data: []byte(`["not", "a", "catalog"]`)}}, ".")
+		assert.ErrorContains(tt, err, "parsing catalog<en.json>")
+	})
+}
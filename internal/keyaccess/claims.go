@@ -0,0 +1,119 @@
+package keyaccess
+
+import (
+	"maps"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+)
+
+const (
+	// VCJWT11Profile encodes credentials as in VC-JWT 1.1: `sub`, `jti`, `iat`, `nbf`, and `exp` are set from the
+	// credential, and the properties they are set from are removed from the `vc` claim.
+	VCJWT11Profile = "vc-jwt-1.1"
+	// JWTVCIssuanceProfile encodes credentials as in the JWT VC Issuance Profile: `sub`, `jti`, `nbf`, and `exp` are set
+	// from the credential, without `iat`, and the credential is kept whole in the `vc` claim.
+	JWTVCIssuanceProfile = "jwt-vc-issuance"
+)
+
+// JWTClaimsPolicy controls which registered claims of a vc-jwt are set from the credential. `iss` is always set.
+type JWTClaimsPolicy struct {
+	// Subject sets `sub` to the ID of the credential subject.
+	Subject bool
+	// ID sets `jti` to the ID of the credential.
+	ID bool
+	// IssuedAt sets `iat` to the issuance date of the credential.
+	IssuedAt bool
+	// NotBefore sets `nbf` to the issuance date of the credential.
+	NotBefore bool
+	// Expiration sets `exp` to the expiration date of the credential.
+	Expiration bool
+	// KeepProperties keeps the properties claims are set from in the `vc` claim. Otherwise, a property is removed when
+	// a claim it is restored from on parsing is set, so that any policy round trips.
+	KeepProperties bool
+}
+
+// JWTClaimsProfiles are the presets of JWTClaimsPolicy, by name.
+var JWTClaimsProfiles = map[string]JWTClaimsPolicy{
+	VCJWT11Profile:       {Subject: true, ID: true, IssuedAt: true, NotBefore: true, Expiration: true},
+	JWTVCIssuanceProfile: {Subject: true, ID: true, NotBefore: true, Expiration: true, KeepProperties: true},
+}
+
+// GetJWTClaimsPolicy returns the preset policy of a profile, which is VCJWT11Profile when empty.
+func GetJWTClaimsPolicy(profile string) (JWTClaimsPolicy, error) {
+	if profile == "" {
+		profile = VCJWT11Profile
+	}
+	policy, ok := JWTClaimsProfiles[profile]
+	if !ok {
+		return JWTClaimsPolicy{}, errors.Errorf("unsupported jwt claims profile<%s>", profile)
+	}
+	return policy, nil
+}
+
+// claimSet encodes the credential into the claims of a vc-jwt, like integrity.JWTClaimSetFromVC does for VC-JWT 1.1.
+func (p JWTClaimsPolicy) claimSet(cred credential.VerifiableCredential) (jwt.Token, error) {
+	t := jwt.New()
+	if err := t.Set(integrity.NonceProperty, uuid.New().String()); err != nil {
+		return nil, errors.Wrap(err, "setting nonce value")
+	}
+
+	if err := t.Set(jwt.IssuerKey, cred.Issuer); err != nil {
+		return nil, errors.Wrap(err, "setting iss value")
+	}
+	if !p.KeepProperties {
+		cred.Issuer = nil
+	}
+
+	if p.Expiration && cred.ExpirationDate != "" {
+		if err := t.Set(jwt.ExpirationKey, cred.ExpirationDate); err != nil {
+			return nil, errors.Wrap(err, "setting exp value")
+		}
+		if !p.KeepProperties {
+			cred.ExpirationDate = ""
+		}
+	}
+
+	if p.IssuedAt {
+		if err := t.Set(jwt.IssuedAtKey, cred.IssuanceDate); err != nil {
+			return nil, errors.Wrap(err, "setting iat value")
+		}
+	}
+	if p.NotBefore {
+		if err := t.Set(jwt.NotBeforeKey, cred.IssuanceDate); err != nil {
+			return nil, errors.Wrap(err, "setting nbf value")
+		}
+	}
+	// the issuance date is only restored from `iat` on parsing
+	if p.IssuedAt && !p.KeepProperties {
+		cred.IssuanceDate = ""
+	}
+
+	if p.ID && cred.ID != "" {
+		if err := t.Set(jwt.JwtIDKey, cred.ID); err != nil {
+			return nil, errors.Wrap(err, "setting jti value")
+		}
+		if !p.KeepProperties {
+			cred.ID = ""
+		}
+	}
+
+	if subject := cred.CredentialSubject.GetID(); p.Subject && subject != "" {
+		if err := t.Set(jwt.SubjectKey, subject); err != nil {
+			return nil, errors.Wrap(err, "setting sub value")
+		}
+		if !p.KeepProperties {
+			// the subject is shared with the caller's credential
+			cred.CredentialSubject = maps.Clone(cred.CredentialSubject)
+			delete(cred.CredentialSubject, credential.VerifiableCredentialIDProperty)
+		}
+	}
+
+	if err := t.Set(integrity.VCJWTProperty, cred); err != nil {
+		return nil, errors.Wrap(err, "setting credential value")
+	}
+	return t, nil
+}
@@ -0,0 +1,84 @@
+package keyaccess
+
+import (
+	"bytes"
+	"embed"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+)
+
+var (
+	//go:embed context
+	knownContexts embed.FS
+
+	// knownContextFiles maps the URLs of the JSON-LD contexts of the credentials the service issues to local copies,
+	// so that signing and verifying their data integrity proofs does not load them over the network.
+	knownContextFiles = map[string]string{
+		"https://www.w3.org/2018/credentials/v1":           "credentials-v1.jsonld",
+		"https://w3id.org/security/suites/ed25519-2020/v1": "ed25519-signature-2020-v1.jsonld",
+		"https://w3id.org/vc/status-list/2021/v1":          "status-list-2021-v1.jsonld",
+	}
+)
+
+// ErrUndefinedTerm is returned for documents with a term which none of their contexts define. Canonicalization drops
+// such terms, so data integrity proofs would leave them unsigned.
+var ErrUndefinedTerm = errors.New("term is not defined by the contexts of the document")
+
+// CheckTermsDefined returns ErrUndefinedTerm when the document has a term which none of its contexts define. Known
+// contexts are loaded from their local copies, and others over the network.
+func CheckTermsDefined(doc any) error {
+	generic, err := toGenericJSON(doc)
+	if err != nil {
+		return errors.Wrap(err, "preparing document")
+	}
+	options := ld.NewJsonLdOptions("")
+	options.ProcessingMode = ld.JsonLd_1_1
+	options.DocumentLoader = newContextLoader()
+	options.SafeMode = true
+	if _, err = ld.NewJsonLdProcessor().Expand(generic, options); err != nil {
+		var ldErr *ld.JsonLdError
+		if errors.As(err, &ldErr) && ldErr.Code == ld.InvalidProperty {
+			return errors.Wrap(ErrUndefinedTerm, err.Error())
+		}
+		return errors.Wrap(err, "expanding document")
+	}
+	return nil
+}
+
+// HasOnlyKnownContexts returns true when every context which is referenced by URL is loaded from a local copy.
+func HasOnlyKnownContexts(contexts []any) bool {
+	for _, c := range contexts {
+		if url, ok := c.(string); ok {
+			if _, known := knownContextFiles[url]; !known {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// contextLoader loads known JSON-LD contexts from their local copies, and all other documents with its fallback.
+type contextLoader struct {
+	fallback ld.DocumentLoader
+}
+
+func newContextLoader() *contextLoader {
+	return &contextLoader{fallback: ld.NewRFC7324CachingDocumentLoader(nil)}
+}
+
+func (l contextLoader) LoadDocument(url string) (*ld.RemoteDocument, error) {
+	fileName, ok := knownContextFiles[url]
+	if !ok {
+		return l.fallback.LoadDocument(url)
+	}
+	contextBytes, err := knownContexts.ReadFile("context/" + fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading known context<%s>", url)
+	}
+	document, err := ld.DocumentFromReader(bytes.NewReader(contextBytes))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing known context<%s>", url)
+	}
+	return &ld.RemoteDocument{DocumentURL: url, Document: document}, nil
+}
@@ -2,6 +2,7 @@ package keyaccess
 
 import (
 	gocrypto "crypto"
+	"crypto/ed25519"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
@@ -11,18 +12,19 @@ import (
 	"github.com/pkg/errors"
 )
 
-// TODO(gabe) integrate signing for Data Integrity as well in https://github.com/TBD54566975/ssi-service/issues/105
-
-// DataIntegrityKeyAccess represents a key access object for data integrity using the JsonWebSignature2020 suite:
+// DataIntegrityKeyAccess represents a key access object for data integrity. Ed25519 keys use the Ed25519Signature2020
+// suite, https://w3c.github.io/vc-di-eddsa/#ed25519signature2020, and other keys the JsonWebSignature2020 suite,
 // https://w3c.github.io/vc-jws-2020/
 type DataIntegrityKeyAccess struct {
-	Signer      jws2020.JSONWebKeySigner
-	Verifier    jws2020.JSONWebKeyVerifier
+	// ID identifies the controller of the key
+	ID          string
+	Signer      cryptosuite.Signer
+	Verifier    cryptosuite.Verifier
 	CryptoSuite cryptosuite.CryptoSuite
 }
 
-// NewDataIntegrityKeyAccess creates a new DataIntegrityKeyAccess object from an id, key id, and private key, generating both
-// JSON Web Key Signer and Verifier objects.
+// NewDataIntegrityKeyAccess creates a new DataIntegrityKeyAccess object from an id, key id, and private key, generating
+// both Signer and Verifier objects for the suite of the key.
 func NewDataIntegrityKeyAccess(id, kid string, key gocrypto.PrivateKey) (*DataIntegrityKeyAccess, error) {
 	if kid == "" {
 		return nil, errors.New("kid cannot be empty")
@@ -30,6 +32,30 @@ func NewDataIntegrityKeyAccess(id, kid string, key gocrypto.PrivateKey) (*DataIn
 	if key == nil {
 		return nil, errors.New("key cannot be nil")
 	}
+	var privateKey ed25519.PrivateKey
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		privateKey = k
+	case *ed25519.PrivateKey:
+		privateKey = *k
+	default:
+		return newJSONWebSignatureKeyAccess(id, kid, key)
+	}
+	publicKey, ok := privateKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("could not get public key: %s", kid)
+	}
+	return &DataIntegrityKeyAccess{
+		ID:          id,
+		Signer:      NewEd25519Signer(id, kid, privateKey, cryptosuite.AssertionMethod),
+		Verifier:    NewEd25519Verifier(id, kid, publicKey),
+		CryptoSuite: GetEd25519Signature2020Suite(),
+	}, nil
+}
+
+// newJSONWebSignatureKeyAccess creates a DataIntegrityKeyAccess object of the JsonWebSignature2020 suite, for keys
+// without a suite of their own.
+func newJSONWebSignatureKeyAccess(id, kid string, key gocrypto.PrivateKey) (*DataIntegrityKeyAccess, error) {
 	publicKeyJWK, privateKeyJWK, err := jwx.PrivateKeyToPrivateKeyJWK(kid, key)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not convert private key to JWK: %s", kid)
@@ -43,8 +69,9 @@ func NewDataIntegrityKeyAccess(id, kid string, key gocrypto.PrivateKey) (*DataIn
 		return nil, errors.Wrapf(err, "could not create JWK verifier: %s", kid)
 	}
 	return &DataIntegrityKeyAccess{
-		Signer:      *signer,
-		Verifier:    *verifier,
+		ID:          id,
+		Signer:      signer,
+		Verifier:    verifier,
 		CryptoSuite: jws2020.GetJSONWebSignature2020Suite(),
 	}, nil
 }
@@ -59,7 +86,7 @@ func (ka DataIntegrityKeyAccess) Sign(payload cryptosuite.WithEmbeddedProof) (*D
 	if payload == nil {
 		return nil, errors.New("payload cannot be nil")
 	}
-	if err := ka.CryptoSuite.Sign(&ka.Signer, payload); err != nil {
+	if err := ka.CryptoSuite.Sign(ka.Signer, payload); err != nil {
 		return nil, errors.Wrap(err, "signing payload")
 	}
 	signedJSONBytes, err := json.Marshal(payload)
@@ -73,7 +100,7 @@ func (ka DataIntegrityKeyAccess) Verify(payload cryptosuite.WithEmbeddedProof) e
 	if payload == nil {
 		return errors.New("payload cannot be nil")
 	}
-	if err := ka.CryptoSuite.Verify(&ka.Verifier, payload); err != nil {
+	if err := ka.CryptoSuite.Verify(ka.Verifier, payload); err != nil {
 		return errors.Wrap(err, "verifying payload")
 	}
 	return nil
@@ -1,10 +1,12 @@
 package keyaccess
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,6 +31,14 @@ func TestCreateDataIntegrityKeyAccess(t *testing.T) {
 		assert.Empty(tt, ka)
 	})
 
+	t.Run("Create a Key Access object - Not an Ed25519 Key", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateP256Key()
+		assert.NoError(tt, err)
+		ka, err := NewDataIntegrityKeyAccess("test-id", "test-kid", privKey)
+		assert.NoError(tt, err)
+		assert.Equal(tt, jws2020.JSONWebSignature2020, ka.CryptoSuite.SignatureAlgorithm())
+	})
+
 	t.Run("Create a Key Access object - No KID", func(tt *testing.T) {
 		_, privKey, err := crypto.GenerateEd25519Key()
 		assert.NoError(tt, err)
@@ -50,7 +60,7 @@ func TestDataIntegrityKeyAccessSignVerify(t *testing.T) {
 		assert.NotEmpty(tt, ka)
 
 		// sign
-		testCred := getTestCredential(id)
+		testCred := getDataIntegrityTestCredential(id)
 		signedCred, err := ka.Sign(&testCred)
 		assert.NoError(tt, err)
 		assert.NotEmpty(tt, signedCred)
@@ -58,10 +68,21 @@ func TestDataIntegrityKeyAccessSignVerify(t *testing.T) {
 		var cred credential.VerifiableCredential
 		err = json.Unmarshal(signedCred.Data, &cred)
 		assert.NoError(tt, err)
+		proof, err := ed25519Signature2020ProofFromGenericProof(*cred.Proof)
+		assert.NoError(tt, err)
+		assert.Equal(tt, Ed25519Signature2020, proof.Type)
+		assert.Equal(tt, kid, proof.VerificationMethod)
+		assert.True(tt, strings.HasPrefix(proof.ProofValue, "z"))
 
 		// verify
 		err = ka.Verify(&cred)
 		assert.NoError(tt, err)
+
+		// the signature covers the subject of the credential
+		cred.CredentialSubject["happiness"] = map[string]any{"howHappy": "not happy"}
+		err = ka.Verify(&cred)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "invalid Ed25519 signature")
 	})
 
 	t.Run("Sign and Verify Credential - Bad Data", func(tt *testing.T) {
@@ -123,3 +144,23 @@ func TestDataIntegrityKeyAccessSignVerify(t *testing.T) {
 		assert.Contains(t, err.Error(), "not implemented")
 	})
 }
+
+// dataIntegrityTestContext defines the terms of the test credential with a vocabulary, rather than with a remote
+// context, so that signing it only loads the local copies of known contexts.
+var dataIntegrityTestContext = []any{"https://www.w3.org/2018/credentials/v1", map[string]any{"@vocab": "https://example.com/vocab#"}}
+
+func getDataIntegrityTestCredential(issuerDID string) credential.VerifiableCredential {
+	testCred := getTestCredential(issuerDID)
+	testCred.Context = dataIntegrityTestContext
+	return testCred
+}
+
+func TestCheckTermsDefined(t *testing.T) {
+	testCred := getDataIntegrityTestCredential("test-id")
+	assert.NoError(t, CheckTermsDefined(testCred))
+
+	// the terms of the subject are not defined without the test vocabulary
+	testCred.Context = []any{"https://www.w3.org/2018/credentials/v1"}
+	err := CheckTermsDefined(testCred)
+	assert.ErrorIs(t, err, ErrUndefinedTerm)
+}
@@ -0,0 +1,275 @@
+package keyaccess
+
+import (
+	gocrypto "crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/mr-tron/base58"
+	"github.com/piprate/json-gold/ld"
+	"github.com/pkg/errors"
+)
+
+// https://w3c.github.io/vc-di-eddsa/#ed25519signature2020
+
+const (
+	Ed25519Signature2020                 cryptosuite.SignatureType = "Ed25519Signature2020"
+	Ed25519Signature2020Context          string                    = cryptosuite.Ed25519VerificationKey2020Context
+	Ed25519Signature2020SuiteID          string                    = "https://w3id.org/security#Ed25519Signature2020"
+	Ed25519Signature2020Canonicalization string                    = "https://w3id.org/security#URDNA2015"
+	Ed25519Signature2020DigestAlgorithm  gocrypto.Hash             = gocrypto.SHA256
+	ed25519Signature2020SigningAlgorithm string                    = "EdDSA"
+	// multibaseBase58BTCPrefix prefixes proof values, which are multibase encoded with base58btc
+	multibaseBase58BTCPrefix byte = 'z'
+)
+
+var errInvalidProofValue = errors.New("proof value must be a multibase base58btc encoded signature")
+
+// Ed25519Signature2020Suite is the Ed25519Signature2020 data integrity suite, which signs the URDNA2015 canonical
+// forms of a document and its proof options with Ed25519.
+type Ed25519Signature2020Suite struct{}
+
+func GetEd25519Signature2020Suite() cryptosuite.CryptoSuite {
+	return new(Ed25519Signature2020Suite)
+}
+
+var _ cryptosuite.CryptoSuite = (*Ed25519Signature2020Suite)(nil)
+
+func (Ed25519Signature2020Suite) ID() string {
+	return Ed25519Signature2020SuiteID
+}
+
+func (Ed25519Signature2020Suite) Type() cryptosuite.LDKeyType {
+	return cryptosuite.Ed25519VerificationKey2020
+}
+
+func (Ed25519Signature2020Suite) CanonicalizationAlgorithm() string {
+	return Ed25519Signature2020Canonicalization
+}
+
+func (Ed25519Signature2020Suite) MessageDigestAlgorithm() gocrypto.Hash {
+	return Ed25519Signature2020DigestAlgorithm
+}
+
+func (Ed25519Signature2020Suite) SignatureAlgorithm() cryptosuite.SignatureType {
+	return Ed25519Signature2020
+}
+
+func (Ed25519Signature2020Suite) RequiredContexts() []string {
+	return []string{Ed25519Signature2020Context}
+}
+
+func (e Ed25519Signature2020Suite) Sign(s cryptosuite.Signer, p cryptosuite.WithEmbeddedProof) error {
+	proof := Ed25519Signature2020Proof{
+		Type:               e.SignatureAlgorithm(),
+		Created:            sdkutil.GetRFC3339Timestamp(),
+		VerificationMethod: s.GetKeyID(),
+		ProofPurpose:       s.GetProofPurpose(),
+	}
+	tbs, err := e.createVerifyHash(p, proof)
+	if err != nil {
+		return errors.Wrap(err, "create verify hash algorithm failed")
+	}
+	signature, err := s.Sign(tbs)
+	if err != nil {
+		return errors.Wrap(err, "could not sign provable value")
+	}
+	proof.ProofValue = string(multibaseBase58BTCPrefix) + base58.Encode(signature)
+	genericProof := crypto.Proof(proof)
+	p.SetProof(&genericProof)
+	return nil
+}
+
+func (e Ed25519Signature2020Suite) Verify(v cryptosuite.Verifier, p cryptosuite.WithEmbeddedProof) error {
+	proof := p.GetProof()
+	if proof == nil {
+		return errors.New("provable has no proof")
+	}
+	gotProof, err := ed25519Signature2020ProofFromGenericProof(*proof)
+	if err != nil {
+		return errors.Wrap(err, "coercing proof into Ed25519Signature2020 proof")
+	}
+	if gotProof.Type != e.SignatureAlgorithm() {
+		return errors.Errorf("proof type<%s> is not %s", gotProof.Type, e.SignatureAlgorithm())
+	}
+	if len(gotProof.ProofValue) < 2 || gotProof.ProofValue[0] != multibaseBase58BTCPrefix {
+		return errInvalidProofValue
+	}
+	signature, err := base58.Decode(gotProof.ProofValue[1:])
+	if err != nil {
+		return errors.Wrap(errInvalidProofValue, err.Error())
+	}
+
+	// the signature is computed over the provable without its proof, and the proof without its value
+	p.SetProof(nil)
+	defer p.SetProof(proof)
+	gotProof.ProofValue = ""
+
+	tbv, err := e.createVerifyHash(p, *gotProof)
+	if err != nil {
+		return errors.Wrap(err, "create verify hash algorithm failed")
+	}
+	if err = v.Verify(tbv, signature); err != nil {
+		return errors.Wrap(err, "could not verify signature")
+	}
+	return nil
+}
+
+// createVerifyHash returns the SHA-256 digest of the canonical proof options, which carry the contexts of the
+// provable, followed by the SHA-256 digest of the canonical provable.
+func (e Ed25519Signature2020Suite) createVerifyHash(p cryptosuite.WithEmbeddedProof, proof Ed25519Signature2020Proof) ([]byte, error) {
+	contexts, err := cryptosuite.GetContextsFromProvable(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get contexts from provable")
+	}
+	contexts = cryptosuite.EnsureRequiredContexts(contexts, e.RequiredContexts())
+
+	doc, err := toGenericJSON(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "preparing provable")
+	}
+	delete(doc, "proof")
+	proofOptions, err := toGenericJSON(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "preparing proof options")
+	}
+	proofOptions["@context"] = contexts
+
+	canonicalOptions, err := canonicalize(proofOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not canonicalize proof options")
+	}
+	canonicalDoc, err := canonicalize(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not canonicalize provable")
+	}
+	optionsDigest := sha256.Sum256([]byte(canonicalOptions))
+	docDigest := sha256.Sum256([]byte(canonicalDoc))
+	return append(optionsDigest[:], docDigest[:]...), nil
+}
+
+// canonicalize returns the URDNA2015 canonical N-Quads of a JSON-LD document, loading known contexts locally.
+func canonicalize(doc map[string]any) (string, error) {
+	options := ld.NewJsonLdOptions("")
+	options.Format = "application/n-quads"
+	options.Algorithm = ld.AlgorithmURDNA2015
+	options.ProcessingMode = ld.JsonLd_1_1
+	options.DocumentLoader = newContextLoader()
+	normalized, err := ld.NewJsonLdProcessor().Normalize(doc, options)
+	if err != nil {
+		return "", err
+	}
+	canonical, ok := normalized.(string)
+	if !ok {
+		return "", errors.Errorf("unexpected canonical form: %T", normalized)
+	}
+	return canonical, nil
+}
+
+func toGenericJSON(v any) (map[string]any, error) {
+	vBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic map[string]any
+	if err = json.Unmarshal(vBytes, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+type Ed25519Signature2020Proof struct {
+	Type               cryptosuite.SignatureType `json:"type,omitempty"`
+	Created            string                    `json:"created,omitempty"`
+	VerificationMethod string                    `json:"verificationMethod,omitempty"`
+	ProofPurpose       cryptosuite.ProofPurpose  `json:"proofPurpose,omitempty"`
+	ProofValue         string                    `json:"proofValue,omitempty"`
+}
+
+func ed25519Signature2020ProofFromGenericProof(p crypto.Proof) (*Ed25519Signature2020Proof, error) {
+	proofBytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var result Ed25519Signature2020Proof
+	if err = json.Unmarshal(proofBytes, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Ed25519Signer signs Ed25519Signature2020 proofs with an Ed25519 private key.
+type Ed25519Signer struct {
+	// ID identifies the controller of the key, and KID the key
+	ID            string
+	KID           string
+	PrivateKey    ed25519.PrivateKey
+	proofPurpose  cryptosuite.ProofPurpose
+	payloadFormat cryptosuite.PayloadFormat
+}
+
+var _ cryptosuite.Signer = (*Ed25519Signer)(nil)
+
+func NewEd25519Signer(id, kid string, key ed25519.PrivateKey, purpose cryptosuite.ProofPurpose) *Ed25519Signer {
+	return &Ed25519Signer{ID: id, KID: kid, PrivateKey: key, proofPurpose: purpose, payloadFormat: cryptosuite.LDPFormat}
+}
+
+func (s *Ed25519Signer) Sign(tbs []byte) ([]byte, error) {
+	return ed25519.Sign(s.PrivateKey, tbs), nil
+}
+
+func (s *Ed25519Signer) GetKeyID() string {
+	return s.KID
+}
+
+func (*Ed25519Signer) GetSignatureType() cryptosuite.SignatureType {
+	return Ed25519Signature2020
+}
+
+func (*Ed25519Signer) GetSigningAlgorithm() string {
+	return ed25519Signature2020SigningAlgorithm
+}
+
+func (s *Ed25519Signer) SetProofPurpose(purpose cryptosuite.ProofPurpose) {
+	s.proofPurpose = purpose
+}
+
+func (s *Ed25519Signer) GetProofPurpose() cryptosuite.ProofPurpose {
+	return s.proofPurpose
+}
+
+func (s *Ed25519Signer) SetPayloadFormat(format cryptosuite.PayloadFormat) {
+	s.payloadFormat = format
+}
+
+func (s *Ed25519Signer) GetPayloadFormat() cryptosuite.PayloadFormat {
+	return s.payloadFormat
+}
+
+// Ed25519Verifier verifies Ed25519Signature2020 proofs with an Ed25519 public key.
+type Ed25519Verifier struct {
+	ID        string
+	KID       string
+	PublicKey ed25519.PublicKey
+}
+
+var _ cryptosuite.Verifier = (*Ed25519Verifier)(nil)
+
+func NewEd25519Verifier(id, kid string, key ed25519.PublicKey) *Ed25519Verifier {
+	return &Ed25519Verifier{ID: id, KID: kid, PublicKey: key}
+}
+
+func (v *Ed25519Verifier) Verify(message, signature []byte) error {
+	if !ed25519.Verify(v.PublicKey, message, signature) {
+		return errors.New("invalid Ed25519 signature")
+	}
+	return nil
+}
+
+func (v *Ed25519Verifier) GetKeyID() string {
+	return v.KID
+}
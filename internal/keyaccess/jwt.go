@@ -11,6 +11,9 @@ import (
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	"github.com/goccy/go-json"
 	"github.com/lestrrat-go/jwx/jws"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	jwsv2 "github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/pkg/errors"
 )
 
@@ -130,6 +133,71 @@ func (ka JWKKeyAccess) SignVerifiableCredential(cred credential.VerifiableCreden
 	return JWT(tokenBytes).Ptr(), nil
 }
 
+// ReservedJWTClaims are the claims a credential is encoded into when signed as a vc-jwt, which additional claims may
+// not override.
+var ReservedJWTClaims = []string{
+	jwt.IssuerKey, jwt.SubjectKey, jwt.AudienceKey, jwt.ExpirationKey, jwt.NotBeforeKey, jwt.IssuedAtKey, jwt.JwtIDKey,
+	integrity.VCJWTProperty, integrity.VPJWTProperty, integrity.NonceProperty,
+}
+
+// ValidateJWTClaims returns an error when any of the claims is reserved.
+func ValidateJWTClaims(claims map[string]any) error {
+	for _, reserved := range ReservedJWTClaims {
+		if _, ok := claims[reserved]; ok {
+			return fmt.Errorf("claim<%s> is reserved and cannot be set", reserved)
+		}
+	}
+	return nil
+}
+
+// SignVerifiableCredentialWithClaims signs a credential as a vc-jwt, like SignVerifiableCredential, adding the given
+// claims to the top level of the JWT, such as `vct` for interoperability with SD-JWT VCs. Reserved claims are rejected
+// so the credential payload can't be overridden.
+func (ka JWKKeyAccess) SignVerifiableCredentialWithClaims(cred credential.VerifiableCredential, claims map[string]any) (*JWT, error) {
+	if len(claims) == 0 {
+		return ka.SignVerifiableCredential(cred)
+	}
+	return ka.SignVerifiableCredentialWithPolicy(cred, JWTClaimsProfiles[VCJWT11Profile], claims)
+}
+
+// SignVerifiableCredentialWithPolicy signs a credential as a vc-jwt like SignVerifiableCredentialWithClaims, setting
+// the registered claims from the credential as the policy says.
+func (ka JWKKeyAccess) SignVerifiableCredentialWithPolicy(cred credential.VerifiableCredential, policy JWTClaimsPolicy, claims map[string]any) (*JWT, error) {
+	if ka.Signer == nil {
+		return nil, errors.New("cannot sign with nil signer")
+	}
+	if err := cred.IsValid(); err != nil {
+		return nil, errors.New("cannot sign invalid credential")
+	}
+	if cred.Proof != nil {
+		return nil, errors.New("credential cannot already have a proof")
+	}
+	if err := ValidateJWTClaims(claims); err != nil {
+		return nil, err
+	}
+
+	token, err := policy.claimSet(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "building claims from credential")
+	}
+	for claim, value := range claims {
+		if err = token.Set(claim, value); err != nil {
+			return nil, errors.Wrapf(err, "setting claim<%s>", claim)
+		}
+	}
+	headers := jwsv2.NewHeaders()
+	if ka.Signer.KID != "" {
+		if err = headers.Set(jwsv2.KeyIDKey, ka.Signer.KID); err != nil {
+			return nil, errors.Wrap(err, "setting KID protected header")
+		}
+	}
+	tokenBytes, err := jwt.Sign(token, jwt.WithKey(jwa.SignatureAlgorithm(ka.Signer.ALG), ka.Signer.PrivateKey, jwsv2.WithProtectedHeaders(headers)))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing cred")
+	}
+	return JWT(tokenBytes).Ptr(), nil
+}
+
 func (ka JWKKeyAccess) VerifyVerifiableCredential(token JWT) (*credential.VerifiableCredential, error) {
 	if token == "" {
 		return nil, errors.New("token cannot be empty")
@@ -2,9 +2,12 @@ package keyaccess
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
@@ -219,6 +222,115 @@ func TestJWKKeyAccessSignVerifyCredentials(t *testing.T) {
 		assert.Error(tt, err)
 		assert.Contains(tt, err.Error(), "invalid JWT")
 	})
+
+	t.Run("Sign and Verify Credentials - Additional Claims", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		testID := "test-id"
+		kid := "test-kid"
+		assert.NoError(tt, err)
+		ka, err := NewJWKKeyAccess(testID, kid, privKey)
+		assert.NoError(tt, err)
+
+		// sign with additional claims
+		testCred := getTestCredential(testID)
+		signedCred, err := ka.SignVerifiableCredentialWithClaims(copyCred(t, testCred), map[string]any{"vct": "https://example.com/license"})
+		require.NoError(tt, err)
+
+		// verify, and check the claims are at the top level of the JWT alongside the credential
+		verifiedCred, err := ka.VerifyVerifiableCredential(*signedCred)
+		require.NoError(tt, err)
+		assert.Equal(tt, testCred.ID, verifiedCred.ID)
+		_, token, _, err := integrity.ParseVerifiableCredentialFromJWT(signedCred.String())
+		require.NoError(tt, err)
+		vct, ok := token.Get("vct")
+		assert.True(tt, ok)
+		assert.Equal(tt, "https://example.com/license", vct)
+
+		// reserved claims can't override the credential payload
+		for _, reserved := range []string{"iss", "sub", "vc", "jti"} {
+			_, err = ka.SignVerifiableCredentialWithClaims(copyCred(t, testCred), map[string]any{reserved: "override"})
+			assert.ErrorContains(tt, err, "is reserved and cannot be set")
+		}
+	})
+
+	t.Run("Sign and Verify Credentials - Claims Profiles", func(tt *testing.T) {
+		_, privKey, err := crypto.GenerateEd25519Key()
+		require.NoError(tt, err)
+		testID := "test-id"
+		ka, err := NewJWKKeyAccess(testID, "test-kid", privKey)
+		require.NoError(tt, err)
+
+		testCred := getTestCredential(testID)
+		testCred.ExpirationDate = "2030-01-01T19:23:24Z"
+		claimSets := func(token JWT) (map[string]any, map[string]any) {
+			payloadBytes, err := base64.RawURLEncoding.DecodeString(strings.Split(token.String(), ".")[1])
+			require.NoError(tt, err)
+			var payload map[string]any
+			require.NoError(tt, json.Unmarshal(payloadBytes, &payload))
+			vc, ok := payload["vc"].(map[string]any)
+			require.True(tt, ok)
+			return payload, vc
+		}
+		keys := func(claims map[string]any) []string {
+			var claimKeys []string
+			for claim := range claims {
+				claimKeys = append(claimKeys, claim)
+			}
+			return claimKeys
+		}
+
+		tests := []struct {
+			profile  string
+			claims   []string
+			vcClaims []string
+		}{
+			{
+				profile:  "",
+				claims:   []string{"iss", "sub", "jti", "iat", "nbf", "exp", "nonce", "vc"},
+				vcClaims: []string{"@context", "type", "credentialSubject"},
+			},
+			{
+				profile:  JWTVCIssuanceProfile,
+				claims:   []string{"iss", "sub", "jti", "nbf", "exp", "nonce", "vc"},
+				vcClaims: []string{"@context", "id", "type", "issuer", "issuanceDate", "expirationDate", "credentialSubject"},
+			},
+		}
+		for _, test := range tests {
+			policy, err := GetJWTClaimsPolicy(test.profile)
+			require.NoError(tt, err)
+			signedCred, err := ka.SignVerifiableCredentialWithPolicy(copyCred(t, testCred), policy, nil)
+			require.NoError(tt, err)
+
+			claims, vc := claimSets(*signedCred)
+			assert.ElementsMatch(tt, test.claims, keys(claims), test.profile)
+			assert.ElementsMatch(tt, test.vcClaims, keys(vc), test.profile)
+			assert.Equal(tt, testCred.ID, claims["jti"])
+			assert.Equal(tt, testCred.CredentialSubject.GetID(), claims["sub"])
+
+			// credentials round trip whatever the profile they are signed with
+			verifiedCred, err := ka.VerifyVerifiableCredential(*signedCred)
+			require.NoError(tt, err)
+			assert.Equal(tt, testCred.ID, verifiedCred.ID)
+			assert.Equal(tt, testCred.Issuer, verifiedCred.Issuer)
+			assert.Equal(tt, testCred.IssuanceDate, verifiedCred.IssuanceDate)
+			assert.Equal(tt, testCred.ExpirationDate, verifiedCred.ExpirationDate)
+			assert.Equal(tt, testCred.CredentialSubject.GetID(), verifiedCred.CredentialSubject.GetID())
+		}
+
+		// properties are kept in the vc claim when their claim is not set
+		signedCred, err := ka.SignVerifiableCredentialWithPolicy(copyCred(t, testCred), JWTClaimsPolicy{NotBefore: true}, nil)
+		require.NoError(tt, err)
+		claims, vc := claimSets(*signedCred)
+		assert.ElementsMatch(tt, []string{"iss", "nbf", "nonce", "vc"}, keys(claims))
+		assert.ElementsMatch(tt, []string{"@context", "id", "type", "issuanceDate", "expirationDate", "credentialSubject"}, keys(vc))
+		verifiedCred, err := ka.VerifyVerifiableCredential(*signedCred)
+		require.NoError(tt, err)
+		assert.Equal(tt, testCred.IssuanceDate, verifiedCred.IssuanceDate)
+		assert.Equal(tt, testCred.CredentialSubject.GetID(), verifiedCred.CredentialSubject.GetID())
+
+		_, err = GetJWTClaimsPolicy("jwt-vc-2.0")
+		assert.ErrorContains(tt, err, "unsupported jwt claims profile<jwt-vc-2.0>")
+	})
 }
 
 func TestJWKKeyAccessSignVerifyPresentations(t *testing.T) {
@@ -309,11 +421,11 @@ func getTestCredential(issuerDID string) credential.VerifiableCredential {
 }
 
 func getDataIntegrityTestPresentation(ka DataIntegrityKeyAccess) credential.VerifiablePresentation {
-	knownContext := []string{"https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1"}
+	knownContext := dataIntegrityTestContext
 	knownID := uuid.NewString()
 	knownType := []string{"VerifiablePresentation", "HappyPresentation"}
 	knownHolder := "did:example:ebfeb1f712ebc6f1c276e12ec21"
-	testCredential := getTestCredential(ka.Signer.ID)
+	testCredential := getDataIntegrityTestCredential(ka.ID)
 	signedCred, _ := ka.Sign(&testCredential)
 	return credential.VerifiablePresentation{
 		Context:              knownContext,
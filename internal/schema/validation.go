@@ -0,0 +1,278 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemaURL is a placeholder needed to compile a schema from a string
+const schemaURL = "schema.json"
+
+// IsCredentialValidForJSONSchema behaves like the ssi-sdk function of the same name, except that the numbers of the
+// credential are validated as json.Number rather than float64. Integer claims above 2^53 are therefore validated with
+// their exact value. The `format` keywords with one of the asserted formats fail validation on malformed values, even
+// for drafts where formats are only annotations.
+func IsCredentialValidForJSONSchema(cred credential.VerifiableCredential, s schema.JSONSchema, assertedFormats ...string) error {
+	if !schema.IsSupportedVCJSONSchemaType(cred.CredentialSchema.Type) {
+		return fmt.Errorf("credential schema type<%s> is not supported", cred.CredentialSchema.Type)
+	}
+	credBytes, err := json.Marshal(cred)
+	if err != nil {
+		return errors.Wrap(err, "marshalling credential")
+	}
+	return IsCredentialJSONValidForJSONSchema(credBytes, s, assertedFormats...)
+}
+
+// IsCredentialJSONValidForJSONSchema is like IsCredentialValidForJSONSchema, for a credential in JSON. When its
+// `credentialSubject` is an array of subjects, the credential is validated once for each of them, and the errors of
+// all the subjects are reported with their index, such as `credentialSubject[2].email: ...`. A credential which is not
+// valid fails with a ValidationError listing each violation.
+func IsCredentialJSONValidForJSONSchema(credJSON []byte, s schema.JSONSchema, assertedFormats ...string) error {
+	if !schema.IsSupportedJSONSchemaVersion(s.Schema()) {
+		return fmt.Errorf("schema version<%s> is not supported", s.Schema())
+	}
+	schemaBytes, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "marshalling schema")
+	}
+	compiled, err := compileSchema(s, string(schemaBytes), assertedFormats)
+	if err != nil {
+		return errors.Wrap(err, "schema is not valid")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(credJSON))
+	decoder.UseNumber()
+	var cred any
+	if err = decoder.Decode(&cred); err != nil {
+		return errors.Wrap(err, "decoding credential")
+	}
+	credMap, ok := cred.(map[string]any)
+	if !ok {
+		return errors.New("credential is not a JSON object")
+	}
+	var schemaDoc any
+	if err = json.Unmarshal(schemaBytes, &schemaDoc); err != nil {
+		return errors.Wrap(err, "decoding schema")
+	}
+	subjects, ok := credMap["credentialSubject"].([]any)
+	if !ok {
+		if err = compiled.Validate(cred); err != nil {
+			return &ValidationError{
+				Violations: violations(err, schemaDoc, cred, -1),
+				Err:        errors.Wrap(err, "credential not valid for schema"),
+			}
+		}
+		return nil
+	}
+
+	var failures []string
+	var subjectViolations []Violation
+	for i, subject := range subjects {
+		// validate a copy of the credential with the single subject, since the schema describes one
+		singleSubject := make(map[string]any, len(credMap))
+		for k, v := range credMap {
+			singleSubject[k] = v
+		}
+		singleSubject["credentialSubject"] = subject
+		if err = compiled.Validate(singleSubject); err != nil {
+			failures = append(failures, subjectFailures(i, err)...)
+			subjectViolations = append(subjectViolations, violations(err, schemaDoc, singleSubject, i)...)
+		}
+	}
+	if len(failures) > 0 {
+		return &ValidationError{
+			Violations: subjectViolations,
+			Err:        fmt.Errorf("credential not valid for schema: %s", strings.Join(failures, "; ")),
+		}
+	}
+	return nil
+}
+
+// Violation is a value of a credential which fails a keyword of its schema.
+type Violation struct {
+	// Pointer is the JSON pointer of the value within the credential, such as `/credentialSubject/age`. For missing
+	// required claims, it is the pointer the claim is missing at.
+	Pointer string `json:"pointer"`
+	// Keyword is the keyword of the schema the value fails, such as `type` or `required`.
+	Keyword string `json:"keyword"`
+	// Expected is the value of the keyword in the schema, such as `integer`, when it can be resolved.
+	Expected any `json:"expected,omitempty"`
+	// Actual is the offending value, absent for missing claims.
+	Actual any `json:"actual,omitempty"`
+}
+
+// ValidationError is returned when a credential is not valid for its schema, along with each violation found.
+type ValidationError struct {
+	Violations []Violation
+	Err        error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// SchemaViolations returns the violations of the schema by the credential.
+func (e *ValidationError) SchemaViolations() any {
+	return e.Violations
+}
+
+// violations returns a violation for each failure of the validation of a credential, whose subject is the subject at
+// the given index of the credential when it is not negative.
+func violations(err error, schemaDoc, cred any, subjectIndex int) []Violation {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+	var found []Violation
+	for _, leaf := range validationLeaves(validationErr) {
+		keywordPath := strings.Split(leaf.KeywordLocation, "/")
+		keyword := unescapePointerToken(keywordPath[len(keywordPath)-1])
+		var expected any
+		if _, schemaPointer, ok := strings.Cut(leaf.AbsoluteKeywordLocation, "#"); ok {
+			expected, _ = resolvePointer(schemaDoc, schemaPointer)
+		}
+		actual, _ := resolvePointer(cred, leaf.InstanceLocation)
+		pointer := leaf.InstanceLocation
+		if rest, ok := strings.CutPrefix(pointer, "/credentialSubject"); ok && subjectIndex >= 0 {
+			pointer = fmt.Sprintf("/credentialSubject/%d%s", subjectIndex, rest)
+		}
+
+		// each missing claim is reported at its own pointer
+		required, isRequired := expected.([]any)
+		object, isObject := actual.(map[string]any)
+		if keyword == "required" && isRequired && isObject {
+			for _, claim := range required {
+				name, ok := claim.(string)
+				if _, present := object[name]; !ok || present {
+					continue
+				}
+				found = append(found, Violation{Pointer: pointer + "/" + escapePointerToken(name), Keyword: keyword, Expected: expected})
+			}
+			continue
+		}
+		found = append(found, Violation{Pointer: pointer, Keyword: keyword, Expected: expected, Actual: actual})
+	}
+	return found
+}
+
+// resolvePointer returns the value at a JSON pointer within a JSON document.
+func resolvePointer(doc any, pointer string) (any, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = unescapePointerToken(token)
+		switch v := doc.(type) {
+		case map[string]any:
+			child, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			doc = child
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			doc = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// subjectFailures describes each failure of the validation of the subject at the given index, with the path of the
+// value which failed within the subject.
+func subjectFailures(index int, err error) []string {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []string{fmt.Sprintf("credentialSubject[%d]: %s", index, err)}
+	}
+	var failures []string
+	for _, leaf := range validationLeaves(validationErr) {
+		location := fmt.Sprintf("credentialSubject[%d]", index)
+		if rest, ok := strings.CutPrefix(leaf.InstanceLocation, "/credentialSubject"); ok {
+			location += strings.ReplaceAll(rest, "/", ".")
+		} else {
+			// the failure is outside the subject, such as a claim of the credential itself
+			location += fmt.Sprintf(" at %q", leaf.InstanceLocation)
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", location, leaf.Message))
+	}
+	return failures
+}
+
+// validationLeaves returns the errors without causes within a validation error, which are the failures of specific
+// values rather than of the schemas containing them.
+func validationLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, validationLeaves(cause)...)
+	}
+	return leaves
+}
+
+// ValidateFormatAssertions checks that each format is one the validator knows how to assert.
+func ValidateFormatAssertions(formats []string) error {
+	for _, format := range formats {
+		if _, ok := jsonschema.Formats[format]; !ok {
+			return fmt.Errorf("format<%s> cannot be asserted", format)
+		}
+	}
+	return nil
+}
+
+func compileSchema(s schema.JSONSchema, schemaJSON string, assertedFormats []string) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if len(assertedFormats) > 0 && !assertsAllFormats(s) {
+		compiler.AssertFormat = true
+		// only the asserted formats are validated, the others remain annotations
+		asserted := make(map[string]bool, len(assertedFormats))
+		for _, format := range assertedFormats {
+			asserted[format] = true
+		}
+		for format := range jsonschema.Formats {
+			if !asserted[format] {
+				compiler.Formats[format] = func(any) bool { return true }
+			}
+		}
+	}
+	if err := compiler.AddResource(schemaURL, strings.NewReader(schemaJSON)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(schemaURL)
+}
+
+// assertsAllFormats is true for the drafts before 2019-09, in which every known format is an assertion.
+func assertsAllFormats(s schema.JSONSchema) bool {
+	for _, draft := range []string{"draft-04", "draft-06", "draft-07"} {
+		if strings.Contains(s.Schema(), draft) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCredentialJSONValidForJSONSchema(t *testing.T) {
+	emailSchema := schema.JSONSchema{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type":    "object",
+		"properties": map[string]any{
+			"credentialSubject": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"email": map[string]any{"type": "string", "format": "email"},
+				},
+				"required": []any{"email"},
+			},
+		},
+		"required": []any{"credentialSubject"},
+	}
+	credentialWithSubject := func(subject string) []byte {
+		return []byte(`{"issuer":"did:abc:123","credentialSubject":` + subject + `}`)
+	}
+
+	t.Run("single subject", func(tt *testing.T) {
+		err := IsCredentialJSONValidForJSONSchema(credentialWithSubject(`{"id":"did:abc:456","email":"jack@example.com"}`), emailSchema)
+		assert.NoError(tt, err)
+
+		err = IsCredentialJSONValidForJSONSchema(credentialWithSubject(`{"id":"did:abc:456"}`), emailSchema)
+		assert.ErrorContains(tt, err, "credential not valid for schema")
+	})
+
+	t.Run("array of subjects", func(tt *testing.T) {
+		subjects := `[{"id":"did:abc:1","email":"a@example.com"},{"id":"did:abc:2","email":"b@example.com"}]`
+		err := IsCredentialJSONValidForJSONSchema(credentialWithSubject(subjects), emailSchema)
+		assert.NoError(tt, err)
+
+		// every invalid subject is reported with its index
+		subjects = `[{"id":"did:abc:1","email":"a@example.com"},{"id":"did:abc:2"},{"id":"did:abc:3","email":42}]`
+		err = IsCredentialJSONValidForJSONSchema(credentialWithSubject(subjects), emailSchema)
+		require.Error(tt, err)
+		assert.NotContains(tt, err.Error(), "credentialSubject[0]")
+		assert.Contains(tt, err.Error(), "credentialSubject[1]: missing properties: 'email'")
+		assert.Contains(tt, err.Error(), "credentialSubject[2].email: expected string, but got number")
+	})
+
+	t.Run("asserted formats apply to each subject", func(tt *testing.T) {
+		subjects := `[{"id":"did:abc:1","email":"a@example.com"},{"id":"did:abc:2","email":"not an email"}]`
+		err := IsCredentialJSONValidForJSONSchema(credentialWithSubject(subjects), emailSchema)
+		assert.NoError(tt, err)
+
+		err = IsCredentialJSONValidForJSONSchema(credentialWithSubject(subjects), emailSchema, "email")
+		assert.ErrorContains(tt, err, "credentialSubject[1].email: 'not an email' is not valid 'email'")
+	})
+
+	t.Run("violations", func(tt *testing.T) {
+		err := IsCredentialJSONValidForJSONSchema(credentialWithSubject(`{"id":"did:abc:456","email":42}`), emailSchema)
+		var validationErr *ValidationError
+		require.ErrorAs(tt, err, &validationErr)
+		require.Len(tt, validationErr.Violations, 1)
+		assert.Equal(tt, Violation{Pointer: "/credentialSubject/email", Keyword: "type", Expected: "string", Actual: json.Number("42")}, validationErr.Violations[0])
+
+		err = IsCredentialJSONValidForJSONSchema(credentialWithSubject(`{"id":"did:abc:456"}`), emailSchema)
+		require.ErrorAs(tt, err, &validationErr)
+		require.Len(tt, validationErr.Violations, 1)
+		assert.Equal(tt, "/credentialSubject/email", validationErr.Violations[0].Pointer)
+		assert.Equal(tt, "required", validationErr.Violations[0].Keyword)
+		assert.Equal(tt, []any{"email"}, validationErr.Violations[0].Expected)
+		assert.Nil(tt, validationErr.Violations[0].Actual)
+
+		// the violations of an array of subjects point to the subject
+		subjects := `[{"id":"did:abc:1","email":"a@example.com"},{"id":"did:abc:2"},{"id":"did:abc:3","email":42}]`
+		err = IsCredentialJSONValidForJSONSchema(credentialWithSubject(subjects), emailSchema)
+		require.ErrorAs(tt, err, &validationErr)
+		require.Len(tt, validationErr.Violations, 2)
+		assert.Equal(tt, "/credentialSubject/1/email", validationErr.Violations[0].Pointer)
+		assert.Equal(tt, "required", validationErr.Violations[0].Keyword)
+		assert.Equal(tt, "/credentialSubject/2/email", validationErr.Violations[1].Pointer)
+		assert.Equal(tt, "type", validationErr.Violations[1].Keyword)
+	})
+}
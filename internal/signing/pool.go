@@ -0,0 +1,117 @@
+// Package signing bounds the concurrency of signing operations, so that spikes of them fail fast instead of
+// saturating the CPU until every request times out.
+package signing
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+)
+
+// RetryAfter is how long clients are asked to wait before retrying an operation rejected by a busy pool.
+const RetryAfter = time.Second
+
+// BusyError is returned when a signing operation is rejected because the pool and its queue are full.
+type BusyError struct {
+	QueueDepth int
+}
+
+func (e BusyError) Error() string {
+	return fmt.Sprintf("server busy: %d signing operations are already waiting", e.QueueDepth)
+}
+
+// RetryAfter is how long the client should wait before retrying.
+func (e BusyError) RetryAfter() time.Duration {
+	return RetryAfter
+}
+
+// Pool runs signing operations with bounded concurrency. Operations wait for a free worker while fewer than the queue
+// depth are waiting, and are rejected with a BusyError otherwise. A nil Pool runs every operation right away.
+type Pool struct {
+	workers    chan struct{}
+	queueDepth int
+	waiting    atomic.Int64
+
+	depth    metric.Int64UpDownCounter
+	waitTime metric.Float64Histogram
+}
+
+// NewPool returns a pool of the given size, or nil when the size is 0, so that signing is not bounded.
+func NewPool(size, queueDepth int) (*Pool, error) {
+	if size < 0 || queueDepth < 0 {
+		return nil, errors.New("signing pool size and queue depth cannot be negative")
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	meter := otel.Meter(config.ServiceName)
+	depth, err := meter.Int64UpDownCounter(
+		"ssi_service.signing.queue_depth",
+		metric.WithDescription("Number of signing operations waiting for a worker of the signing pool"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating signing queue depth metric")
+	}
+	waitTime, err := meter.Float64Histogram(
+		"ssi_service.signing.wait_time",
+		metric.WithDescription("Time signing operations waited for a worker of the signing pool"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating signing wait time metric")
+	}
+	return &Pool{
+		workers:    make(chan struct{}, size),
+		queueDepth: queueDepth,
+		depth:      depth,
+		waitTime:   waitTime,
+	}, nil
+}
+
+// Do runs the operation once a worker is free. It returns a BusyError without running it when the queue is full, and
+// the error of the context when it is done before a worker is free.
+func (p *Pool) Do(ctx context.Context, operation func() error) error {
+	if p == nil {
+		return operation()
+	}
+
+	start := time.Now()
+	select {
+	case p.workers <- struct{}{}:
+	default:
+		if err := p.wait(ctx); err != nil {
+			return err
+		}
+	}
+	defer func() { <-p.workers }()
+	p.waitTime.Record(ctx, time.Since(start).Seconds())
+
+	return operation()
+}
+
+// wait queues for a free worker, unless the queue is full.
+func (p *Pool) wait(ctx context.Context) error {
+	if p.waiting.Add(1) > int64(p.queueDepth) {
+		p.waiting.Add(-1)
+		return BusyError{QueueDepth: p.queueDepth}
+	}
+	p.depth.Add(ctx, 1)
+	defer func() {
+		p.waiting.Add(-1)
+		p.depth.Add(ctx, -1)
+	}()
+
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "waiting for a signing worker")
+	}
+}
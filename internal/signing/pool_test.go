@@ -0,0 +1,115 @@
+package signing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPool(t *testing.T) {
+	pool, err := NewPool(0, 10)
+	assert.NoError(t, err)
+	assert.Nil(t, pool)
+
+	// a nil pool runs operations right away
+	ran := false
+	assert.NoError(t, pool.Do(context.Background(), func() error {
+		ran = true
+		return nil
+	}))
+	assert.True(t, ran)
+
+	_, err = NewPool(-1, 10)
+	assert.Error(t, err)
+	_, err = NewPool(1, -1)
+	assert.Error(t, err)
+}
+
+func TestPoolRejectsBeyondCapacity(t *testing.T) {
+	const size, queueDepth = 2, 3
+	pool, err := NewPool(size, queueDepth)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int64
+	operation := func() error {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return nil
+	}
+
+	// fill the workers and the queue
+	var wg sync.WaitGroup
+	accepted := make(chan error, size+queueDepth)
+	for i := 0; i < size+queueDepth; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			accepted <- pool.Do(context.Background(), operation)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return running.Load() == size && pool.waiting.Load() == queueDepth
+	}, 5*time.Second, time.Millisecond)
+
+	// operations beyond capacity fail fast
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+		err = pool.Do(context.Background(), operation)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		var busyErr BusyError
+		require.True(t, errors.As(err, &busyErr), err)
+		assert.Equal(t, queueDepth, busyErr.QueueDepth)
+		assert.Equal(t, RetryAfter, busyErr.RetryAfter())
+	}
+
+	// while the others complete, without exceeding the size of the pool
+	close(release)
+	wg.Wait()
+	close(accepted)
+	for err = range accepted {
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, size, maxRunning.Load())
+	assert.Zero(t, pool.waiting.Load())
+
+	// the pool is free again
+	assert.NoError(t, pool.Do(context.Background(), func() error { return nil }))
+}
+
+func TestPoolStopsWaitingWhenContextIsDone(t *testing.T) {
+	pool, err := NewPool(1, 1)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		_ = pool.Do(context.Background(), func() error {
+			<-release
+			return nil
+		})
+	}()
+	require.Eventually(t, func() bool { return len(pool.workers) == 1 }, 5*time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = pool.Do(ctx, func() error {
+		t.Fatal("operation should not run")
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, pool.waiting.Load())
+}
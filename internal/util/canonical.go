@@ -0,0 +1,130 @@
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/goccy/go-json"
+	"github.com/gowebpki/jcs"
+	"github.com/pkg/errors"
+)
+
+// CanonicalJSON returns the JSON representation of the value canonicalized with the JSON Canonicalization Scheme
+// (RFC 8785): object keys are sorted, insignificant whitespace is removed, and numbers are formatted the same way
+// whatever their representation, so that the same logical value always serializes to the same bytes. Unlike RFC 8785,
+// integers which a double cannot represent exactly keep all their digits, so that large integer claims keep their
+// precision.
+func CanonicalJSON(value any) ([]byte, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling value to canonicalize")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(valueBytes))
+	decoder.UseNumber()
+	var decoded any
+	if err = decoder.Decode(&decoded); err != nil {
+		return nil, errors.Wrap(err, "decoding value to canonicalize")
+	}
+	var canonical bytes.Buffer
+	if err = writeCanonicalJSON(&canonical, decoded); err != nil {
+		return nil, errors.Wrap(err, "canonicalizing value")
+	}
+	return canonical.Bytes(), nil
+}
+
+// writeCanonicalJSON writes a value decoded with json.Number numbers, sorting the keys of objects by their UTF-16 code
+// units as RFC 8785 does.
+func writeCanonicalJSON(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, element); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	default:
+		// strings and literals are canonicalized as they are by RFC 8785, within an array since it cannot parse
+		// literals at the end of its input
+		scalarBytes, err := json.Marshal([]any{v})
+		if err != nil {
+			return err
+		}
+		canonical, err := jcs.Transform(scalarBytes)
+		if err != nil {
+			return err
+		}
+		buf.Write(canonical[1 : len(canonical)-1])
+	}
+	return nil
+}
+
+// canonicalNumber formats a number as RFC 8785 does, unless it is an integer which a double cannot represent exactly,
+// whose digits are kept.
+func canonicalNumber(number json.Number) (string, error) {
+	exact, ok := new(big.Rat).SetString(number.String())
+	if !ok {
+		return "", errors.Errorf("invalid number<%s>", number)
+	}
+	double, isExact := exact.Float64()
+	if !isExact && exact.IsInt() {
+		return exact.Num().String(), nil
+	}
+	return jcs.NumberToJSON(double)
+}
+
+// lessUTF16 returns whether a sorts before b when compared by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	aUnits, bUnits := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(aUnits) && i < len(bUnits); i++ {
+		if aUnits[i] != bUnits[i] {
+			return aUnits[i] < bUnits[i]
+		}
+	}
+	return len(aUnits) < len(bUnits)
+}
+
+// ContentHash returns the hex encoded SHA-256 hash of the canonical JSON representation of the value, which is the
+// same for any two values which are logically equal.
+func ContentHash(value any) (string, error) {
+	canonicalBytes, err := CanonicalJSON(value)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(canonicalBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	cred := map[string]any{
+		"id":     "urn:uuid:123",
+		"type":   []any{"VerifiableCredential", "EmployeeCredential"},
+		"issuer": "did:abc:123",
+		"credentialSubject": map[string]any{
+			"id":      "did:abc:456",
+			"address": map[string]any{"street": "1 Main St", "city": "Springfield"},
+			"scores":  []any{3, 1.50, 1e21, map[string]any{"b": 2, "a": 1}},
+		},
+	}
+
+	canonical, err := CanonicalJSON(cred)
+	require.NoError(t, err)
+	assert.Equal(t, `{"credentialSubject":{"address":{"city":"Springfield","street":"1 Main St"},"id":"did:abc:456",`+
+		`"scores":[3,1.5,1e+21,{"a":1,"b":2}]},"id":"urn:uuid:123","issuer":"did:abc:123",`+
+		`"type":["VerifiableCredential","EmployeeCredential"]}`, string(canonical))
+
+	hash, err := ContentHash(cred)
+	require.NoError(t, err)
+
+	t.Run("stable across marshal cycles", func(t *testing.T) {
+		value := any(cred)
+		for i := 0; i < 3; i++ {
+			valueBytes, err := json.MarshalIndent(value, "", "  ")
+			require.NoError(t, err)
+			var roundTripped any
+			require.NoError(t, json.Unmarshal(valueBytes, &roundTripped))
+
+			roundTrippedCanonical, err := CanonicalJSON(roundTripped)
+			require.NoError(t, err)
+			assert.Equal(t, string(canonical), string(roundTrippedCanonical))
+			roundTrippedHash, err := ContentHash(roundTripped)
+			require.NoError(t, err)
+			assert.Equal(t, hash, roundTrippedHash)
+			value = roundTripped
+		}
+	})
+
+	t.Run("independent of key order and number formatting", func(t *testing.T) {
+		reordered := json.RawMessage(`{
+			"type": ["VerifiableCredential", "EmployeeCredential"],
+			"issuer": "did:abc:123",
+			"credentialSubject": {
+				"scores": [3.0, 15e-1, 1000000000000000000000, {"a": 1, "b": 2.00}],
+				"id": "did:abc:456",
+				"address": {"street": "1 Main St", "city": "Springfield"}
+			},
+			"id": "urn:uuid:123"
+		}`)
+		reorderedHash, err := ContentHash(reordered)
+		require.NoError(t, err)
+		assert.Equal(t, hash, reorderedHash)
+	})
+
+	t.Run("changed content", func(t *testing.T) {
+		changed, err := ContentHash(map[string]any{"id": "urn:uuid:124"})
+		require.NoError(t, err)
+		assert.NotEqual(t, hash, changed)
+	})
+
+	t.Run("large integers keep their precision", func(t *testing.T) {
+		canonical, err := CanonicalJSON(json.RawMessage(`{"accountNumber": 9007199254740993, "limit": 9007199254740992.0}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"accountNumber":9007199254740993,"limit":9007199254740992}`, string(canonical))
+	})
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// RedactedValue replaces the values at redacted JSON paths.
+const RedactedValue = "***"
+
+// RedactJSONPaths returns the JSON representation of the value, with the values at the given dot separated paths, such
+// as "credentialSubject.email", replaced with RedactedValue. Arrays along a path are redacted element by element, so
+// that the path applies to each of an array of subjects. Paths which are absent are ignored.
+func RedactJSONPaths(value any, paths []string) ([]byte, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling value to redact")
+	}
+	if len(paths) == 0 {
+		return valueBytes, nil
+	}
+	var generic any
+	if err = json.Unmarshal(valueBytes, &generic); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling value to redact")
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		redactPath(generic, strings.Split(path, "."))
+	}
+	return json.Marshal(generic)
+}
+
+func redactPath(value any, path []string) {
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = RedactedValue
+			return
+		}
+		redactPath(child, path[1:])
+	case []any:
+		for _, element := range v {
+			redactPath(element, path)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactJSONPaths(t *testing.T) {
+	cred := map[string]any{
+		"id": "urn:uuid:123",
+		"credentialSubject": map[string]any{
+			"id":      "did:abc:456",
+			"email":   "jack@example.com",
+			"address": map[string]any{"street": "1 Main St", "city": "Springfield"},
+		},
+	}
+
+	redacted, err := RedactJSONPaths(cred, []string{"credentialSubject.email", "credentialSubject.address.street", "credentialSubject.phone", ""})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "urn:uuid:123",
+		"credentialSubject": {
+			"id": "did:abc:456",
+			"email": "***",
+			"address": {"street": "***", "city": "Springfield"}
+		}
+	}`, string(redacted))
+
+	// the original value is left as is
+	assert.Equal(t, "jack@example.com", cred["credentialSubject"].(map[string]any)["email"])
+
+	// whole objects are redacted, and paths apply to each element of arrays
+	subjects := map[string]any{
+		"credentialSubject": []any{
+			map[string]any{"id": "did:abc:1", "email": "a@example.com"},
+			map[string]any{"id": "did:abc:2"},
+		},
+		"evidence": map[string]any{"document": "passport"},
+	}
+	redacted, err = RedactJSONPaths(subjects, []string{"credentialSubject.email", "evidence"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"credentialSubject": [{"id": "did:abc:1", "email": "***"}, {"id": "did:abc:2"}],
+		"evidence": "***"
+	}`, string(redacted))
+
+	// without paths, the value is not redacted
+	redacted, err = RedactJSONPaths(cred, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(redacted), "jack@example.com")
+}
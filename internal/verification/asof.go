@@ -0,0 +1,106 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/validation"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	didint "github.com/tbd54566975/ssi-service/internal/did"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// asOfOption is the validation option of the time the validity period of a credential is checked at.
+const asOfOption validation.OptionKey = "asOf"
+
+// AsOf returns a copy of the verifier which checks the validity period of credentials, and the time claims of the JWTs
+// securing them, at the given time rather than at the current time. A credential issued after that time fails
+// verification. Signatures are still checked with the current DID documents of their signers, since DIDs are not
+// resolved as of a past version.
+func (v Verifier) AsOf(at time.Time) *Verifier {
+	v.at = &at
+	return &v
+}
+
+// validityPeriodValidator replaces the expiry check of the sdk, which checks expiry at the current time, with one
+// checking the validity period of a credential at the time of the verifier.
+func validityPeriodValidator(validators []validation.Validator) []validation.Validator {
+	replaced := make([]validation.Validator, 0, len(validators))
+	for _, validator := range validators {
+		if validator.ID == "Expiry Check" {
+			validator.ValidateFunc = validateValidityPeriod
+		}
+		replaced = append(replaced, validator)
+	}
+	return replaced
+}
+
+// validateValidityPeriod checks a credential has not expired, at the time of the asOfOption when it is given, or at
+// the current time otherwise. When the option is given, the credential must also have been issued by then.
+func validateValidityPeriod(cred credsdk.VerifiableCredential, opts ...validation.Option) error {
+	at := time.Now()
+	asOf, err := validation.GetValidationOption(opts, asOfOption)
+	if err == nil {
+		at = asOf.(time.Time)
+		issuedAt, err := time.Parse(time.RFC3339, cred.IssuanceDate)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse issuance date: %s", cred.IssuanceDate)
+		}
+		if issuedAt.After(at) {
+			return fmt.Errorf("credential was issued at %s, after %s", issuedAt.String(), at.String())
+		}
+	}
+	if cred.ExpirationDate == "" {
+		return nil
+	}
+	expiryTime, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse expiry date: %s", cred.ExpirationDate)
+	}
+	if expiryTime.Before(at) {
+		return fmt.Errorf("credential has expired as of %s", expiryTime.String())
+	}
+	return nil
+}
+
+// verifyJWT checks the signature of a JWT with the verifier, along with its time claims, which are checked at the
+// time of the verifier when it has one.
+func (v Verifier) verifyJWT(verifier *jwx.Verifier, token keyaccess.JWT) error {
+	if v.at == nil {
+		return verifier.Verify(token.String())
+	}
+	if err := verifier.VerifyJWS(token.String()); err != nil {
+		return err
+	}
+	at := *v.at
+	clock := jwt.WithClock(jwt.ClockFunc(func() time.Time { return at }))
+	if _, err := jwt.Parse([]byte(token.String()), jwt.WithVerify(false), jwt.WithValidate(true), clock); err != nil {
+		return errors.Wrapf(err, "validating JWT as of %s", at.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// verifyJWTFromDID checks a JWT is signed with the key of the verification method of a DID, like
+// did.VerifyTokenFromDID, checking its time claims at the time of the verifier when it has one.
+func (v Verifier) verifyJWTFromDID(ctx context.Context, did, kid string, token keyaccess.JWT) error {
+	if v.at == nil {
+		return didint.VerifyTokenFromDID(ctx, v.didResolver, did, kid, token)
+	}
+	pubKey, err := didint.ResolveKeyForDID(ctx, v.didResolver, did, kid)
+	if err != nil {
+		return err
+	}
+	verifier, err := jwx.NewJWXVerifier(did, kid, pubKey)
+	if err != nil {
+		return errors.Wrapf(err, "constructing verifier for key<%s>", kid)
+	}
+	if err = v.verifyJWT(verifier, token); err != nil {
+		return errors.Wrapf(err, "verifying token signed with key<%s>", kid)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package verification
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+const (
+	// DelegationCredentialType is the type of the credentials by which their issuer, the delegator, authorizes the DID
+	// of their subject, the delegate, to sign credentials naming the delegator as their issuer.
+	DelegationCredentialType = "DelegationCredential"
+
+	// DelegationClaim is the claim of a credential JWT signed by a delegate, holding the delegation credential JWT which
+	// authorizes it, so that verifiers can check the DID of its `kid` header may sign on behalf of its `iss`.
+	DelegationClaim = "delegation"
+)
+
+// VerifyDelegation checks the signature of a delegation credential JWT with the key resolved from the DID document of
+// the delegator, and that it authorizes the delegate and has not expired.
+func VerifyDelegation(ctx context.Context, resolver resolution.Resolver, token keyaccess.JWT, delegator, delegate string) error {
+	if _, err := integrity.VerifyJWTCredential(ctx, token.String(), resolver); err != nil {
+		return errors.Wrap(err, "verifying delegation credential")
+	}
+	_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(token.String())
+	if err != nil {
+		return errors.Wrap(err, "parsing delegation credential from jwt")
+	}
+	if err = CheckCredentialExpectations(*cred, CredentialExpectations{Types: []string{DelegationCredentialType}}); err != nil {
+		return err
+	}
+	if cred.IssuerID() != delegator {
+		return errors.Errorf("delegation credential is issued by<%s>, not by delegator<%s>", cred.IssuerID(), delegator)
+	}
+	if subject := cred.CredentialSubject.GetID(); subject != delegate {
+		return errors.Errorf("delegation credential authorizes<%s>, not delegate<%s>", subject, delegate)
+	}
+	if cred.ExpirationDate != "" {
+		expiresAt, err := time.Parse(time.RFC3339, cred.ExpirationDate)
+		if err != nil {
+			return errors.Wrapf(err, "parsing expiration date of delegation credential<%s>", cred.ID)
+		}
+		if !expiresAt.After(time.Now()) {
+			return errors.Errorf("delegation credential<%s> expired at %s", cred.ID, cred.ExpirationDate)
+		}
+	}
+	return nil
+}
+
+// verifyJWTCredentialSignature checks the signature of a credential JWT with the key of its `kid` header, resolved from
+// the DID document of its issuer or, when the key belongs to another DID, from the document of that DID, provided the
+// credential carries a delegation from its issuer to that DID.
+func (v Verifier) verifyJWTCredentialSignature(ctx context.Context, token keyaccess.JWT) error {
+	headers, parsed, _, err := integrity.ParseVerifiableCredentialFromJWT(token.String())
+	if err != nil {
+		return errors.Wrap(err, "parsing JWT")
+	}
+	kid := headers.KeyID()
+	signer, _, _ := strings.Cut(kid, "#")
+	if !strings.HasPrefix(signer, "did:") || signer == parsed.Issuer() {
+		if v.at != nil {
+			return v.verifyJWTFromDID(ctx, parsed.Issuer(), kid, token)
+		}
+		_, err = integrity.VerifyJWTCredential(ctx, token.String(), v.didResolver)
+		return err
+	}
+
+	delegation, ok := parsed.Get(DelegationClaim)
+	if !ok {
+		return errors.Errorf("credential is signed with key<%s> of DID<%s>, which is not its issuer<%s>, without a delegation", kid, signer, parsed.Issuer())
+	}
+	delegationJWT, ok := delegation.(string)
+	if !ok {
+		return errors.Errorf("claim<%s> of credential is not a JWT", DelegationClaim)
+	}
+	if err = VerifyDelegation(ctx, v.didResolver, keyaccess.JWT(delegationJWT), parsed.Issuer(), signer); err != nil {
+		return errors.Wrapf(err, "verifying delegation of issuer<%s> to DID<%s>", parsed.Issuer(), signer)
+	}
+	return v.verifyJWTFromDID(ctx, signer, kid, token)
+}
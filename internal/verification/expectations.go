@@ -0,0 +1,138 @@
+package verification
+
+import (
+	"fmt"
+	"strings"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+const (
+	// SchemaMismatch is the reason given when a credential does not have the expected credential schema.
+	SchemaMismatch = "SCHEMA_MISMATCH"
+	// TypeMismatch is the reason given when a credential does not have all the expected types.
+	TypeMismatch = "TYPE_MISMATCH"
+)
+
+// CredentialExpectations describe what a credential must be, in addition to being valid.
+type CredentialExpectations struct {
+	// The ID the credential's `credentialSchema` must have. Ignored when empty.
+	SchemaID string `json:"expectedSchemaId,omitempty"`
+
+	// Types which must all be present in the credential's `type` array. Ignored when empty.
+	Types []string `json:"expectedTypes,omitempty"`
+}
+
+func (e CredentialExpectations) IsEmpty() bool {
+	return e.SchemaID == "" && len(e.Types) == 0
+}
+
+// ExpectationError is returned when a credential is valid, but does not satisfy the expectations of the verifier.
+type ExpectationError struct {
+	// Reason is one of SchemaMismatch or TypeMismatch.
+	Reason  string
+	Message string
+}
+
+func (e ExpectationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Message)
+}
+
+// CheckCredentialExpectations returns an ExpectationError when the credential does not satisfy the expectations. It
+// must only be called once the credential's signature has been verified, so that the reason can be trusted.
+func CheckCredentialExpectations(cred credsdk.VerifiableCredential, expectations CredentialExpectations) error {
+	if expectations.SchemaID != "" {
+		if cred.CredentialSchema == nil {
+			return ExpectationError{
+				Reason:  SchemaMismatch,
+				Message: fmt.Sprintf("credential<%s> has no credentialSchema, expected schema<%s>", cred.ID, expectations.SchemaID),
+			}
+		}
+		if cred.CredentialSchema.ID != expectations.SchemaID {
+			return ExpectationError{
+				Reason: SchemaMismatch,
+				Message: fmt.Sprintf("credential<%s> has schema<%s>, expected schema<%s>", cred.ID,
+					cred.CredentialSchema.ID, expectations.SchemaID),
+			}
+		}
+	}
+
+	if len(expectations.Types) > 0 {
+		credTypes, err := sdkutil.InterfaceToStrings(cred.Type)
+		if err != nil {
+			return ExpectationError{Reason: TypeMismatch, Message: fmt.Sprintf("credential<%s> has an invalid type", cred.ID)}
+		}
+		var missing []string
+		for _, expectedType := range expectations.Types {
+			if !sdkutil.Contains(expectedType, credTypes) {
+				missing = append(missing, expectedType)
+			}
+		}
+		if len(missing) > 0 {
+			return ExpectationError{
+				Reason: TypeMismatch,
+				Message: fmt.Sprintf("credential<%s> has types [%s], missing expected types [%s]", cred.ID,
+					strings.Join(credTypes, ", "), strings.Join(missing, ", ")),
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPresentationExpectations checks, for each input descriptor ID in expectations, that the credential submitted
+// for it in the presentation satisfies the expectations. The presentation must have been verified beforehand.
+func CheckPresentationExpectations(pres credsdk.VerifiablePresentation, expectations map[string]CredentialExpectations) error {
+	if len(expectations) == 0 {
+		return nil
+	}
+	if pres.PresentationSubmission == nil {
+		return errors.New("presentation has no presentation_submission, so expectations for input descriptors cannot be checked")
+	}
+	submissionBytes, err := json.Marshal(pres.PresentationSubmission)
+	if err != nil {
+		return errors.Wrap(err, "marshalling presentation submission")
+	}
+	var submission exchange.PresentationSubmission
+	if err = json.Unmarshal(submissionBytes, &submission); err != nil {
+		return errors.Wrap(err, "unmarshalling presentation submission")
+	}
+	presJSON, err := sdkutil.ToJSONMap(pres)
+	if err != nil {
+		return errors.Wrap(err, "converting presentation to json")
+	}
+
+	descriptors := make(map[string]exchange.SubmissionDescriptor, len(submission.DescriptorMap))
+	for _, d := range submission.DescriptorMap {
+		descriptors[d.ID] = d
+	}
+	for inputDescriptorID, expected := range expectations {
+		descriptor, ok := descriptors[inputDescriptorID]
+		if !ok {
+			return fmt.Errorf("no credential submitted for input descriptor<%s>", inputDescriptorID)
+		}
+		submitted, err := jsonpath.JsonPathLookup(presJSON, descriptor.Path)
+		if err != nil {
+			return errors.Wrapf(err, "looking up json path \"%s\" for input descriptor<%s>", descriptor.Path, inputDescriptorID)
+		}
+		containers, err := credential.NewCredentialContainerFromArray([]any{submitted})
+		if err != nil {
+			return errors.Wrapf(err, "parsing credential submitted for input descriptor<%s>", inputDescriptorID)
+		}
+		if err = CheckCredentialExpectations(*containers[0].Credential, expected); err != nil {
+			var expectationErr ExpectationError
+			if errors.As(err, &expectationErr) {
+				expectationErr.Message = fmt.Sprintf("input descriptor<%s>: %s", inputDescriptorID, expectationErr.Message)
+				return expectationErr
+			}
+			return err
+		}
+	}
+	return nil
+}
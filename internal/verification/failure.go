@@ -0,0 +1,77 @@
+package verification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/internal/util"
+)
+
+const (
+	// Revoked is the reason given when a credential has been revoked by its issuer.
+	Revoked = "REVOKED"
+	// Suspended is the reason given when a credential has been suspended by its issuer.
+	Suspended = "SUSPENDED"
+	// InvalidCredential is the reason given when the signature, expiry, or data of a credential is not valid.
+	InvalidCredential = "INVALID_CREDENTIAL"
+	// InvalidPresentation is the reason given when the signature of a presentation, or a credential in it, is not valid.
+	InvalidPresentation = "INVALID_PRESENTATION"
+)
+
+// StatusError is returned when a credential is valid, but its issuer has revoked or suspended it.
+type StatusError struct {
+	// Reason is one of Revoked or Suspended.
+	Reason  string
+	Message string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Message)
+}
+
+// Failure describes a failed verification without the claims of the credentials verified, so that it can be shared
+// with parties monitoring verifications.
+type Failure struct {
+	// ReasonCode is why verification failed, such as Revoked or SchemaMismatch.
+	ReasonCode string `json:"reasonCode"`
+	// Presenter is the holder a presentation claims to be made by, when known. It is not verified when the signature of
+	// the presentation is not valid.
+	Presenter string `json:"presenter,omitempty"`
+	// CredentialHashes are the hex encoded SHA-256 hashes of the credentials verified, as given to the verifier.
+	CredentialHashes []string `json:"credentialHashes,omitempty"`
+}
+
+// FailureFunc is called with each failed verification.
+type FailureFunc func(ctx context.Context, failure Failure)
+
+// Notify calls the function, when set, with the failure. It runs in its own goroutine, detached from the request, so
+// that it does not add to the latency of verification.
+func (f FailureFunc) Notify(failure Failure) {
+	if f == nil {
+		return
+	}
+	go f(context.Background(), failure)
+}
+
+// HashCredential returns the hex encoded SHA-256 hash of a credential, either a JWT or a data integrity credential.
+// Data integrity credentials are hashed in their canonical JSON representation, so that the hash does not depend on
+// the order of their claims. An empty string is returned when the credential cannot be serialized.
+func HashCredential(cred any) string {
+	var credBytes []byte
+	switch c := cred.(type) {
+	case string:
+		credBytes = []byte(c)
+	default:
+		var err error
+		if credBytes, err = util.CanonicalJSON(c); err != nil {
+			logrus.WithError(err).Warn("could not hash credential")
+			return ""
+		}
+	}
+	hash := sha256.Sum256(credBytes)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,50 @@
+package verification
+
+import (
+	"fmt"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// ErrOffline is returned in offline mode by checks which would need to resolve a resource over the network.
+var ErrOffline = errors.New("offline mode: cannot resolve external resource")
+
+// Offline returns a copy of the verifier which resolves DIDs with the given resolver, which must not make outbound
+// calls, and which fails data integrity credentials with ErrOffline when the JSON-LD contexts their signatures are
+// computed over are loaded over the network.
+func (v Verifier) Offline(didResolver resolution.Resolver) *Verifier {
+	v.didResolver = didResolver
+	v.offline = true
+	return &v
+}
+
+// checkOnline returns ErrOffline when the verifier is offline.
+func (v Verifier) checkOnline(resource string) error {
+	if v.offline {
+		return errors.Wrap(ErrOffline, resource)
+	}
+	return nil
+}
+
+// checkContextsOnline returns ErrOffline when the verifier is offline, unless the credential has an Ed25519Signature2020
+// proof and only references JSON-LD contexts which are loaded from local copies.
+func (v Verifier) checkContextsOnline(credential credsdk.VerifiableCredential) error {
+	if !v.offline {
+		return nil
+	}
+	if credential.Proof != nil {
+		proofType, err := getKeyFromProof(*credential.Proof, "type")
+		if err == nil && proofType == string(keyaccess.Ed25519Signature2020) {
+			contexts, err := cryptosuite.GetContextsFromProvable(&credential)
+			if err == nil && keyaccess.HasOnlyKnownContexts(contexts) {
+				return nil
+			}
+		}
+	}
+	return v.checkOnline(fmt.Sprintf("loading JSON-LD contexts of credential<%s>", credential.ID))
+}
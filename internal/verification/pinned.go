@@ -0,0 +1,79 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// KeyMismatch is the reason given when a credential is not signed with the key pinned by the verifier.
+const KeyMismatch = "KEY_MISMATCH"
+
+// KeyMismatchError is returned when the key ID a credential is signed with does not correspond to the pinned key.
+type KeyMismatchError struct {
+	Message string
+}
+
+func (e KeyMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s", KeyMismatch, e.Message)
+}
+
+// VerifyJWTCredentialWithKey checks the signature on the given JWT credential with a pinned public key, instead of the
+// key resolved from its issuer's DID document, so that a substituted document cannot make it verify. Next, it runs the
+// static verification checks on the credential as per the service's configuration.
+func (v Verifier) VerifyJWTCredentialWithKey(ctx context.Context, token keyaccess.JWT, key jwx.PublicKeyJWK) error {
+	headers, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(token.String())
+	if err != nil {
+		return errors.Wrap(err, "parsing vc from jwt")
+	}
+	if err = checkPinnedKeyID(headers.KeyID(), key); err != nil {
+		return err
+	}
+	verifier, err := jwx.NewJWXVerifierFromJWK(key.KID, key)
+	if err != nil {
+		return errors.Wrap(err, "constructing verifier for pinned key")
+	}
+	if err = v.verifyJWT(verifier, token); err != nil {
+		return errors.Wrap(err, "verifying JWT credential with pinned key")
+	}
+	return v.staticValidationChecks(ctx, *cred)
+}
+
+// VerifyDataIntegrityCredentialWithKey checks the signature on the given data integrity credential with a pinned
+// public key, instead of the key resolved from its issuer's DID document. Next, it runs the static verification checks
+// on the credential as per the service's configuration.
+func (v Verifier) VerifyDataIntegrityCredentialWithKey(ctx context.Context, credential credsdk.VerifiableCredential, key jwx.PublicKeyJWK) error {
+	issuer, verificationMethod, err := dataIntegritySigner(credential)
+	if err != nil {
+		return err
+	}
+	if err = v.checkContextsOnline(credential); err != nil {
+		return err
+	}
+	if err = checkPinnedKeyID(verificationMethod, key); err != nil {
+		return err
+	}
+	if err = verifyDataIntegritySignature(issuer, verificationMethod, key, credential); err != nil {
+		return err
+	}
+	return v.staticValidationChecks(ctx, credential)
+}
+
+// checkPinnedKeyID returns a KeyMismatchError unless the key ID a credential is signed with corresponds to the key ID
+// of the pinned key, either by being equal to it, or by ending with it when it is a fragment such as `#key-1`.
+func checkPinnedKeyID(kid string, key jwx.PublicKeyJWK) error {
+	if key.KID == "" {
+		return errors.New("pinned key must have a kid")
+	}
+	if kid == key.KID || (strings.HasPrefix(key.KID, "#") && strings.HasSuffix(kid, key.KID)) {
+		return nil
+	}
+	return KeyMismatchError{Message: fmt.Sprintf("credential is signed with key<%s>, not the pinned key<%s>", kid, key.KID)}
+}
@@ -0,0 +1,166 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	didint "github.com/tbd54566975/ssi-service/internal/did"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+const (
+	// JWTFormat is the format of a credential embedded in a presentation as a compact JWT.
+	JWTFormat = "jwt_vc"
+	// DataIntegrityFormat is the format of a credential embedded in a presentation as a JSON object with a proof.
+	DataIntegrityFormat = "ldp_vc"
+)
+
+// CredentialResult is the result of verifying one of the credentials embedded in a presentation.
+type CredentialResult struct {
+	// Index is the position of the credential in the presentation.
+	Index int `json:"index"`
+	// ID is the ID of the credential, when it could be parsed.
+	ID       string `json:"id,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Verified bool   `json:"verified"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// VerifyJWTPresentation first parses and checks the signature on the given JWT presentation. Next, it checks the
+// signature of each of the presentation's credentials, whatever their format, and runs a set of static verification
+// checks on them as per the service's configuration.
+func (v Verifier) VerifyJWTPresentation(ctx context.Context, token keyaccess.JWT) error {
+	results, err := v.VerifyJWTPresentationCredentials(ctx, token, "")
+	if err != nil {
+		return err
+	}
+	return CredentialResultsError(results)
+}
+
+// VerifyJWTPresentationCredentials parses and checks the signature on the given JWT presentation, returning an error
+// when it is not valid. Next, it verifies each of the presentation's credentials through the path of its format,
+// returning a result for every credential, so that a credential failing verification does not hide the others.
+// When audience is set, the presentation must be addressed to it, as presentations following the JWT VC Presentation
+// Profile are addressed to their verifier.
+func (v Verifier) VerifyJWTPresentationCredentials(ctx context.Context, token keyaccess.JWT, audience string) ([]CredentialResult, error) {
+	presentation, err := v.verifyPresentationSignature(ctx, token, audience)
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying JWT presentation")
+	}
+	results := make([]CredentialResult, 0, len(presentation.VerifiableCredential))
+	for i, embedded := range presentation.VerifiableCredential {
+		result := CredentialResult{Index: i}
+		if err = v.verifyEmbeddedCredential(ctx, embedded, &result); err != nil {
+			result.Reason = err.Error()
+		} else {
+			result.Verified = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CredentialResultsError returns an error naming each credential which failed verification, or nil when they were all
+// verified.
+func CredentialResultsError(results []CredentialResult) error {
+	var failures []string
+	for _, result := range results {
+		if !result.Verified {
+			failures = append(failures, fmt.Sprintf("verifying credential %d: %s", result.Index, result.Reason))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(failures, "; "))
+}
+
+// verifyPresentationSignature checks the signature on the given JWT presentation against the key resolved from its
+// holder's DID document, and that it is addressed to the expected audience. When no audience is expected, the audience
+// of the presentation, when it has one, must be the holder.
+func (v Verifier) verifyPresentationSignature(ctx context.Context, token keyaccess.JWT, expectedAudience string) (*credsdk.VerifiablePresentation, error) {
+	headers, vpToken, presentation, err := integrity.ParseVerifiablePresentationFromJWT(token.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing JWT")
+	}
+	kid := headers.KeyID()
+	if kid == "" {
+		return nil, errors.Errorf("missing kid in header of presentation<%s>", vpToken.JwtID())
+	}
+	key, err := didint.ResolveKeyForDID(ctx, v.didResolver, vpToken.Issuer(), kid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting key to verify presentation<%s>", vpToken.JwtID())
+	}
+	verifier, err := jwx.NewJWXVerifier(vpToken.Issuer(), kid, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "constructing verifier for presentation<%s>", vpToken.JwtID())
+	}
+	if err = verifier.Verify(token.String()); err != nil {
+		return nil, errors.Wrap(err, "verifying JWT and its signature")
+	}
+	if expectedAudience != "" {
+		for _, aud := range vpToken.Audience() {
+			if aud == expectedAudience {
+				return presentation, nil
+			}
+		}
+		return nil, errors.Errorf("audience mismatch: expected [%s], got %s", expectedAudience, vpToken.Audience())
+	}
+	if audience := vpToken.Audience(); len(audience) != 0 {
+		matched := false
+		for _, aud := range audience {
+			if aud == verifier.ID || aud == verifier.KID {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, errors.Errorf("audience mismatch: expected [%s] or [%s], got %s", verifier.ID, verifier.KID, audience)
+		}
+	}
+	return presentation, nil
+}
+
+// verifyEmbeddedCredential detects the format of a credential embedded in a presentation, either a compact JWT or a
+// JSON object with a proof, and verifies it through the path of that format, recording its ID and format in the result.
+func (v Verifier) verifyEmbeddedCredential(ctx context.Context, embedded any, result *CredentialResult) error {
+	if jwtString, ok := embedded.(string); ok && !strings.HasPrefix(strings.TrimSpace(jwtString), "{") {
+		result.Format = JWTFormat
+		_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(jwtString)
+		if err != nil {
+			return errors.Wrap(err, "parsing JWT")
+		}
+		result.ID = cred.ID
+		return v.VerifyJWTCredential(ctx, keyaccess.JWT(jwtString))
+	}
+
+	result.Format = DataIntegrityFormat
+	var credBytes []byte
+	if jsonString, ok := embedded.(string); ok {
+		credBytes = []byte(jsonString)
+	} else {
+		var err error
+		if credBytes, err = json.Marshal(embedded); err != nil {
+			return errors.Wrap(err, "marshalling credential")
+		}
+	}
+	var cred credsdk.VerifiableCredential
+	if err := json.Unmarshal(credBytes, &cred); err != nil {
+		return errors.Wrap(err, "unmarshalling credential")
+	}
+	if cred.IsEmpty() {
+		return errors.New("not a valid credential")
+	}
+	result.ID = cred.ID
+	if cred.Proof == nil {
+		return errors.New("data integrity credential has no proof")
+	}
+	return v.VerifyDataIntegrityCredential(ctx, cred)
+}
@@ -0,0 +1,27 @@
+package verification
+
+import (
+	"context"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// UntrustedSchema is the reason given when the schema of a credential is not a JsonSchemaCredential issued by one of
+// the trusted schema authorities.
+const UntrustedSchema = "UNTRUSTED_SCHEMA"
+
+// VerifySchemaCredential checks the signature of a JsonSchemaCredential with the key resolved from its issuer's DID
+// document, and returns its issuer, which can then be trusted.
+func (v Verifier) VerifySchemaCredential(ctx context.Context, token keyaccess.JWT) (string, error) {
+	if _, err := integrity.VerifyJWTCredential(ctx, token.String(), v.didResolver); err != nil {
+		return "", errors.Wrap(err, "verifying schema credential")
+	}
+	_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(token.String())
+	if err != nil {
+		return "", errors.Wrap(err, "parsing schema credential from jwt")
+	}
+	return cred.IssuerID(), nil
+}
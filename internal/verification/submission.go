@@ -0,0 +1,168 @@
+package verification
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/parsing"
+	"github.com/oliveagle/jsonpath"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/util"
+)
+
+// ErrUnmetSubmissionRequirement is returned when the distinct credentials of a presentation submission do not meet
+// the submission requirements of its presentation definition.
+var ErrUnmetSubmissionRequirement = errors.New("submission requirement not met")
+
+// submittedCredential identifies a credential submitted in a presentation submission, whatever its format.
+type submittedCredential struct {
+	// key is the ID of the credential, or the hash of its claims when it has none. Copies of a credential in other
+	// formats, such as a JWT and a data integrity credential, have the same key.
+	key string
+	// claimsHash is the hash of the claims of the credential, which differs between copies of a credential when they
+	// are inconsistent.
+	claimsHash string
+}
+
+// CheckDistinctSubmission checks a presentation submission whose descriptors were each matched against their input
+// descriptor, counting copies of the same credential once. Wallets may submit a credential in several formats, such as
+// a JWT and a data integrity credential, in which case the copies fulfill the input descriptors they are submitted for
+// as one credential, and so count once against the submission requirements of the definition.
+//
+// The paths of the descriptors of the submission are resolved against submittedJSON, which is the presentation or the
+// credential application the submission is part of, and submitted holds every credential submitted in it. It returns a
+// warning for each credential submitted more than once, and for copies whose claims differ, along with an
+// ErrUnmetSubmissionRequirement when the distinct credentials do not meet the submission requirements.
+func CheckDistinctSubmission(definition exchange.PresentationDefinition, submission exchange.PresentationSubmission, submittedJSON map[string]any, submitted []any) ([]string, error) {
+	warnings := duplicateCredentialWarnings(submitted)
+	if len(definition.SubmissionRequirements) == 0 {
+		return warnings, nil
+	}
+
+	// the credential fulfilling each input descriptor
+	fulfilledBy := make(map[string]string, len(submission.DescriptorMap))
+	for _, descriptor := range submission.DescriptorMap {
+		claim, err := jsonpath.JsonPathLookup(submittedJSON, descriptor.Path)
+		if err != nil {
+			return warnings, errors.Wrapf(err, "resolving credential of submission descriptor<%s> with path: %s", descriptor.ID, descriptor.Path)
+		}
+		identified, err := identifyCredential(claim)
+		if err != nil {
+			return warnings, errors.Wrapf(err, "identifying credential of submission descriptor<%s>", descriptor.ID)
+		}
+		fulfilledBy[descriptor.ID] = identified.key
+	}
+	groups := make(map[string][]string)
+	for _, inputDescriptor := range definition.InputDescriptors {
+		for _, group := range inputDescriptor.Group {
+			groups[group] = append(groups[group], inputDescriptor.ID)
+		}
+	}
+	for _, requirement := range definition.SubmissionRequirements {
+		if err := checkSubmissionRequirement(requirement, groups, fulfilledBy); err != nil {
+			return warnings, err
+		}
+	}
+	return warnings, nil
+}
+
+// checkSubmissionRequirement returns an ErrUnmetSubmissionRequirement when the requirement is not met. A group
+// counts the distinct credentials fulfilling its input descriptors. Maximums are not checked, since every input
+// descriptor must be fulfilled for the submission to match its definition.
+func checkSubmissionRequirement(requirement exchange.SubmissionRequirement, groups map[string][]string, fulfilledBy map[string]string) error {
+	var met, total int
+	what := "nested requirements"
+	if requirement.From != "" {
+		what = fmt.Sprintf("distinct credentials of group<%s>", requirement.From)
+		distinct := make(map[string]bool)
+		for _, inputDescriptorID := range groups[requirement.From] {
+			if key, ok := fulfilledBy[inputDescriptorID]; ok {
+				distinct[key] = true
+				total++
+			}
+		}
+		met = len(distinct)
+		if requirement.Rule == exchange.All {
+			// an input descriptor may be fulfilled by the same credential as another
+			if total < len(groups[requirement.From]) {
+				return errors.Wrapf(ErrUnmetSubmissionRequirement, "requirement<%s> needs every input descriptor of group<%s>", requirement.Name, requirement.From)
+			}
+			return nil
+		}
+	} else {
+		for _, nested := range requirement.FromNested {
+			if checkSubmissionRequirement(nested, groups, fulfilledBy) == nil {
+				met++
+			}
+		}
+		total = len(requirement.FromNested)
+	}
+
+	needed := total
+	if requirement.Rule == exchange.Pick {
+		needed = max(requirement.Count, requirement.Minimum)
+	}
+	if met < needed {
+		return errors.Wrapf(ErrUnmetSubmissionRequirement, "requirement<%s> needs %d %s, %d were submitted", requirement.Name, needed, what, met)
+	}
+	return nil
+}
+
+// duplicateCredentialWarnings returns a warning for each credential submitted more than once, and for each one whose
+// copies have different claims.
+func duplicateCredentialWarnings(submitted []any) []string {
+	var order []string
+	copies := make(map[string][]submittedCredential)
+	for _, claim := range submitted {
+		identified, err := identifyCredential(claim)
+		if err != nil {
+			// credentials which cannot be parsed fail their verification
+			continue
+		}
+		if _, ok := copies[identified.key]; !ok {
+			order = append(order, identified.key)
+		}
+		copies[identified.key] = append(copies[identified.key], *identified)
+	}
+
+	var warnings []string
+	for _, key := range order {
+		if len(copies[key]) < 2 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("credential<%s> is submitted %d times, and counts once", key, len(copies[key])))
+		for _, copied := range copies[key][1:] {
+			if copied.claimsHash != copies[key][0].claimsHash {
+				warnings = append(warnings, fmt.Sprintf("copies of credential<%s> have different claims", key))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// identifyCredential identifies a credential submitted as a JWT or as a JSON object. The claims it is identified by
+// when it has no ID leave out its proof and dates, whose representation differs between formats.
+func identifyCredential(claim any) (*submittedCredential, error) {
+	_, _, cred, err := parsing.ToCredential(claim)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing credential")
+	}
+	claimsHash, err := util.ContentHash(map[string]any{
+		"issuer":            cred.Issuer,
+		"type":              cred.Type,
+		"credentialSubject": cred.CredentialSubject,
+		"credentialSchema":  cred.CredentialSchema,
+		"credentialStatus":  cred.CredentialStatus,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "hashing claims of credential")
+	}
+	key := strings.TrimSpace(cred.ID)
+	if key == "" {
+		key = claimsHash
+	}
+	return &submittedCredential{key: key, claimsHash: claimsHash}, nil
+}
@@ -2,13 +2,16 @@ package verification
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
+	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	"github.com/TBD54566975/ssi-sdk/credential/validation"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/cryptosuite/jws2020"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
@@ -25,11 +28,28 @@ type Verifier struct {
 	validator      *validation.CredentialValidator
 	didResolver    resolution.Resolver
 	schemaResolver schema.Resolution
+	// assertedFormats are the JSON Schema formats credentials must satisfy, whatever the draft of their schema
+	assertedFormats []string
+	// offline is true when checks needing network access fail closed
+	offline bool
+	// at is the time validity periods are checked at, when it is not the current time
+	at *time.Time
+}
+
+// Option configures the checks run by a Verifier.
+type Option func(v *Verifier)
+
+// WithAssertedFormats makes the `format` keywords of credential schemas with one of the given formats fail
+// verification on malformed values, even for schema drafts where formats are only annotations.
+func WithAssertedFormats(formats ...string) Option {
+	return func(v *Verifier) {
+		v.assertedFormats = formats
+	}
 }
 
 // NewVerifiableDataVerifier creates a new verifier for both verifiable credentials and verifiable presentations. The verifier
 // executes both signature and static verification checks. In the future the set of verification checks will be configurable.
-func NewVerifiableDataVerifier(didResolver resolution.Resolver, schemaResolver schema.Resolution) (*Verifier, error) {
+func NewVerifiableDataVerifier(didResolver resolution.Resolver, schemaResolver schema.Resolution, opts ...Option) (*Verifier, error) {
 	if didResolver == nil {
 		return nil, errors.New("didResolver cannot be nil")
 	}
@@ -37,16 +57,29 @@ func NewVerifiableDataVerifier(didResolver resolution.Resolver, schemaResolver s
 		return nil, errors.New("schemaResolver cannot be nil")
 	}
 	// TODO(gabe): consider making this configurable
-	validators := validation.GetKnownVerifiers()
+	validators := validityPeriodValidator(validation.GetKnownVerifiers())
 	validator, err := validation.NewCredentialValidator(validators)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating static validator")
 	}
-	return &Verifier{
+	verifier := Verifier{
 		validator:      validator,
 		didResolver:    didResolver,
 		schemaResolver: schemaResolver,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(&verifier)
+	}
+	if err = schema.ValidateFormatAssertions(verifier.assertedFormats); err != nil {
+		return nil, errors.Wrap(err, "invalid asserted formats")
+	}
+	return &verifier, nil
+}
+
+// WithSchemaResolver returns a copy of the verifier which resolves the schemas of credentials with the given resolver.
+func (v Verifier) WithSchemaResolver(schemaResolver schema.Resolution) *Verifier {
+	v.schemaResolver = schemaResolver
+	return &v
 }
 
 // VerifyCredential first parses and checks the signature on the given credential. Next, it runs
@@ -66,11 +99,11 @@ func (v Verifier) VerifyCredential(ctx context.Context, credential credential.Co
 	return nil
 }
 
-// VerifyJWTCredential first parses and checks the signature on the given JWT verification. Next, it runs
-// a set of static verification checks on the credential as per the service's configuration.
+// VerifyJWTCredential first parses and checks the signature on the given JWT verification, which may be made by a
+// delegate of its issuer. Next, it runs a set of static verification checks on the credential as per the service's
+// configuration.
 func (v Verifier) VerifyJWTCredential(ctx context.Context, token keyaccess.JWT) error {
-	_, err := integrity.VerifyJWTCredential(ctx, token.String(), v.didResolver)
-	if err != nil {
+	if err := v.verifyJWTCredentialSignature(ctx, token); err != nil {
 		return errors.Wrap(err, "verifying JWT credential")
 	}
 	_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(token.String())
@@ -83,19 +116,14 @@ func (v Verifier) VerifyJWTCredential(ctx context.Context, token keyaccess.JWT)
 // VerifyDataIntegrityCredential first checks the signature on the given data integrity verification. Next, it runs
 // a set of static verification checks on the credential as per the service's configuration.
 func (v Verifier) VerifyDataIntegrityCredential(ctx context.Context, credential credsdk.VerifiableCredential) error {
-	// resolve the issuer's key material
-	issuer, ok := credential.Issuer.(string)
-	if !ok {
-		return sdkutil.LoggingNewErrorf("could not convert issuer to string: %v", credential.Issuer)
+	if err := v.checkContextsOnline(credential); err != nil {
+		return err
 	}
 
-	maybeVerificationMethod, err := getKeyFromProof(*credential.Proof, "verificationMethod")
+	// resolve the issuer's key material
+	issuer, verificationMethod, err := dataIntegritySigner(credential)
 	if err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not get verification method from proof")
-	}
-	verificationMethod, ok := maybeVerificationMethod.(string)
-	if !ok {
-		return sdkutil.LoggingNewErrorf("could not convert verification method to string: %v", maybeVerificationMethod)
+		return err
 	}
 
 	pubKey, err := didint.ResolveKeyForDID(ctx, v.didResolver, issuer, verificationMethod)
@@ -103,46 +131,71 @@ func (v Verifier) VerifyDataIntegrityCredential(ctx context.Context, credential
 		return sdkutil.LoggingError(err)
 	}
 
-	// construct a signature validator from the verification information
 	publicKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(verificationMethod, pubKey)
 	if err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "could not convert private key to JWK: %s", verificationMethod)
 	}
-	verifier, err := jws2020.NewJSONWebKeyVerifier(issuer, *publicKeyJWK)
-	if err != nil {
-		errMsg := fmt.Sprintf("could not create validator for kid %s", verificationMethod)
-		return sdkutil.LoggingErrorMsg(err, errMsg)
-	}
-
-	cryptoSuite := jws2020.GetJSONWebSignature2020Suite()
-	// verify the signature on the credential
-	if err = cryptoSuite.Verify(verifier, &credential); err != nil {
-		return sdkutil.LoggingErrorMsg(err, "could not verify the credential's signature")
+	if err = verifyDataIntegritySignature(issuer, verificationMethod, *publicKeyJWK, credential); err != nil {
+		return err
 	}
 
 	return v.staticValidationChecks(ctx, credential)
 }
 
-// VerifyJWTPresentation first parses and checks the signature on the given JWT presentation. Next, it runs
-// a set of static verification checks on the presentation's credentials as per the service's configuration.
-func (v Verifier) VerifyJWTPresentation(ctx context.Context, token keyaccess.JWT) error {
-	_, err := integrity.VerifyJWTPresentation(ctx, token.String(), v.didResolver)
-	if err != nil {
-		return errors.Wrap(err, "verifying JWT presentation")
+// dataIntegritySigner returns the issuer of a data integrity credential, and the verification method of its proof.
+func dataIntegritySigner(credential credsdk.VerifiableCredential) (issuer string, verificationMethod string, err error) {
+	issuer, ok := credential.Issuer.(string)
+	if !ok {
+		return "", "", sdkutil.LoggingNewErrorf("could not convert issuer to string: %v", credential.Issuer)
 	}
-	_, _, pres, err := integrity.ParseVerifiablePresentationFromJWT(token.String())
+
+	maybeVerificationMethod, err := getKeyFromProof(*credential.Proof, "verificationMethod")
 	if err != nil {
-		return errors.Wrap(err, "parsing vc from jwt")
+		return "", "", sdkutil.LoggingErrorMsg(err, "could not get verification method from proof")
 	}
-	// for each credential in the presentation, run a set of static verification checks
-	creds, err := credential.NewCredentialContainerFromArray(pres.VerifiableCredential)
+	verificationMethod, ok = maybeVerificationMethod.(string)
+	if !ok {
+		return "", "", sdkutil.LoggingNewErrorf("could not convert verification method to string: %v", maybeVerificationMethod)
+	}
+	return issuer, verificationMethod, nil
+}
+
+// verifyDataIntegritySignature checks the signature on a data integrity credential with the public key of its
+// verification method.
+func verifyDataIntegritySignature(issuer, verificationMethod string, publicKeyJWK jwx.PublicKeyJWK, credential credsdk.VerifiableCredential) error {
+	proofType, err := getKeyFromProof(*credential.Proof, "type")
 	if err != nil {
-		return errors.Wrapf(err, "error parsing credentials in presentation<%s>", pres.ID)
+		return sdkutil.LoggingErrorMsg(err, "could not get type from proof")
 	}
-	for _, cred := range creds {
-		if err = v.staticValidationChecks(ctx, *cred.Credential); err != nil {
-			return errors.Wrapf(err, "error running static validation checks on credential in presentation<%v>", cred.ID)
+
+	// construct a signature validator for the suite of the proof from the verification information
+	var verifier cryptosuite.Verifier
+	var cryptoSuite cryptosuite.CryptoSuite
+	switch proofType {
+	case string(keyaccess.Ed25519Signature2020):
+		pubKey, err := publicKeyJWK.ToPublicKey()
+		if err != nil {
+			return sdkutil.LoggingErrorMsgf(err, "could not convert JWK to public key: %s", verificationMethod)
 		}
+		ed25519Key, ok := pubKey.(ed25519.PublicKey)
+		if !ok {
+			return sdkutil.LoggingNewErrorf("%s proofs must be signed with Ed25519 keys, not %T: %s", proofType, pubKey, verificationMethod)
+		}
+		verifier = keyaccess.NewEd25519Verifier(issuer, verificationMethod, ed25519Key)
+		cryptoSuite = keyaccess.GetEd25519Signature2020Suite()
+	default:
+		jwkVerifier, err := jws2020.NewJSONWebKeyVerifier(issuer, publicKeyJWK)
+		if err != nil {
+			errMsg := fmt.Sprintf("could not create validator for kid %s", verificationMethod)
+			return sdkutil.LoggingErrorMsg(err, errMsg)
+		}
+		verifier = jwkVerifier
+		cryptoSuite = jws2020.GetJSONWebSignature2020Suite()
+	}
+
+	// verify the signature on the credential
+	if err = cryptoSuite.Verify(verifier, &credential); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not verify the credential's signature")
 	}
 	return nil
 }
@@ -175,9 +228,18 @@ func (v Verifier) staticValidationChecks(ctx context.Context, credential credsdk
 			return errors.Wrapf(err, "for credential<%s> failed to marshal schema: %s", credential.ID, schemaID)
 		}
 		validationOpts = append(validationOpts, validation.WithSchema(string(schemaBytes)))
+
+		if len(v.assertedFormats) > 0 {
+			if err = schema.IsCredentialValidForJSONSchema(credential, *resolvedSchema, v.assertedFormats...); err != nil {
+				return sdkutil.LoggingErrorMsgf(err, "credential<%s> does not comply with schema: %s", credential.ID, schemaID)
+			}
+		}
 	}
 
 	// run the configured static checks on the credential
+	if v.at != nil {
+		validationOpts = append(validationOpts, validation.Option{ID: asOfOption, Option: *v.at})
+	}
 	if err := v.validator.ValidateCredential(credential, validationOpts...); err != nil {
 		return sdkutil.LoggingErrorMsg(err, "static credential validation failed")
 	}
@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -12,11 +13,90 @@ type FieldError struct {
 	Error string `json:"error"`
 }
 
+// Codes of errors sent back to the requester. Unlike error messages, codes are stable, so that clients can rely on
+// them. Each code has a message in the catalogs of internal/i18n.
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeInvalidJSON        = "INVALID_JSON"
+	CodeValidationFailed   = "VALIDATION_FAILED"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	CodeInternalError      = "INTERNAL_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	CodeTimeout            = "TIMEOUT"
+)
+
 // ErrorResponse is the structure of response error payloads sent back to the requester
 // when validation of a request payload fails.
 type ErrorResponse struct {
-	Error  string `json:"error"`
-	Fields string `json:"fields,omitempty"`
+	// Code is a stable identifier of the error, such as "NOT_FOUND".
+	Code  string `json:"code"`
+	Error string `json:"error"`
+	// LocalizedMessage describes the error to end users, in the language best matching the Accept-Language header of
+	// the request.
+	LocalizedMessage string `json:"localizedMessage,omitempty"`
+	Fields           string `json:"fields,omitempty"`
+	// Retryable is true when the request conflicted with a concurrent update, and may succeed when retried.
+	Retryable bool `json:"retryable,omitempty"`
+	// Contended describes the resources which may have been updated concurrently, when known.
+	Contended any `json:"contended,omitempty"`
+	// Violations describe each value of a credential which fails its schema, when the credential does not comply with
+	// it, such as `{"pointer": "/credentialSubject/age", "keyword": "type", "expected": "integer", "actual": "ten"}`.
+	Violations any `json:"violations,omitempty"`
+}
+
+// CodedError gives an error a code more specific than the one of its status code, which is sent back to the
+// requester along with its message.
+type CodedError struct {
+	Code string
+	Err  error
+}
+
+func (e CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e CodedError) Unwrap() error {
+	return e.Err
+}
+
+// NewCodedError returns an error with a code, as sent back to the requester.
+func NewCodedError(code string, err error) error {
+	return CodedError{Code: code, Err: err}
+}
+
+// errorCode returns the code of an error sent back to the requester with a status code. It is the code of the first
+// CodedError wrapped by the error, or otherwise the code of the status.
+func errorCode(err error, statusCode int) string {
+	var codedErr CodedError
+	if errors.As(err, &codedErr) {
+		return codedErr.Code
+	}
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
+		return CodeBadRequest
+	}
+	return CodeInternalError
 }
 
 // SafeError is used to pass an error during the request through the server with
@@ -34,6 +114,10 @@ func (err *SafeError) Error() string {
 	return err.Err.Error()
 }
 
+func (err *SafeError) Unwrap() error {
+	return err.Err
+}
+
 // FieldErrors returns a string containing all field errors.
 func (err *SafeError) FieldErrors() string {
 	if len(err.Fields) == 0 {
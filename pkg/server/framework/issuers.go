@@ -0,0 +1,36 @@
+package framework
+
+import (
+	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// AnyIssuer in the permitted issuers of a caller allows it to act as any issuer.
+	AnyIssuer = "*"
+
+	permittedIssuersKey = "permittedIssuers"
+)
+
+// SetPermittedIssuers records the issuers the caller of the request is allowed to act as.
+func SetPermittedIssuers(c *gin.Context, issuers []string) {
+	c.Set(permittedIssuersKey, issuers)
+}
+
+// IsIssuerScoped returns whether the caller of the request is restricted to a set of issuers.
+func IsIssuerScoped(c *gin.Context) bool {
+	_, ok := c.Get(permittedIssuersKey)
+	return ok
+}
+
+// IsIssuerPermitted returns whether the caller of the request may act as the given issuer. Callers are permitted any
+// issuer when no permitted issuers were recorded for the request, which is the case when issuer scoped API keys are
+// not configured.
+func IsIssuerPermitted(c *gin.Context, issuer string) bool {
+	got, ok := c.Get(permittedIssuersKey)
+	if !ok {
+		return true
+	}
+	issuers, _ := got.([]string)
+	return util.Contains(AnyIssuer, issuers) || util.Contains(issuer, issuers)
+}
@@ -0,0 +1,49 @@
+package framework
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutputFormat is the shape credentials are returned in.
+type OutputFormat string
+
+const (
+	// ContainerOutput returns credentials with both their JSON and JWT representations.
+	ContainerOutput OutputFormat = "container"
+	// JWTOutput returns credentials with only their JWT representation.
+	JWTOutput OutputFormat = "jwt"
+
+	// ViewParam is the query parameter overriding the output format of a request.
+	ViewParam = "view"
+
+	defaultOutputFormatKey = "defaultOutputFormat"
+)
+
+func (f OutputFormat) IsValid() bool {
+	return f == ContainerOutput || f == JWTOutput
+}
+
+// SetDefaultOutputFormat records the output format the caller of the request wants when it does not ask for one.
+func SetDefaultOutputFormat(c *gin.Context, format OutputFormat) {
+	c.Set(defaultOutputFormatKey, format)
+}
+
+// GetOutputFormat returns the output format requested with the `view` query parameter, falling back to the default
+// recorded for the caller, and then to ContainerOutput.
+func GetOutputFormat(c *gin.Context) (OutputFormat, error) {
+	if view := c.Query(ViewParam); view != "" {
+		format := OutputFormat(view)
+		if !format.IsValid() {
+			return "", fmt.Errorf("invalid %s<%s>, must be one of [%s, %s]", ViewParam, view, ContainerOutput, JWTOutput)
+		}
+		return format, nil
+	}
+	if got, ok := c.Get(defaultOutputFormatKey); ok {
+		if format, _ := got.(OutputFormat); format.IsValid() {
+			return format, nil
+		}
+	}
+	return ContainerOutput, nil
+}
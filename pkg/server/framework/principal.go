@@ -0,0 +1,29 @@
+package framework
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// AnonymousPrincipal is the principal of requests made without a known API key, which is the case of every
+	// request when API keys are not configured.
+	AnonymousPrincipal = "anonymous"
+
+	principalKey = "principal"
+)
+
+// SetPrincipal records the principal the request is made by, which is recorded on the resources it creates and the
+// changes it makes.
+func SetPrincipal(c *gin.Context, principal string) {
+	c.Set(principalKey, principal)
+}
+
+// GetPrincipal returns the principal the request is made by, or AnonymousPrincipal when none was recorded.
+func GetPrincipal(c *gin.Context) string {
+	if got, ok := c.Get(principalKey); ok {
+		if principal, _ := got.(string); principal != "" {
+			return principal
+		}
+	}
+	return AnonymousPrincipal
+}
@@ -50,13 +50,15 @@ func init() {
 // Decode reads an HTTP request body looking for a JSON document.
 // The body is decoded into the value provided.
 //
-// The provided value is checked for validation tags if it's a struct.
+// The provided value is checked for validation tags if it's a struct. Numbers in untyped values, such as credential
+// data, are decoded as json.Number rather than float64, so that large integers are kept without loss of precision.
 func Decode(r *http.Request, val any) error {
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
 
 	if err := decoder.Decode(val); err != nil {
-		return newRequestError(err, http.StatusBadRequest)
+		return newRequestError(NewCodedError(CodeInvalidJSON, err), http.StatusBadRequest)
 	}
 
 	if err := validate.Struct(val); err != nil {
@@ -80,7 +82,7 @@ func Decode(r *http.Request, val any) error {
 		}
 
 		return &SafeError{
-			Err:        errors.New("field validation error"),
+			Err:        NewCodedError(CodeValidationFailed, errors.New("field validation error")),
 			StatusCode: http.StatusBadRequest,
 			Fields:     fieldErrors,
 		}
@@ -90,5 +92,8 @@ func Decode(r *http.Request, val any) error {
 }
 
 func ValidateRequest(request any) error {
-	return util.IsValidStruct(request)
+	if err := util.IsValidStruct(request); err != nil {
+		return NewCodedError(CodeValidationFailed, err)
+	}
+	return nil
 }
@@ -1,11 +1,18 @@
 package framework
 
 import (
+	"context"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/internal/i18n"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 // Respond convert a Go value to JSON and sends it to the client.
@@ -20,13 +27,31 @@ func Respond(c *gin.Context, data any, statusCode int) {
 		if ok = errors.As(err, &safeErr); !ok {
 			statusCode = http.StatusInternalServerError
 			logrus.WithError(err).Error("unsafe error")
-			safeErr.Err = errors.New("error processing request")
+			safeErr = &SafeError{Err: errors.New("error processing request"), StatusCode: statusCode}
 		}
 		// if the error is a `SafeError`, we can retrieve the status code and any field errors from it and use them
 		// to build the response.
+		code := errorCode(safeErr.Err, statusCode)
+		localizedMessage, lang := i18n.Message(c.GetHeader("Accept-Language"), code)
+		if lang != "" {
+			c.Header("Content-Language", lang)
+		}
 		errResp := ErrorResponse{
-			Error:  safeErr.Err.Error(),
-			Fields: safeErr.FieldErrors(),
+			Code:             code,
+			Error:            safeErr.Err.Error(),
+			LocalizedMessage: localizedMessage,
+			Fields:           safeErr.FieldErrors(),
+		}
+		if errors.Is(safeErr.Err, storage.ErrConflict) {
+			errResp.Retryable = true
+			var contended contendedError
+			if errors.As(safeErr.Err, &contended) {
+				errResp.Contended = contended.Contended()
+			}
+		}
+		var violating violatingError
+		if errors.As(safeErr.Err, &violating) {
+			errResp.Violations = violating.SchemaViolations()
 		}
 		c.PureJSON(statusCode, errResp)
 		return
@@ -42,8 +67,41 @@ func Respond(c *gin.Context, data any, statusCode int) {
 	c.PureJSON(statusCode, data)
 }
 
-// LoggingRespondError sends an error response back to the client as a safe error
+// retryableError is implemented by errors of operations rejected because the service is busy, such as a
+// signing.BusyError, which clients can retry after some time.
+type retryableError interface {
+	RetryAfter() time.Duration
+}
+
+// contendedError is implemented by conflict errors which know the resources updated concurrently, such as a
+// credential.StatusListConflictError.
+type contendedError interface {
+	Contended() any
+}
+
+// violatingError is implemented by errors of credentials which do not comply with their schema, such as a
+// schema.ValidationError, which describe each violation of the schema.
+type violatingError interface {
+	SchemaViolations() any
+}
+
+// LoggingRespondError sends an error response back to the client as a safe error. Errors of operations rejected
+// because the service is busy are responded to with a 503 and a Retry-After header, and errors of transactions aborted
+// by their context with a 504 when it timed out, or a 408 when the request was canceled, whatever the status code
+// given.
 func LoggingRespondError(c *gin.Context, err error, statusCode int) {
+	var retryable retryableError
+	if errors.As(err, &retryable) {
+		statusCode = http.StatusServiceUnavailable
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryable.RetryAfter().Seconds()))))
+	}
+	if errors.Is(err, storage.ErrAborted) {
+		statusCode = http.StatusGatewayTimeout
+		if errors.Is(err, context.Canceled) {
+			statusCode = http.StatusRequestTimeout
+		}
+	}
+
 	var fieldErrors []FieldError
 	var safeErr *SafeError
 	if errors.As(errors.WithStack(err), &safeErr) {
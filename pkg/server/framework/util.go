@@ -1,9 +1,31 @@
 package framework
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 )
 
+// RequestContext returns a context holding the values of the gin context, such as the principal, which is canceled
+// along with the request, such as when the client disconnects. The gin context itself is never canceled, so long
+// running operations which should stop with the request must use this context instead.
+func RequestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(requestContext{Context: c.Request.Context(), c: c})
+}
+
+// requestContext is canceled with the request, and looks up values in the gin context before the request context.
+type requestContext struct {
+	context.Context
+	c *gin.Context
+}
+
+func (ctx requestContext) Value(key any) any {
+	if value := ctx.c.Value(key); value != nil {
+		return value
+	}
+	return ctx.Context.Value(key)
+}
+
 // GetParam is a utility to get a path parameter from context, nil if not found
 func GetParam(c *gin.Context, param string) *string {
 	got := c.Param(param)
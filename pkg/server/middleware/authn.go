@@ -7,6 +7,8 @@ import (
 	"os"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 )
 
 /*
@@ -19,17 +21,49 @@ func setUpEngine(cfg config.ServerConfig, shutdown chan os.Signal) *gin.Engine {
 		gin.Recovery(),
 		gin.Logger(),
 		middleware.Errors(shutdown),
-		middleware.AuthMiddleware(),
+		middleware.AuthMiddleware(AuthExemptRoutes...),
 	}
 */
 
-func AuthMiddleware() gin.HandlerFunc {
-	authToken := os.Getenv("AUTH_TOKEN")
+// AuthMiddleware checks bearer tokens against the sha256 hash in `AUTH_TOKEN`. Routes whose full path, such as
+// "/v1/credentials/:id/status/public", is one of the exempt routes are served without a token.
+func AuthMiddleware(exemptRoutes ...string) gin.HandlerFunc {
+	auth := tokenAuth(os.Getenv("AUTH_TOKEN"))
+	if len(exemptRoutes) == 0 {
+		return auth
+	}
+	exempt := make(map[string]bool, len(exemptRoutes))
+	for _, route := range exemptRoutes {
+		exempt[route] = true
+	}
+	return func(c *gin.Context) {
+		if exempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// AdminAuthMiddleware guards the admin scoped routes. It behaves like AuthMiddleware, but checks bearer tokens against
+// the sha256 hash in `ADMIN_AUTH_TOKEN`. Unlike AuthMiddleware, it fails closed: if `ADMIN_AUTH_TOKEN` is not set,
+// admin routes respond with a 503.
+func AdminAuthMiddleware() gin.HandlerFunc {
+	authToken := os.Getenv("ADMIN_AUTH_TOKEN")
+	if authToken == "" {
+		return func(c *gin.Context) {
+			framework.LoggingRespondErrMsg(c, "admin routes are disabled until ADMIN_AUTH_TOKEN is set", http.StatusServiceUnavailable)
+			c.Abort()
+		}
+	}
+	return tokenAuth(authToken)
+}
 
+func tokenAuth(authToken string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 
-		// If AUTH_TOKEN is not set, skip the authentication
+		// If the auth token is not set, skip the authentication
 		if authToken == "" {
 			c.Next()
 			return
@@ -46,7 +80,7 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// Check if the hashed token from the header matches the AUTH token
 		if hashedToken != authToken {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization is required"})
+			framework.LoggingRespondErrMsg(c, "Authorization is required", http.StatusUnauthorized)
 			c.Abort()
 			return
 		}
@@ -78,3 +78,70 @@ func TestNoAuthMiddleware(t *testing.T) {
 	// Assert that the status code is 200 OK
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestAuthMiddlewareExemptRoutes(t *testing.T) {
+	t.Setenv("AUTH_TOKEN", "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7") // sha256 hash of "hunter2"
+
+	r := gin.Default()
+	r.Use(AuthMiddleware("/public/:id"))
+	r.GET("/public/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	r.GET("/private/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	// the exempt route is matched by its full path, whatever its parameters
+	req, _ := http.NewRequest(http.MethodGet, "/public/123", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/private/123", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/private/123", nil)
+	req.Header.Add("Authorization", "Bearer hunter2")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	t.Setenv("ADMIN_AUTH_TOKEN", "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7") // sha256 hash of "hunter2"
+
+	r := gin.Default()
+	r.GET("/admin", AdminAuthMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Add("Authorization", "Bearer hunter2")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/admin", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAuthMiddlewareFailsClosed(t *testing.T) {
+	// without an admin token, admin routes are unavailable, even without global authentication
+	t.Setenv("AUTH_TOKEN", "")
+	t.Setenv("ADMIN_AUTH_TOKEN", "")
+
+	r := gin.Default()
+	r.GET("/admin", AdminAuthMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Add("Authorization", "Bearer hunter2")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
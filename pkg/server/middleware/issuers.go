@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+)
+
+// APIKeyHeader carries the API key used to look up the issuers a caller is permitted to act as.
+const APIKeyHeader = "X-API-Key"
+
+// IssuerScopedAuth maps the API key of the caller to the issuers it is permitted to act as, which routers check with
+// framework.IsIssuerPermitted. The key is read from the `X-API-Key` header, falling back to a Bearer token. Requests
+// without a known key are rejected. When no keys are configured, the middleware does nothing.
+func IssuerScopedAuth(keys []config.IssuerAPIKeyConfig) gin.HandlerFunc {
+	issuersByKeyHash := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		keyHash := strings.ToLower(key.KeyHash)
+		issuersByKeyHash[keyHash] = append(issuersByKeyHash[keyHash], key.Issuers...)
+	}
+
+	return func(c *gin.Context) {
+		if len(issuersByKeyHash) == 0 {
+			c.Next()
+			return
+		}
+
+		keyHash, ok := apiKeyHash(c)
+		issuers, known := issuersByKeyHash[keyHash]
+		if !ok || !known {
+			framework.LoggingRespondErrMsg(c, "a valid API key is required", http.StatusUnauthorized)
+			c.Abort()
+			return
+		}
+
+		framework.SetPermittedIssuers(c, issuers)
+		c.Next()
+	}
+}
+
+// ClientOutputFormat records the output format configured for the API key of the caller as the default of the
+// request, which routers resolve with framework.GetOutputFormat. Unlike IssuerScopedAuth, requests without a known key
+// are let through unchanged.
+func ClientOutputFormat(keys []config.IssuerAPIKeyConfig) gin.HandlerFunc {
+	formatsByKeyHash := make(map[string]framework.OutputFormat, len(keys))
+	for _, key := range keys {
+		if key.OutputFormat != "" {
+			formatsByKeyHash[strings.ToLower(key.KeyHash)] = framework.OutputFormat(key.OutputFormat)
+		}
+	}
+
+	return func(c *gin.Context) {
+		if keyHash, ok := apiKeyHash(c); ok {
+			if format, known := formatsByKeyHash[keyHash]; known {
+				framework.SetDefaultOutputFormat(c, format)
+			}
+		}
+		c.Next()
+	}
+}
+
+// Principal records the name of the API key of the caller as the principal of the request, which routers get with
+// framework.GetPrincipal. Requests without a known key are let through, and attributed to
+// framework.AnonymousPrincipal.
+func Principal(keys []config.IssuerAPIKeyConfig) gin.HandlerFunc {
+	principalsByKeyHash := make(map[string]string, len(keys))
+	for _, key := range keys {
+		principalsByKeyHash[strings.ToLower(key.KeyHash)] = key.Principal()
+	}
+
+	return func(c *gin.Context) {
+		principal := framework.AnonymousPrincipal
+		if keyHash, ok := apiKeyHash(c); ok {
+			if known, ok := principalsByKeyHash[keyHash]; ok {
+				principal = known
+			}
+		}
+		framework.SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// apiKeyHash returns the hex encoded sha256 hash of the API key of the caller, read from the `X-API-Key` header or a
+// Bearer token. It returns false when the request carries no key.
+func apiKeyHash(c *gin.Context) (string, bool) {
+	key := c.GetHeader(APIKeyHeader)
+	if key == "" {
+		key = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	if key == "" {
+		return "", false
+	}
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:]), true
+}
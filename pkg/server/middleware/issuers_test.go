@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+)
+
+func TestIssuerScopedAuth(t *testing.T) {
+	r := gin.Default()
+	r.Use(IssuerScopedAuth([]config.IssuerAPIKeyConfig{
+		{
+			KeyHash: "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", // sha256 hash of "hunter2"
+			Issuers: []string{"did:example:a"},
+		},
+	}))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatBool(framework.IsIssuerPermitted(c, c.Query("issuer"))))
+	})
+
+	serve := func(issuer string, header, value string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/test?issuer="+issuer, nil)
+		if header != "" {
+			req.Header.Add(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("did:example:a", APIKeyHeader, "hunter2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Body.String())
+
+	w = serve("did:example:a", "Authorization", "Bearer hunter2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Body.String())
+
+	w = serve("did:example:b", APIKeyHeader, "hunter2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "false", w.Body.String())
+
+	w = serve("did:example:a", APIKeyHeader, "nonsense")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve("did:example:a", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestNoIssuerScopedAuth(t *testing.T) {
+	// no keys so any issuer is permitted
+	r := gin.Default()
+	r.Use(IssuerScopedAuth(nil))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatBool(framework.IsIssuerPermitted(c, "did:example:b")))
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Body.String())
+}
+
+func TestClientOutputFormat(t *testing.T) {
+	r := gin.Default()
+	r.Use(ClientOutputFormat([]config.IssuerAPIKeyConfig{
+		{
+			KeyHash:      "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", // sha256 hash of "hunter2"
+			OutputFormat: "jwt",
+		},
+	}))
+	r.GET("/test", func(c *gin.Context) {
+		format, err := framework.GetOutputFormat(c)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, string(format))
+	})
+
+	serve := func(query string, key string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/test"+query, nil)
+		if key != "" {
+			req.Header.Add(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("", "hunter2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "jwt", w.Body.String())
+
+	w = serve("?view=container", "hunter2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "container", w.Body.String())
+
+	// unknown keys are let through with the default format
+	w = serve("", "nonsense")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "container", w.Body.String())
+
+	w = serve("?view=xml", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPrincipal(t *testing.T) {
+	r := gin.Default()
+	r.Use(Principal([]config.IssuerAPIKeyConfig{
+		{
+			KeyHash: "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", // sha256 hash of "hunter2"
+			Name:    "billing",
+		},
+	}))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, framework.GetPrincipal(c))
+	})
+
+	serve := func(key string) string {
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		if key != "" {
+			req.Header.Add(APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "billing", serve("hunter2"))
+	assert.Equal(t, framework.AnonymousPrincipal, serve("nonsense"))
+	assert.Equal(t, framework.AnonymousPrincipal, serve(""))
+
+	// keys without a name are attributed to the start of their hash
+	assert.Equal(t, "7c211433f020", config.IssuerAPIKeyConfig{KeyHash: "7C211433F02071597741E6FF5A8EA34789ABBF43F10D9A2A5B9C9E3D2C5E5F9A"}.Principal())
+}
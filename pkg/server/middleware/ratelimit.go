@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+)
+
+// RateLimit limits each client IP to the given number of requests per window. Requests beyond it are responded to with
+// a 429 and a Retry-After header until the window ends. Requests are not limited when the limit is 0.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	limiter := &rateLimiter{limit: limit, window: window, counts: make(map[string]int)}
+	return func(c *gin.Context) {
+		if retryAfter, ok := limiter.allow(c.ClientIP(), time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			framework.LoggingRespondErrMsg(c, "Too many requests", http.StatusTooManyRequests)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimiter counts the requests of each client in fixed windows. The counts are dropped when a window ends, so that
+// memory is only held for the clients of the current window.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// allow counts a request of the client, returning false and the time left in the window when the client has reached
+// the limit.
+func (l *rateLimiter) allow(client string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.windowStart) >= l.window {
+		l.windowStart = now
+		l.counts = make(map[string]int)
+	}
+	if l.counts[client] >= l.limit {
+		return l.window - now.Sub(l.windowStart), false
+	}
+	l.counts[client]++
+	return 0, true
+}
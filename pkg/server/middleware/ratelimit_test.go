@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	r := gin.Default()
+	r.Use(RateLimit(2, time.Minute))
+	r.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusOK, serve("192.0.2.1:1234").Code)
+
+	// the third request of the client in the window is rejected, while other clients are not limited by it
+	w := serve("192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("192.0.2.2:1234").Code)
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	limiter := &rateLimiter{limit: 1, window: time.Minute, counts: make(map[string]int)}
+	start := time.Now()
+
+	_, ok := limiter.allow("client", start)
+	assert.True(t, ok)
+	retryAfter, ok := limiter.allow("client", start.Add(20*time.Second))
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// the count is dropped once the window ends
+	_, ok = limiter.allow("client", start.Add(time.Minute))
+	assert.True(t, ok)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
+)
+
+// StreamLimits records the configured limits of the NDJSON streams of list endpoints on each request. The page size
+// defaults to that of pagination.DefaultStreamLimits when not set.
+func StreamLimits(cfg config.StreamingConfig) gin.HandlerFunc {
+	limits := pagination.StreamLimits{
+		MaxItems:          cfg.MaxItems,
+		PageSize:          cfg.PageSize,
+		HeartbeatInterval: cfg.HeartbeatInterval,
+	}
+	if limits.PageSize <= 0 {
+		limits.PageSize = pagination.DefaultStreamLimits.PageSize
+	}
+	return func(c *gin.Context) {
+		pagination.SetStreamLimits(c, limits)
+		c.Next()
+	}
+}
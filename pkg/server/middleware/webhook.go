@@ -23,9 +23,15 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
-// Webhook is a middleware that publishes a webhook after the request handler has finished writing the response
+// Webhook is a middleware that publishes a webhook after the request handler has finished writing the response.
+// Events published from the outbox are left to it, since they are written along with the changes they are about.
 // TODO(https://github.com/TBD54566975/ssi-service/issues/435): currently this runs on each request even if no webhooks are registered. It should be updated to only run if webhooks are registered.
 func Webhook(webhookService *webhook.Service, noun webhook.Noun, verb webhook.Verb) gin.HandlerFunc {
+	if webhookService != nil && webhookService.Outboxed(noun, verb) {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
 	return func(c *gin.Context) {
 		// Wrap the original response writer with a new response writer that writes to the buffer
 		buf := bytes.NewBuffer([]byte{})
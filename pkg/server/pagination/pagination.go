@@ -50,34 +50,65 @@ func ParsePaginationQueryValues(c *gin.Context, pageRequest *PageRequest) bool {
 
 	queryPageToken := framework.GetQueryValue(c, PageTokenParam)
 	if queryPageToken != nil {
-		errMsg := "token value cannot be decoded"
-		tokenData, err := base64.RawURLEncoding.DecodeString(*queryPageToken)
-		if err != nil {
-			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
-			return true
-		}
-		var pageToken PageToken
-		if err := json.Unmarshal(tokenData, &pageToken); err != nil {
-			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		nextPageToken, hasErr := parsePageToken(c, *queryPageToken, pageTokenQuery(c))
+		if hasErr {
 			return true
 		}
-		pageTokenValues, err := url.ParseQuery(pageToken.EncodedQuery)
-		if err != nil {
+		pageRequest.PageToken = nextPageToken
+	}
+	return false
+}
+
+// ParsePaginationBodyValues validates the pagination params sent in a request body, and populates the passed in
+// pageRequest. Since the request has no query params to bind the PageToken to, it must have been issued for the passed
+// in query, which callers build from the rest of the body. Any error during the execution is responded to using the
+// passed in gin.Context. The return value corresponds to whether there was an error within the function.
+func ParsePaginationBodyValues(c *gin.Context, body PageRequest, query url.Values, pageRequest *PageRequest) bool {
+	if body.PageSize != nil {
+		if *body.PageSize <= 0 {
+			errMsg := fmt.Sprintf("'%s' must be greater than 0", PageSizeParam)
 			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
 			return true
 		}
+		pageRequest.PageSize = body.PageSize
+	}
 
-		query := pageTokenQuery(c)
-		if !reflect.DeepEqual(pageTokenValues, query) {
-			logrus.Warnf("expected query from token to be equal to query from request. token: %v\nrequest%v", pageTokenValues, query)
-			framework.LoggingRespondErrMsg(c, "page token must be for the same query", http.StatusBadRequest)
+	if body.PageToken != nil {
+		nextPageToken, hasErr := parsePageToken(c, *body.PageToken, query)
+		if hasErr {
 			return true
 		}
-		pageRequest.PageToken = &pageToken.NextPageToken
+		pageRequest.PageToken = nextPageToken
 	}
 	return false
 }
 
+func parsePageToken(c *gin.Context, encodedToken string, query url.Values) (*string, bool) {
+	errMsg := "token value cannot be decoded"
+	tokenData, err := base64.RawURLEncoding.DecodeString(encodedToken)
+	if err != nil {
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return nil, true
+	}
+	var pageToken PageToken
+	if err := json.Unmarshal(tokenData, &pageToken); err != nil {
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return nil, true
+	}
+	pageTokenValues, err := url.ParseQuery(pageToken.EncodedQuery)
+	if err != nil {
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return nil, true
+	}
+
+	if !reflect.DeepEqual(pageTokenValues, query) {
+		logrus.Warnf("expected query from token to be equal to query from request. token: %v\nrequest%v", pageTokenValues, query)
+		framework.LoggingRespondErrMsg(c, "page token must be for the same query", http.StatusBadRequest)
+		return nil, true
+	}
+	return &pageToken.NextPageToken, false
+}
+
 func pageTokenQuery(c *gin.Context) url.Values {
 	query := c.Request.URL.Query()
 	delete(query, PageTokenParam)
@@ -90,10 +121,15 @@ func pageTokenQuery(c *gin.Context) url.Values {
 // execution is responded to using the passed in gin.Context. The return value corresponds to whether there was an error
 // within the function.
 func MaybeSetNextPageToken(c *gin.Context, serviceNextPageToken string, respNextPageToken *string) bool {
+	return MaybeSetNextPageTokenForQuery(c, pageTokenQuery(c), serviceNextPageToken, respNextPageToken)
+}
+
+// MaybeSetNextPageTokenForQuery is like MaybeSetNextPageToken, but encodes the passed in query instead of the URL query
+// params. It is meant for requests which send their params in the body, and parse them with ParsePaginationBodyValues.
+func MaybeSetNextPageTokenForQuery(c *gin.Context, query url.Values, serviceNextPageToken string, respNextPageToken *string) bool {
 	if serviceNextPageToken != "" {
-		tokenQuery := pageTokenQuery(c)
 		pageToken := PageToken{
-			EncodedQuery:  tokenQuery.Encode(),
+			EncodedQuery:  query.Encode(),
 			NextPageToken: serviceNextPageToken,
 		}
 		nextPageTokenData, err := json.Marshal(pageToken)
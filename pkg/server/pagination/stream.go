@@ -0,0 +1,179 @@
+package pagination
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/sirupsen/logrus"
+)
+
+// NDJSONContentType is the media type list endpoints stream their items in, one JSON value per line, when the Accept
+// header of the request asks for it.
+const NDJSONContentType = "application/x-ndjson"
+
+const streamLimitsKey = "streamLimits"
+
+// StreamLimits bound the streams of list endpoints.
+type StreamLimits struct {
+	// MaxItems is the hard cap on the items of a stream, which ends with a truncated trailer once reached. Streams are
+	// not capped when 0.
+	MaxItems int
+	// PageSize is how many items are read from storage at once.
+	PageSize int
+	// HeartbeatInterval is how often an empty line is written, so that idle proxies do not cut the connection while
+	// pages are slow to read. No heartbeats are written when 0.
+	HeartbeatInterval time.Duration
+}
+
+// DefaultStreamLimits are the limits of streams of requests which have none set.
+var DefaultStreamLimits = StreamLimits{MaxItems: 100000, PageSize: 100, HeartbeatInterval: 15 * time.Second}
+
+// SetStreamLimits records the limits of the streams of the request.
+func SetStreamLimits(c *gin.Context, limits StreamLimits) {
+	c.Set(streamLimitsKey, limits)
+}
+
+func getStreamLimits(c *gin.Context) StreamLimits {
+	if got, ok := c.Get(streamLimitsKey); ok {
+		if limits, ok := got.(StreamLimits); ok {
+			return limits
+		}
+	}
+	return DefaultStreamLimits
+}
+
+// WantsStream returns whether the request accepts NDJSON, which list endpoints then stream their items as.
+func WantsStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), NDJSONContentType)
+}
+
+// StreamTrailer is the last line of a stream which ended before its last item, keyed by "trailer" so that it cannot be
+// mistaken for an item.
+type StreamTrailer struct {
+	// Error is set when the items could not be read, after those which were already streamed.
+	Error string `json:"error,omitempty"`
+	// Truncated is set when the stream reached its cap on items.
+	Truncated bool `json:"truncated,omitempty"`
+	// Streamed is the number of items streamed before the trailer.
+	Streamed int `json:"streamed"`
+}
+
+// StreamItems returns the values of a page as the items of a stream.
+func StreamItems[T any](values []T) []any {
+	items := make([]any, 0, len(values))
+	for _, value := range values {
+		items = append(items, value)
+	}
+	return items
+}
+
+// StreamPageFunc returns a page of items, and the token of the next page, which is empty after the last page.
+type StreamPageFunc func(ctx context.Context, pageRequest PageRequest) (items []any, nextPageToken string, err error)
+
+// Stream responds with the items of every page as NDJSON, writing each page as soon as it is read rather than
+// buffering the whole list, up to the cap on items of the request. Empty lines are written as heartbeats while pages
+// are read. An error reading a page or marshalling an item ends the stream with a trailer carrying errMsg, since the
+// status of the response is already sent; the error itself is logged, as it may not be safe to send back. Only a
+// failed write to the connection ends the stream without a trailer, as none could be written.
+func Stream(c *gin.Context, errMsg string, page StreamPageFunc) {
+	limits := getStreamLimits(c)
+	c.Header("Content-Type", NDJSONContentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	// the write timeout of the server bounds whole responses, which streams outlast
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	w := &streamWriter{c: c}
+	if limits.HeartbeatInterval > 0 {
+		stop := w.heartbeat(limits.HeartbeatInterval)
+		defer stop()
+	}
+
+	streamed := 0
+	pageRequest := PageRequest{PageSize: &limits.PageSize}
+	for {
+		if c.Request.Context().Err() != nil {
+			return
+		}
+		items, nextPageToken, err := page(c, pageRequest)
+		if err != nil {
+			logrus.WithError(err).Errorf("%s after streaming %d items", errMsg, streamed)
+			w.writeTrailer(StreamTrailer{Error: errMsg, Streamed: streamed})
+			return
+		}
+		for _, item := range items {
+			if limits.MaxItems > 0 && streamed == limits.MaxItems {
+				w.writeTrailer(StreamTrailer{Truncated: true, Streamed: streamed})
+				return
+			}
+			line, err := json.Marshal(item)
+			if err != nil {
+				logrus.WithError(err).Errorf("%s: marshalling item after streaming %d items", errMsg, streamed)
+				w.writeTrailer(StreamTrailer{Error: errMsg, Streamed: streamed})
+				return
+			}
+			if !w.write(append(line, '\n')) {
+				return
+			}
+			streamed++
+		}
+		if nextPageToken == "" {
+			return
+		}
+		pageRequest.PageToken = &nextPageToken
+	}
+}
+
+// streamWriter writes the lines of a stream, which the heartbeat writes to concurrently.
+type streamWriter struct {
+	mu sync.Mutex
+	c  *gin.Context
+}
+
+func (w *streamWriter) writeTrailer(trailer StreamTrailer) {
+	// trailers always marshal
+	line, _ := json.Marshal(map[string]StreamTrailer{"trailer": trailer})
+	w.write(append(line, '\n'))
+}
+
+func (w *streamWriter) write(line []byte) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.c.Writer.Write(line); err != nil {
+		logrus.WithError(err).Warn("writing stream")
+		return false
+	}
+	w.c.Writer.Flush()
+	return true
+}
+
+// heartbeat writes an empty line every interval until the returned function is called, which waits for it to stop.
+func (w *streamWriter) heartbeat(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-w.c.Request.Context().Done():
+				return
+			case <-ticker.C:
+				w.write([]byte("\n"))
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
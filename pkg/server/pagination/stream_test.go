@@ -0,0 +1,106 @@
+package pagination
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	stream := func(t *testing.T, limits StreamLimits, page StreamPageFunc) []string {
+		req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/schemas", nil)
+		req.Header.Set("Accept", NDJSONContentType)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		require.True(t, WantsStream(c))
+		SetStreamLimits(c, limits)
+		Stream(c, "could not list items", page)
+		require.Equal(t, http.StatusOK, w.Code)
+		return strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	}
+
+	// pages of size items numbered from their page token, up to total items
+	pages := func(total int) StreamPageFunc {
+		return func(_ context.Context, pageRequest PageRequest) ([]any, string, error) {
+			start := 0
+			if pageRequest.PageToken != nil {
+				start, _ = strconv.Atoi(*pageRequest.PageToken)
+			}
+			end := start + *pageRequest.PageSize
+			if end >= total {
+				end = total
+			}
+			var items []any
+			for i := start; i < end; i++ {
+				items = append(items, i)
+			}
+			if end == total {
+				return items, "", nil
+			}
+			return items, strconv.Itoa(end), nil
+		}
+	}
+
+	t.Run("streams every page", func(tt *testing.T) {
+		lines := stream(tt, StreamLimits{PageSize: 7}, pages(300))
+		require.Len(tt, lines, 300)
+		for i, line := range lines {
+			assert.Equal(tt, strconv.Itoa(i), line)
+		}
+	})
+
+	t.Run("a stream reaching the cap exactly is not truncated", func(tt *testing.T) {
+		lines := stream(tt, StreamLimits{PageSize: 10, MaxItems: 30}, pages(30))
+		assert.Len(tt, lines, 30)
+	})
+
+	t.Run("errors end the stream with a trailer", func(tt *testing.T) {
+		lines := stream(tt, StreamLimits{PageSize: 10}, func(ctx context.Context, pageRequest PageRequest) ([]any, string, error) {
+			if pageRequest.PageToken != nil {
+				return nil, "", errors.New("storage is unavailable")
+			}
+			return pages(100)(ctx, pageRequest)
+		})
+		require.Len(tt, lines, 11)
+		var trailer map[string]StreamTrailer
+		require.NoError(tt, json.Unmarshal([]byte(lines[10]), &trailer))
+		assert.Equal(tt, StreamTrailer{Error: "could not list items", Streamed: 10}, trailer["trailer"])
+		assert.NotContains(tt, lines[10], "storage is unavailable")
+	})
+
+	t.Run("items which cannot be marshalled end the stream with a trailer", func(tt *testing.T) {
+		lines := stream(tt, StreamLimits{PageSize: 10}, func(context.Context, PageRequest) ([]any, string, error) {
+			return []any{0, 1, make(chan int), 3}, "", nil
+		})
+		require.Len(tt, lines, 3)
+		var trailer map[string]StreamTrailer
+		require.NoError(tt, json.Unmarshal([]byte(lines[2]), &trailer))
+		assert.Equal(tt, StreamTrailer{Error: "could not list items", Streamed: 2}, trailer["trailer"])
+	})
+
+	t.Run("heartbeats are written while pages are read", func(tt *testing.T) {
+		lines := stream(tt, StreamLimits{PageSize: 10, HeartbeatInterval: 5 * time.Millisecond}, func(ctx context.Context, pageRequest PageRequest) ([]any, string, error) {
+			time.Sleep(50 * time.Millisecond)
+			return pages(10)(ctx, pageRequest)
+		})
+		var items []string
+		for _, line := range lines {
+			if line != "" {
+				items = append(items, line)
+			}
+		}
+		assert.Len(tt, items, 10)
+		assert.Greater(tt, len(lines), len(items))
+	})
+}
@@ -0,0 +1,721 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/admin"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	// BundlePassphraseHeader is the header carrying the passphrase used to encrypt the keys of an exported bundle.
+	// A header is used, rather than a query parameter, so that the passphrase is not written to access logs.
+	BundlePassphraseHeader = "X-Bundle-Passphrase"
+
+	// DemoWebhookSinkPath is the path, under the admin API, of the logging sink the seeded demo webhook posts to.
+	DemoWebhookSinkPath = "/seed/webhooks"
+
+	// IssuerDIDParam is the path parameter of the DID of the issuer to freeze or unfreeze.
+	IssuerDIDParam = "did"
+)
+
+// AdminRouter exposes operational endpoints meant for operators of the service, rather than its integrators.
+type AdminRouter struct {
+	config  config.SSIServiceConfig
+	service *admin.Service
+}
+
+func NewAdminRouter(cfg config.SSIServiceConfig, s svcframework.Service) (*AdminRouter, error) {
+	if s == nil {
+		return nil, errors.New("service cannot be nil")
+	}
+	adminService, ok := s.(*admin.Service)
+	if !ok {
+		return nil, fmt.Errorf("could not create admin router with service type: %s", s.Type())
+	}
+	return &AdminRouter{config: cfg, service: adminService}, nil
+}
+
+type GetConfigResponse struct {
+	// The effective configuration loaded by the running instance. Secrets are masked.
+	Config any `json:"config"`
+
+	// The base URL used to build externally visible identifiers, such as credential and schema IDs.
+	APIBase string `json:"apiBase"`
+
+	// The base URL used to build status list credential IDs.
+	StatusBase string `json:"statusBase"`
+
+	// The version of the running service.
+	Version string `json:"version"`
+
+	// The state of the optional behaviors that can be toggled through config.
+	FeatureFlags map[string]bool `json:"featureFlags"`
+}
+
+// GetConfig godoc
+//
+//	@Summary		Get the service configuration
+//	@Description	Returns the effective configuration of the running instance, including derived values. Values of
+//	@Description	sensitive fields, such as passwords and KMS credentials, are masked.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	GetConfigResponse
+//	@Router			/v1/admin/config [get]
+func (ar AdminRouter) GetConfig(c *gin.Context) {
+	resp := GetConfigResponse{
+		Config:       config.Sanitize(ar.config),
+		APIBase:      config.GetAPIBase(),
+		StatusBase:   config.GetStatusBase(),
+		Version:      config.ServiceVersion,
+		FeatureFlags: ar.config.FeatureFlags(),
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type ExportBundleResponse struct {
+	// The exported bundle, which can be given as-is to the import endpoint of another deployment.
+	admin.Bundle
+}
+
+// ExportBundle godoc
+//
+//	@Summary		Export a bundle
+//	@Description	Exports the schemas, presentation definitions, and credential manifests stored by the service as a
+//	@Description	bundle, so that they can be imported with the same IDs into another deployment. When `includeDids` is
+//	@Description	true, DIDs are exported along with their private keys, which are encrypted with the passphrase given in
+//	@Description	the `X-Bundle-Passphrase` header. The bundle is serialized as canonical JSON (RFC 8785), so that
+//	@Description	exports of the same items are byte-identical.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			includeDids			query		bool	false	"Whether to export DIDs and their keys"
+//	@Param			X-Bundle-Passphrase	header		string	false	"Passphrase for encrypting keys. Required when includeDids is true."
+//	@Success		200					{object}	ExportBundleResponse
+//	@Failure		400					{string}	string	"Bad request"
+//	@Failure		500					{string}	string	"Internal server error"
+//	@Router			/v1/admin/export [get]
+func (ar AdminRouter) ExportBundle(c *gin.Context) {
+	includeDIDs := false
+	if includeDIDsParam := framework.GetQueryValue(c, "includeDids"); includeDIDsParam != nil {
+		parsed, err := strconv.ParseBool(*includeDIDsParam)
+		if err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, "invalid includeDids value", http.StatusBadRequest)
+			return
+		}
+		includeDIDs = parsed
+	}
+
+	req := admin.ExportBundleRequest{
+		IncludeDIDs: includeDIDs,
+		Passphrase:  c.GetHeader(BundlePassphraseHeader),
+	}
+	if err := req.IsValid(); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "invalid export bundle request", http.StatusBadRequest)
+		return
+	}
+
+	exported, err := ar.service.ExportBundle(c, req)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not export bundle", http.StatusInternalServerError)
+		return
+	}
+	respondWithBundle(c, exported.Bundle)
+}
+
+// respondWithBundle serializes the bundle canonically, so that exports of the same items are byte-identical and can be
+// diffed.
+func respondWithBundle(c *gin.Context, bundle admin.Bundle) {
+	bundleBytes, err := util.CanonicalJSON(ExportBundleResponse{Bundle: bundle})
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not serialize exported bundle", http.StatusInternalServerError)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", bundleBytes)
+}
+
+type ImportBundleRequest struct {
+	// The bundle to import, as returned by the export endpoint.
+	Bundle admin.Bundle `json:"bundle" validate:"required"`
+
+	// Passphrase used to decrypt the keys in the bundle. Required when the bundle contains keys.
+	Passphrase string `json:"passphrase,omitempty"`
+
+	// When true, items whose ID already exists are replaced with the ones in the bundle. Otherwise, they are skipped.
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
+func (r ImportBundleRequest) toServiceRequest() admin.ImportBundleRequest {
+	return admin.ImportBundleRequest{
+		Bundle:     r.Bundle,
+		Passphrase: r.Passphrase,
+		Overwrite:  r.Overwrite,
+	}
+}
+
+type ImportBundleResponse struct {
+	// The outcome of importing each item of the bundle.
+	Results []admin.ImportResult `json:"results"`
+}
+
+// ImportBundle godoc
+//
+//	@Summary		Import a bundle
+//	@Description	Imports a bundle produced by the export endpoint, recreating each item with its original ID. Items
+//	@Description	whose ID already exists are skipped, unless `overwrite` is true. The outcome of each item is reported
+//	@Description	individually.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ImportBundleRequest	true	"request body"
+//	@Success		200		{object}	ImportBundleResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/admin/import [post]
+func (ar AdminRouter) ImportBundle(c *gin.Context) {
+	var request ImportBundleRequest
+	invalidImportBundleRequest := "invalid import bundle request"
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidImportBundleRequest, http.StatusBadRequest)
+		return
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidImportBundleRequest, http.StatusBadRequest)
+		return
+	}
+
+	imported, err := ar.service.ImportBundle(c, request.toServiceRequest())
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not import bundle", http.StatusBadRequest)
+		return
+	}
+	framework.Respond(c, ImportBundleResponse{Results: imported.Results}, http.StatusOK)
+}
+
+// ExportPresentationDefinition godoc
+//
+//	@Summary		Export a presentation definition
+//	@Description	Exports a presentation definition as a bundle, along with the schemas stored by the service it
+//	@Description	references, by value, so that it can be imported into another deployment. The bundle is serialized as
+//	@Description	canonical JSON (RFC 8785).
+//	@Tags			PresentationDefinitions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"ID"
+//	@Success		200	{object}	ExportBundleResponse
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/presentations/definitions/{id}/export [get]
+func (ar AdminRouter) ExportPresentationDefinition(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		framework.LoggingRespondErrMsg(c, "cannot export presentation definition without ID parameter", http.StatusBadRequest)
+		return
+	}
+	exported, err := ar.service.ExportPresentationDefinition(c, *id)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not export presentation definition with id: %s", *id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+	respondWithBundle(c, exported.Bundle)
+}
+
+// ExportManifest godoc
+//
+//	@Summary		Export a manifest
+//	@Description	Exports a credential manifest as a bundle, along with the schemas stored by the service it
+//	@Description	references, by value, so that it can be imported into another deployment. The bundle is serialized as
+//	@Description	canonical JSON (RFC 8785).
+//	@Tags			Manifests
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"ID"
+//	@Success		200	{object}	ExportBundleResponse
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/manifests/{id}/export [get]
+func (ar AdminRouter) ExportManifest(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		framework.LoggingRespondErrMsg(c, "cannot export manifest without ID parameter", http.StatusBadRequest)
+		return
+	}
+	exported, err := ar.service.ExportManifest(c, *id)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not export manifest with id: %s", *id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+	respondWithBundle(c, exported.Bundle)
+}
+
+type ImportItemRequest struct {
+	// The bundle to import, as returned by the export endpoint of a presentation definition or manifest.
+	Bundle admin.Bundle `json:"bundle" validate:"required"`
+
+	// When true, the presentation definition or manifest and its schemas keep the IDs they have in the bundle.
+	// Otherwise, they are given new IDs, and the references between them are updated.
+	PreserveIDs bool `json:"preserveIds,omitempty"`
+
+	// When true, items whose ID already exists are replaced with the ones in the bundle. Otherwise, they are skipped.
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
+func (r ImportItemRequest) toServiceRequest() admin.ImportItemRequest {
+	return admin.ImportItemRequest{
+		Bundle:      r.Bundle,
+		PreserveIDs: r.PreserveIDs,
+		Overwrite:   r.Overwrite,
+	}
+}
+
+type ImportItemResponse struct {
+	// ID of the imported presentation definition or manifest.
+	ID string `json:"id"`
+
+	// The outcome of importing each item of the bundle.
+	Results []admin.ImportResult `json:"results"`
+
+	// DIDs referenced by the imported item, such as the issuers it accepts or its own issuer, which do not exist in
+	// this deployment.
+	MissingIssuerDIDs []string `json:"missingIssuerDids"`
+}
+
+// ImportPresentationDefinition godoc
+//
+//	@Summary		Import a presentation definition
+//	@Description	Imports a bundle produced by the export endpoint of a presentation definition, recreating the
+//	@Description	definition and the schemas it references either with their original IDs, or with new IDs when
+//	@Description	`preserveIds` is false. DIDs referenced by the definition which do not exist in this deployment are
+//	@Description	reported.
+//	@Tags			PresentationDefinitions
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ImportItemRequest	true	"request body"
+//	@Success		200		{object}	ImportItemResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/presentations/definitions/import [post]
+func (ar AdminRouter) ImportPresentationDefinition(c *gin.Context) {
+	ar.importItem(c, "presentation definition", ar.service.ImportPresentationDefinition)
+}
+
+// ImportManifest godoc
+//
+//	@Summary		Import a manifest
+//	@Description	Imports a bundle produced by the export endpoint of a manifest, recreating the manifest and the
+//	@Description	schemas it references either with their original IDs, or with new IDs when `preserveIds` is false.
+//	@Description	The issuer of the manifest, and other DIDs it references, are reported when they do not exist in this
+//	@Description	deployment.
+//	@Tags			Manifests
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ImportItemRequest	true	"request body"
+//	@Success		200		{object}	ImportItemResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/manifests/import [post]
+func (ar AdminRouter) ImportManifest(c *gin.Context) {
+	ar.importItem(c, "manifest", ar.service.ImportManifest)
+}
+
+func (ar AdminRouter) importItem(c *gin.Context, itemName string, importFunc func(context.Context, admin.ImportItemRequest) (*admin.ImportItemResponse, error)) {
+	var request ImportItemRequest
+	errMsg := fmt.Sprintf("invalid import %s request", itemName)
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	imported, err := importFunc(c, request.toServiceRequest())
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, fmt.Sprintf("could not import %s", itemName), http.StatusBadRequest)
+		return
+	}
+	resp := ImportItemResponse{
+		ID:                imported.ID,
+		Results:           imported.Results,
+		MissingIssuerDIDs: imported.MissingIssuerDIDs,
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type GetStorageStatsResponse struct {
+	// The space used by the storage, including an estimate of the space compaction would reclaim, and the number of
+	// keys stored in each namespace along with approximately how many bytes they use.
+	storage.Stats
+	// The soft quota on the size of the storage, when one is configured.
+	SoftQuotaBytes int64 `json:"softQuotaBytes,omitempty"`
+	// Whether the storage is over its soft quota.
+	OverSoftQuota bool `json:"overSoftQuota"`
+}
+
+// GetStorageStats godoc
+//
+//	@Summary		Get storage stats
+//	@Description	Returns the number of keys stored in each namespace, such as credentials, DIDs, keys, and operations,
+//	@Description	and the approximate size of their keys and values. For the bolt storage, also returns the size of the
+//	@Description	storage, an estimate of the space compaction would reclaim, and whether the storage is over its soft
+//	@Description	quota. Stats are cached for a short time. Supported by the bolt and redis storage. Other storage
+//	@Description	providers respond with a 501.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	GetStorageStatsResponse
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Failure		501	{string}	string	"Not implemented by the storage provider"
+//	@Router			/v1/admin/storage [get]
+func (ar AdminRouter) GetStorageStats(c *gin.Context) {
+	stats, err := ar.service.GetStorageStats(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not get storage stats", storageErrorStatus(err))
+		return
+	}
+	resp := GetStorageStatsResponse{
+		Stats:          *stats,
+		SoftQuotaBytes: ar.service.SoftQuotaBytes(),
+		OverSoftQuota:  ar.service.IsOverSoftQuota(stats.SizeBytes),
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type CompactStorageResponse struct {
+	// The size of the storage before and after compaction, and how long it took.
+	storage.CompactionResult
+}
+
+// CompactStorage godoc
+//
+//	@Summary		Compact storage
+//	@Description	Copies the live data of the storage to a fresh file, which then replaces the current one, reclaiming
+//	@Description	the space of deleted data. Writes are paused while compaction runs. Only supported by the bolt storage.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	CompactStorageResponse
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Failure		501	{string}	string	"Not implemented by the storage provider"
+//	@Router			/v1/admin/storage/compaction [post]
+func (ar AdminRouter) CompactStorage(c *gin.Context) {
+	result, err := ar.service.CompactStorage(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not compact storage", storageErrorStatus(err))
+		return
+	}
+	framework.Respond(c, CompactStorageResponse{CompactionResult: *result}, http.StatusOK)
+}
+
+// ReindexCredentials godoc
+//
+//	@Summary		Reindex credentials
+//	@Description	Starts rebuilding the indexes of the stored credentials (issuer, subject, schema, and issuance date)
+//	@Description	from the credentials themselves, scanning them in pages. Indexes are merged rather than truncated, so
+//	@Description	the service keeps serving traffic while they are rebuilt. The returned operation reports the number of
+//	@Description	entries scanned, added, and removed once it is done.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	Operation
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/admin/reindex [post]
+func (ar AdminRouter) ReindexCredentials(c *gin.Context) {
+	op, err := ar.service.ReindexCredentials(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not start reindexing credentials", http.StatusInternalServerError)
+		return
+	}
+	framework.Respond(c, routerModel(*op), http.StatusCreated)
+}
+
+// CheckStatusConsistency godoc
+//
+//	@Summary		Check status consistency
+//	@Description	Starts comparing the stored revoked and suspended status of the credentials issued with a status list
+//	@Description	of the service to the bits of their status lists. Credentials are scanned in pages, and grouped by
+//	@Description	status list, so that each status list is decoded once. Each mismatch is counted, and published with
+//	@Description	the StatusList StatusMismatch webhook. The returned operation reports the mismatches once it is done.
+//	@Description	With `repair=true`, the status lists with mismatches are regenerated from the stored status.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			repair	query		bool	false	"Whether to regenerate the status lists with mismatches from the stored status of their credentials"
+//	@Success		201		{object}	Operation
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		501		{string}	string	"Not implemented"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/admin/status-consistency [post]
+func (ar AdminRouter) CheckStatusConsistency(c *gin.Context) {
+	repair := false
+	if repairParam := framework.GetQueryValue(c, "repair"); repairParam != nil {
+		parsed, err := strconv.ParseBool(*repairParam)
+		if err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, "invalid repair value", http.StatusBadRequest)
+			return
+		}
+		repair = parsed
+	}
+
+	op, err := ar.service.CheckStatusConsistency(c, credential.CheckStatusConsistencyRequest{Repair: repair})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, admin.ErrStatusConsistencyNotSupported) {
+			status = http.StatusNotImplemented
+		}
+		framework.LoggingRespondErrWithMsg(c, err, "could not start checking status consistency", status)
+		return
+	}
+	framework.Respond(c, routerModel(*op), http.StatusCreated)
+}
+
+type FreezeIssuerRequest struct {
+	// Why the issuer is frozen, which is returned with the errors of the requests rejected while it is frozen.
+	Reason string `json:"reason" validate:"required"`
+}
+
+type IssuerFreeze struct {
+	// The DID of the frozen issuer.
+	Issuer string `json:"issuer"`
+
+	// Why the issuer is frozen.
+	Reason string `json:"reason,omitempty"`
+
+	// When the issuer was frozen, as an RFC3339 timestamp.
+	FrozenAt string `json:"frozenAt"`
+}
+
+type ListIssuerFreezesResponse struct {
+	// The freezes of the frozen issuers, sorted by issuer.
+	Freezes []IssuerFreeze `json:"freezes"`
+}
+
+// FreezeIssuer godoc
+//
+//	@Summary		Freeze an issuer
+//	@Description	Stops issuing credentials of the issuer, singly or in batches, and updating the status of its
+//	@Description	credentials, until it is unfrozen. Rejected requests fail with a 423 naming the freeze and its reason.
+//	@Description	Verifying and reading credentials are not affected, nor are the keys of the issuer. Freezing a frozen
+//	@Description	issuer replaces the reason of its freeze. The freeze is published with the Issuer Freeze webhook.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			did		path		string				true	"Issuer DID"
+//	@Param			request	body		FreezeIssuerRequest	true	"request body"
+//	@Success		200		{object}	IssuerFreeze
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		501		{string}	string	"Not implemented"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/admin/issuers/{did}/freeze [post]
+func (ar AdminRouter) FreezeIssuer(c *gin.Context) {
+	issuer := framework.GetParam(c, IssuerDIDParam)
+	if issuer == nil {
+		framework.LoggingRespondErrMsg(c, "cannot freeze issuer without DID parameter", http.StatusBadRequest)
+		return
+	}
+
+	var request FreezeIssuerRequest
+	invalidFreezeIssuerRequest := "invalid freeze issuer request"
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidFreezeIssuerRequest, http.StatusBadRequest)
+		return
+	}
+	if err := framework.ValidateRequest(request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidFreezeIssuerRequest, http.StatusBadRequest)
+		return
+	}
+
+	freeze, err := ar.service.FreezeIssuer(c, credential.FreezeIssuerRequest{Issuer: *issuer, Reason: request.Reason})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not freeze issuer: %s", *issuer)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, issuerFreezeErrStatus(err))
+		return
+	}
+	framework.Respond(c, IssuerFreeze(*freeze), http.StatusOK)
+}
+
+// UnfreezeIssuer godoc
+//
+//	@Summary		Unfreeze an issuer
+//	@Description	Lifts the freeze of an issuer, so that its credentials are issued and their status updated again.
+//	@Description	Returns the freeze lifted, which is published with the Issuer Unfreeze webhook.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			did	path		string	true	"Issuer DID"
+//	@Success		200	{object}	IssuerFreeze
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		404	{string}	string	"Issuer is not frozen"
+//	@Failure		501	{string}	string	"Not implemented"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/admin/issuers/{did}/unfreeze [post]
+func (ar AdminRouter) UnfreezeIssuer(c *gin.Context) {
+	issuer := framework.GetParam(c, IssuerDIDParam)
+	if issuer == nil {
+		framework.LoggingRespondErrMsg(c, "cannot unfreeze issuer without DID parameter", http.StatusBadRequest)
+		return
+	}
+
+	freeze, err := ar.service.UnfreezeIssuer(c, credential.UnfreezeIssuerRequest{Issuer: *issuer})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not unfreeze issuer: %s", *issuer)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, issuerFreezeErrStatus(err))
+		return
+	}
+	framework.Respond(c, IssuerFreeze(*freeze), http.StatusOK)
+}
+
+// ListIssuerFreezes godoc
+//
+//	@Summary		List issuer freezes
+//	@Description	Lists the freezes of the frozen issuers, with their reasons.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	ListIssuerFreezesResponse
+//	@Failure		501	{string}	string	"Not implemented"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/admin/issuers/freezes [get]
+func (ar AdminRouter) ListIssuerFreezes(c *gin.Context) {
+	listed, err := ar.service.ListIssuerFreezes(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not list issuer freezes", issuerFreezeErrStatus(err))
+		return
+	}
+	freezes := make([]IssuerFreeze, 0, len(listed.Freezes))
+	for _, freeze := range listed.Freezes {
+		freezes = append(freezes, IssuerFreeze(freeze))
+	}
+	framework.Respond(c, ListIssuerFreezesResponse{Freezes: freezes}, http.StatusOK)
+}
+
+type SeedDemoDataResponse struct {
+	admin.DemoData
+}
+
+// SeedDemoData godoc
+//
+//	@Summary		Seed demo data
+//	@Description	Creates a demo issuer DID, an email schema, a presentation definition, a manifest, a revocable
+//	@Description	credential, and a webhook posting created credentials to a sink logging what it receives, all named
+//	@Description	after the `demo` tag. The credential is checked to verify. Seeding only uses the public APIs of the
+//	@Description	services, so it doubles as a smoke test. The demo data is deleted with DELETE /v1/admin/seed.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	SeedDemoDataResponse
+//	@Failure		409	{string}	string	"Demo data is already seeded"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/admin/seed [post]
+func (ar AdminRouter) SeedDemoData(c *gin.Context) {
+	req := admin.SeedDemoDataRequest{WebhookURL: config.GetServicePath(svcframework.Admin) + DemoWebhookSinkPath}
+	data, err := ar.service.SeedDemoData(c, req)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not seed demo data", demoErrorStatus(err))
+		return
+	}
+	framework.Respond(c, SeedDemoDataResponse{DemoData: *data}, http.StatusCreated)
+}
+
+// DeleteDemoData godoc
+//
+//	@Summary		Delete demo data
+//	@Description	Deletes the demo data created by POST /v1/admin/seed. Succeeds when there is none.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Success		204
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/admin/seed [delete]
+func (ar AdminRouter) DeleteDemoData(c *gin.Context) {
+	if err := ar.service.DeleteDemoData(c); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not delete demo data", demoErrorStatus(err))
+		return
+	}
+	framework.Respond(c, nil, http.StatusNoContent)
+}
+
+// DemoWebhookSink godoc
+//
+//	@Summary		Demo webhook sink
+//	@Description	Logs the noun and verb of the webhook payloads posted to it by the seeded demo webhook. It is not
+//	@Description	guarded by the admin auth middleware, since webhooks are posted without credentials, so it only
+//	@Description	accepts payloads while demo data is seeded.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body	webhook.Payload	true	"The webhook payload"
+//	@Success		204
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		404	{string}	string	"Demo data is not seeded"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/admin/seed/webhooks [post]
+func (ar AdminRouter) DemoWebhookSink(c *gin.Context) {
+	seeded, err := ar.service.IsDemoDataSeeded(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not check demo data", http.StatusInternalServerError)
+		return
+	}
+	if !seeded {
+		framework.LoggingRespondErrMsg(c, "demo data is not seeded", http.StatusNotFound)
+		return
+	}
+	var payload webhook.Payload
+	if err = framework.Decode(c.Request, &payload); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+	// the payload is posted without credentials, so its data is not logged
+	logrus.WithFields(logrus.Fields{"noun": payload.Noun, "verb": payload.Verb}).Info("demo webhook received")
+	framework.Respond(c, nil, http.StatusNoContent)
+}
+
+// demoErrorStatus maps errors of seeding and deleting demo data to a status code.
+func demoErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, admin.ErrDemoDataSeeded):
+		return http.StatusConflict
+	case errors.Is(err, admin.ErrDemoNotSupported):
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// issuerFreezeErrStatus maps errors of freezing and unfreezing issuers to a status code.
+func issuerFreezeErrStatus(err error) int {
+	switch {
+	case errors.Is(err, credential.ErrIssuerNotFrozen):
+		return http.StatusNotFound
+	case errors.Is(err, admin.ErrIssuerFreezeNotSupported):
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// storageErrorStatus maps errors of storage operations to a status code, telling apart operations the storage provider
+// does not support.
+func storageErrorStatus(err error) int {
+	if errors.Is(err, storage.ErrNotSupported) {
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
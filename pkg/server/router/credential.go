@@ -1,26 +1,60 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	schemaint "github.com/tbd54566975/ssi-service/internal/schema"
 	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 	"go.einride.tech/aip/filtering"
 )
 
 const (
-	IssuerParam  string = "issuer"
-	SubjectParam string = "subject"
-	SchemaParam  string = "schema"
+	IssuerParam   string = "issuer"
+	SubjectParam  string = "subject"
+	SchemaParam   string = "schema"
+	PurposeParam  string = "purpose"
+	DiffWithParam string = "with"
+	ExpandParam   string = "expand"
+
+	// MetadataOnlyParam lists credentials without their claims and proofs when true.
+	MetadataOnlyParam string = "metadataOnly"
+	// AcknowledgedParam lists credentials depending on whether their subject acknowledged receipt of them.
+	AcknowledgedParam string = "acknowledged"
+	// SchemaVersionParam lists credentials created against a version of their schema.
+	SchemaVersionParam string = "schemaVersion"
+	// SubjectTypeParam lists credentials whose subject has a type, such as `Organization`.
+	SubjectTypeParam string = "subjectType"
+	// CursorParam lists the credential events written after the event with this ID.
+	CursorParam string = "cursor"
+	// IncludeExpiredParam lists the expired credentials of a subject when true.
+	IncludeExpiredParam string = "includeExpired"
+	// IncludeRevokedParam lists the revoked credentials of a subject when true.
+	IncludeRevokedParam string = "includeRevoked"
+	// StateParam lists credentials in a lifecycle state, such as `ACTIVE`.
+	StateParam string = "state"
+
+	// ExpandIssuer is the value of ExpandParam which adds the display name of the issuer to each credential.
+	ExpandIssuer string = "issuer"
+	// ExpandKeyStatus is the value of ExpandParam which adds the status of the key a credential was issued with.
+	ExpandKeyStatus string = "keyStatus"
 )
 
 type CredentialRouter struct {
@@ -39,14 +73,19 @@ func NewCredentialRouter(s svcframework.Service) (*CredentialRouter, error) {
 }
 
 type BatchCreateCredentialsRequest struct {
-	// Required. The list of create credential requests. Cannot be more than {{.Services.CredentialConfig.BatchCreateMaxItems}} items.
+	// Required. The list of create credential requests, which may be of several issuers. Cannot be more than
+	// {{.Services.CredentialConfig.BatchCreateMaxItems}} items of each issuer, schema, and status purpose.
 	Requests []CreateCredentialRequest `json:"requests" maxItems:"1000" validate:"required,dive"`
+
+	// When the service creates batches in chunks, keep creating the chunks after one fails. Otherwise, the requests
+	// after a failed chunk are not attempted. Either way, the credentials of chunks created before remain.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
 }
 
-func (r BatchCreateCredentialsRequest) toServiceRequest() credential.BatchCreateCredentialsRequest {
-	var req credential.BatchCreateCredentialsRequest
+func (r BatchCreateCredentialsRequest) toServiceRequest(principal string) credential.BatchCreateCredentialsRequest {
+	req := credential.BatchCreateCredentialsRequest{ContinueOnError: r.ContinueOnError}
 	for _, routerReq := range r.Requests {
-		req.Requests = append(req.Requests, routerReq.toServiceRequest())
+		req.Requests = append(req.Requests, routerReq.toServiceRequest(principal))
 	}
 	return req
 }
@@ -54,19 +93,45 @@ func (r BatchCreateCredentialsRequest) toServiceRequest() credential.BatchCreate
 type BatchCreateCredentialsResponse struct {
 	// The credentials created.
 	Credentials []credmodel.Container `json:"credentials"`
+
+	// Non-fatal findings about each request, in the same order as `credentials`. Each warning is prefixed with its
+	// code.
+	Warnings [][]string `json:"warnings,omitempty"`
+
+	// IDs of the credentials revoked and replaced by each credential, in the same order as `credentials`.
+	ReplacedCredentialIDs [][]string `json:"replacedCredentialIds,omitempty"`
+
+	// Errors of each request, in the same order as `credentials`, when the batch was created in chunks and some
+	// failed. The credentials of the failed requests are empty, and the errors of those created are empty.
+	Errors []string `json:"errors,omitempty"`
+
+	// Violations of its schema by the credential of each request which failed for not complying with it, in the same
+	// order as `credentials`. Each violation has the JSON pointer of the offending value, the schema keyword it fails,
+	// the expected constraint, and the value, redacted at `log_redacted_paths` and truncated.
+	Violations [][]schemaint.Violation `json:"violations,omitempty"`
 }
 
 // BatchCreateCredentials godoc
 //
 //	@Summary		Batch create Credentials
-//	@Description	Create a batch of Verifiable Credentials.
+//	@Description	Create a batch of Verifiable Credentials. The batch is created in a single transaction, unless the
+//	@Description	service is configured with `batch_create_chunk_size`, in which case it is created in transactions
+//	@Description	of that many credentials. A failed chunk does not roll back the chunks created before it, which are
+//	@Description	returned along with `errors`. A 201 is returned whenever any credential is created.
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		BatchCreateCredentialsRequest	true	"The batch requests"
+//	@Param			view	query		string	false	"Output format of the credentials, `container` or `jwt`. Defaults to the format configured for the caller's API key, or `container`."
 //	@Success		201		{object}	BatchCreateCredentialsResponse
 //	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		408		{string}	string	"Request canceled before the batch was created"
+//	@Failure		409		{string}	string	"Conflict"
+//	@Failure		423		{string}	string	"Issuer is frozen"
 //	@Failure		500		{string}	string	"Internal server error"
+//	@Failure		503		{string}	string	"Server busy, retry after the Retry-After header"
+//	@Failure		504		{string}	string	"Batch timeout expired before the batch was created"
 //	@Router			/v1/credentials/batch [put]
 func (cr CredentialRouter) BatchCreateCredentials(c *gin.Context) {
 	invalidCreateCredentialRequest := "invalid batch create credential request"
@@ -76,23 +141,56 @@ func (cr CredentialRouter) BatchCreateCredentials(c *gin.Context) {
 		return
 	}
 
-	batchCreateMaxItems := cr.service.Config().BatchCreateMaxItems
-	if len(batchRequest.Requests) > batchCreateMaxItems {
-		framework.LoggingRespondErrMsg(c, fmt.Sprintf("max number of requests is %d", batchCreateMaxItems), http.StatusBadRequest)
+	format, err := framework.GetOutputFormat(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidCreateCredentialRequest, http.StatusBadRequest)
 		return
 	}
 
-	req := batchRequest.toServiceRequest()
-	batchCreateCredentialsResponse, err := cr.service.BatchCreateCredentials(c, req)
+	for _, request := range batchRequest.Requests {
+		if err = request.validateProof(); err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, invalidCreateCredentialRequest, http.StatusBadRequest)
+			return
+		}
+		if !framework.IsIssuerPermitted(c, request.Issuer) {
+			framework.LoggingRespondErrMsg(c, notPermittedIssuerMsg(request.Issuer), http.StatusForbidden)
+			return
+		}
+	}
+
+	req := batchRequest.toServiceRequest(framework.GetPrincipal(c))
+	// the batch is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	batchCreateCredentialsResponse, err := cr.service.BatchCreateCredentials(ctx, req)
 	if err != nil {
 		errMsg := "could not create credentials"
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, createCredentialErrStatus(err))
 		return
 	}
 
 	var resp BatchCreateCredentialsResponse
 	for _, cred := range batchCreateCredentialsResponse.Credentials {
-		resp.Credentials = append(resp.Credentials, cred)
+		resp.Credentials = append(resp.Credentials, formatContainer(cred, format))
+	}
+	for _, warnings := range batchCreateCredentialsResponse.Warnings {
+		if len(warnings) > 0 {
+			resp.Warnings = batchCreateCredentialsResponse.Warnings
+			break
+		}
+	}
+	for _, replacedIDs := range batchCreateCredentialsResponse.ReplacedCredentialIDs {
+		if len(replacedIDs) > 0 {
+			resp.ReplacedCredentialIDs = batchCreateCredentialsResponse.ReplacedCredentialIDs
+			break
+		}
+	}
+	resp.Errors = batchCreateCredentialsResponse.Errors
+	for _, violations := range batchCreateCredentialsResponse.Violations {
+		if len(violations) > 0 {
+			resp.Violations = batchCreateCredentialsResponse.Violations
+			break
+		}
 	}
 	framework.Respond(c, resp, http.StatusCreated)
 }
@@ -102,20 +200,31 @@ type CreateCredentialRequest struct {
 	Issuer string `json:"issuer" validate:"required" example:"did:key:z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3"`
 
 	// The id of the verificationMethod (see https://www.w3.org/TR/did-core/#verification-methods) who's privateKey is
-	// stored in ssi-service. The verificationMethod must be part of the did document associated with `issuer`.
+	// stored in ssi-service. The verificationMethod must be part of the did document associated with `issuer`, or of a
+	// DID the issuer delegated to (see /v1/dids/{method}/{id}/delegations).
 	// The private key associated with the verificationMethod's publicKey will be used to sign the credential.
 	VerificationMethodID string `json:"verificationMethodId" validate:"required" example:"did:key:z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3#z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3"`
 
 	// The subject id.
 	Subject string `json:"subject" validate:"required" example:"did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"`
 
+	// Optional. The type of the subject, such as `Organization` for a credential about a non-person entity. Set as the
+	// `type` of the credential subject, alongside its `id`. When `data` also contains a `type`, it must be the same.
+	SubjectType string `json:"subjectType,omitempty" example:"Organization"`
+
 	// A context is optional. If not present, we'll apply default, required context values.
 	Context string `json:"@context,omitempty" example:""`
 
+	// Optional. Types added to the credential's `type`, after `VerifiableCredential`. Must contain the expected type of
+	// the schema, when the schema has one.
+	Types []string `json:"types,omitempty" example:"EmailCredential"`
+
 	// A schema ID is optional. If present, we'll attempt to look it up and validate the data against it.
 	SchemaID string `json:"schemaId,omitempty" example:"30e3f9b7-0528-4f6f-8aac-b74c8843187a"`
 
 	// Claims about the subject. The keys should be predicates (e.g. "alumniOf"), and the values can be any object.
+	// Nested objects are validated against the schema, with violations pointing at the nested claim, such as
+	// `/credentialSubject/address/postalCode`. Nested objects are encoded with their keys sorted.
 	Data map[string]any `json:"data" validate:"required" swaggertype:"object,string" example:"alumniOf:did_for_uni"`
 
 	// Optional. Corresponds to `expirationDate` in https://www.w3.org/TR/vc-data-model/#expiration.
@@ -129,29 +238,94 @@ type CreateCredentialRequest struct {
 	// property set.
 	Suspendable bool `json:"suspendable,omitempty" example:"false"`
 
+	// Optional. When set, the created VC is added to a message status list, whose entries are `size` bits long and hold
+	// one of the given status values, each explained by its message. The credential starts with the status `0x0`.
+	// Cannot be combined with `revocable` or `suspendable`.
+	MessageStatus *credential.MessageStatus `json:"messageStatus,omitempty"`
+
 	// Optional. Corresponds to `evidence` in https://www.w3.org/TR/vc-data-model-2.0/#evidence
 	Evidence []any `json:"evidence" example:"[{\"id\":\"https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231\",\"type\":[\"DocumentVerification\"]}]"`
-	// TODO(gabe) support more capabilities like signature type, format, and more.
+
+	// Whether the subject may hold at most one active credential of the schema from the issuer. When the subject
+	// already holds one, the request is rejected with a 409. Also enforced by a uniqueness policy on the schema.
+	UniqueSubject bool `json:"uniqueSubject,omitempty" example:"false"`
+
+	// Whether an active credential of the schema the subject holds from the issuer is revoked and replaced, instead of
+	// the request being rejected. Implies `uniqueSubject`.
+	ReplaceExisting bool `json:"replaceExisting,omitempty" example:"false"`
+
+	// Optional. Claims added to the top level of the credential's JWT, such as `vct` for interoperability with SD-JWT
+	// VCs. Claims the credential is encoded into, such as `iss`, `sub`, and `vc`, cannot be set.
+	JWTClaims map[string]any `json:"jwtClaims,omitempty"`
+
+	// Optional. The preset setting which registered claims of the credential's JWT are set from the credential,
+	// overriding the one configured: `vc-jwt-1.1`, which sets `sub`, `jti`, `iat`, `nbf`, and `exp` and removes the
+	// properties they are set from, or `jwt-vc-issuance`, which sets them without `iat` and keeps the credential whole.
+	JWTClaimsProfile string `json:"jwtClaimsProfile,omitempty" example:"vc-jwt-1.1"`
+
+	// Optional. Re-issues the credential with the same data and schema before it expires, e.g. every `144h` for a
+	// credential valid for 7 days. Each re-issued credential links to the one it replaces with `previousCredential`.
+	// Requires an `expiry`.
+	AutoRenew *credmodel.AutoRenewPolicy `json:"autoRenew,omitempty"`
+
+	// Optional. `JWT`, the default, signs the credential as a vc-jwt. `DataIntegrity` also signs it with an embedded
+	// Data Integrity proof, of the Ed25519Signature2020 suite for Ed25519 keys and of the JsonWebSignature2020 suite for
+	// other keys, which is returned in `credential`, along with the `credentialJwt`, when it is created, read, and
+	// listed. The proof must be made with a key of the issuer, and every term of the credential must be defined by its
+	// `@context`.
+	ProofFormat string `json:"proofFormat,omitempty" example:"DataIntegrity"`
+	// TODO(gabe) support more capabilities like signature type and more.
+}
+
+// validateProof checks that the claims added to the credential's JWT are not reserved, that its claims profile exists,
+// and that its proof format is supported.
+func (c CreateCredentialRequest) validateProof() error {
+	if err := keyaccess.ValidateJWTClaims(c.JWTClaims); err != nil {
+		return err
+	}
+	if c.JWTClaimsProfile != "" {
+		if _, err := keyaccess.GetJWTClaimsPolicy(c.JWTClaimsProfile); err != nil {
+			return err
+		}
+	}
+	return credential.ValidateProofFormat(c.ProofFormat)
 }
 
-func (c CreateCredentialRequest) toServiceRequest() credential.CreateCredentialRequest {
+func (c CreateCredentialRequest) toServiceRequest(principal string) credential.CreateCredentialRequest {
 	verificationMethodID := did.FullyQualifiedVerificationMethodID(c.Issuer, c.VerificationMethodID)
 	return credential.CreateCredentialRequest{
 		Issuer:                             c.Issuer,
 		FullyQualifiedVerificationMethodID: verificationMethodID,
 		Subject:                            c.Subject,
+		SubjectType:                        c.SubjectType,
 		Context:                            c.Context,
+		Types:                              c.Types,
 		SchemaID:                           c.SchemaID,
 		Data:                               c.Data,
 		Expiry:                             c.Expiry,
 		Revocable:                          c.Revocable,
 		Suspendable:                        c.Suspendable,
+		MessageStatus:                      c.MessageStatus,
 		Evidence:                           c.Evidence,
+		UniqueSubject:                      c.UniqueSubject,
+		ReplaceExisting:                    c.ReplaceExisting,
+		JWTClaims:                          c.JWTClaims,
+		JWTClaimsProfile:                   c.JWTClaimsProfile,
+		ProofFormat:                        c.ProofFormat,
+		AutoRenew:                          c.AutoRenew,
+		CreatedBy:                          principal,
 	}
 }
 
 type CreateCredentialResponse struct {
 	credmodel.Container
+
+	// Non-fatal findings about the request, such as an expiry more than 10 years away. Each warning is prefixed with
+	// its code, which can be promoted to an error through config.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// IDs of the credentials revoked and replaced by the created credential.
+	ReplacedCredentialIDs []string `json:"replacedCredentialIds,omitempty"`
 }
 
 // CreateCredential godoc
@@ -162,9 +336,14 @@ type CreateCredentialResponse struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		CreateCredentialRequest	true	"request body"
+//	@Param			view	query		string	false	"Output format of the credentials, `container` or `jwt`. Defaults to the format configured for the caller's API key, or `container`."
 //	@Success		201		{object}	CreateCredentialResponse
 //	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		409		{string}	string	"Conflict"
+//	@Failure		423		{string}	string	"Issuer is frozen"
 //	@Failure		500		{string}	string	"Internal server error"
+//	@Failure		503		{string}	string	"Server busy, retry after the Retry-After header"
 //	@Router			/v1/credentials [put]
 func (cr CredentialRouter) CreateCredential(c *gin.Context) {
 	invalidCreateCredentialRequest := "invalid create credential request"
@@ -179,22 +358,109 @@ func (cr CredentialRouter) CreateCredential(c *gin.Context) {
 		return
 	}
 
-	req := request.toServiceRequest()
-	createCredentialResponse, err := cr.service.CreateCredential(c, req)
+	if err := request.validateProof(); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidCreateCredentialRequest, http.StatusBadRequest)
+		return
+	}
+
+	format, err := framework.GetOutputFormat(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidCreateCredentialRequest, http.StatusBadRequest)
+		return
+	}
+
+	if !framework.IsIssuerPermitted(c, request.Issuer) {
+		framework.LoggingRespondErrMsg(c, notPermittedIssuerMsg(request.Issuer), http.StatusForbidden)
+		return
+	}
+
+	req := request.toServiceRequest(framework.GetPrincipal(c))
+	// the transaction is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	createCredentialResponse, err := cr.service.CreateCredential(ctx, req)
 	if err != nil {
 		errMsg := "could not create credential"
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, createCredentialErrStatus(err))
 		return
 	}
 
-	resp := CreateCredentialResponse{Container: createCredentialResponse.Container}
+	resp := CreateCredentialResponse{
+		Container:             formatContainer(createCredentialResponse.Container, format),
+		Warnings:              createCredentialResponse.Warnings,
+		ReplacedCredentialIDs: createCredentialResponse.ReplacedCredentialIDs,
+	}
 	framework.Respond(c, resp, http.StatusCreated)
 }
 
+// createCredentialErrStatus returns the status code of an error creating a credential.
+func createCredentialErrStatus(err error) int {
+	if errors.Is(err, credential.ErrIssuerFrozen) {
+		return http.StatusLocked
+	}
+	if errors.Is(err, credential.ErrActiveCredentialExists) || errors.Is(err, credential.ErrSubjectQuotaExceeded) ||
+		errors.Is(err, storage.ErrConflict) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, credential.ErrValidityExceedsMaximum) || errors.Is(err, credential.ErrExpiryRequired) ||
+		errors.Is(err, credential.ErrMissingExpectedType) || errors.Is(err, credential.ErrInvalidAutoRenew) ||
+		errors.Is(err, credential.ErrVerificationMethodNotFound) || errors.Is(err, credential.ErrVerificationMethodNotAuthorized) ||
+		errors.Is(err, credential.ErrBatchTooLarge) || errors.Is(err, credential.ErrInjectedClaimConflict) ||
+		errors.Is(err, credential.ErrSchemaCredentialRevoked) || errors.Is(err, keyaccess.ErrUndefinedTerm) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// formatContainer returns the container of a credential in the given output format. Credentials without a JWT
+// representation are always returned in full.
+func formatContainer(container credmodel.Container, format framework.OutputFormat) credmodel.Container {
+	if format == framework.JWTOutput && container.HasJWTCredential() {
+		container.Credential = nil
+	}
+	return container
+}
+
+// isIssuerExpanded returns whether the `expand` query parameter asks for the display names of issuers, which is the
+// only expansion supported.
+func isIssuerExpanded(c *gin.Context) (bool, error) {
+	expand := framework.GetQueryValue(c, ExpandParam)
+	if expand == nil {
+		return false, nil
+	}
+	if *expand != ExpandIssuer {
+		return false, fmt.Errorf("invalid %s<%s>, must be %s", ExpandParam, *expand, ExpandIssuer)
+	}
+	return true, nil
+}
+
+// getCredentialExpansions parses the comma separated values of ExpandParam when getting a single credential, which
+// may expand its issuer and the status of its key.
+func getCredentialExpansions(c *gin.Context) (issuer bool, keyStatus bool, err error) {
+	expand := framework.GetQueryValue(c, ExpandParam)
+	if expand == nil {
+		return false, false, nil
+	}
+	for _, value := range strings.Split(*expand, ",") {
+		switch strings.TrimSpace(value) {
+		case ExpandIssuer:
+			issuer = true
+		case ExpandKeyStatus:
+			keyStatus = true
+		default:
+			return false, false, fmt.Errorf("invalid %s<%s>, must be %s or %s", ExpandParam, *expand, ExpandIssuer, ExpandKeyStatus)
+		}
+	}
+	return issuer, keyStatus, nil
+}
+
 type GetCredentialResponse struct {
 	// The `id` of this credential within SSI-Service. Same as the `id` passed in the query parameter.
 	ID string `json:"id"`
 	credmodel.Container
+
+	// Whether the key the credential was issued with is still valid. Only present when `keyStatus` is expanded.
+	KeyStatus *credential.KeyStatus `json:"keyStatus,omitempty"`
 }
 
 // GetCredential godoc
@@ -204,10 +470,12 @@ type GetCredentialResponse struct {
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		string	true	"ID of the credential within SSI-Service. Must be a UUID."
-//	@Success		200	{object}	GetCredentialResponse
-//	@Failure		400	{string}	string	"Bad request"
-//	@Failure		500	{string}	string	"Internal server error"
+//	@Param			id		path		string	true	"ID of the credential within SSI-Service. Must be a UUID."
+//	@Param			view	query		string	false	"Output format of the credential, `container` or `jwt`. Defaults to the format configured for the caller's API key, or `container`."
+//	@Param			expand	query		string	false	"Comma separated. `issuer` includes the display name of the issuer, when it is a DID managed by the service which has one. `keyStatus` includes whether the key the credential was issued with is still in the issuer's DID document, and not revoked."
+//	@Success		200		{object}	GetCredentialResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
 //	@Router			/v1/credentials/{id} [get]
 func (cr CredentialRouter) GetCredential(c *gin.Context) {
 	id := framework.GetParam(c, IDParam)
@@ -217,6 +485,18 @@ func (cr CredentialRouter) GetCredential(c *gin.Context) {
 		return
 	}
 
+	format, err := framework.GetOutputFormat(c)
+	if err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	expandIssuer, expandKeyStatus, err := getCredentialExpansions(c)
+	if err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
 	gotCredential, err := cr.service.GetCredential(c, credential.GetCredentialRequest{ID: *id})
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get credential with id: %s", *id)
@@ -224,24 +504,209 @@ func (cr CredentialRouter) GetCredential(c *gin.Context) {
 		return
 	}
 
+	if expandIssuer {
+		containers := []credmodel.Container{gotCredential.Container}
+		if err = cr.service.ExpandIssuers(c, containers); err != nil {
+			errMsg := fmt.Sprintf("could not expand issuer of credential with id: %s", *id)
+			framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+			return
+		}
+		gotCredential.Container = containers[0]
+	}
+
 	resp := GetCredentialResponse{
 		ID:        *id,
-		Container: gotCredential.Container,
+		Container: formatContainer(gotCredential.Container, format),
+	}
+	if expandKeyStatus {
+		resp.KeyStatus, err = cr.service.GetKeyStatus(c, gotCredential.Container)
+		if err != nil {
+			errMsg := fmt.Sprintf("could not get key status of credential with id: %s", *id)
+			framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+			return
+		}
 	}
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+// GetCredentialRender godoc
+//
+//	@Summary		Render a Verifiable Credential
+//	@Description	Renders a Verifiable Credential with the SVG template of its render method, which comes from the
+//	@Description	schema it was created against. Each `{{path}}` placeholder of the template, such as
+//	@Description	`{{credentialSubject.firstName}}`, is replaced with the claim at that path.
+//	@Tags			Credentials
+//	@Produce		image/svg+xml
+//	@Param			id	path		string	true	"ID of the credential within SSI-Service. Must be a UUID."
+//	@Success		200	{string}	string	"The rendered credential"
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		404	{string}	string	"The credential has no render template"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/render [get]
+func (cr CredentialRouter) GetCredentialRender(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot render credential without ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	render, err := cr.service.GetCredentialRender(c, credential.GetCredentialRenderRequest{ID: *id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not render credential with id: %s", *id)
+		status := http.StatusInternalServerError
+		if errors.Is(err, credential.ErrNoRenderTemplate) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+	c.Data(http.StatusOK, render.MediaType, render.Render)
+}
+
+type DiffCredentialsResponse struct {
+	// Changes to the subject, issuer, schema, and expiry from the credential to the one it is compared with, ordered
+	// by path.
+	Changes []credential.Change `json:"changes"`
+}
+
+// DiffCredentials godoc
+//
+//	@Summary		Diff two Verifiable Credentials
+//	@Description	Compares the `credentialSubject`, issuer, schema, and expiry of a credential with those of another
+//	@Description	one, such as the credential it was reissued as. Each change is reported with the path of the claim and
+//	@Description	its values in both credentials. Objects are compared claim by claim, while arrays are compared as a
+//	@Description	whole.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"ID of the credential within SSI-Service. Must be a UUID."
+//	@Param			with	query		string	true	"ID of the credential to compare with. Must be a UUID."
+//	@Success		200		{object}	DiffCredentialsResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Router			/v1/credentials/{id}/diff [get]
+func (cr CredentialRouter) DiffCredentials(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot diff credential without ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+	with := framework.GetQueryValue(c, DiffWithParam)
+	if with == nil {
+		errMsg := fmt.Sprintf("cannot diff credential without the %q query parameter", DiffWithParam)
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	diff, err := cr.service.DiffCredentials(c, *id, *with)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not diff credential<%s> with credential<%s>", *id, *with)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+	framework.Respond(c, DiffCredentialsResponse{Changes: diff.Changes}, http.StatusOK)
+}
+
+type StoreCredentialReceiptRequest struct {
+	// JWT signed by the subject of the credential with a key of its DID document, whose `iss` is the DID of the
+	// subject, and whose `credentialId` is the ID of the credential within SSI-Service, or its `id`.
+	ReceiptJWT keyaccess.JWT `json:"receiptJwt" validate:"required"`
+}
+
+type StoreCredentialReceiptResponse struct {
+	// The stored receipt, which is also returned with the credential.
+	Receipt credmodel.Receipt `json:"receipt"`
+}
+
+// StoreCredentialReceipt godoc
+//
+//	@Summary		Acknowledge receipt of a Verifiable Credential
+//	@Description	Stores a receipt signed by the subject of a credential, acknowledging that the credential was
+//	@Description	delivered to them, whether it was claimed from an offer, through OID4VCI, or read from this API. The
+//	@Description	receipt is verified against the DID document of the subject, and rejected when it is signed by anyone
+//	@Description	else. Acknowledged credentials can be listed with `acknowledged=true`.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"ID of the credential within SSI-Service. Must be a UUID."
+//	@Param			request	body		StoreCredentialReceiptRequest	true	"request body"
+//	@Success		201		{object}	StoreCredentialReceiptResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"The receipt is not signed by the subject of the credential"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/receipt [post]
+func (cr CredentialRouter) StoreCredentialReceipt(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot store receipt without ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	invalidReceiptRequest := "invalid store credential receipt request"
+	var request StoreCredentialReceiptRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidReceiptRequest, http.StatusBadRequest)
+		return
+	}
+	if err := framework.ValidateRequest(request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidReceiptRequest, http.StatusBadRequest)
+		return
+	}
+
+	// the transaction is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	stored, err := cr.service.StoreReceipt(ctx, credential.StoreReceiptRequest{ID: *id, ReceiptJWT: request.ReceiptJWT})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not store receipt of credential with id: %s", *id)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, credential.ErrReceiptSignerNotSubject):
+			status = http.StatusForbidden
+		case errors.Is(err, credential.ErrInvalidReceipt):
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+	framework.Respond(c, StoreCredentialReceiptResponse{Receipt: stored.Receipt}, http.StatusCreated)
+}
+
 type GetCredentialStatusResponse struct {
 	// Whether the credential has been revoked.
 	Revoked bool `json:"revoked"`
 	// Whether the credential has been suspended.
 	Suspended bool `json:"suspended"`
+	// The current status value of a credential using a message status list, e.g. `0x1`.
+	StatusValue string `json:"statusValue,omitempty"`
+	// The message of the current status value, e.g. `pending`.
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// Set to `external` for imported credentials, whose status is read from the status list credential of their issuer.
+	Source string `json:"source,omitempty"`
+	// Whether the status of an imported credential could not be checked, because the status list credential of its
+	// issuer could not be fetched, verified, or evaluated. `revoked` and `suspended` are meaningless when set.
+	StatusUnknown bool `json:"statusUnknown,omitempty"`
+	// Why the status of an imported credential could not be checked.
+	StatusUnknownReason string `json:"statusUnknownReason,omitempty"`
+	// Whether the status of an imported credential was checked against a cached status list credential of its issuer
+	// which expired, while it is refreshed in the background.
+	Stale bool `json:"stale,omitempty"`
+	// Why the status of the credential was last updated, and the code of that reason, as given with the update.
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Whether the credential is currently usable: `ACTIVE`, `SUSPENDED`, `REVOKED`, `EXPIRED`, or `DELETED`. A revoked
+	// credential is `REVOKED` even when suspended or expired, and a suspended one `SUSPENDED` even when expired. Not set
+	// when the status of an imported credential is unknown.
+	LifecycleState credmodel.LifecycleState `json:"lifecycleState,omitempty"`
 }
 
 // GetCredentialStatus godoc
 //
 //	@Summary		Get a Verifiable Credential's status
-//	@Description	Get a Verifiable Credential's status by the credential's ID
+//	@Description	Get a Verifiable Credential's status by the credential's ID. The status of an imported credential is
+//	@Description	evaluated against the status list credential of its issuer, once its signature is verified.
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
@@ -266,13 +731,72 @@ func (cr CredentialRouter) GetCredentialStatus(c *gin.Context) {
 	}
 
 	resp := GetCredentialStatusResponse{
-		Revoked:   getCredentialStatusResponse.Revoked,
-		Suspended: getCredentialStatusResponse.Suspended,
+		Revoked:             getCredentialStatusResponse.Revoked,
+		Suspended:           getCredentialStatusResponse.Suspended,
+		StatusValue:         getCredentialStatusResponse.StatusValue,
+		StatusMessage:       getCredentialStatusResponse.StatusMessage,
+		Source:              getCredentialStatusResponse.Source,
+		StatusUnknown:       getCredentialStatusResponse.StatusUnknown,
+		StatusUnknownReason: getCredentialStatusResponse.StatusUnknownReason,
+		Stale:               getCredentialStatusResponse.Stale,
+		Reason:              getCredentialStatusResponse.Reason,
+		ReasonCode:          getCredentialStatusResponse.ReasonCode,
+		LifecycleState:      getCredentialStatusResponse.LifecycleState,
 	}
 
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+type GetPublicCredentialStatusResponse struct {
+	// Whether the credential has been revoked.
+	Revoked bool `json:"revoked"`
+	// Whether the credential has been suspended.
+	Suspended bool `json:"suspended"`
+}
+
+// GetPublicCredentialStatus godoc
+//
+//	@Summary		Get whether a Verifiable Credential is revoked or suspended
+//	@Description	Get only whether a Verifiable Credential issued by the service is revoked or suspended, without
+//	@Description	authenticating, so that its subject can check it. Responses may be cached, and lookups are rate
+//	@Description	limited by client IP. Deleted credentials are not found, just like credentials that never existed.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"ID"
+//	@Success		200	{object}	GetPublicCredentialStatusResponse
+//	@Failure		404	{string}	string	"Not found"
+//	@Failure		429	{string}	string	"Too many requests"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/status/public [get]
+func (cr CredentialRouter) GetPublicCredentialStatus(maxAge time.Duration) gin.HandlerFunc {
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+	return func(c *gin.Context) {
+		id := framework.GetParam(c, IDParam)
+		if id == nil {
+			errMsg := "cannot get credential status without ID parameter"
+			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+			return
+		}
+
+		gotStatus, err := cr.service.GetPublicCredentialStatus(c, credential.GetCredentialStatusRequest{ID: *id})
+		if err != nil {
+			if errors.Is(err, credential.ErrCredentialNotFound) {
+				// the same response is cached whether the credential was deleted or never existed
+				c.Header("Cache-Control", cacheControl)
+				framework.LoggingRespondErrMsg(c, "credential not found", http.StatusNotFound)
+				return
+			}
+			errMsg := fmt.Sprintf("could not get credential status with id: %s", *id)
+			framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+			return
+		}
+
+		c.Header("Cache-Control", cacheControl)
+		framework.Respond(c, GetPublicCredentialStatusResponse{Revoked: gotStatus.Revoked, Suspended: gotStatus.Suspended}, http.StatusOK)
+	}
+}
+
 type GetCredentialStatusListResponse struct {
 	ID string `json:"id"`
 	// Credential where type includes "VerifiableCredential" and "StatusList2021".
@@ -318,151 +842,622 @@ func (cr CredentialRouter) GetCredentialStatusList(c *gin.Context) {
 	framework.Respond(c, resp, http.StatusOK)
 }
 
-type UpdateCredentialStatusRequest struct {
-	// The new revoked status of this credential. The status will be saved in the encodedList of the StatusList2021
-	// credential associated with this VC.
-	Revoked   bool `json:"revoked,omitempty"`
-	Suspended bool `json:"suspended,omitempty"`
-}
-
-func (c UpdateCredentialStatusRequest) toServiceRequest(id string) credential.UpdateCredentialStatusRequest {
-	return credential.UpdateCredentialStatusRequest{
-		ID:        id,
-		Revoked:   c.Revoked,
-		Suspended: c.Suspended,
-	}
-}
+type ListStatusListCredentialsResponse struct {
+	// The credentials whose status entries point at the status list, with their index within the list and their
+	// current status.
+	Credentials []credential.StatusListReference `json:"credentials"`
 
-type UpdateCredentialStatusResponse struct {
-	// The updated status of this credential.
-	Revoked   bool `json:"revoked"`
-	Suspended bool `json:"suspended"`
+	// Pagination token to retrieve the next page of results. If the value is "", it means no further results for the request.
+	NextPageToken string `json:"nextPageToken"`
 }
 
-type SingleUpdateCredentialStatusRequest struct {
-	// ID of the credential who's status should be updated.
-	ID string `json:"id" validate:"required"`
-	UpdateCredentialStatusRequest
-}
+// ListStatusListCredentials godoc
+//
+//	@Summary		List the credentials of a status list
+//	@Description	Lists the credentials whose status entries point at a status list credential, along with the index of
+//	@Description	each credential within the list and whether it is currently revoked or suspended. Useful when a status
+//	@Description	list must be rotated or investigated.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"ID of the status list credential"
+//	@Param			pageSize	query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
+//	@Param			pageToken	query		string	false	"When specified will give the next page of results."
+//	@Success		200			{object}	ListStatusListCredentialsResponse
+//	@Failure		400			{string}	string	"Bad request"
+//	@Failure		500			{string}	string	"Internal server error"
+//	@Router			/v1/credentials/status/{id}/credentials [get]
+func (cr CredentialRouter) ListStatusListCredentials(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		framework.LoggingRespondErrMsg(c, "cannot list credentials of status list without ID parameter", http.StatusBadRequest)
+		return
+	}
+	var pageRequest pagination.PageRequest
+	if pagination.ParsePaginationQueryValues(c, &pageRequest) {
+		return
+	}
 
-type BatchUpdateCredentialStatusRequest struct {
-	// Required. The list of update credential requests. Cannot be more than the config value in `services.credentials.batch_update_status_max_items`.
-	Requests []SingleUpdateCredentialStatusRequest `json:"requests" maxItems:"100" validate:"required,dive"`
-}
+	listed, err := cr.service.ListStatusListCredentials(c, credential.ListStatusListCredentialsRequest{ID: *id, PageRequest: &pageRequest})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not list credentials of status list with id: %s", *id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
 
-func (r BatchUpdateCredentialStatusRequest) toServiceRequest() credential.BatchUpdateCredentialStatusRequest {
-	var req credential.BatchUpdateCredentialStatusRequest
-	for _, routerReq := range r.Requests {
-		serviceReq := routerReq.toServiceRequest(routerReq.ID)
-		req.Requests = append(req.Requests, serviceReq)
+	resp := ListStatusListCredentialsResponse{Credentials: listed.Credentials}
+	if pagination.MaybeSetNextPageToken(c, listed.NextPageToken, &resp.NextPageToken) {
+		return
 	}
-	return req
+	framework.Respond(c, resp, http.StatusOK)
 }
 
-type BatchUpdateCredentialStatusResponse struct {
-	CredentialStatuses []credential.Status `json:"credentialStatuses"`
+type GetStatusListCapacityResponse struct {
+	// The capacity of each status list maintained for the issuer and schema. Empty when no status enabled credential
+	// has been issued for them.
+	StatusLists []credential.StatusListCapacity `json:"statusLists"`
 }
 
-// BatchUpdateCredentialStatus godoc
+// GetStatusListCapacity godoc
 //
-//	@Summary		Batch Update a Verifiable Credential's status
-//	@Description	Updates the status all a batch of Verifiable Credentials.
+//	@Summary		Get the capacity of Credential Status Lists
+//	@Description	Get the total number of bits, allocated indexes, and remaining indexes of the status lists maintained
+//	@Description	for an issuer and schema. Once a status list has no remaining indexes, status enabled credentials can no
+//	@Description	longer be issued for the issuer and schema.
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
-//	@Param			request	body		BatchUpdateCredentialStatusRequest	true	"request body"
-//	@Success		201		{object}	BatchUpdateCredentialStatusResponse
+//	@Param			issuer	query		string	true	"The issuer DID"
+//	@Param			schema	query		string	false	"The schema ID. Empty for credentials issued without a schema."
+//	@Param			purpose	query		string	false	"One of revocation, suspension or message. All are returned when empty."
+//	@Success		200		{object}	GetStatusListCapacityResponse
 //	@Failure		400		{string}	string	"Bad request"
 //	@Failure		500		{string}	string	"Internal server error"
-//	@Router			/v1/credentials/status/batch [put]
-func (cr CredentialRouter) BatchUpdateCredentialStatus(c *gin.Context) {
-	var batchRequest BatchUpdateCredentialStatusRequest
-	invalidCreateCredentialRequest := "invalid batch update credential request"
-	if err := framework.Decode(c.Request, &batchRequest); err != nil {
-		errMsg := invalidCreateCredentialRequest
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+//	@Router			/v1/credentials/status/capacity [get]
+func (cr CredentialRouter) GetStatusListCapacity(c *gin.Context) {
+	issuer := framework.GetQueryValue(c, IssuerParam)
+	if issuer == nil {
+		framework.LoggingRespondErrMsg(c, "cannot get status list capacity without issuer parameter", http.StatusBadRequest)
 		return
 	}
 
-	batchUpdateMaxItems := cr.service.Config().BatchUpdateStatusMaxItems
-	if len(batchRequest.Requests) > batchUpdateMaxItems {
-		framework.LoggingRespondErrMsg(c, fmt.Sprintf("max number of requests is %d", batchUpdateMaxItems), http.StatusBadRequest)
-		return
+	req := credential.GetStatusListCapacityRequest{Issuer: *issuer}
+	if schema := framework.GetQueryValue(c, SchemaParam); schema != nil {
+		req.SchemaID = *schema
+	}
+	if purpose := framework.GetQueryValue(c, PurposeParam); purpose != nil {
+		req.Purpose = statussdk.StatusPurpose(*purpose)
+		if req.Purpose != statussdk.StatusRevocation && req.Purpose != statussdk.StatusSuspension && req.Purpose != credential.MessageStatusPurpose {
+			framework.LoggingRespondErrMsg(c, fmt.Sprintf("invalid purpose parameter: %s", *purpose), http.StatusBadRequest)
+			return
+		}
 	}
 
-	req := batchRequest.toServiceRequest()
-	batchUpdateResponse, err := cr.service.BatchUpdateCredentialStatus(c, req)
-
+	capacity, err := cr.service.GetStatusListCapacity(c, req)
 	if err != nil {
-		errMsg := "could not update credentials"
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		framework.LoggingRespondErrWithMsg(c, err, "could not get status list capacity", http.StatusInternalServerError)
 		return
 	}
+	framework.Respond(c, GetStatusListCapacityResponse{StatusLists: capacity.StatusLists}, http.StatusOK)
+}
 
-	var resp BatchUpdateCredentialStatusResponse
-	resp.CredentialStatuses = append(resp.CredentialStatuses, batchUpdateResponse.CredentialStatuses...)
+type GetIssuerStatisticsResponse struct {
+	Issuer string `json:"issuer"`
 
-	framework.Respond(c, resp, http.StatusOK)
+	// The number of credentials of the issuer, in total, by status, and by schema.
+	Credentials credential.IssuerCredentialCounts `json:"credentials"`
+
+	// The capacity of each status list of the issuer, across its schemas and purposes.
+	StatusLists []credential.StatusListCapacity `json:"statusLists"`
+
+	// The number of indexes allocated, and remaining, across the status lists of the issuer, and the ratio of allocated
+	// indexes to all of them.
+	AllocatedIndexes int     `json:"allocatedIndexes"`
+	RemainingIndexes int     `json:"remainingIndexes"`
+	IndexUtilization float64 `json:"indexUtilization"`
 }
 
-// UpdateCredentialStatus godoc
+// GetIssuerStatistics godoc
 //
-//	@Summary		Update a Verifiable Credential's status
-//	@Description	Update a Verifiable Credential's status
+//	@Summary		Get the statistics of an issuer
+//	@Description	Get the number of credentials of an issuer, in total, active, revoked, suspended, and expired, along
+//	@Description	with the capacity of its status lists, so that an issuer's dashboard is served by a single call.
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
-//	@Param			id		path		string							true	"ID"
-//	@Param			request	body		UpdateCredentialStatusRequest	true	"request body"
-//	@Success		201		{object}	UpdateCredentialStatusResponse
+//	@Param			issuer	query		string	true	"The issuer DID"
+//	@Success		200		{object}	GetIssuerStatisticsResponse
 //	@Failure		400		{string}	string	"Bad request"
 //	@Failure		500		{string}	string	"Internal server error"
-//	@Router			/v1/credentials/{id}/status [put]
-func (cr CredentialRouter) UpdateCredentialStatus(c *gin.Context) {
-	id := framework.GetParam(c, IDParam)
-	if id == nil {
-		errMsg := "cannot get credential without ID parameter"
-		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+//	@Router			/v1/credentials/statistics [get]
+func (cr CredentialRouter) GetIssuerStatistics(c *gin.Context) {
+	issuer := framework.GetQueryValue(c, IssuerParam)
+	if issuer == nil {
+		framework.LoggingRespondErrMsg(c, "cannot get issuer statistics without issuer parameter", http.StatusBadRequest)
 		return
 	}
 
-	var request UpdateCredentialStatusRequest
-	invalidCreateCredentialRequest := "invalid update credential request"
-	if err := framework.Decode(c.Request, &request); err != nil {
-		errMsg := invalidCreateCredentialRequest
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+	statistics, err := cr.service.GetIssuerStatistics(c, credential.GetIssuerStatisticsRequest{Issuer: *issuer})
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not get issuer statistics", http.StatusInternalServerError)
 		return
 	}
 
-	if err := framework.ValidateRequest(request); err != nil {
-		errMsg := invalidCreateCredentialRequest
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
-		return
+	resp := GetIssuerStatisticsResponse{
+		Issuer:           statistics.Issuer,
+		Credentials:      statistics.Credentials,
+		StatusLists:      statistics.StatusLists,
+		AllocatedIndexes: statistics.AllocatedIndexes,
+		RemainingIndexes: statistics.RemainingIndexes,
+		IndexUtilization: statistics.IndexUtilization,
 	}
+	framework.Respond(c, resp, http.StatusOK)
+}
 
-	req := request.toServiceRequest(*id)
-	gotCredential, err := cr.service.UpdateCredentialStatus(c, req)
+type ListCredentialEventsResponse struct {
+	// The events written after the cursor, in the order they were written.
+	Events []credential.Event `json:"events"`
 
-	if err != nil {
+	// The ID of the last event listed, or the cursor of the request when no event was listed. Consumers store it once
+	// they have processed the events, and pass it as `cursor` to list the events written after them.
+	Cursor string `json:"cursor"`
+
+	// Pagination token to retrieve the next page of results. If the value is "", it means no further results for the request.
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// ListCredentialEvents godoc
+//
+//	@Summary		List credential events
+//	@Description	Lists the events of the credential event log, which records the creation, status updates, and deletion of
+//	@Description	credentials, written after a cursor. Integrations which poll the log, and store the returned cursor once they
+//	@Description	have processed the events, receive each event at least once. Events are only recorded when
+//	@Description	`services.credential.event_log_enabled` is set.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			cursor		query		string	false	"The ID of the last event processed. Events are listed from the start of the log when absent."
+//	@Param			pageSize	query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
+//	@Param			pageToken	query		string	false	"When specified will give the next page of results."
+//	@Success		200			{object}	ListCredentialEventsResponse
+//	@Failure		400			{string}	string	"Bad request"
+//	@Failure		500			{string}	string	"Internal server error"
+//	@Router			/v1/credentials/events [get]
+func (cr CredentialRouter) ListCredentialEvents(c *gin.Context) {
+	var pageRequest pagination.PageRequest
+	if pagination.ParsePaginationQueryValues(c, &pageRequest) {
+		return
+	}
+
+	request := credential.ListEventsRequest{PageRequest: &pageRequest}
+	if cursor := framework.GetQueryValue(c, CursorParam); cursor != nil {
+		request.Cursor = *cursor
+	}
+	listed, err := cr.service.ListEvents(c, request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, credential.ErrInvalidEventCursor) {
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, "could not list credential events", status)
+		return
+	}
+
+	resp := ListCredentialEventsResponse{Events: listed.Events, Cursor: listed.Cursor}
+	if pagination.MaybeSetNextPageToken(c, listed.NextPageToken, &resp.NextPageToken) {
+		return
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type UpdateCredentialStatusRequest struct {
+	// The new revoked status of this credential. The status will be saved in the encodedList of the StatusList2021
+	// credential associated with this VC.
+	Revoked   bool `json:"revoked,omitempty"`
+	Suspended bool `json:"suspended,omitempty"`
+
+	// The new status value of a credential using a message status list, given either as a value such as `0x1` or as
+	// one of the list's messages, such as `pending`.
+	StatusValue string `json:"statusValue,omitempty" example:"0x1"`
+
+	// The verification method the status is updated with. Required when the revocation or suspension status list of
+	// the credential is bound to a verification method through `services.credential.status_list_signing_keys`, in
+	// which case it must be that one.
+	VerificationMethodID string `json:"verificationMethodId,omitempty"`
+
+	// Why the status is updated, recorded for auditors and returned with the status of the credential.
+	Reason string `json:"reason,omitempty" example:"the subject's key was compromised"`
+
+	// The code of the reason. When `services.credential.status_reason_codes` is configured, it must be one of them.
+	ReasonCode string `json:"reasonCode,omitempty" example:"KEY_COMPROMISE"`
+}
+
+func (c UpdateCredentialStatusRequest) toServiceRequest(id, principal string) credential.UpdateCredentialStatusRequest {
+	return credential.UpdateCredentialStatusRequest{
+		ID:                   id,
+		Revoked:              c.Revoked,
+		Suspended:            c.Suspended,
+		StatusValue:          c.StatusValue,
+		VerificationMethodID: c.VerificationMethodID,
+		Reason:               c.Reason,
+		ReasonCode:           c.ReasonCode,
+		Principal:            principal,
+	}
+}
+
+type UpdateCredentialStatusResponse struct {
+	// The updated status of this credential.
+	Revoked   bool `json:"revoked"`
+	Suspended bool `json:"suspended"`
+
+	// The updated status value, and its message, of a credential using a message status list.
+	StatusValue   string `json:"statusValue,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+
+	// Why the status was last updated, and the code of that reason.
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reasonCode,omitempty"`
+}
+
+type SingleUpdateCredentialStatusRequest struct {
+	// ID of the credential who's status should be updated.
+	ID string `json:"id" validate:"required"`
+	UpdateCredentialStatusRequest
+}
+
+type BatchUpdateCredentialStatusRequest struct {
+	// Required. The list of update credential requests. Cannot be more than the config value in `services.credentials.batch_update_status_max_items`.
+	Requests []SingleUpdateCredentialStatusRequest `json:"requests" maxItems:"100" validate:"required,dive"`
+}
+
+func (r BatchUpdateCredentialStatusRequest) toServiceRequest(principal string) credential.BatchUpdateCredentialStatusRequest {
+	var req credential.BatchUpdateCredentialStatusRequest
+	for _, routerReq := range r.Requests {
+		serviceReq := routerReq.toServiceRequest(routerReq.ID, principal)
+		req.Requests = append(req.Requests, serviceReq)
+	}
+	return req
+}
+
+type BatchUpdateCredentialStatusResponse struct {
+	CredentialStatuses []credential.Status `json:"credentialStatuses"`
+}
+
+// BatchUpdateCredentialStatus godoc
+//
+//	@Summary		Batch Update a Verifiable Credential's status
+//	@Description	Updates the status all a batch of Verifiable Credentials.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		BatchUpdateCredentialStatusRequest	true	"request body"
+//	@Success		201		{object}	BatchUpdateCredentialStatusResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		408		{string}	string	"Request canceled before the statuses were updated"
+//	@Failure		409		{string}	string	"Status list updated concurrently, retry"
+//	@Failure		423		{string}	string	"Issuer is frozen"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Failure		504		{string}	string	"Batch timeout expired before the statuses were updated"
+//	@Router			/v1/credentials/status/batch [put]
+func (cr CredentialRouter) BatchUpdateCredentialStatus(c *gin.Context) {
+	var batchRequest BatchUpdateCredentialStatusRequest
+	invalidCreateCredentialRequest := "invalid batch update credential request"
+	if err := framework.Decode(c.Request, &batchRequest); err != nil {
+		errMsg := invalidCreateCredentialRequest
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	batchUpdateMaxItems := cr.service.Config().BatchUpdateStatusMaxItems
+	if len(batchRequest.Requests) > batchUpdateMaxItems {
+		framework.LoggingRespondErrMsg(c, fmt.Sprintf("max number of requests is %d", batchUpdateMaxItems), http.StatusBadRequest)
+		return
+	}
+
+	for _, request := range batchRequest.Requests {
+		if !cr.checkCredentialIssuerPermitted(c, request.ID) {
+			return
+		}
+	}
+
+	req := batchRequest.toServiceRequest(framework.GetPrincipal(c))
+	// the batch is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	batchUpdateResponse, err := cr.service.BatchUpdateCredentialStatus(ctx, req)
+
+	if err != nil {
+		errMsg := "could not update credentials"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, updateCredentialStatusErrStatus(err))
+		return
+	}
+
+	var resp BatchUpdateCredentialStatusResponse
+	resp.CredentialStatuses = append(resp.CredentialStatuses, batchUpdateResponse.CredentialStatuses...)
+
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+// UpdateCredentialStatus godoc
+//
+//	@Summary		Update a Verifiable Credential's status
+//	@Description	Update a Verifiable Credential's status
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"ID"
+//	@Param			request	body		UpdateCredentialStatusRequest	true	"request body"
+//	@Success		201		{object}	UpdateCredentialStatusResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		409		{string}	string	"Status list updated concurrently, retry"
+//	@Failure		423		{string}	string	"Issuer is frozen"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/status [put]
+func (cr CredentialRouter) UpdateCredentialStatus(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot get credential without ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var request UpdateCredentialStatusRequest
+	invalidCreateCredentialRequest := "invalid update credential request"
+	if err := framework.Decode(c.Request, &request); err != nil {
+		errMsg := invalidCreateCredentialRequest
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if err := framework.ValidateRequest(request); err != nil {
+		errMsg := invalidCreateCredentialRequest
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if !cr.checkCredentialIssuerPermitted(c, *id) {
+		return
+	}
+
+	req := request.toServiceRequest(*id, framework.GetPrincipal(c))
+	// the transaction is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	gotCredential, err := cr.service.UpdateCredentialStatus(ctx, req)
+
+	if err != nil {
 		errMsg := fmt.Sprintf("could not update credential with id: %s", req.ID)
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, updateCredentialStatusErrStatus(err))
 		return
 	}
 
 	resp := UpdateCredentialStatusResponse{
-		Revoked:   gotCredential.Revoked,
-		Suspended: gotCredential.Suspended,
+		Revoked:       gotCredential.Revoked,
+		Suspended:     gotCredential.Suspended,
+		StatusValue:   gotCredential.StatusValue,
+		StatusMessage: gotCredential.StatusMessage,
+		Reason:        gotCredential.Reason,
+		ReasonCode:    gotCredential.ReasonCode,
 	}
 
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+type CredentialStatusActionRequest struct {
+	// The verification method the status is updated with. Required when the status list of the credential is bound to
+	// a verification method through `services.credential.status_list_signing_keys`, in which case it must be that one.
+	VerificationMethodID string `json:"verificationMethodId,omitempty"`
+
+	// Why the status is updated, recorded for auditors and returned with the status of the credential. Required to
+	// revoke or suspend when `services.credential.require_status_reason` is set.
+	Reason string `json:"reason,omitempty" example:"the subject's key was compromised"`
+
+	// The code of the reason. When `services.credential.status_reason_codes` is configured, it must be one of them.
+	ReasonCode string `json:"reasonCode,omitempty" example:"KEY_COMPROMISE"`
+}
+
+func (c CredentialStatusActionRequest) toServiceRequest(id string, action credential.StatusAction, principal string) credential.StatusActionRequest {
+	return credential.StatusActionRequest{
+		ID:                   id,
+		Action:               action,
+		VerificationMethodID: c.VerificationMethodID,
+		Reason:               c.Reason,
+		ReasonCode:           c.ReasonCode,
+		Principal:            principal,
+	}
+}
+
+// RevokeCredential godoc
+//
+//	@Summary		Revoke a Verifiable Credential
+//	@Description	Revoke a Verifiable Credential issued with a revocation status. Unlike updating its status, a credential
+//	@Description	issued with another status, or without status, is never revoked, and there is no way to unrevoke it.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"ID"
+//	@Param			request	body		CredentialStatusActionRequest	false	"request body"
+//	@Success		200		{object}	UpdateCredentialStatusResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		409		{string}	string	"Conflict"
+//	@Failure		423		{string}	string	"Issuer is frozen"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/revoke [post]
+func (cr CredentialRouter) RevokeCredential(c *gin.Context) {
+	cr.applyCredentialStatusAction(c, credential.RevokeStatusAction)
+}
+
+// SuspendCredential godoc
+//
+//	@Summary		Suspend a Verifiable Credential
+//	@Description	Suspend a Verifiable Credential issued with a suspension status. Unlike updating its status, a
+//	@Description	credential issued with another status, or without status, is never suspended.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"ID"
+//	@Param			request	body		CredentialStatusActionRequest	false	"request body"
+//	@Success		200		{object}	UpdateCredentialStatusResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		409		{string}	string	"Conflict"
+//	@Failure		423		{string}	string	"Issuer is frozen"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/suspend [post]
+func (cr CredentialRouter) SuspendCredential(c *gin.Context) {
+	cr.applyCredentialStatusAction(c, credential.SuspendStatusAction)
+}
+
+// UnsuspendCredential godoc
+//
+//	@Summary		Unsuspend a Verifiable Credential
+//	@Description	Lift the suspension of a Verifiable Credential issued with a suspension status.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"ID"
+//	@Param			request	body		CredentialStatusActionRequest	false	"request body"
+//	@Success		200		{object}	UpdateCredentialStatusResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		403		{string}	string	"Forbidden"
+//	@Failure		409		{string}	string	"Conflict"
+//	@Failure		423		{string}	string	"Issuer is frozen"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/unsuspend [post]
+func (cr CredentialRouter) UnsuspendCredential(c *gin.Context) {
+	cr.applyCredentialStatusAction(c, credential.UnsuspendStatusAction)
+}
+
+// applyCredentialStatusAction updates the status of the credential with the given action. The request body is
+// optional.
+func (cr CredentialRouter) applyCredentialStatusAction(c *gin.Context, action credential.StatusAction) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := fmt.Sprintf("cannot %s credential without ID parameter", action)
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var request CredentialStatusActionRequest
+	if c.Request.ContentLength != 0 {
+		if err := framework.Decode(c.Request, &request); err != nil {
+			errMsg := fmt.Sprintf("invalid %s credential request", action)
+			framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if !cr.checkCredentialIssuerPermitted(c, *id) {
+		return
+	}
+
+	// the transaction is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	gotCredential, err := cr.service.ApplyStatusAction(ctx, request.toServiceRequest(*id, action, framework.GetPrincipal(c)))
+	if err != nil {
+		errMsg := fmt.Sprintf("could not %s credential with id: %s", action, *id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, updateCredentialStatusErrStatus(err))
+		return
+	}
+
+	resp := UpdateCredentialStatusResponse{
+		Revoked:       gotCredential.Revoked,
+		Suspended:     gotCredential.Suspended,
+		StatusValue:   gotCredential.StatusValue,
+		StatusMessage: gotCredential.StatusMessage,
+		Reason:        gotCredential.Reason,
+		ReasonCode:    gotCredential.ReasonCode,
+	}
+
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+// updateCredentialStatusErrStatus returns the status code of an error updating the status of a credential.
+func updateCredentialStatusErrStatus(err error) int {
+	if errors.Is(err, credential.ErrIssuerFrozen) {
+		return http.StatusLocked
+	}
+	if errors.Is(err, credential.ErrStatusSignerNotPermitted) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, credential.ErrStatusReasonCodeNotPermitted) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, credential.ErrStatusReasonRequired) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, credential.ErrStatusActionConflict) || errors.Is(err, storage.ErrConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// checkCredentialIssuerPermitted responds with an error and returns false when the caller may not act as the issuer of
+// the credential with the given ID.
+func (cr CredentialRouter) checkCredentialIssuerPermitted(c *gin.Context, id string) bool {
+	if !framework.IsIssuerScoped(c) {
+		return true
+	}
+	gotCredential, err := cr.service.GetCredential(c, credential.GetCredentialRequest{ID: id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not get credential with id: %s", id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return false
+	}
+	if issuer := gotCredential.Credential.IssuerID(); !framework.IsIssuerPermitted(c, issuer) {
+		framework.LoggingRespondErrMsg(c, notPermittedIssuerMsg(issuer), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func notPermittedIssuerMsg(issuer string) string {
+	return fmt.Sprintf("the API key is not permitted to act as issuer<%s>", issuer)
+}
+
 type VerifyCredentialRequest struct {
 	// A credential secured via data integrity. Must have the "proof" property set.
 	DataIntegrityCredential *credsdk.VerifiableCredential `json:"credential,omitempty"`
 
 	// A JWT that encodes a credential.
 	CredentialJWT *keyaccess.JWT `json:"credentialJwt,omitempty"`
+
+	// When set, verification fails with the `SCHEMA_MISMATCH` reason code unless the credential's `credentialSchema`
+	// has this ID.
+	ExpectedSchemaID string `json:"expectedSchemaId,omitempty"`
+
+	// When set, verification fails with the `TYPE_MISMATCH` reason code unless the credential's `type` contains every
+	// one of these types.
+	ExpectedTypes []string `json:"expectedTypes,omitempty"`
+
+	// When set, the signature is checked with this public key rather than with the key resolved from the issuer's DID
+	// document, which defends against the substitution of the documents of known issuers. Its `kid` is required, and
+	// verification fails with the `KEY_MISMATCH` reason code unless the credential is signed with that key ID, or with
+	// one ending with it when it is a fragment such as `#key-1`.
+	PinnedIssuerKey *jwx.PublicKeyJWK `json:"pinnedIssuerKey,omitempty"`
+
+	// When true, the credential is parsed and returned as `unverifiedCredential` when verification fails, such as to
+	// log the subject which presented a revoked credential.
+	ReturnCredentialOnFailure bool `json:"returnCredentialOnFailure,omitempty"`
+
+	// When true, verification fails with the `UNTRUSTED_SCHEMA` reason code unless the credential's schema, if it has
+	// one, is a `JsonSchemaCredential` held by this service, issued by one of the configured trusted schema authorities.
+	RequireTrustedSchema bool `json:"requireTrustedSchema,omitempty"`
+
+	// When true, a verification receipt signed by the service is returned as `verificationReceipt`. Requires the
+	// `verification_receipt_signer` of the credential service to be configured.
+	ReturnReceipt bool `json:"returnReceipt,omitempty"`
+
+	// When set, the credential is verified as of this past time, such as to find out whether it was valid on a given
+	// date. Its expiry and issuance are checked at that time, and the status of each of its revocation and suspension
+	// entries with a status list held by this service is reconstructed from the credential event log, as reported in
+	// `statusResults`. Signatures are always checked with the current DID document of their signer, since DIDs are
+	// not resolved as of a past version.
+	AtTime *time.Time `json:"atTime,omitempty"`
 }
 
 func (vcr VerifyCredentialRequest) IsValid() bool {
@@ -476,16 +1471,58 @@ type VerifyCredentialResponse struct {
 
 	// The reason why this credential couldn't be verified.
 	Reason string `json:"reason,omitempty"`
+
+	// Set to `SCHEMA_MISMATCH` or `TYPE_MISMATCH` when the credential is valid, but does not have the expected schema
+	// or types, to `REVOKED` or `SUSPENDED` when its status is set in a status list held by this service, and to
+	// `KEY_MISMATCH` when it is not signed with the pinned issuer key, and to `UNTRUSTED_SCHEMA` when its schema is
+	// not issued by a trusted schema authority.
+	ReasonCode string `json:"reasonCode,omitempty"`
+
+	// The current status value, and its message, of a verified credential using a message status list held by this
+	// service.
+	StatusValue   string `json:"statusValue,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+
+	// The result of checking each revocation and suspension status entry of the credential, whose `credentialStatus`
+	// may be a single entry or an array of them, against its status list held by this service.
+	StatusResults []credential.StatusResult `json:"statusResults,omitempty"`
+
+	// Set when `atTime` is set, and the credential event log does not cover the history of the status of a status
+	// entry, such as when the event log was not enabled, in which case that entry is `unknown` in `statusResults`
+	// and `verified` does not account for it.
+	StatusUnknown bool `json:"statusUnknown,omitempty"`
+
+	// The result of checking the credential's schema is issued by a trusted schema authority, when
+	// `requireTrustedSchema` is true and the credential has a schema.
+	SchemaTrust *credential.SchemaTrustResult `json:"schemaTrust,omitempty"`
+
+	// The parsed credential when verification failed and `returnCredentialOnFailure` is true. It is NOT verified, and
+	// its claims must not be trusted. Absent when the credential could not be parsed.
+	UnverifiedCredential *credsdk.VerifiableCredential `json:"unverifiedCredential,omitempty"`
+
+	// A JWT signed by the service when `returnReceipt` is true, attesting to the outcome of the verification for third
+	// parties and audit records. Its `iss` is the DID of the service and its `iat` the time of the verification, and
+	// its claims are the `credentialId`, the hex encoded SHA-256 `credentialHash` of the credential as verified, whether
+	// it was `verified`, the `reasonCode` when it was not, and the `checks` performed, in order, out of `signature`,
+	// `expiry`, `dataModel`, `schema`, `status`, `expectations`, and `trustedSchema`.
+	VerificationReceipt *keyaccess.JWT `json:"verificationReceipt,omitempty"`
 }
 
 // VerifyCredential godoc
 //
 //	@Summary		Verify a Verifiable Credential
 //	@Description	Verifies a given verifiable credential. The system does the following levels of verification:
-//	@Description	1. Makes sure the credential has a valid signature
+//	@Description	1. Makes sure the credential has a valid signature, checked with `pinnedIssuerKey` when set
 //	@Description	2. Makes sure the credential has is not expired
 //	@Description	3. Makes sure the credential complies with the VC Data Model v1.1
 //	@Description	4. If the credential has a schema, makes sure its data complies with the schema
+//	@Description	5. If `expectedSchemaId` or `expectedTypes` are set, makes sure the credential has that schema and types. When the schema of the credential is held by this service and has an `expectedType`, makes sure the credential has that type as well.
+//	@Description	6. If the credential uses a message status list held by this service, returns its current status message
+//	@Description	7. For each revocation or suspension status entry with a status list held by this service, makes sure its status is not set. The `credentialStatus` may be a single entry or an array of them.
+//	@Description	8. If `requireTrustedSchema` is set, makes sure the schema of the credential is a `JsonSchemaCredential` issued by a trusted schema authority.
+//	@Description	When `returnCredentialOnFailure` is set, a credential failing verification is returned parsed, but unverified.
+//	@Description	When `returnReceipt` is set, a verification receipt JWT signed by the service is returned, attesting to the outcome of the verification.
+//	@Description	When `atTime` is set, the credential is verified as of that past time, with its expiry, issuance, and status as they were then. Signatures are checked with current DID documents.
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
@@ -507,21 +1544,219 @@ func (cr CredentialRouter) VerifyCredential(c *gin.Context) {
 		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
 		return
 	}
+	if request.PinnedIssuerKey != nil && request.PinnedIssuerKey.KID == "" {
+		framework.LoggingRespondErrMsg(c, "pinned issuer key must have a kid", http.StatusBadRequest)
+		return
+	}
+	if request.AtTime != nil && request.AtTime.After(time.Now()) {
+		framework.LoggingRespondErrMsg(c, "atTime cannot be in the future", http.StatusBadRequest)
+		return
+	}
 
 	verificationResult, err := cr.service.VerifyCredential(c, credential.VerifyCredentialRequest{
-		DataIntegrityCredential: request.DataIntegrityCredential,
-		CredentialJWT:           request.CredentialJWT,
+		DataIntegrityCredential:   request.DataIntegrityCredential,
+		CredentialJWT:             request.CredentialJWT,
+		ExpectedSchemaID:          request.ExpectedSchemaID,
+		ExpectedTypes:             request.ExpectedTypes,
+		PinnedIssuerKey:           request.PinnedIssuerKey,
+		ReturnCredentialOnFailure: request.ReturnCredentialOnFailure,
+		RequireTrustedSchema:      request.RequireTrustedSchema,
+		ReturnReceipt:             request.ReturnReceipt,
+		AtTime:                    request.AtTime,
 	})
 	if err != nil {
 		errMsg := "could not verify credential"
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, credential.ErrVerificationReceiptsDisabled) {
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
 		return
 	}
 
-	resp := VerifyCredentialResponse{Verified: verificationResult.Verified, Reason: verificationResult.Reason}
+	resp := VerifyCredentialResponse{
+		Verified:             verificationResult.Verified,
+		Reason:               verificationResult.Reason,
+		ReasonCode:           verificationResult.ReasonCode,
+		StatusValue:          verificationResult.StatusValue,
+		StatusMessage:        verificationResult.StatusMessage,
+		StatusResults:        verificationResult.StatusResults,
+		StatusUnknown:        verificationResult.StatusUnknown,
+		SchemaTrust:          verificationResult.SchemaTrust,
+		UnverifiedCredential: verificationResult.UnverifiedCredential,
+		VerificationReceipt:  verificationResult.VerificationReceipt,
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type VerifyCredentialByIDResponse struct {
+	VerifyCredentialResponse
+
+	// Whether the credential is revoked, as stored with the credential rather than read from its status list.
+	Revoked bool `json:"revoked"`
+
+	// Whether the credential is suspended, as stored with the credential rather than read from its status list.
+	Suspended bool `json:"suspended"`
+}
+
+// VerifyCredentialByID godoc
+//
+//	@Summary		Verify a stored Verifiable Credential
+//	@Description	Verifies a credential held by this service, with the same levels of verification as `PUT /v1/credentials/verification`,
+//	@Description	without having to get the credential first. The revoked and suspended flags stored with the credential are returned
+//	@Description	alongside the result, so that a discrepancy with its status list is visible.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"ID of the credential within SSI-Service. Must be a UUID."
+//	@Success		200	{object}	VerifyCredentialByIDResponse
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		404	{string}	string	"Not found"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/verification [get]
+func (cr CredentialRouter) VerifyCredentialByID(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot verify credential without ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	verificationResult, err := cr.service.VerifyCredentialByID(c, credential.VerifyCredentialByIDRequest{ID: *id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not verify credential with id: %s", *id)
+		status := http.StatusInternalServerError
+		if errors.Is(err, credential.ErrCredentialNotFound) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	resp := VerifyCredentialByIDResponse{
+		VerifyCredentialResponse: VerifyCredentialResponse{
+			Verified:      verificationResult.Verified,
+			Reason:        verificationResult.Reason,
+			ReasonCode:    verificationResult.ReasonCode,
+			StatusValue:   verificationResult.StatusValue,
+			StatusMessage: verificationResult.StatusMessage,
+			StatusResults: verificationResult.StatusResults,
+		},
+		Revoked:   verificationResult.Revoked,
+		Suspended: verificationResult.Suspended,
+	}
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+type GetCredentialHistoryResponse struct {
+	// Credentials re-issued in place of one another under the auto-renew policy of the credential, oldest first. Only
+	// the credential itself when it was not created with an auto-renew policy.
+	Credentials []credmodel.Container `json:"credentials"`
+
+	// Why the credentials are no longer re-issued, one of `credential revoked`, `policy expired`, `maximum renewals
+	// reached`, or `issuer key revoked`. Empty while they are.
+	StoppedReason string `json:"stoppedReason,omitempty"`
+}
+
+// GetCredentialHistory godoc
+//
+//	@Summary		Get the renewal history of a credential
+//	@Description	Returns the chain of credentials re-issued under the auto-renew policy of the credential, from the first credential
+//	@Description	to the last one, which any credential of the chain can be given to get.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"ID of the credential within SSI-Service. Must be a UUID."
+//	@Success		200	{object}	GetCredentialHistoryResponse
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		404	{string}	string	"Not found"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/credentials/{id}/history [get]
+func (cr CredentialRouter) GetCredentialHistory(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot get credential history without ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	history, err := cr.service.GetCredentialHistory(c, credential.GetCredentialHistoryRequest{ID: *id})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not get history of credential with id: %s", *id)
+		status := http.StatusInternalServerError
+		if errors.Is(err, credential.ErrCredentialNotFound) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	resp := GetCredentialHistoryResponse{Credentials: history.Credentials, StoppedReason: history.StoppedReason}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type ImportCredentialRequest struct {
+	// A credential secured via data integrity. Must have the "proof" property set.
+	DataIntegrityCredential *credsdk.VerifiableCredential `json:"credential,omitempty"`
+
+	// A JWT that encodes a credential.
+	CredentialJWT *keyaccess.JWT `json:"credentialJwt,omitempty"`
+}
+
+func (icr ImportCredentialRequest) IsValid() bool {
+	return (icr.DataIntegrityCredential != nil && icr.CredentialJWT == nil) ||
+		(icr.DataIntegrityCredential == nil && icr.CredentialJWT != nil)
+}
+
+type ImportCredentialResponse struct {
+	credmodel.Container
+}
+
+// ImportCredential godoc
+//
+//	@Summary		Import a Verifiable Credential
+//	@Description	Imports a Verifiable Credential issued by another party, once its signature is verified. The status of
+//	@Description	an imported credential is read from the status list credential of its issuer, and cannot be updated.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ImportCredentialRequest	true	"request body"
+//	@Success		201		{object}	ImportCredentialResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/imports [put]
+func (cr CredentialRouter) ImportCredential(c *gin.Context) {
+	var request ImportCredentialRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		errMsg := "invalid import credential request"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if !request.IsValid() {
+		errMsg := "request must contain either a Data Integrity Credential or a JWT Credential"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	// the transaction is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	importResponse, err := cr.service.ImportCredential(ctx, credential.ImportCredentialRequest{
+		DataIntegrityCredential: request.DataIntegrityCredential,
+		CredentialJWT:           request.CredentialJWT,
+		CreatedBy:               framework.GetPrincipal(c),
+	})
+	if err != nil {
+		errMsg := "could not import credential"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	resp := ImportCredentialResponse{Container: importResponse.Container}
+	framework.Respond(c, resp, http.StatusCreated)
+}
+
 type ListCredentialsResponse struct {
 	// Array of credentials that match the query parameters.
 	Credentials []credmodel.Container `json:"credentials,omitempty"`
@@ -530,10 +1765,24 @@ type ListCredentialsResponse struct {
 	NextPageToken string `json:"nextPageToken"`
 }
 
+// ListCredentialMetadataResponse is returned instead of ListCredentialsResponse when listing with `metadataOnly=true`.
+type ListCredentialMetadataResponse struct {
+	// Metadata of the credentials that match the query parameters.
+	Credentials []credential.CredentialMetadata `json:"credentials,omitempty"`
+
+	// Pagination token to retrieve the next page of results. If the value is "", it means no further results for the request.
+	NextPageToken string `json:"nextPageToken"`
+}
+
 type listCredentialsRequest struct {
-	issuer  *string
-	schema  *string
-	subject *string
+	issuer        *string
+	schema        *string
+	subject       *string
+	acknowledged  *bool
+	schemaVersion *int
+	createdBy     *string
+	subjectType   *string
+	state         *credmodel.LifecycleState
 }
 
 func (l listCredentialsRequest) GetFilter() string {
@@ -547,6 +1796,36 @@ func (l listCredentialsRequest) GetFilter() string {
 	if l.subject != nil {
 		filter += fmt.Sprintf(`subject="%s"`, *l.subject)
 	}
+	if l.acknowledged != nil {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += fmt.Sprintf(`acknowledged=%t`, *l.acknowledged)
+	}
+	if l.schemaVersion != nil {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += fmt.Sprintf(`schemaVersion=%d`, *l.schemaVersion)
+	}
+	if l.createdBy != nil {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += fmt.Sprintf(`createdBy=%q`, *l.createdBy)
+	}
+	if l.subjectType != nil {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += fmt.Sprintf(`subjectType=%q`, *l.subjectType)
+	}
+	if l.state != nil {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += fmt.Sprintf(`state=%q`, *l.state)
+	}
 	return filter
 }
 
@@ -564,10 +1843,46 @@ func init() {
 				filtering.TypeString,
 				filtering.TypeString,
 			),
+			filtering.NewFunctionOverload(
+				filtering.FunctionOverloadEqualsBool,
+				filtering.TypeBool,
+				filtering.TypeBool,
+				filtering.TypeBool,
+			),
+			filtering.NewFunctionOverload(
+				filtering.FunctionOverloadEqualsInt,
+				filtering.TypeBool,
+				filtering.TypeInt,
+				filtering.TypeInt,
+			),
+		),
+		// Search requests can combine comparisons with `AND`, `OR` and `NOT`.
+		filtering.DeclareFunction(
+			filtering.FunctionAnd,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadAndBool, filtering.TypeBool, filtering.TypeBool, filtering.TypeBool),
+		),
+		filtering.DeclareFunction(
+			filtering.FunctionOr,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadOrBool, filtering.TypeBool, filtering.TypeBool, filtering.TypeBool),
+		),
+		filtering.DeclareFunction(
+			filtering.FunctionNot,
+			filtering.NewFunctionOverload(filtering.FunctionOverloadNotBool, filtering.TypeBool, filtering.TypeBool),
 		),
 		filtering.DeclareIdent("issuer", filtering.TypeString),
 		filtering.DeclareIdent("schema", filtering.TypeString),
+		filtering.DeclareIdent("schemaVersion", filtering.TypeInt),
 		filtering.DeclareIdent("subject", filtering.TypeString),
+		filtering.DeclareIdent(SubjectTypeParam, filtering.TypeString),
+		filtering.DeclareIdent("acknowledged", filtering.TypeBool),
+		filtering.DeclareIdent(CreatedByParam, filtering.TypeString),
+		// the lifecycle state of credentials derives from whether they are revoked, suspended, and expired
+		filtering.DeclareIdent("revoked", filtering.TypeBool),
+		filtering.DeclareIdent("suspended", filtering.TypeBool),
+		filtering.DeclareIdent("expired", filtering.TypeBool),
+		filtering.DeclareIdent(StateParam, filtering.TypeString),
+		filtering.DeclareIdent(True, filtering.TypeBool),
+		filtering.DeclareIdent(False, filtering.TypeBool),
 	)
 	if err != nil {
 		panic(err)
@@ -582,14 +1897,23 @@ func init() {
 //	@Tags			Credentials
 //	@Accept			json
 //	@Produce		json
-//	@Param			issuer		query		string	false	"The issuer id, e.g. did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
-//	@Param			schema		query		string	false	"The credentialSchema.id value to filter by"
-//	@Param			subject		query		string	false	"The credentialSubject.id value to filter by"
-//	@Param			pageSize	query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
-//	@Param			pageToken	query		string	false	"Used to indicate to the server to return a specific page of the list results. Must match a previous requests' `nextPageToken`."
-//	@Success		200			{object}	ListCredentialsResponse
-//	@Failure		400			{string}	string	"Bad request"
-//	@Failure		500			{string}	string	"Internal server error"
+//	@Param			issuer			query		string	false	"The issuer id, e.g. did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+//	@Param			schema			query		string	false	"The credentialSchema.id value to filter by"
+//	@Param			subject			query		string	false	"The credentialSubject.id value to filter by"
+//	@Param			subjectType		query		string	false	"When set, only lists credentials whose credentialSubject has that type, e.g. Organization. Can be combined with the other filters."
+//	@Param			acknowledged	query		boolean	false	"When set, only lists credentials whose subject acknowledged receipt of them, when true, or has not, when false. Can be combined with the other filters."
+//	@Param			schemaVersion	query		number	false	"When set, only lists credentials created against that version of their schema. Can be combined with the other filters."
+//	@Param			createdBy		query		string	false	"When set, only lists credentials created by that principal, the name of an API key or `anonymous`. Can be combined with the other filters."
+//	@Param			state			query		string	false	"When set, only lists credentials in that lifecycle state, `ACTIVE`, `SUSPENDED`, `REVOKED`, or `EXPIRED`. Can be combined with the other filters."
+//	@Param			pageSize		query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
+//	@Param			pageToken		query		string	false	"Used to indicate to the server to return a specific page of the list results. Must match a previous requests' `nextPageToken`."
+//	@Param			view			query		string	false	"Output format of the credentials, `container` or `jwt`. Defaults to the format configured for the caller's API key, or `container`."
+//	@Param			expand			query		string	false	"Set to `issuer` to include the display name of the issuer of each credential, when it is a DID managed by the service which has one."
+//	@Param			metadataOnly	query		boolean	false	"When true, returns only the metadata of each credential, as a ListCredentialMetadataResponse, without the credential or its JWT. Default is false."
+//	@Param			Accept			header		string	false	"Set to `application/x-ndjson` to stream every credential matching the filters, one per line, instead of a page. Pagination parameters are then ignored."
+//	@Success		200				{object}	ListCredentialsResponse
+//	@Failure		400				{string}	string	"Bad request"
+//	@Failure		500				{string}	string	"Internal server error"
 //	@Router			/v1/credentials [get]
 func (cr CredentialRouter) ListCredentials(c *gin.Context) {
 	var pageRequest pagination.PageRequest
@@ -609,10 +1933,62 @@ func (cr CredentialRouter) ListCredentials(c *gin.Context) {
 		return
 	}
 
+	format, err := framework.GetOutputFormat(c)
+	if err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	expandIssuer, err := isIssuerExpanded(c)
+	if err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var acknowledged *bool
+	if acknowledgedValue := framework.GetQueryValue(c, AcknowledgedParam); acknowledgedValue != nil {
+		isAcknowledged, err := strconv.ParseBool(*acknowledgedValue)
+		if err != nil {
+			errMsg := fmt.Sprintf("invalid %s<%s>, must be a boolean", AcknowledgedParam, *acknowledgedValue)
+			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+			return
+		}
+		acknowledged = &isAcknowledged
+	}
+
+	var schemaVersion *int
+	if schemaVersionValue := framework.GetQueryValue(c, SchemaVersionParam); schemaVersionValue != nil {
+		version, err := strconv.Atoi(*schemaVersionValue)
+		if err != nil || version < 1 {
+			errMsg := fmt.Sprintf("invalid %s<%s>, must be a positive integer", SchemaVersionParam, *schemaVersionValue)
+			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+			return
+		}
+		schemaVersion = &version
+	}
+
+	var state *credmodel.LifecycleState
+	if stateValue := framework.GetQueryValue(c, StateParam); stateValue != nil {
+		lifecycleState := credmodel.LifecycleState(*stateValue)
+		switch lifecycleState {
+		case credmodel.LifecycleActive, credmodel.LifecycleSuspended, credmodel.LifecycleRevoked, credmodel.LifecycleExpired:
+			state = &lifecycleState
+		default:
+			errMsg := fmt.Sprintf("invalid %s<%s>, must be one of ACTIVE, SUSPENDED, REVOKED, or EXPIRED", StateParam, *stateValue)
+			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+			return
+		}
+	}
+
 	req := listCredentialsRequest{
-		issuer:  issuer,
-		schema:  schema,
-		subject: subject,
+		issuer:        issuer,
+		schema:        schema,
+		subject:       subject,
+		acknowledged:  acknowledged,
+		schemaVersion: schemaVersion,
+		createdBy:     framework.GetQueryValue(c, CreatedByParam),
+		subjectType:   framework.GetQueryValue(c, SubjectTypeParam),
+		state:         state,
 	}
 
 	filter, err := filtering.ParseFilter(req, listCredentialsFilterDeclarations)
@@ -621,6 +1997,39 @@ func (cr CredentialRouter) ListCredentials(c *gin.Context) {
 		return
 	}
 
+	if metadataOnly := framework.GetQueryValue(c, MetadataOnlyParam); metadataOnly != nil {
+		isMetadataOnly, err := strconv.ParseBool(*metadataOnly)
+		if err != nil {
+			errMsg := fmt.Sprintf("invalid %s<%s>, must be a boolean", MetadataOnlyParam, *metadataOnly)
+			framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+			return
+		}
+		if isMetadataOnly {
+			cr.listCredentialMetadata(c, filter, pageRequest, expandIssuer)
+			return
+		}
+	}
+
+	if pagination.WantsStream(c) {
+		pagination.Stream(c, "could not get credentials", func(ctx context.Context, pageRequest pagination.PageRequest) ([]any, string, error) {
+			listCredentialsResponse, err := cr.service.ListCredentials(ctx, filter, pageRequest)
+			if err != nil {
+				return nil, "", err
+			}
+			if expandIssuer {
+				if err = cr.service.ExpandIssuers(ctx, listCredentialsResponse.Credentials); err != nil {
+					return nil, "", err
+				}
+			}
+			credentials := make([]any, 0, len(listCredentialsResponse.Credentials))
+			for _, cred := range listCredentialsResponse.Credentials {
+				credentials = append(credentials, formatContainer(cred, format))
+			}
+			return credentials, listCredentialsResponse.NextPageToken, nil
+		})
+		return
+	}
+
 	listCredentialsResponse, err := cr.service.ListCredentials(c, filter, pageRequest)
 	if err != nil {
 		errMsg := fmt.Sprintf("could not get credentials")
@@ -628,7 +2037,18 @@ func (cr CredentialRouter) ListCredentials(c *gin.Context) {
 		return
 	}
 
-	resp := ListCredentialsResponse{Credentials: listCredentialsResponse.Credentials}
+	if expandIssuer {
+		if err = cr.service.ExpandIssuers(c, listCredentialsResponse.Credentials); err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, "could not expand issuers of credentials", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	credentials := make([]credmodel.Container, 0, len(listCredentialsResponse.Credentials))
+	for _, cred := range listCredentialsResponse.Credentials {
+		credentials = append(credentials, formatContainer(cred, format))
+	}
+	resp := ListCredentialsResponse{Credentials: credentials}
 
 	if pagination.MaybeSetNextPageToken(c, listCredentialsResponse.NextPageToken, &resp.NextPageToken) {
 		return
@@ -636,6 +2056,202 @@ func (cr CredentialRouter) ListCredentials(c *gin.Context) {
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+// listCredentialMetadata responds with the metadata of the credentials matching the filter.
+func (cr CredentialRouter) listCredentialMetadata(c *gin.Context, filter filtering.Filter, pageRequest pagination.PageRequest, expandIssuer bool) {
+	if pagination.WantsStream(c) {
+		pagination.Stream(c, "could not get credential metadata", func(ctx context.Context, pageRequest pagination.PageRequest) ([]any, string, error) {
+			listMetadataResponse, err := cr.service.ListCredentialMetadata(ctx, filter, pageRequest)
+			if err != nil {
+				return nil, "", err
+			}
+			if expandIssuer {
+				if err = cr.service.ExpandMetadataIssuers(ctx, listMetadataResponse.Credentials); err != nil {
+					return nil, "", err
+				}
+			}
+			return pagination.StreamItems(listMetadataResponse.Credentials), listMetadataResponse.NextPageToken, nil
+		})
+		return
+	}
+
+	listMetadataResponse, err := cr.service.ListCredentialMetadata(c, filter, pageRequest)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not get credential metadata", http.StatusInternalServerError)
+		return
+	}
+
+	if expandIssuer {
+		if err = cr.service.ExpandMetadataIssuers(c, listMetadataResponse.Credentials); err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, "could not expand issuers of credentials", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	resp := ListCredentialMetadataResponse{Credentials: listMetadataResponse.Credentials}
+	if pagination.MaybeSetNextPageToken(c, listMetadataResponse.NextPageToken, &resp.NextPageToken) {
+		return
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type ListSubjectCredentialsResponse struct {
+	// The credentials of the subject, newest first.
+	Credentials []credential.SubjectCredential `json:"credentials"`
+
+	// Pagination token to retrieve the next page of results. If the value is "", it means no further results for the request.
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// ListSubjectCredentials godoc
+//
+//	@Summary		List the credentials of a subject
+//	@Description	Lists the credentials issued to a subject, sorted by issuance date with the newest first, along with
+//	@Description	the name of their schema, the display name of their issuer, their expiry, and whether they are
+//	@Description	revoked or suspended. Expired and revoked credentials are left out unless requested.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string	true	"DID of the subject"
+//	@Param			includeExpired	query		boolean	false	"When true, also lists the credentials which expired. Default is false."
+//	@Param			includeRevoked	query		boolean	false	"When true, also lists the credentials which were revoked. Default is false."
+//	@Param			pageSize		query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
+//	@Param			pageToken		query		string	false	"When specified will give the next page of results."
+//	@Success		200				{object}	ListSubjectCredentialsResponse
+//	@Failure		400				{string}	string	"Bad request"
+//	@Failure		500				{string}	string	"Internal server error"
+//	@Router			/v1/subjects/{id}/credentials [get]
+func (cr CredentialRouter) ListSubjectCredentials(c *gin.Context) {
+	subject := framework.GetParam(c, IDParam)
+	if subject == nil {
+		framework.LoggingRespondErrMsg(c, "cannot list credentials of subject without ID parameter", http.StatusBadRequest)
+		return
+	}
+	request := credential.ListSubjectCredentialsRequest{Subject: *subject}
+	for param, include := range map[string]*bool{
+		IncludeExpiredParam: &request.IncludeExpired,
+		IncludeRevokedParam: &request.IncludeRevoked,
+	} {
+		if value := framework.GetQueryValue(c, param); value != nil {
+			parsed, err := strconv.ParseBool(*value)
+			if err != nil {
+				errMsg := fmt.Sprintf("invalid %s<%s>, must be a boolean", param, *value)
+				framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+				return
+			}
+			*include = parsed
+		}
+	}
+	var pageRequest pagination.PageRequest
+	if pagination.ParsePaginationQueryValues(c, &pageRequest) {
+		return
+	}
+	request.PageRequest = &pageRequest
+
+	listed, err := cr.service.ListSubjectCredentials(c, request)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not list credentials of subject: %s", *subject)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	resp := ListSubjectCredentialsResponse{Credentials: listed.Credentials}
+	if pagination.MaybeSetNextPageToken(c, listed.NextPageToken, &resp.NextPageToken) {
+		return
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+// SearchFilterCharacterLimit bounds the filter of a search request. It is far above what fits in a URL, but parsing
+// filters can be expensive.
+const SearchFilterCharacterLimit = 64 * 1024
+
+type SearchCredentialsRequest struct {
+	// A filter over the `issuer`, `schema`, `subject`, `subjectType`, `createdBy`, `revoked`, `suspended`, `expired`,
+	// and lifecycle `state` of credentials, using the grammar in https://google.aip.dev/160.
+	// Comparisons can be combined with `AND`, `OR` and `NOT`. When empty, all credentials are returned.
+	Filter string `json:"filter,omitempty" example:"subject=\"did:key:z6Mkm...\" OR subject=\"did:key:z6Mkp...\""`
+
+	// Hint to the server of the maximum elements to return. When not set, the server will return all elements.
+	PageSize *int `json:"pageSize,omitempty"`
+
+	// Used to return a specific page of the results. Must match the `nextPageToken` of a previous search with the same
+	// filter.
+	PageToken *string `json:"pageToken,omitempty"`
+}
+
+func (r SearchCredentialsRequest) GetFilter() string {
+	return r.Filter
+}
+
+// pageTokenQuery binds page tokens to the filter of the search, since there are no query params to bind them to.
+func (r SearchCredentialsRequest) pageTokenQuery() url.Values {
+	return url.Values{"filter": []string{r.Filter}}
+}
+
+// SearchCredentials godoc
+//
+//	@Summary		Search Verifiable Credentials
+//	@Description	Lists the credentials that match a filter sent in the request body, for filters too long to be sent
+//	@Description	as query parameters. The response is the same as when listing credentials.
+//	@Tags			Credentials
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		SearchCredentialsRequest	true	"request body"
+//	@Param			view	query		string						false	"Output format of the credentials, `container` or `jwt`. Defaults to the format configured for the caller's API key, or `container`."
+//	@Success		200		{object}	ListCredentialsResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/credentials/search [post]
+func (cr CredentialRouter) SearchCredentials(c *gin.Context) {
+	var request SearchCredentialsRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		errMsg := "invalid search credentials request"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Filter) > SearchFilterCharacterLimit {
+		errMsg := fmt.Sprintf("filter longer than %d character size limit", SearchFilterCharacterLimit)
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var pageRequest pagination.PageRequest
+	if pagination.ParsePaginationBodyValues(c, pagination.PageRequest{PageSize: request.PageSize, PageToken: request.PageToken}, request.pageTokenQuery(), &pageRequest) {
+		return
+	}
+
+	format, err := framework.GetOutputFormat(c)
+	if err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	filter, err := filtering.ParseFilter(request, listCredentialsFilterDeclarations)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "the filter request is malformed", http.StatusBadRequest)
+		return
+	}
+
+	listCredentialsResponse, err := cr.service.ListCredentials(c, filter, pageRequest)
+	if err != nil {
+		errMsg := "could not search credentials"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	credentials := make([]credmodel.Container, 0, len(listCredentialsResponse.Credentials))
+	for _, cred := range listCredentialsResponse.Credentials {
+		credentials = append(credentials, formatContainer(cred, format))
+	}
+	resp := ListCredentialsResponse{Credentials: credentials}
+
+	if pagination.MaybeSetNextPageTokenForQuery(c, request.pageTokenQuery(), listCredentialsResponse.NextPageToken, &resp.NextPageToken) {
+		return
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
 // DeleteCredential godoc
 //
 //	@Summary		Delete a Verifiable Credential
@@ -646,6 +2262,7 @@ func (cr CredentialRouter) ListCredentials(c *gin.Context) {
 //	@Param			id	path		string	true	"ID of the credential to delete"
 //	@Success		204	{string}	string	"No Content"
 //	@Failure		400	{string}	string	"Bad request"
+//	@Failure		403	{string}	string	"Forbidden"
 //	@Failure		500	{string}	string	"Internal server error"
 //	@Router			/v1/credentials/{id} [delete]
 func (cr CredentialRouter) DeleteCredential(c *gin.Context) {
@@ -655,8 +2272,14 @@ func (cr CredentialRouter) DeleteCredential(c *gin.Context) {
 		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
 		return
 	}
+	if !cr.checkCredentialIssuerPermitted(c, *id) {
+		return
+	}
 
-	if err := cr.service.DeleteCredential(c, credential.DeleteCredentialRequest{ID: *id}); err != nil {
+	// the transaction is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	if err := cr.service.DeleteCredential(ctx, credential.DeleteCredentialRequest{ID: *id, Principal: framework.GetPrincipal(c)}); err != nil {
 		errMsg := fmt.Sprintf("deleting credential with id: %s", *id)
 		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
 		return
@@ -0,0 +1,180 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+)
+
+const DelegationIDParam = "delegationId"
+
+type CreateDelegationRequest struct {
+	// A credential JWT issued by the DID of the path, of type `DelegationCredential`, whose `credentialSubject.id` is
+	// the DID authorized to sign credentials naming the DID of the path as their issuer.
+	DelegationJWT keyaccess.JWT `json:"delegationJwt" validate:"required"`
+}
+
+type DelegationResponse struct {
+	ID string `json:"id"`
+	// The DID on whose behalf the delegate may sign credentials.
+	Delegator string `json:"delegator"`
+	// The DID whose keys may sign credentials naming the delegator as their issuer.
+	Delegate      string        `json:"delegate"`
+	DelegationJWT keyaccess.JWT `json:"delegationJwt"`
+	CreatedAt     string        `json:"createdAt"`
+	ExpiresAt     string        `json:"expiresAt,omitempty"`
+	Revoked       bool          `json:"revoked"`
+	RevokedAt     string        `json:"revokedAt,omitempty"`
+}
+
+func toDelegationResponse(delegation did.Delegation) DelegationResponse {
+	return DelegationResponse{
+		ID:            delegation.ID,
+		Delegator:     delegation.Delegator,
+		Delegate:      delegation.Delegate,
+		DelegationJWT: delegation.DelegationJWT,
+		CreatedAt:     delegation.CreatedAt,
+		ExpiresAt:     delegation.ExpiresAt,
+		Revoked:       delegation.Revoked,
+		RevokedAt:     delegation.RevokedAt,
+	}
+}
+
+// CreateDelegation godoc
+//
+//	@Summary		Create a delegation
+//	@Description	Stores a delegation credential by which the DID of the path authorizes another DID, the subject of
+//	@Description	the credential, to sign credentials naming the DID of the path as their issuer. The credential is
+//	@Description	verified against the DID document of its issuer. Credentials signed by the delegate carry the
+//	@Description	delegation in their `delegation` claim, so that verifiers can check it.
+//	@Tags			DecentralizedIdentifiers
+//	@Accept			json
+//	@Produce		json
+//	@Param			method	path		string					true	"Method"
+//	@Param			id		path		string					true	"ID of the delegator"
+//	@Param			request	body		CreateDelegationRequest	true	"request body"
+//	@Success		201		{object}	DelegationResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/dids/{method}/{id}/delegations [put]
+func (dr DIDRouter) CreateDelegation(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "create delegation request missing id parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var request CreateDelegationRequest
+	invalidCreateDelegationRequest := "invalid create delegation request"
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidCreateDelegationRequest, http.StatusBadRequest)
+		return
+	}
+	if err := framework.ValidateRequest(request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidCreateDelegationRequest, http.StatusBadRequest)
+		return
+	}
+
+	delegation, err := dr.service.CreateDelegation(c, did.CreateDelegationRequest{Delegator: *id, DelegationJWT: request.DelegationJWT})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not create delegation of DID<%s>", *id)
+		status := http.StatusInternalServerError
+		if errors.Is(err, did.ErrInvalidDelegation) {
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	framework.Respond(c, toDelegationResponse(*delegation), http.StatusCreated)
+}
+
+type ListDelegationsResponse struct {
+	Delegations []DelegationResponse `json:"delegations"`
+}
+
+// ListDelegations godoc
+//
+//	@Summary		List delegations
+//	@Description	Lists the delegations of the DID of the path, revoked ones included, oldest first.
+//	@Tags			DecentralizedIdentifiers
+//	@Accept			json
+//	@Produce		json
+//	@Param			method	path		string	true	"Method"
+//	@Param			id		path		string	true	"ID of the delegator"
+//	@Success		200		{object}	ListDelegationsResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/dids/{method}/{id}/delegations [get]
+func (dr DIDRouter) ListDelegations(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "list delegations request missing id parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	delegations, err := dr.service.ListDelegations(c, *id)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not list delegations of DID<%s>", *id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	resp := ListDelegationsResponse{Delegations: make([]DelegationResponse, 0, len(delegations))}
+	for _, delegation := range delegations {
+		resp.Delegations = append(resp.Delegations, toDelegationResponse(delegation))
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+// RevokeDelegation godoc
+//
+//	@Summary		Revoke a delegation
+//	@Description	Revokes a delegation of the DID of the path, so that its delegate can no longer sign credentials on
+//	@Description	its behalf. Credentials signed before are not affected. The delegation is still listed, as revoked.
+//	@Tags			DecentralizedIdentifiers
+//	@Accept			json
+//	@Produce		json
+//	@Param			method			path		string	true	"Method"
+//	@Param			id				path		string	true	"ID of the delegator"
+//	@Param			delegationId	path		string	true	"ID of the delegation"
+//	@Success		200				{object}	DelegationResponse
+//	@Failure		400				{string}	string	"Bad request"
+//	@Failure		404				{string}	string	"Not found"
+//	@Failure		500				{string}	string	"Internal server error"
+//	@Router			/v1/dids/{method}/{id}/delegations/{delegationId} [delete]
+func (dr DIDRouter) RevokeDelegation(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "revoke delegation request missing id parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+	delegationID := framework.GetParam(c, DelegationIDParam)
+	if delegationID == nil {
+		errMsg := fmt.Sprintf("revoke delegation request missing delegation id parameter for DID<%s>", *id)
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	delegation, err := dr.service.RevokeDelegation(c, did.RevokeDelegationRequest{Delegator: *id, ID: *delegationID})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not revoke delegation<%s> of DID<%s>", *delegationID, *id)
+		status := http.StatusInternalServerError
+		if errors.Is(err, did.ErrDelegationNotFound) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	framework.Respond(c, toDelegationResponse(*delegation), http.StatusOK)
+}
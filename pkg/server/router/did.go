@@ -1,11 +1,13 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/ion"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
@@ -68,6 +70,15 @@ type CreateDIDByMethodRequest struct {
 
 	// Options for creating the DID. Implementation dependent on the method.
 	Options any `json:"options,omitempty"`
+
+	// Optional human readable name of the DID, such as the name of the organization issuing with it. It is shown in
+	// place of the DID when credentials are listed with `expand=issuer`.
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Optional base64 encoded seed the key is derived from instead of being generated, so that test and staging
+	// environments can create the same DID again. It must have the length of a private key of the key type, such as 32
+	// bytes for Ed25519. Anyone knowing the seed knows the private key. Only supported for the `key` method.
+	Seed []byte `json:"seed,omitempty"`
 }
 
 type CreateDIDByMethodResponse struct {
@@ -111,7 +122,7 @@ func (dr DIDRouter) CreateDIDByMethod(c *gin.Context) {
 	}
 
 	// TODO(gabe) check if the key type is supported for the method, to tell whether this is a bad req or internal error
-	createDIDRequest, err := toCreateDIDRequest(didsdk.Method(*method), request)
+	createDIDRequest, err := toCreateDIDRequest(didsdk.Method(*method), request, framework.GetPrincipal(c))
 	if err != nil {
 		errMsg := fmt.Sprintf("%s: could not create DID for method<%s> with key type: %s", invalidCreateDIDRequest, *method, request.KeyType)
 		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
@@ -120,7 +131,11 @@ func (dr DIDRouter) CreateDIDByMethod(c *gin.Context) {
 	createDIDResponse, err := dr.service.CreateDIDByMethod(c, *createDIDRequest)
 	if err != nil {
 		errMsg := fmt.Sprintf("could not create DID for method<%s> with key type: %s", *method, request.KeyType)
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, did.ErrInvalidSeed) {
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
 		return
 	}
 
@@ -223,10 +238,16 @@ func toUpdateIONDIDRequest(id string, request UpdateDIDByMethodRequest) (*did.Up
 }
 
 // toCreateDIDRequest converts CreateDIDByMethodRequest to did.CreateDIDRequest, parsing options according to method
-func toCreateDIDRequest(m didsdk.Method, request CreateDIDByMethodRequest) (*did.CreateDIDRequest, error) {
+func toCreateDIDRequest(m didsdk.Method, request CreateDIDByMethodRequest, principal string) (*did.CreateDIDRequest, error) {
 	createRequest := did.CreateDIDRequest{
-		Method:  m,
-		KeyType: request.KeyType,
+		Method:      m,
+		KeyType:     request.KeyType,
+		DisplayName: request.DisplayName,
+		Seed:        request.Seed,
+		CreatedBy:   principal,
+	}
+	if len(request.Seed) > 0 && m != didsdk.KeyMethod {
+		return nil, fmt.Errorf("seed is not supported for method<%s>", m)
 	}
 
 	// check if options are present
@@ -273,7 +294,11 @@ func optionsToType(options any, out any) error {
 }
 
 type GetDIDByMethodResponse struct {
-	DID didsdk.Document `json:"did"`
+	DID         didsdk.Document `json:"did"`
+	DisplayName string          `json:"displayName,omitempty"`
+	// Principal which created the DID: the name of the API key of its caller, or `anonymous`. Not set for DIDs
+	// created before principals were recorded.
+	CreatedBy string `json:"createdBy,omitempty"`
 }
 
 // GetDIDByMethod godoc
@@ -313,13 +338,78 @@ func (dr DIDRouter) GetDIDByMethod(c *gin.Context) {
 		return
 	}
 
-	resp := GetDIDByMethodResponse{DID: gotDID.DID}
+	resp := GetDIDByMethodResponse{DID: gotDID.DID, DisplayName: gotDID.DisplayName, CreatedBy: gotDID.CreatedBy}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+type UpdateDIDMetadataRequest struct {
+	// Human readable name of the DID. An empty name removes it.
+	DisplayName string `json:"displayName"`
+}
+
+type UpdateDIDMetadataResponse struct {
+	DID         didsdk.Document `json:"did"`
+	DisplayName string          `json:"displayName,omitempty"`
+}
+
+// UpdateDIDMetadata godoc
+//
+//	@Summary		Update the metadata of a DID
+//	@Description	Updates the metadata the service keeps about a DID it manages, such as its display name. The DID
+//	@Description	document itself is not changed.
+//	@Tags			DecentralizedIdentifiers
+//	@Accept			json
+//	@Produce		json
+//	@Param			method	path		string						true	"Method"
+//	@Param			id		path		string						true	"ID"
+//	@Param			request	body		UpdateDIDMetadataRequest	true	"request body"
+//	@Success		200		{object}	UpdateDIDMetadataResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		404		{string}	string	"Not found"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/dids/{method}/{id} [patch]
+func (dr DIDRouter) UpdateDIDMetadata(c *gin.Context) {
+	method := framework.GetParam(c, MethodParam)
+	if method == nil {
+		errMsg := "update DID metadata request missing method parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := fmt.Sprintf("update DID metadata request missing id parameter for method: %s", *method)
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var request UpdateDIDMetadataRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "invalid update DID metadata request", http.StatusBadRequest)
+		return
+	}
+
+	updateRequest := did.UpdateDIDMetadataRequest{Method: didsdk.Method(*method), ID: *id, DisplayName: request.DisplayName}
+	updatedDID, err := dr.service.UpdateDIDMetadata(c, updateRequest)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not update metadata of DID<%s>", *id)
+		status := http.StatusInternalServerError
+		if errors.Is(err, did.ErrDIDNotFound) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	resp := UpdateDIDMetadataResponse{DID: updatedDID.DID, DisplayName: updatedDID.DisplayName}
 	framework.Respond(c, resp, http.StatusOK)
 }
 
 type ListDIDsByMethodResponse struct {
 	DIDs []didsdk.Document `json:"dids,omitempty"`
 
+	// Principal which created each DID listed, keyed by the DID, for the DIDs created since principals were recorded.
+	CreatedBy map[string]string `json:"createdBy,omitempty"`
+
 	// Pagination token to retrieve the next page of results. If the value is "", it means no further results for the request.
 	NextPageToken string `json:"nextPageToken"`
 }
@@ -340,8 +430,10 @@ type GetDIDsRequest struct {
 //	@Produce		json
 //	@Param			method		path		string	true	"Method must be one returned by GET /v1/dids"
 //	@Param			deleted		query		boolean	false	"When true, returns soft-deleted DIDs. Otherwise, returns DIDs that have not been soft-deleted. Default is false."
+//	@Param			createdBy	query		string	false	"When set, only lists DIDs created by that principal, the name of an API key or `anonymous`."
 //	@Param			pageSize	query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
 //	@Param			pageToken	query		string	false	"Used to indicate to the server to return a specific page of the list results. Must match a previous requests' `nextPageToken`."
+//	@Param			Accept		header		string	false	"Set to `application/x-ndjson` to stream every DID, one per line, instead of a page. Pagination parameters are then ignored."
 //	@Success		200			{object}	ListDIDsByMethodResponse
 //	@Failure		400			{string}	string	"Bad request"
 //	@Failure		500			{string}	string	"Internal server error"
@@ -367,14 +459,31 @@ func (dr DIDRouter) ListDIDsByMethod(c *gin.Context) {
 	}
 	// TODO(gabe) check if the method is supported, to tell whether this is a bad req or internal error
 	// TODO(gabe) differentiate between internal errors and not found DIDs
+	filter, err := parseCreatedByFilter(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "the filter request is malformed", http.StatusBadRequest)
+		return
+	}
 	getDIDsRequest := did.ListDIDsRequest{
 		Method:  didsdk.Method(*method),
 		Deleted: getIsDeleted,
+		Filter:  filter,
 	}
 	var pageRequest pagination.PageRequest
 	if pagination.ParsePaginationQueryValues(c, &pageRequest) {
 		return
 	}
+	if pagination.WantsStream(c) {
+		pagination.Stream(c, fmt.Sprintf("could not get DIDs for method: %s", *method), func(ctx context.Context, pageRequest pagination.PageRequest) ([]any, string, error) {
+			getDIDsRequest.PageRequest = pageRequest.ToServicePage()
+			listResp, err := dr.service.ListDIDsByMethod(ctx, getDIDsRequest)
+			if err != nil {
+				return nil, "", err
+			}
+			return pagination.StreamItems(listResp.DIDs), listResp.NextPageToken, nil
+		})
+		return
+	}
 	getDIDsRequest.PageRequest = pageRequest.ToServicePage()
 
 	listResp, err := dr.service.ListDIDsByMethod(c, getDIDsRequest)
@@ -385,7 +494,8 @@ func (dr DIDRouter) ListDIDsByMethod(c *gin.Context) {
 	}
 
 	resp := ListDIDsByMethodResponse{
-		DIDs: listResp.DIDs,
+		DIDs:      listResp.DIDs,
+		CreatedBy: listResp.CreatedBy,
 	}
 	if pagination.MaybeSetNextPageToken(c, listResp.NextPageToken, &resp.NextPageToken) {
 		return
@@ -470,15 +580,110 @@ func (dr DIDRouter) ResolveDID(c *gin.Context) {
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+type BatchResolveDIDsRequest struct {
+	// Required. The DIDs to resolve. Cannot be more than {{.Services.DIDConfig.BatchResolveMaxItems}} items.
+	DIDs []string `json:"dids" maxItems:"100" validate:"required"`
+}
+
+type BatchResolveDIDsResponse struct {
+	// The resolution result of each DID, in the order of the request. A DID which could not be resolved has an error
+	// instead of a document.
+	Results []did.BatchResolveDIDResult `json:"results"`
+}
+
+// BatchResolveDIDs godoc
+//
+//	@Summary		Batch resolve DIDs
+//	@Description	Resolve a batch of DIDs, which may not be stored in this service, concurrently. A DID which cannot be
+//	@Description	resolved, such as a malformed DID or one of an unsupported method, has an error as its result, without
+//	@Description	failing the batch.
+//	@Tags			DecentralizedIdentifiers
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		BatchResolveDIDsRequest	true	"The DIDs to resolve"
+//	@Success		200		{object}	BatchResolveDIDsResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Router			/v1/dids/resolver/batch [post]
+func (dr DIDRouter) BatchResolveDIDs(c *gin.Context) {
+	invalidBatchResolveDIDsRequest := "invalid batch resolve DIDs request"
+	var request BatchResolveDIDsRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidBatchResolveDIDsRequest, http.StatusBadRequest)
+		return
+	}
+	if err := framework.ValidateRequest(request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidBatchResolveDIDsRequest, http.StatusBadRequest)
+		return
+	}
+
+	batchResolveMaxItems := dr.service.Config().BatchResolveMaxItems
+	if len(request.DIDs) > batchResolveMaxItems {
+		framework.LoggingRespondErrMsg(c, fmt.Sprintf("max number of DIDs is %d", batchResolveMaxItems), http.StatusBadRequest)
+		return
+	}
+
+	resolved, err := dr.service.BatchResolveDIDs(c, did.BatchResolveDIDsRequest{DIDs: request.DIDs})
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "could not resolve DIDs", http.StatusInternalServerError)
+		return
+	}
+
+	framework.Respond(c, BatchResolveDIDsResponse{Results: resolved.Results}, http.StatusOK)
+}
+
+type ResolveVerificationMethodResponse struct {
+	// The fully qualified ID of the verification method.
+	ID           string           `json:"id"`
+	KeyType      crypto.KeyType   `json:"keyType"`
+	PublicKeyJWK jwx.PublicKeyJWK `json:"publicKeyJwk"`
+}
+
+// ResolveVerificationMethod godoc
+//
+//	@Summary		Resolve a verification method
+//	@Description	Resolve a fully qualified verification method ID, such as `did:key:z6Mk...#z6Mk...`, to its public
+//	@Description	key, without returning the whole DID document. The `#` of the ID must be URL encoded as `%23`.
+//	@Tags			DecentralizedIdentifiers
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Fully qualified verification method ID"
+//	@Success		200	{object}	ResolveVerificationMethodResponse
+//	@Failure		400	{string}	string	"Bad request"
+//	@Failure		404	{string}	string	"Not found"
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/dids/verification-methods/{id} [get]
+func (dr DIDRouter) ResolveVerificationMethod(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "resolve verification method request missing id parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	resolved, err := dr.service.ResolveVerificationMethod(c, *id)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not resolve verification method: %s", *id)
+		status := http.StatusBadRequest
+		if errors.Is(err, did.ErrVerificationMethodNotFound) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	resp := ResolveVerificationMethodResponse{ID: resolved.ID, KeyType: resolved.KeyType, PublicKeyJWK: resolved.PublicKeyJWK}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
 type BatchCreateDIDsRequest struct {
 	// Required. The list of create credential requests. Cannot be more than {{.Services.DIDConfig.BatchCreateMaxItems}} items.
 	Requests []CreateDIDByMethodRequest `json:"requests" maxItems:"100" validate:"required,dive"`
 }
 
-func (r BatchCreateDIDsRequest) toServiceRequest(m didsdk.Method) (*did.BatchCreateDIDsRequest, error) {
+func (r BatchCreateDIDsRequest) toServiceRequest(m didsdk.Method, principal string) (*did.BatchCreateDIDsRequest, error) {
 	var req did.BatchCreateDIDsRequest
 	for _, routerReq := range r.Requests {
-		serviceReq, err := toCreateDIDRequest(m, routerReq)
+		serviceReq, err := toCreateDIDRequest(m, routerReq, principal)
 		if err != nil {
 			return &req, err
 		}
@@ -512,7 +717,9 @@ func NewBatchDIDRouter(svc *did.BatchService) *BatchDIDRouter {
 //	@Param			request	body		BatchCreateDIDsRequest	true	"The batch requests"
 //	@Success		201		{object}	BatchCreateDIDsResponse
 //	@Failure		400		{string}	string	"Bad request"
+//	@Failure		408		{string}	string	"Request canceled before the DIDs were created"
 //	@Failure		500		{string}	string	"Internal server error"
+//	@Failure		504		{string}	string	"Batch timeout expired before the DIDs were created"
 //	@Router			/v1/dids/{method}/batch [put]
 func (dr BatchDIDRouter) BatchCreateDIDs(c *gin.Context) {
 	method := framework.GetParam(c, MethodParam)
@@ -539,12 +746,15 @@ func (dr BatchDIDRouter) BatchCreateDIDs(c *gin.Context) {
 		return
 	}
 
-	req, err := batchRequest.toServiceRequest(didsdk.Method(*method))
+	req, err := batchRequest.toServiceRequest(didsdk.Method(*method), framework.GetPrincipal(c))
 	if err != nil {
 		framework.LoggingRespondError(c, err, http.StatusBadRequest)
 		return
 	}
-	batchCreateDIDsResponse, err := dr.service.BatchCreateDIDs(c, *req)
+	// the batch is aborted when the client disconnects
+	ctx, cancel := framework.RequestContext(c)
+	defer cancel()
+	batchCreateDIDsResponse, err := dr.service.BatchCreateDIDs(ctx, *req)
 	if err != nil {
 		errMsg := "could not create credentials"
 		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	wellknown "github.com/tbd54566975/ssi-service/pkg/service/well-known"
+)
+
+type IssuerMetadataRouter struct {
+	service *wellknown.IssuerMetadataService
+}
+
+func NewIssuerMetadataRouter(s svcframework.Service) (*IssuerMetadataRouter, error) {
+	if s == nil {
+		return nil, errors.New("service cannot be nil")
+	}
+	service, ok := s.(*wellknown.IssuerMetadataService)
+	if !ok {
+		return nil, fmt.Errorf("could not create issuer metadata router with service type: %s", s.Type())
+	}
+	return &IssuerMetadataRouter{service: service}, nil
+}
+
+// GetIssuerMetadata godoc
+//
+//	@Summary		Get OpenID4VCI Credential Issuer Metadata
+//	@Description	Get the credential issuer metadata according to https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-issuer-metadata
+//	@Description	Each schema is advertised as a credential configuration in every configured format.
+//	@Description	When the Accept-Language header matches the locale of a display of the issuer, only that display is returned.
+//	@Tags			IssuerMetadata
+//	@Accept			json
+//	@Produce		json
+//	@Param			Accept-Language	header		string	false	"Preferred locales of the issuer display"
+//	@Success		200	{object}	wellknown.IssuerMetadata
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/.well-known/openid-credential-issuer [get]
+func (ir IssuerMetadataRouter) GetIssuerMetadata(c *gin.Context) {
+	metadata, err := ir.service.GetIssuerMetadata(c, wellknown.GetIssuerMetadataRequest{AcceptLanguage: c.GetHeader("Accept-Language")})
+	if err != nil {
+		errMsg := "could not get issuer metadata"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+	framework.Respond(c, metadata, http.StatusOK)
+}
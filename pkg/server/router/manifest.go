@@ -18,6 +18,7 @@ import (
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/util"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest"
+	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -247,12 +248,15 @@ type SubmitApplicationRequest struct {
 	// A JWT signed by the applicant. The payload MUST contain the following properties:
 	// - `credential_application`: an object of type manifest.CredentialApplication (specified in https://identity.foundation/credential-manifest/#credential-application).
 	// - `vcs`: an array of Verifiable Credentials.
+	// It MAY contain a `presentationJwt` property: a Verifiable Presentation JWT signed by the applicant, which is verified
+	// when the application is submitted.
 	ApplicationJWT keyaccess.JWT `json:"applicationJwt" validate:"required"`
 }
 
 const (
 	vcsJSONProperty                   = "vcs"
 	verifiableCredentialsJSONProperty = "verifiableCredentials"
+	presentationJWTJSONProperty       = "presentationJwt"
 )
 
 func (sar SubmitApplicationRequest) toServiceRequest() (*model.SubmitApplicationRequest, error) {
@@ -298,12 +302,22 @@ func (sar SubmitApplicationRequest) toServiceRequest() (*model.SubmitApplication
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing submitted credentials")
 	}
+
+	var presentationJWT *keyaccess.JWT
+	if presentation, ok := token.Get(presentationJWTJSONProperty); ok {
+		presentationString, ok := presentation.(string)
+		if !ok {
+			return nil, fmt.Errorf("could not parse Credential Application token, %s is not a string", presentationJWTJSONProperty)
+		}
+		presentationJWT = keyaccess.JWT(presentationString).Ptr()
+	}
 	return &model.SubmitApplicationRequest{
 		ApplicantDID:    iss,
 		Application:     application,
 		Credentials:     credContainer,
 		ApplicationJWT:  sar.ApplicationJWT,
 		ApplicationJSON: token.PrivateClaims(),
+		PresentationJWT: presentationJWT,
 	}, nil
 }
 
@@ -356,6 +370,11 @@ func (mr ManifestRouter) SubmitApplication(c *gin.Context) {
 type GetApplicationResponse struct {
 	ID          string                            `json:"id"`
 	Application manifestsdk.CredentialApplication `json:"application"`
+	// The result of verifying the presentation submitted with the application, if any.
+	PresentationVerification *manifeststg.PresentationVerification `json:"presentationVerification,omitempty"`
+	// Findings about the application which did not deny it, such as a credential submitted in several formats, which
+	// counts once against the submission requirements of the manifest.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // GetApplication godoc
@@ -385,14 +404,18 @@ func (mr ManifestRouter) GetApplication(c *gin.Context) {
 	}
 
 	resp := GetApplicationResponse{
-		ID:          gotApplication.Application.ID,
-		Application: gotApplication.Application,
+		ID:                       gotApplication.Application.ID,
+		Application:              gotApplication.Application,
+		PresentationVerification: gotApplication.PresentationVerification,
+		Warnings:                 gotApplication.Warnings,
 	}
 	framework.Respond(c, resp, http.StatusOK)
 }
 
 type ListApplicationsResponse struct {
 	Applications []manifestsdk.CredentialApplication `json:"applications"`
+	// The results of verifying the presentations submitted with the applications, keyed by application ID.
+	PresentationVerifications map[string]manifeststg.PresentationVerification `json:"presentationVerifications,omitempty"`
 }
 
 // ListApplications godoc
@@ -413,7 +436,10 @@ func (mr ManifestRouter) ListApplications(c *gin.Context) {
 		return
 	}
 
-	resp := ListApplicationsResponse{Applications: gotApplications.Applications}
+	resp := ListApplicationsResponse{
+		Applications:              gotApplications.Applications,
+		PresentationVerifications: gotApplications.PresentationVerifications,
+	}
 	framework.Respond(c, resp, http.StatusOK)
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/credential/integrity"
@@ -15,11 +16,13 @@ import (
 
 	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
 	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation/model"
+	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
 )
 
 type PresentationRouter struct {
@@ -40,6 +43,18 @@ func NewPresentationRouter(s svcframework.Service) (*PresentationRouter, error)
 type VerifyPresentationRequest struct {
 	// A JWT that encodes a verifiable presentation according to https://www.w3.org/TR/vc-data-model/#json-web-token
 	PresentationJWT *keyaccess.JWT `json:"presentationJwt,omitempty" validate:"required"`
+
+	// Expectations on the credentials submitted in the presentation, keyed by the ID of the input descriptor they were
+	// submitted for. Verification fails with the `SCHEMA_MISMATCH` or `TYPE_MISMATCH` reason code when a credential
+	// does not have the expected schema or types.
+	ExpectedCredentials map[string]verification.CredentialExpectations `json:"expectedCredentials,omitempty"`
+
+	// The verifier the presentation must be addressed to with its `aud` claim, as presentations following the JWT VC
+	// Presentation Profile are. When not set, the audience of the presentation, if any, must be its holder.
+	Audience string `json:"audience,omitempty"`
+
+	// The nonce the presentation must carry as its `nonce` claim.
+	Nonce string `json:"nonce,omitempty"`
 }
 
 type VerifyPresentationResponse struct {
@@ -48,6 +63,16 @@ type VerifyPresentationResponse struct {
 
 	// The reason why this presentation couldn't be verified.
 	Reason string `json:"reason,omitempty"`
+
+	// Set to `SCHEMA_MISMATCH` or `TYPE_MISMATCH` when a submitted credential is valid, but does not have the expected
+	// schema or types, to `REVOKED` or `SUSPENDED` when its status is set in a status list held by this service, and to
+	// `INVALID_CREDENTIAL` when a submitted credential fails verification.
+	ReasonCode string `json:"reasonCode,omitempty"`
+
+	// The results of verifying each credential in the presentation, whether embedded as a JWT or as a JSON object
+	// with a proof. Set once the signature of the presentation is verified, so that a credential which fails
+	// verification is identified by its index in the presentation.
+	CredentialResults []verification.CredentialResult `json:"credentialResults,omitempty"`
 }
 
 // VerifyPresentation godoc
@@ -58,10 +83,15 @@ type VerifyPresentationResponse struct {
 //	@Description	2. Makes sure the presentation is not expired
 //	@Description	3. Makes sure the presentation complies with https://www.w3.org/TR/vc-data-model/#presentations-0 of VC Data Model v1.1
 //	@Description	4. For each credential in the presentation, makes sure:
-//	@Description	a. Makes sure the credential has a valid signature
+//	@Description	a. Makes sure the credential has a valid signature, whether it is a JWT or a JSON object with a proof
 //	@Description	b. Makes sure the credential is not expired
 //	@Description	c. Makes sure the credential complies with the VC Data Model
 //	@Description	d. If the credential has a schema, makes sure its data complies with the schema
+//	@Description	e. If the credential uses a revocation or suspension status list held by this service, makes sure its status is not set
+//	@Description	5. For each input descriptor in `expectedCredentials`, makes sure the credential submitted for it has the
+//	@Description	expected schema and types
+//	@Description	6. When `audience` or `nonce` are set, makes sure the presentation is addressed to the audience and
+//	@Description	carries the nonce
 //	@Tags			Presentations
 //	@Accept			json
 //	@Produce		json
@@ -84,7 +114,10 @@ func (pr PresentationRouter) VerifyPresentation(c *gin.Context) {
 	}
 
 	verificationResult, err := pr.service.VerifyPresentation(c, presentation.VerifyPresentationRequest{
-		PresentationJWT: request.PresentationJWT,
+		PresentationJWT:     request.PresentationJWT,
+		ExpectedCredentials: request.ExpectedCredentials,
+		Audience:            request.Audience,
+		Nonce:               request.Nonce,
 	})
 	if err != nil {
 		errMsg := "could not verify presentation"
@@ -92,10 +125,197 @@ func (pr PresentationRouter) VerifyPresentation(c *gin.Context) {
 		return
 	}
 
-	resp := VerifyPresentationResponse{Verified: verificationResult.Verified, Reason: verificationResult.Reason}
+	resp := VerifyPresentationResponse{
+		Verified:          verificationResult.Verified,
+		Reason:            verificationResult.Reason,
+		ReasonCode:        verificationResult.ReasonCode,
+		CredentialResults: verificationResult.CredentialResults,
+	}
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+type BatchVerifyPresentationsRequest struct {
+	// The challenge shared by all presentations in the batch. Each presentation must carry it as its `nonce` claim.
+	Challenge string `json:"challenge" validate:"required"`
+
+	// The presentations to verify, as JWTs.
+	PresentationJWTs []keyaccess.JWT `json:"presentationJwts" validate:"required,min=1"`
+}
+
+type BatchVerifyPresentationsResponse struct {
+	// The verification result of each presentation, in the same order as in the request.
+	Results []VerifyPresentationResponse `json:"results"`
+}
+
+// BatchVerifyPresentations godoc
+//
+//	@Summary		Batch verify Verifiable Presentations
+//	@Description	Verifies a batch of presentations created in response to the same challenge. Each presentation is
+//	@Description	verified as in `/v1/presentations/verification`, and must carry the challenge as its `nonce` claim.
+//	@Description	The challenge is consumed once for the whole batch, so it cannot be used in a later request. When
+//	@Description	challenges expire, only challenges created through `/v1/presentations/challenges` are accepted.
+//	@Tags			Presentations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		BatchVerifyPresentationsRequest	true	"request body"
+//	@Success		200		{object}	BatchVerifyPresentationsResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		409		{string}	string	"Challenge already used"
+//	@Failure		410		{string}	string	"Challenge expired"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/presentations/verification/batch [put]
+func (pr PresentationRouter) BatchVerifyPresentations(c *gin.Context) {
+	var request BatchVerifyPresentationsRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		errMsg := "invalid batch verify presentations request"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if err := util.IsValidStruct(request); err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	batchResult, err := pr.service.BatchVerifyPresentations(c, presentation.BatchVerifyPresentationsRequest{
+		Challenge:        request.Challenge,
+		PresentationJWTs: request.PresentationJWTs,
+	})
+	if err != nil {
+		errMsg := "could not verify presentations"
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, prestorage.ErrChallengeConsumed):
+			status = http.StatusConflict
+		case errors.Is(err, prestorage.ErrChallengeExpired):
+			status = http.StatusGone
+		case errors.Is(err, prestorage.ErrChallengeNotFound):
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	results := make([]VerifyPresentationResponse, 0, len(batchResult.Results))
+	for _, result := range batchResult.Results {
+		results = append(results, VerifyPresentationResponse{
+			Verified:          result.Verified,
+			Reason:            result.Reason,
+			ReasonCode:        result.ReasonCode,
+			CredentialResults: result.CredentialResults,
+		})
+	}
+	framework.Respond(c, BatchVerifyPresentationsResponse{Results: results}, http.StatusOK)
+}
+
+type CreateChallengeResponse struct {
+	// The challenge holders must carry as the `nonce` claim of their presentations.
+	Challenge string `json:"challenge"`
+
+	// When the challenge expires, as an RFC3339 timestamp. Not set when challenges never expire.
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// CreateChallenge godoc
+//
+//	@Summary		Create a challenge
+//	@Description	Creates a challenge for holders to carry as the `nonce` claim of their presentations, which can be
+//	@Description	used once in `/v1/presentations/verification/batch` before it expires.
+//	@Tags			Presentations
+//	@Produce		json
+//	@Success		201	{object}	CreateChallengeResponse
+//	@Failure		500	{string}	string	"Internal server error"
+//	@Router			/v1/presentations/challenges [put]
+func (pr PresentationRouter) CreateChallenge(c *gin.Context) {
+	challenge, err := pr.service.CreateChallenge(c)
+	if err != nil {
+		errMsg := "could not create challenge"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+	resp := CreateChallengeResponse{Challenge: challenge.Challenge, ExpiresAt: challenge.ExpiresAt}
+	framework.Respond(c, resp, http.StatusCreated)
+}
+
+type ConstructPresentationRequest struct {
+	// IDs of credentials stored in the service to present. Each of them must be stored as a JWT.
+	CredentialIDs []string `json:"credentialIds" validate:"required,min=1"`
+
+	// DID of the holder presenting the credentials. The DID must have been previously created with the DID API.
+	HolderDID string `json:"holderDid" validate:"required"`
+
+	// The id of the verificationMethod (see https://www.w3.org/TR/did-core/#verification-methods) of `holderDid` whose
+	// privateKey is stored in ssi-service, and which signs the presentation.
+	VerificationMethodID string `json:"verificationMethodId" validate:"required" example:"did:key:z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3#z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3"`
+
+	// The verifier the presentation is addressed to, set as its `aud` claim.
+	Audience string `json:"audience" validate:"required" example:"https://verifier.example.com"`
+
+	// The nonce given by the verifier, set as the `nonce` claim of the presentation.
+	Nonce string `json:"nonce" validate:"required"`
+
+	// When the presentation expires, as an RFC3339 timestamp. Defaults to 5 minutes after it is constructed.
+	Expiration string `json:"expiration,omitempty" example:"2051-10-05T14:48:00.000Z"`
+}
+
+type ConstructPresentationResponse struct {
+	// The signed presentation, as a JWT following the JWT VC Presentation Profile.
+	PresentationJWT keyaccess.JWT `json:"presentationJwt"`
+}
+
+// ConstructPresentation godoc
+//
+//	@Summary		Construct a Verifiable Presentation
+//	@Description	Builds and signs a presentation of credentials stored in the service following the JWT VC
+//	@Description	Presentation Profile. The presentation is a JWT issued by the holder, addressed to the verifier with
+//	@Description	`aud`, carrying the verifier's `nonce`, valid from `nbf` until `exp`, and embedding the credentials
+//	@Description	as compact JWTs in `vp.verifiableCredential`.
+//	@Tags			Presentations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ConstructPresentationRequest	true	"request body"
+//	@Success		201		{object}	ConstructPresentationResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/presentations/construct [post]
+func (pr PresentationRouter) ConstructPresentation(c *gin.Context) {
+	var request ConstructPresentationRequest
+	if err := framework.Decode(c.Request, &request); err != nil {
+		errMsg := "invalid construct presentation request"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	if err := util.IsValidStruct(request); err != nil {
+		framework.LoggingRespondError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	serviceRequest := presentation.ConstructPresentationRequest{
+		CredentialIDs:        request.CredentialIDs,
+		HolderDID:            request.HolderDID,
+		VerificationMethodID: request.VerificationMethodID,
+		Audience:             request.Audience,
+		Nonce:                request.Nonce,
+	}
+	if request.Expiration != "" {
+		expiration, err := time.Parse(time.RFC3339, request.Expiration)
+		if err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, "invalid expiration", http.StatusBadRequest)
+			return
+		}
+		serviceRequest.Expiration = &expiration
+	}
+
+	constructed, err := pr.service.ConstructPresentation(c, serviceRequest)
+	if err != nil {
+		errMsg := "could not construct presentation"
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusBadRequest)
+		return
+	}
+	framework.Respond(c, ConstructPresentationResponse{PresentationJWT: constructed.PresentationJWT}, http.StatusCreated)
+}
+
 type CreatePresentationDefinitionRequest struct {
 	Name                   string                           `json:"name,omitempty"`
 	Purpose                string                           `json:"purpose,omitempty"`
@@ -324,6 +544,9 @@ func (r CreateSubmissionRequest) toServiceRequest() (*model.CreateSubmissionRequ
 //
 //	@Summary		Create a Presentation Submission
 //	@Description	Accepts a Presentation Submission (https://identity.foundation/presentation-exchange/spec/v2.0.0/#presentation-submission) in this server ready to be reviewed.
+//	@Description	Copies of a credential submitted in several formats, such as a JWT and a data integrity credential,
+//	@Description	count once against the submission requirements of the definition, and are reported as `warnings` of
+//	@Description	the submission.
 //	@Tags			PresentationSubmissions
 //	@Accept			json
 //	@Produce		json
@@ -349,7 +572,11 @@ func (pr PresentationRouter) CreateSubmission(c *gin.Context) {
 	operation, err := pr.service.CreateSubmission(c, *req)
 	if err != nil {
 		errMsg := "cannot create submission"
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, verification.ErrUnmetSubmissionRequirement) {
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
 		return
 	}
 
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"go.einride.tech/aip/filtering"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+)
+
+// CreatedByParam lists the credentials, DIDs, schemas, or webhooks created by a principal, which is the name of the
+// API key of their creator, or `anonymous`.
+const CreatedByParam string = "createdBy"
+
+// createdByFilterDeclarations declares the filters of the list endpoints of DIDs, schemas, and webhooks, which can only
+// be filtered by their creator.
+var createdByFilterDeclarations *filtering.Declarations
+
+func init() {
+	var err error
+	createdByFilterDeclarations, err = filtering.NewDeclarations(
+		filtering.DeclareFunction(
+			filtering.FunctionEquals,
+			filtering.NewFunctionOverload(
+				filtering.FunctionOverloadEqualsString,
+				filtering.TypeBool,
+				filtering.TypeString,
+				filtering.TypeString,
+			),
+		),
+		filtering.DeclareIdent(CreatedByParam, filtering.TypeString),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// createdByRequest filters a list by the principal of the CreatedByParam query parameter, if any.
+type createdByRequest struct {
+	createdBy *string
+}
+
+func (r createdByRequest) GetFilter() string {
+	if r.createdBy == nil {
+		return ""
+	}
+	return fmt.Sprintf(`%s=%q`, CreatedByParam, *r.createdBy)
+}
+
+// parseCreatedByFilter returns the filter of the CreatedByParam query parameter of a list request. The filter is empty
+// when the parameter is not set.
+func parseCreatedByFilter(c *gin.Context) (filtering.Filter, error) {
+	return filtering.ParseFilter(createdByRequest{createdBy: framework.GetQueryValue(c, CreatedByParam)}, createdByFilterDeclarations)
+}
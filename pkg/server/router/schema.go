@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 
+	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
@@ -43,6 +45,27 @@ type CreateSchemaRequest struct {
 	// `https://json-schema.org/draft/2019-09/schema`, or `https://json-schema.org/draft-07/schema`.
 	Schema schemalib.JSONSchema `json:"schema" validate:"required"`
 
+	// StatusPolicy is an optional default status for credentials created against the schema, applied when the request
+	// for a credential asks for neither a revocable nor a suspendable credential. When the policy is mandatory, requests
+	// for a credential with another status are rejected.
+	StatusPolicy *schema.StatusPolicy `json:"statusPolicy,omitempty"`
+
+	// UniquenessPolicy optionally limits each subject to one active credential created against the schema per issuer.
+	// A request for another credential is rejected, unless the policy replaces the active credential by revoking it.
+	UniquenessPolicy *schema.UniquenessPolicy `json:"uniquenessPolicy,omitempty"`
+
+	// RenderMethod optionally tells wallets how to display credentials created against the schema. An
+	// `SvgRenderingTemplate2023` with an inline template can also be rendered by the service.
+	RenderMethod *credmodel.RenderMethod `json:"renderMethod,omitempty"`
+
+	// ExpectedType is an optional type, such as `EmailCredential`, which credentials created against the schema must
+	// have in their `types`. Verification checks that credentials of the schema declare it.
+	ExpectedType string `json:"expectedType,omitempty" example:"EmailCredential"`
+
+	// ValidityDuration is an optional lifetime, such as "24h", of credentials created against the schema. Credentials
+	// requested without an expiry expire this long after they are issued, while an expiry in the request still wins.
+	ValidityDuration string `json:"validityDuration,omitempty" example:"24h"`
+
 	// CredentialSchemaRequest request is an optional additional request to create a credentialized version of a schema.
 	*CredentialSchemaRequest
 }
@@ -59,6 +82,10 @@ type CredentialSchemaRequest struct {
 	// credential using JsonSchemaCredential.
 	// Required if intending to sign the schema as a credential using JsonSchemaCredential.
 	VerificationMethodID string `json:"verificationMethodId" validate:"required" example:"did:key:z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3#z6MkkZDjunoN4gyPMx5TSy7Mfzw22D2RZQZUcx46bii53Ex3"`
+
+	// Revocable gives the schema credential a revocation status. It is stored as the credential with the ID of the
+	// schema, and revoking it stops the creation of credentials against the schema.
+	Revocable bool `json:"revocable,omitempty"`
 }
 
 func (csr *CredentialSchemaRequest) IsValid() bool {
@@ -83,6 +110,40 @@ type SchemaResponse struct {
 
 	// CredentialSchema is the JWT schema for the credential, returned when the type is CredentialSchema
 	CredentialSchema *keyaccess.JWT `json:"credentialSchema,omitempty"`
+
+	// StatusPolicy is the default status of credentials created against the schema, if any
+	StatusPolicy *schema.StatusPolicy `json:"statusPolicy,omitempty"`
+
+	// UniquenessPolicy limits each subject to one active credential created against the schema per issuer, if present
+	UniquenessPolicy *schema.UniquenessPolicy `json:"uniquenessPolicy,omitempty"`
+
+	// RenderMethod tells wallets how to display credentials created against the schema, if present
+	RenderMethod *credmodel.RenderMethod `json:"renderMethod,omitempty"`
+
+	// ExpectedClaims are claims of the credential subject which credentials created against the schema should have,
+	// without the schema requiring them. Credentials missing them are created with a `MISSING_EXPECTED_CLAIM` warning.
+	ExpectedClaims []string `json:"expectedClaims,omitempty"`
+
+	// ExpectedType is the type credentials created against the schema must have, if any.
+	ExpectedType string `json:"expectedType,omitempty"`
+
+	// SubjectCredentialQuota is how many credentials each subject may hold from an issuer when creating credentials
+	// against the schema, overriding the quota of the service. 0 means no quota.
+	SubjectCredentialQuota *int `json:"subjectCredentialQuota,omitempty"`
+
+	// ValidityDuration is how long after they are issued credentials created against the schema without an expiry
+	// expire, if set.
+	ValidityDuration string `json:"validityDuration,omitempty"`
+
+	// ClaimInjection holds the claims the service injects into the credential subject of credentials created against
+	// the schema before validating them, and what happens when a request has one of them, if set.
+	ClaimInjection *schema.ClaimInjection `json:"claimInjection,omitempty"`
+
+	// CreatedBy is the principal which created the schema: the name of the API key it was created with, or
+	// `anonymous`. Empty for schemas created before creators were recorded.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// Version of the JSON schema, which starts at 1 and is incremented each time the JSON schema is replaced.
+	Version int `json:"version"`
 }
 
 // CreateSchema godoc
@@ -111,9 +172,15 @@ func (sr SchemaRouter) CreateSchema(c *gin.Context) {
 	}
 
 	req := schema.CreateSchemaRequest{
-		Name:        request.Name,
-		Description: request.Description,
-		Schema:      request.Schema,
+		Name:             request.Name,
+		Description:      request.Description,
+		Schema:           request.Schema,
+		StatusPolicy:     request.StatusPolicy,
+		UniquenessPolicy: request.UniquenessPolicy,
+		RenderMethod:     request.RenderMethod,
+		ExpectedType:     request.ExpectedType,
+		ValidityDuration: request.ValidityDuration,
+		CreatedBy:        framework.GetPrincipal(c),
 	}
 
 	if request.CredentialSchemaRequest != nil {
@@ -125,6 +192,11 @@ func (sr SchemaRouter) CreateSchema(c *gin.Context) {
 		// if we have a valid credential schema request, set the issuer and kid properties
 		req.Issuer = request.Issuer
 		req.FullyQualifiedVerificationMethodID = did.FullyQualifiedVerificationMethodID(request.Issuer, request.VerificationMethodID)
+		req.Revocable = request.Revocable
+	}
+	if err := req.IsValid(); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidCreateSchemaRequest, http.StatusBadRequest)
+		return
 	}
 
 	createSchemaResponse, err := sr.service.CreateSchema(c, req)
@@ -139,6 +211,13 @@ func (sr SchemaRouter) CreateSchema(c *gin.Context) {
 			Type:             createSchemaResponse.Type,
 			Schema:           createSchemaResponse.Schema,
 			CredentialSchema: createSchemaResponse.CredentialSchema,
+			StatusPolicy:     createSchemaResponse.StatusPolicy,
+			UniquenessPolicy: createSchemaResponse.UniquenessPolicy,
+			RenderMethod:     createSchemaResponse.RenderMethod,
+			ExpectedType:     createSchemaResponse.ExpectedType,
+			ValidityDuration: createSchemaResponse.ValidityDuration,
+			CreatedBy:        createSchemaResponse.CreatedBy,
+			Version:          createSchemaResponse.Version,
 		},
 	}
 	framework.Respond(c, resp, http.StatusCreated)
@@ -173,10 +252,20 @@ func (sr SchemaRouter) GetSchema(c *gin.Context) {
 
 	resp := GetSchemaResponse{
 		SchemaResponse: &SchemaResponse{
-			ID:               gotSchema.ID,
-			Type:             gotSchema.Type,
-			Schema:           gotSchema.Schema,
-			CredentialSchema: gotSchema.CredentialSchema,
+			ID:                     gotSchema.ID,
+			Type:                   gotSchema.Type,
+			Schema:                 gotSchema.Schema,
+			CredentialSchema:       gotSchema.CredentialSchema,
+			StatusPolicy:           gotSchema.StatusPolicy,
+			UniquenessPolicy:       gotSchema.UniquenessPolicy,
+			RenderMethod:           gotSchema.RenderMethod,
+			ExpectedClaims:         gotSchema.ExpectedClaims,
+			ExpectedType:           gotSchema.ExpectedType,
+			SubjectCredentialQuota: gotSchema.SubjectCredentialQuota,
+			ValidityDuration:       gotSchema.ValidityDuration,
+			ClaimInjection:         gotSchema.ClaimInjection,
+			CreatedBy:              gotSchema.CreatedBy,
+			Version:                gotSchema.Version,
 		},
 	}
 	framework.Respond(c, resp, http.StatusOK)
@@ -200,7 +289,10 @@ type ListSchemasResponse struct {
 //	@Produce		json
 //	@Param			pageSize	query		number	false	"Hint to the server of the maximum elements to return. More may be returned. When not set, the server will return all elements."
 //	@Param			pageToken	query		string	false	"Used to indicate to the server to return a specific page of the list results. Must match a previous requests' `nextPageToken`."
+//	@Param			createdBy	query		string	false	"When set, only lists schemas created by that principal, the name of an API key or `anonymous`."
+//	@Param			Accept		header		string	false	"Set to `application/x-ndjson` to stream every schema, one per line, instead of a page. Pagination parameters are then ignored."
 //	@Success		200			{object}	ListSchemasResponse
+//	@Failure		400			{string}	string	"Bad request"
 //	@Failure		500			{string}	string	"Internal server error"
 //	@Router			/v1/schemas [get]
 func (sr SchemaRouter) ListSchemas(c *gin.Context) {
@@ -208,8 +300,25 @@ func (sr SchemaRouter) ListSchemas(c *gin.Context) {
 	if pagination.ParsePaginationQueryValues(c, &pageRequest) {
 		return
 	}
+	filter, err := parseCreatedByFilter(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "the filter request is malformed", http.StatusBadRequest)
+		return
+	}
+
+	if pagination.WantsStream(c) {
+		pagination.Stream(c, "could not list schemas", func(ctx context.Context, pageRequest pagination.PageRequest) ([]any, string, error) {
+			gotSchemas, err := sr.service.ListSchemas(ctx, schema.ListSchemasRequest{Filter: filter, PageRequest: &pageRequest})
+			if err != nil {
+				return nil, "", err
+			}
+			return pagination.StreamItems(schemaResponses(gotSchemas.Schemas)), gotSchemas.NextPageToken, nil
+		})
+		return
+	}
 
 	gotSchemas, err := sr.service.ListSchemas(c, schema.ListSchemasRequest{
+		Filter:      filter,
 		PageRequest: &pageRequest,
 	})
 	if err != nil {
@@ -218,30 +327,144 @@ func (sr SchemaRouter) ListSchemas(c *gin.Context) {
 		return
 	}
 
-	schemas := make([]GetSchemaResponse, 0, len(gotSchemas.Schemas))
-	for _, s := range gotSchemas.Schemas {
+	resp := ListSchemasResponse{Schemas: schemaResponses(gotSchemas.Schemas)}
+
+	if pagination.MaybeSetNextPageToken(c, gotSchemas.NextPageToken, &resp.NextPageToken) {
+		return
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
+func schemaResponses(gotSchemas []schema.GetSchemaResponse) []GetSchemaResponse {
+	schemas := make([]GetSchemaResponse, 0, len(gotSchemas))
+	for _, s := range gotSchemas {
 		schemas = append(schemas, GetSchemaResponse{
 			SchemaResponse: &SchemaResponse{
-				ID:               s.ID,
-				Type:             s.Type,
-				Schema:           s.Schema,
-				CredentialSchema: s.CredentialSchema,
+				ID:                     s.ID,
+				Type:                   s.Type,
+				Schema:                 s.Schema,
+				CredentialSchema:       s.CredentialSchema,
+				StatusPolicy:           s.StatusPolicy,
+				UniquenessPolicy:       s.UniquenessPolicy,
+				RenderMethod:           s.RenderMethod,
+				ExpectedClaims:         s.ExpectedClaims,
+				ExpectedType:           s.ExpectedType,
+				SubjectCredentialQuota: s.SubjectCredentialQuota,
+				ValidityDuration:       s.ValidityDuration,
+				ClaimInjection:         s.ClaimInjection,
+				CreatedBy:              s.CreatedBy,
+				Version:                s.Version,
 			},
 		})
 	}
+	return schemas
+}
 
-	resp := ListSchemasResponse{Schemas: schemas}
+type GetSchemaResponse struct {
+	*SchemaResponse
+}
 
-	if pagination.MaybeSetNextPageToken(c, gotSchemas.NextPageToken, &resp.NextPageToken) {
-		return
-	}
-	framework.Respond(c, resp, http.StatusOK)
+type UpdateSchemaRequest struct {
+	// ExpectedClaims replaces the claims of the credential subject which credentials created against the schema should
+	// have, such as `email` or `address.city`. Unlike claims required by the schema, missing expected claims do not
+	// prevent a credential from being created. An empty list removes them, while leaving it unset keeps them as-is.
+	ExpectedClaims *[]string `json:"expectedClaims,omitempty"`
+
+	// ExpectedType replaces the type credentials created against the schema must have. An empty string removes it,
+	// while leaving it unset keeps it as-is.
+	ExpectedType *string `json:"expectedType,omitempty"`
+
+	// SubjectCredentialQuota overrides how many credentials each subject may hold from an issuer when creating
+	// credentials against the schema. 0 lifts the quota for the schema, a negative value removes the override so that
+	// the quota of the service applies, and leaving it unset keeps it as-is.
+	SubjectCredentialQuota *int `json:"subjectCredentialQuota,omitempty"`
+
+	// ValidityDuration replaces how long after they are issued credentials created against the schema without an
+	// expiry expire, such as "24h". An empty string removes it, while leaving it unset keeps it as-is.
+	ValidityDuration *string `json:"validityDuration,omitempty"`
+
+	// Schema replaces the JSON schema with a new version, incrementing the version of the schema. Credentials created
+	// against earlier versions are still verified against the version they were created against. The schemas of
+	// credential schemas, which are signed, cannot be replaced.
+	Schema *schemalib.JSONSchema `json:"schema,omitempty"`
+
+	// ClaimInjection replaces the claims the service injects into the credential subject of credentials created
+	// against the schema, such as `{"claims": {"jurisdiction": "US-CA"}, "conflictPolicy": "server-wins"}`. Injected
+	// claims are validated against the schema along with the others. When a request has an injected claim with
+	// another value, the conflict policy either rejects it (`reject`, the default), keeps the claim of the request
+	// (`client-wins`), or replaces it (`server-wins`). Injecting no claims removes it, while leaving it unset keeps it
+	// as-is.
+	ClaimInjection *schema.ClaimInjection `json:"claimInjection,omitempty"`
 }
 
-type GetSchemaResponse struct {
+type UpdateSchemaResponse struct {
 	*SchemaResponse
 }
 
+// UpdateSchema godoc
+//
+//	@Summary		Update a Credential Schema
+//	@Description	Updates the service-level settings of a schema, such as its expected claims, subject credential
+//	@Description	quota, validity duration, and injected claims, or replaces its JSON schema with a new version. Earlier versions are kept for the credentials
+//	@Description	created against them.
+//	@Tags			Schemas
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"ID"
+//	@Param			request	body		UpdateSchemaRequest	true	"request body"
+//	@Success		200		{object}	UpdateSchemaResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/schemas/{id} [patch]
+func (sr SchemaRouter) UpdateSchema(c *gin.Context) {
+	id := framework.GetParam(c, IDParam)
+	if id == nil {
+		errMsg := "cannot update a schema without an ID parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var request UpdateSchemaRequest
+	invalidUpdateSchemaRequest := "invalid update schema request"
+	if err := framework.Decode(c.Request, &request); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidUpdateSchemaRequest, http.StatusBadRequest)
+		return
+	}
+
+	req := schema.UpdateSchemaRequest{ID: *id, ExpectedClaims: request.ExpectedClaims, ExpectedType: request.ExpectedType, SubjectCredentialQuota: request.SubjectCredentialQuota, ValidityDuration: request.ValidityDuration, Schema: request.Schema, ClaimInjection: request.ClaimInjection}
+	if err := req.IsValid(); err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, invalidUpdateSchemaRequest, http.StatusBadRequest)
+		return
+	}
+
+	updatedSchema, err := sr.service.UpdateSchema(c, req)
+	if err != nil {
+		errMsg := fmt.Sprintf("could not update schema with id: %s", *id)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	resp := UpdateSchemaResponse{
+		SchemaResponse: &SchemaResponse{
+			ID:                     updatedSchema.ID,
+			Type:                   updatedSchema.Type,
+			Schema:                 updatedSchema.Schema,
+			CredentialSchema:       updatedSchema.CredentialSchema,
+			StatusPolicy:           updatedSchema.StatusPolicy,
+			UniquenessPolicy:       updatedSchema.UniquenessPolicy,
+			RenderMethod:           updatedSchema.RenderMethod,
+			ExpectedClaims:         updatedSchema.ExpectedClaims,
+			ExpectedType:           updatedSchema.ExpectedType,
+			SubjectCredentialQuota: updatedSchema.SubjectCredentialQuota,
+			ValidityDuration:       updatedSchema.ValidityDuration,
+			ClaimInjection:         updatedSchema.ClaimInjection,
+			CreatedBy:              updatedSchema.CreatedBy,
+			Version:                updatedSchema.Version,
+		},
+	}
+	framework.Respond(c, resp, http.StatusOK)
+}
+
 // DeleteSchema godoc
 //
 //	@Summary		Delete a Credential Schema
@@ -0,0 +1,71 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	wellknown "github.com/tbd54566975/ssi-service/pkg/service/well-known"
+)
+
+type SSIConfigurationRouter struct {
+	service *wellknown.SSIConfigurationService
+}
+
+func NewSSIConfigurationRouter(s svcframework.Service) (*SSIConfigurationRouter, error) {
+	if s == nil {
+		return nil, errors.New("service cannot be nil")
+	}
+	service, ok := s.(*wellknown.SSIConfigurationService)
+	if !ok {
+		return nil, fmt.Errorf("could not create ssi configuration router with service type: %s", s.Type())
+	}
+	return &SSIConfigurationRouter{service: service}, nil
+}
+
+type GetSSIConfigurationResponse struct {
+	// The issuer DIDs, their public keys, the schemas, and the status list endpoint of the service, as the claims of a
+	// JWT signed with the configured signer, whose key is among the advertised ones.
+	ConfigurationJWT keyaccess.JWT `json:"configurationJwt"`
+}
+
+// GetSSIConfiguration godoc
+//
+//	@Summary		Get the SSI configuration
+//	@Description	Get a signed bundle of the issuer DIDs managed by the service, their public JWKs, the schemas, and the
+//	@Description	status list endpoint, for verifiers to fetch periodically. Responses may be cached, and revalidated with
+//	@Description	their ETag, which changes whenever the bundled resources do.
+//	@Tags			SSIConfiguration
+//	@Accept			json
+//	@Produce		json
+//	@Param			If-None-Match	header		string	false	"ETag of a cached bundle"
+//	@Success		200				{object}	GetSSIConfigurationResponse
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		404				{string}	string	"Not found"
+//	@Failure		500				{string}	string	"Internal server error"
+//	@Router			/v1/.well-known/ssi-configuration [get]
+func (sr SSIConfigurationRouter) GetSSIConfiguration(c *gin.Context) {
+	gotConfiguration, err := sr.service.GetSSIConfiguration(c)
+	if err != nil {
+		if errors.Is(err, wellknown.ErrSSIConfigurationDisabled) {
+			framework.LoggingRespondErrWithMsg(c, err, "ssi configuration not found", http.StatusNotFound)
+			return
+		}
+		framework.LoggingRespondErrWithMsg(c, err, "could not get ssi configuration", http.StatusInternalServerError)
+		return
+	}
+
+	etag := fmt.Sprintf("%q", gotConfiguration.ETag)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(sr.service.MaxAge().Seconds())))
+	if c.GetHeader("If-None-Match") == etag {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	framework.Respond(c, GetSSIConfigurationResponse{ConfigurationJWT: gotConfiguration.ConfigurationJWT}, http.StatusOK)
+}
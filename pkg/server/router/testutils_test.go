@@ -72,7 +72,7 @@ func testCredentialService(t *testing.T, db storage.ServiceStorage, keyStore *ke
 }
 
 func testPresentationDefinitionService(t *testing.T, db storage.ServiceStorage, didService *did.Service, schemaService *schema.Service, keyStoreService *keystore.Service) *presentation.Service {
-	svc, err := presentation.NewPresentationService(db, didService.GetResolver(), schemaService, keyStoreService)
+	svc, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, db, didService.GetResolver(), schemaService, keyStoreService)
 	require.NoError(t, err)
 	require.NotEmpty(t, svc)
 	return svc
@@ -80,7 +80,7 @@ func testPresentationDefinitionService(t *testing.T, db storage.ServiceStorage,
 
 func testManifestService(t *testing.T, db storage.ServiceStorage, keyStore *keystore.Service, did *did.Service, credential *credential.Service, presentationSvc *presentation.Service) *manifest.Service {
 	// create a manifest service
-	manifestService, err := manifest.NewManifestService(db, keyStore, did.GetResolver(), credential, presentationSvc)
+	manifestService, err := manifest.NewManifestService(config.ManifestServiceConfig{}, db, keyStore, did.GetResolver(), credential, presentationSvc)
 	require.NoError(t, err)
 	require.NotEmpty(t, manifestService)
 	return manifestService
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 
 	"github.com/tbd54566975/ssi-service/pkg/server/framework"
@@ -36,6 +37,16 @@ type CreateWebhookRequest struct {
 	Verb webhook.Verb `json:"verb" validate:"required"`
 	// The URL to post the output of this request to Noun.Verb action to.
 	URL string `json:"url" validate:"required"`
+	// Reason codes of the failed verifications posted to the URL, such as REVOKED. All failures are posted when
+	// empty. Only valid for the VerificationFailed verb.
+	FailureCodes []string `json:"failureCodes,omitempty"`
+	// A Go template transforming the payloads posted to the URL into another JSON document, such as a Slack message.
+	// The template reads the payload by its JSON field names, such as `{{.noun}}` or `{{.data.id}}`, and may encode
+	// values with the `json` function. When it fails, the payload is posted untransformed, with a `templateError`.
+	Template string `json:"template,omitempty"`
+	// The data of the sample payload the template is validated against when the webhook is created. Defaults to an
+	// empty object.
+	SampleData json.RawMessage `json:"sampleData,omitempty" swaggertype:"object"`
 }
 
 type CreateWebhookResponse struct {
@@ -67,7 +78,15 @@ func (wr WebhookRouter) CreateWebhook(c *gin.Context) {
 		return
 	}
 
-	req := webhook.CreateWebhookRequest{Noun: request.Noun, Verb: request.Verb, URL: request.URL}
+	req := webhook.CreateWebhookRequest{
+		Noun:         request.Noun,
+		Verb:         request.Verb,
+		URL:          request.URL,
+		FailureCodes: request.FailureCodes,
+		Template:     request.Template,
+		SampleData:   request.SampleData,
+		CreatedBy:    framework.GetPrincipal(c),
+	}
 	if !req.IsValid() {
 		errMsg := "invalid create webhook request. wrong noun, verb, or url format (needs http / https)"
 		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
@@ -77,7 +96,11 @@ func (wr WebhookRouter) CreateWebhook(c *gin.Context) {
 	createWebhookResponse, err := wr.service.CreateWebhook(c, req)
 	if err != nil {
 		errMsg := "could not create webhook"
-		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if errors.Is(err, webhook.ErrInvalidTemplate) {
+			status = http.StatusBadRequest
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
 		return
 	}
 
@@ -135,15 +158,24 @@ type ListWebhooksResponse struct {
 // ListWebhooks godoc
 //
 //	@Summary		List webhooks
-//	@Description	Lists all webhooks stored by the service
+//	@Description	Lists all webhooks stored by the service. When filtered by `createdBy`, webhooks only list the URLs
+//	@Description	registered by that principal.
 //	@Tags			Webhooks
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	ListWebhooksResponse
-//	@Failure		500	{string}	string	"Internal server error"
+//	@Param			createdBy	query		string	false	"When set, only lists URLs registered by that principal, the name of an API key or `anonymous`."
+//	@Success		200			{object}	ListWebhooksResponse
+//	@Failure		400			{string}	string	"Bad request"
+//	@Failure		500			{string}	string	"Internal server error"
 //	@Router			/v1/webhooks [get]
 func (wr WebhookRouter) ListWebhooks(c *gin.Context) {
-	gotWebhooks, err := wr.service.ListWebhooks(c)
+	filter, err := parseCreatedByFilter(c)
+	if err != nil {
+		framework.LoggingRespondErrWithMsg(c, err, "the filter request is malformed", http.StatusBadRequest)
+		return
+	}
+
+	gotWebhooks, err := wr.service.ListWebhooks(c, webhook.ListWebhooksRequest{Filter: filter})
 	if err != nil {
 		errMsg := "could not list webhooks"
 		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
@@ -159,6 +191,122 @@ func (wr WebhookRouter) ListWebhooks(c *gin.Context) {
 	framework.Respond(c, resp, http.StatusOK)
 }
 
+type ListWebhookDeliveriesResponse struct {
+	// The most recent deliveries of the webhook, most recent first, each with the payload before it was transformed
+	// by a template.
+	Deliveries []webhook.Delivery `json:"deliveries"`
+}
+
+// ListWebhookDeliveries godoc
+//
+//	@Summary		List webhook deliveries
+//	@Description	Lists the most recent deliveries of a webhook, with the untransformed payload of each, for debugging
+//	@Tags			Webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			noun	path		string	true	"noun"
+//	@Param			verb	path		string	true	"verb"
+//	@Success		200		{object}	ListWebhookDeliveriesResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/webhooks/{noun}/{verb}/deliveries [get]
+func (wr WebhookRouter) ListWebhookDeliveries(c *gin.Context) {
+	noun := framework.GetParam(c, "noun")
+	if noun == nil {
+		errMsg := "cannot list webhook deliveries without noun parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	verb := framework.GetParam(c, "verb")
+	if verb == nil {
+		errMsg := "cannot list webhook deliveries without verb parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	gotDeliveries, err := wr.service.ListDeliveries(c, webhook.ListDeliveriesRequest{Noun: webhook.Noun(*noun), Verb: webhook.Verb(*verb)})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not list deliveries of webhook with id: %s-%s", *noun, *verb)
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	framework.Respond(c, ListWebhookDeliveriesResponse{Deliveries: gotDeliveries.Deliveries}, http.StatusOK)
+}
+
+type TestWebhookRequest struct {
+	// When set, the test event is only posted to this URL of the webhook.
+	URL string `json:"url,omitempty"`
+	// The data of the test event. Defaults to an object with a random `id`.
+	Data json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+type TestWebhookResponse struct {
+	// The deliveries of the test event, one per URL, each with the error posting it, if any.
+	Deliveries []webhook.Delivery `json:"deliveries"`
+}
+
+// TestWebhook godoc
+//
+//	@Summary		Test a webhook
+//	@Description	Posts a test event to the URLs of a webhook, marked with `test: true`, to validate a receiver. The
+//	@Description	event is delivered like actual events, and recorded in the deliveries of the webhook. The outcome of
+//	@Description	each delivery is returned once made, within the webhook timeout. The request body is optional.
+//	@Tags			Webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			noun	path		string				true	"noun"
+//	@Param			verb	path		string				true	"verb"
+//	@Param			request	body		TestWebhookRequest	false	"request body"
+//	@Success		200		{object}	TestWebhookResponse
+//	@Failure		400		{string}	string	"Bad request"
+//	@Failure		404		{string}	string	"Not found"
+//	@Failure		429		{string}	string	"Too many requests"
+//	@Failure		500		{string}	string	"Internal server error"
+//	@Router			/v1/webhooks/{noun}/{verb}/test [post]
+func (wr WebhookRouter) TestWebhook(c *gin.Context) {
+	noun := framework.GetParam(c, "noun")
+	if noun == nil {
+		errMsg := "cannot test webhook without noun parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	verb := framework.GetParam(c, "verb")
+	if verb == nil {
+		errMsg := "cannot test webhook without verb parameter"
+		framework.LoggingRespondErrMsg(c, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	var request TestWebhookRequest
+	if c.Request.ContentLength != 0 {
+		if err := framework.Decode(c.Request, &request); err != nil {
+			framework.LoggingRespondErrWithMsg(c, err, "invalid test webhook request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tested, err := wr.service.TestWebhook(c, webhook.TestWebhookRequest{
+		Noun: webhook.Noun(*noun),
+		Verb: webhook.Verb(*verb),
+		URL:  request.URL,
+		Data: request.Data,
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("could not test webhook with id: %s-%s", *noun, *verb)
+		status := http.StatusInternalServerError
+		if errors.Is(err, webhook.ErrWebhookNotFound) {
+			status = http.StatusNotFound
+		}
+		framework.LoggingRespondErrWithMsg(c, err, errMsg, status)
+		return
+	}
+
+	framework.Respond(c, TestWebhookResponse{Deliveries: tested.Deliveries}, http.StatusOK)
+}
+
 type DeleteWebhookRequest struct {
 	Noun webhook.Noun `json:"noun" validate:"required"`
 	Verb webhook.Verb `json:"verb" validate:"required"`
@@ -3,23 +3,33 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	swaggerfiles "github.com/swaggo/files"
 	ginswagger "github.com/swaggo/gin-swagger"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/i18n"
+	"github.com/tbd54566975/ssi-service/internal/verification"
 	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 	"github.com/tbd54566975/ssi-service/pkg/server/middleware"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
 	"github.com/tbd54566975/ssi-service/pkg/service"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	didsvc "github.com/tbd54566975/ssi-service/pkg/service/did"
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	wellknown "github.com/tbd54566975/ssi-service/pkg/service/well-known"
 )
 
 const (
@@ -30,8 +40,10 @@ const (
 	OperationPrefix         = "/operations"
 	DIDsPrefix              = "/dids"
 	ResolverPrefix          = "/resolver"
+	VerificationMethodsPath = "/verification-methods"
 	SchemasPrefix           = "/schemas"
 	CredentialsPrefix       = "/credentials"
+	SubjectsPrefix          = "/subjects"
 	StatusPrefix            = "/status"
 	PresentationsPrefix     = "/presentations"
 	DefinitionsPrefix       = "/definitions"
@@ -43,12 +55,50 @@ const (
 	ResponsesPrefix         = "/responses"
 	KeyStorePrefix          = "/keys"
 	VerificationPath        = "/verification"
+	CapacityPath            = "/capacity"
+	RenderPath              = "/render"
+	DiffPath                = "/diff"
+	ReceiptPath             = "/receipt"
+	RevokePath              = "/revoke"
+	SuspendPath             = "/suspend"
+	UnsuspendPath           = "/unsuspend"
+	ImportsPath             = "/imports"
+	SearchPath              = "/search"
+	StatisticsPath          = "/statistics"
+	EventsPath              = "/events"
+	HistoryPath             = "/history"
+	ChallengesPrefix        = "/challenges"
+	ConstructPath           = "/construct"
 	WebhookPrefix           = "/webhooks"
 	DIDConfigurationsPrefix = "/did-configurations"
+	AdminPrefix             = "/admin"
+	ConfigPath              = "/config"
+	ExportPath              = "/export"
+	ImportPath              = "/import"
+	StoragePath             = "/storage"
+	CompactionPath          = "/compaction"
+	ReindexPath             = "/reindex"
+	SeedPath                = "/seed"
+	StatusConsistencyPath   = "/status-consistency"
+	PublicPath              = "/public"
+	DeliveriesPath          = "/deliveries"
+	TestPath                = "/test"
+	DelegationsPath         = "/delegations"
+	IssuersPath             = "/issuers"
+	FreezePath              = "/freeze"
+	UnfreezePath            = "/unfreeze"
+	FreezesPath             = "/freezes"
 
 	batchSuffix = "/batch"
 )
 
+// AuthExemptRoutes are served without the global authentication of middleware.AuthMiddleware, since they are meant to
+// be called by anyone. Routes which are not registered, such as a disabled public credential status lookup, are never
+// matched.
+var AuthExemptRoutes = []string{
+	V1Prefix + CredentialsPrefix + "/:id" + StatusPrefix + PublicPath,
+}
+
 // SSIServer exposes all dependencies needed to run a http server and all its services
 type SSIServer struct {
 	*config.ServerConfig
@@ -61,7 +111,7 @@ func NewSSIServer(shutdown chan os.Signal, cfg config.SSIServiceConfig) (*SSISer
 	// creates an HTTP server from the framework, and wrap it to extend it for the SSIS
 	engine := setUpEngine(cfg.Server, shutdown)
 	httpServer := framework.NewServer(cfg.Server, engine, shutdown)
-	ssi, err := service.InstantiateSSIService(cfg.Services)
+	ssi, err := service.InstantiateSSIService(cfg.Services, backgroundWorkers()...)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate ssi service")
 	}
@@ -69,14 +119,37 @@ func NewSSIServer(shutdown chan os.Signal, cfg config.SSIServiceConfig) (*SSISer
 	// make sure to set the api base in our service info
 	config.SetAPIBase(cfg.Services.ServiceEndpoint)
 
+	if cfg.Server.MessageCatalogsDir != "" {
+		if err = i18n.LoadCatalogs(cfg.Server.MessageCatalogsDir); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "unable to load message catalogs")
+		}
+	}
+
+	if cfg.Services.WebhookConfig.VerificationFailureEvents {
+		ssi.Credential.OnVerificationFailed(publishVerificationFailed(ssi.Webhook, webhook.Credential))
+		ssi.Presentation.OnVerificationFailed(publishVerificationFailed(ssi.Webhook, webhook.Presentation))
+	}
+
+	// status mismatches are found by the scheduled consistency check, and by the one triggered through the admin API
+	ssi.Credential.OnStatusMismatch(publishStatusMismatch(ssi.Webhook))
+
+	// the events of the outbox are written by the services changing credentials and DIDs, and published by its worker
+	if cfg.Services.WebhookConfig.OutboxEnabled {
+		ssi.Credential.EnableOutbox()
+		ssi.DID.EnableOutbox()
+	}
+
 	// service-level routers
 	engine.GET(HealthPrefix, router.Health)
 	engine.GET(ReadinessPrefix, router.Readiness(ssi.GetServices()))
 	engine.StaticFile("swagger.yaml", "./doc/swagger.yaml")
 	engine.GET(SwaggerPrefix, ginswagger.WrapHandler(swaggerfiles.Handler, ginswagger.URL("/swagger.yaml")))
+	if err = IssuerMetadataAPI(&engine.RouterGroup, ssi.IssuerMetadata); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate IssuerMetadata API")
+	}
 
 	// register all v1 routers
-	v1 := engine.Group(V1Prefix)
+	v1 := engine.Group(V1Prefix, middleware.Principal(cfg.Server.IssuerAPIKeys))
 	if err = KeyStoreAPI(v1, ssi.KeyStore); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate KeyStore API")
 	}
@@ -86,7 +159,7 @@ func NewSSIServer(shutdown chan os.Signal, cfg config.SSIServiceConfig) (*SSISer
 	if err = SchemaAPI(v1, ssi.Schema, ssi.Webhook); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate Schema API")
 	}
-	if err = CredentialAPI(v1, ssi.Credential, ssi.Webhook, cfg.Services.StatusEndpoint); err != nil {
+	if err = CredentialAPI(v1, ssi.Credential, ssi.Webhook, cfg.Services.StatusEndpoint, cfg.Server.IssuerAPIKeys, cfg.Server.PublicCredentialStatus); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate Credential API")
 	}
 	if err = OperationAPI(v1, ssi.Operation); err != nil {
@@ -101,12 +174,18 @@ func NewSSIServer(shutdown chan os.Signal, cfg config.SSIServiceConfig) (*SSISer
 	if err = IssuanceAPI(v1, ssi.Issuance); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate Issuance API")
 	}
-	if err = WebhookAPI(v1, ssi.Webhook); err != nil {
+	if err = WebhookAPI(v1, ssi.Webhook, cfg.Services.WebhookConfig.TestRateLimit); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate Webhook API")
 	}
 	if err = DIDConfigurationAPI(v1, ssi.DIDConfiguration); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate DIDConfiguration API")
 	}
+	if err = SSIConfigurationAPI(v1, ssi.SSIConfiguration); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate SSIConfiguration API")
+	}
+	if err = AdminAPI(v1, cfg, ssi.Admin, ssi.Webhook); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "unable to instantiate Admin API")
+	}
 
 	return &SSIServer{
 		Server:       httpServer,
@@ -115,6 +194,128 @@ func NewSSIServer(shutdown chan os.Signal, cfg config.SSIServiceConfig) (*SSISer
 	}, nil
 }
 
+// Names of the background worker components started along with the services.
+const (
+	StatusListRefreshComponent      = "status_list_refresh"
+	ChallengeSweeperComponent       = "challenge_sweeper"
+	StorageCompactionComponent      = "storage_compaction"
+	WebhookOutboxComponent          = "webhook_outbox"
+	CredentialRenewalComponent      = "credential_renewal"
+	StatusListHostingCheckComponent = "status_list_hosting_check"
+	StatusConsistencyCheckComponent = "status_consistency_check"
+)
+
+// backgroundWorkers returns the workers which run for the lifetime of the server. They are stopped, and waited for,
+// before the services they depend on, so they never use a closed store.
+func backgroundWorkers() []service.Component {
+	return []service.Component{
+		service.BackgroundWorker(StatusListRefreshComponent,
+			[]string{service.CredentialComponent, service.WebhookComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Credential.RunStatusListRefresh(ctx, publishStatusListRefreshed(ssi.Webhook))
+			}),
+		service.BackgroundWorker(ChallengeSweeperComponent,
+			[]string{service.PresentationComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Presentation.RunChallengeSweeper(ctx)
+			}),
+		service.BackgroundWorker(StorageCompactionComponent,
+			[]string{service.AdminComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Admin.RunStorageCompaction(ctx)
+			}),
+		service.BackgroundWorker(WebhookOutboxComponent,
+			[]string{service.WebhookComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Webhook.RunOutboxDispatcher(ctx)
+			}),
+		service.BackgroundWorker(CredentialRenewalComponent,
+			[]string{service.CredentialComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Credential.RunCredentialRenewal(ctx)
+			}),
+		service.BackgroundWorker(StatusListHostingCheckComponent,
+			[]string{service.CredentialComponent, service.WebhookComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Credential.RunStatusListHostingCheck(ctx, publishStatusListHostingMismatch(ssi.Webhook))
+			}),
+		service.BackgroundWorker(StatusConsistencyCheckComponent,
+			[]string{service.CredentialComponent},
+			func(ctx context.Context, ssi *service.SSIService) {
+				ssi.Credential.RunStatusConsistencyCheck(ctx)
+			}),
+	}
+}
+
+// Shutdown gracefully shuts down the HTTP server, and then stops all services in the reverse order they were started.
+// Services are stopped even when the HTTP server fails to shut down gracefully.
+func (s *SSIServer) Shutdown(ctx context.Context) error {
+	shutdownErr := s.Server.Shutdown(ctx)
+	if err := s.SSIService.Stop(ctx); err != nil {
+		logrus.WithError(err).Error("stopping services")
+		if shutdownErr == nil {
+			return errors.Wrap(err, "stopping services")
+		}
+	}
+	return shutdownErr
+}
+
+// publishStatusListRefreshed returns a function which publishes the StatusList Refresh webhook with each status list
+// credential re-signed on a schedule.
+func publishStatusListRefreshed(webhookService *webhook.Service) credential.StatusListRefreshedFunc {
+	return func(ctx context.Context, statusList credint.Container) {
+		payload, err := json.Marshal(router.GetCredentialStatusListResponse{
+			ID:            statusList.ID,
+			Credential:    statusList.Credential,
+			CredentialJWT: statusList.CredentialJWT,
+		})
+		if err != nil {
+			logrus.WithError(err).Errorf("marshalling status list credential<%s>", statusList.ID)
+			return
+		}
+		webhookService.Publish(ctx, webhook.StatusList, webhook.Refresh, payload)
+	}
+}
+
+// publishStatusListHostingMismatch returns a function which publishes the StatusList HostingMismatch webhook with each
+// status list credential not served as stored from its public URI.
+func publishStatusListHostingMismatch(webhookService *webhook.Service) credential.StatusListHostingMismatchFunc {
+	return func(ctx context.Context, mismatch credential.StatusListHostingMismatch) {
+		payload, err := json.Marshal(mismatch)
+		if err != nil {
+			logrus.WithError(err).Errorf("marshalling hosting mismatch of status list credential<%s>", mismatch.ID)
+			return
+		}
+		webhookService.Publish(ctx, webhook.StatusList, webhook.HostingMismatch, payload)
+	}
+}
+
+// publishStatusMismatch returns a function which publishes the StatusList StatusMismatch webhook with each credential
+// whose stored status does not match the bit of its status list.
+func publishStatusMismatch(webhookService *webhook.Service) credential.StatusMismatchFunc {
+	return func(ctx context.Context, mismatch credential.StatusMismatch) {
+		payload, err := json.Marshal(mismatch)
+		if err != nil {
+			logrus.WithError(err).Errorf("marshalling status mismatch of credential<%s>", mismatch.CredentialID)
+			return
+		}
+		webhookService.Publish(ctx, webhook.StatusList, webhook.StatusMismatch, payload)
+	}
+}
+
+// publishVerificationFailed returns a function which publishes the VerificationFailed webhook of the noun with each
+// failed verification.
+func publishVerificationFailed(webhookService *webhook.Service, noun webhook.Noun) verification.FailureFunc {
+	return func(ctx context.Context, failure verification.Failure) {
+		payload, err := json.Marshal(failure)
+		if err != nil {
+			logrus.WithError(err).Errorf("marshalling %s verification failure", noun)
+			return
+		}
+		webhookService.PublishVerificationFailed(ctx, noun, failure.ReasonCode, payload)
+	}
+}
+
 // setUpEngine creates the gin engine and sets up the middleware based on config
 func setUpEngine(cfg config.ServerConfig, shutdown chan os.Signal) *gin.Engine {
 	gin.ForceConsoleColor()
@@ -122,8 +323,9 @@ func setUpEngine(cfg config.ServerConfig, shutdown chan os.Signal) *gin.Engine {
 		gin.Recovery(),
 		gin.Logger(),
 		middleware.Errors(shutdown),
+		middleware.StreamLimits(cfg.Streaming),
 		// uncomment the below line to enable middle ware auth, see doc/config/auth.md for details
-		// middleware.AuthMiddleware()
+		// middleware.AuthMiddleware(AuthExemptRoutes...)
 	}
 	if cfg.JagerEnabled {
 		middlewares = append(middlewares, otelgin.Middleware(config.ServiceName))
@@ -176,11 +378,17 @@ func DecentralizedIdentityAPI(rg *gin.RouterGroup, service *didsvc.Service, did
 	didAPI.GET("", didRouter.ListDIDMethods)
 	didAPI.PUT("/:method", middleware.Webhook(webhookService, webhook.DID, webhook.Create), didRouter.CreateDIDByMethod)
 	didAPI.PUT("/:method/:id", didRouter.UpdateDIDByMethod)
+	didAPI.PATCH("/:method/:id", didRouter.UpdateDIDMetadata)
 	didAPI.PUT("/:method/batch", middleware.Webhook(webhookService, webhook.DID, webhook.BatchCreate), batchDIDRouter.BatchCreateDIDs)
 	didAPI.GET("/:method", didRouter.ListDIDsByMethod)
 	didAPI.GET("/:method/:id", didRouter.GetDIDByMethod)
 	didAPI.DELETE("/:method/:id", didRouter.SoftDeleteDIDByMethod)
+	didAPI.PUT("/:method/:id"+DelegationsPath, didRouter.CreateDelegation)
+	didAPI.GET("/:method/:id"+DelegationsPath, didRouter.ListDelegations)
+	didAPI.DELETE("/:method/:id"+DelegationsPath+"/:delegationId", didRouter.RevokeDelegation)
 	didAPI.GET(ResolverPrefix+"/:id", didRouter.ResolveDID)
+	didAPI.POST(ResolverPrefix+"/batch", didRouter.BatchResolveDIDs)
+	didAPI.GET(VerificationMethodsPath+"/:id", didRouter.ResolveVerificationMethod)
 	return
 }
 
@@ -197,12 +405,13 @@ func SchemaAPI(rg *gin.RouterGroup, service svcframework.Service, webhookService
 	schemaAPI.PUT("", middleware.Webhook(webhookService, webhook.Schema, webhook.Create), schemaRouter.CreateSchema)
 	schemaAPI.GET("/:id", schemaRouter.GetSchema)
 	schemaAPI.GET("", schemaRouter.ListSchemas)
+	schemaAPI.PATCH("/:id", schemaRouter.UpdateSchema)
 	schemaAPI.DELETE("/:id", middleware.Webhook(webhookService, webhook.Schema, webhook.Delete), schemaRouter.DeleteSchema)
 	return
 }
 
 // CredentialAPI registers all HTTP handlers for the Credentials Service
-func CredentialAPI(rg *gin.RouterGroup, service svcframework.Service, webhookService *webhook.Service, statusEndpoint string) (err error) {
+func CredentialAPI(rg *gin.RouterGroup, service svcframework.Service, webhookService *webhook.Service, statusEndpoint string, issuerAPIKeys []config.IssuerAPIKeyConfig, publicStatus config.PublicCredentialStatusConfig) (err error) {
 	credRouter, err := router.NewCredentialRouter(service)
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, "creating credential router")
@@ -218,20 +427,48 @@ func CredentialAPI(rg *gin.RouterGroup, service svcframework.Service, webhookSer
 		config.SetStatusBase(fmt.Sprintf("%s/status", config.GetServicePath(svcframework.Credential)))
 	}
 
+	// routes acting as an issuer are restricted to the issuers permitted for the caller's API key
+	issuerScopedAuth := middleware.IssuerScopedAuth(issuerAPIKeys)
+
 	// Credentials
-	credentialAPI := rg.Group(CredentialsPrefix)
-	credentialAPI.PUT("", middleware.Webhook(webhookService, webhook.Credential, webhook.Create), credRouter.CreateCredential)
-	credentialAPI.PUT(batchSuffix, middleware.Webhook(webhookService, webhook.Credential, webhook.BatchCreate), credRouter.BatchCreateCredentials)
+	credentialAPI := rg.Group(CredentialsPrefix, middleware.ClientOutputFormat(issuerAPIKeys))
+	credentialAPI.PUT("", issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.Create), credRouter.CreateCredential)
+	credentialAPI.PUT(batchSuffix, issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.BatchCreate), credRouter.BatchCreateCredentials)
 	credentialAPI.GET("", credRouter.ListCredentials)
+	credentialAPI.POST(SearchPath, credRouter.SearchCredentials)
 	credentialAPI.GET("/:id", credRouter.GetCredential)
+	credentialAPI.GET("/:id"+RenderPath, credRouter.GetCredentialRender)
+	credentialAPI.GET("/:id"+DiffPath, credRouter.DiffCredentials)
+	// receipts are posted by the subjects of credentials, not their issuers, and are authenticated by their signature
+	credentialAPI.POST("/:id"+ReceiptPath, credRouter.StoreCredentialReceipt)
 	credentialAPI.PUT(VerificationPath, credRouter.VerifyCredential)
-	credentialAPI.DELETE("/:id", middleware.Webhook(webhookService, webhook.Credential, webhook.Delete), credRouter.DeleteCredential)
+	credentialAPI.GET("/:id"+VerificationPath, credRouter.VerifyCredentialByID)
+	credentialAPI.GET("/:id"+HistoryPath, credRouter.GetCredentialHistory)
+	// imported credentials are issued by other parties, so importing requires an API key but not one of their issuer
+	credentialAPI.PUT(ImportsPath, issuerScopedAuth, credRouter.ImportCredential)
+	credentialAPI.DELETE("/:id", issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.Delete), credRouter.DeleteCredential)
 
 	// Credential Status
 	credentialAPI.GET("/:id"+StatusPrefix, credRouter.GetCredentialStatus)
-	credentialAPI.PUT("/:id"+StatusPrefix, credRouter.UpdateCredentialStatus)
-	credentialAPI.PUT(StatusPrefix+batchSuffix, credRouter.BatchUpdateCredentialStatus)
+	credentialAPI.PUT("/:id"+StatusPrefix, issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.StatusUpdate), credRouter.UpdateCredentialStatus)
+	credentialAPI.POST("/:id"+RevokePath, issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.StatusUpdate), credRouter.RevokeCredential)
+	credentialAPI.POST("/:id"+SuspendPath, issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.StatusUpdate), credRouter.SuspendCredential)
+	credentialAPI.POST("/:id"+UnsuspendPath, issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.StatusUpdate), credRouter.UnsuspendCredential)
+	credentialAPI.PUT(StatusPrefix+batchSuffix, issuerScopedAuth, middleware.Webhook(webhookService, webhook.Credential, webhook.BatchStatusUpdate), credRouter.BatchUpdateCredentialStatus)
+	credentialAPI.GET(StatusPrefix+CapacityPath, credRouter.GetStatusListCapacity)
+	credentialAPI.GET(StatisticsPath, credRouter.GetIssuerStatistics)
+	credentialAPI.GET(EventsPath, credRouter.ListCredentialEvents)
 	credentialAPI.GET(StatusPrefix+"/:id", credRouter.GetCredentialStatusList)
+	credentialAPI.GET(StatusPrefix+"/:id"+CredentialsPrefix, credRouter.ListStatusListCredentials)
+
+	// Credentials held by a subject, for wallets
+	subjectAPI := rg.Group(SubjectsPrefix)
+	subjectAPI.GET("/:id"+CredentialsPrefix, credRouter.ListSubjectCredentials)
+
+	// the public status lookup is unauthenticated, so it is only registered when enabled, and is rate limited
+	if publicStatus.Enabled {
+		credentialAPI.GET("/:id"+StatusPrefix+PublicPath, middleware.RateLimit(publicStatus.RateLimit, time.Minute), credRouter.GetPublicCredentialStatus(publicStatus.MaxAge))
+	}
 	return
 }
 
@@ -247,6 +484,9 @@ func PresentationAPI(rg *gin.RouterGroup, service svcframework.Service, webhookS
 
 	presAPI := rg.Group(PresentationsPrefix)
 	presAPI.PUT(VerificationPath, presRouter.VerifyPresentation)
+	presAPI.PUT(VerificationPath+batchSuffix, presRouter.BatchVerifyPresentations)
+	presAPI.PUT(ChallengesPrefix, presRouter.CreateChallenge)
+	presAPI.POST(ConstructPath, presRouter.ConstructPresentation)
 
 	presDefAPI := rg.Group(PresentationsPrefix + DefinitionsPrefix)
 	presDefAPI.PUT("", presRouter.CreateDefinition)
@@ -341,8 +581,9 @@ func IssuanceAPI(rg *gin.RouterGroup, service svcframework.Service) error {
 	return nil
 }
 
-// WebhookAPI registers all HTTP handlers for the Webhook Service
-func WebhookAPI(rg *gin.RouterGroup, service svcframework.Service) (err error) {
+// WebhookAPI registers all HTTP handlers for the Webhook Service. Test events are limited to testRateLimit per minute
+// for each client IP.
+func WebhookAPI(rg *gin.RouterGroup, service svcframework.Service, testRateLimit int) (err error) {
 	webhookRouter, err := router.NewWebhookRouter(service)
 	if err != nil {
 		return sdkutil.LoggingErrorMsg(err, "creating webhook router")
@@ -355,6 +596,8 @@ func WebhookAPI(rg *gin.RouterGroup, service svcframework.Service) (err error) {
 	webhookAPI.PUT("", webhookRouter.CreateWebhook)
 	webhookAPI.GET("", webhookRouter.ListWebhooks)
 	webhookAPI.GET("/:noun/:verb", webhookRouter.GetWebhook)
+	webhookAPI.GET("/:noun/:verb"+DeliveriesPath, webhookRouter.ListWebhookDeliveries)
+	webhookAPI.POST("/:noun/:verb"+TestPath, middleware.RateLimit(testRateLimit, time.Minute), webhookRouter.TestWebhook)
 	webhookAPI.DELETE("/:noun/:verb", webhookRouter.DeleteWebhook)
 
 	// TODO(gabe): consider refactoring this to a single get on /webhooks/info or similar
@@ -378,3 +621,63 @@ func DIDConfigurationAPI(rg *gin.RouterGroup, service svcframework.Service) erro
 
 	return nil
 }
+
+// IssuerMetadataAPI registers the HTTP handler serving the OpenID4VCI credential issuer metadata at its well-known location
+func IssuerMetadataAPI(rg *gin.RouterGroup, service svcframework.Service) error {
+	issuerMetadataRouter, err := router.NewIssuerMetadataRouter(service)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "creating issuer metadata router")
+	}
+
+	rg.GET(wellknown.OpenIDCredentialIssuerLocationSuffix, issuerMetadataRouter.GetIssuerMetadata)
+	return nil
+}
+
+// SSIConfigurationAPI registers the HTTP handler serving the signed SSI configuration at its well-known location
+func SSIConfigurationAPI(rg *gin.RouterGroup, service svcframework.Service) error {
+	ssiConfigurationRouter, err := router.NewSSIConfigurationRouter(service)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "creating ssi configuration router")
+	}
+
+	rg.GET(wellknown.SSIConfigurationLocationSuffix, ssiConfigurationRouter.GetSSIConfiguration)
+	return nil
+}
+
+// AdminAPI registers all HTTP handlers for operating the service. All routes but the demo webhook sink are guarded by
+// the admin auth middleware.
+func AdminAPI(rg *gin.RouterGroup, cfg config.SSIServiceConfig, service svcframework.Service, webhookService *webhook.Service) (err error) {
+	adminRouter, err := router.NewAdminRouter(cfg, service)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "creating admin router")
+	}
+
+	// make sure the admin service is configured to use the correct path, which the demo webhook posts to
+	config.SetServicePath(svcframework.Admin, AdminPrefix)
+
+	adminAPI := rg.Group(AdminPrefix, middleware.AdminAuthMiddleware())
+	adminAPI.GET(ConfigPath, adminRouter.GetConfig)
+	adminAPI.GET(ExportPath, adminRouter.ExportBundle)
+	adminAPI.POST(ImportPath, adminRouter.ImportBundle)
+	adminAPI.GET(StoragePath, adminRouter.GetStorageStats)
+	adminAPI.POST(StoragePath+CompactionPath, adminRouter.CompactStorage)
+	adminAPI.POST(ReindexPath, adminRouter.ReindexCredentials)
+	adminAPI.POST(StatusConsistencyPath, adminRouter.CheckStatusConsistency)
+	adminAPI.POST(SeedPath, adminRouter.SeedDemoData)
+	adminAPI.DELETE(SeedPath, adminRouter.DeleteDemoData)
+	adminAPI.GET(IssuersPath+FreezesPath, adminRouter.ListIssuerFreezes)
+	adminAPI.POST(IssuersPath+"/:did"+FreezePath, middleware.Webhook(webhookService, webhook.Issuer, webhook.Freeze), adminRouter.FreezeIssuer)
+	adminAPI.POST(IssuersPath+"/:did"+UnfreezePath, middleware.Webhook(webhookService, webhook.Issuer, webhook.Unfreeze), adminRouter.UnfreezeIssuer)
+
+	// presentation definitions and manifests are promoted between deployments where they live, by operators
+	presDefAPI := rg.Group(PresentationsPrefix+DefinitionsPrefix, middleware.AdminAuthMiddleware())
+	presDefAPI.GET("/:id"+ExportPath, adminRouter.ExportPresentationDefinition)
+	presDefAPI.POST(ImportPath, adminRouter.ImportPresentationDefinition)
+	manifestAPI := rg.Group(ManifestsPrefix, middleware.AdminAuthMiddleware())
+	manifestAPI.GET("/:id"+ExportPath, adminRouter.ExportManifest)
+	manifestAPI.POST(ImportPath, adminRouter.ImportManifest)
+
+	// webhooks are posted without credentials, so the sink only accepts them while demo data is seeded
+	rg.POST(AdminPrefix+router.DemoWebhookSinkPath, adminRouter.DemoWebhookSink)
+	return
+}
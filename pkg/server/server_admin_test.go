@@ -0,0 +1,1106 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/admin"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/manifest"
+	manifestmodel "github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
+	presmodel "github.com/tbd54566975/ssi-service/pkg/service/presentation/model"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestAdminAPI(t *testing.T) {
+	t.Run("Test Get Config", func(tt *testing.T) {
+		serviceConfig, err := config.LoadConfig("", nil)
+		require.NoError(tt, err)
+		serviceConfig.Services.StorageOptions = []storage.Option{
+			{ID: storage.BoltDBFilePathOption, Option: "admin.db"},
+			{ID: storage.PasswordOption, Option: "hunter2"},
+		}
+		serviceConfig.Services.KeyStoreConfig.MasterKeyURI = "gcp-kms://projects/p/locations/l/keyRings/r/cryptoKeys/k"
+		serviceConfig.Services.KeyStoreConfig.KMSCredentialsPath = "/secrets/kms-credentials.json"
+
+		db := testutil.TestDatabases[0].ServiceStorage(tt)
+		keyStoreService, _ := testKeyStoreService(tt, db)
+		adminRouter := testAdminRouter(tt, *serviceConfig, db, keyStoreService)
+		req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/config", nil)
+		w := httptest.NewRecorder()
+		c := newRequestContext(w, req)
+		adminRouter.GetConfig(c)
+		assert.True(tt, util.Is2xxResponse(w.Code))
+
+		body := w.Body.String()
+		assert.NotContains(tt, body, "hunter2")
+		assert.NotContains(tt, body, "gcp-kms://")
+		assert.NotContains(tt, body, "kms-credentials.json")
+		assert.Contains(tt, body, "admin.db")
+
+		var resp router.GetConfigResponse
+		require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(tt, testServerURL, resp.APIBase)
+		assert.Equal(tt, config.ServiceVersion, resp.Version)
+		assert.Equal(tt, serviceConfig.Server.EnableSchemaCaching, resp.FeatureFlags["schemaCaching"])
+		assert.True(tt, resp.FeatureFlags["keyStoreEncryption"])
+
+		sanitized := resp.Config.(map[string]any)
+		services := sanitized["services"].(map[string]any)
+		assert.Equal(tt, serviceConfig.Services.StorageProvider, services["storage"])
+		keyStore := services["keystore"].(map[string]any)
+		assert.Equal(tt, config.MaskedValue, keyStore["master_key_uri"])
+		assert.Equal(tt, config.MaskedValue, keyStore["kms_credentials_path"])
+	})
+
+	t.Run("Test Storage Stats and Compaction", func(tt *testing.T) {
+		serviceConfig, err := config.LoadConfig("", nil)
+		require.NoError(tt, err)
+
+		for _, test := range testutil.TestDatabases {
+			db := test.ServiceStorage(tt)
+			keyStoreService, _ := testKeyStoreService(tt, db)
+			adminRouter := testAdminRouter(tt, *serviceConfig, db, keyStoreService)
+			for i := 0; i < 100; i++ {
+				require.NoError(tt, db.Write(context.Background(), "compaction", fmt.Sprintf("key-%d", i), []byte("value")))
+			}
+			for i := 0; i < 90; i++ {
+				require.NoError(tt, db.Delete(context.Background(), "compaction", fmt.Sprintf("key-%d", i)))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/storage", nil)
+			w := httptest.NewRecorder()
+			adminRouter.GetStorageStats(newRequestContext(w, req))
+			assert.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+			var statsResp router.GetStorageStatsResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&statsResp))
+			assert.Equal(tt, 10, statsResp.Namespaces["compaction"].KeyCount)
+			if db.Type() != storage.Bolt {
+				assert.Zero(tt, statsResp.SizeBytes)
+
+				req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/storage/compaction", nil)
+				w = httptest.NewRecorder()
+				adminRouter.CompactStorage(newRequestContext(w, req))
+				assert.Equal(tt, http.StatusNotImplemented, w.Code)
+				continue
+			}
+			assert.Positive(tt, statsResp.SizeBytes)
+			assert.Zero(tt, statsResp.SoftQuotaBytes)
+			assert.False(tt, statsResp.OverSoftQuota)
+
+			// a storage larger than its soft quota is reported as over it, without failing
+			quotaConfig := *serviceConfig
+			quotaConfig.Services.StorageSoftQuotaBytes = 1
+			quotaRouter := testAdminRouter(tt, quotaConfig, db, keyStoreService)
+			w = httptest.NewRecorder()
+			quotaRouter.GetStorageStats(newRequestContext(w, req))
+			assert.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+			var quotaResp router.GetStorageStatsResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&quotaResp))
+			assert.Equal(tt, int64(1), quotaResp.SoftQuotaBytes)
+			assert.True(tt, quotaResp.OverSoftQuota)
+
+			req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/storage/compaction", nil)
+			w = httptest.NewRecorder()
+			adminRouter.CompactStorage(newRequestContext(w, req))
+			assert.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+			var compactResp router.CompactStorageResponse
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&compactResp))
+			assert.Positive(tt, compactResp.SizeAfterBytes)
+
+			// compaction drops the cached stats
+			req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/storage", nil)
+			w = httptest.NewRecorder()
+			adminRouter.GetStorageStats(newRequestContext(w, req))
+			require.NoError(tt, json.NewDecoder(w.Body).Decode(&statsResp))
+			assert.Equal(tt, compactResp.SizeAfterBytes, statsResp.SizeBytes)
+
+			value, err := db.Read(context.Background(), "compaction", "key-99")
+			require.NoError(tt, err)
+			assert.Equal(tt, []byte("value"), value)
+		}
+	})
+
+	t.Run("Test Namespace Stats", func(tt *testing.T) {
+		serviceConfig, err := config.LoadConfig("", nil)
+		require.NoError(tt, err)
+
+		for _, test := range testutil.TestDatabases {
+			db := test.ServiceStorage(tt)
+			keyStoreService, _ := testKeyStoreService(tt, db)
+			adminRouter := testAdminRouter(tt, *serviceConfig, db, keyStoreService)
+			written := map[string]int{"stats-credential": 5, "stats-did": 3, "stats-operation": 1}
+			for namespace, count := range written {
+				for i := 0; i < count; i++ {
+					require.NoError(tt, db.Write(context.Background(), namespace, fmt.Sprintf("key-%d", i), []byte("value")))
+				}
+			}
+
+			getStorageStats := func() router.GetStorageStatsResponse {
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/storage", nil)
+				w := httptest.NewRecorder()
+				adminRouter.GetStorageStats(newRequestContext(w, req))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var resp router.GetStorageStatsResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				return resp
+			}
+			resp := getStorageStats()
+			for namespace, count := range written {
+				assert.Equal(tt, count, resp.Namespaces[namespace].KeyCount, namespace)
+				assert.GreaterOrEqual(tt, resp.Namespaces[namespace].SizeBytes, int64(count*len("key-0value")), namespace)
+			}
+
+			// stats are cached for a short time
+			require.NoError(tt, db.Write(context.Background(), "stats-did", "another", []byte("value")))
+			assert.Equal(tt, 3, getStorageStats().Namespaces["stats-did"].KeyCount)
+		}
+
+		// storage providers which cannot report stats are told apart from failing ones
+		db := unreportedStorage{ServiceStorage: testutil.TestDatabases[0].ServiceStorage(tt)}
+		keyStoreService, _ := testKeyStoreService(tt, db)
+		adminRouter := testAdminRouter(tt, *serviceConfig, db, keyStoreService)
+		req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/storage", nil)
+		w := httptest.NewRecorder()
+		adminRouter.GetStorageStats(newRequestContext(w, req))
+		assert.Equal(tt, http.StatusNotImplemented, w.Code)
+		assert.Contains(tt, w.Body.String(), storage.ErrNotSupported.Error())
+	})
+
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Run("Test Seed Demo Data", func(tt *testing.T) {
+				serviceConfig, err := config.LoadConfig("", nil)
+				require.NoError(tt, err)
+
+				// the store is empty
+				db := test.ServiceStorage(tt)
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(tt, db, keyStoreService, didService, schemaService)
+				credentialService := testCredentialService(tt, db, keyStoreService, didService, schemaService)
+				presentationService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, db, didService.GetResolver(), schemaService, keyStoreService)
+				require.NoError(tt, err)
+				manifestService, err := manifest.NewManifestService(config.ManifestServiceConfig{}, db, keyStoreService, didService.GetResolver(), credentialService, presentationService)
+				require.NoError(tt, err)
+				webhookService := testWebhookService(tt, db)
+
+				adminService, err := admin.NewAdminService(serviceConfig.Services, db, keyStoreService)
+				require.NoError(tt, err)
+				adminService.SetDemoServices(admin.DemoServices{
+					DID:          didService,
+					Schema:       schemaService,
+					Credential:   credentialService,
+					Presentation: presentationService,
+					Manifest:     manifestService,
+					Webhook:      webhookService,
+				})
+				adminRouter, err := router.NewAdminRouter(*serviceConfig, adminService)
+				require.NoError(tt, err)
+
+				seed := func() *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/seed", nil)
+					w := httptest.NewRecorder()
+					adminRouter.SeedDemoData(newRequestContext(w, req))
+					return w
+				}
+				postToSink := func() int {
+					requestValue := newRequestValue(tt, webhook.Payload{Noun: webhook.Credential, Verb: webhook.Create, URL: "https://ssi-service.com/v1/admin" + router.DemoWebhookSinkPath})
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/seed/webhooks", requestValue)
+					w := httptest.NewRecorder()
+					adminRouter.DemoWebhookSink(newRequestContext(w, req))
+					return w.Code
+				}
+				// the webhook sink only accepts payloads while demo data is seeded
+				assert.Equal(tt, http.StatusNotFound, postToSink())
+
+				w := seed()
+				require.Equal(tt, http.StatusCreated, w.Code, w.Body.String())
+				assert.True(tt, util.Is2xxResponse(postToSink()))
+				var resp router.SeedDemoDataResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+
+				// each resource exists
+				ctx := context.Background()
+				gotDID, err := didService.GetDIDByMethod(ctx, did.GetDIDRequest{Method: didsdk.KeyMethod, ID: resp.IssuerDID})
+				require.NoError(tt, err)
+				assert.Equal(tt, admin.DemoTag, gotDID.CreatedBy)
+				gotSchema, err := schemaService.GetSchema(ctx, schema.GetSchemaRequest{ID: resp.SchemaID})
+				require.NoError(tt, err)
+				assert.Equal(tt, resp.SchemaID, gotSchema.ID)
+				definition, err := presentationService.GetPresentationDefinition(ctx, presmodel.GetPresentationDefinitionRequest{ID: resp.PresentationDefinitionID})
+				require.NoError(tt, err)
+				assert.True(tt, strings.HasPrefix(definition.PresentationDefinition.ID, admin.DemoTag))
+				gotManifest, err := manifestService.GetManifest(ctx, manifestmodel.GetManifestRequest{ID: resp.ManifestID})
+				require.NoError(tt, err)
+				assert.Equal(tt, resp.SchemaID, gotManifest.Manifest.OutputDescriptors[0].Schema)
+				gotWebhook, err := webhookService.GetWebhook(ctx, webhook.GetWebhookRequest{Noun: webhook.Credential, Verb: webhook.Create})
+				require.NoError(tt, err)
+				assert.Equal(tt, []string{resp.WebhookURL}, gotWebhook.Webhook.URLS)
+				assert.Equal(tt, admin.DemoTag, gotWebhook.Webhook.CreatedBy[resp.WebhookURL])
+				webhookURL, err := url.Parse(resp.WebhookURL)
+				require.NoError(tt, err)
+				assert.True(tt, strings.HasSuffix(webhookURL.Path, router.DemoWebhookSinkPath))
+				assert.Equal(tt, admin.DemoTag, webhookURL.Query().Get("tag"))
+				gotCredential, err := credentialService.GetCredential(ctx, credential.GetCredentialRequest{ID: resp.CredentialID})
+				require.NoError(tt, err)
+				assert.Equal(tt, admin.DemoTag, gotCredential.CreatedBy)
+				assert.NotEmpty(tt, gotCredential.Credential.CredentialStatus)
+
+				// and the credential verifies
+				verified, err := credentialService.VerifyCredential(ctx, credential.VerifyCredentialRequest{CredentialJWT: &resp.CredentialJWT})
+				require.NoError(tt, err)
+				assert.True(tt, verified.Verified, verified.Reason)
+
+				// demo data is seeded once
+				w = seed()
+				assert.Equal(tt, http.StatusConflict, w.Code)
+
+				deleteDemoData := func() {
+					req := httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/admin/seed", nil)
+					w := httptest.NewRecorder()
+					adminRouter.DeleteDemoData(newRequestContext(w, req))
+					require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				}
+				deleteDemoData()
+				_, err = schemaService.GetSchema(ctx, schema.GetSchemaRequest{ID: resp.SchemaID})
+				assert.Error(tt, err)
+				_, err = manifestService.GetManifest(ctx, manifestmodel.GetManifestRequest{ID: resp.ManifestID})
+				assert.Error(tt, err)
+				_, err = presentationService.GetPresentationDefinition(ctx, presmodel.GetPresentationDefinitionRequest{ID: resp.PresentationDefinitionID})
+				assert.Error(tt, err)
+				_, err = webhookService.GetWebhook(ctx, webhook.GetWebhookRequest{Noun: webhook.Credential, Verb: webhook.Create})
+				assert.Error(tt, err)
+				_, err = credentialService.GetCredential(ctx, credential.GetCredentialRequest{ID: resp.CredentialID})
+				assert.Error(tt, err)
+				assert.Equal(tt, http.StatusNotFound, postToSink())
+
+				// deleting again does nothing, and demo data can be seeded anew, once by concurrent seeds
+				deleteDemoData()
+				var wg sync.WaitGroup
+				codes := make([]int, 5)
+				for i := range codes {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						codes[i] = seed().Code
+					}(i)
+				}
+				wg.Wait()
+				assert.ElementsMatch(tt, []int{http.StatusCreated, http.StatusConflict, http.StatusConflict, http.StatusConflict, http.StatusConflict}, codes)
+			})
+
+			t.Run("Test Export and Import Bundle", func(tt *testing.T) {
+				serviceConfig, err := config.LoadConfig("", nil)
+				require.NoError(tt, err)
+
+				// populate a store with a DID, a schema, a presentation definition, and a manifest
+				sourceDB := test.ServiceStorage(tt)
+				keyStoreService, _ := testKeyStoreService(tt, sourceDB)
+				didService, _ := testDIDService(tt, sourceDB, keyStoreService, nil)
+				schemaService := testSchemaService(tt, sourceDB, keyStoreService, didService)
+				credentialService := testCredentialService(tt, sourceDB, keyStoreService, didService, schemaService)
+				manifestRouter, _ := testManifest(tt, sourceDB, keyStoreService, didService, credentialService)
+				presentationService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, sourceDB, didService.GetResolver(), schemaService, keyStoreService)
+				require.NoError(tt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				verificationMethodID := issuerDID.DID.VerificationMethod[0].ID
+
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name: "simple schema",
+					Schema: map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"type":    "object",
+						"properties": map[string]any{
+							"credentialSubject": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"firstName": map[string]any{
+										"type": "string",
+									},
+								},
+								"required": []any{"firstName"},
+							},
+						},
+					},
+				})
+				require.NoError(tt, err)
+
+				definition, err := presentationService.CreatePresentationDefinition(context.Background(), presmodel.CreatePresentationDefinitionRequest{
+					PresentationDefinition: exchange.PresentationDefinition{
+						ID: "bundled-definition",
+						InputDescriptors: []exchange.InputDescriptor{
+							{
+								ID: "id-1",
+								Constraints: &exchange.Constraints{
+									Fields: []exchange.Field{{Path: []string{"$.vc.credentialSubject.firstName"}}},
+								},
+							},
+						},
+					},
+				})
+				require.NoError(tt, err)
+
+				createManifestRequest := getValidCreateManifestRequest(issuerDID.DID.ID, verificationMethodID, createdSchema.ID)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests", newRequestValue(tt, createManifestRequest))
+				w := httptest.NewRecorder()
+				manifestRouter.CreateManifest(newRequestContext(w, req))
+				require.True(tt, util.Is2xxResponse(w.Code))
+				var manifestResp router.CreateManifestResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&manifestResp))
+
+				// exporting DIDs requires a passphrase
+				sourceRouter := testAdminRouter(tt, *serviceConfig, sourceDB, keyStoreService)
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/export?includeDids=true", nil)
+				w = httptest.NewRecorder()
+				sourceRouter.ExportBundle(newRequestContext(w, req))
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				assert.Contains(tt, w.Body.String(), admin.ErrPassphraseRequired.Error())
+
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/export?includeDids=true", nil)
+				req.Header.Set(router.BundlePassphraseHeader, "correct horse battery staple")
+				w = httptest.NewRecorder()
+				sourceRouter.ExportBundle(newRequestContext(w, req))
+				require.True(tt, util.Is2xxResponse(w.Code))
+
+				var exported router.ExportBundleResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&exported))
+				assert.Len(tt, exported.Schemas, 1)
+				assert.Len(tt, exported.PresentationDefinitions, 1)
+				assert.Len(tt, exported.Manifests, 1)
+				assert.Len(tt, exported.DIDs, 1)
+				require.NotEmpty(tt, exported.EncryptedKeys)
+
+				// import into a fresh store
+				destDB := test.ServiceStorage(tt)
+				destKeyStoreService, _ := testKeyStoreService(tt, destDB)
+				destRouter := testAdminRouter(tt, *serviceConfig, destDB, destKeyStoreService)
+				importBundle := func(request router.ImportBundleRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/import", newRequestValue(tt, request))
+					w := httptest.NewRecorder()
+					destRouter.ImportBundle(newRequestContext(w, req))
+					return w
+				}
+
+				w = importBundle(router.ImportBundleRequest{Bundle: exported.Bundle, Passphrase: "wrong passphrase"})
+				assert.Contains(tt, w.Body.String(), "the passphrase may be incorrect")
+
+				w = importBundle(router.ImportBundleRequest{Bundle: exported.Bundle, Passphrase: "correct horse battery staple"})
+				require.True(tt, util.Is2xxResponse(w.Code))
+				var imported router.ImportBundleResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&imported))
+				require.Len(tt, imported.Results, 5)
+				for _, result := range imported.Results {
+					assert.Equal(tt, admin.ImportStatusCreated, result.Status, result.ID)
+				}
+
+				// importing again skips existing items, unless overwriting
+				w = importBundle(router.ImportBundleRequest{Bundle: exported.Bundle, Passphrase: "correct horse battery staple"})
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&imported))
+				for _, result := range imported.Results {
+					assert.Equal(tt, admin.ImportStatusSkipped, result.Status, result.ID)
+				}
+				w = importBundle(router.ImportBundleRequest{Bundle: exported.Bundle, Passphrase: "correct horse battery staple", Overwrite: true})
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&imported))
+				for _, result := range imported.Results {
+					assert.Equal(tt, admin.ImportStatusOverwritten, result.Status, result.ID)
+				}
+
+				// the imported items keep their original IDs
+				destDIDService, _ := testDIDService(tt, destDB, destKeyStoreService, nil)
+				destSchemaService := testSchemaService(tt, destDB, destKeyStoreService, destDIDService)
+				gotSchema, err := destSchemaService.GetSchema(context.Background(), schema.GetSchemaRequest{ID: createdSchema.ID})
+				require.NoError(tt, err)
+				assert.Equal(tt, createdSchema.ID, gotSchema.ID)
+				destPresentationService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, destDB, destDIDService.GetResolver(), destSchemaService, destKeyStoreService)
+				require.NoError(tt, err)
+				_, err = destPresentationService.GetPresentationDefinition(context.Background(), presmodel.GetPresentationDefinitionRequest{ID: definition.PresentationDefinition.ID})
+				require.NoError(tt, err)
+
+				// issue a credential against the imported schema, signed with the imported key
+				destCredentialRouter := testCredentialRouter(tt, destDB, destKeyStoreService, destDIDService, destSchemaService)
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: verificationMethodID,
+					Subject:              "did:abc:456",
+					SchemaID:             createdSchema.ID,
+					Data:                 map[string]any{"firstName": "Satoshi"},
+					Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+				}
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, createCredRequest))
+				w = httptest.NewRecorder()
+				destCredentialRouter.CreateCredential(newRequestContext(w, req))
+				assert.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+			})
+
+			t.Run("Test Export and Import Presentation Definition and Manifest", func(tt *testing.T) {
+				serviceConfig, err := config.LoadConfig("", nil)
+				require.NoError(tt, err)
+
+				// populate a store with a composed schema, a presentation definition referencing it, and a manifest
+				sourceDB := test.ServiceStorage(tt)
+				keyStoreService, _ := testKeyStoreService(tt, sourceDB)
+				didService, _ := testDIDService(tt, sourceDB, keyStoreService, nil)
+				schemaService := testSchemaService(tt, sourceDB, keyStoreService, didService)
+				credentialService := testCredentialService(tt, sourceDB, keyStoreService, didService, schemaService)
+				manifestRouter, _ := testManifest(tt, sourceDB, keyStoreService, didService, credentialService)
+				presentationService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, sourceDB, didService.GetResolver(), schemaService, keyStoreService)
+				require.NoError(tt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				verificationMethodID := issuerDID.DID.VerificationMethod[0].ID
+
+				baseSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name: "base schema",
+					Schema: map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"type":    "object",
+						"properties": map[string]any{
+							"firstName": map[string]any{"type": "string"},
+						},
+					},
+				})
+				require.NoError(tt, err)
+				composedSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name: "composed schema",
+					Schema: map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"allOf":   []any{map[string]any{"$ref": baseSchema.ID}},
+					},
+				})
+				require.NoError(tt, err)
+
+				definition, err := presentationService.CreatePresentationDefinition(context.Background(), presmodel.CreatePresentationDefinitionRequest{
+					PresentationDefinition: exchange.PresentationDefinition{
+						ID: uuid.NewString(),
+						InputDescriptors: []exchange.InputDescriptor{
+							{
+								ID: "id-1",
+								Constraints: &exchange.Constraints{
+									Fields: []exchange.Field{
+										{
+											Path:   []string{"$.credentialSchema.id"},
+											Filter: &exchange.Filter{Type: "string", Const: composedSchema.ID},
+										},
+										{
+											Path:   []string{"$.issuer"},
+											Filter: &exchange.Filter{Type: "string", Const: issuerDID.DID.ID},
+										},
+									},
+								},
+							},
+						},
+					},
+				})
+				require.NoError(tt, err)
+
+				createManifestRequest := getValidCreateManifestRequest(issuerDID.DID.ID, verificationMethodID, baseSchema.ID)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests", newRequestValue(tt, createManifestRequest))
+				w := httptest.NewRecorder()
+				manifestRouter.CreateManifest(newRequestContext(w, req))
+				require.True(tt, util.Is2xxResponse(w.Code))
+				var manifestResp router.CreateManifestResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&manifestResp))
+
+				// the exported definition holds the schemas it references, directly or through composition
+				sourceRouter := testAdminRouter(tt, *serviceConfig, sourceDB, keyStoreService)
+				definitionID := definition.PresentationDefinition.ID
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/presentations/definitions/%s/export", definitionID), nil)
+				w = httptest.NewRecorder()
+				sourceRouter.ExportPresentationDefinition(newRequestContextWithParams(w, req, map[string]string{"id": definitionID}))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var exported router.ExportBundleResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&exported))
+				require.Len(tt, exported.PresentationDefinitions, 1)
+				assert.Len(tt, exported.Schemas, 2)
+				assert.Empty(tt, exported.Manifests)
+				assert.Empty(tt, exported.DIDs)
+
+				importItem := func(adminRouter *router.AdminRouter, handler func(*router.AdminRouter, *gin.Context), request router.ImportItemRequest) router.ImportItemResponse {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/import", newRequestValue(tt, request))
+					w := httptest.NewRecorder()
+					handler(adminRouter, newRequestContext(w, req))
+					require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+					var imported router.ImportItemResponse
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&imported))
+					return imported
+				}
+				importDefinition := (*router.AdminRouter).ImportPresentationDefinition
+				importManifest := (*router.AdminRouter).ImportManifest
+
+				// evaluating a credential against a definition builds a presentation submission and verifies it
+				evaluate := func(def exchange.PresentationDefinition, schemaID string) error {
+					cred := credsdk.VerifiableCredential{
+						Context:           []any{credsdk.VerifiableCredentialsLinkedDataContext},
+						ID:                uuid.NewString(),
+						Type:              []any{credsdk.VerifiableCredentialType},
+						Issuer:            issuerDID.DID.ID,
+						IssuanceDate:      time.Now().Format(time.RFC3339),
+						CredentialSubject: credsdk.CredentialSubject{"id": "did:abc:456", "firstName": "Satoshi"},
+						CredentialSchema:  &credsdk.CredentialSchema{ID: schemaID, Type: schemalib.JSONSchemaType.String()},
+					}
+					credJSON, err := sdkutil.ToJSONMap(cred)
+					require.NoError(tt, err)
+					vp, err := exchange.BuildPresentationSubmissionVP("did:abc:456", def, []exchange.NormalizedClaim{
+						{ID: cred.ID, Data: credJSON, RawClaim: credJSON, Format: string(exchange.LDPVC)},
+					})
+					require.NoError(tt, err)
+					_, err = exchange.VerifyPresentationSubmissionVP(def, *vp)
+					return err
+				}
+
+				// importing with preserved IDs gives a copy which evaluates credentials like the original
+				destDB := test.ServiceStorage(tt)
+				destKeyStoreService, _ := testKeyStoreService(tt, destDB)
+				destRouter := testAdminRouter(tt, *serviceConfig, destDB, destKeyStoreService)
+				imported := importItem(destRouter, importDefinition, router.ImportItemRequest{Bundle: exported.Bundle, PreserveIDs: true})
+				assert.Equal(tt, definitionID, imported.ID)
+				require.Len(tt, imported.Results, 3)
+				for _, result := range imported.Results {
+					assert.Equal(tt, admin.ImportStatusCreated, result.Status, result.ID)
+					assert.Empty(tt, result.OriginalID)
+				}
+				assert.Equal(tt, []string{issuerDID.DID.ID}, imported.MissingIssuerDIDs)
+
+				destDIDService, _ := testDIDService(tt, destDB, destKeyStoreService, nil)
+				destSchemaService := testSchemaService(tt, destDB, destKeyStoreService, destDIDService)
+				destPresentationService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, destDB, destDIDService.GetResolver(), destSchemaService, destKeyStoreService)
+				require.NoError(tt, err)
+				preserved, err := destPresentationService.GetPresentationDefinition(context.Background(), presmodel.GetPresentationDefinitionRequest{ID: definitionID})
+				require.NoError(tt, err)
+				assert.Equal(tt, definition.PresentationDefinition, preserved.PresentationDefinition)
+				for _, def := range []exchange.PresentationDefinition{definition.PresentationDefinition, preserved.PresentationDefinition} {
+					assert.NoError(tt, evaluate(def, composedSchema.ID))
+					assert.Error(tt, evaluate(def, baseSchema.ID))
+				}
+
+				// importing again skips the existing items
+				imported = importItem(destRouter, importDefinition, router.ImportItemRequest{Bundle: exported.Bundle, PreserveIDs: true})
+				for _, result := range imported.Results {
+					assert.Equal(tt, admin.ImportStatusSkipped, result.Status, result.ID)
+				}
+
+				// importing with new IDs updates the references to the imported schemas
+				imported = importItem(destRouter, importDefinition, router.ImportItemRequest{Bundle: exported.Bundle})
+				assert.NotEqual(tt, definitionID, imported.ID)
+				require.Len(tt, imported.Results, 3)
+				newIDs := make(map[string]string)
+				for _, result := range imported.Results {
+					assert.Equal(tt, admin.ImportStatusCreated, result.Status, result.ID)
+					newIDs[result.OriginalID] = result.ID
+				}
+				assert.Equal(tt, imported.ID, newIDs[definitionID])
+				newComposedID, newBaseID := newIDs[composedSchema.ID], newIDs[baseSchema.ID]
+				require.NotEmpty(tt, newComposedID)
+				require.NotEmpty(tt, newBaseID)
+
+				gotComposed, err := destSchemaService.GetSchema(context.Background(), schema.GetSchemaRequest{ID: newComposedID})
+				require.NoError(tt, err)
+				assert.Equal(tt, []any{map[string]any{"$ref": newBaseID}}, (*gotComposed.Schema)["allOf"])
+
+				copied, err := destPresentationService.GetPresentationDefinition(context.Background(), presmodel.GetPresentationDefinitionRequest{ID: imported.ID})
+				require.NoError(tt, err)
+				assert.Equal(tt, imported.ID, copied.PresentationDefinition.ID)
+				assert.Equal(tt, newComposedID, copied.PresentationDefinition.InputDescriptors[0].Constraints.Fields[0].Filter.Const)
+				assert.NoError(tt, evaluate(copied.PresentationDefinition, newComposedID))
+				assert.Error(tt, evaluate(copied.PresentationDefinition, composedSchema.ID))
+				assert.Error(tt, evaluate(definition.PresentationDefinition, newComposedID))
+
+				// a manifest is exported with the schema of its output descriptors, and imported with a new ID
+				manifestID := manifestResp.Manifest.ID
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/manifests/%s/export", manifestID), nil)
+				w = httptest.NewRecorder()
+				sourceRouter.ExportManifest(newRequestContextWithParams(w, req, map[string]string{"id": manifestID}))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var exportedManifest router.ExportBundleResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&exportedManifest))
+				require.Len(tt, exportedManifest.Manifests, 1)
+				require.Len(tt, exportedManifest.Schemas, 1)
+				assert.Equal(tt, baseSchema.ID, exportedManifest.Schemas[0].ID)
+
+				// a manifest bundle can't be imported as a presentation definition
+				req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/presentations/definitions/import", newRequestValue(tt, router.ImportItemRequest{Bundle: exportedManifest.Bundle}))
+				w = httptest.NewRecorder()
+				destRouter.ImportPresentationDefinition(newRequestContext(w, req))
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+
+				imported = importItem(destRouter, importManifest, router.ImportItemRequest{Bundle: exportedManifest.Bundle})
+				assert.NotEqual(tt, manifestID, imported.ID)
+				assert.Equal(tt, []string{issuerDID.DID.ID}, imported.MissingIssuerDIDs)
+				require.Len(tt, imported.Results, 2)
+				newManifestBaseID := imported.Results[0].ID
+				assert.Equal(tt, baseSchema.ID, imported.Results[0].OriginalID)
+
+				destManifestRouter, _ := testManifest(tt, destDB, destKeyStoreService, destDIDService, testCredentialService(tt, destDB, destKeyStoreService, destDIDService, destSchemaService))
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/manifests/%s", imported.ID), nil)
+				w = httptest.NewRecorder()
+				destManifestRouter.GetManifest(newRequestContextWithParams(w, req, map[string]string{"id": imported.ID}))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var gotManifest router.ListManifestResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&gotManifest))
+				assert.Equal(tt, imported.ID, gotManifest.Manifest.ID)
+				for _, descriptor := range gotManifest.Manifest.OutputDescriptors {
+					assert.Equal(tt, newManifestBaseID, descriptor.Schema)
+				}
+			})
+
+			t.Run("Test Reindex Credentials", func(tt *testing.T) {
+				serviceConfig, err := config.LoadConfig("", nil)
+				require.NoError(tt, err)
+
+				db := test.ServiceStorage(tt)
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(tt, db, keyStoreService, didService, schemaService)
+				adminRouter := testAdminRouter(tt, *serviceConfig, db, keyStoreService)
+				opRouter := setupOperationsRouter(tt, db)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				var createdIDs []string
+				for _, subject := range []string{"did:abc:123", "did:abc:456"} {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              subject,
+						Data:                 map[string]any{"firstName": "Satoshi"},
+					}))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+					var created router.CreateCredentialResponse
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&created))
+					createdIDs = append(createdIDs, created.ID)
+				}
+
+				listCredentials := func(query string) []string {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListCredentials(newRequestContext(w, req))
+					require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+					var resp router.ListCredentialsResponse
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+					var ids []string
+					for _, cred := range resp.Credentials {
+						ids = append(ids, cred.ID)
+					}
+					return ids
+				}
+				corrupt := func(id string, moveTo func(key string) string, field, value string) {
+					entries, err := db.ReadPrefix(context.Background(), "credential", id)
+					require.NoError(tt, err)
+					require.Len(tt, entries, 1)
+					for key, entry := range entries {
+						var stored map[string]any
+						require.NoError(tt, json.Unmarshal(entry, &stored))
+						stored[field] = value
+						corrupted, err := json.Marshal(stored)
+						require.NoError(tt, err)
+						require.NoError(tt, db.Write(context.Background(), "credential", moveTo(key), corrupted))
+						if moveTo(key) != key {
+							require.NoError(tt, db.Delete(context.Background(), "credential", key))
+						}
+					}
+				}
+
+				// the first credential is moved to a key with another issuer, and the second loses its subject
+				corrupt(createdIDs[0], func(key string) string {
+					return strings.Replace(key, issuerDID.DID.ID, "did:corrupt:issuer", 1)
+				}, "issuer", "did:corrupt:issuer")
+				corrupt(createdIDs[1], func(key string) string { return key }, "subject", "did:corrupt:subject")
+				assert.ElementsMatch(tt, []string{createdIDs[1]}, listCredentials("issuer="+issuerDID.DID.ID))
+				assert.Empty(tt, listCredentials("subject=did:abc:456"))
+
+				reindex := func() credential.ReindexResult {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/reindex", nil)
+					w := httptest.NewRecorder()
+					adminRouter.ReindexCredentials(newRequestContext(w, req))
+					require.Equal(tt, http.StatusCreated, w.Code, w.Body.String())
+					var op router.Operation
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&op))
+					require.True(tt, strings.HasPrefix(op.ID, "admin/reindexes/"))
+
+					require.Eventually(tt, func() bool {
+						req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/operations/"+op.ID, nil)
+						w := httptest.NewRecorder()
+						opRouter.GetOperation(newRequestContextWithParams(w, req, map[string]string{"id": op.ID}))
+						return util.Is2xxResponse(w.Code) && json.NewDecoder(w.Body).Decode(&op) == nil && op.Done
+					}, 5*time.Second, 10*time.Millisecond)
+					require.Empty(tt, op.Result.Error)
+
+					responseBytes, err := json.Marshal(op.Result.Response)
+					require.NoError(tt, err)
+					var result credential.ReindexResult
+					require.NoError(tt, json.Unmarshal(responseBytes, &result))
+					return result
+				}
+
+				result := reindex()
+				assert.GreaterOrEqual(tt, result.Scanned, 2)
+				assert.Equal(tt, 2, result.Added)
+				assert.Equal(tt, 2, result.Removed)
+				assert.ElementsMatch(tt, createdIDs, listCredentials("issuer="+issuerDID.DID.ID))
+				assert.ElementsMatch(tt, []string{createdIDs[1]}, listCredentials("subject=did:abc:456"))
+
+				// the entry of the first credential is back at its key, and can be deleted
+				req := httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/credentials/"+createdIDs[0], nil)
+				w := httptest.NewRecorder()
+				credRouter.DeleteCredential(newRequestContextWithParams(w, req, map[string]string{"id": createdIDs[0]}))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				assert.ElementsMatch(tt, []string{createdIDs[1]}, listCredentials("issuer="+issuerDID.DID.ID))
+
+				// reindexing consistent indexes changes nothing
+				result = reindex()
+				assert.Equal(tt, 1, result.Scanned)
+				assert.Zero(tt, result.Added)
+				assert.Zero(tt, result.Removed)
+			})
+
+			t.Run("Test Check Status Consistency", func(tt *testing.T) {
+				serviceConfig, err := config.LoadConfig("", nil)
+				require.NoError(tt, err)
+
+				db := test.ServiceStorage(tt)
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(tt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(tt, db, keyStoreService, didService, schemaService)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(tt, err)
+				opRouter := setupOperationsRouter(tt, db)
+
+				adminService, err := admin.NewAdminService(serviceConfig.Services, db, keyStoreService)
+				require.NoError(tt, err)
+				adminRouter, err := router.NewAdminRouter(*serviceConfig, adminService)
+				require.NoError(tt, err)
+
+				// the check is not supported without the credential service
+				req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/status-consistency", nil)
+				w := httptest.NewRecorder()
+				adminRouter.CheckStatusConsistency(newRequestContext(w, req))
+				assert.Equal(tt, http.StatusNotImplemented, w.Code)
+				adminService.SetCredentialService(credService)
+
+				var mu sync.Mutex
+				var published []credential.StatusMismatch
+				credService.OnStatusMismatch(func(_ context.Context, mismatch credential.StatusMismatch) {
+					mu.Lock()
+					defer mu.Unlock()
+					published = append(published, mismatch)
+				})
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				var created []credential.CreateCredentialResponse
+				for _, subject := range []string{"did:abc:1", "did:abc:2", "did:abc:3"} {
+					cred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            subject,
+						Data:                               map[string]any{"firstName": "Satoshi"},
+						Revocable:                          true,
+					})
+					require.NoError(tt, err)
+					created = append(created, *cred)
+				}
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": created[0].ID}))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				checkStatusConsistency := func(query string) credential.StatusConsistencyResult {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/status-consistency"+query, nil)
+					w := httptest.NewRecorder()
+					adminRouter.CheckStatusConsistency(newRequestContext(w, req))
+					require.Equal(tt, http.StatusCreated, w.Code, w.Body.String())
+					var op router.Operation
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&op))
+					require.True(tt, strings.HasPrefix(op.ID, "admin/status-consistency-checks/"))
+
+					require.Eventually(tt, func() bool {
+						req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/operations/"+op.ID, nil)
+						w := httptest.NewRecorder()
+						opRouter.GetOperation(newRequestContextWithParams(w, req, map[string]string{"id": op.ID}))
+						return util.Is2xxResponse(w.Code) && json.NewDecoder(w.Body).Decode(&op) == nil && op.Done
+					}, 5*time.Second, 10*time.Millisecond)
+					require.Empty(tt, op.Result.Error)
+
+					responseBytes, err := json.Marshal(op.Result.Response)
+					require.NoError(tt, err)
+					var result credential.StatusConsistencyResult
+					require.NoError(tt, json.Unmarshal(responseBytes, &result))
+					return result
+				}
+				statusListURI := created[0].Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+				isRevokedInStatusList := func(cred credential.CreateCredentialResponse) bool {
+					statusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: path.Base(statusListURI)})
+					require.NoError(tt, err)
+					revoked, err := statussdk.ValidateCredentialInStatusList(*cred.Credential, *statusList.Credential)
+					require.NoError(tt, err)
+					return revoked
+				}
+
+				result := checkStatusConsistency("")
+				assert.Equal(tt, 3, result.Credentials)
+				assert.Equal(tt, 1, result.StatusLists)
+				assert.Empty(tt, result.Mismatches)
+
+				// the stored flags drift from the status list: the first credential is no longer stored as revoked,
+				// and the second is, without either bit being flipped
+				setRevoked := func(id string, revoked bool) {
+					entries, err := db.ReadPrefix(context.Background(), "credential", id)
+					require.NoError(tt, err)
+					require.Len(tt, entries, 1)
+					for key, entry := range entries {
+						var stored map[string]any
+						require.NoError(tt, json.Unmarshal(entry, &stored))
+						stored["revoked"] = revoked
+						corrupted, err := json.Marshal(stored)
+						require.NoError(tt, err)
+						require.NoError(tt, db.Write(context.Background(), "credential", key, corrupted))
+					}
+				}
+				setRevoked(created[0].ID, false)
+				setRevoked(created[1].ID, true)
+
+				result = checkStatusConsistency("?repair=false")
+				require.Len(tt, result.Mismatches, 2)
+				mismatches := make(map[string]credential.StatusMismatch)
+				for _, mismatch := range result.Mismatches {
+					mismatches[mismatch.CredentialID] = mismatch
+					assert.False(tt, mismatch.Repaired)
+					assert.Equal(tt, "revocation", mismatch.StatusPurpose)
+				}
+				assert.Equal(tt, credential.StatusBitSet, mismatches[created[0].ID].Reason)
+				assert.False(tt, mismatches[created[0].ID].Stored)
+				assert.Equal(tt, credential.StatusBitUnset, mismatches[created[1].ID].Reason)
+				assert.True(tt, mismatches[created[1].ID].Stored)
+				assert.Zero(tt, result.RepairedStatusLists)
+				assert.True(tt, isRevokedInStatusList(created[0]))
+				assert.False(tt, isRevokedInStatusList(created[1]))
+				mu.Lock()
+				assert.Len(tt, published, 2)
+				mu.Unlock()
+
+				// repairing re-derives the status list from the stored flags
+				result = checkStatusConsistency("?repair=true")
+				require.Len(tt, result.Mismatches, 2)
+				for _, mismatch := range result.Mismatches {
+					assert.True(tt, mismatch.Repaired)
+				}
+				assert.Equal(tt, 1, result.RepairedStatusLists)
+				assert.False(tt, isRevokedInStatusList(created[0]))
+				assert.True(tt, isRevokedInStatusList(created[1]))
+				assert.False(tt, isRevokedInStatusList(created[2]))
+
+				result = checkStatusConsistency("")
+				assert.Equal(tt, 3, result.Credentials)
+				assert.Empty(tt, result.Mismatches)
+
+				req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/status-consistency?repair=maybe", nil)
+				w = httptest.NewRecorder()
+				adminRouter.CheckStatusConsistency(newRequestContext(w, req))
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+			})
+
+			t.Run("Test Freeze Issuer", func(tt *testing.T) {
+				serviceConfig, err := config.LoadConfig("", nil)
+				require.NoError(tt, err)
+
+				db := test.ServiceStorage(tt)
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(tt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(tt, db, keyStoreService, didService, schemaService)
+
+				adminService, err := admin.NewAdminService(serviceConfig.Services, db, keyStoreService)
+				require.NoError(tt, err)
+				adminRouter, err := router.NewAdminRouter(*serviceConfig, adminService)
+				require.NoError(tt, err)
+
+				freezeIssuer := func(issuer, reason string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/issuers/"+issuer+"/freeze", newRequestValue(tt, router.FreezeIssuerRequest{Reason: reason}))
+					w := httptest.NewRecorder()
+					adminRouter.FreezeIssuer(newRequestContextWithParams(w, req, map[string]string{"did": issuer}))
+					return w
+				}
+				unfreezeIssuer := func(issuer string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/admin/issuers/"+issuer+"/unfreeze", nil)
+					w := httptest.NewRecorder()
+					adminRouter.UnfreezeIssuer(newRequestContextWithParams(w, req, map[string]string{"did": issuer}))
+					return w
+				}
+				listIssuerFreezes := func() []router.IssuerFreeze {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/admin/issuers/freezes", nil)
+					w := httptest.NewRecorder()
+					adminRouter.ListIssuerFreezes(newRequestContext(w, req))
+					require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+					var resp router.ListIssuerFreezesResponse
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.Freezes
+				}
+
+				frozenDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				otherDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				createCredential := func(issuerDID *did.CreateDIDResponse) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(tt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:123",
+						Data:                 map[string]any{"firstName": "Satoshi"},
+						Revocable:            true,
+					}))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+				updateCredentialStatus := func(id string, revoked bool) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(tt, router.UpdateCredentialStatusRequest{Revoked: revoked}))
+					w := httptest.NewRecorder()
+					credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+
+				// issuers cannot be frozen without the credential service
+				assert.Equal(tt, http.StatusNotImplemented, freezeIssuer(frozenDID.DID.ID, "incident").Code)
+				adminService.SetCredentialService(credService)
+
+				w := createCredential(frozenDID)
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var issuedBeforeFreeze router.CreateCredentialResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&issuedBeforeFreeze))
+
+				// a reason is required
+				assert.Equal(tt, http.StatusBadRequest, freezeIssuer(frozenDID.DID.ID, "").Code)
+				w = freezeIssuer(frozenDID.DID.ID, "signing key under investigation")
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				var freeze router.IssuerFreeze
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&freeze))
+				assert.Equal(tt, frozenDID.DID.ID, freeze.Issuer)
+				assert.Equal(tt, "signing key under investigation", freeze.Reason)
+				assert.NotEmpty(tt, freeze.FrozenAt)
+				assert.Equal(tt, []router.IssuerFreeze{freeze}, listIssuerFreezes())
+
+				// issuance and status changes of the frozen issuer are rejected, naming the freeze
+				w = createCredential(frozenDID)
+				assert.Equal(tt, http.StatusLocked, w.Code)
+				assert.Contains(tt, w.Body.String(), "signing key under investigation")
+				_, err = credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+					Requests: []credential.CreateCredentialRequest{
+						{
+							Issuer:                             otherDID.DID.ID,
+							FullyQualifiedVerificationMethodID: otherDID.DID.VerificationMethod[0].ID,
+							Subject:                            "did:abc:456",
+							Data:                               map[string]any{"firstName": "Satoshi"},
+						},
+						{
+							Issuer:                             frozenDID.DID.ID,
+							FullyQualifiedVerificationMethodID: frozenDID.DID.VerificationMethod[0].ID,
+							Subject:                            "did:abc:456",
+							Data:                               map[string]any{"firstName": "Satoshi"},
+						},
+					},
+				})
+				assert.ErrorIs(tt, err, credential.ErrIssuerFrozen)
+				w = updateCredentialStatus(issuedBeforeFreeze.ID, true)
+				assert.Equal(tt, http.StatusLocked, w.Code)
+				assert.Contains(tt, w.Body.String(), "signing key under investigation")
+
+				// reads and verification are not affected
+				gotStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: issuedBeforeFreeze.ID})
+				require.NoError(tt, err)
+				assert.False(tt, gotStatus.Revoked)
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: issuedBeforeFreeze.CredentialJWT})
+				require.NoError(tt, err)
+				assert.True(tt, verified.Verified, verified.Reason)
+
+				// other issuers are not affected
+				w = createCredential(otherDID)
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				w = unfreezeIssuer(frozenDID.DID.ID)
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				assert.Empty(tt, listIssuerFreezes())
+				assert.Equal(tt, http.StatusNotFound, unfreezeIssuer(frozenDID.DID.ID).Code)
+
+				w = createCredential(frozenDID)
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				w = updateCredentialStatus(issuedBeforeFreeze.ID, true)
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+			})
+		})
+	}
+}
+
+// unreportedStorage hides the optional capabilities of the storage it embeds, such as reporting stats.
+type unreportedStorage struct {
+	storage.ServiceStorage
+}
+
+func testAdminRouter(t *testing.T, cfg config.SSIServiceConfig, db storage.ServiceStorage, keyStore *keystore.Service) *router.AdminRouter {
+	adminService, err := admin.NewAdminService(cfg.Services, db, keyStore)
+	require.NoError(t, err)
+
+	adminRouter, err := router.NewAdminRouter(cfg, adminService)
+	require.NoError(t, err)
+	return adminRouter
+}
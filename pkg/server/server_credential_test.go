@@ -2,30 +2,59 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/benbjohnson/clock"
+	"github.com/gin-gonic/gin"
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/mohae/deepcopy"
+	"github.com/mr-tron/base58"
 
 	"github.com/tbd54566975/ssi-service/pkg/testutil"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/key"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tbd54566975/ssi-service/config"
+	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
+	didint "github.com/tbd54566975/ssi-service/internal/did"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	"github.com/tbd54566975/ssi-service/pkg/server/middleware"
+	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/analytics"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 func TestCredentialAPI(t *testing.T) {
@@ -323,6 +352,32 @@ func TestCredentialAPI(t *testing.T) {
 					assert.Equal(ttt, http.StatusBadRequest, w.Code)
 					assert.Contains(ttt, w.Body.String(), "max number of requests is 1000")
 				})
+
+				ttt.Run("Creates nothing when the request is canceled mid-batch", func(ttt *testing.T) {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+					canceling := &cancelingStorage{ServiceStorage: db, namespace: "credential", cancel: cancel}
+					cancelingService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10}, canceling, keyStoreService, didService.GetResolver(), schemaService)
+					require.NoError(ttt, err)
+					cancelingRouter, err := router.NewCredentialRouter(cancelingService)
+					require.NoError(ttt, err)
+
+					before, err := db.ReadAll(context.Background(), "credential")
+					require.NoError(ttt, err)
+
+					requestValue := newRequestValue(ttt, batchCreateCredentialsRequest)
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", requestValue).WithContext(ctx)
+					w := httptest.NewRecorder()
+					c := newRequestContext(w, req)
+					cancelingRouter.BatchCreateCredentials(c)
+					assert.Equal(ttt, http.StatusRequestTimeout, w.Code)
+					assert.Contains(ttt, w.Body.String(), "TIMEOUT")
+
+					// the credential written before the request was canceled was rolled back
+					after, err := db.ReadAll(context.Background(), "credential")
+					require.NoError(ttt, err)
+					assert.Len(ttt, after, len(before))
+				})
 			})
 
 			tt.Run("Test Create Credential", func(ttt *testing.T) {
@@ -374,7 +429,7 @@ func TestCredentialAPI(t *testing.T) {
 				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", missingIssuerRequestValue)
 				c = newRequestContext(w, req)
 				credRouter.CreateCredential(c)
-				assert.Contains(ttt, w.Body.String(), "getting key for signing credential<did:abc:123#key-1>")
+				assert.Contains(ttt, w.Body.String(), "could not resolve issuer<did:abc:123>")
 
 				// reset the http recorder
 				w = httptest.NewRecorder()
@@ -408,6 +463,29 @@ func TestCredentialAPI(t *testing.T) {
 				assert.NotPanics(ttt, func() {
 					uuid.MustParse(after)
 				})
+
+				// a request canceled during its transaction is rolled back and times out
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				canceling := &cancelingStorage{ServiceStorage: db, namespace: "credential", cancel: cancel}
+				cancelingService, err := credential.NewCredentialService(config.CredentialServiceConfig{}, canceling, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				cancelingRouter, err := router.NewCredentialRouter(cancelingService)
+				require.NoError(ttt, err)
+
+				before, err := db.ReadAll(context.Background(), "credential")
+				require.NoError(ttt, err)
+
+				w = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest)).WithContext(ctx)
+				c = newRequestContext(w, req)
+				cancelingRouter.CreateCredential(c)
+				assert.Equal(ttt, http.StatusRequestTimeout, w.Code)
+				assert.Contains(ttt, w.Body.String(), "TIMEOUT")
+
+				afterCanceled, err := db.ReadAll(context.Background(), "credential")
+				require.NoError(ttt, err)
+				assert.Len(ttt, afterCanceled, len(before))
 			})
 
 			tt.Run("Test Create Credential with Schema", func(ttt *testing.T) {
@@ -517,6 +595,541 @@ func TestCredentialAPI(t *testing.T) {
 				assert.Contains(ttt, w.Body.String(), "schema not found")
 			})
 
+			tt.Run("Test Schema Format Assertions", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				// formats are only annotations in this draft, unless asserted
+				emailSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft/2020-12/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"email": map[string]any{
+									"type":   "string",
+									"format": "email",
+								},
+							},
+							"required": []any{"email"},
+						},
+					},
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Issuer: "me", Name: "email schema", Schema: emailSchema})
+				require.NoError(ttt, err)
+
+				createRequest := func(email string) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           createdSchema.ID,
+						Data:                               map[string]any{"email": email},
+					}
+				}
+
+				annotatingService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				assertingService, err := credential.NewCredentialService(config.CredentialServiceConfig{
+					BatchCreateMaxItems:    10,
+					SchemaFormatAssertions: []string{"email"},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+
+				ttt.Run("unknown formats are rejected", func(ttt *testing.T) {
+					_, err := credential.NewCredentialService(config.CredentialServiceConfig{SchemaFormatAssertions: []string{"phone"}}, db, keyStoreService, didService.GetResolver(), schemaService)
+					assert.ErrorContains(ttt, err, "format<phone> cannot be asserted")
+				})
+
+				ttt.Run("malformed values fail creation when asserted", func(ttt *testing.T) {
+					_, err := assertingService.CreateCredential(context.Background(), createRequest("not an email"))
+					assert.ErrorContains(ttt, err, "credential data does not comply with the provided schema")
+
+					created, err := assertingService.CreateCredential(context.Background(), createRequest("jack@example.com"))
+					require.NoError(ttt, err)
+					assert.NotEmpty(ttt, created.CredentialJWT)
+				})
+
+				ttt.Run("malformed values pass when formats are annotations", func(ttt *testing.T) {
+					created, err := annotatingService.CreateCredential(context.Background(), createRequest("not an email"))
+					require.NoError(ttt, err)
+
+					verified, err := annotatingService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+					require.NoError(ttt, err)
+					assert.True(ttt, verified.Verified, verified.Reason)
+
+					// the same credential fails verification once the format is asserted
+					verified, err = assertingService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+					require.NoError(ttt, err)
+					assert.False(ttt, verified.Verified)
+					assert.Contains(ttt, verified.Reason, "does not comply with schema")
+				})
+			})
+
+			tt.Run("Test Schema Version Pinning", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				nameSchema := func(required ...any) map[string]any {
+					return map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"type":    "object",
+						"properties": map[string]any{
+							"credentialSubject": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"firstName": map[string]any{"type": "string"},
+									"lastName":  map[string]any{"type": "string"},
+								},
+								"required": required,
+							},
+						},
+					}
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Issuer: "me", Name: "name schema", Schema: nameSchema("firstName")})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 1, createdSchema.Version)
+
+				createRequest := func(data map[string]any) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           createdSchema.ID,
+						Data:                               data,
+					}
+				}
+				firstVersionCred, err := credService.CreateCredential(context.Background(), createRequest(map[string]any{"firstName": "Satoshi"}))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 1, firstVersionCred.SchemaVersion)
+
+				// the second version also requires a last name
+				secondVersion := schemalib.JSONSchema(nameSchema("firstName", "lastName"))
+				updated, err := schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, Schema: &secondVersion})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 2, updated.Version)
+
+				// credentials are verified against the version they were issued with
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: firstVersionCred.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified, verified.Reason)
+
+				_, err = credService.CreateCredential(context.Background(), createRequest(map[string]any{"firstName": "Satoshi"}))
+				assert.ErrorContains(ttt, err, "credential data does not comply with the provided schema")
+				secondVersionCred, err := credService.CreateCredential(context.Background(), createRequest(map[string]any{"firstName": "Satoshi", "lastName": "Nakamoto"}))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 2, secondVersionCred.SchemaVersion)
+
+				gotCred, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: firstVersionCred.ID})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 1, gotCred.SchemaVersion)
+
+				for version, expectedID := range map[int]string{1: firstVersionCred.ID, 2: secondVersionCred.ID} {
+					w := httptest.NewRecorder()
+					req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials?schema=%s&schemaVersion=%d", createdSchema.ID, version), nil)
+					c := newRequestContext(w, req)
+					credRouter.ListCredentials(c)
+					assert.True(ttt, util.Is2xxResponse(w.Code))
+
+					var listResp router.ListCredentialsResponse
+					err = json.NewDecoder(w.Body).Decode(&listResp)
+					require.NoError(ttt, err)
+					require.Len(ttt, listResp.Credentials, 1)
+					assert.Equal(ttt, expectedID, listResp.Credentials[0].ID)
+					assert.Equal(ttt, version, listResp.Credentials[0].SchemaVersion)
+				}
+
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?schemaVersion=abc", nil)
+				c := newRequestContext(w, req)
+				credRouter.ListCredentials(c)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Schema Expected Type", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				emailSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"email": map[string]any{"type": "string"},
+							},
+							"required": []any{"email"},
+						},
+					},
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Issuer: "me", Name: "email schema", Schema: emailSchema, ExpectedType: "EmailCredential"})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "EmailCredential", createdSchema.ExpectedType)
+
+				createRequest := func(types ...string) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           createdSchema.ID,
+						Types:                              types,
+						Data:                               map[string]any{"email": "jack@example.com"},
+					}
+				}
+
+				// the expected type is required at issuance
+				_, err = credService.CreateCredential(context.Background(), createRequest())
+				assert.ErrorIs(ttt, err, credential.ErrMissingExpectedType)
+				_, err = credService.CreateCredential(context.Background(), createRequest("AlumniCredential"))
+				assert.ErrorIs(ttt, err, credential.ErrMissingExpectedType)
+
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					SchemaID:             createdSchema.ID,
+					Data:                 map[string]any{"email": "jack@example.com"},
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+
+				created, err := credService.CreateCredential(context.Background(), createRequest("EmailCredential"))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, []string{"VerifiableCredential", "EmailCredential"}, created.Credential.Type)
+
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified, verified.Reason)
+
+				// verification checks the type the schema expects now
+				expectedType := "VerifiedEmailCredential"
+				_, err = schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, ExpectedType: &expectedType})
+				require.NoError(ttt, err)
+				verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.False(ttt, verified.Verified)
+				assert.Equal(ttt, verification.TypeMismatch, verified.ReasonCode)
+				assert.Contains(ttt, verified.Reason, expectedType)
+			})
+
+			tt.Run("Test Schema Validity Duration", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				mockClock := clock.NewMock()
+				mockClock.Set(time.Date(2023, 06, 23, 0, 0, 0, 0, time.UTC))
+				credService.Clock = mockClock
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				badgeSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"type":    "object",
+				}
+				for _, invalid := range []string{"-24h", "0s", "a day"} {
+					_, err = schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "visitor badge schema", Schema: badgeSchema, ValidityDuration: invalid})
+					assert.ErrorContains(ttt, err, "invalid validity duration")
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "visitor badge schema", Schema: badgeSchema, ValidityDuration: "24h"})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "24h", createdSchema.ValidityDuration)
+
+				createRequest := func(expiry string) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           createdSchema.ID,
+						Data:                               map[string]any{"visiting": "lab"},
+						Expiry:                             expiry,
+					}
+				}
+
+				// credentials requested without an expiry last as long as the schema says
+				created, err := credService.CreateCredential(context.Background(), createRequest(""))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "2023-06-24T00:00:00Z", created.Credential.ExpirationDate)
+
+				// an expiry in the request wins
+				created, err = credService.CreateCredential(context.Background(), createRequest("2023-06-30T00:00:00Z"))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "2023-06-30T00:00:00Z", created.Credential.ExpirationDate)
+
+				invalid := "-1h"
+				_, err = schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, ValidityDuration: &invalid})
+				assert.ErrorContains(ttt, err, "invalid validity duration")
+
+				// credentials don't expire once the validity duration is removed
+				removed := ""
+				updated, err := schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, ValidityDuration: &removed})
+				require.NoError(ttt, err)
+				assert.Empty(ttt, updated.ValidityDuration)
+				created, err = credService.CreateCredential(context.Background(), createRequest(""))
+				require.NoError(ttt, err)
+				assert.Empty(ttt, created.Credential.ExpirationDate)
+			})
+
+			tt.Run("Test Schema Claim Injection", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				schemaRouter, err := router.NewSchemaRouter(schemaService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				programSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft/2020-12/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"name":         map[string]any{"type": "string"},
+								"jurisdiction": map[string]any{"type": "string", "enum": []any{"US-CA", "US-NY"}},
+								"programId":    map[string]any{"type": "string"},
+							},
+							"required": []any{"name", "jurisdiction", "programId"},
+						},
+					},
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "program schema", Schema: programSchema})
+				require.NoError(ttt, err)
+
+				updateSchema := func(injection schema.ClaimInjection) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPatch, "https://ssi-service.com/v1/schemas/"+createdSchema.ID, newRequestValue(ttt, router.UpdateSchemaRequest{ClaimInjection: &injection}))
+					w := httptest.NewRecorder()
+					schemaRouter.UpdateSchema(newRequestContextWithParams(w, req, map[string]string{"id": createdSchema.ID}))
+					return w
+				}
+				createCredential := func(data map[string]any) *httptest.ResponseRecorder {
+					createCredRequest := router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						SchemaID:             createdSchema.ID,
+						Data:                 data,
+					}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+				subjectOf := func(w *httptest.ResponseRecorder) map[string]any {
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					_, _, signed, err := integrity.ParseVerifiableCredentialFromJWT(resp.CredentialJWT.String())
+					require.NoError(ttt, err)
+					assert.Equal(ttt, map[string]any(resp.Credential.CredentialSubject), map[string]any(signed.CredentialSubject))
+					return resp.Credential.CredentialSubject
+				}
+
+				// without injection, clients must include the claims
+				w := createCredential(map[string]any{"name": "Satoshi"})
+				assert.False(ttt, util.Is2xxResponse(w.Code))
+
+				// the subject id and unknown conflict policies cannot be injected
+				w = updateSchema(schema.ClaimInjection{Claims: map[string]any{"id": "did:abc:789"}})
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				w = updateSchema(schema.ClaimInjection{Claims: map[string]any{"jurisdiction": "US-CA"}, ConflictPolicy: "first-wins"})
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+
+				injected := map[string]any{"jurisdiction": "US-CA", "programId": "program-7"}
+				w = updateSchema(schema.ClaimInjection{Claims: injected})
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				var updateResp router.UpdateSchemaResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&updateResp))
+				require.NotNil(ttt, updateResp.ClaimInjection)
+				assert.Equal(ttt, injected, updateResp.ClaimInjection.Claims)
+
+				// injected claims are signed, and satisfy the schema
+				w = createCredential(map[string]any{"name": "Satoshi"})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				subject := subjectOf(w)
+				assert.Equal(ttt, "US-CA", subject["jurisdiction"])
+				assert.Equal(ttt, "program-7", subject["programId"])
+				assert.Equal(ttt, "Satoshi", subject["name"])
+
+				// a claim of the request with the injected value is no conflict
+				w = createCredential(map[string]any{"name": "Satoshi", "jurisdiction": "US-CA"})
+				assert.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+
+				// conflicts are rejected by default
+				w = createCredential(map[string]any{"name": "Satoshi", "jurisdiction": "US-NY"})
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "jurisdiction")
+
+				w = updateSchema(schema.ClaimInjection{Claims: injected, ConflictPolicy: schema.ClientWinsClaimConflict})
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				w = createCredential(map[string]any{"name": "Satoshi", "jurisdiction": "US-NY"})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				subject = subjectOf(w)
+				assert.Equal(ttt, "US-NY", subject["jurisdiction"])
+				assert.Equal(ttt, "program-7", subject["programId"])
+
+				w = updateSchema(schema.ClaimInjection{Claims: injected, ConflictPolicy: schema.ServerWinsClaimConflict})
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				w = createCredential(map[string]any{"name": "Satoshi", "jurisdiction": "US-NY"})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				subject = subjectOf(w)
+				assert.Equal(ttt, "US-CA", subject["jurisdiction"])
+
+				// injected values are validated against the schema
+				w = updateSchema(schema.ClaimInjection{Claims: map[string]any{"jurisdiction": "EU", "programId": "program-7"}})
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				w = createCredential(map[string]any{"name": "Satoshi"})
+				assert.False(ttt, util.Is2xxResponse(w.Code))
+
+				// injecting no claims removes the injection
+				w = updateSchema(schema.ClaimInjection{})
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				updateResp = router.UpdateSchemaResponse{}
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&updateResp))
+				assert.Nil(ttt, updateResp.ClaimInjection)
+				w = createCredential(map[string]any{"name": "Satoshi"})
+				assert.False(ttt, util.Is2xxResponse(w.Code))
+			})
+
+			tt.Run("Test Revalidate Credentials For Schema", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				ageSchema := func(minimum int) map[string]any {
+					age := map[string]any{"type": "integer"}
+					if minimum > 0 {
+						age["minimum"] = minimum
+					}
+					return map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"type":    "object",
+						"properties": map[string]any{
+							"credentialSubject": map[string]any{
+								"type":       "object",
+								"properties": map[string]any{"age": age},
+								"required":   []any{"age"},
+							},
+						},
+					}
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Issuer: "me", Name: "age schema", Schema: ageSchema(0)})
+				require.NoError(ttt, err)
+				otherSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Issuer: "me", Name: "other schema", Schema: ageSchema(0)})
+				require.NoError(ttt, err)
+
+				createCred := func(schemaID string, age int) string {
+					created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           schemaID,
+						Data:                               map[string]any{"age": age},
+					})
+					require.NoError(ttt, err)
+					return created.ID
+				}
+				var minorIDs []string
+				for _, age := range []int{30, 12, 45, 16, 18} {
+					id := createCred(createdSchema.ID, age)
+					if age < 18 {
+						minorIDs = append(minorIDs, id)
+					}
+				}
+				// credentials of other schemas are not revalidated
+				createCred(otherSchema.ID, 12)
+
+				// fix the schema by requiring adults, as if it had been wrong all along
+				schemaStorage, err := schema.NewSchemaStorage(db)
+				require.NoError(ttt, err)
+				storedSchema, err := schemaStorage.GetSchema(context.Background(), createdSchema.ID)
+				require.NoError(ttt, err)
+				fixedSchema := schemalib.JSONSchema(ageSchema(18))
+				storedSchema.Schema = &fixedSchema
+				require.NoError(ttt, schemaStorage.StoreSchema(context.Background(), *storedSchema))
+
+				ttt.Run("all credentials", func(ttt *testing.T) {
+					revalidated, err := credService.RevalidateCredentialsForSchema(context.Background(), createdSchema.ID, pagination.PageRequest{})
+					require.NoError(ttt, err)
+					assert.Equal(ttt, 5, revalidated.Checked)
+					assert.ElementsMatch(ttt, minorIDs, revalidated.FailedIDs())
+					assert.Empty(ttt, revalidated.NextPageToken)
+					for _, failure := range revalidated.Failures {
+						assert.Contains(ttt, failure.Reason, "credential not valid for schema")
+					}
+				})
+
+				ttt.Run("paginated", func(ttt *testing.T) {
+					pageSize := 2
+					request := pagination.PageRequest{PageSize: &pageSize}
+					checked := 0
+					var failedIDs []string
+					for {
+						revalidated, err := credService.RevalidateCredentialsForSchema(context.Background(), createdSchema.ID, request)
+						require.NoError(ttt, err)
+						checked += revalidated.Checked
+						failedIDs = append(failedIDs, revalidated.FailedIDs()...)
+						if revalidated.NextPageToken == "" {
+							break
+						}
+						request.PageToken = &revalidated.NextPageToken
+					}
+					assert.Equal(ttt, 5, checked)
+					assert.ElementsMatch(ttt, minorIDs, failedIDs)
+				})
+
+				ttt.Run("unknown schema", func(ttt *testing.T) {
+					_, err := credService.RevalidateCredentialsForSchema(context.Background(), "bad", pagination.PageRequest{})
+					assert.ErrorContains(ttt, err, "could not get schema: bad")
+				})
+			})
+
 			tt.Run("Test Get Credential By ID", func(ttt *testing.T) {
 				db := test.ServiceStorage(ttt)
 				require.NotEmpty(ttt, db)
@@ -855,6 +1468,117 @@ func TestCredentialAPI(t *testing.T) {
 				assert.Equal(ttt, createCredentialResponse.Credential.CredentialSubject[credsdk.VerifiableCredentialIDProperty], listCredentialsResponse.Credentials[0].Credential.CredentialSubject[credsdk.VerifiableCredentialIDProperty])
 			})
 
+			tt.Run("Test Search Credentials", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createdIDs := make(map[string]string)
+				for _, subject := range []string{"did:abc:0", "did:abc:1", "did:abc:2"} {
+					created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            subject,
+						Data:                               map[string]any{"firstName": "Jack"},
+					})
+					require.NoError(ttt, err)
+					createdIDs[subject] = created.ID
+				}
+
+				// a filter far longer than what fits in a URL, matching the first two subjects
+				subjectFilters := make([]string, 0, 200)
+				for i := 0; i < 200; i++ {
+					if i == 2 {
+						continue
+					}
+					subjectFilters = append(subjectFilters, fmt.Sprintf(`subject="did:abc:%d"`, i))
+				}
+				longFilter := strings.Join(subjectFilters, " OR ")
+				require.Greater(ttt, len(longFilter), 4096)
+
+				search := func(request router.SearchCredentialsRequest) *httptest.ResponseRecorder {
+					w := httptest.NewRecorder()
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/search", newRequestValue(ttt, request))
+					credRouter.SearchCredentials(newRequestContext(w, req))
+					return w
+				}
+
+				ttt.Run("long filter", func(ttt *testing.T) {
+					w := search(router.SearchCredentialsRequest{Filter: longFilter})
+					require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+
+					var resp router.ListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					require.Len(ttt, resp.Credentials, 2)
+					assert.ElementsMatch(ttt, []string{createdIDs["did:abc:0"], createdIDs["did:abc:1"]}, []string{resp.Credentials[0].ID, resp.Credentials[1].ID})
+					assert.Empty(ttt, resp.NextPageToken)
+				})
+
+				ttt.Run("combined comparisons", func(ttt *testing.T) {
+					filter := fmt.Sprintf(`issuer="%s" AND NOT subject="did:abc:0"`, issuerDID.DID.ID)
+					w := search(router.SearchCredentialsRequest{Filter: filter})
+					require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+
+					var resp router.ListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					require.Len(ttt, resp.Credentials, 2)
+					assert.ElementsMatch(ttt, []string{createdIDs["did:abc:1"], createdIDs["did:abc:2"]}, []string{resp.Credentials[0].ID, resp.Credentials[1].ID})
+				})
+
+				ttt.Run("paginated", func(ttt *testing.T) {
+					pageSize := 1
+					request := router.SearchCredentialsRequest{Filter: longFilter, PageSize: &pageSize}
+					var ids []string
+					for {
+						w := search(request)
+						require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+
+						var resp router.ListCredentialsResponse
+						require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+						for _, cred := range resp.Credentials {
+							ids = append(ids, cred.ID)
+						}
+						if resp.NextPageToken == "" {
+							break
+						}
+						request.PageToken = &resp.NextPageToken
+
+						// page tokens can't be used with another filter
+						otherFilter := search(router.SearchCredentialsRequest{Filter: `subject="did:abc:2"`, PageSize: &pageSize, PageToken: &resp.NextPageToken})
+						assert.Equal(ttt, http.StatusBadRequest, otherFilter.Code)
+						assert.Contains(ttt, otherFilter.Body.String(), "page token must be for the same query")
+					}
+					assert.ElementsMatch(ttt, []string{createdIDs["did:abc:0"], createdIDs["did:abc:1"]}, ids)
+				})
+
+				ttt.Run("invalid bodies", func(ttt *testing.T) {
+					zero := 0
+					badToken := "not a token"
+					for name, body := range map[string]io.Reader{
+						"not json":       strings.NewReader(`{"filter": `),
+						"unknown field":  strings.NewReader(`{"query": "subject=\"did:abc:0\""}`),
+						"malformed":      newRequestValue(ttt, router.SearchCredentialsRequest{Filter: `subject=`}),
+						"unknown ident":  newRequestValue(ttt, router.SearchCredentialsRequest{Filter: `firstName="Jack"`}),
+						"too long":       newRequestValue(ttt, router.SearchCredentialsRequest{Filter: strings.Repeat(" ", router.SearchFilterCharacterLimit+1)}),
+						"zero page size": newRequestValue(ttt, router.SearchCredentialsRequest{Filter: longFilter, PageSize: &zero}),
+						"bad page token": newRequestValue(ttt, router.SearchCredentialsRequest{Filter: longFilter, PageToken: &badToken}),
+					} {
+						w := httptest.NewRecorder()
+						req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/search", body)
+						credRouter.SearchCredentials(newRequestContext(w, req))
+						assert.Equal(ttt, http.StatusBadRequest, w.Code, name)
+					}
+				})
+			})
+
 			tt.Run("Test Delete Credential", func(ttt *testing.T) {
 				db := test.ServiceStorage(ttt)
 				require.NotEmpty(ttt, db)
@@ -999,7 +1723,7 @@ func TestCredentialAPI(t *testing.T) {
 				assert.Contains(ttt, verifyResp.Reason, "parsing JWT: parsing credential token: invalid JWT")
 			})
 
-			tt.Run("Test Create Revocable Credential", func(ttt *testing.T) {
+			tt.Run("Test Verifying a Credential Returning It On Failure", func(ttt *testing.T) {
 				db := test.ServiceStorage(ttt)
 				require.NotEmpty(ttt, db)
 
@@ -1012,59 +1736,478 @@ func TestCredentialAPI(t *testing.T) {
 					Method:  didsdk.KeyMethod,
 					KeyType: crypto.Ed25519,
 				})
-				assert.NoError(ttt, err)
-				assert.NotEmpty(ttt, issuerDID)
-
-				issuerDIDTwo, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
-					Method:  didsdk.KeyMethod,
-					KeyType: crypto.Ed25519,
-				})
-				assert.NoError(ttt, err)
-				assert.NotEmpty(ttt, issuerDIDTwo)
-
-				w := httptest.NewRecorder()
+				require.NoError(ttt, err)
 
-				// good request One
-				createCredRequest := router.CreateCredentialRequest{
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
 					Issuer:               issuerDID.DID.ID,
 					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
 					Subject:              "did:abc:456",
-					Data: map[string]any{
-						"firstName": "Jack",
-						"lastName":  "Dorsey",
-					},
-					Expiry: time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-				}
-				requestValue := newRequestValue(ttt, createCredRequest)
+					Data:                 map[string]any{"firstName": "Jack"},
+					Revocable:            true,
+				})
 				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
-				c := newRequestContext(w, req)
-				credRouter.CreateCredential(c)
-				assert.True(ttt, util.Is2xxResponse(w.Code))
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
 
-				var resp router.CreateCredentialResponse
-				err = json.NewDecoder(w.Body).Decode(&resp)
-				assert.NoError(ttt, err)
+				verifyCredential := func(request router.VerifyCredentialRequest) router.VerifyCredentialResponse {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var verifyResp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&verifyResp))
+					return verifyResp
+				}
 
-				assert.NotEmpty(ttt, resp.CredentialJWT)
-				assert.NoError(ttt, err)
-				assert.Empty(ttt, resp.Credential.CredentialStatus)
-				assert.Equal(ttt, resp.Credential.Issuer, issuerDID.DID.ID)
+				// a verified credential is not returned
+				verifyResp := verifyCredential(router.VerifyCredentialRequest{CredentialJWT: createResp.CredentialJWT, ReturnCredentialOnFailure: true})
+				assert.True(ttt, verifyResp.Verified)
+				assert.Nil(ttt, verifyResp.UnverifiedCredential)
 
-				// good revocable request One
-				createRevocableCredRequestOne := router.CreateCredentialRequest{
-					Issuer:               issuerDID.DID.ID,
-					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
-					Subject:              "did:abc:456",
-					Data: map[string]any{
-						"firstName": "Jack",
-						"lastName":  "Dorsey",
-					},
-					Expiry:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-					Revocable: true,
-				}
+				requestValue = newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+				req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", createResp.ID), requestValue)
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": createResp.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
 
-				requestValue = newRequestValue(ttt, createRevocableCredRequestOne)
-				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				// a credential failing verification is only returned when asked for
+				verifyResp = verifyCredential(router.VerifyCredentialRequest{CredentialJWT: createResp.CredentialJWT})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Nil(ttt, verifyResp.UnverifiedCredential)
+
+				verifyResp = verifyCredential(router.VerifyCredentialRequest{CredentialJWT: createResp.CredentialJWT, ReturnCredentialOnFailure: true})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.Revoked, verifyResp.ReasonCode)
+				require.NotNil(ttt, verifyResp.UnverifiedCredential)
+				assert.Equal(ttt, createResp.Credential.ID, verifyResp.UnverifiedCredential.ID)
+				assert.Equal(ttt, "did:abc:456", verifyResp.UnverifiedCredential.CredentialSubject.GetID())
+
+				// a credential which can't be parsed is not returned
+				verifyResp = verifyCredential(router.VerifyCredentialRequest{CredentialJWT: keyaccess.JWTPtr("bad"), ReturnCredentialOnFailure: true})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Nil(ttt, verifyResp.UnverifiedCredential)
+			})
+
+			tt.Run("Test Verifying a Credential With Expectations", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(ttt, err)
+
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name: "email schema",
+					Schema: map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"type":    "object",
+						"properties": map[string]any{
+							"credentialSubject": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"email": map[string]any{
+										"type": "string",
+									},
+								},
+								"required": []any{"email"},
+							},
+						},
+					},
+				})
+				require.NoError(ttt, err)
+
+				createCredential := func(schemaID string) *keyaccess.JWT {
+					createCredRequest := router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						SchemaID:             schemaID,
+						Data:                 map[string]any{"email": "jack@example.com"},
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+					}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.CredentialJWT
+				}
+				verify := func(request router.VerifyCredentialRequest) router.VerifyCredentialResponse {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+
+					var resp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				credWithSchema := createCredential(createdSchema.ID)
+				credWithoutSchema := createCredential("")
+
+				// matching schema and types
+				verifyResp := verify(router.VerifyCredentialRequest{
+					CredentialJWT:    credWithSchema,
+					ExpectedSchemaID: createdSchema.ID,
+					ExpectedTypes:    []string{"VerifiableCredential"},
+				})
+				assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+				assert.Empty(ttt, verifyResp.ReasonCode)
+
+				// a different schema
+				verifyResp = verify(router.VerifyCredentialRequest{CredentialJWT: credWithSchema, ExpectedSchemaID: "other-schema"})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.SchemaMismatch, verifyResp.ReasonCode)
+
+				// no credentialSchema at all
+				verifyResp = verify(router.VerifyCredentialRequest{CredentialJWT: credWithoutSchema, ExpectedSchemaID: createdSchema.ID})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.SchemaMismatch, verifyResp.ReasonCode)
+				assert.Contains(ttt, verifyResp.Reason, "has no credentialSchema")
+
+				// wrong type
+				verifyResp = verify(router.VerifyCredentialRequest{CredentialJWT: credWithSchema, ExpectedTypes: []string{"EmailCredential"}})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.TypeMismatch, verifyResp.ReasonCode)
+				assert.Contains(ttt, verifyResp.Reason, "EmailCredential")
+
+				// expectations are only checked on valid credentials
+				verifyResp = verify(router.VerifyCredentialRequest{CredentialJWT: keyaccess.JWTPtr("bad"), ExpectedTypes: []string{"EmailCredential"}})
+				assert.False(ttt, verifyResp.Verified)
+				assert.Empty(ttt, verifyResp.ReasonCode)
+			})
+
+			tt.Run("Test Verify Credential With Pinned Issuer Key", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				otherDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				verificationMethodID := issuerDID.DID.VerificationMethod[0].ID
+
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: verificationMethodID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+
+				publicKey := func(id string) jwx.PublicKeyJWK {
+					gotKey, err := keyStoreService.GetKeyDetails(context.Background(), keystore.GetKeyDetailsRequest{ID: id})
+					require.NoError(ttt, err)
+					return gotKey.PublicKeyJWK
+				}
+				verify := func(pinned jwx.PublicKeyJWK) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(ttt, router.VerifyCredentialRequest{
+						CredentialJWT:   createResp.CredentialJWT,
+						PinnedIssuerKey: &pinned,
+					}))
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					return w
+				}
+				verified := func(pinned jwx.PublicKeyJWK) router.VerifyCredentialResponse {
+					w := verify(pinned)
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var resp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				// the issuer's key, by its full key ID or its fragment
+				issuerKey := publicKey(verificationMethodID)
+				issuerKey.KID = verificationMethodID
+				verifyResp := verified(issuerKey)
+				assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+				issuerKey.KID = verificationMethodID[strings.Index(verificationMethodID, "#"):]
+				verifyResp = verified(issuerKey)
+				assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+
+				// another key pinned under the key ID of the issuer does not validate the signature
+				substitutedKey := publicKey(otherDID.DID.VerificationMethod[0].ID)
+				substitutedKey.KID = verificationMethodID
+				verifyResp = verified(substitutedKey)
+				assert.False(ttt, verifyResp.Verified)
+				assert.Empty(ttt, verifyResp.ReasonCode)
+				assert.Contains(ttt, verifyResp.Reason, "pinned key")
+
+				// a credential signed with another key ID than the pinned one
+				substitutedKey.KID = otherDID.DID.VerificationMethod[0].ID
+				verifyResp = verified(substitutedKey)
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.KeyMismatch, verifyResp.ReasonCode)
+
+				// pinned keys must have a key ID
+				issuerKey.KID = ""
+				assert.Equal(ttt, http.StatusBadRequest, verify(issuerKey).Code)
+			})
+
+			tt.Run("Test Verifying a Credential With Multiple Statuses", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				verificationMethodID := issuerDID.DID.VerificationMethod[0].ID
+
+				createCredential := func(revocable, suspendable bool) router.CreateCredentialResponse {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: verificationMethodID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            revocable,
+						Suspendable:          suspendable,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+				updateStatus := func(id string, request router.UpdateCredentialStatusRequest) {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+				}
+				revocable := createCredential(true, false)
+				suspendable := createCredential(false, true)
+
+				// a credential with both status entries, signed by the issuer
+				multiStatusCred := *revocable.Credential
+				multiStatusCred.CredentialStatus = []any{revocable.Credential.CredentialStatus, suspendable.Credential.CredentialStatus}
+				gotKey, err := keyStoreService.GetKey(context.Background(), keystore.GetKeyRequest{ID: verificationMethodID})
+				require.NoError(ttt, err)
+				keyAccess, err := keyaccess.NewJWKKeyAccess(verificationMethodID, gotKey.ID, gotKey.Key)
+				require.NoError(ttt, err)
+				multiStatusJWT, err := keyAccess.SignVerifiableCredential(multiStatusCred)
+				require.NoError(ttt, err)
+
+				verify := func() router.VerifyCredentialResponse {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: multiStatusJWT}))
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+				setStatuses := func(resp router.VerifyCredentialResponse) map[statussdk.StatusPurpose]bool {
+					statuses := make(map[statussdk.StatusPurpose]bool)
+					for _, result := range resp.StatusResults {
+						statuses[result.StatusPurpose] = result.Set
+					}
+					return statuses
+				}
+
+				verifyResp := verify()
+				assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+				assert.Equal(ttt, map[statussdk.StatusPurpose]bool{statussdk.StatusRevocation: false, statussdk.StatusSuspension: false}, setStatuses(verifyResp))
+
+				updateStatus(suspendable.ID, router.UpdateCredentialStatusRequest{Suspended: true})
+				verifyResp = verify()
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.Suspended, verifyResp.ReasonCode)
+				assert.Equal(ttt, map[statussdk.StatusPurpose]bool{statussdk.StatusRevocation: false, statussdk.StatusSuspension: true}, setStatuses(verifyResp))
+
+				// revocation takes precedence when both are set
+				updateStatus(revocable.ID, router.UpdateCredentialStatusRequest{Revoked: true})
+				verifyResp = verify()
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.Revoked, verifyResp.ReasonCode)
+				assert.Equal(ttt, map[statussdk.StatusPurpose]bool{statussdk.StatusRevocation: true, statussdk.StatusSuspension: true}, setStatuses(verifyResp))
+
+				updateStatus(suspendable.ID, router.UpdateCredentialStatusRequest{Suspended: false})
+				verifyResp = verify()
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.Revoked, verifyResp.ReasonCode)
+			})
+
+			tt.Run("Test Verifying a Credential As Of a Past Time", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials, without the event log
+				withoutEventLog := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchUpdateStatusMaxItems: 10, EventLogEnabled: true}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createCredential := func(credRouter *router.CredentialRouter) router.CreateCredentialResponse {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+						Revocable:            true,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+				verifyAt := func(credJWT *keyaccess.JWT, at time.Time) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: credJWT, AtTime: &at})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					return w
+				}
+				verified := func(credJWT *keyaccess.JWT, at time.Time) router.VerifyCredentialResponse {
+					w := verifyAt(credJWT, at)
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var resp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				created := createCredential(credRouter)
+				beforeRevocation := time.Now()
+				requestValue := newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", requestValue)
+				w := httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				afterRevocation := time.Now()
+
+				// the credential was valid before it was revoked
+				verifyResp := verified(created.CredentialJWT, beforeRevocation)
+				assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+				require.Len(ttt, verifyResp.StatusResults, 1)
+				assert.False(ttt, verifyResp.StatusResults[0].Set)
+				assert.False(ttt, verifyResp.StatusUnknown)
+
+				verifyResp = verified(created.CredentialJWT, afterRevocation)
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, verification.Revoked, verifyResp.ReasonCode)
+				require.Len(ttt, verifyResp.StatusResults, 1)
+				assert.True(ttt, verifyResp.StatusResults[0].Set)
+
+				// nor was it valid before it was issued, and it cannot be verified as of a future time
+				verifyResp = verified(created.CredentialJWT, beforeRevocation.Add(-time.Hour))
+				assert.False(ttt, verifyResp.Verified)
+				assert.Equal(ttt, http.StatusBadRequest, verifyAt(created.CredentialJWT, time.Now().Add(time.Hour)).Code)
+
+				// the status of credentials created without the event log is unknown
+				untracked := createCredential(withoutEventLog)
+				verifyResp = verified(untracked.CredentialJWT, time.Now())
+				assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+				assert.True(ttt, verifyResp.StatusUnknown)
+				require.Len(ttt, verifyResp.StatusResults, 1)
+				assert.True(ttt, verifyResp.StatusResults[0].Unknown)
+			})
+
+			tt.Run("Test Create Revocable Credential", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				assert.NoError(ttt, err)
+				assert.NotEmpty(ttt, issuerDID)
+
+				issuerDIDTwo, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				assert.NoError(ttt, err)
+				assert.NotEmpty(ttt, issuerDIDTwo)
+
+				w := httptest.NewRecorder()
+
+				// good request One
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data: map[string]any{
+						"firstName": "Jack",
+						"lastName":  "Dorsey",
+					},
+					Expiry: time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+				}
+				requestValue := newRequestValue(ttt, createCredRequest)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				c := newRequestContext(w, req)
+				credRouter.CreateCredential(c)
+				assert.True(ttt, util.Is2xxResponse(w.Code))
+
+				var resp router.CreateCredentialResponse
+				err = json.NewDecoder(w.Body).Decode(&resp)
+				assert.NoError(ttt, err)
+
+				assert.NotEmpty(ttt, resp.CredentialJWT)
+				assert.NoError(ttt, err)
+				assert.Empty(ttt, resp.Credential.CredentialStatus)
+				assert.Equal(ttt, resp.Credential.Issuer, issuerDID.DID.ID)
+
+				// good revocable request One
+				createRevocableCredRequestOne := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data: map[string]any{
+						"firstName": "Jack",
+						"lastName":  "Dorsey",
+					},
+					Expiry:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+					Revocable: true,
+				}
+
+				requestValue = newRequestValue(ttt, createRevocableCredRequestOne)
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
 				c = newRequestContext(w, req)
 				credRouter.CreateCredential(c)
 				assert.True(ttt, util.Is2xxResponse(w.Code))
@@ -1255,76 +2398,5049 @@ func TestCredentialAPI(t *testing.T) {
 
 			})
 
-			tt.Run("Test Get Status List Credential", func(ttt *testing.T) {
+			tt.Run("Test Credential Status Reasons", func(ttt *testing.T) {
 				db := test.ServiceStorage(ttt)
 				require.NotEmpty(ttt, db)
 
 				keyStoreService, _ := testKeyStoreService(ttt, db)
 				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
 				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
-				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
 
-				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
-					Method:  didsdk.KeyMethod,
-					KeyType: crypto.Ed25519,
-				})
-				assert.NoError(ttt, err)
-				assert.NotEmpty(ttt, issuerDID)
+				serviceConfig := config.CredentialServiceConfig{BatchUpdateStatusMaxItems: 10, StatusReasonCodes: []string{"KEY_COMPROMISE", "SUPERSEDED"}}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
 
-				w := httptest.NewRecorder()
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
 
-				// good request number one
-				createCredRequest := router.CreateCredentialRequest{
-					Issuer:               issuerDID.DID.ID,
-					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
-					Subject:              "did:abc:456",
-					Data: map[string]any{
-						"firstName": "Jack",
-						"lastName":  "Dorsey",
-					},
-					Expiry:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-					Revocable: true,
+				createCredential := func() string {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            true,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.ID
+				}
+				updateStatus := func(id string, request router.UpdateCredentialStatusRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+				getStatus := func(id string) router.GetCredentialStatusResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status", nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.GetCredentialStatusResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
 				}
 
-				requestValue := newRequestValue(ttt, createCredRequest)
-				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
-				c := newRequestContext(w, req)
-				credRouter.CreateCredential(c)
-				assert.True(ttt, util.Is2xxResponse(w.Code))
-
-				var resp router.CreateCredentialResponse
-				err = json.NewDecoder(w.Body).Decode(&resp)
-				assert.NoError(ttt, err)
-
-				assert.NotEmpty(ttt, resp.CredentialJWT)
-				assert.NotEmpty(ttt, resp.Credential.CredentialStatus)
-				assert.Equal(ttt, resp.Credential.Issuer, issuerDID.DID.ID)
-
-				credStatusMap, ok := resp.Credential.CredentialStatus.(map[string]any)
-				assert.True(ttt, ok)
-
-				assert.NotEmpty(ttt, credStatusMap["statusListIndex"])
+				// revoking with a reason
+				withReason := createCredential()
+				w := updateStatus(withReason, router.UpdateCredentialStatusRequest{Revoked: true, Reason: "the subject's key was compromised", ReasonCode: "KEY_COMPROMISE"})
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var updateResp router.UpdateCredentialStatusResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&updateResp))
+				assert.True(ttt, updateResp.Revoked)
+				assert.Equal(ttt, "the subject's key was compromised", updateResp.Reason)
+				assert.Equal(ttt, "KEY_COMPROMISE", updateResp.ReasonCode)
+
+				statusResp := getStatus(withReason)
+				assert.True(ttt, statusResp.Revoked)
+				assert.Equal(ttt, "the subject's key was compromised", statusResp.Reason)
+				assert.Equal(ttt, "KEY_COMPROMISE", statusResp.ReasonCode)
+
+				// revoking without a reason
+				withoutReason := createCredential()
+				w = updateStatus(withoutReason, router.UpdateCredentialStatusRequest{Revoked: true})
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				statusResp = getStatus(withoutReason)
+				assert.True(ttt, statusResp.Revoked)
+				assert.Empty(ttt, statusResp.Reason)
+				assert.Empty(ttt, statusResp.ReasonCode)
+
+				// reason codes outside the configured ones are rejected
+				unknownCode := createCredential()
+				w = updateStatus(unknownCode, router.UpdateCredentialStatusRequest{Revoked: true, ReasonCode: "BORED"})
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.False(ttt, getStatus(unknownCode).Revoked)
+
+				// reasons are given per credential in a batch
+				batchRequest := router.BatchUpdateCredentialStatusRequest{Requests: []router.SingleUpdateCredentialStatusRequest{
+					{ID: unknownCode, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true, Reason: "reissued", ReasonCode: "SUPERSEDED"}},
+					{ID: withoutReason, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: false, Reason: "revoked by mistake"}},
+				}}
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status/batch", newRequestValue(ttt, batchRequest))
+				w = httptest.NewRecorder()
+				credRouter.BatchUpdateCredentialStatus(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var batchResp router.BatchUpdateCredentialStatusResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&batchResp))
+				require.Len(ttt, batchResp.CredentialStatuses, 2)
+				assert.Equal(ttt, "SUPERSEDED", batchResp.CredentialStatuses[0].ReasonCode)
+
+				statusResp = getStatus(unknownCode)
+				assert.True(ttt, statusResp.Revoked)
+				assert.Equal(ttt, "reissued", statusResp.Reason)
+				assert.Equal(ttt, "SUPERSEDED", statusResp.ReasonCode)
+				statusResp = getStatus(withoutReason)
+				assert.False(ttt, statusResp.Revoked)
+				assert.Equal(ttt, "revoked by mistake", statusResp.Reason)
+
+				batchRequest = router.BatchUpdateCredentialStatusRequest{Requests: []router.SingleUpdateCredentialStatusRequest{
+					{ID: withoutReason, UpdateCredentialStatusRequest: router.UpdateCredentialStatusRequest{Revoked: true, ReasonCode: "BORED"}},
+				}}
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status/batch", newRequestValue(ttt, batchRequest))
+				w = httptest.NewRecorder()
+				credRouter.BatchUpdateCredentialStatus(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
 
-				credStatusListID := (credStatusMap["statusListCredential"]).(string)
+			tt.Run("Test Credential Status Actions", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
 
-				assert.NotEmpty(ttt, credStatusListID)
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
 
-				i := strings.LastIndex(credStatusListID, "/")
-				uuidStringUUID := credStatusListID[i+1:]
+				serviceConfig := config.CredentialServiceConfig{BatchUpdateStatusMaxItems: 10, RequireStatusReason: true}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
 
-				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://localhost:8080/%s", credStatusListID), nil)
-				c = newRequestContextWithParams(w, req, map[string]string{"id": uuidStringUUID})
-				credRouter.GetCredentialStatusList(c)
-				assert.True(ttt, util.Is2xxResponse(w.Code))
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
 
-				var credListResp router.GetCredentialStatusListResponse
+				createCredential := func(revocable, suspendable bool) string {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            revocable,
+						Suspendable:          suspendable,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.ID
+				}
+				applyAction := func(handler gin.HandlerFunc, id string, request *router.CredentialStatusActionRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/"+id, nil)
+					if request != nil {
+						req = httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/"+id, newRequestValue(ttt, *request))
+					}
+					w := httptest.NewRecorder()
+					handler(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+				getStatus := func(id string) router.GetCredentialStatusResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status", nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.GetCredentialStatusResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+				withReason := &router.CredentialStatusActionRequest{Reason: "the subject's key was compromised"}
+
+				// revoking
+				revocable := createCredential(true, false)
+				w := applyAction(credRouter.RevokeCredential, revocable, nil)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.False(ttt, getStatus(revocable).Revoked)
+
+				w = applyAction(credRouter.RevokeCredential, revocable, withReason)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var updateResp router.UpdateCredentialStatusResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&updateResp))
+				assert.True(ttt, updateResp.Revoked)
+				assert.Equal(ttt, withReason.Reason, updateResp.Reason)
+				assert.True(ttt, getStatus(revocable).Revoked)
+
+				// a revocable credential can't be suspended, nor unsuspended
+				w = applyAction(credRouter.SuspendCredential, revocable, withReason)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+				w = applyAction(credRouter.UnsuspendCredential, revocable, nil)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+				assert.True(ttt, getStatus(revocable).Revoked)
+
+				// suspending requires a reason, while unsuspending does not
+				suspendable := createCredential(false, true)
+				w = applyAction(credRouter.SuspendCredential, suspendable, nil)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				w = applyAction(credRouter.SuspendCredential, suspendable, withReason)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				assert.True(ttt, getStatus(suspendable).Suspended)
+
+				w = applyAction(credRouter.RevokeCredential, suspendable, withReason)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+
+				w = applyAction(credRouter.UnsuspendCredential, suspendable, nil)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				assert.False(ttt, getStatus(suspendable).Suspended)
+
+				// a credential issued without status is irrevocable
+				irrevocable := createCredential(false, false)
+				w = applyAction(credRouter.RevokeCredential, irrevocable, withReason)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+				w = applyAction(credRouter.SuspendCredential, irrevocable, withReason)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+
+				// the generic status update requires a reason as well
+				another := createCredential(true, false)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": another}))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.False(ttt, getStatus(another).Revoked)
+			})
+
+			tt.Run("Test Credential Event Log", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				serviceConfig := config.CredentialServiceConfig{BatchCreateMaxItems: 10, BatchUpdateStatusMaxItems: 10, EventLogEnabled: true}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				listEvents := func(query string) router.ListCredentialEventsResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/events"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListCredentialEvents(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.ListCredentialEventsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+				assert.Empty(ttt, listEvents("").Events)
+
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					Revocable:            true,
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var created router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&created))
+
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				// a rejected update is not recorded
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true, Suspended: true}))
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.False(ttt, util.Is2xxResponse(w.Code))
+
+				req = httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/credentials/"+created.ID, nil)
+				w = httptest.NewRecorder()
+				credRouter.DeleteCredential(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				all := listEvents("")
+				require.Len(ttt, all.Events, 3)
+				assert.Equal(ttt, webhook.Create, all.Events[0].Verb)
+				assert.Equal(ttt, webhook.StatusUpdate, all.Events[1].Verb)
+				assert.Equal(ttt, webhook.Delete, all.Events[2].Verb)
+				for _, event := range all.Events {
+					assert.Equal(ttt, created.ID, event.CredentialID)
+				}
+				assert.Contains(ttt, string(all.Events[0].Data), created.Credential.ID)
+				assert.Contains(ttt, string(all.Events[1].Data), `"revoked":true`)
+				assert.Equal(ttt, all.Events[2].ID, all.Cursor)
+				assert.Empty(ttt, all.NextPageToken)
+
+				// consumers replay from their cursor
+				afterCreate := listEvents("?cursor=" + all.Events[0].ID)
+				require.Len(ttt, afterCreate.Events, 2)
+				assert.Equal(ttt, all.Events[1:], afterCreate.Events)
+
+				caughtUp := listEvents("?cursor=" + all.Cursor)
+				assert.Empty(ttt, caughtUp.Events)
+				assert.Equal(ttt, all.Cursor, caughtUp.Cursor)
+
+				// pages continue from the last event of the previous page
+				firstPage := listEvents("?pageSize=2")
+				require.Len(ttt, firstPage.Events, 2)
+				require.NotEmpty(ttt, firstPage.NextPageToken)
+				secondPage := listEvents("?pageSize=2&pageToken=" + firstPage.NextPageToken)
+				require.Len(ttt, secondPage.Events, 1)
+				assert.Equal(ttt, all.Events[2].ID, secondPage.Events[0].ID)
+				assert.Empty(ttt, secondPage.NextPageToken)
+
+				// cursors are the sequence numbers of events
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/events?cursor=not-an-event", nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentialEvents(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), credential.ErrInvalidEventCursor.Error())
+
+				// the events appended in a single transaction are numbered in order
+				batchRequest := credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+				}
+				batch, err := credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+					Requests: []credential.CreateCredentialRequest{batchRequest, batchRequest},
+				})
+				require.NoError(ttt, err)
+				afterBatch := listEvents("?cursor=" + all.Cursor)
+				require.Len(ttt, afterBatch.Events, 2)
+				for i, event := range afterBatch.Events {
+					assert.Equal(ttt, webhook.Create, event.Verb)
+					assert.Equal(ttt, batch.Credentials[i].ID, event.CredentialID)
+				}
+				assert.Equal(ttt, "00000000000000000004", afterBatch.Events[0].ID)
+				assert.Equal(ttt, "00000000000000000005", afterBatch.Events[1].ID)
+			})
+
+			tt.Run("Test Credential Lifecycle State", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				serviceConfig := config.CredentialServiceConfig{BatchUpdateStatusMaxItems: 10, EventLogEnabled: true, ExpiryClockSkew: "-5m"}
+				_, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				assert.ErrorContains(ttt, err, "invalid expiry clock skew")
+
+				serviceConfig.ExpiryClockSkew = "5m"
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				mockClock := clock.NewMock()
+				mockClock.Set(time.Date(2023, 06, 23, 0, 0, 0, 0, time.UTC))
+				credService.Clock = mockClock
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createCredential := func(expiry string, revocable, suspendable bool) string {
+					created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						Data:                               map[string]any{"firstName": "Jack"},
+						Expiry:                             expiry,
+						Revocable:                          revocable,
+						Suspendable:                        suspendable,
+					})
+					require.NoError(ttt, err)
+					return created.ID
+				}
+				getState := func(id string) credmodel.LifecycleState {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+id+"/status", nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.GetCredentialStatusResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.LifecycleState
+				}
+				listStates := func(state string) map[string]credmodel.LifecycleState {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?subject=did:abc:456&state="+state, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListCredentials(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.ListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					states := make(map[string]credmodel.LifecycleState, len(resp.Credentials))
+					for _, cred := range resp.Credentials {
+						states[cred.ID] = cred.LifecycleState
+					}
+					return states
+				}
+
+				active := createCredential("2023-06-30T00:00:00Z", false, false)
+				expiring := createCredential("2023-06-24T00:00:00Z", false, false)
+				suspended := createCredential("2023-06-24T00:00:00Z", false, true)
+				revoked := createCredential("", true, false)
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: suspended, Suspended: true})
+				require.NoError(ttt, err)
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revoked, Revoked: true})
+				require.NoError(ttt, err)
+
+				assert.Equal(ttt, credmodel.LifecycleActive, getState(active))
+				assert.Equal(ttt, credmodel.LifecycleActive, getState(expiring))
+				assert.Equal(ttt, credmodel.LifecycleSuspended, getState(suspended))
+				assert.Equal(ttt, credmodel.LifecycleRevoked, getState(revoked))
+				gotCred, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: revoked})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, credmodel.LifecycleRevoked, gotCred.LifecycleState)
+
+				// credentials are still active within the skew after their expiration date
+				mockClock.Set(time.Date(2023, 06, 24, 0, 4, 0, 0, time.UTC))
+				assert.Equal(ttt, credmodel.LifecycleActive, getState(expiring))
+
+				// being suspended takes precedence over being expired
+				mockClock.Set(time.Date(2023, 06, 24, 0, 6, 0, 0, time.UTC))
+				assert.Equal(ttt, credmodel.LifecycleActive, getState(active))
+				assert.Equal(ttt, credmodel.LifecycleExpired, getState(expiring))
+				assert.Equal(ttt, credmodel.LifecycleSuspended, getState(suspended))
+
+				assert.Equal(ttt, map[string]credmodel.LifecycleState{active: credmodel.LifecycleActive}, listStates("ACTIVE"))
+				assert.Equal(ttt, map[string]credmodel.LifecycleState{expiring: credmodel.LifecycleExpired}, listStates("EXPIRED"))
+				assert.Equal(ttt, map[string]credmodel.LifecycleState{suspended: credmodel.LifecycleSuspended}, listStates("SUSPENDED"))
+				assert.Equal(ttt, map[string]credmodel.LifecycleState{revoked: credmodel.LifecycleRevoked}, listStates("REVOKED"))
+
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?state=DELETED", nil)
+				w := httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "invalid state<DELETED>")
+
+				// being revoked takes precedence over being suspended, for credentials stored with both
+				entries, err := db.ReadPrefix(context.Background(), "credential", suspended)
+				require.NoError(ttt, err)
+				require.Len(ttt, entries, 1)
+				for key, entry := range entries {
+					var stored map[string]any
+					require.NoError(ttt, json.Unmarshal(entry, &stored))
+					stored["revoked"] = true
+					storedBytes, err := json.Marshal(stored)
+					require.NoError(ttt, err)
+					require.NoError(ttt, db.Write(context.Background(), "credential", key, storedBytes))
+				}
+				assert.Equal(ttt, credmodel.LifecycleRevoked, getState(suspended))
+
+				// deleted credentials are reported as such from the event log
+				req = httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/credentials/"+expiring, nil)
+				w = httptest.NewRecorder()
+				credRouter.DeleteCredential(newRequestContextWithParams(w, req, map[string]string{"id": expiring}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				assert.Equal(ttt, credmodel.LifecycleDeleted, getState(expiring))
+			})
+
+			tt.Run("Test JWT Claims Profile", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				serviceConfig := config.CredentialServiceConfig{BatchUpdateStatusMaxItems: 10, JWTClaimsProfile: "jwt-vc-2.0"}
+				_, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				assert.ErrorContains(ttt, err, "invalid jwt claims profile")
+
+				serviceConfig.JWTClaimsProfile = keyaccess.JWTVCIssuanceProfile
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createCredential := func(profile string) (int, router.CreateCredentialResponse) {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+						Revocable:            true,
+						JWTClaimsProfile:     profile,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					var created router.CreateCredentialResponse
+					if util.Is2xxResponse(w.Code) {
+						require.NoError(ttt, json.NewDecoder(w.Body).Decode(&created))
+					}
+					return w.Code, created
+				}
+				payload := func(token *keyaccess.JWT) map[string]any {
+					payloadBytes, err := base64.RawURLEncoding.DecodeString(strings.Split(token.String(), ".")[1])
+					require.NoError(ttt, err)
+					var claims map[string]any
+					require.NoError(ttt, json.Unmarshal(payloadBytes, &claims))
+					return claims
+				}
+
+				// credentials are signed with the configured profile, unless the request overrides it
+				code, configured := createCredential("")
+				require.Equal(ttt, http.StatusCreated, code)
+				claims := payload(configured.CredentialJWT)
+				assert.NotContains(ttt, claims, "iat")
+				assert.Equal(ttt, configured.Credential.ID, claims["jti"])
+				assert.Equal(ttt, "did:abc:456", claims["sub"])
+				assert.Equal(ttt, configured.Credential.IssuanceDate, claims["vc"].(map[string]any)["issuanceDate"])
+
+				code, overridden := createCredential(keyaccess.VCJWT11Profile)
+				require.Equal(ttt, http.StatusCreated, code)
+				claims = payload(overridden.CredentialJWT)
+				assert.Contains(ttt, claims, "iat")
+				assert.NotContains(ttt, claims["vc"], "issuanceDate")
+
+				code, _ = createCredential("jwt-vc-2.0")
+				assert.Equal(ttt, http.StatusBadRequest, code)
+
+				// credentials of every profile verify, and so do their status lists
+				for _, created := range []router.CreateCredentialResponse{configured, overridden} {
+					verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+					require.NoError(ttt, err)
+					assert.True(ttt, verified.Verified, verified.Reason)
+				}
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: configured.ID, Revoked: true})
+				require.NoError(ttt, err)
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: configured.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.False(ttt, verified.Verified)
+				assert.Equal(ttt, verification.Revoked, verified.ReasonCode)
+			})
+
+			tt.Run("Test Data Integrity Proof Format", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				// the contexts of the credential define none of the terms of data such as firstName, which a proof would not
+				// cover, so the credential only has a subject
+				createCredential := func(proofFormat string) (int, router.CreateCredentialResponse) {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{},
+						Revocable:            true,
+						ProofFormat:          proofFormat,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					var created router.CreateCredentialResponse
+					if util.Is2xxResponse(w.Code) {
+						require.NoError(ttt, json.NewDecoder(w.Body).Decode(&created))
+					}
+					return w.Code, created
+				}
+
+				code, _ := createCredential("ldp_vc")
+				assert.Equal(ttt, http.StatusBadRequest, code)
+
+				// credentials are only signed as vc-jwt by default
+				code, jwtOnly := createCredential("")
+				require.Equal(ttt, http.StatusCreated, code)
+				assert.Nil(ttt, jwtOnly.Credential.Proof)
+				assert.NotNil(ttt, jwtOnly.CredentialJWT)
+
+				code, created := createCredential(credential.DataIntegrityProofFormat)
+				require.Equal(ttt, http.StatusCreated, code)
+				require.NotNil(ttt, created.Credential.Proof)
+				require.NotNil(ttt, created.CredentialJWT)
+				proof, ok := (*created.Credential.Proof).(map[string]any)
+				require.True(ttt, ok)
+				assert.Equal(ttt, string(keyaccess.Ed25519Signature2020), proof["type"])
+				assert.Equal(ttt, issuerDID.DID.VerificationMethod[0].ID, proof["verificationMethod"])
+				assert.Contains(ttt, created.Credential.Context, keyaccess.Ed25519Signature2020Context)
+
+				// both representations are returned when the credential is read and listed
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+created.ID, nil)
+				w := httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				var got router.GetCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&got))
+				assert.NotNil(ttt, got.Credential.Proof)
+				assert.Equal(ttt, created.CredentialJWT, got.CredentialJWT)
+
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?issuer="+issuerDID.DID.ID, nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				var listed router.ListCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listed))
+				require.Len(ttt, listed.Credentials, 2)
+				proofs := 0
+				for _, listedCred := range listed.Credentials {
+					if listedCred.Credential.Proof != nil {
+						proofs++
+					}
+				}
+				assert.Equal(ttt, 1, proofs)
+
+				// both representations verify
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: got.Credential})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified, verified.Reason)
+				verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: got.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified, verified.Reason)
+
+				// credentials with terms which their contexts do not define are not signed with data integrity proofs
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					ProofFormat:          credential.DataIntegrityProofFormat,
+				})
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				w = httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), keyaccess.ErrUndefinedTerm.Error())
+			})
+
+			tt.Run("Test List Status List Credentials", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				// the index of each credential within the status list, by credential ID
+				indexes := make(map[string]string)
+				var statusListID string
+				for i := 0; i < 3; i++ {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            true,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var created router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&created))
+
+					credentialStatus := created.Credential.CredentialStatus.(map[string]any)
+					statusListURI := credentialStatus["statusListCredential"].(string)
+					statusListID = statusListURI[strings.LastIndex(statusListURI, "/")+1:]
+					indexes[created.ID] = credentialStatus["statusListIndex"].(string)
+				}
+
+				credentialIDs := make([]string, 0, len(indexes))
+				for id := range indexes {
+					credentialIDs = append(credentialIDs, id)
+				}
+				revokedID := credentialIDs[0]
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w := httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": revokedID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				listCredentials := func(query string) router.ListStatusListCredentialsResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status/"+statusListID+"/credentials"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListStatusListCredentials(newRequestContextWithParams(w, req, map[string]string{"id": statusListID}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.ListStatusListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				all := listCredentials("")
+				require.Len(ttt, all.Credentials, 3)
+				assert.Empty(ttt, all.NextPageToken)
+				for _, listed := range all.Credentials {
+					assert.Equal(ttt, indexes[listed.CredentialID], listed.StatusListIndex)
+					assert.Equal(ttt, statussdk.StatusRevocation, listed.StatusPurpose)
+					assert.Equal(ttt, listed.CredentialID == revokedID, listed.Revoked)
+					assert.False(ttt, listed.Suspended)
+				}
+
+				firstPage := listCredentials("?pageSize=2")
+				require.Len(ttt, firstPage.Credentials, 2)
+				require.NotEmpty(ttt, firstPage.NextPageToken)
+				secondPage := listCredentials("?pageSize=2&pageToken=" + firstPage.NextPageToken)
+				require.Len(ttt, secondPage.Credentials, 1)
+				assert.Equal(ttt, all.Credentials, append(firstPage.Credentials, secondPage.Credentials...))
+
+				// deleted credentials no longer reference the status list
+				req = httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/credentials/"+revokedID, nil)
+				w = httptest.NewRecorder()
+				credRouter.DeleteCredential(newRequestContextWithParams(w, req, map[string]string{"id": revokedID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				remaining := listCredentials("")
+				require.Len(ttt, remaining.Credentials, 2)
+				for _, listed := range remaining.Credentials {
+					assert.NotEqual(ttt, revokedID, listed.CredentialID)
+					assert.False(ttt, listed.Revoked)
+				}
+
+				// an unknown status list is not listed
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status/bad/credentials", nil)
+				w = httptest.NewRecorder()
+				credRouter.ListStatusListCredentials(newRequestContextWithParams(w, req, map[string]string{"id": "bad"}))
+				assert.Contains(ttt, w.Body.String(), "could not list credentials of status list")
+			})
+
+			tt.Run("Test Verify Credential By ID", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createCredential := func(expiry string) string {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               expiry,
+						Revocable:            true,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.ID
+				}
+				verifyByID := func(id string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/verification", id), nil)
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredentialByID(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+				decode := func(w *httptest.ResponseRecorder) router.VerifyCredentialByIDResponse {
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.VerifyCredentialByIDResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				valid := createCredential(time.Now().Add(24 * time.Hour).Format(time.RFC3339))
+				resp := decode(verifyByID(valid))
+				assert.True(ttt, resp.Verified, resp.Reason)
+				assert.False(ttt, resp.Revoked)
+				assert.False(ttt, resp.Suspended)
+
+				expired := createCredential(time.Now().Add(-time.Hour).Format(time.RFC3339))
+				resp = decode(verifyByID(expired))
+				assert.False(ttt, resp.Verified)
+				assert.NotEmpty(ttt, resp.Reason)
+				assert.False(ttt, resp.Revoked)
+
+				revoked := createCredential(time.Now().Add(24 * time.Hour).Format(time.RFC3339))
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w := httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": revoked}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				resp = decode(verifyByID(revoked))
+				assert.False(ttt, resp.Verified)
+				assert.Equal(ttt, verification.Revoked, resp.ReasonCode)
+				assert.True(ttt, resp.Revoked)
+
+				w = verifyByID("not-a-credential")
+				assert.Equal(ttt, http.StatusNotFound, w.Code)
+			})
+
+			tt.Run("Test Trusted Schema Authorities", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				newDID := func() *did.CreateDIDResponse {
+					created, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+					require.NoError(ttt, err)
+					return created
+				}
+				authorityDID, otherDID, issuerDID := newDID(), newDID(), newDID()
+
+				serviceConfig := config.CredentialServiceConfig{TrustedSchemaAuthorities: []string{authorityDID.DID.ID}}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				createCredential := func(schemaIssuer *did.CreateDIDResponse) *credential.CreateCredentialResponse {
+					schemaRequest := schema.CreateSchemaRequest{
+						Name: "name schema",
+						Schema: map[string]any{
+							"$schema": "https://json-schema.org/draft-07/schema",
+							"type":    "object",
+							"properties": map[string]any{
+								"credentialSubject": map[string]any{
+									"type":       "object",
+									"properties": map[string]any{"firstName": map[string]any{"type": "string"}},
+								},
+							},
+						},
+					}
+					if schemaIssuer != nil {
+						schemaRequest.Issuer = schemaIssuer.DID.ID
+						schemaRequest.FullyQualifiedVerificationMethodID = schemaIssuer.DID.VerificationMethod[0].ID
+					}
+					createdSchema, err := schemaService.CreateSchema(context.Background(), schemaRequest)
+					require.NoError(ttt, err)
+
+					created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           createdSchema.ID,
+						Data:                               map[string]any{"firstName": "Jack"},
+					})
+					require.NoError(ttt, err)
+					return created
+				}
+				verify := func(created *credential.CreateCredentialResponse, requireTrustedSchema bool) router.VerifyCredentialResponse {
+					requestValue := newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT, RequireTrustedSchema: requireTrustedSchema})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				trusted := verify(createCredential(authorityDID), true)
+				assert.True(ttt, trusted.Verified, trusted.Reason)
+				require.NotNil(ttt, trusted.SchemaTrust)
+				assert.True(ttt, trusted.SchemaTrust.Trusted)
+				assert.Equal(ttt, authorityDID.DID.ID, trusted.SchemaTrust.Issuer)
+
+				untrustedCred := createCredential(otherDID)
+				untrusted := verify(untrustedCred, true)
+				assert.False(ttt, untrusted.Verified)
+				assert.Equal(ttt, verification.UntrustedSchema, untrusted.ReasonCode)
+				require.NotNil(ttt, untrusted.SchemaTrust)
+				assert.False(ttt, untrusted.SchemaTrust.Trusted)
+				assert.Equal(ttt, otherDID.DID.ID, untrusted.SchemaTrust.Issuer)
+				assert.Contains(ttt, untrusted.SchemaTrust.Reason, "not a trusted schema authority")
+
+				// a plain JSON schema is not issued by any authority
+				unsigned := verify(createCredential(nil), true)
+				assert.False(ttt, unsigned.Verified)
+				assert.Equal(ttt, verification.UntrustedSchema, unsigned.ReasonCode)
+				assert.Empty(ttt, unsigned.SchemaTrust.Issuer)
+
+				// the schema is only checked when required
+				unchecked := verify(untrustedCred, false)
+				assert.True(ttt, unchecked.Verified, unchecked.Reason)
+				assert.Nil(ttt, unchecked.SchemaTrust)
+			})
+
+			tt.Run("Test Revoked Schema Credential", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createSchema := func(signed bool) *schema.CreateSchemaResponse {
+					schemaRequest := schema.CreateSchemaRequest{
+						Name: "name schema",
+						Schema: map[string]any{
+							"$schema": "https://json-schema.org/draft-07/schema",
+							"type":    "object",
+							"properties": map[string]any{
+								"credentialSubject": map[string]any{
+									"type":       "object",
+									"properties": map[string]any{"firstName": map[string]any{"type": "string"}},
+								},
+							},
+						},
+					}
+					if signed {
+						schemaRequest.Issuer = issuerDID.DID.ID
+						schemaRequest.FullyQualifiedVerificationMethodID = issuerDID.DID.VerificationMethod[0].ID
+						schemaRequest.Revocable = true
+					}
+					createdSchema, err := schemaService.CreateSchema(context.Background(), schemaRequest)
+					require.NoError(ttt, err)
+					return createdSchema
+				}
+				createCredential := func(schemaID string) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						SchemaID:             schemaID,
+						Data:                 map[string]any{"firstName": "Jack"},
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				// only credential schemas can be revocable
+				_, err = schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "plain", Schema: map[string]any{"type": "object"}, Revocable: true})
+				assert.ErrorContains(ttt, err, "only credential schemas can be revocable")
+
+				signedSchema := createSchema(true)
+				assert.Equal(ttt, schemalib.JSONSchemaCredentialType, signedSchema.Type)
+				schemaCred, err := credmodel.ParseVerifiableCredentialFromJWT(signedSchema.CredentialSchema.String())
+				require.NoError(ttt, err)
+				require.NotEmpty(ttt, schemaCred.CredentialStatus)
+
+				w := createCredential(signedSchema.ID)
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				// the schema credential is revoked like other credentials, by the ID of its schema
+				requestValue := newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", requestValue)
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": signedSchema.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				w = createCredential(signedSchema.ID)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), fmt.Sprintf("schema credential<%s> is revoked in status list", schemaCred.ID))
+
+				// a plain JSON schema is unaffected
+				plainSchema := createSchema(false)
+				w = createCredential(plainSchema.ID)
+				assert.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				// issuance against the revoked schema can be allowed in emergencies
+				serviceConfig := config.CredentialServiceConfig{AllowRevokedSchemaCredentials: true}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				_, err = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					SchemaID:                           signedSchema.ID,
+					Data:                               map[string]any{"firstName": "Jack"},
+				})
+				assert.NoError(ttt, err)
+			})
+
+			tt.Run("Test Verification Receipt", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				newDID := func() *did.CreateDIDResponse {
+					created, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+					require.NoError(ttt, err)
+					return created
+				}
+				serviceDID, issuerDID := newDID(), newDID()
+				signer := serviceDID.DID.VerificationMethod[0].ID
+
+				serviceConfig := config.CredentialServiceConfig{VerificationReceiptSigner: signer}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+
+				verify := func(request router.VerifyCredentialRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					return w
+				}
+				receiptClaims := func(w *httptest.ResponseRecorder) map[string]any {
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var resp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					require.NotNil(ttt, resp.VerificationReceipt)
+					require.NoError(ttt, didint.VerifyTokenFromDID(context.Background(), didService.GetResolver(), serviceDID.DID.ID, signer, *resp.VerificationReceipt))
+					token, err := jwt.ParseInsecure([]byte(resp.VerificationReceipt.String()))
+					require.NoError(ttt, err)
+					assert.Equal(ttt, serviceDID.DID.ID, token.Issuer())
+					assert.NotEmpty(ttt, token.JwtID())
+					assert.WithinDuration(ttt, time.Now(), token.IssuedAt(), time.Minute)
+					claims, err := token.AsMap(context.Background())
+					require.NoError(ttt, err)
+					return claims
+				}
+
+				claims := receiptClaims(verify(router.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT, ReturnReceipt: true}))
+				assert.Equal(ttt, created.Credential.ID, claims["credentialId"])
+				assert.Equal(ttt, verification.HashCredential(created.CredentialJWT.String()), claims["credentialHash"])
+				assert.Equal(ttt, true, claims["verified"])
+				assert.NotContains(ttt, claims, "reasonCode")
+				assert.Equal(ttt, []any{"signature", "expiry", "dataModel", "schema", "status", "expectations"}, claims["checks"])
+
+				// a failed verification is attested to as well
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w := httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				claims = receiptClaims(verify(router.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT, ReturnReceipt: true}))
+				assert.Equal(ttt, false, claims["verified"])
+				assert.Equal(ttt, verification.Revoked, claims["reasonCode"])
+				assert.Equal(ttt, []any{"signature", "expiry", "dataModel", "schema", "status"}, claims["checks"])
+
+				// receipts are only returned when requested
+				w = verify(router.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				assert.NotContains(ttt, w.Body.String(), "verificationReceipt")
+
+				// and cannot be requested without a signer
+				unconfiguredRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT, ReturnReceipt: true}))
+				w = httptest.NewRecorder()
+				unconfiguredRouter.VerifyCredential(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "verification receipts are not enabled")
+
+				_, err = credential.NewCredentialService(config.CredentialServiceConfig{VerificationReceiptSigner: serviceDID.DID.ID}, db, keyStoreService, didService.GetResolver(), schemaService)
+				assert.ErrorContains(ttt, err, "not a fully qualified verification method")
+			})
+
+			tt.Run("Test Analytics Events", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				sink := new(recordingAnalyticsSink)
+				sampler, err := analytics.NewSampler(1, 1)
+				require.NoError(ttt, err)
+				emitter, err := analytics.NewEmitter(sink, "test salt", sampler)
+				require.NoError(ttt, err)
+				credService.SetAnalytics(emitter)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"email": "jack@example.com"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+				_, err = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:  issuerDID.DID.ID,
+					Subject: "did:abc:456",
+					Data:    map[string]any{"email": "jack@example.com"},
+				})
+				require.Error(ttt, err)
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+				require.NoError(ttt, err)
+				require.True(ttt, verified.Verified)
+
+				events := sink.wait(ttt, 3)
+				outcomes := make(map[analytics.EventType][]string)
+				for _, event := range events {
+					outcomes[event.Type] = append(outcomes[event.Type], event.Outcome)
+					assert.Equal(ttt, emitter.Hash(issuerDID.DID.ID), event.IssuerHash)
+					assert.NotEmpty(ttt, event.LatencyBucket)
+				}
+				assert.ElementsMatch(ttt, []string{analytics.Succeeded, analytics.Failed}, outcomes[analytics.CredentialIssuance])
+				assert.Equal(ttt, []string{analytics.Verified}, outcomes[analytics.CredentialVerification])
+
+				// events hold neither the claims nor the subject of credentials, nor the issuer in the clear
+				eventsBytes, err := json.Marshal(events)
+				require.NoError(ttt, err)
+				for _, value := range []string{"jack@example.com", "did:abc:456", issuerDID.DID.ID, created.ID} {
+					assert.NotContains(ttt, string(eventsBytes), value)
+				}
+			})
+
+			tt.Run("Test Stream Credentials", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				batchCreate := func(n int) string {
+					issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+					require.NoError(ttt, err)
+					requests := make([]credential.CreateCredentialRequest, 0, n)
+					for i := 0; i < n; i++ {
+						requests = append(requests, credential.CreateCredentialRequest{
+							Issuer:                             issuerDID.DID.ID,
+							FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+							Subject:                            fmt.Sprintf("did:abc:%d", i),
+							Data:                               map[string]any{"firstName": "Jack"},
+						})
+					}
+					_, err = credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{Requests: requests})
+					require.NoError(ttt, err)
+					return issuerDID.DID.ID
+				}
+				issuer := batchCreate(250)
+				_ = batchCreate(5)
+
+				stream := func(limits pagination.StreamLimits) []string {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?issuer="+url.QueryEscape(issuer), nil)
+					req.Header.Set("Accept", pagination.NDJSONContentType)
+					w := httptest.NewRecorder()
+					c := newRequestContext(w, req)
+					pagination.SetStreamLimits(c, limits)
+					credRouter.ListCredentials(c)
+					require.Equal(ttt, http.StatusOK, w.Code)
+					assert.Equal(ttt, pagination.NDJSONContentType, w.Header().Get("Content-Type"))
+					return strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+				}
+
+				// every credential of the issuer is streamed, page by page
+				lines := stream(pagination.StreamLimits{PageSize: 40})
+				require.Len(ttt, lines, 250)
+				ids := make(map[string]bool)
+				for _, line := range lines {
+					var container credmodel.Container
+					require.NoError(ttt, json.Unmarshal([]byte(line), &container))
+					assert.Equal(ttt, issuer, container.Credential.IssuerID())
+					ids[container.ID] = true
+				}
+				assert.Len(ttt, ids, 250)
+
+				// streams stop at the cap, with a trailer telling so
+				lines = stream(pagination.StreamLimits{PageSize: 40, MaxItems: 100})
+				require.Len(ttt, lines, 101)
+				var trailer map[string]pagination.StreamTrailer
+				require.NoError(ttt, json.Unmarshal([]byte(lines[100]), &trailer))
+				assert.Equal(ttt, pagination.StreamTrailer{Truncated: true, Streamed: 100}, trailer["trailer"])
+			})
+
+			tt.Run("Test Status List Hosting Check", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				serviceConfig := config.CredentialServiceConfig{StatusListHostingCheck: true}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				// a CDN in front of the status endpoint, which can be made to serve a stale copy, or nothing
+				var stale atomic.Value
+				var unreachable atomic.Bool
+				var lastServed atomic.Value
+				cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if unreachable.Load() {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					if staleJWT, ok := stale.Load().(string); ok {
+						_, _ = w.Write([]byte(staleJWT))
+						return
+					}
+					statusList, err := credService.GetCredentialStatusList(r.Context(), credential.GetCredentialStatusListRequest{ID: strings.TrimPrefix(r.URL.Path, "/status/")})
+					if err != nil {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					lastServed.Store(statusList.CredentialJWT.String())
+					w.Header().Set("Content-Type", "application/jwt")
+					_, _ = w.Write([]byte(statusList.CredentialJWT.String()))
+				}))
+				defer cdn.Close()
+				config.SetStatusBase(cdn.URL + "/status")
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+
+				mismatches, err := credService.CheckStatusListHosting(context.Background())
+				require.NoError(ttt, err)
+				assert.Empty(ttt, mismatches)
+				assert.Nil(ttt, credService.Status().Details)
+
+				// the CDN keeps serving the status list from before the credential was revoked
+				stale.Store(lastServed.Load())
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true}))
+				w := httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": created.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				var published []credential.StatusListHostingMismatch
+				ctx, cancel := context.WithCancel(context.Background())
+				credService.RunStatusListHostingCheck(ctx, func(_ context.Context, mismatch credential.StatusListHostingMismatch) {
+					published = append(published, mismatch)
+				})
+				cancel()
+				require.Len(ttt, published, 1)
+				assert.Equal(ttt, credential.HostingStale, published[0].Reason)
+				assert.True(ttt, strings.HasPrefix(published[0].URI, cdn.URL+"/status/"))
+				assert.NotEmpty(ttt, published[0].ID)
+
+				status := credService.Status()
+				assert.True(ttt, status.IsReady())
+				statusBytes, err := json.Marshal(status)
+				require.NoError(ttt, err)
+				assert.Contains(ttt, string(statusBytes), credential.HostingStale)
+
+				unreachable.Store(true)
+				mismatches, err = credService.CheckStatusListHosting(context.Background())
+				require.NoError(ttt, err)
+				require.Len(ttt, mismatches, 1)
+				assert.Equal(ttt, credential.HostingUnreachable, mismatches[0].Reason)
+
+				// the check does not run when disabled
+				disabledService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				disabledService.RunStatusListHostingCheck(context.Background(), func(context.Context, credential.StatusListHostingMismatch) {
+					ttt.Fatal("the hosting check ran while disabled")
+				})
+				assert.Nil(ttt, disabledService.Status().Details)
+			})
+
+			tt.Run("Test Credential Auto Renewal", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				mockClock := clock.NewMock()
+				mockClock.Set(time.Now())
+				credService.Clock = mockClock
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				week := 7 * 24 * time.Hour
+				every := 6 * 24 * time.Hour
+				createRequest := func(subject string, policy credmodel.AutoRenewPolicy) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            subject,
+						Data:                               map[string]any{"access": "lab"},
+						Expiry:                             mockClock.Now().Add(week).Format(time.RFC3339),
+						Revocable:                          true,
+						AutoRenew:                          &policy,
+					}
+				}
+				getHistory := func(id string) router.GetCredentialHistoryResponse {
+					req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/history", id), nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredentialHistory(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.GetCredentialHistoryResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				// credentials must be renewed before they expire
+				_, err = credService.CreateCredential(context.Background(), createRequest("did:abc:123", credmodel.AutoRenewPolicy{Every: "168h"}))
+				assert.ErrorIs(ttt, err, credential.ErrInvalidAutoRenew)
+
+				created, err := credService.CreateCredential(context.Background(), createRequest("did:abc:123", credmodel.AutoRenewPolicy{Every: "144h", MaxRenewals: 2, RevokePrevious: true}))
+				require.NoError(ttt, err)
+
+				// nothing is renewed before the renewal period has passed
+				renewed, err := credService.RenewCredentials(context.Background())
+				require.NoError(ttt, err)
+				assert.Empty(ttt, renewed)
+
+				ids := []string{created.ID}
+				for cycle := 1; cycle <= 2; cycle++ {
+					mockClock.Add(every)
+					renewed, err = credService.RenewCredentials(context.Background())
+					require.NoError(ttt, err)
+					require.Len(ttt, renewed, 1)
+					assert.Equal(ttt, ids[len(ids)-1], renewed[0].PreviousCredential)
+					assert.Equal(ttt, "lab", renewed[0].Credential.CredentialSubject["access"])
+					assert.Equal(ttt, mockClock.Now().Add(week).Format(time.RFC3339), renewed[0].Credential.ExpirationDate)
+					ids = append(ids, renewed[0].ID)
+
+					previous, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: ids[len(ids)-2]})
+					require.NoError(ttt, err)
+					assert.True(ttt, previous.Revoked)
+				}
+
+				// the chain stops once its maximum renewals are reached
+				mockClock.Add(every)
+				renewed, err = credService.RenewCredentials(context.Background())
+				require.NoError(ttt, err)
+				assert.Empty(ttt, renewed)
+
+				history := getHistory(ids[1])
+				require.Len(ttt, history.Credentials, 3)
+				for i, cred := range history.Credentials {
+					assert.Equal(ttt, ids[i], cred.ID)
+				}
+				assert.False(ttt, history.Credentials[2].Revoked)
+				assert.Equal(ttt, credential.RenewalStoppedMaxRenewals, history.StoppedReason)
+
+				// a revoked credential, an expired policy, and a revoked issuer key each stop their chain
+				revoked, err := credService.CreateCredential(context.Background(), createRequest("did:abc:456", credmodel.AutoRenewPolicy{Every: "144h"}))
+				require.NoError(ttt, err)
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revoked.ID, Revoked: true})
+				require.NoError(ttt, err)
+				expired, err := credService.CreateCredential(context.Background(), createRequest("did:abc:789", credmodel.AutoRenewPolicy{Every: "144h", Until: mockClock.Now().Add(time.Hour).Format(time.RFC3339)}))
+				require.NoError(ttt, err)
+
+				mockClock.Add(every)
+				renewed, err = credService.RenewCredentials(context.Background())
+				require.NoError(ttt, err)
+				assert.Empty(ttt, renewed)
+				assert.Equal(ttt, credential.RenewalStoppedCredentialRevoked, getHistory(revoked.ID).StoppedReason)
+				assert.Equal(ttt, credential.RenewalStoppedPolicyExpired, getHistory(expired.ID).StoppedReason)
+
+				keyRevoked, err := credService.CreateCredential(context.Background(), createRequest("did:abc:012", credmodel.AutoRenewPolicy{Every: "144h"}))
+				require.NoError(ttt, err)
+				require.NoError(ttt, keyStoreService.RevokeKey(context.Background(), keystore.RevokeKeyRequest{ID: keyRevoked.FullyQualifiedVerificationMethodID}))
+
+				mockClock.Add(every)
+				renewed, err = credService.RenewCredentials(context.Background())
+				require.NoError(ttt, err)
+				assert.Empty(ttt, renewed)
+				history = getHistory(keyRevoked.ID)
+				assert.Len(ttt, history.Credentials, 1)
+				assert.Equal(ttt, credential.RenewalStoppedIssuerKeyRevoked, history.StoppedReason)
+			})
+
+			tt.Run("Test Get Status List Credential", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				assert.NoError(ttt, err)
+				assert.NotEmpty(ttt, issuerDID)
+
+				w := httptest.NewRecorder()
+
+				// good request number one
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data: map[string]any{
+						"firstName": "Jack",
+						"lastName":  "Dorsey",
+					},
+					Expiry:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+					Revocable: true,
+				}
+
+				requestValue := newRequestValue(ttt, createCredRequest)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				c := newRequestContext(w, req)
+				credRouter.CreateCredential(c)
+				assert.True(ttt, util.Is2xxResponse(w.Code))
+
+				var resp router.CreateCredentialResponse
+				err = json.NewDecoder(w.Body).Decode(&resp)
+				assert.NoError(ttt, err)
+
+				assert.NotEmpty(ttt, resp.CredentialJWT)
+				assert.NotEmpty(ttt, resp.Credential.CredentialStatus)
+				assert.Equal(ttt, resp.Credential.Issuer, issuerDID.DID.ID)
+
+				credStatusMap, ok := resp.Credential.CredentialStatus.(map[string]any)
+				assert.True(ttt, ok)
+
+				assert.NotEmpty(ttt, credStatusMap["statusListIndex"])
+
+				credStatusListID := (credStatusMap["statusListCredential"]).(string)
+
+				assert.NotEmpty(ttt, credStatusListID)
+
+				i := strings.LastIndex(credStatusListID, "/")
+				uuidStringUUID := credStatusListID[i+1:]
+
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://localhost:8080/%s", credStatusListID), nil)
+				c = newRequestContextWithParams(w, req, map[string]string{"id": uuidStringUUID})
+				credRouter.GetCredentialStatusList(c)
+				assert.True(ttt, util.Is2xxResponse(w.Code))
+
+				var credListResp router.GetCredentialStatusListResponse
 				err = json.NewDecoder(w.Body).Decode(&credListResp)
 				assert.NoError(ttt, err)
 
-				assert.NotEmpty(ttt, credListResp.CredentialJWT)
-				assert.Empty(ttt, credListResp.Credential.CredentialStatus)
-				assert.Equal(ttt, credListResp.Credential.ID, credStatusListID)
+				assert.NotEmpty(ttt, credListResp.CredentialJWT)
+				assert.Empty(ttt, credListResp.Credential.CredentialStatus)
+				assert.Equal(ttt, credListResp.Credential.ID, credStatusListID)
+			})
+
+			tt.Run("Test Status List Is Published On Status Update", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				// the external store fails once, so that publication is retried
+				var mu sync.Mutex
+				attempts := 0
+				published := make(map[string]string)
+				store := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					defer mu.Unlock()
+					attempts++
+					if attempts == 1 {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					assert.Equal(ttt, http.MethodPut, r.Method)
+					assert.Equal(ttt, "Bearer secret", r.Header.Get("Authorization"))
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(ttt, err)
+					published[r.URL.Path] = string(body)
+					w.WriteHeader(http.StatusOK)
+				}))
+				defer store.Close()
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{
+					StatusListPublisher: config.StatusListPublisherConfig{
+						Type:          config.HTTPStatusListPublisher,
+						URL:           store.URL + "/status-lists/{id}",
+						Authorization: "Bearer secret",
+						MaxAttempts:   3,
+						RetryBackoff:  "10ms",
+					},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(ttt, err)
+
+				createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+					Expiry:                             time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{
+					ID:      createdCred.ID,
+					Revoked: true,
+				})
+				require.NoError(ttt, err)
+
+				statusListURI := createdCred.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+				statusListPath := "/status-lists/" + idFromURI(statusListURI)
+				require.Eventually(ttt, func() bool {
+					mu.Lock()
+					defer mu.Unlock()
+					return published[statusListPath] != ""
+				}, 5*time.Second, 10*time.Millisecond)
+
+				statusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: idFromURI(statusListURI)})
+				require.NoError(ttt, err)
+				mu.Lock()
+				defer mu.Unlock()
+				assert.Equal(ttt, statusList.CredentialJWT.String(), published[statusListPath])
+				assert.Equal(ttt, 2, attempts)
+			})
+
+			tt.Run("Test Get Status List Capacity", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(ttt, err)
+
+				getCapacity := func(query string) (int, router.GetStatusListCapacityResponse) {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status/capacity?"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.GetStatusListCapacity(newRequestContext(w, req))
+					var resp router.GetStatusListCapacityResponse
+					if util.Is2xxResponse(w.Code) {
+						require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					}
+					return w.Code, resp
+				}
+
+				// the issuer is required, and the purpose must be known
+				code, _ := getCapacity("")
+				assert.Equal(ttt, http.StatusBadRequest, code)
+				code, _ = getCapacity("issuer=" + issuerDID.DID.ID + "&purpose=unknown")
+				assert.Equal(ttt, http.StatusBadRequest, code)
+
+				// no status list exists before the first status enabled credential
+				code, capacity := getCapacity("issuer=" + issuerDID.DID.ID)
+				require.Equal(ttt, http.StatusOK, code)
+				assert.Empty(ttt, capacity.StatusLists)
+
+				for i := 1; i <= 3; i++ {
+					createCredRequest := router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+						Revocable:            true,
+					}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					statusListURI := resp.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+
+					code, capacity = getCapacity("issuer=" + issuerDID.DID.ID + "&purpose=revocation")
+					require.Equal(ttt, http.StatusOK, code)
+					require.Len(ttt, capacity.StatusLists, 1)
+					statusList := capacity.StatusLists[0]
+					assert.Equal(ttt, statusListURI, statusList.StatusListCredentialID)
+					assert.Equal(ttt, statussdk.StatusRevocation, statusList.Purpose)
+					assert.Equal(ttt, 131072, statusList.TotalBits)
+					assert.Equal(ttt, i, statusList.Allocated)
+					assert.Equal(ttt, statusList.TotalBits-1-i, statusList.Remaining)
+				}
+
+				// status lists of other schemas and purposes are not affected
+				code, capacity = getCapacity("issuer=" + issuerDID.DID.ID + "&purpose=suspension")
+				require.Equal(ttt, http.StatusOK, code)
+				assert.Empty(ttt, capacity.StatusLists)
+				code, capacity = getCapacity("issuer=" + issuerDID.DID.ID + "&schema=unknown-schema")
+				require.Equal(ttt, http.StatusOK, code)
+				assert.Empty(ttt, capacity.StatusLists)
+			})
+
+			tt.Run("Test Get Issuer Statistics", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				otherIssuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createCredential := func(issuer *did.CreateDIDResponse, revocable, suspendable bool) string {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuer.DID.ID,
+						VerificationMethodID: issuer.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            revocable,
+						Suspendable:          suspendable,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp.ID
+				}
+				updateStatus := func(id string, request router.UpdateCredentialStatusRequest) {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+				}
+				getStatistics := func(query string) (int, router.GetIssuerStatisticsResponse) {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/statistics?"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.GetIssuerStatistics(newRequestContext(w, req))
+					var resp router.GetIssuerStatisticsResponse
+					if util.Is2xxResponse(w.Code) {
+						require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					}
+					return w.Code, resp
+				}
+
+				// the issuer is required
+				code, _ := getStatistics("")
+				assert.Equal(ttt, http.StatusBadRequest, code)
+
+				// an issuer without credentials
+				code, statistics := getStatistics("issuer=" + issuerDID.DID.ID)
+				require.Equal(ttt, http.StatusOK, code)
+				assert.Zero(ttt, statistics.Credentials.Total)
+				assert.Empty(ttt, statistics.StatusLists)
+				assert.Zero(ttt, statistics.IndexUtilization)
+
+				_ = createCredential(issuerDID, true, false)
+				revoked := createCredential(issuerDID, true, false)
+				suspended := createCredential(issuerDID, false, true)
+				_ = createCredential(issuerDID, false, false)
+				_ = createCredential(otherIssuerDID, true, false)
+				updateStatus(revoked, router.UpdateCredentialStatusRequest{Revoked: true})
+				updateStatus(suspended, router.UpdateCredentialStatusRequest{Suspended: true})
+
+				code, statistics = getStatistics("issuer=" + issuerDID.DID.ID)
+				require.Equal(ttt, http.StatusOK, code)
+				assert.Equal(ttt, issuerDID.DID.ID, statistics.Issuer)
+				assert.Equal(ttt, credential.IssuerCredentialCounts{
+					Total:     4,
+					Active:    2,
+					Revoked:   1,
+					Suspended: 1,
+					BySchema:  map[string]int{"": 4},
+				}, statistics.Credentials)
+
+				// the revocation and suspension lists, and not those of the other issuer
+				require.Len(ttt, statistics.StatusLists, 2)
+				assert.Equal(ttt, statussdk.StatusRevocation, statistics.StatusLists[0].Purpose)
+				assert.Equal(ttt, 2, statistics.StatusLists[0].Allocated)
+				assert.Equal(ttt, statussdk.StatusSuspension, statistics.StatusLists[1].Purpose)
+				assert.Equal(ttt, 1, statistics.StatusLists[1].Allocated)
+				assert.Equal(ttt, 3, statistics.AllocatedIndexes)
+				assert.Equal(ttt, statistics.StatusLists[0].Remaining+statistics.StatusLists[1].Remaining, statistics.RemainingIndexes)
+				assert.InDelta(ttt, 3/float64(3+statistics.RemainingIndexes), statistics.IndexUtilization, 1e-9)
+
+				code, statistics = getStatistics("issuer=" + otherIssuerDID.DID.ID)
+				require.Equal(ttt, http.StatusOK, code)
+				assert.Equal(ttt, 1, statistics.Credentials.Total)
+				assert.Equal(ttt, 1, statistics.Credentials.Active)
+				assert.Len(ttt, statistics.StatusLists, 1)
+			})
+
+			tt.Run("Test Issuer Scoped API Keys", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerA, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				issuerB, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				// the key hashes are the sha256 hashes of keyA and keyB
+				keyA, keyB := "hunter2", "hunter3"
+				issuerScopedAuth := middleware.IssuerScopedAuth([]config.IssuerAPIKeyConfig{
+					{KeyHash: "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", Issuers: []string{issuerA.DID.ID}},
+					{KeyHash: "fb8c2e2b85ca81eb4350199faddd983cb26af3064614e737ea9f479621cfa57a", Issuers: []string{issuerB.DID.ID}},
+				})
+				engine := gin.New()
+				engine.PUT("/v1/credentials", issuerScopedAuth, credRouter.CreateCredential)
+				engine.PUT("/v1/credentials/:id/status", issuerScopedAuth, credRouter.UpdateCredentialStatus)
+				engine.DELETE("/v1/credentials/:id", issuerScopedAuth, credRouter.DeleteCredential)
+				serve := func(method, url, key string, body any) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(method, url, newRequestValue(ttt, body))
+					if key != "" {
+						req.Header.Set(middleware.APIKeyHeader, key)
+					}
+					w := httptest.NewRecorder()
+					engine.ServeHTTP(w, req)
+					return w
+				}
+				createCredRequest := func(issuer did.CreateDIDResponse) router.CreateCredentialRequest {
+					return router.CreateCredentialRequest{
+						Issuer:               issuer.DID.ID,
+						VerificationMethodID: issuer.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+						Revocable:            true,
+					}
+				}
+
+				w := serve(http.MethodPut, "/v1/credentials", "", createCredRequest(*issuerA))
+				assert.Equal(ttt, http.StatusUnauthorized, w.Code)
+
+				w = serve(http.MethodPut, "/v1/credentials", keyB, createCredRequest(*issuerA))
+				assert.Equal(ttt, http.StatusForbidden, w.Code)
+				assert.Contains(ttt, w.Body.String(), issuerA.DID.ID)
+
+				w = serve(http.MethodPut, "/v1/credentials", keyA, createCredRequest(*issuerA))
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				var createdCred router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createdCred))
+
+				// only the owner of the credential may update its status
+				statusURL := "/v1/credentials/" + createdCred.ID + "/status"
+				w = serve(http.MethodPut, statusURL, keyB, router.UpdateCredentialStatusRequest{Revoked: true})
+				assert.Equal(ttt, http.StatusForbidden, w.Code)
+
+				w = serve(http.MethodPut, statusURL, keyA, router.UpdateCredentialStatusRequest{Revoked: true})
+				require.Equal(ttt, http.StatusOK, w.Code)
+				var statusResp router.UpdateCredentialStatusResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&statusResp))
+				assert.True(ttt, statusResp.Revoked)
+
+				// and only the owner may delete it
+				credURL := "/v1/credentials/" + createdCred.ID
+				w = serve(http.MethodDelete, credURL, "", nil)
+				assert.Equal(ttt, http.StatusUnauthorized, w.Code)
+
+				w = serve(http.MethodDelete, credURL, keyB, nil)
+				assert.Equal(ttt, http.StatusForbidden, w.Code)
+
+				w = serve(http.MethodDelete, credURL, keyA, nil)
+				assert.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+			})
+
+			tt.Run("Test Credential ID Schemes", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				schemes := []struct {
+					scheme        string
+					idPrefix      string
+					statusEntryID string
+				}{
+					{scheme: config.URLCredentialIDScheme, idPrefix: config.GetServicePath(svcframework.Credential) + "/", statusEntryID: "/status"},
+					{scheme: config.URNCredentialIDScheme, idPrefix: "urn:uuid:", statusEntryID: "#status"},
+					{scheme: "https://ids.example.com/credentials/", idPrefix: "https://ids.example.com/credentials/", statusEntryID: "#status"},
+				}
+				for _, s := range schemes {
+					credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10, IDScheme: s.scheme}, db, keyStoreService, didService.GetResolver(), schemaService)
+					require.NoError(ttt, err)
+					credRouter, err := router.NewCredentialRouter(credService)
+					require.NoError(ttt, err)
+
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+						Revocable:            true,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+
+					var createResp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+					credID := createResp.Credential.ID
+					assert.Equal(ttt, s.idPrefix+createResp.ID, credID)
+					statusEntry, ok := createResp.Credential.CredentialStatus.(map[string]any)
+					require.True(ttt, ok)
+					assert.Equal(ttt, credID+s.statusEntryID, statusEntry["id"])
+
+					// the credential can be looked up by its local ID and by its full ID
+					for _, id := range []string{createResp.ID, credID} {
+						req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+url.PathEscape(id), nil)
+						w = httptest.NewRecorder()
+						credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+						require.True(ttt, util.Is2xxResponse(w.Code))
+						var getResp router.GetCredentialResponse
+						require.NoError(ttt, json.NewDecoder(w.Body).Decode(&getResp))
+						assert.Equal(ttt, credID, getResp.Credential.ID)
+					}
+
+					requestValue = newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: createResp.CredentialJWT})
+					req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", requestValue)
+					w = httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var verifyResp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&verifyResp))
+					assert.True(ttt, verifyResp.Verified, verifyResp.Reason)
+
+					requestValue = newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+					req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/status", requestValue)
+					w = httptest.NewRecorder()
+					credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": credID}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+
+					req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/status", nil)
+					w = httptest.NewRecorder()
+					credRouter.GetCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": createResp.ID}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var statusResp router.GetCredentialStatusResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&statusResp))
+					assert.True(ttt, statusResp.Revoked)
+
+					// the status entry resolves to the status list, which has the credential revoked
+					requestValue = newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: createResp.CredentialJWT})
+					req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", requestValue)
+					w = httptest.NewRecorder()
+					credRouter.VerifyCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var revokedVerifyResp router.VerifyCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&revokedVerifyResp))
+					assert.False(ttt, revokedVerifyResp.Verified)
+					assert.Equal(ttt, verification.Revoked, revokedVerifyResp.ReasonCode)
+				}
+			})
+
+			tt.Run("Test Create Credential With Deterministic ID", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10, DeterministicID: true}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createCredential := func(subject string, data map[string]any) router.CreateCredentialResponse {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              subject,
+						Data:                 data,
+						Revocable:            true,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var createResp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+					return createResp
+				}
+
+				first := createCredential("did:abc:456", map[string]any{"firstName": "Jack", "lastName": "Dorsey"})
+				_, err = uuid.Parse(first.ID)
+				assert.NoError(ttt, err)
+
+				// identical content, in any order, returns the existing credential
+				again := createCredential("did:abc:456", map[string]any{"lastName": "Dorsey", "firstName": "Jack"})
+				assert.Equal(ttt, first.ID, again.ID)
+				assert.Equal(ttt, first.CredentialJWT, again.CredentialJWT)
+
+				// different content gets a different ID
+				otherData := createCredential("did:abc:456", map[string]any{"firstName": "Jack", "lastName": "Smith"})
+				assert.NotEqual(ttt, first.ID, otherData.ID)
+				otherSubject := createCredential("did:abc:789", map[string]any{"firstName": "Jack", "lastName": "Dorsey"})
+				assert.NotEqual(ttt, first.ID, otherSubject.ID)
+
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials?issuer=%s", issuerDID.DID.ID), nil)
+				w := httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var listResp router.ListCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+				assert.Len(ttt, listResp.Credentials, 3)
+
+				// identical credentials in one batch are rejected
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:101",
+					Data:                 map[string]any{"firstName": "Jack"},
+				}
+				requestValue := newRequestValue(ttt, router.BatchCreateCredentialsRequest{Requests: []router.CreateCredentialRequest{createCredRequest, createCredRequest}})
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", requestValue)
+				w = httptest.NewRecorder()
+				credRouter.BatchCreateCredentials(newRequestContext(w, req))
+				assert.False(ttt, util.Is2xxResponse(w.Code))
+				assert.Contains(ttt, w.Body.String(), "batch contains more than one credential with identical content")
+			})
+
+			tt.Run("Test Expand Issuer Display Names", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				// count the lookups, so that they can be checked to be batched
+				var lookups [][]string
+				credService.SetDisplayNames(displayNamesFunc(func(ctx context.Context, ids []string) (map[string]string, error) {
+					lookups = append(lookups, ids)
+					return didService.GetDisplayNames(ctx, ids)
+				}))
+
+				managedDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519, DisplayName: "Acme Bank"})
+				require.NoError(ttt, err)
+				unnamedDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createCredential := func(issuer didsdk.Document, subject string) router.CreateCredentialResponse {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuer.ID,
+						VerificationMethodID: issuer.VerificationMethod[0].ID,
+						Subject:              subject,
+						Data:                 map[string]any{"firstName": "Jack"},
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var createResp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+					return createResp
+				}
+				createCredential(managedDID.DID, "did:abc:123")
+				createCredential(managedDID.DID, "did:abc:456")
+				unnamedCred := createCredential(unnamedDID.DID, "did:abc:789")
+
+				listCredentials := func(query string) router.ListCredentialsResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListCredentials(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var listResp router.ListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+					return listResp
+				}
+
+				// issuers are not expanded unless requested
+				listResp := listCredentials("")
+				assert.Len(ttt, listResp.Credentials, 3)
+				for _, cred := range listResp.Credentials {
+					assert.Empty(ttt, cred.IssuerDisplayName)
+				}
+				assert.Empty(ttt, lookups)
+
+				listResp = listCredentials("?expand=issuer")
+				require.Len(ttt, listResp.Credentials, 3)
+				for _, cred := range listResp.Credentials {
+					if cred.Credential.IssuerID() == managedDID.DID.ID {
+						assert.Equal(ttt, "Acme Bank", cred.IssuerDisplayName)
+					} else {
+						assert.Equal(ttt, unnamedDID.DID.ID, cred.Credential.IssuerID())
+						assert.Empty(ttt, cred.IssuerDisplayName)
+					}
+				}
+				// a single lookup of the distinct issuers is made for the page
+				require.Len(ttt, lookups, 1)
+				assert.ElementsMatch(ttt, []string{managedDID.DID.ID, unnamedDID.DID.ID}, lookups[0])
+				gotDisplayNames, err := didService.GetDisplayNames(context.Background(), lookups[0])
+				require.NoError(ttt, err)
+				assert.Equal(ttt, map[string]string{managedDID.DID.ID: "Acme Bank"}, gotDisplayNames)
+
+				// the display name of a DID can be set after it is created
+				didRouter, err := router.NewDIDRouter(didService)
+				require.NoError(ttt, err)
+				requestValue := newRequestValue(ttt, router.UpdateDIDMetadataRequest{DisplayName: "Globex"})
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("https://ssi-service.com/v1/dids/key/%s", unnamedDID.DID.ID), requestValue)
+				w := httptest.NewRecorder()
+				didRouter.UpdateDIDMetadata(newRequestContextWithParams(w, req, map[string]string{"method": "key", "id": unnamedDID.DID.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s?expand=issuer", unnamedCred.ID), nil)
+				w = httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": unnamedCred.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var getResp router.GetCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&getResp))
+				assert.Equal(ttt, "Globex", getResp.IssuerDisplayName)
+
+				// only DIDs managed by the service have metadata
+				requestValue = newRequestValue(ttt, router.UpdateDIDMetadataRequest{DisplayName: "Initech"})
+				req = httptest.NewRequest(http.MethodPatch, "https://ssi-service.com/v1/dids/key/did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp", requestValue)
+				w = httptest.NewRecorder()
+				didRouter.UpdateDIDMetadata(newRequestContextWithParams(w, req, map[string]string{"method": "key", "id": "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"}))
+				assert.Equal(ttt, http.StatusNotFound, w.Code)
+
+				// other expansions are not supported
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?expand=subject", nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Expand Key Status", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				createCredential := func() router.CreateCredentialResponse {
+					issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+					require.NoError(ttt, err)
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:123",
+						Data:                 map[string]any{"firstName": "Jack"},
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var createResp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+					return createResp
+				}
+				getCredential := func(id, query string) router.GetCredentialResponse {
+					req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s%s", id, query), nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var getResp router.GetCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&getResp))
+					return getResp
+				}
+
+				revokedCred := createCredential()
+				removedCred := createCredential()
+
+				// the key status is not included unless requested
+				getResp := getCredential(revokedCred.ID, "")
+				assert.Nil(ttt, getResp.KeyStatus)
+
+				getResp = getCredential(revokedCred.ID, "?expand=keyStatus")
+				require.NotNil(ttt, getResp.KeyStatus)
+				assert.Equal(ttt, revokedCred.FullyQualifiedVerificationMethodID, getResp.KeyStatus.VerificationMethodID)
+				assert.True(ttt, getResp.KeyStatus.InDIDDocument)
+				assert.True(ttt, getResp.KeyStatus.Managed)
+				assert.False(ttt, getResp.KeyStatus.Revoked)
+				assert.True(ttt, getResp.KeyStatus.Valid)
+				_, err := time.Parse(time.RFC3339, getResp.KeyStatus.ResolvedAt)
+				assert.NoError(ttt, err)
+
+				// a revoked key is no longer valid, though it is still in the DID document
+				err = keyStoreService.RevokeKey(context.Background(), keystore.RevokeKeyRequest{ID: revokedCred.FullyQualifiedVerificationMethodID})
+				require.NoError(ttt, err)
+				getResp = getCredential(revokedCred.ID, "?expand=issuer,keyStatus")
+				require.NotNil(ttt, getResp.KeyStatus)
+				assert.True(ttt, getResp.KeyStatus.InDIDDocument)
+				assert.True(ttt, getResp.KeyStatus.Revoked)
+				assert.NotEmpty(ttt, getResp.KeyStatus.RevokedAt)
+				assert.False(ttt, getResp.KeyStatus.Valid)
+
+				// a key removed from the DID document is no longer valid, though it is not revoked
+				didStorage, err := did.NewDIDStorage(db)
+				require.NoError(ttt, err)
+				storedDID, err := didStorage.GetDIDDefault(context.Background(), removedCred.Credential.IssuerID())
+				require.NoError(ttt, err)
+				storedDID.DID.VerificationMethod = nil
+				require.NoError(ttt, didStorage.StoreDID(context.Background(), *storedDID))
+				getResp = getCredential(removedCred.ID, "?expand=keyStatus")
+				require.NotNil(ttt, getResp.KeyStatus)
+				assert.Equal(ttt, removedCred.FullyQualifiedVerificationMethodID, getResp.KeyStatus.VerificationMethodID)
+				assert.False(ttt, getResp.KeyStatus.InDIDDocument)
+				assert.Empty(ttt, getResp.KeyStatus.ResolutionError)
+				assert.True(ttt, getResp.KeyStatus.Managed)
+				assert.False(ttt, getResp.KeyStatus.Revoked)
+				assert.False(ttt, getResp.KeyStatus.Valid)
+
+				// other expansions are not supported
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s?expand=keyStatus,subject", removedCred.ID), nil)
+				w := httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": removedCred.ID}))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Credential Receipt", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				// the subject holds its own key
+				subjectPrivKey, subjectDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+				require.NoError(ttt, err)
+				subjectDID, err := subjectDIDKey.Expand()
+				require.NoError(ttt, err)
+				subjectKeyAccess, err := keyaccess.NewJWKKeyAccess(subjectDID.ID, subjectDID.VerificationMethod[0].ID, subjectPrivKey)
+				require.NoError(ttt, err)
+
+				createCredential := func(subject string) router.CreateCredentialResponse {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              subject,
+						Data:                 map[string]any{"firstName": "Jack"},
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var createResp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+					return createResp
+				}
+				storeReceipt := func(id string, receiptJWT keyaccess.JWT) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(ttt, router.StoreCredentialReceiptRequest{ReceiptJWT: receiptJWT})
+					req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/receipt", id), requestValue)
+					w := httptest.NewRecorder()
+					credRouter.StoreCredentialReceipt(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+				listCredentials := func(query string) []credmodel.Container {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListCredentials(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var listResp router.ListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+					return listResp.Credentials
+				}
+
+				acknowledgedCred := createCredential(subjectDID.ID)
+				unacknowledgedCred := createCredential(subjectDID.ID)
+				otherSubjectCred := createCredential("did:abc:456")
+
+				receiptJWT, err := subjectKeyAccess.Sign(map[string]any{credential.ReceiptCredentialIDClaim: acknowledgedCred.ID})
+				require.NoError(ttt, err)
+				w := storeReceipt(acknowledgedCred.ID, *receiptJWT)
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var receiptResp router.StoreCredentialReceiptResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&receiptResp))
+				assert.Equal(ttt, *receiptJWT, receiptResp.Receipt.ReceiptJWT)
+				assert.Equal(ttt, subjectDID.VerificationMethod[0].ID, receiptResp.Receipt.VerificationMethodID)
+				assert.NotEmpty(ttt, receiptResp.Receipt.AcknowledgedAt)
+
+				// the receipt is stored alongside the credential
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s", acknowledgedCred.ID), nil)
+				w = httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": acknowledgedCred.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var getResp router.GetCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&getResp))
+				require.NotNil(ttt, getResp.Receipt)
+				assert.Equal(ttt, receiptResp.Receipt, *getResp.Receipt)
+
+				// credentials can be filtered by whether they were acknowledged
+				acknowledged := listCredentials("?acknowledged=true")
+				require.Len(ttt, acknowledged, 1)
+				assert.Equal(ttt, acknowledgedCred.ID, acknowledged[0].ID)
+				unacknowledged := listCredentials("?acknowledged=false")
+				assert.Len(ttt, unacknowledged, 2)
+				for _, cred := range unacknowledged {
+					assert.Nil(ttt, cred.Receipt)
+				}
+				acknowledged = listCredentials(fmt.Sprintf("?subject=%s&acknowledged=true", subjectDID.ID))
+				require.Len(ttt, acknowledged, 1)
+				assert.Equal(ttt, acknowledgedCred.ID, acknowledged[0].ID)
+				assert.Empty(ttt, listCredentials("?subject=did:abc:456&acknowledged=true"))
+
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?acknowledged=maybe", nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+
+				// a receipt of a credential whose subject is not the signer is rejected
+				receiptJWT, err = subjectKeyAccess.Sign(map[string]any{credential.ReceiptCredentialIDClaim: otherSubjectCred.ID})
+				require.NoError(ttt, err)
+				w = storeReceipt(otherSubjectCred.ID, *receiptJWT)
+				assert.Equal(ttt, http.StatusForbidden, w.Code)
+				assert.Contains(ttt, w.Body.String(), "receipt is not signed by the subject of the credential")
+
+				// a receipt must reference the credential it acknowledges
+				w = storeReceipt(unacknowledgedCred.ID, *receiptJWT)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "invalid receipt")
+
+				// a receipt must be signed with a key of the subject
+				otherPrivKey, _, err := key.GenerateDIDKey(crypto.Ed25519)
+				require.NoError(ttt, err)
+				forgingKeyAccess, err := keyaccess.NewJWKKeyAccess(subjectDID.ID, subjectDID.VerificationMethod[0].ID, otherPrivKey)
+				require.NoError(ttt, err)
+				receiptJWT, err = forgingKeyAccess.Sign(map[string]any{credential.ReceiptCredentialIDClaim: unacknowledgedCred.ID})
+				require.NoError(ttt, err)
+				w = storeReceipt(unacknowledgedCred.ID, *receiptJWT)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+
+				assert.Len(ttt, listCredentials("?acknowledged=true"), 1)
+			})
+
+			tt.Run("Test List Credential Metadata", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519, DisplayName: "Acme Bank"})
+				require.NoError(ttt, err)
+
+				expiry := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:123",
+					Data:                 map[string]any{"firstName": "Jack"},
+					Expiry:               expiry,
+					Revocable:            true,
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+
+				requestValue = newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+				req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", createResp.ID), requestValue)
+				w = httptest.NewRecorder()
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": createResp.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials?issuer=%s&metadataOnly=true&expand=issuer", issuerDID.DID.ID), nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				assert.NotContains(ttt, w.Body.String(), "credentialSubject")
+				assert.NotContains(ttt, w.Body.String(), "credentialJwt")
+
+				var listResp router.ListCredentialMetadataResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+				require.Len(ttt, listResp.Credentials, 1)
+				assert.Equal(ttt, credential.CredentialMetadata{
+					ID:                createResp.ID,
+					Issuer:            issuerDID.DID.ID,
+					Subject:           "did:abc:123",
+					Revoked:           true,
+					IssuanceDate:      createResp.Credential.IssuanceDate,
+					ExpirationDate:    expiry,
+					CreatedBy:         framework.AnonymousPrincipal,
+					LifecycleState:    credmodel.LifecycleRevoked,
+					IssuerDisplayName: "Acme Bank",
+				}, listResp.Credentials[0])
+
+				// filters apply to metadata as they do to credentials
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?subject=did:abc:456&metadataOnly=true", nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				listResp = router.ListCredentialMetadataResponse{}
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+				assert.Empty(ttt, listResp.Credentials)
+
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?metadataOnly=maybe", nil)
+				w = httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Signing Pool Rejects Requests Beyond Capacity", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{SigningPoolSize: 1, SigningQueueDepth: 0}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				const requests = 50
+				var wg sync.WaitGroup
+				responses := make([]*httptest.ResponseRecorder, requests)
+				for i := 0; i < requests; i++ {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              fmt.Sprintf("did:abc:%d", i),
+						Data:                 map[string]any{"firstName": "Jack"},
+					})
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+						responses[i] = httptest.NewRecorder()
+						credRouter.CreateCredential(newRequestContext(responses[i], req))
+					}(i)
+				}
+				wg.Wait()
+
+				// requests the pool has no room for fail with a 503 and a Retry-After, while the others are created
+				created := 0
+				for _, w := range responses {
+					if w.Code == http.StatusCreated {
+						created++
+						continue
+					}
+					assert.Equal(ttt, http.StatusServiceUnavailable, w.Code, w.Body.String())
+					assert.Equal(ttt, "1", w.Header().Get("Retry-After"))
+					assert.Contains(ttt, w.Body.String(), "server busy")
+				}
+				assert.Positive(ttt, created)
+
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials?issuer=%s", issuerDID.DID.ID), nil)
+				w := httptest.NewRecorder()
+				credRouter.ListCredentials(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var listResp router.ListCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+				assert.Len(ttt, listResp.Credentials, created)
+			})
+
+			tt.Run("Test Max Validity Duration", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{MaxValidityDuration: "8760h", RequireExpiry: true}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				create := func(expiry string) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Expiry:               expiry,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				w := create(time.Now().Add(2 * 365 * 24 * time.Hour).Format(time.RFC3339))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "requested validity exceeds maximum")
+
+				w = create("")
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "credential must have an expiry")
+
+				w = create(time.Now().Add(180 * 24 * time.Hour).Format(time.RFC3339))
+				assert.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+
+				_, err = credential.NewCredentialService(config.CredentialServiceConfig{MaxValidityDuration: "a year"}, db, keyStoreService, didService.GetResolver(), schemaService)
+				assert.ErrorContains(ttt, err, "invalid max validity duration")
+			})
+
+			tt.Run("Test Batch Create Credentials In Chunks", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 100, BatchCreateChunkSize: 2}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createCredRequest := func(subject, verificationMethodID string) router.CreateCredentialRequest {
+					return router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: verificationMethodID,
+						Subject:              subject,
+						Data:                 map[string]any{"firstName": "Jack"},
+					}
+				}
+				verificationMethodID := issuerDID.DID.VerificationMethod[0].ID
+				// credentials signed with a revoked key pass validation, and fail once their chunk signs them
+				revokedDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				revokedVerificationMethodID := revokedDID.DID.VerificationMethod[0].ID
+				require.NoError(ttt, keyStoreService.RevokeKey(context.Background(), keystore.RevokeKeyRequest{ID: revokedVerificationMethodID}))
+				revokedCredRequest := createCredRequest("did:abc:3", revokedVerificationMethodID)
+				revokedCredRequest.Issuer = revokedDID.DID.ID
+				batchCreate := func(batchRequest router.BatchCreateCredentialsRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(ttt, batchRequest))
+					w := httptest.NewRecorder()
+					credRouter.BatchCreateCredentials(newRequestContext(w, req))
+					return w
+				}
+
+				// the second chunk fails, so the third is not attempted, but the first remains created
+				requests := []router.CreateCredentialRequest{
+					createCredRequest("did:abc:1", verificationMethodID),
+					createCredRequest("did:abc:2", verificationMethodID),
+					revokedCredRequest,
+					createCredRequest("did:abc:4", verificationMethodID),
+					createCredRequest("did:abc:5", verificationMethodID),
+				}
+				w := batchCreate(router.BatchCreateCredentialsRequest{Requests: requests})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var resp router.BatchCreateCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(ttt, resp.Credentials, 5)
+				require.Len(ttt, resp.Errors, 5)
+				for i, cred := range resp.Credentials {
+					if i < 2 {
+						assert.NotEmpty(ttt, cred.ID)
+						assert.Empty(ttt, resp.Errors[i])
+						continue
+					}
+					assert.Empty(ttt, cred.ID)
+					assert.NotEmpty(ttt, resp.Errors[i])
+				}
+				assert.Contains(ttt, resp.Errors[4], "not attempted")
+
+				getCredential := func(id string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+id, nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+				assert.True(ttt, util.Is2xxResponse(getCredential(resp.Credentials[0].ID).Code))
+
+				// chunks after the failed one are created when continuing on error
+				w = batchCreate(router.BatchCreateCredentialsRequest{Requests: requests, ContinueOnError: true})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				resp = router.BatchCreateCredentialsResponse{}
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(ttt, resp.Errors, 5)
+				assert.NotEmpty(ttt, resp.Credentials[4].ID)
+				assert.Empty(ttt, resp.Errors[4])
+				assert.Empty(ttt, resp.Credentials[3].ID)
+				assert.NotEmpty(ttt, resp.Errors[3])
+				assert.True(ttt, util.Is2xxResponse(getCredential(resp.Credentials[4].ID).Code))
+
+				// batches without failures report no errors
+				w = batchCreate(router.BatchCreateCredentialsRequest{Requests: []router.CreateCredentialRequest{requests[0], requests[1], requests[3]}})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				resp = router.BatchCreateCredentialsResponse{}
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Len(ttt, resp.Credentials, 3)
+				assert.Empty(ttt, resp.Errors)
+
+				// the batch fails when no credential is created
+				w = batchCreate(router.BatchCreateCredentialsRequest{Requests: []router.CreateCredentialRequest{requests[2], requests[0], requests[1]}})
+				assert.False(ttt, util.Is2xxResponse(w.Code))
+			})
+
+			tt.Run("Test Batch Create Credentials Of Several Issuers", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 2}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerA, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				issuerB, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createCredRequest := func(issuer *did.CreateDIDResponse, subject string, revocable, suspendable bool) router.CreateCredentialRequest {
+					return router.CreateCredentialRequest{
+						Issuer:               issuer.DID.ID,
+						VerificationMethodID: issuer.DID.VerificationMethod[0].ID,
+						Subject:              subject,
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            revocable,
+						Suspendable:          suspendable,
+					}
+				}
+				batchCreate := func(requests ...router.CreateCredentialRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(ttt, router.BatchCreateCredentialsRequest{Requests: requests}))
+					w := httptest.NewRecorder()
+					credRouter.BatchCreateCredentials(newRequestContext(w, req))
+					return w
+				}
+
+				// the cap applies to each issuer, schema, and status purpose, so the batch holds more credentials than it
+				w := batchCreate(
+					createCredRequest(issuerA, "did:abc:1", true, false),
+					createCredRequest(issuerB, "did:abc:2", true, false),
+					createCredRequest(issuerA, "did:abc:3", true, false),
+					createCredRequest(issuerA, "did:abc:4", false, false),
+					createCredRequest(issuerB, "did:abc:5", true, false),
+					createCredRequest(issuerB, "did:abc:6", false, true),
+					createCredRequest(issuerA, "did:abc:7", false, false),
+				)
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var resp router.BatchCreateCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(ttt, resp.Credentials, 7)
+				assert.Empty(ttt, resp.Errors)
+
+				status := func(i int) map[string]any {
+					require.NotNil(ttt, resp.Credentials[i].Credential)
+					credStatusMap, ok := resp.Credentials[i].Credential.CredentialStatus.(map[string]any)
+					require.True(ttt, ok, "credential %d has no status", i)
+					return credStatusMap
+				}
+				for i, issuer := range []*did.CreateDIDResponse{issuerA, issuerB, issuerA, issuerA, issuerB, issuerB, issuerA} {
+					assert.Equal(ttt, issuer.DID.ID, resp.Credentials[i].Credential.IssuerID())
+				}
+				assert.Nil(ttt, resp.Credentials[3].Credential.CredentialStatus)
+				assert.Nil(ttt, resp.Credentials[6].Credential.CredentialStatus)
+
+				// credentials sharing a status list get distinct indexes of it
+				assert.Equal(ttt, status(0)["statusListCredential"], status(2)["statusListCredential"])
+				assert.NotEqual(ttt, status(0)["statusListIndex"], status(2)["statusListIndex"])
+				assert.Equal(ttt, status(1)["statusListCredential"], status(4)["statusListCredential"])
+				assert.NotEqual(ttt, status(1)["statusListIndex"], status(4)["statusListIndex"])
+				assert.NotEqual(ttt, status(0)["statusListCredential"], status(1)["statusListCredential"])
+				assert.NotEqual(ttt, status(4)["statusListCredential"], status(5)["statusListCredential"])
+				assert.Equal(ttt, string(statussdk.StatusSuspension), status(5)["statusPurpose"])
+
+				// a later batch keeps allocating distinct indexes of the lists created by the first
+				w = batchCreate(createCredRequest(issuerA, "did:abc:8", true, false), createCredRequest(issuerA, "did:abc:9", true, false))
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var next router.BatchCreateCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&next))
+				require.Len(ttt, next.Credentials, 2)
+				indexes := map[any]bool{status(0)["statusListIndex"]: true, status(2)["statusListIndex"]: true}
+				for _, cred := range next.Credentials {
+					credStatusMap, ok := cred.Credential.CredentialStatus.(map[string]any)
+					require.True(ttt, ok)
+					assert.Equal(ttt, status(0)["statusListCredential"], credStatusMap["statusListCredential"])
+					assert.False(ttt, indexes[credStatusMap["statusListIndex"]])
+					indexes[credStatusMap["statusListIndex"]] = true
+				}
+
+				// more credentials of an issuer, schema, and status purpose than the cap fail
+				w = batchCreate(
+					createCredRequest(issuerA, "did:abc:10", true, false),
+					createCredRequest(issuerA, "did:abc:11", true, false),
+					createCredRequest(issuerA, "did:abc:12", true, false),
+				)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "max number of requests is 2")
+
+				// each credential is validated against its own issuer, and its error references its index
+				invalid := createCredRequest(issuerB, "did:abc:13", false, false)
+				invalid.VerificationMethodID = issuerB.DID.ID + "#missing"
+				w = batchCreate(createCredRequest(issuerA, "did:abc:14", false, false), invalid)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "credential 1 of batch")
+			})
+
+			tt.Run("Test Subject Credential Quota", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 100, SubjectCredentialQuota: 2}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Issuer: "me", Name: "name schema", Schema: map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"firstName": map[string]any{"type": "string"},
+							},
+						},
+					},
+				}})
+				require.NoError(ttt, err)
+
+				createCredRequest := func(subject, schemaID string) router.CreateCredentialRequest {
+					return router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              subject,
+						SchemaID:             schemaID,
+						Data:                 map[string]any{"firstName": "Jack"},
+					}
+				}
+				create := func(request router.CreateCredentialRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				assert.Equal(ttt, http.StatusCreated, create(createCredRequest("did:abc:456", "")).Code)
+				assert.Equal(ttt, http.StatusCreated, create(createCredRequest("did:abc:456", createdSchema.ID)).Code)
+				w := create(createCredRequest("did:abc:456", ""))
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+				assert.Contains(ttt, w.Body.String(), "subject credential quota exceeded")
+
+				// the quota is per subject
+				assert.Equal(ttt, http.StatusCreated, create(createCredRequest("did:abc:789", "")).Code)
+
+				// batches are rejected as a whole when they would exceed the quota
+				requestValue := newRequestValue(ttt, router.BatchCreateCredentialsRequest{Requests: []router.CreateCredentialRequest{
+					createCredRequest("did:abc:789", ""),
+					createCredRequest("did:abc:789", ""),
+				}})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", requestValue)
+				w = httptest.NewRecorder()
+				credRouter.BatchCreateCredentials(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+				assert.Contains(ttt, w.Body.String(), "subject credential quota exceeded")
+
+				// schemas may override the quota
+				quota := 3
+				_, err = schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, SubjectCredentialQuota: &quota})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, http.StatusCreated, create(createCredRequest("did:abc:456", createdSchema.ID)).Code)
+				assert.Equal(ttt, http.StatusConflict, create(createCredRequest("did:abc:456", createdSchema.ID)).Code)
+				assert.Equal(ttt, http.StatusConflict, create(createCredRequest("did:abc:456", "")).Code)
+
+				noQuota := 0
+				_, err = schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, SubjectCredentialQuota: &noQuota})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, http.StatusCreated, create(createCredRequest("did:abc:456", createdSchema.ID)).Code)
+
+				removeOverride := -1
+				updated, err := schemaService.UpdateSchema(context.Background(), schema.UpdateSchemaRequest{ID: createdSchema.ID, SubjectCredentialQuota: &removeOverride})
+				require.NoError(ttt, err)
+				assert.Nil(ttt, updated.SubjectCredentialQuota)
+				assert.Equal(ttt, http.StatusConflict, create(createCredRequest("did:abc:456", createdSchema.ID)).Code)
+			})
+
+			tt.Run("Test Public Credential Status With Auth Enabled", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				ttt.Setenv("AUTH_TOKEN", "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7") // sha256 hash of "hunter2"
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				engine := gin.New()
+				engine.Use(middleware.AuthMiddleware(AuthExemptRoutes...))
+				publicStatus := config.PublicCredentialStatusConfig{Enabled: true, MaxAge: 5 * time.Minute, RateLimit: 100}
+				require.NoError(ttt, CredentialAPI(engine.Group(V1Prefix), credService, testWebhookService(ttt, db), "", nil, publicStatus))
+				serve := func(method, url, token string, body any) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(method, url, newRequestValue(ttt, body))
+					if token != "" {
+						req.Header.Set("Authorization", "Bearer "+token)
+					}
+					w := httptest.NewRecorder()
+					engine.ServeHTTP(w, req)
+					return w
+				}
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				w := serve(http.MethodPut, "/v1/credentials", "hunter2", router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					Revocable:            true,
+				})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+				publicStatusURL := "/v1/credentials/" + createResp.ID + "/status/public"
+
+				// the public status is served without a token, unlike every other route
+				w = serve(http.MethodGet, publicStatusURL, "", nil)
+				assert.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				assert.JSONEq(ttt, `{"revoked":false,"suspended":false}`, w.Body.String())
+				assert.Equal(ttt, "public, max-age=300", w.Header().Get("Cache-Control"))
+				w = serve(http.MethodGet, "/v1/credentials/"+createResp.ID, "", nil)
+				assert.Equal(ttt, http.StatusUnauthorized, w.Code)
+				w = serve(http.MethodGet, "/v1/credentials/"+createResp.ID+"/status", "", nil)
+				assert.Equal(ttt, http.StatusUnauthorized, w.Code)
+
+				w = serve(http.MethodPut, "/v1/credentials/"+createResp.ID+"/status", "hunter2", router.UpdateCredentialStatusRequest{Revoked: true})
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				w = serve(http.MethodGet, publicStatusURL, "", nil)
+				assert.Equal(ttt, http.StatusOK, w.Code, w.Body.String())
+				assert.JSONEq(ttt, `{"revoked":true,"suspended":false}`, w.Body.String())
+
+				// a partial ID does not match the credential it starts
+				w = serve(http.MethodGet, "/v1/credentials/"+createResp.ID[:8]+"/status/public", "", nil)
+				assert.Equal(ttt, http.StatusNotFound, w.Code)
+
+				w = serve(http.MethodDelete, "/v1/credentials/"+createResp.ID, "hunter2", nil)
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				// a deleted credential cannot be told apart from one that never existed
+				deleted := serve(http.MethodGet, publicStatusURL, "", nil)
+				unknownID := uuid.NewString()
+				unknown := serve(http.MethodGet, "/v1/credentials/"+unknownID+"/status/public", "", nil)
+				assert.Equal(ttt, http.StatusNotFound, deleted.Code)
+				assert.Equal(ttt, http.StatusNotFound, unknown.Code)
+				assert.Equal(ttt, unknown.Body.String(), deleted.Body.String())
+				assert.Equal(ttt, unknown.Header(), deleted.Header())
+			})
+
+			tt.Run("Test Public Credential Status Disabled", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				engine := gin.New()
+				require.NoError(ttt, CredentialAPI(engine.Group(V1Prefix), credService, testWebhookService(ttt, db), "", nil, config.PublicCredentialStatusConfig{}))
+
+				req := httptest.NewRequest(http.MethodGet, "/v1/credentials/"+uuid.NewString()+"/status/public", nil)
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+				assert.Equal(ttt, http.StatusNotFound, w.Code)
+			})
+
+			tt.Run("Test Client Output Format", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				// keyA, whose hash is configured below, wants JWTs by default
+				keyA := "hunter2"
+				engine := gin.New()
+				engine.Use(middleware.ClientOutputFormat([]config.IssuerAPIKeyConfig{
+					{KeyHash: "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", Issuers: []string{"*"}, OutputFormat: "jwt"},
+				}))
+				engine.PUT("/v1/credentials", credRouter.CreateCredential)
+				engine.GET("/v1/credentials/:id", credRouter.GetCredential)
+				serve := func(method, url, key string, body any) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(method, url, newRequestValue(ttt, body))
+					if key != "" {
+						req.Header.Set(middleware.APIKeyHeader, key)
+					}
+					w := httptest.NewRecorder()
+					engine.ServeHTTP(w, req)
+					return w
+				}
+
+				w := serve(http.MethodPut, "/v1/credentials", keyA, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+				})
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				var createdCred router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createdCred))
+				assert.Nil(ttt, createdCred.Credential)
+				assert.NotEmpty(ttt, createdCred.CredentialJWT)
+
+				getCredential := func(url, key string) router.GetCredentialResponse {
+					w := serve(http.MethodGet, url, key, nil)
+					require.Equal(ttt, http.StatusOK, w.Code)
+					var resp router.GetCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					assert.NotEmpty(ttt, resp.CredentialJWT)
+					return resp
+				}
+				credURL := "/v1/credentials/" + createdCred.ID
+				assert.Nil(ttt, getCredential(credURL, keyA).Credential)
+				assert.NotNil(ttt, getCredential(credURL+"?view=container", keyA).Credential)
+
+				// callers without a configured format get the full container, unless they ask otherwise
+				assert.NotNil(ttt, getCredential(credURL, "").Credential)
+				assert.NotNil(ttt, getCredential(credURL, "unknown").Credential)
+				assert.Nil(ttt, getCredential(credURL+"?view=jwt", "").Credential)
+
+				w = serve(http.MethodGet, credURL+"?view=xml", keyA, nil)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Large Integer Claims Keep Their Precision", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(ttt, err)
+
+				// 2^53 cannot be told apart from 2^53 + 1 once decoded as a float64
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name: "account schema",
+					Schema: map[string]any{
+						"$schema": "https://json-schema.org/draft-07/schema",
+						"type":    "object",
+						"properties": map[string]any{
+							"credentialSubject": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"accountNumber": map[string]any{
+										"type": "integer",
+									},
+									"limit": map[string]any{
+										"type":    "integer",
+										"maximum": uint64(9007199254740992),
+									},
+								},
+								"required": []any{"accountNumber"},
+							},
+						},
+					},
+				})
+				require.NoError(ttt, err)
+
+				createCredential := func(data map[string]any) *httptest.ResponseRecorder {
+					createCredRequest := router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              issuerDID.DID.ID,
+						SchemaID:             createdSchema.ID,
+						Data:                 data,
+						Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+					}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				w := createCredential(map[string]any{"accountNumber": 1, "limit": uint64(9007199254740993)})
+				assert.Equal(ttt, http.StatusInternalServerError, w.Code)
+				assert.Contains(ttt, w.Body.String(), "credential data does not comply with the provided schema")
+
+				w = createCredential(map[string]any{"accountNumber": uint64(9007199254740993)})
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				assert.Contains(ttt, w.Body.String(), `"accountNumber":9007199254740993`)
+				var resp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+
+				// the exact value is signed, stored, and returned
+				jwtPayload, err := base64.RawURLEncoding.DecodeString(strings.Split(resp.CredentialJWT.String(), ".")[1])
+				require.NoError(ttt, err)
+				assert.Contains(ttt, string(jwtPayload), `"accountNumber":9007199254740993`)
+
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+resp.ID, nil)
+				w = httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": resp.ID}))
+				require.Equal(ttt, http.StatusOK, w.Code)
+				assert.Contains(ttt, w.Body.String(), `"accountNumber":9007199254740993`)
+			})
+
+			tt.Run("Test Create Credential Warnings", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(ttt, err)
+
+				// the subject is the issuer, so that it can be resolved
+				longExpiry := time.Now().Add(11 * 365 * 24 * time.Hour).Format(time.RFC3339)
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              issuerDID.DID.ID,
+					Data:                 map[string]any{"firstName": "Jack"},
+					Expiry:               longExpiry,
+				}
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusCreated, w.Code)
+
+				var resp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				assert.NotEmpty(ttt, resp.CredentialJWT)
+				require.Len(ttt, resp.Warnings, 1)
+				assert.True(ttt, strings.HasPrefix(resp.Warnings[0], credential.WarningLongExpiry))
+
+				// no warnings are returned for a short expiry
+				createCredRequest.Expiry = time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+				w = httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				assert.NotContains(ttt, w.Body.String(), "warnings")
+
+				// warnings are returned per item in batches
+				batchRequest := router.BatchCreateCredentialsRequest{Requests: []router.CreateCredentialRequest{createCredRequest, createCredRequest}}
+				batchRequest.Requests[1].Expiry = longExpiry
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(ttt, batchRequest))
+				w = httptest.NewRecorder()
+				credRouter.BatchCreateCredentials(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				var batchResp router.BatchCreateCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&batchResp))
+				require.Len(ttt, batchResp.Warnings, 2)
+				assert.Empty(ttt, batchResp.Warnings[0])
+				require.Len(ttt, batchResp.Warnings[1], 1)
+				assert.True(ttt, strings.HasPrefix(batchResp.Warnings[1][0], credential.WarningLongExpiry))
+
+				// promoting the warning to an error blocks issuance
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{
+					PromotedWarnings: []string{credential.WarningLongExpiry},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				_, err = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            issuerDID.DID.ID,
+					Data:                               map[string]any{"firstName": "Jack"},
+					Expiry:                             longExpiry,
+				})
+				require.Error(ttt, err)
+				assert.Contains(ttt, err.Error(), "warning promoted to error: "+credential.WarningLongExpiry)
+			})
+
+			tt.Run("Test Create Credential With Expected Claims", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				schemaRouter, err := router.NewSchemaRouter(schemaService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				contactSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft/2020-12/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"email": map[string]any{"type": "string"},
+								"phone": map[string]any{"type": "string"},
+								"address": map[string]any{
+									"type":       "object",
+									"properties": map[string]any{"city": map[string]any{"type": "string"}},
+								},
+							},
+							"required": []any{"email"},
+						},
+					},
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "contact schema", Schema: contactSchema})
+				require.NoError(ttt, err)
+
+				// expected claims are managed by patching the schema
+				expectedClaims := []string{"phone", "address.city"}
+				req := httptest.NewRequest(http.MethodPatch, "https://ssi-service.com/v1/schemas/"+createdSchema.ID, newRequestValue(ttt, router.UpdateSchemaRequest{ExpectedClaims: &expectedClaims}))
+				w := httptest.NewRecorder()
+				schemaRouter.UpdateSchema(newRequestContextWithParams(w, req, map[string]string{"id": createdSchema.ID}))
+				require.Equal(ttt, http.StatusOK, w.Code)
+				var updateResp router.UpdateSchemaResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&updateResp))
+				assert.Equal(ttt, expectedClaims, updateResp.ExpectedClaims)
+
+				invalidClaims := []string{"address."}
+				req = httptest.NewRequest(http.MethodPatch, "https://ssi-service.com/v1/schemas/"+createdSchema.ID, newRequestValue(ttt, router.UpdateSchemaRequest{ExpectedClaims: &invalidClaims}))
+				w = httptest.NewRecorder()
+				schemaRouter.UpdateSchema(newRequestContextWithParams(w, req, map[string]string{"id": createdSchema.ID}))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+
+				createCredential := func(data map[string]any) *httptest.ResponseRecorder {
+					createCredRequest := router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              issuerDID.DID.ID,
+						SchemaID:             createdSchema.ID,
+						Data:                 data,
+					}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				// claims required by the schema are still enforced
+				w = createCredential(map[string]any{"phone": "555-0100"})
+				assert.Equal(ttt, http.StatusInternalServerError, w.Code)
+				assert.Contains(ttt, w.Body.String(), "credential data does not comply with the provided schema")
+
+				// missing expected claims are warned about, and recorded on the credential
+				w = createCredential(map[string]any{"email": "jack@example.com", "address": map[string]any{}})
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				var resp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(ttt, resp.Warnings, 1)
+				assert.Equal(ttt, credential.WarningMissingExpectedClaim+": expected claims [phone, address.city] are missing", resp.Warnings[0])
+				assert.Equal(ttt, expectedClaims, resp.MissingClaims)
+
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s", resp.ID), nil)
+				w = httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": resp.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var getResp router.GetCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&getResp))
+				assert.Equal(ttt, expectedClaims, getResp.MissingClaims)
+
+				// fully populated data has neither warnings nor missing claims
+				w = createCredential(map[string]any{"email": "jack@example.com", "phone": "555-0100", "address": map[string]any{"city": "Oakland"}})
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				assert.NotContains(ttt, w.Body.String(), "warnings")
+				assert.NotContains(ttt, w.Body.String(), "missingClaims")
+			})
+
+			tt.Run("Test Create Credential Schema Violations", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{
+					BatchCreateMaxItems:  10,
+					BatchCreateChunkSize: 1,
+					LogRedactedPaths:     []string{"credentialSubject.ssn"},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				personSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft/2020-12/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"name": map[string]any{"type": "string"},
+								"age":  map[string]any{"type": "integer"},
+								"ssn":  map[string]any{"type": "string", "pattern": "^[0-9]{3}-[0-9]{2}-[0-9]{4}$"},
+							},
+							"required": []any{"name"},
+						},
+					},
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "person schema", Schema: personSchema})
+				require.NoError(ttt, err)
+
+				createCredRequest := func(data map[string]any) router.CreateCredentialRequest {
+					return router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						SchemaID:             createdSchema.ID,
+						Data:                 data,
+					}
+				}
+				createCredential := func(data map[string]any) []map[string]any {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest(data)))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					assert.Equal(ttt, http.StatusInternalServerError, w.Code)
+					var errResp struct {
+						Error      string           `json:"error"`
+						Violations []map[string]any `json:"violations"`
+					}
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&errResp))
+					assert.Contains(ttt, errResp.Error, "credential data does not comply with the provided schema")
+					return errResp.Violations
+				}
+
+				// a type mismatch points to the claim, along with the expected type and the offending value
+				violations := createCredential(map[string]any{"name": "Satoshi", "age": "ten"})
+				require.Len(ttt, violations, 1)
+				assert.Equal(ttt, "/credentialSubject/age", violations[0]["pointer"])
+				assert.Equal(ttt, "type", violations[0]["keyword"])
+				assert.Equal(ttt, "integer", violations[0]["expected"])
+				assert.Equal(ttt, "ten", violations[0]["actual"])
+
+				// a missing required claim points to where it is missing
+				violations = createCredential(map[string]any{"age": 10})
+				require.Len(ttt, violations, 1)
+				assert.Equal(ttt, "/credentialSubject/name", violations[0]["pointer"])
+				assert.Equal(ttt, "required", violations[0]["keyword"])
+				assert.Equal(ttt, []any{"name"}, violations[0]["expected"])
+				assert.Nil(ttt, violations[0]["actual"])
+
+				// redacted claims are not reported, and long values are truncated
+				violations = createCredential(map[string]any{"name": "Satoshi", "ssn": "123456789"})
+				require.Len(ttt, violations, 1)
+				assert.Equal(ttt, "/credentialSubject/ssn", violations[0]["pointer"])
+				assert.Equal(ttt, "pattern", violations[0]["keyword"])
+				assert.Equal(ttt, util.RedactedValue, violations[0]["actual"])
+				violations = createCredential(map[string]any{"name": map[string]any{"first": strings.Repeat("x", 1000)}})
+				require.Len(ttt, violations, 1)
+				assert.Equal(ttt, "/credentialSubject/name", violations[0]["pointer"])
+				actual, ok := violations[0]["actual"].(string)
+				require.True(ttt, ok)
+				assert.Less(ttt, len(actual), 300)
+
+				// batches carry the violations of each credential
+				batchRequest := router.BatchCreateCredentialsRequest{
+					Requests: []router.CreateCredentialRequest{
+						createCredRequest(map[string]any{"name": "Satoshi"}),
+						createCredRequest(map[string]any{"name": "Satoshi", "age": "ten"}),
+						createCredRequest(map[string]any{"age": 10}),
+					},
+					ContinueOnError: true,
+				}
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/batch", newRequestValue(ttt, batchRequest))
+				w := httptest.NewRecorder()
+				credRouter.BatchCreateCredentials(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var batchResp router.BatchCreateCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&batchResp))
+				require.Len(ttt, batchResp.Violations, 3)
+				assert.Empty(ttt, batchResp.Violations[0])
+				require.Len(ttt, batchResp.Violations[1], 1)
+				assert.Equal(ttt, "/credentialSubject/age", batchResp.Violations[1][0].Pointer)
+				assert.Equal(ttt, "type", batchResp.Violations[1][0].Keyword)
+				require.Len(ttt, batchResp.Violations[2], 1)
+				assert.Equal(ttt, "/credentialSubject/name", batchResp.Violations[2][0].Pointer)
+				assert.Equal(ttt, "required", batchResp.Violations[2][0].Keyword)
+			})
+
+			tt.Run("Test Create Credential With Typed Nested Subject", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				organizationSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft/2020-12/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"legalName": map[string]any{"type": "string"},
+								"address": map[string]any{
+									"type": "object",
+									"properties": map[string]any{
+										"street":     map[string]any{"type": "string"},
+										"postalCode": map[string]any{"type": "string"},
+										"country":    map[string]any{"type": "string", "minLength": 2, "maxLength": 2},
+									},
+									"required": []any{"street", "postalCode"},
+								},
+							},
+							"required": []any{"legalName", "address"},
+						},
+					},
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "organization schema", Schema: organizationSchema})
+				require.NoError(ttt, err)
+
+				createCredential := func(subjectType string, data map[string]any) *httptest.ResponseRecorder {
+					request := router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:web:acme.example",
+						SubjectType:          subjectType,
+						SchemaID:             createdSchema.ID,
+						Data:                 data,
+					}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				// the subject type is set alongside the id, and nested claims are kept as they are
+				w := createCredential("Organization", map[string]any{
+					"legalName": "Acme Corp",
+					"address":   map[string]any{"street": "1 Main St", "postalCode": "94105", "country": "US"},
+				})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+				subject := createResp.Credential.CredentialSubject
+				assert.Equal(ttt, "did:web:acme.example", subject[credsdk.VerifiableCredentialIDProperty])
+				assert.Equal(ttt, "Organization", subject[credential.SubjectTypeProperty])
+				assert.Equal(ttt, map[string]any{"street": "1 Main St", "postalCode": "94105", "country": "US"}, subject["address"])
+
+				// a type in the data is accepted when it is the same, and rejected when it conflicts
+				w = createCredential("Organization", map[string]any{
+					"type":      "Organization",
+					"legalName": "Acme Corp",
+					"address":   map[string]any{"street": "1 Main St", "postalCode": "94105"},
+				})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				w = createCredential("Organization", map[string]any{
+					"type":      "Person",
+					"legalName": "Acme Corp",
+					"address":   map[string]any{"street": "1 Main St", "postalCode": "94105"},
+				})
+				assert.False(ttt, util.Is2xxResponse(w.Code))
+				assert.Contains(ttt, w.Body.String(), "data already contains a different type value")
+
+				// violations deep in the subject point to the nested claim
+				w = createCredential("Organization", map[string]any{
+					"legalName": "Acme Corp",
+					"address":   map[string]any{"street": "1 Main St", "country": "USA"},
+				})
+				assert.Equal(ttt, http.StatusInternalServerError, w.Code)
+				var errResp struct {
+					Violations []map[string]any `json:"violations"`
+				}
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&errResp))
+				require.Len(ttt, errResp.Violations, 2)
+				pointers := []any{errResp.Violations[0]["pointer"], errResp.Violations[1]["pointer"]}
+				assert.ElementsMatch(ttt, []any{"/credentialSubject/address/postalCode", "/credentialSubject/address/country"}, pointers)
+
+				// credentials about people are not listed by subject type
+				w = createCredential("", map[string]any{
+					"legalName": "Jane Doe",
+					"address":   map[string]any{"street": "2 Main St", "postalCode": "94105"},
+				})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+
+				listCredentials := func(query string) router.ListCredentialsResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials?"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListCredentials(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var listResp router.ListCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+					return listResp
+				}
+				assert.Len(ttt, listCredentials("subjectType=Organization").Credentials, 2)
+				assert.Empty(ttt, listCredentials("subjectType=Person").Credentials)
+				assert.Len(ttt, listCredentials("schema="+createdSchema.ID).Credentials, 3)
+
+				// search requests can filter by subject type
+				searchRequest := router.SearchCredentialsRequest{Filter: `subjectType="Organization" AND subject="did:web:acme.example"`}
+				req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/credentials/search", newRequestValue(ttt, searchRequest))
+				w = httptest.NewRecorder()
+				credRouter.SearchCredentials(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var searchResp router.ListCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&searchResp))
+				assert.Len(ttt, searchResp.Credentials, 2)
+			})
+
+			tt.Run("Test List Subject Credentials", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				mockClock := clock.NewMock()
+				mockClock.Set(time.Date(2023, 06, 23, 0, 0, 0, 0, time.UTC))
+				credService.Clock = mockClock
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519, DisplayName: "Acme Bank"})
+				require.NoError(ttt, err)
+				objectSchema := map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"type":    "object",
+				}
+				emailSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "email schema", Schema: objectSchema})
+				require.NoError(ttt, err)
+				badgeSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{Name: "badge schema", Schema: objectSchema})
+				require.NoError(ttt, err)
+
+				holder := "did:key:z6MkholderA"
+				createCredential := func(subject, schemaID, name, expiry string) string {
+					created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            subject,
+						SchemaID:                           schemaID,
+						Data:                               map[string]any{"name": name},
+						Expiry:                             expiry,
+						Revocable:                          true,
+					})
+					require.NoError(ttt, err)
+					mockClock.Add(time.Hour)
+					return created.ID
+				}
+				revoked := createCredential(holder, emailSchema.ID, "Alice", "")
+				oldBadge := createCredential(holder, badgeSchema.ID, "Alice", "")
+				expired := createCredential(holder, emailSchema.ID, "Alice", "2023-06-23T05:00:00Z")
+				newBadge := createCredential(holder, badgeSchema.ID, "Alice", "")
+				createCredential("did:key:z6MkholderB", badgeSchema.ID, "Bob", "")
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revoked, Revoked: true})
+				require.NoError(ttt, err)
+				mockClock.Set(time.Date(2023, 06, 24, 0, 0, 0, 0, time.UTC))
+
+				listSubjectCredentials := func(query string) router.ListSubjectCredentialsResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/subjects/"+holder+"/credentials?"+query, nil)
+					w := httptest.NewRecorder()
+					credRouter.ListSubjectCredentials(newRequestContextWithParams(w, req, map[string]string{"id": holder}))
+					require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+					var listResp router.ListSubjectCredentialsResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&listResp))
+					return listResp
+				}
+				ids := func(credentials []credential.SubjectCredential) []string {
+					var ids []string
+					for _, cred := range credentials {
+						ids = append(ids, cred.ID)
+					}
+					return ids
+				}
+
+				// expired and revoked credentials are left out by default, and the rest are joined with their schema
+				// and issuer names, newest first
+				listed := listSubjectCredentials("")
+				assert.Equal(ttt, []credential.SubjectCredential{
+					{
+						ID:                newBadge,
+						Issuer:            issuerDID.DID.ID,
+						IssuerDisplayName: "Acme Bank",
+						Schema:            badgeSchema.ID,
+						SchemaName:        "badge schema",
+						IssuanceDate:      "2023-06-23T03:00:00Z",
+					},
+					{
+						ID:                oldBadge,
+						Issuer:            issuerDID.DID.ID,
+						IssuerDisplayName: "Acme Bank",
+						Schema:            badgeSchema.ID,
+						SchemaName:        "badge schema",
+						IssuanceDate:      "2023-06-23T01:00:00Z",
+					},
+				}, listed.Credentials)
+				assert.Empty(ttt, listed.NextPageToken)
+
+				assert.Equal(ttt, []string{newBadge, expired, oldBadge}, ids(listSubjectCredentials("includeExpired=true").Credentials))
+				assert.Equal(ttt, []string{newBadge, oldBadge, revoked}, ids(listSubjectCredentials("includeRevoked=true").Credentials))
+				listed = listSubjectCredentials("includeExpired=true&includeRevoked=true")
+				assert.Equal(ttt, []string{newBadge, expired, oldBadge, revoked}, ids(listed.Credentials))
+				assert.True(ttt, listed.Credentials[1].Expired)
+				assert.Equal(ttt, "email schema", listed.Credentials[1].SchemaName)
+				assert.True(ttt, listed.Credentials[3].Revoked)
+
+				// pages follow the same order
+				var paged []string
+				query := "includeExpired=true&includeRevoked=true&pageSize=3"
+				for {
+					page := listSubjectCredentials(query)
+					require.LessOrEqual(ttt, len(page.Credentials), 3)
+					paged = append(paged, ids(page.Credentials)...)
+					if page.NextPageToken == "" {
+						break
+					}
+					query = "includeExpired=true&includeRevoked=true&pageSize=3&pageToken=" + page.NextPageToken
+				}
+				assert.Equal(ttt, []string{newBadge, expired, oldBadge, revoked}, paged)
+
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/subjects/"+holder+"/credentials?includeExpired=maybe", nil)
+				w := httptest.NewRecorder()
+				credRouter.ListSubjectCredentials(newRequestContextWithParams(w, req, map[string]string{"id": holder}))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Refresh Status Lists", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the status base used for status list credential ids
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				_, err := credential.NewCredentialService(config.CredentialServiceConfig{StatusListRefreshInterval: "daily"}, db, keyStoreService, didService.GetResolver(), schemaService)
+				assert.ErrorContains(ttt, err, "invalid status list refresh interval")
+
+				createStatusList := func(suspendable bool) (string, string) {
+					issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+					require.NoError(ttt, err)
+					createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						Data:                               map[string]any{"firstName": "Jack"},
+						Revocable:                          !suspendable,
+						Suspendable:                        suspendable,
+					})
+					require.NoError(ttt, err)
+					statusListURI := createdCred.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+					return createdCred.ID, statusListURI[strings.LastIndex(statusListURI, "/")+1:]
+				}
+				revokedCredID, statusListID := createStatusList(false)
+				_, excludedStatusListID := createStatusList(true)
+
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revokedCredID, Revoked: true})
+				require.NoError(ttt, err)
+				before, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: statusListID})
+				require.NoError(ttt, err)
+
+				refresher, err := credential.NewCredentialService(config.CredentialServiceConfig{
+					StatusListRefreshInterval:   "24h",
+					StatusListRefreshValidity:   "1h",
+					StatusListRefreshExclusions: []string{excludedStatusListID},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 24*time.Hour, refresher.StatusListRefreshInterval())
+
+				refreshed, err := refresher.RefreshStatusLists(context.Background())
+				require.NoError(ttt, err)
+				require.Len(ttt, refreshed, 1)
+				assert.Equal(ttt, statusListID, refreshed[0].ID)
+				assert.NotEqual(ttt, before.Container.CredentialJWT, refreshed[0].CredentialJWT)
+
+				// the bitstring is unchanged, and the re-signed status list expires after the configured validity
+				after, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: statusListID})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, refreshed[0].CredentialJWT, after.Container.CredentialJWT)
+				assert.Equal(ttt, before.Container.Credential.CredentialSubject["encodedList"], after.Container.Credential.CredentialSubject["encodedList"])
+				expiresAt, err := time.Parse(time.RFC3339, after.Container.Credential.ExpirationDate)
+				require.NoError(ttt, err)
+				assert.WithinDuration(ttt, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+				// status updates keep working on the re-signed status list
+				updated, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revokedCredID, Revoked: false})
+				require.NoError(ttt, err)
+				assert.False(ttt, updated.Revoked)
+			})
+
+			tt.Run("Test Credential JWT Claims", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					JWTClaims:            map[string]any{"vct": "https://example.com/license"},
+				}
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				var resp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.NotNil(ttt, resp.CredentialJWT)
+				payloadBytes, err := base64.RawURLEncoding.DecodeString(strings.Split(resp.CredentialJWT.String(), ".")[1])
+				require.NoError(ttt, err)
+				var payload map[string]any
+				require.NoError(ttt, json.Unmarshal(payloadBytes, &payload))
+				assert.Equal(ttt, "https://example.com/license", payload["vct"])
+				assert.Equal(ttt, issuerDID.DID.ID, payload["iss"])
+
+				// reserved claims can't override the credential payload
+				createCredRequest.JWTClaims = map[string]any{"sub": "did:abc:789"}
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+				w = httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "claim<sub> is reserved")
+			})
+
+			tt.Run("Test Create Credential Verification Method Validation", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				verificationMethodID := issuerDID.DID.VerificationMethod[0].ID
+				missingVerificationMethodID := issuerDID.DID.ID + "#missing-key"
+
+				newService := func(resolver resolution.Resolver, relaxed bool) *credential.Service {
+					serviceConfig := config.CredentialServiceConfig{BatchCreateMaxItems: 10, BatchUpdateStatusMaxItems: 10, RelaxAssertionMethodCheck: relaxed}
+					credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, resolver, schemaService)
+					require.NoError(ttt, err)
+					return credService
+				}
+				createRequest := func(verificationMethodID string) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: verificationMethodID,
+						Subject:                            "did:abc:456",
+						Data:                               map[string]any{"firstName": "Jack"},
+					}
+				}
+
+				credService := newService(didService.GetResolver(), false)
+				_, err = credService.CreateCredential(context.Background(), createRequest(missingVerificationMethodID))
+				assert.ErrorIs(ttt, err, credential.ErrVerificationMethodNotFound)
+				assert.ErrorContains(ttt, err, "verification method "+missingVerificationMethodID+" not found in DID document")
+				_, err = credService.CreateCredential(context.Background(), createRequest(verificationMethodID))
+				assert.NoError(ttt, err)
+
+				// a verification method the issuer does not reference from assertionMethod is only accepted when relaxed
+				unasserted := unassertedResolver{Resolver: didService.GetResolver()}
+				_, err = newService(unasserted, false).CreateCredential(context.Background(), createRequest(verificationMethodID))
+				assert.ErrorIs(ttt, err, credential.ErrVerificationMethodNotAuthorized)
+				assert.ErrorContains(ttt, err, "method "+verificationMethodID+" not authorized for assertionMethod")
+				relaxed := newService(unasserted, true)
+				_, err = relaxed.CreateCredential(context.Background(), createRequest(verificationMethodID))
+				assert.NoError(ttt, err)
+				_, err = relaxed.CreateCredential(context.Background(), createRequest(missingVerificationMethodID))
+				assert.ErrorIs(ttt, err, credential.ErrVerificationMethodNotFound)
+
+				// batches validate each credential
+				_, err = credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+					Requests: []credential.CreateCredentialRequest{createRequest(verificationMethodID), createRequest(missingVerificationMethodID)},
+				})
+				assert.ErrorIs(ttt, err, credential.ErrVerificationMethodNotFound)
+				assert.ErrorContains(ttt, err, "credential 1 of batch")
+
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: missingVerificationMethodID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), "not found in DID document")
+			})
+
+			tt.Run("Test Status List Signing Keys", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the status base used for status list credential ids
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				issuer := issuerDID.DID.ID
+				storeKey := func(verificationMethodID string) string {
+					_, privKey, err := crypto.GenerateKeyByKeyType(crypto.Ed25519)
+					require.NoError(ttt, err)
+					privKeyBytes, err := crypto.PrivKeyToBytes(privKey)
+					require.NoError(ttt, err)
+					keyID := issuer + verificationMethodID
+					require.NoError(ttt, keyStoreService.StoreKey(context.Background(), keystore.StoreKeyRequest{
+						ID:               keyID,
+						Type:             crypto.Ed25519,
+						Controller:       issuer,
+						PrivateKeyBase58: base58.Encode(privKeyBytes),
+					}))
+					return keyID
+				}
+				suspensionKeyID := storeKey("#suspension-key")
+				revocationKeyID := storeKey("#revocation-key")
+
+				_, err = credential.NewCredentialService(config.CredentialServiceConfig{
+					StatusListSigningKeys: []config.StatusListSigningKeyConfig{{Issuer: issuer, Purpose: "message", VerificationMethodID: suspensionKeyID}},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				assert.ErrorContains(ttt, err, "unsupported purpose<message>")
+
+				credService, err := credential.NewCredentialService(config.CredentialServiceConfig{
+					StatusListSigningKeys: []config.StatusListSigningKeyConfig{
+						{Issuer: issuer, Purpose: string(statussdk.StatusSuspension), VerificationMethodID: "#suspension-key"},
+						{Issuer: issuer, Purpose: string(statussdk.StatusRevocation), VerificationMethodID: revocationKeyID},
+					},
+				}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+
+				signingKeyID := func(statusListID string) any {
+					statusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: statusListID})
+					require.NoError(ttt, err)
+					require.NotNil(ttt, statusList.CredentialJWT)
+					headerBytes, err := base64.RawURLEncoding.DecodeString(strings.Split(string(*statusList.CredentialJWT), ".")[0])
+					require.NoError(ttt, err)
+					var header map[string]any
+					require.NoError(ttt, json.Unmarshal(headerBytes, &header))
+					return header["kid"]
+				}
+
+				createCred := func(suspendable bool) (string, string) {
+					createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuer,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						Data:                               map[string]any{"firstName": "Jack"},
+						Revocable:                          !suspendable,
+						Suspendable:                        suspendable,
+					})
+					require.NoError(ttt, err)
+					assert.Equal(ttt, issuerDID.DID.VerificationMethod[0].ID, createdCred.FullyQualifiedVerificationMethodID)
+					statusListURI := createdCred.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+					return createdCred.ID, statusListURI[strings.LastIndex(statusListURI, "/")+1:]
+				}
+				suspendableCredID, suspensionListID := createCred(true)
+				revocableCredID, revocationListID := createCred(false)
+
+				// each status list is signed with the key of its purpose, rather than the key credentials are issued with
+				assert.Equal(ttt, suspensionKeyID, signingKeyID(suspensionListID))
+				assert.Equal(ttt, revocationKeyID, signingKeyID(revocationListID))
+
+				// suspending with the suspension key succeeds, while suspending without it is not permitted
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: suspendableCredID, Suspended: true})
+				assert.ErrorIs(ttt, err, credential.ErrStatusSignerNotPermitted)
+				suspended, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: suspendableCredID, Suspended: true, VerificationMethodID: suspensionKeyID})
+				require.NoError(ttt, err)
+				assert.True(ttt, suspended.Suspended)
+				assert.Equal(ttt, suspensionKeyID, signingKeyID(suspensionListID))
+
+				// revoking requires the revocation key, and neither the suspension key nor the issuing key
+				for _, verificationMethodID := range []string{suspensionKeyID, issuerDID.DID.VerificationMethod[0].ID} {
+					_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revocableCredID, Revoked: true, VerificationMethodID: verificationMethodID})
+					assert.ErrorIs(ttt, err, credential.ErrStatusSignerNotPermitted)
+				}
+				gotStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: revocableCredID})
+				require.NoError(ttt, err)
+				assert.False(ttt, gotStatus.Revoked)
+				revoked, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revocableCredID, Revoked: true, VerificationMethodID: "#revocation-key"})
+				require.NoError(ttt, err)
+				assert.True(ttt, revoked.Revoked)
+				assert.Equal(ttt, revocationKeyID, signingKeyID(revocationListID))
+
+				// the router forbids updates with a verification method which is not permitted
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+				value := newRequestValue(ttt, router.UpdateCredentialStatusRequest{Suspended: false, VerificationMethodID: revocationKeyID})
+				req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", suspendableCredID), value)
+				w := httptest.NewRecorder()
+				c := newRequestContextWithParams(w, req, map[string]string{"id": suspendableCredID})
+				credRouter.UpdateCredentialStatus(c)
+				assert.Equal(ttt, http.StatusForbidden, w.Code)
+			})
+
+			tt.Run("Test Schema Status Policy", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the status base used for status list credential ids
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				_, err = schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:         "bad policy",
+					Schema:       getLicenseApplicationSchema(),
+					StatusPolicy: &schema.StatusPolicy{Default: "sometimes"},
+				})
+				assert.ErrorContains(ttt, err, "validating schema request")
+
+				createSchema := func(policy schema.StatusPolicy) string {
+					createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+						Name:         fmt.Sprintf("%s schema", policy.Default),
+						Schema:       getLicenseApplicationSchema(),
+						StatusPolicy: &policy,
+					})
+					require.NoError(ttt, err)
+					gotSchema, err := schemaService.GetSchema(context.Background(), schema.GetSchemaRequest{ID: createdSchema.ID})
+					require.NoError(ttt, err)
+					assert.Equal(ttt, &policy, gotSchema.StatusPolicy)
+					return createdSchema.ID
+				}
+				credentialRequest := func(schemaID string, revocable, suspendable bool) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						SchemaID:                           schemaID,
+						Data:                               map[string]any{"licenseType": "Class D"},
+						Revocable:                          revocable,
+						Suspendable:                        suspendable,
+					}
+				}
+				statusPurpose := func(cred credsdk.VerifiableCredential) any {
+					if cred.CredentialStatus == nil {
+						return nil
+					}
+					return cred.CredentialStatus.(map[string]any)["statusPurpose"]
+				}
+
+				// a default is applied when the request asks for no status, and can be overridden
+				revocableSchemaID := createSchema(schema.StatusPolicy{Default: schema.RevocableStatusPolicy})
+				createdCred, err := credService.CreateCredential(context.Background(), credentialRequest(revocableSchemaID, false, false))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, string(statussdk.StatusRevocation), statusPurpose(*createdCred.Credential))
+				createdCred, err = credService.CreateCredential(context.Background(), credentialRequest(revocableSchemaID, false, true))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, string(statussdk.StatusSuspension), statusPurpose(*createdCred.Credential))
+
+				batch, err := credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+					Requests: []credential.CreateCredentialRequest{credentialRequest(revocableSchemaID, false, false)},
+				})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, string(statussdk.StatusRevocation), statusPurpose(*batch.Credentials[0].Credential))
+
+				// a mandatory default rejects requests which contradict it
+				suspendableSchemaID := createSchema(schema.StatusPolicy{Default: schema.SuspendableStatusPolicy, Mandatory: true})
+				createdCred, err = credService.CreateCredential(context.Background(), credentialRequest(suspendableSchemaID, false, false))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, string(statussdk.StatusSuspension), statusPurpose(*createdCred.Credential))
+				_, err = credService.CreateCredential(context.Background(), credentialRequest(suspendableSchemaID, true, false))
+				assert.ErrorContains(ttt, err, "requires credentials to have status<suspendable>, but status<revocable> was requested")
+
+				noStatusSchemaID := createSchema(schema.StatusPolicy{Default: schema.NoStatusPolicy, Mandatory: true})
+				createdCred, err = credService.CreateCredential(context.Background(), credentialRequest(noStatusSchemaID, false, false))
+				require.NoError(ttt, err)
+				assert.Nil(ttt, createdCred.Credential.CredentialStatus)
+				_, err = credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+					Requests: []credential.CreateCredentialRequest{credentialRequest(noStatusSchemaID, true, false)},
+				})
+				assert.ErrorContains(ttt, err, "requires credentials to have status<none>")
+			})
+
+			tt.Run("Test Subject Uniqueness", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:             "membership schema",
+					Schema:           getLicenseApplicationSchema(),
+					StatusPolicy:     &schema.StatusPolicy{Default: schema.RevocableStatusPolicy},
+					UniquenessPolicy: &schema.UniquenessPolicy{},
+				})
+				require.NoError(ttt, err)
+				gotSchema, err := schemaService.GetSchema(context.Background(), schema.GetSchemaRequest{ID: createdSchema.ID})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, &schema.UniquenessPolicy{}, gotSchema.UniquenessPolicy)
+
+				createCredential := func(subject string, replaceExisting bool) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              subject,
+						SchemaID:             createdSchema.ID,
+						Data:                 map[string]any{"licenseType": "Class D"},
+						ReplaceExisting:      replaceExisting,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					return w
+				}
+
+				w := createCredential("did:abc:456", false)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var firstResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&firstResp))
+				assert.Empty(ttt, firstResp.ReplacedCredentialIDs)
+
+				// another subject is unaffected
+				w = createCredential("did:abc:789", false)
+				assert.True(ttt, util.Is2xxResponse(w.Code))
+
+				// a second active credential for the subject is rejected
+				w = createCredential("did:abc:456", false)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+				assert.Contains(ttt, w.Body.String(), credential.ErrActiveCredentialExists.Error())
+
+				// unless it replaces the active credential, which is revoked
+				w = createCredential("did:abc:456", true)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var secondResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&secondResp))
+				assert.Equal(ttt, []string{firstResp.ID}, secondResp.ReplacedCredentialIDs)
+
+				firstStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: firstResp.ID})
+				require.NoError(ttt, err)
+				assert.True(ttt, firstStatus.Revoked)
+				secondStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: secondResp.ID})
+				require.NoError(ttt, err)
+				assert.False(ttt, secondStatus.Revoked)
+
+				statusListID, ok := firstResp.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+				require.True(ttt, ok)
+				statusListID = statusListID[len(statusListID)-36:]
+				statusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: statusListID})
+				require.NoError(ttt, err)
+				revoked, err := statussdk.ValidateCredentialInStatusList(*firstResp.Credential, *statusList.Credential)
+				require.NoError(ttt, err)
+				assert.True(ttt, revoked)
+
+				// once the active credential is revoked, a new one can be created
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: secondResp.ID, Revoked: true})
+				require.NoError(ttt, err)
+				w = createCredential("did:abc:456", false)
+				assert.True(ttt, util.Is2xxResponse(w.Code))
+
+				// uniqueness can also be requested without a schema policy
+				request := credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"name": "Satoshi"},
+					UniqueSubject:                      true,
+				}
+				_, err = credService.CreateCredential(context.Background(), request)
+				require.NoError(ttt, err)
+				_, err = credService.CreateCredential(context.Background(), request)
+				assert.ErrorIs(ttt, err, credential.ErrActiveCredentialExists)
+
+				// and a batch may not contain two credentials for the same subject
+				request.Subject = "did:abc:batch"
+				_, err = credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{
+					Requests: []credential.CreateCredentialRequest{request, request},
+				})
+				assert.ErrorContains(ttt, err, "batch contains more than one credential")
+			})
+
+			tt.Run("Test Diff Credentials", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				createCred := func(data map[string]any, expiry string) string {
+					createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:456",
+						Data:                               data,
+						Expiry:                             expiry,
+					})
+					require.NoError(ttt, err)
+					return createdCred.ID
+				}
+				originalID := createCred(map[string]any{
+					"firstName": "Jack",
+					"address":   map[string]any{"city": "Paris", "country": "FR"},
+					"nickname":  "JD",
+				}, "")
+				reissuedID := createCred(map[string]any{
+					"firstName": "Jack",
+					"address":   map[string]any{"city": "Lyon", "country": "FR"},
+					"age":       42,
+				}, "2051-10-05T14:48:00Z")
+
+				diff, err := credService.DiffCredentials(context.Background(), originalID, reissuedID)
+				require.NoError(ttt, err)
+				assert.Equal(ttt, []credential.Change{
+					{Path: "credentialSubject.address.city", Type: credential.ChangeChanged, Before: "Paris", After: "Lyon"},
+					{Path: "credentialSubject.age", Type: credential.ChangeAdded, After: json.Number("42")},
+					{Path: "credentialSubject.nickname", Type: credential.ChangeRemoved, Before: "JD"},
+					{Path: "expirationDate", Type: credential.ChangeAdded, After: "2051-10-05T14:48:00Z"},
+				}, diff.Changes)
+
+				// a credential has no changes from itself
+				diff, err = credService.DiffCredentials(context.Background(), originalID, originalID)
+				require.NoError(ttt, err)
+				assert.Empty(ttt, diff.Changes)
+
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/diff?with=%s", originalID, reissuedID), nil)
+				w := httptest.NewRecorder()
+				credRouter.DiffCredentials(newRequestContextWithParams(w, req, map[string]string{"id": originalID}))
+				require.True(ttt, util.Is2xxResponse(w.Code), w.Body.String())
+				var resp router.DiffCredentialsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Len(ttt, resp.Changes, 4)
+
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/diff", originalID), nil)
+				w = httptest.NewRecorder()
+				credRouter.DiffCredentials(newRequestContextWithParams(w, req, map[string]string{"id": originalID}))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+
+				req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/diff?with=missing", originalID), nil)
+				w = httptest.NewRecorder()
+				credRouter.DiffCredentials(newRequestContextWithParams(w, req, map[string]string{"id": originalID}))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Credential Render Method", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				// an overlay capture bundle must be referenced by its id
+				_, err = schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:         "license schema",
+					Schema:       getLicenseApplicationSchema(),
+					RenderMethod: &credmodel.RenderMethod{Type: credmodel.OverlayCaptureBundle},
+				})
+				assert.ErrorContains(ttt, err, "must have an id")
+
+				renderMethod := &credmodel.RenderMethod{
+					Type:     credmodel.SVGRenderingTemplate,
+					Name:     "License Card",
+					Template: `<svg xmlns="http://www.w3.org/2000/svg"><text>{{ credentialSubject.licenseType }}</text><text>{{credentialSubject.missing}}</text></svg>`,
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:         "license schema",
+					Schema:       getLicenseApplicationSchema(),
+					RenderMethod: renderMethod,
+				})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, renderMethod, createdSchema.RenderMethod)
+
+				createCredential := func(schemaID string) router.CreateCredentialResponse {
+					requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						SchemaID:             schemaID,
+						Data:                 map[string]any{"licenseType": "Class <D> & M"},
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					credRouter.CreateCredential(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.CreateCredentialResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+
+				createResp := createCredential(createdSchema.ID)
+				assert.Equal(ttt, renderMethod, createResp.RenderMethod)
+
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+createResp.ID, nil)
+				w := httptest.NewRecorder()
+				credRouter.GetCredential(newRequestContextWithParams(w, req, map[string]string{"id": createResp.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var getResp router.GetCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&getResp))
+				assert.Equal(ttt, renderMethod, getResp.RenderMethod)
+
+				// the claims are substituted into the template, escaped for XML
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+createResp.ID+"/render", nil)
+				w = httptest.NewRecorder()
+				credRouter.GetCredentialRender(newRequestContextWithParams(w, req, map[string]string{"id": createResp.ID}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				assert.Equal(ttt, "image/svg+xml", w.Header().Get("Content-Type"))
+				assert.Equal(ttt, `<svg xmlns="http://www.w3.org/2000/svg"><text>Class &lt;D&gt; &amp; M</text><text></text></svg>`, w.Body.String())
+
+				// a credential without a render template cannot be rendered
+				plainSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:   "plain license schema",
+					Schema: getLicenseApplicationSchema(),
+				})
+				require.NoError(ttt, err)
+				plainResp := createCredential(plainSchema.ID)
+				assert.Nil(ttt, plainResp.RenderMethod)
+
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+plainResp.ID+"/render", nil)
+				w = httptest.NewRecorder()
+				credRouter.GetCredentialRender(newRequestContextWithParams(w, req, map[string]string{"id": plainResp.ID}))
+				assert.Equal(ttt, http.StatusNotFound, w.Code)
+			})
+
+			tt.Run("Test Imported Credential Status", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				issuerService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				// the issuer hosts its status lists outside the importing service
+				var fetches atomic.Int32
+				statusListServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fetches.Add(1)
+					statusList, err := issuerService.GetCredentialStatusList(r.Context(), credential.GetCredentialStatusListRequest{ID: strings.TrimPrefix(r.URL.Path, "/status/")})
+					if err != nil {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Header().Set("Content-Type", "application/jwt")
+					_, _ = w.Write([]byte(statusList.CredentialJWT.String()))
+				}))
+				defer statusListServer.Close()
+				config.SetStatusBase(statusListServer.URL + "/status")
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				issued, err := issuerService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+
+				importingService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10, ExternalStatusListCacheTTL: "1h"}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				importingRouter, err := router.NewCredentialRouter(importingService)
+				require.NoError(ttt, err)
+
+				requestValue := newRequestValue(ttt, router.ImportCredentialRequest{CredentialJWT: issued.CredentialJWT})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/imports", requestValue)
+				w := httptest.NewRecorder()
+				importingRouter.ImportCredential(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var importResp router.ImportCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&importResp))
+				assert.True(ttt, importResp.Imported)
+				assert.NotEqual(ttt, issued.ID, importResp.ID)
+				assert.Equal(ttt, issued.Credential.ID, importResp.Credential.ID)
+
+				getStatus := func(credRouter *router.CredentialRouter) router.GetCredentialStatusResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+importResp.ID+"/status", nil)
+					w := httptest.NewRecorder()
+					credRouter.GetCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": importResp.ID}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var statusResp router.GetCredentialStatusResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&statusResp))
+					return statusResp
+				}
+
+				statusResp := getStatus(importingRouter)
+				assert.Equal(ttt, credential.ExternalStatusSource, statusResp.Source)
+				assert.False(ttt, statusResp.StatusUnknown, statusResp.StatusUnknownReason)
+				assert.False(ttt, statusResp.Revoked)
+				assert.EqualValues(ttt, 1, fetches.Load())
+
+				// the status of an imported credential can only be changed by its issuer
+				_, err = importingService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: importResp.ID, Revoked: true})
+				assert.ErrorContains(ttt, err, "was imported")
+
+				_, err = issuerService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: issued.ID, Revoked: true})
+				require.NoError(ttt, err)
+
+				// the cached status list is used until its ttl expires
+				statusResp = getStatus(importingRouter)
+				assert.False(ttt, statusResp.Revoked)
+				assert.EqualValues(ttt, 1, fetches.Load())
+
+				uncachedService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				uncachedRouter, err := router.NewCredentialRouter(uncachedService)
+				require.NoError(ttt, err)
+				statusResp = getStatus(uncachedRouter)
+				assert.Equal(ttt, credential.ExternalStatusSource, statusResp.Source)
+				assert.False(ttt, statusResp.StatusUnknown, statusResp.StatusUnknownReason)
+				assert.True(ttt, statusResp.Revoked)
+				assert.EqualValues(ttt, 2, fetches.Load())
+
+				// a status list that cannot be fetched leaves the status unknown
+				statusListServer.Close()
+				statusResp = getStatus(uncachedRouter)
+				assert.True(ttt, statusResp.StatusUnknown)
+				assert.NotEmpty(ttt, statusResp.StatusUnknownReason)
+				assert.False(ttt, statusResp.Revoked)
+
+				// credentials which cannot be verified are not imported
+				requestValue = newRequestValue(ttt, router.ImportCredentialRequest{CredentialJWT: keyaccess.JWTPtr(issued.CredentialJWT.String() + "tampered")})
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/imports", requestValue)
+				w = httptest.NewRecorder()
+				importingRouter.ImportCredential(newRequestContext(w, req))
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Test Stale External Status List", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				issuerService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				// the issuer's status list host can go down, or lose the status list
+				var fetches atomic.Int32
+				var down, gone atomic.Bool
+				statusListServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fetches.Add(1)
+					if down.Load() {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					statusList, err := issuerService.GetCredentialStatusList(r.Context(), credential.GetCredentialStatusListRequest{ID: strings.TrimPrefix(r.URL.Path, "/status/")})
+					if err != nil || gone.Load() {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Header().Set("Content-Type", "application/jwt")
+					_, _ = w.Write([]byte(statusList.CredentialJWT.String()))
+				}))
+				defer statusListServer.Close()
+				config.SetStatusBase(statusListServer.URL + "/status")
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				issued, err := issuerService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+
+				serviceConfig := config.CredentialServiceConfig{
+					BatchCreateMaxItems:            10,
+					ExternalStatusListCacheTTL:     "1m",
+					ExternalStatusListMaxStaleness: "10m",
+					ExternalStatusListNotFoundTTL:  "10m",
+				}
+				importingService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				mockClock := clock.NewMock()
+				importingService.Clock = mockClock
+				imported, err := importingService.ImportCredential(context.Background(), credential.ImportCredentialRequest{CredentialJWT: issued.CredentialJWT})
+				require.NoError(ttt, err)
+				importingRouter, err := router.NewCredentialRouter(importingService)
+				require.NoError(ttt, err)
+
+				getStatus := func() router.GetCredentialStatusResponse {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/credentials/"+imported.ID+"/status", nil)
+					w := httptest.NewRecorder()
+					importingRouter.GetCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": imported.ID}))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var statusResp router.GetCredentialStatusResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&statusResp))
+					return statusResp
+				}
+				// waitForStatus checks the status until the background refresh of the status list is done
+				waitForStatus := func(done func(router.GetCredentialStatusResponse) bool) router.GetCredentialStatusResponse {
+					statusResp := getStatus()
+					for i := 0; i < 100 && !done(statusResp); i++ {
+						time.Sleep(10 * time.Millisecond)
+						statusResp = getStatus()
+					}
+					return statusResp
+				}
+
+				statusResp := getStatus()
+				assert.False(ttt, statusResp.StatusUnknown, statusResp.StatusUnknownReason)
+				assert.False(ttt, statusResp.Stale)
+				assert.False(ttt, statusResp.Revoked)
+				assert.EqualValues(ttt, 1, fetches.Load())
+
+				_, err = issuerService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: issued.ID, Revoked: true})
+				require.NoError(ttt, err)
+
+				// an outage shorter than the max staleness serves the expired status list while it is refreshed
+				down.Store(true)
+				mockClock.Add(2 * time.Minute)
+				statusResp = getStatus()
+				assert.False(ttt, statusResp.StatusUnknown, statusResp.StatusUnknownReason)
+				assert.True(ttt, statusResp.Stale)
+				assert.False(ttt, statusResp.Revoked)
+				assert.Eventually(ttt, func() bool { return fetches.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+				down.Store(false)
+				statusResp = waitForStatus(func(resp router.GetCredentialStatusResponse) bool { return !resp.Stale })
+				assert.False(ttt, statusResp.StatusUnknown, statusResp.StatusUnknownReason)
+				assert.False(ttt, statusResp.Stale)
+				assert.True(ttt, statusResp.Revoked)
+
+				// an outage longer than the max staleness leaves the status unknown
+				down.Store(true)
+				mockClock.Add(2 * time.Minute)
+				statusResp = getStatus()
+				assert.True(ttt, statusResp.Stale)
+				assert.True(ttt, statusResp.Revoked)
+				mockClock.Add(10 * time.Minute)
+				statusResp = waitForStatus(func(resp router.GetCredentialStatusResponse) bool { return resp.StatusUnknown })
+				assert.True(ttt, statusResp.StatusUnknown)
+				assert.False(ttt, statusResp.Stale)
+				assert.Contains(ttt, statusResp.StatusUnknownReason, "unexpected status 503")
+
+				// a status list which is not found is not fetched again until the not found ttl expires
+				down.Store(false)
+				gone.Store(true)
+				statusResp = getStatus()
+				assert.True(ttt, statusResp.StatusUnknown)
+				fetchesWhenGone := fetches.Load()
+				statusResp = getStatus()
+				assert.True(ttt, statusResp.StatusUnknown)
+				assert.Contains(ttt, statusResp.StatusUnknownReason, "recently not found")
+				assert.Equal(ttt, fetchesWhenGone, fetches.Load())
+
+				gone.Store(false)
+				mockClock.Add(11 * time.Minute)
+				statusResp = getStatus()
+				assert.False(ttt, statusResp.StatusUnknown, statusResp.StatusUnknownReason)
+				assert.True(ttt, statusResp.Revoked)
+				assert.Equal(ttt, fetchesWhenGone+1, fetches.Load())
+			})
+
+			tt.Run("Test Offline Verification", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				offlineResolver, err := didService.GetOfflineResolver()
+				require.NoError(ttt, err)
+				offlineService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10}, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(ttt, err)
+				offlineService.SetOfflineVerification(offlineResolver)
+
+				// credentials of controlled DIDs with status lists hosted by the service still verify
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				issued, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Jack"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+				verified, err := offlineService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: issued.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified, verified.Reason)
+
+				// so do data integrity credentials which only reference contexts with local copies
+				issuedWithProof, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{},
+					Revocable:                          true,
+					ProofFormat:                        credential.DataIntegrityProofFormat,
+				})
+				require.NoError(ttt, err)
+				verified, err = offlineService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{DataIntegrityCredential: issuedWithProof.Credential})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified, verified.Reason)
+
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: issued.ID, Revoked: true})
+				require.NoError(ttt, err)
+				verified, err = offlineService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: issued.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.False(ttt, verified.Verified)
+				assert.Contains(ttt, verified.Reason, "is revoked")
+
+				// DIDs which are neither controlled nor self describing are not resolved
+				_, err = offlineResolver.Resolve(context.Background(), "did:web:example.com")
+				assert.ErrorIs(ttt, err, verification.ErrOffline)
+				assert.ErrorContains(ttt, err, "offline mode: cannot resolve external resource")
+				_, err = offlineResolver.Resolve(context.Background(), issuerDID.DID.ID)
+				assert.NoError(ttt, err)
+
+				// the status list of an imported credential is not fetched from its issuer
+				var fetches atomic.Int32
+				statusListServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fetches.Add(1)
+					w.WriteHeader(http.StatusNotFound)
+				}))
+				defer statusListServer.Close()
+				config.SetStatusBase(statusListServer.URL + "/status")
+				external, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:789",
+					Data:                               map[string]any{"firstName": "Jill"},
+					Suspendable:                        true,
+				})
+				require.NoError(ttt, err)
+				imported, err := offlineService.ImportCredential(context.Background(), credential.ImportCredentialRequest{CredentialJWT: external.CredentialJWT})
+				require.NoError(ttt, err)
+				status, err := offlineService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: imported.ID})
+				require.NoError(ttt, err)
+				assert.True(ttt, status.StatusUnknown)
+				assert.Contains(ttt, status.StatusUnknownReason, "offline mode: cannot resolve external resource")
+				assert.Zero(ttt, fetches.Load())
+			})
+
+			tt.Run("Test Message Status", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, didService, schemaService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				messageStatus := credential.MessageStatus{
+					Size: 2,
+					Messages: []credential.StatusMessage{
+						{Status: "0x0", Message: "valid"},
+						{Status: "0x1", Message: "pending"},
+						{Status: "0x2", Message: "under review"},
+						{Status: "0x3", Message: "rejected"},
+					},
+				}
+				credentialRequest := func(subject string, messageStatus *credential.MessageStatus) credential.CreateCredentialRequest {
+					return credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            subject,
+						Data:                               map[string]any{"name": "Satoshi"},
+						MessageStatus:                      messageStatus,
+					}
+				}
+
+				// the messages must cover every value of the entry size
+				_, err = credService.CreateCredential(context.Background(), credentialRequest("did:abc:123", &credential.MessageStatus{Size: 2, Messages: messageStatus.Messages[:3]}))
+				assert.ErrorContains(ttt, err, "a status size of 2 requires 4 status messages")
+				revocableRequest := credentialRequest("did:abc:123", &messageStatus)
+				revocableRequest.Revocable = true
+				_, err = credService.CreateCredential(context.Background(), revocableRequest)
+				assert.ErrorContains(ttt, err, "at most one status")
+
+				first, err := credService.CreateCredential(context.Background(), credentialRequest("did:abc:123", &messageStatus))
+				require.NoError(ttt, err)
+				second, err := credService.CreateCredential(context.Background(), credentialRequest("did:abc:456", &messageStatus))
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "0x0", first.StatusValue)
+				entry := first.Credential.CredentialStatus.(map[string]any)
+				assert.Equal(ttt, string(credential.MessageStatusPurpose), entry["statusPurpose"])
+				assert.EqualValues(ttt, 2, entry["statusSize"])
+				assert.Equal(ttt, second.Credential.CredentialStatus.(map[string]any)["statusListCredential"], entry["statusListCredential"])
+
+				// credentials added to an existing list must describe it the same way
+				otherMessages := messageStatus
+				otherMessages.Messages = append([]credential.StatusMessage{{Status: "0x0", Message: "active"}}, messageStatus.Messages[1:]...)
+				_, err = credService.CreateCredential(context.Background(), credentialRequest("did:abc:789", &otherMessages))
+				assert.ErrorContains(ttt, err, "differ from those requested")
+
+				gotStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: first.ID})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "0x0", gotStatus.StatusValue)
+				assert.Equal(ttt, "valid", gotStatus.StatusMessage)
+
+				// the status value can be given as a value or as a message
+				updated, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: first.ID, StatusValue: "pending"})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "0x1", updated.StatusValue)
+				assert.Equal(ttt, "pending", updated.StatusMessage)
+				batchUpdated, err := credService.BatchUpdateCredentialStatus(context.Background(), credential.BatchUpdateCredentialStatusRequest{
+					Requests: []credential.UpdateCredentialStatusRequest{{ID: second.ID, StatusValue: "0x3"}},
+				})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "rejected", batchUpdated.CredentialStatuses[0].StatusMessage)
+
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: first.ID, StatusValue: "0x7"})
+				assert.ErrorContains(ttt, err, "not one of the status list's messages")
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: first.ID, Revoked: true})
+				assert.ErrorContains(ttt, err, "only its status value can be updated")
+
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("https://ssi-service.com/v1/credentials/%s/status", first.ID), nil)
+				w := httptest.NewRecorder()
+				c := newRequestContextWithParams(w, req, map[string]string{"id": first.ID})
+				credRouter.GetCredentialStatus(c)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var statusResp router.GetCredentialStatusResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&statusResp))
+				assert.Equal(ttt, router.GetCredentialStatusResponse{StatusValue: "0x1", StatusMessage: "pending", LifecycleState: credmodel.LifecycleActive}, statusResp)
+
+				// verification reads the current status from the status list
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: first.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.True(ttt, verified.Verified)
+				assert.Equal(ttt, "0x1", verified.StatusValue)
+				assert.Equal(ttt, "pending", verified.StatusMessage)
+				verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: second.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, "rejected", verified.StatusMessage)
+
+				// status values are only for credentials using a message status list
+				revocable, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:123",
+					Data:                               map[string]any{"name": "Satoshi"},
+					Revocable:                          true,
+				})
+				require.NoError(ttt, err)
+				_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: revocable.ID, StatusValue: "0x1"})
+				assert.ErrorContains(ttt, err, "does not use a message status list")
+				verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: revocable.CredentialJWT})
+				require.NoError(ttt, err)
+				assert.Empty(ttt, verified.StatusMessage)
+
+				// entries of two bits halve the number of indexes of the list
+				capacity, err := credService.GetStatusListCapacity(context.Background(), credential.GetStatusListCapacityRequest{Issuer: issuerDID.DID.ID, Purpose: credential.MessageStatusPurpose})
+				require.NoError(ttt, err)
+				require.Len(ttt, capacity.StatusLists, 1)
+				assert.Equal(ttt, 2, capacity.StatusLists[0].StatusSize)
+				assert.Equal(ttt, 2, capacity.StatusLists[0].Allocated)
+				assert.Equal(ttt, 8*1024*16/2-1-1-2, capacity.StatusLists[0].Remaining)
+			})
+
+			tt.Run("Test Ensure Status List", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				ensureRequest := credential.EnsureStatusListRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Purpose:                            statussdk.StatusRevocation,
+				}
+
+				created, err := credService.EnsureStatusList(context.Background(), ensureRequest)
+				require.NoError(ttt, err)
+				assert.True(ttt, created.Created)
+				assert.NotEmpty(ttt, created.StatusListCredentialID)
+
+				// ensuring the status list again finds it
+				found, err := credService.EnsureStatusList(context.Background(), ensureRequest)
+				require.NoError(ttt, err)
+				assert.False(ttt, found.Created)
+				assert.Equal(ttt, created.StatusListCredentialID, found.StatusListCredentialID)
+
+				capacity, err := credService.GetStatusListCapacity(context.Background(), credential.GetStatusListCapacityRequest{Issuer: issuerDID.DID.ID, Purpose: statussdk.StatusRevocation})
+				require.NoError(ttt, err)
+				require.Len(ttt, capacity.StatusLists, 1)
+				assert.Zero(ttt, capacity.StatusLists[0].Allocated)
+
+				// credentials consume the indexes of the pre-created list
+				statusListIndexes := make(map[string]bool)
+				for i := 0; i < 2; i++ {
+					createdCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            "did:abc:123",
+						Data:                               map[string]any{"name": "Satoshi"},
+						Revocable:                          true,
+					})
+					require.NoError(ttt, err)
+					credentialStatus := createdCred.Credential.CredentialStatus.(map[string]any)
+					assert.Equal(ttt, created.StatusListCredentialID, credentialStatus["statusListCredential"])
+					statusListIndexes[credentialStatus["statusListIndex"].(string)] = true
+				}
+				assert.Len(ttt, statusListIndexes, 2)
+
+				capacity, err = credService.GetStatusListCapacity(context.Background(), credential.GetStatusListCapacityRequest{Issuer: issuerDID.DID.ID, Purpose: statussdk.StatusRevocation})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 2, capacity.StatusLists[0].Allocated)
+
+				// message status lists are created with the status messages of their first credential
+				ensureRequest.Purpose = credential.MessageStatusPurpose
+				_, err = credService.EnsureStatusList(context.Background(), ensureRequest)
+				assert.ErrorContains(ttt, err, "unsupported status purpose")
+			})
+
+			tt.Run("Test Concurrent Subject Uniqueness", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+
+				const attempts = 5
+				var wg sync.WaitGroup
+				errs := make([]error, attempts)
+				for i := 0; i < attempts; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						_, errs[i] = credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+							Issuer:                             issuerDID.DID.ID,
+							FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+							Subject:                            "did:abc:456",
+							Data:                               map[string]any{"attempt": i},
+							UniqueSubject:                      true,
+						})
+					}(i)
+				}
+				wg.Wait()
+
+				created := 0
+				for _, err := range errs {
+					if err == nil {
+						created++
+						continue
+					}
+					assert.ErrorIs(ttt, err, credential.ErrActiveCredentialExists)
+				}
+				assert.Equal(ttt, 1, created)
+			})
+
+			tt.Run("Test Canonical Stored Credentials", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(ttt, err)
+				created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data: map[string]any{
+						"name":    "Satoshi",
+						"address": map[string]any{"street": "1 Main St", "city": "Springfield"},
+						"scores":  []any{3, 1.5, map[string]any{"b": 2, "a": 1}},
+					},
+				})
+				require.NoError(ttt, err)
+
+				entries, err := db.ReadPrefix(context.Background(), "credential", created.ID)
+				require.NoError(ttt, err)
+				require.Len(ttt, entries, 1)
+				for key, entry := range entries {
+					// the stored entry is canonical, and carries the hash of its credential
+					canonical, err := util.CanonicalJSON(json.RawMessage(entry))
+					require.NoError(ttt, err)
+					assert.Equal(ttt, string(canonical), string(entry))
+					var stored credential.StoredCredential
+					require.NoError(ttt, json.Unmarshal(entry, &stored))
+					contentHash, err := util.ContentHash(stored.Credential)
+					require.NoError(ttt, err)
+					assert.Equal(ttt, contentHash, stored.ContentHash)
+					intact, err := stored.HasIntactContent()
+					require.NoError(ttt, err)
+					assert.True(ttt, intact)
+
+					// entries stored before, without a content hash and with their keys in another order, still read
+					var legacy map[string]any
+					require.NoError(ttt, json.Unmarshal(entry, &legacy))
+					delete(legacy, "contentHash")
+					legacyBytes, err := json.MarshalIndent(legacy, "", "  ")
+					require.NoError(ttt, err)
+					require.NoError(ttt, db.Write(context.Background(), "credential", key, legacyBytes))
+				}
+
+				gotCred, err := credService.GetCredential(context.Background(), credential.GetCredentialRequest{ID: created.ID})
+				require.NoError(ttt, err)
+				assert.Equal(ttt, created.ID, gotCred.ID)
+				assert.Equal(ttt, created.CredentialJWT.String(), gotCred.CredentialJWT.String())
 			})
 		})
 	}
 }
+
+func TestCredentialStatusConflict(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+	db, err := storage.NewStorage(storage.Redis,
+		storage.Option{ID: storage.RedisAddressOption, Option: redisServer.Addr()},
+		storage.Option{ID: storage.PasswordOption, Option: "test-password"},
+		storage.Option{ID: storage.RedisConflictRetriesOption, Option: false},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	keyStoreService, _ := testKeyStoreService(t, db)
+	didService, _ := testDIDService(t, db, keyStoreService, nil)
+	schemaService := testSchemaService(t, db, keyStoreService, didService)
+	// sets the service path of credentials
+	_ = testCredentialRouter(t, db, keyStoreService, didService, schemaService)
+	credService := testCredentialService(t, db, keyStoreService, didService, schemaService)
+
+	issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+	require.NoError(t, err)
+	createCredential := func() *credential.CreateCredentialResponse {
+		created, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+			Issuer:                             issuerDID.DID.ID,
+			FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+			Subject:                            "did:abc:456",
+			Data:                               map[string]any{"firstName": "Jack"},
+			Revocable:                          true,
+		})
+		require.NoError(t, err)
+		return created
+	}
+	first, second := createCredential(), createCredential()
+
+	// the status of the second credential is updated while the status of the first one is, in the same status list
+	interleaved := &interleavedStorage{ServiceStorage: db, interleave: func() {
+		_, err := credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: second.ID, Revoked: true})
+		require.NoError(t, err)
+	}}
+	conflictingService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 10, BatchUpdateStatusMaxItems: 10}, interleaved, keyStoreService, didService.GetResolver(), schemaService)
+	require.NoError(t, err)
+	credRouter, err := router.NewCredentialRouter(conflictingService)
+	require.NoError(t, err)
+
+	requestValue := newRequestValue(t, router.UpdateCredentialStatusRequest{Revoked: true})
+	req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/"+first.ID+"/status", requestValue)
+	w := httptest.NewRecorder()
+	credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": first.ID}))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var errResp struct {
+		Code      string                          `json:"code"`
+		Retryable bool                            `json:"retryable"`
+		Contended []credential.StatusListResource `json:"contended"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, "CONFLICT", errResp.Code)
+	assert.True(t, errResp.Retryable)
+	assert.Equal(t, []credential.StatusListResource{{Issuer: issuerDID.DID.ID, Purpose: string(statussdk.StatusRevocation)}}, errResp.Contended)
+
+	// the conflicting update was not applied, while the concurrent one was
+	firstStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: first.ID})
+	require.NoError(t, err)
+	assert.False(t, firstStatus.Revoked)
+	secondStatus, err := credService.GetCredentialStatus(context.Background(), credential.GetCredentialStatusRequest{ID: second.ID})
+	require.NoError(t, err)
+	assert.True(t, secondStatus.Revoked)
+
+	// the update succeeds once retried
+	_, err = conflictingService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: first.ID, Revoked: true})
+	assert.NoError(t, err)
+}
+
+func TestStatusListRegenerationBoundedMemory(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(tt *testing.T) {
+			db := test.ServiceStorage(tt)
+			require.NotEmpty(tt, db)
+
+			keyStoreService, _ := testKeyStoreService(tt, db)
+			didService, _ := testDIDService(tt, db, keyStoreService, nil)
+			schemaService := testSchemaService(tt, db, keyStoreService, didService)
+			// sets the service path of credentials
+			_ = testCredentialRouter(tt, db, keyStoreService, didService, schemaService)
+			recorder := &readRecordingStorage{ServiceStorage: db}
+			credService, err := credential.NewCredentialService(config.CredentialServiceConfig{BatchCreateMaxItems: 1000, BatchUpdateStatusMaxItems: 10}, recorder, keyStoreService, didService.GetResolver(), schemaService)
+			require.NoError(tt, err)
+
+			issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+			require.NoError(tt, err)
+			var created []credmodel.Container
+			for batch := 0; batch < 3; batch++ {
+				requests := make([]credential.CreateCredentialRequest, 0, 1000)
+				for i := 0; i < 1000; i++ {
+					requests = append(requests, credential.CreateCredentialRequest{
+						Issuer:                             issuerDID.DID.ID,
+						FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:                            fmt.Sprintf("did:abc:%d", batch*1000+i),
+						Data:                               map[string]any{"firstName": "Jack"},
+						Revocable:                          true,
+					})
+				}
+				resp, err := credService.BatchCreateCredentials(context.Background(), credential.BatchCreateCredentialsRequest{Requests: requests})
+				require.NoError(tt, err)
+				created = append(created, resp.Credentials...)
+			}
+			statusListURI := created[0].Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+			statusListID := statusListURI[strings.LastIndex(statusListURI, "/")+1:]
+
+			// every seventh credential is stored as revoked, along with its reference, without regenerating the status list
+			// each time
+			referenceNamespace := "status-list-reference-" + statusListID
+			setRevoked := func(namespace, key string, value []byte) {
+				var stored map[string]any
+				require.NoError(tt, json.Unmarshal(value, &stored))
+				stored["revoked"] = true
+				storedBytes, err := json.Marshal(stored)
+				require.NoError(tt, err)
+				require.NoError(tt, db.Write(context.Background(), namespace, key, storedBytes))
+			}
+			var revoked []credsdk.VerifiableCredential
+			for i := 0; i < len(created); i += 7 {
+				entries, err := db.ReadPrefix(context.Background(), "credential", created[i].ID)
+				require.NoError(tt, err)
+				for key, entry := range entries {
+					setRevoked("credential", key, entry)
+				}
+				reference, err := db.Read(context.Background(), referenceNamespace, created[i].ID)
+				require.NoError(tt, err)
+				require.NotEmpty(tt, reference)
+				setRevoked(referenceNamespace, created[i].ID, reference)
+				revoked = append(revoked, *created[i].Credential)
+			}
+
+			assertEncodedList := func(expected []credsdk.VerifiableCredential) {
+				generated, err := statussdk.GenerateStatusList2021Credential(statusListURI, issuerDID.DID.ID, statussdk.StatusRevocation, expected)
+				require.NoError(tt, err)
+				statusList, err := credService.GetCredentialStatusList(context.Background(), credential.GetCredentialStatusListRequest{ID: statusListID})
+				require.NoError(tt, err)
+				assert.Equal(tt, generated.CredentialSubject["encodedList"], statusList.Credential.CredentialSubject["encodedList"])
+			}
+
+			// the credentials of the status list are read through its references
+			target := created[1]
+			credentialReads := recorder.prefixReads("credential")
+			_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: target.ID, Revoked: true})
+			require.NoError(tt, err)
+			assertEncodedList(append(revoked, *target.Credential))
+			assert.Zero(tt, recorder.fullReads("credential"))
+			assert.Zero(tt, recorder.pages("credential"))
+			// the references are paged through, and only the credentials whose bit is set are read
+			assert.Zero(tt, recorder.fullReads(referenceNamespace))
+			assert.NotZero(tt, recorder.pages(referenceNamespace))
+			assert.Less(tt, recorder.prefixReads("credential")-credentialReads, 2*len(revoked))
+
+			// status lists created before references were recorded are regenerated from a page of credentials at a time
+			require.NoError(tt, db.Delete(context.Background(), "status-list-indexed", statusListID))
+			_, err = credService.UpdateCredentialStatus(context.Background(), credential.UpdateCredentialStatusRequest{ID: target.ID})
+			require.NoError(tt, err)
+			assertEncodedList(revoked)
+			assert.Zero(tt, recorder.fullReads("credential"))
+			assert.NotZero(tt, recorder.pages("credential"))
+		})
+	}
+}
+
+// displayNamesFunc looks up display names with a function, so that tests can observe the lookups.
+type displayNamesFunc func(ctx context.Context, ids []string) (map[string]string, error)
+
+func (f displayNamesFunc) GetDisplayNames(ctx context.Context, ids []string) (map[string]string, error) {
+	return f(ctx, ids)
+}
+
+// unassertedResolver resolves DID documents without their assertionMethod relationship, like those of DID methods
+// which don't model relationships.
+type unassertedResolver struct {
+	resolution.Resolver
+}
+
+func (r unassertedResolver) Resolve(ctx context.Context, id string, opts ...resolution.Option) (*resolution.Result, error) {
+	resolved, err := r.Resolver.Resolve(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	unasserted := *resolved
+	unasserted.Document.AssertionMethod = nil
+	return &unasserted, nil
+}
+
+// interleavedStorage runs a function before the first transaction it executes, within that transaction, so that tests
+// can make it conflict with a concurrent update.
+type interleavedStorage struct {
+	storage.ServiceStorage
+	interleave func()
+	once       sync.Once
+}
+
+func (s *interleavedStorage) Execute(ctx context.Context, businessLogicFunc storage.BusinessLogicFunc, watchKeys []storage.WatchKey) (any, error) {
+	return s.ServiceStorage.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		s.once.Do(s.interleave)
+		return businessLogicFunc(ctx, tx)
+	}, watchKeys)
+}
+
+// cancelingStorage cancels a context once a transaction it executes writes to a namespace, so that tests can abort
+// transactions after part of their work is done.
+type cancelingStorage struct {
+	storage.ServiceStorage
+	namespace string
+	cancel    context.CancelFunc
+}
+
+func (s *cancelingStorage) Execute(ctx context.Context, businessLogicFunc storage.BusinessLogicFunc, watchKeys []storage.WatchKey) (any, error) {
+	return s.ServiceStorage.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		return businessLogicFunc(ctx, cancelingTx{Tx: tx, storage: s})
+	}, watchKeys)
+}
+
+type cancelingTx struct {
+	storage.Tx
+	storage *cancelingStorage
+}
+
+func (tx cancelingTx) Write(ctx context.Context, namespace, key string, value []byte) error {
+	if err := tx.Tx.Write(ctx, namespace, key, value); err != nil {
+		return err
+	}
+	if namespace == tx.storage.namespace {
+		tx.storage.cancel()
+	}
+	return nil
+}
+
+// readRecordingStorage records the pages read from each namespace, and the reads of whole namespaces, so that tests
+// can check that reads are bounded.
+type readRecordingStorage struct {
+	storage.ServiceStorage
+	mu         sync.Mutex
+	pageReads  map[string]int
+	fullRead   map[string]int
+	prefixRead map[string]int
+}
+
+func (s *readRecordingStorage) record(namespace string, full bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pageReads == nil {
+		s.pageReads, s.fullRead = make(map[string]int), make(map[string]int)
+	}
+	if full {
+		s.fullRead[namespace]++
+	} else {
+		s.pageReads[namespace]++
+	}
+}
+
+func (s *readRecordingStorage) pages(namespace string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pageReads[namespace]
+}
+
+func (s *readRecordingStorage) fullReads(namespace string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fullRead[namespace]
+}
+
+func (s *readRecordingStorage) ReadAll(ctx context.Context, namespace string) (map[string][]byte, error) {
+	s.record(namespace, true)
+	return s.ServiceStorage.ReadAll(ctx, namespace)
+}
+
+func (s *readRecordingStorage) ReadAllKeys(ctx context.Context, namespace string) ([]string, error) {
+	s.record(namespace, true)
+	return s.ServiceStorage.ReadAllKeys(ctx, namespace)
+}
+
+func (s *readRecordingStorage) ReadPage(ctx context.Context, namespace string, pageToken string, pageSize int) (map[string][]byte, string, error) {
+	s.record(namespace, pageSize == -1)
+	return s.ServiceStorage.ReadPage(ctx, namespace, pageToken, pageSize)
+}
+
+func (s *readRecordingStorage) ReadPrefix(ctx context.Context, namespace, prefix string) (map[string][]byte, error) {
+	s.mu.Lock()
+	if s.prefixRead == nil {
+		s.prefixRead = make(map[string]int)
+	}
+	s.prefixRead[namespace]++
+	s.mu.Unlock()
+	return s.ServiceStorage.ReadPrefix(ctx, namespace, prefix)
+}
+
+func (s *readRecordingStorage) prefixReads(namespace string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prefixRead[namespace]
+}
+
+// recordingAnalyticsSink records the analytics events written to it.
+type recordingAnalyticsSink struct {
+	mu     sync.Mutex
+	events []analytics.Event
+}
+
+func (s *recordingAnalyticsSink) Write(_ context.Context, event analytics.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// wait returns the events once n of them are written, which happens in the background.
+func (s *recordingAnalyticsSink) wait(t *testing.T, n int) []analytics.Event {
+	var events []analytics.Event
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		events = append([]analytics.Event(nil), s.events...)
+		return len(events) >= n
+	}, time.Second, 10*time.Millisecond)
+	return events
+}
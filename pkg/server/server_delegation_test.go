@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestDelegationAPI(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Run("Test Delegated Issuance", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				credService := testCredentialService(tt, db, keyStoreService, didService, schemaService)
+				didRouter, err := router.NewDIDRouter(didService)
+				require.NoError(tt, err)
+
+				createDID := func() *did.CreateDIDResponse {
+					created, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+						Method:  didsdk.KeyMethod,
+						KeyType: crypto.Ed25519,
+					})
+					require.NoError(tt, err)
+					return created
+				}
+				parentDID := createDID()
+				subsidiaryDID := createDID()
+				subsidiaryKey := subsidiaryDID.DID.VerificationMethod[0].ID
+
+				issueOnBehalfOfParent := func() (*credential.CreateCredentialResponse, error) {
+					return credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+						Issuer:                             parentDID.DID.ID,
+						FullyQualifiedVerificationMethodID: subsidiaryKey,
+						Subject:                            "did:abc:456",
+						Data:                               map[string]any{"firstName": "Satoshi"},
+					})
+				}
+
+				// without a delegation, the subsidiary cannot sign on behalf of the parent
+				_, err = issueOnBehalfOfParent()
+				assert.ErrorContains(tt, err, "does not match credential issuer")
+
+				// the parent issues a delegation credential to the subsidiary
+				delegationCred, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             parentDID.DID.ID,
+					FullyQualifiedVerificationMethodID: parentDID.DID.VerificationMethod[0].ID,
+					Subject:                            subsidiaryDID.DID.ID,
+					Types:                              []string{verification.DelegationCredentialType},
+					Data:                               map[string]any{"scope": "issuance"},
+				})
+				require.NoError(tt, err)
+
+				createDelegation := func(delegator string, delegationJWT keyaccess.JWT) *httptest.ResponseRecorder {
+					value := newRequestValue(tt, router.CreateDelegationRequest{DelegationJWT: delegationJWT})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/dids/key/"+delegator+"/delegations", value)
+					w := httptest.NewRecorder()
+					didRouter.CreateDelegation(newRequestContextWithParams(w, req, map[string]string{"method": "key", "id": delegator}))
+					return w
+				}
+
+				// a delegation must be issued by the DID of the path
+				w := createDelegation(subsidiaryDID.DID.ID, *delegationCred.CredentialJWT)
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+
+				w = createDelegation(parentDID.DID.ID, *delegationCred.CredentialJWT)
+				require.Equal(tt, http.StatusCreated, w.Code, w.Body.String())
+				var delegation router.DelegationResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&delegation))
+				assert.Equal(tt, parentDID.DID.ID, delegation.Delegator)
+				assert.Equal(tt, subsidiaryDID.DID.ID, delegation.Delegate)
+
+				// the subsidiary now signs on behalf of the parent, and the credential verifies
+				created, err := issueOnBehalfOfParent()
+				require.NoError(tt, err)
+				headers, token, _, err := integrity.ParseVerifiableCredentialFromJWT(created.CredentialJWT.String())
+				require.NoError(tt, err)
+				assert.Equal(tt, parentDID.DID.ID, token.Issuer())
+				assert.Equal(tt, subsidiaryKey, headers.KeyID())
+				delegationClaim, ok := token.Get(verification.DelegationClaim)
+				assert.True(tt, ok)
+				assert.Equal(tt, delegationCred.CredentialJWT.String(), delegationClaim)
+
+				verified, err := credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: created.CredentialJWT})
+				require.NoError(tt, err)
+				assert.True(tt, verified.Verified, verified.Reason)
+
+				// a credential of another DID cannot be passed off as delegated by the parent
+				otherDID := createDID()
+				forged, err := credService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             otherDID.DID.ID,
+					FullyQualifiedVerificationMethodID: otherDID.DID.VerificationMethod[0].ID,
+					Subject:                            "did:abc:456",
+					Data:                               map[string]any{"firstName": "Satoshi"},
+				})
+				require.NoError(tt, err)
+				_, _, forgedCred, err := integrity.ParseVerifiableCredentialFromJWT(forged.CredentialJWT.String())
+				require.NoError(tt, err)
+				forgedCred.Issuer = parentDID.DID.ID
+				otherKey, err := keyStoreService.GetKey(context.Background(), keystore.GetKeyRequest{ID: otherDID.DID.VerificationMethod[0].ID})
+				require.NoError(tt, err)
+				otherAccess, err := keyaccess.NewJWKKeyAccess(otherDID.DID.ID, otherKey.ID, otherKey.Key)
+				require.NoError(tt, err)
+				forgedJWT, err := otherAccess.SignVerifiableCredentialWithClaims(*forgedCred, map[string]any{verification.DelegationClaim: delegationCred.CredentialJWT.String()})
+				require.NoError(tt, err)
+				verified, err = credService.VerifyCredential(context.Background(), credential.VerifyCredentialRequest{CredentialJWT: forgedJWT})
+				require.NoError(tt, err)
+				assert.False(tt, verified.Verified)
+
+				// once the delegation is revoked, the subsidiary can no longer sign on behalf of the parent
+				req := httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/dids/key/"+parentDID.DID.ID+"/delegations/"+delegation.ID, nil)
+				w = httptest.NewRecorder()
+				didRouter.RevokeDelegation(newRequestContextWithParams(w, req, map[string]string{"method": "key", "id": parentDID.DID.ID, "delegationId": delegation.ID}))
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+
+				_, err = issueOnBehalfOfParent()
+				assert.ErrorContains(tt, err, "does not match credential issuer")
+
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/dids/key/"+parentDID.DID.ID+"/delegations", nil)
+				w = httptest.NewRecorder()
+				didRouter.ListDelegations(newRequestContextWithParams(w, req, map[string]string{"method": "key", "id": parentDID.DID.ID}))
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				var listed router.ListDelegationsResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&listed))
+				require.Len(tt, listed.Delegations, 1)
+				assert.True(tt, listed.Delegations[0].Revoked)
+
+				// revoking a delegation of another DID is not found
+				req = httptest.NewRequest(http.MethodDelete, "https://ssi-service.com/v1/dids/key/"+subsidiaryDID.DID.ID+"/delegations/"+delegation.ID, nil)
+				w = httptest.NewRecorder()
+				didRouter.RevokeDelegation(newRequestContextWithParams(w, req, map[string]string{"method": "key", "id": subsidiaryDID.DID.ID, "delegationId": delegation.ID}))
+				assert.Equal(tt, http.StatusNotFound, w.Code)
+			})
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"crypto/ed25519"
 	_ "embed"
 	"fmt"
 	"net/http"
@@ -13,6 +15,7 @@ import (
 	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/ion"
+	"github.com/TBD54566975/ssi-sdk/did/key"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,7 +24,10 @@ import (
 
 	"github.com/tbd54566975/ssi-service/internal/util"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/common"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 //go:embed testdata/basic_did_resolution.json
@@ -102,6 +108,118 @@ func TestDIDAPI(t *testing.T) {
 				assert.Contains(tt, resp.DID.ID, didsdk.KeyMethod)
 			})
 
+			t.Run("Test Create DID By Method: Key From Seed", func(tt *testing.T) {
+				seed := make([]byte, 32)
+				for i := range seed {
+					seed[i] = byte(i + 1)
+				}
+
+				createDID := func(db storage.ServiceStorage, method string, request router.CreateDIDByMethodRequest) *httptest.ResponseRecorder {
+					_, keyStoreService, _ := testKeyStore(tt, db)
+					didRouter, _ := testDIDRouter(tt, db, keyStoreService, []string{"key", "web"}, nil)
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/dids/"+method, newRequestValue(tt, request))
+					w := httptest.NewRecorder()
+					didRouter.CreateDIDByMethod(newRequestContextWithParams(w, req, map[string]string{"method": method}))
+					return w
+				}
+
+				// the same seed creates the same DID in another environment
+				for _, keyType := range []crypto.KeyType{crypto.Ed25519, crypto.X25519, crypto.SECP256k1, crypto.P256} {
+					var ids []string
+					for i := 0; i < 2; i++ {
+						w := createDID(test.ServiceStorage(tt), "key", router.CreateDIDByMethodRequest{KeyType: keyType, Seed: seed})
+						require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+						var resp router.CreateDIDByMethodResponse
+						require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+						ids = append(ids, resp.DID.ID)
+					}
+					assert.Equal(tt, ids[0], ids[1], keyType)
+				}
+
+				// the key is derived from the seed and stored as usual
+				db := test.ServiceStorage(tt)
+				w := createDID(db, "key", router.CreateDIDByMethodRequest{KeyType: crypto.Ed25519, Seed: seed})
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var resp router.CreateDIDByMethodResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				wantDID, err := key.CreateDIDKey(crypto.Ed25519, ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+				require.NoError(tt, err)
+				assert.Equal(tt, wantDID.String(), resp.DID.ID)
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				gotKey, err := keyStoreService.GetKey(context.Background(), keystore.GetKeyRequest{ID: resp.DID.VerificationMethod[0].ID})
+				require.NoError(tt, err)
+				assert.Equal(tt, ed25519.NewKeyFromSeed(seed), gotKey.Key)
+
+				// seeds of the wrong length are rejected
+				w = createDID(test.ServiceStorage(tt), "key", router.CreateDIDByMethodRequest{KeyType: crypto.P384, Seed: seed})
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				assert.Contains(tt, w.Body.String(), "must be 48 bytes")
+
+				// as are seeds of key types and methods keys cannot be derived for
+				w = createDID(test.ServiceStorage(tt), "key", router.CreateDIDByMethodRequest{KeyType: crypto.RSA, Seed: seed})
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				w = createDID(test.ServiceStorage(tt), "web", router.CreateDIDByMethodRequest{KeyType: crypto.Ed25519, Seed: seed, Options: did.CreateWebDIDOptions{DIDWebID: "did:web:example.com"}})
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+			})
+
+			t.Run("Test Resolve Verification Method", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				_, keyStoreService, _ := testKeyStore(tt, db)
+				didRouter, _ := testDIDRouter(tt, db, keyStoreService, []string{"key"}, nil)
+				resolve := func(id string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/dids/verification-methods/"+url.PathEscape(id), nil)
+					w := httptest.NewRecorder()
+					didRouter.ResolveVerificationMethod(newRequestContextWithParams(w, req, map[string]string{"id": id}))
+					return w
+				}
+
+				// the key of a controlled DID is read from the key store
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/dids/key", newRequestValue(tt, router.CreateDIDByMethodRequest{KeyType: crypto.SECP256k1}))
+				w := httptest.NewRecorder()
+				didRouter.CreateDIDByMethod(newRequestContextWithParams(w, req, map[string]string{"method": "key"}))
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+				var createResp router.CreateDIDByMethodResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&createResp))
+				controlledID := createResp.DID.VerificationMethod[0].ID
+				gotKey, err := keyStoreService.GetKeyDetails(context.Background(), keystore.GetKeyDetailsRequest{ID: controlledID})
+				require.NoError(tt, err)
+
+				w = resolve(controlledID)
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				var resp router.ResolveVerificationMethodResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(tt, controlledID, resp.ID)
+				assert.Equal(tt, crypto.SECP256k1, resp.KeyType)
+				assert.Equal(tt, gotKey.PublicKeyJWK, resp.PublicKeyJWK)
+
+				// the key of any other DID is read from its resolved document
+				publicKey, _, err := crypto.GenerateEd25519Key()
+				require.NoError(tt, err)
+				externalDID, err := key.CreateDIDKey(crypto.Ed25519, publicKey)
+				require.NoError(tt, err)
+				expanded, err := externalDID.Expand()
+				require.NoError(tt, err)
+				externalID := expanded.VerificationMethod[0].ID
+
+				w = resolve(externalID)
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				resp = router.ResolveVerificationMethodResponse{}
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(tt, externalID, resp.ID)
+				assert.Equal(tt, crypto.Ed25519, resp.KeyType)
+				wantJWK, err := jwx.PublicKeyToPublicKeyJWK(externalID, publicKey)
+				require.NoError(tt, err)
+				assert.Equal(tt, *wantJWK, resp.PublicKeyJWK)
+
+				// IDs without a fragment are rejected, and fragments the document does not have are not found
+				w = resolve(externalDID.String())
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				w = resolve(externalDID.String() + "#missing")
+				assert.Equal(tt, http.StatusNotFound, w.Code)
+			})
+
 			t.Run("Test Create DID By Method: Web", func(tt *testing.T) {
 				db := test.ServiceStorage(tt)
 				require.NotEmpty(tt, db)
@@ -810,6 +928,84 @@ func TestDIDAPI(t *testing.T) {
 				assert.Len(tt, knownDIDs, 0)
 			})
 
+			t.Run("Test List Controlled DIDs", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				_, keyStoreService, _ := testKeyStore(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil, "key", "web")
+
+				keyDIDs := make([]string, 0, 2)
+				var revokedKeyID string
+				for i := 0; i < 2; i++ {
+					created, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+						Method:  didsdk.KeyMethod,
+						KeyType: crypto.Ed25519,
+					})
+					require.NoError(tt, err)
+					keyDIDs = append(keyDIDs, created.DID.ID)
+					if i == 0 {
+						revokedKeyID = created.DID.VerificationMethod[0].ID
+					}
+				}
+
+				gock.New("https://example.com").
+					Get("/.well-known/did.json").
+					Reply(404)
+				defer gock.Off()
+				webDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.WebMethod,
+					KeyType: crypto.Ed25519,
+					Options: did.CreateWebDIDOptions{DIDWebID: "did:web:example.com"},
+				})
+				require.NoError(tt, err)
+
+				// revoke the only key of the first did:key
+				revokedDID := keyDIDs[0]
+				require.NoError(tt, keyStoreService.RevokeKey(context.Background(), keystore.RevokeKeyRequest{ID: revokedKeyID}))
+
+				all, err := didService.ListControlledDIDs(context.Background(), nil)
+				require.NoError(tt, err)
+				require.Len(tt, all.DIDs, 3)
+				assert.Empty(tt, all.NextPageToken)
+				for _, controlled := range all.DIDs {
+					// the first verification method holds the signing key; did:key also derives a key agreement key,
+					// whose private key is not stored
+					require.NotEmpty(tt, controlled.VerificationMethods)
+					signingMethod := controlled.VerificationMethods[0]
+					switch controlled.ID {
+					case revokedDID:
+						assert.False(tt, controlled.CanSign)
+						assert.Equal(tt, revokedKeyID, signingMethod.ID)
+						assert.Equal(tt, did.KeyStatusRevoked, signingMethod.Status)
+						assert.NotEmpty(tt, signingMethod.RevokedAt)
+					case webDID.DID.ID:
+						assert.Equal(tt, didsdk.WebMethod, controlled.Method)
+						assert.True(tt, controlled.CanSign)
+						assert.Equal(tt, did.KeyStatusUsable, signingMethod.Status)
+					default:
+						assert.Equal(tt, keyDIDs[1], controlled.ID)
+						assert.Equal(tt, didsdk.KeyMethod, controlled.Method)
+						assert.True(tt, controlled.CanSign)
+						assert.Equal(tt, did.KeyStatusUsable, signingMethod.Status)
+						require.Len(tt, controlled.VerificationMethods, 2)
+						assert.Equal(tt, did.KeyStatusMissing, controlled.VerificationMethods[1].Status)
+					}
+				}
+
+				// pages span methods
+				firstPage, err := didService.ListControlledDIDs(context.Background(), &common.Page{Size: 2})
+				require.NoError(tt, err)
+				assert.Len(tt, firstPage.DIDs, 2)
+				require.NotEmpty(tt, firstPage.NextPageToken)
+
+				secondPage, err := didService.ListControlledDIDs(context.Background(), &common.Page{Token: firstPage.NextPageToken, Size: 2})
+				require.NoError(tt, err)
+				assert.Len(tt, secondPage.DIDs, 1)
+				assert.Equal(tt, webDID.DID.ID, secondPage.DIDs[0].ID)
+				assert.Empty(tt, secondPage.NextPageToken)
+			})
+
 			t.Run("Test Resolve DIDs", func(tt *testing.T) {
 				db := test.ServiceStorage(tt)
 				require.NotEmpty(tt, db)
@@ -933,6 +1129,70 @@ func TestBatchCreateDIDs(t *testing.T) {
 	}
 }
 
+func TestBatchResolveDIDs(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(t *testing.T) {
+			db := test.ServiceStorage(t)
+			require.NotEmpty(t, db)
+			_, keyStoreService, factory := testKeyStore(t, db)
+			didRouter, _ := testDIDRouter(t, db, keyStoreService, []string{"key"}, factory)
+
+			batchResolve := func(tt *testing.T, request router.BatchResolveDIDsRequest) *httptest.ResponseRecorder {
+				requestValue := newRequestValue(tt, request)
+				req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/dids/resolver/batch", requestValue)
+				w := httptest.NewRecorder()
+				didRouter.BatchResolveDIDs(newRequestContext(w, req))
+				return w
+			}
+
+			t.Run("Resolves each DID in order", func(tt *testing.T) {
+				keyDID := "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+				w := batchResolve(tt, router.BatchResolveDIDsRequest{DIDs: []string{"bad", keyDID, "did:unknown:abcd", "did:key:abcd", keyDID}})
+				require.True(tt, util.Is2xxResponse(w.Code), w.Body.String())
+
+				var resp router.BatchResolveDIDsResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(tt, resp.Results, 5)
+
+				// partial failures do not fail the batch
+				assert.Equal(tt, "bad", resp.Results[0].DID)
+				assert.Contains(tt, resp.Results[0].Error, "malformed did")
+				assert.Nil(tt, resp.Results[0].DIDDocument)
+
+				assert.Equal(tt, keyDID, resp.Results[1].DID)
+				assert.Empty(tt, resp.Results[1].Error)
+				require.NotNil(tt, resp.Results[1].DIDDocument)
+				assert.Equal(tt, keyDID, resp.Results[1].DIDDocument.ID)
+
+				assert.Equal(tt, "did:unknown:abcd", resp.Results[2].DID)
+				assert.Contains(tt, resp.Results[2].Error, "unable to resolve DID did:unknown:abcd")
+
+				assert.Equal(tt, "did:key:abcd", resp.Results[3].DID)
+				assert.Contains(tt, resp.Results[3].Error, "unable to resolve DID did:key:abcd")
+
+				require.NotNil(tt, resp.Results[4].DIDDocument)
+				assert.Equal(tt, keyDID, resp.Results[4].DIDDocument.ID)
+			})
+
+			t.Run("Fails without DIDs", func(tt *testing.T) {
+				w := batchResolve(tt, router.BatchResolveDIDsRequest{})
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				assert.Contains(tt, w.Body.String(), "invalid batch resolve DIDs request")
+			})
+
+			t.Run("Fails with more than 100 DIDs", func(tt *testing.T) {
+				dids := make([]string, 101)
+				for i := range dids {
+					dids[i] = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+				}
+				w := batchResolve(tt, router.BatchResolveDIDsRequest{DIDs: dids})
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				assert.Contains(tt, w.Body.String(), "max number of DIDs is 100")
+			})
+		})
+	}
+}
+
 func createDIDWithRouter(tt *testing.T, didService *router.DIDRouter) {
 	w := httptest.NewRecorder()
 	createDIDRequest := router.CreateDIDByMethodRequest{KeyType: crypto.Ed25519}
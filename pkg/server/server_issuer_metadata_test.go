@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	wellknown "github.com/tbd54566975/ssi-service/pkg/service/well-known"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestIssuerMetadataAPI(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Run("Test Get Issuer Metadata", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+
+				licenseSchema := getLicenseSchema()
+				licenseSchema["properties"].(map[string]any)["credentialSubject"].(map[string]any)["properties"].(map[string]any)["firstName"] = map[string]any{
+					"type":  "string",
+					"title": "First Name",
+				}
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Name:                               "license schema",
+					Description:                        "a driver's license",
+					Schema:                             licenseSchema,
+				})
+				require.NoError(tt, err)
+
+				metadataService, err := wellknown.NewIssuerMetadataService(config.IssuerMetadataServiceConfig{
+					CredentialIssuer:  "https://issuer.example.com",
+					CredentialFormats: []string{wellknown.JWTVCJSONFormat, wellknown.LDPVCFormat},
+					DisplayName:       "Example Issuer",
+					DisplayLocale:     "en-US",
+				}, didService, schemaService)
+				require.NoError(tt, err)
+				metadata := getIssuerMetadata(tt, metadataService)
+
+				assert.Equal(tt, "https://issuer.example.com", metadata.CredentialIssuer)
+				assert.Equal(tt, []wellknown.Display{{Name: "Example Issuer", Locale: "en-US"}}, metadata.Display)
+				assert.Len(tt, metadata.CredentialConfigurationsSupported, 2)
+
+				configuration, ok := metadata.CredentialConfigurationsSupported[wellknown.CredentialConfigurationID(createdSchema.ID, wellknown.JWTVCJSONFormat)]
+				require.True(tt, ok)
+				assert.Equal(tt, wellknown.JWTVCJSONFormat, configuration.Format)
+				assert.Equal(tt, []string{string(crypto.EdDSA)}, configuration.CredentialSigningAlgValuesSupported)
+				assert.Contains(tt, configuration.CryptographicBindingMethodsSupported, "did:key")
+				assert.Equal(tt, []string{"VerifiableCredential"}, configuration.CredentialDefinition.Type)
+				assert.Equal(tt, []wellknown.CredentialDisplay{{Name: "license schema", Description: "a driver's license", Locale: "en-US"}}, configuration.Display)
+
+				claims := configuration.CredentialDefinition.CredentialSubject
+				assert.Len(tt, claims, 3)
+				assert.True(tt, claims["firstName"].Mandatory)
+				assert.Equal(tt, []wellknown.Display{{Name: "First Name", Locale: "en-US"}}, claims["firstName"].Display)
+				assert.True(tt, claims["state"].Mandatory)
+				assert.Empty(tt, claims["state"].Display)
+
+				ldpConfiguration, ok := metadata.CredentialConfigurationsSupported[wellknown.CredentialConfigurationID(createdSchema.ID, wellknown.LDPVCFormat)]
+				require.True(tt, ok)
+				assert.Equal(tt, wellknown.LDPVCFormat, ldpConfiguration.Format)
+			})
+
+			t.Run("Test Get Issuer Metadata Defaults", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:   "license application schema",
+					Schema: getLicenseApplicationSchema(),
+				})
+				require.NoError(tt, err)
+
+				metadataService, err := wellknown.NewIssuerMetadataService(config.IssuerMetadataServiceConfig{}, didService, schemaService)
+				require.NoError(tt, err)
+				metadata := getIssuerMetadata(tt, metadataService)
+
+				assert.Equal(tt, config.GetAPIBase(), metadata.CredentialIssuer)
+				assert.Empty(tt, metadata.Display)
+				assert.Len(tt, metadata.CredentialConfigurationsSupported, 1)
+
+				configuration, ok := metadata.CredentialConfigurationsSupported[wellknown.CredentialConfigurationID(createdSchema.ID, wellknown.JWTVCJSONFormat)]
+				require.True(tt, ok)
+				assert.Empty(tt, configuration.CredentialSigningAlgValuesSupported)
+				assert.Contains(tt, configuration.CredentialDefinition.CredentialSubject, "licenseType")
+			})
+
+			t.Run("Test Get Issuer Metadata Localized Display", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+
+				metadataService, err := wellknown.NewIssuerMetadataService(config.IssuerMetadataServiceConfig{
+					DisplayName:   "Example Issuer",
+					DisplayLocale: "en-US",
+					Displays: []config.IssuerDisplayConfig{
+						{Name: "Emisor de Ejemplo", Locale: "es"},
+						{Name: "Émetteur Exemple", Locale: "fr"},
+					},
+				}, didService, schemaService)
+				require.NoError(tt, err)
+
+				metadata, err := metadataService.GetIssuerMetadata(context.Background(), wellknown.GetIssuerMetadataRequest{})
+				require.NoError(tt, err)
+				assert.Len(tt, metadata.Display, 3)
+
+				metadata, err = metadataService.GetIssuerMetadata(context.Background(), wellknown.GetIssuerMetadataRequest{AcceptLanguage: "es-MX,es;q=0.9"})
+				require.NoError(tt, err)
+				assert.Equal(tt, []wellknown.Display{{Name: "Emisor de Ejemplo", Locale: "es"}}, metadata.Display)
+
+				metadata, err = metadataService.GetIssuerMetadata(context.Background(), wellknown.GetIssuerMetadataRequest{AcceptLanguage: "de, en;q=0.5"})
+				require.NoError(tt, err)
+				assert.Equal(tt, []wellknown.Display{{Name: "Example Issuer", Locale: "en-US"}}, metadata.Display)
+
+				// no display matches, so all are returned for the wallet to choose from
+				metadata, err = metadataService.GetIssuerMetadata(context.Background(), wellknown.GetIssuerMetadataRequest{AcceptLanguage: "de"})
+				require.NoError(tt, err)
+				assert.Len(tt, metadata.Display, 3)
+			})
+
+			t.Run("Test Unsupported Credential Format", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+
+				_, err := wellknown.NewIssuerMetadataService(config.IssuerMetadataServiceConfig{CredentialFormats: []string{"mso_mdoc"}}, didService, schemaService)
+				assert.ErrorContains(tt, err, "unsupported credential format<mso_mdoc>")
+			})
+		})
+	}
+}
+
+func getIssuerMetadata(t *testing.T, metadataService *wellknown.IssuerMetadataService) wellknown.IssuerMetadata {
+	issuerMetadataRouter, err := router.NewIssuerMetadataRouter(metadataService)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/.well-known/openid-credential-issuer", nil)
+	w := httptest.NewRecorder()
+	c := newRequestContext(w, req)
+	issuerMetadataRouter.GetIssuerMetadata(c)
+	require.True(t, util.Is2xxResponse(w.Code))
+
+	var metadata wellknown.IssuerMetadata
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&metadata))
+	return metadata
+}
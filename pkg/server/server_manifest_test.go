@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	gocrypto "crypto"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,17 +10,22 @@ import (
 	"time"
 
 	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	"github.com/TBD54566975/ssi-sdk/credential/manifest"
 	"github.com/TBD54566975/ssi-sdk/credential/parsing"
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/key"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/benbjohnson/clock"
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tbd54566975/ssi-service/config"
 	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/util"
@@ -27,8 +33,10 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
 	"github.com/tbd54566975/ssi-service/pkg/service/issuance"
+	manifestservice "github.com/tbd54566975/ssi-service/pkg/service/manifest"
 	manifestsvc "github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
 	"github.com/tbd54566975/ssi-service/pkg/testutil"
 )
@@ -1111,10 +1119,183 @@ func TestManifestAPI(t *testing.T) {
 				manifestRouter.GetApplication(c)
 				assert.Contains(tt, w.Body.String(), fmt.Sprintf("could not get application with id: %s", appResp.Response.ID))
 			})
+
+			t.Run("Test Application Presentation Verification", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				credentialService := testCredentialService(tt, db, keyStoreService, didService, schemaService)
+				presentationService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, db, didService.GetResolver(), schemaService, keyStoreService)
+				require.NoError(tt, err)
+				manifestRouter := testManifestWithPresentations(tt, db, config.ManifestServiceConfig{}, keyStoreService, didService, credentialService, presentationService)
+
+				// create an issuer
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+
+				// create an applicant
+				applicantPrivKey, applicantDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+				require.NoError(tt, err)
+				applicantDID, err := applicantDIDKey.Expand()
+				require.NoError(tt, err)
+
+				// create an impostor, who presents the applicant's credential as their own
+				impostorPrivKey, impostorDIDKey, err := key.GenerateDIDKey(crypto.Ed25519)
+				require.NoError(tt, err)
+				impostorDID, err := impostorDIDKey.Expand()
+				require.NoError(tt, err)
+
+				// issue the applicant a credential which satisfies the manifest's presentation definition
+				kid := issuerDID.DID.VerificationMethod[0].ID
+				licenseApplicationSchema, err := schemaService.CreateSchema(context.Background(),
+					schema.CreateSchemaRequest{Issuer: issuerDID.DID.ID, FullyQualifiedVerificationMethodID: kid, Name: "license application schema", Schema: getLicenseApplicationSchema()})
+				require.NoError(tt, err)
+				createdCred, err := credentialService.CreateCredential(context.Background(), credential.CreateCredentialRequest{
+					Issuer:                             issuerDID.DID.ID,
+					FullyQualifiedVerificationMethodID: kid,
+					Subject:                            applicantDID.ID,
+					SchemaID:                           licenseApplicationSchema.ID,
+					Data:                               map[string]any{"licenseType": "Class D"},
+				})
+				require.NoError(tt, err)
+
+				w := httptest.NewRecorder()
+				createManifestRequest := getValidCreateManifestRequest(issuerDID.DID.ID, kid, licenseApplicationSchema.ID)
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests", newRequestValue(tt, createManifestRequest))
+				c := newRequestContext(w, req)
+				manifestRouter.CreateManifest(c)
+				require.True(tt, util.Is2xxResponse(w.Code))
+
+				var resp router.CreateManifestResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				m := resp.Manifest
+
+				// submit an application with a presentation made by the applicant
+				applicantSigner, err := keyaccess.NewJWKKeyAccess(applicantDID.ID, applicantDID.VerificationMethod[0].ID, applicantPrivKey)
+				require.NoError(tt, err)
+				validPresentation := getApplicationPresentationJWT(tt, m, applicantDID, applicantPrivKey, *createdCred.CredentialJWT)
+				validOp := submitApplicationWithPresentation(tt, manifestRouter, m, applicantSigner, *createdCred.CredentialJWT, validPresentation)
+				assert.False(tt, validOp.Done)
+
+				// submit an application with a presentation made by someone other than the applicant
+				invalidPresentation := getApplicationPresentationJWT(tt, m, impostorDID, impostorPrivKey, *createdCred.CredentialJWT)
+				invalidOp := submitApplicationWithPresentation(tt, manifestRouter, m, applicantSigner, *createdCred.CredentialJWT, invalidPresentation)
+				assert.False(tt, invalidOp.Done)
+
+				// the results are stored with each application
+				validApplicationID := storage.StatusObjectID(validOp.ID)
+				w = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/manifests/applications/"+validApplicationID, nil)
+				c = newRequestContextWithParams(w, req, map[string]string{"id": validApplicationID})
+				manifestRouter.GetApplication(c)
+				require.True(tt, util.Is2xxResponse(w.Code))
+
+				var validApplication router.GetApplicationResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&validApplication))
+				require.NotEmpty(tt, validApplication.PresentationVerification)
+				assert.True(tt, validApplication.PresentationVerification.Verified)
+				assert.Len(tt, validApplication.PresentationVerification.Checks, 4)
+				for _, check := range validApplication.PresentationVerification.Checks {
+					assert.True(tt, check.Passed, check.Name)
+				}
+
+				invalidApplicationID := storage.StatusObjectID(invalidOp.ID)
+				w = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/manifests/applications", nil)
+				c = newRequestContext(w, req)
+				manifestRouter.ListApplications(c)
+				require.True(tt, util.Is2xxResponse(w.Code))
+
+				var applications router.ListApplicationsResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&applications))
+				assert.Len(tt, applications.Applications, 2)
+				require.Len(tt, applications.PresentationVerifications, 2)
+				assert.True(tt, applications.PresentationVerifications[validApplicationID].Verified)
+
+				invalidVerification := applications.PresentationVerifications[invalidApplicationID]
+				assert.False(tt, invalidVerification.Verified)
+				for _, check := range invalidVerification.Checks {
+					if check.Name == manifestservice.HolderBindingCheck {
+						assert.False(tt, check.Passed)
+						assert.Contains(tt, check.Reason, "is not the applicant")
+						continue
+					}
+					assert.True(tt, check.Passed, check.Name)
+				}
+
+				// when configured, applications with a presentation which fails verification are denied
+				denyingRouter := testManifestWithPresentations(tt, db, config.ManifestServiceConfig{DenyUnverifiedPresentations: true}, keyStoreService, didService, credentialService, presentationService)
+				deniedOp := submitApplicationWithPresentation(tt, denyingRouter, m, applicantSigner, *createdCred.CredentialJWT, invalidPresentation)
+				assert.True(tt, deniedOp.Done)
+
+				var deniedResp router.SubmitApplicationResponse
+				respData, err := json.Marshal(deniedOp.Result.Response)
+				require.NoError(tt, err)
+				require.NoError(tt, json.Unmarshal(respData, &deniedResp))
+				require.NotEmpty(tt, deniedResp.Response.Denial)
+				assert.Contains(tt, deniedResp.Response.Denial.Reason, "presentation verification failed: holderBinding")
+			})
 		})
 	}
 }
 
+// getApplicationPresentationJWT returns a presentation of the credential made by the holder, which satisfies the
+// presentation definition of the manifest.
+func getApplicationPresentationJWT(t *testing.T, m manifest.CredentialManifest, holder *didsdk.Document, holderKey gocrypto.PrivateKey, credentialJWT keyaccess.JWT) keyaccess.JWT {
+	holderSigner, err := jwx.NewJWXSigner(holder.ID, holder.VerificationMethod[0].ID, holderKey)
+	require.NoError(t, err)
+	vp := credsdk.VerifiablePresentation{
+		Context: []string{credsdk.VerifiableCredentialsLinkedDataContext},
+		ID:      uuid.NewString(),
+		Holder:  holder.ID,
+		Type:    []string{credsdk.VerifiablePresentationType},
+		PresentationSubmission: exchange.PresentationSubmission{
+			ID:           uuid.NewString(),
+			DefinitionID: m.PresentationDefinition.ID,
+			DescriptorMap: []exchange.SubmissionDescriptor{
+				{
+					ID:     m.PresentationDefinition.InputDescriptors[0].ID,
+					Format: exchange.JWTVC.String(),
+					Path:   "$.verifiableCredential[0]",
+				},
+			},
+		},
+		VerifiableCredential: []any{credentialJWT},
+	}
+	signed, err := integrity.SignVerifiablePresentationJWT(*holderSigner, &integrity.JWTVVPParameters{Audience: []string{holder.ID}}, vp)
+	require.NoError(t, err)
+	return keyaccess.JWT(signed)
+}
+
+// submitApplicationWithPresentation submits an application to the manifest, signed by the applicant, which includes the
+// presentation under the presentationJwt property.
+func submitApplicationWithPresentation(t *testing.T, manifestRouter *router.ManifestRouter, m manifest.CredentialManifest,
+	applicantSigner *keyaccess.JWKKeyAccess, credentialJWT, presentationJWT keyaccess.JWT) router.Operation {
+	container := []credmodel.Container{{CredentialJWT: &credentialJWT}}
+	applicationRequest := getValidApplicationRequest(m.ID, m.PresentationDefinition.ID, m.PresentationDefinition.InputDescriptors[0].ID, container)
+	applicationJSON, err := sdkutil.ToJSONMap(applicationRequest)
+	require.NoError(t, err)
+	applicationJSON["presentationJwt"] = presentationJWT.String()
+	signed, err := applicantSigner.SignJSON(applicationJSON)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/manifests/applications", newRequestValue(t, router.SubmitApplicationRequest{ApplicationJWT: *signed}))
+	c := newRequestContext(w, req)
+	manifestRouter.SubmitApplication(c)
+	require.True(t, util.Is2xxResponse(w.Code))
+
+	var op router.Operation
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&op))
+	return op
+}
+
 func getValidManifestRequestRequest(issuerDID *did.CreateDIDResponse, kid string, credentialManifest manifest.CredentialManifest) router.CreateManifestRequestRequest {
 	return router.CreateManifestRequestRequest{
 		CommonCreateRequestRequest: &router.CommonCreateRequestRequest{
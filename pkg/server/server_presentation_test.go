@@ -21,18 +21,26 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tbd54566975/ssi-service/pkg/testutil"
 
+	"github.com/tbd54566975/ssi-service/config"
+	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/internal/verification"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation/model"
+	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
@@ -185,6 +193,332 @@ func TestPresentationAPI(t *testing.T) {
 					assert.NoError(tttt, json.NewDecoder(w.Body).Decode(&resp))
 					assert.True(tttt, resp.Verified)
 				})
+
+				ttt.Run("Verifiable Presentation with mixed credential formats", func(tttt *testing.T) {
+					// sign the credential issued by the service as a data integrity credential, with the issuer's key
+					issuerKID := issuerDID.DID.VerificationMethod[0].ID
+					issuerKey, err := keyStoreService.GetKey(context.Background(), keystore.GetKeyRequest{ID: issuerKID})
+					require.NoError(tttt, err)
+					dataIntegrityKeyAccess, err := keyaccess.NewDataIntegrityKeyAccess(issuerDID.DID.ID, issuerKID, issuerKey.Key)
+					require.NoError(tttt, err)
+					dataIntegrityCred := *createResp.Credential
+					signed, err := dataIntegrityKeyAccess.Sign(&dataIntegrityCred)
+					require.NoError(tttt, err)
+					var signedCred map[string]any
+					require.NoError(tttt, json.Unmarshal(signed.Data, &signedCred))
+
+					verify := func(credentials ...any) router.VerifyPresentationResponse {
+						testPresentation.VerifiableCredential = credentials
+						presentationJWT, err := integrity.SignVerifiablePresentationJWT(holderSigner, &integrity.JWTVVPParameters{Audience: []string{holderSigner.ID}}, testPresentation)
+						require.NoError(tttt, err)
+
+						value := newRequestValue(tttt, router.VerifyPresentationRequest{PresentationJWT: keyaccess.JWTPtr(string(presentationJWT))})
+						req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/verification", value)
+						w := httptest.NewRecorder()
+						presRouter.VerifyPresentation(newRequestContext(w, req))
+						require.True(tttt, util.Is2xxResponse(w.Code))
+
+						var resp router.VerifyPresentationResponse
+						require.NoError(tttt, json.NewDecoder(w.Body).Decode(&resp))
+						return resp
+					}
+
+					resp := verify(createResp.CredentialJWT, signedCred)
+					assert.True(tttt, resp.Verified, resp.Reason)
+					assert.Equal(tttt, []verification.CredentialResult{
+						{Index: 0, ID: createResp.Credential.ID, Format: verification.JWTFormat, Verified: true},
+						{Index: 1, ID: createResp.Credential.ID, Format: verification.DataIntegrityFormat, Verified: true},
+					}, resp.CredentialResults)
+
+					// tampering with the data integrity credential only fails that credential
+					tamperedCred := make(map[string]any, len(signedCred))
+					for k, v := range signedCred {
+						tamperedCred[k] = v
+					}
+					// the contexts of the credential do not define firstName, so only the subject's id is signed
+					tamperedCred["credentialSubject"] = map[string]any{"id": "did:car:912", "firstName": "Frank", "lastName": "Ocean"}
+					resp = verify(createResp.CredentialJWT, tamperedCred)
+					assert.False(tttt, resp.Verified)
+					assert.Equal(tttt, verification.InvalidCredential, resp.ReasonCode)
+					assert.Contains(tttt, resp.Reason, "verifying credential 1: ")
+					require.Len(tttt, resp.CredentialResults, 2)
+					assert.True(tttt, resp.CredentialResults[0].Verified)
+					assert.Equal(tttt, verification.DataIntegrityFormat, resp.CredentialResults[1].Format)
+					assert.False(tttt, resp.CredentialResults[1].Verified)
+					assert.NotEmpty(tttt, resp.CredentialResults[1].Reason)
+				})
+
+				ttt.Run("Verifiable Presentation with expected credentials", func(tttt *testing.T) {
+					// submit the credential for an input descriptor
+					testPresentation.VerifiableCredential = []any{createResp.CredentialJWT}
+					testPresentation.PresentationSubmission = exchange.PresentationSubmission{
+						ID:           uuid.NewString(),
+						DefinitionID: pd.ID,
+						DescriptorMap: []exchange.SubmissionDescriptor{
+							{ID: "id", Format: string(exchange.JWTVC), Path: "$.verifiableCredential[0]"},
+						},
+					}
+					presentationJWT, err := integrity.SignVerifiablePresentationJWT(holderSigner, &integrity.JWTVVPParameters{Audience: []string{holderSigner.ID}}, testPresentation)
+					assert.NoError(tttt, err)
+
+					verify := func(expected map[string]verification.CredentialExpectations) router.VerifyPresentationResponse {
+						value := newRequestValue(tttt, router.VerifyPresentationRequest{
+							PresentationJWT:     keyaccess.JWTPtr(string(presentationJWT)),
+							ExpectedCredentials: expected,
+						})
+						req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/verification", value)
+						w := httptest.NewRecorder()
+						presRouter.VerifyPresentation(newRequestContext(w, req))
+						assert.True(tttt, util.Is2xxResponse(w.Code))
+
+						var resp router.VerifyPresentationResponse
+						assert.NoError(tttt, json.NewDecoder(w.Body).Decode(&resp))
+						return resp
+					}
+
+					resp := verify(map[string]verification.CredentialExpectations{"id": {Types: []string{"VerifiableCredential"}}})
+					assert.True(tttt, resp.Verified, resp.Reason)
+
+					resp = verify(map[string]verification.CredentialExpectations{"id": {Types: []string{"EmailCredential"}}})
+					assert.False(tttt, resp.Verified)
+					assert.Equal(tttt, verification.TypeMismatch, resp.ReasonCode)
+					assert.Contains(tttt, resp.Reason, "input descriptor<id>")
+
+					resp = verify(map[string]verification.CredentialExpectations{"id": {SchemaID: "some-schema"}})
+					assert.False(tttt, resp.Verified)
+					assert.Equal(tttt, verification.SchemaMismatch, resp.ReasonCode)
+
+					resp = verify(map[string]verification.CredentialExpectations{"other-id": {Types: []string{"VerifiableCredential"}}})
+					assert.False(tttt, resp.Verified)
+					assert.Contains(tttt, resp.Reason, "no credential submitted for input descriptor<other-id>")
+				})
+			})
+
+			tt.Run("Batch Verify Verifiable Presentations", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				presRouter, _ := setupPresentationRouter(ttt, db)
+
+				holderSigner, holderDID := getSigner(ttt)
+				testPresentation := credential.VerifiablePresentation{
+					Context: []string{"https://www.w3.org/2018/credentials/v1"},
+					Type:    []string{"VerifiablePresentation"},
+					Holder:  holderDID.String(),
+				}
+				challenge := uuid.NewString()
+				answered := signPresentationWithNonce(ttt, holderSigner, testPresentation, challenge)
+				otherAnswered := signPresentationWithNonce(ttt, holderSigner, testPresentation, challenge)
+				unanswered := signPresentationWithNonce(ttt, holderSigner, testPresentation, uuid.NewString())
+
+				batchVerify := func(request router.BatchVerifyPresentationsRequest) *httptest.ResponseRecorder {
+					value := newRequestValue(ttt, request)
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/verification/batch", value)
+					w := httptest.NewRecorder()
+					presRouter.BatchVerifyPresentations(newRequestContext(w, req))
+					return w
+				}
+
+				w := batchVerify(router.BatchVerifyPresentationsRequest{
+					Challenge:        challenge,
+					PresentationJWTs: []keyaccess.JWT{answered, unanswered, otherAnswered, answered, "bad"},
+				})
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				var resp router.BatchVerifyPresentationsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(ttt, resp.Results, 5)
+				assert.True(ttt, resp.Results[0].Verified)
+				assert.False(ttt, resp.Results[1].Verified)
+				assert.Contains(ttt, resp.Results[1].Reason, "does not match the challenge")
+				assert.True(ttt, resp.Results[2].Verified)
+				assert.False(ttt, resp.Results[3].Verified)
+				assert.Equal(ttt, "presentation was already submitted in this batch", resp.Results[3].Reason)
+				assert.False(ttt, resp.Results[4].Verified)
+
+				// the challenge can't be replayed
+				w = batchVerify(router.BatchVerifyPresentationsRequest{
+					Challenge:        challenge,
+					PresentationJWTs: []keyaccess.JWT{otherAnswered},
+				})
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+
+				w = batchVerify(router.BatchVerifyPresentationsRequest{Challenge: uuid.NewString()})
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+			})
+
+			tt.Run("Batch Verify With Expiring Challenges", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				service, err := presentation.NewPresentationService(config.PresentationServiceConfig{ChallengeTTL: "5m"}, db, didService.GetResolver(), schemaService, keyStoreService)
+				require.NoError(ttt, err)
+				presRouter, err := router.NewPresentationRouter(service)
+				require.NoError(ttt, err)
+
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/challenges", nil)
+				w := httptest.NewRecorder()
+				presRouter.CreateChallenge(newRequestContext(w, req))
+				require.Equal(ttt, http.StatusCreated, w.Code)
+				var challengeResp router.CreateChallengeResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&challengeResp))
+				assert.NotEmpty(ttt, challengeResp.Challenge)
+				assert.NotEmpty(ttt, challengeResp.ExpiresAt)
+
+				holderSigner, holderDID := getSigner(ttt)
+				testPresentation := credential.VerifiablePresentation{
+					Context: []string{"https://www.w3.org/2018/credentials/v1"},
+					Type:    []string{"VerifiablePresentation"},
+					Holder:  holderDID.String(),
+				}
+				batchVerify := func(challenge string) *httptest.ResponseRecorder {
+					value := newRequestValue(ttt, router.BatchVerifyPresentationsRequest{
+						Challenge:        challenge,
+						PresentationJWTs: []keyaccess.JWT{signPresentationWithNonce(ttt, holderSigner, testPresentation, challenge)},
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/verification/batch", value)
+					w := httptest.NewRecorder()
+					presRouter.BatchVerifyPresentations(newRequestContext(w, req))
+					return w
+				}
+
+				// challenges not created by the service are rejected once challenges expire
+				w = batchVerify(uuid.NewString())
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				assert.Contains(ttt, w.Body.String(), prestorage.ErrChallengeNotFound.Error())
+
+				w = batchVerify(challengeResp.Challenge)
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var resp router.BatchVerifyPresentationsResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(ttt, resp.Results, 1)
+				assert.True(ttt, resp.Results[0].Verified, resp.Results[0].Reason)
+
+				// an expired challenge can't be used
+				presentationStorage, err := presentation.NewPresentationStorage(db)
+				require.NoError(ttt, err)
+				expired := prestorage.StoredChallenge{
+					Challenge: uuid.NewString(),
+					ExpiresAt: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+				}
+				require.NoError(ttt, presentationStorage.StoreChallenge(context.Background(), expired))
+				w = batchVerify(expired.Challenge)
+				assert.Equal(ttt, http.StatusGone, w.Code)
+				assert.Contains(ttt, w.Body.String(), "expired")
+
+				// the sweeper purges expired challenges, leaving the others
+				purged, err := service.PurgeExpiredChallenges(context.Background())
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 1, purged)
+				w = batchVerify(expired.Challenge)
+				assert.Equal(ttt, http.StatusBadRequest, w.Code)
+				w = batchVerify(challengeResp.Challenge)
+				assert.Equal(ttt, http.StatusConflict, w.Code)
+			})
+
+			tt.Run("Construct a Profile Compliant Presentation", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				presRouter, didService := setupPresentationRouter(ttt, db)
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				issuerDIDService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, issuerDIDService)
+				credRouter := testCredentialRouter(ttt, db, keyStoreService, issuerDIDService, schemaService)
+
+				issuerDID := createDID(ttt, issuerDIDService)
+				holderDID := createDID(ttt, didService)
+				createCredRequest := router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              holderDID.DID.ID,
+					Data:                 map[string]any{"firstName": "Frank"},
+					Expiry:               time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+				}
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", newRequestValue(ttt, createCredRequest))
+				w := httptest.NewRecorder()
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+
+				audience := "https://verifier.example.com"
+				nonce := uuid.NewString()
+				construct := func(request router.ConstructPresentationRequest) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodPost, "https://ssi-service.com/v1/presentations/construct", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					presRouter.ConstructPresentation(newRequestContext(w, req))
+					return w
+				}
+				w = construct(router.ConstructPresentationRequest{
+					CredentialIDs:        []string{createResp.Credential.ID},
+					HolderDID:            holderDID.DID.ID,
+					VerificationMethodID: holderDID.DID.VerificationMethod[0].ID,
+					Audience:             audience,
+					Nonce:                nonce,
+				})
+				require.Equal(ttt, http.StatusCreated, w.Code, w.Body.String())
+				var constructResp router.ConstructPresentationResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&constructResp))
+
+				// the presentation has the claims and structure of the profile
+				headers, token, vp, err := integrity.ParseVerifiablePresentationFromJWT(constructResp.PresentationJWT.String())
+				require.NoError(ttt, err)
+				assert.Equal(ttt, holderDID.DID.VerificationMethod[0].ID, headers.KeyID())
+				assert.Equal(ttt, holderDID.DID.ID, token.Issuer())
+				assert.Equal(ttt, []string{audience}, token.Audience())
+				gotNonce, ok := token.Get(integrity.NonceProperty)
+				require.True(ttt, ok)
+				assert.Equal(ttt, nonce, gotNonce)
+				assert.False(ttt, token.NotBefore().IsZero())
+				assert.True(ttt, token.Expiration().After(token.NotBefore()))
+				assert.Equal(ttt, []any{createResp.CredentialJWT.String()}, vp.VerifiableCredential)
+
+				verify := func(request router.VerifyPresentationRequest) router.VerifyPresentationResponse {
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/verification", newRequestValue(ttt, request))
+					w := httptest.NewRecorder()
+					presRouter.VerifyPresentation(newRequestContext(w, req))
+					require.True(ttt, util.Is2xxResponse(w.Code))
+					var resp router.VerifyPresentationResponse
+					require.NoError(ttt, json.NewDecoder(w.Body).Decode(&resp))
+					return resp
+				}
+				resp := verify(router.VerifyPresentationRequest{PresentationJWT: &constructResp.PresentationJWT, Audience: audience, Nonce: nonce})
+				assert.True(ttt, resp.Verified, resp.Reason)
+				require.Len(ttt, resp.CredentialResults, 1)
+				assert.True(ttt, resp.CredentialResults[0].Verified)
+
+				resp = verify(router.VerifyPresentationRequest{PresentationJWT: &constructResp.PresentationJWT, Audience: "https://other.example.com", Nonce: nonce})
+				assert.False(ttt, resp.Verified)
+				assert.Contains(ttt, resp.Reason, "audience mismatch")
+
+				resp = verify(router.VerifyPresentationRequest{PresentationJWT: &constructResp.PresentationJWT, Audience: audience, Nonce: uuid.NewString()})
+				assert.False(ttt, resp.Verified)
+				assert.Contains(ttt, resp.Reason, "does not match")
+
+				// without an expected audience, the presentation must be addressed to its holder
+				resp = verify(router.VerifyPresentationRequest{PresentationJWT: &constructResp.PresentationJWT})
+				assert.False(ttt, resp.Verified)
+				assert.Contains(ttt, resp.Reason, "audience mismatch")
+
+				ttt.Run("unknown credentials are rejected", func(tttt *testing.T) {
+					w := construct(router.ConstructPresentationRequest{
+						CredentialIDs:        []string{uuid.NewString()},
+						HolderDID:            holderDID.DID.ID,
+						VerificationMethodID: holderDID.DID.VerificationMethod[0].ID,
+						Audience:             audience,
+						Nonce:                nonce,
+					})
+					assert.Equal(tttt, http.StatusBadRequest, w.Code)
+					assert.Contains(tttt, w.Body.String(), "not found")
+				})
+
+				ttt.Run("a nonce is required", func(tttt *testing.T) {
+					w := construct(router.ConstructPresentationRequest{
+						CredentialIDs:        []string{createResp.Credential.ID},
+						HolderDID:            holderDID.DID.ID,
+						VerificationMethodID: holderDID.DID.VerificationMethod[0].ID,
+						Audience:             audience,
+					})
+					assert.Equal(tttt, http.StatusBadRequest, w.Code)
+				})
 			})
 
 			tt.Run("Create, Get, and Delete Presentation Definition", func(ttt *testing.T) {
@@ -455,6 +789,95 @@ func TestPresentationAPI(t *testing.T) {
 					assert.Zero(tttt, resp.Result)
 				})
 
+				ttt.Run("Copies of a credential count once against submission requirements", func(tttt *testing.T) {
+					s := test.ServiceStorage(tttt)
+					pRouter, didService := setupPresentationRouter(tttt, s)
+					authorDID := createDID(tttt, didService)
+					holderSigner, holderDID := getSigner(tttt)
+
+					// two credentials are needed from the group
+					inputDescriptor := func(id string) exchange.InputDescriptor {
+						return exchange.InputDescriptor{
+							ID:    id,
+							Group: []string{"A"},
+							Constraints: &exchange.Constraints{
+								Fields: []exchange.Field{{Path: []string{"$.vc.credentialSubject.givenName"}}},
+							},
+						}
+					}
+					definition := createPresentationDefinition(tttt, pRouter, WithInputDescriptors([]exchange.InputDescriptor{inputDescriptor("first"), inputDescriptor("second")}),
+						func(r *router.CreatePresentationDefinitionRequest) {
+							r.SubmissionRequirements = []exchange.SubmissionRequirement{{Name: "references", Rule: exchange.Pick, Count: 2, FromOption: exchange.FromOption{From: "A"}}}
+						})
+
+					// each credential is signed twice, so that it has two copies
+					issuerKey, issuerDID, err := key.GenerateDIDKey(crypto.Ed25519)
+					require.NoError(tttt, err)
+					expanded, err := issuerDID.Expand()
+					require.NoError(tttt, err)
+					issuerSigner, err := jwx.NewJWXSigner(issuerDID.String(), expanded.VerificationMethod[0].ID, issuerKey)
+					require.NoError(tttt, err)
+					issue := func() (keyaccess.JWT, keyaccess.JWT, string) {
+						vc := VerifiableCredential()
+						vc.Issuer = issuerDID.String()
+						// signing moves the subject ID of the credential into the JWT claims
+						vcCopy, err := credmodel.CopyCredential(vc)
+						require.NoError(tttt, err)
+						vcJWT, err := integrity.SignVerifiableCredentialJWT(*issuerSigner, vc)
+						require.NoError(tttt, err)
+						copyJWT, err := integrity.SignVerifiableCredentialJWT(*issuerSigner, *vcCopy)
+						require.NoError(tttt, err)
+						return keyaccess.JWT(vcJWT), keyaccess.JWT(copyJWT), vc.ID
+					}
+					firstJWT, firstCopy, firstID := issue()
+					secondJWT, _, _ := issue()
+
+					submit := func(credentials ...any) *httptest.ResponseRecorder {
+						vp := credential.VerifiablePresentation{
+							Context: []string{credential.VerifiableCredentialsLinkedDataContext},
+							ID:      uuid.NewString(),
+							Holder:  holderDID.String(),
+							Type:    []string{credential.VerifiablePresentationType},
+							PresentationSubmission: exchange.PresentationSubmission{
+								ID:           uuid.NewString(),
+								DefinitionID: definition.PresentationDefinition.ID,
+								DescriptorMap: []exchange.SubmissionDescriptor{
+									{ID: "first", Format: verification.JWTFormat, Path: "$.verifiableCredential[0]"},
+									{ID: "second", Format: verification.JWTFormat, Path: "$.verifiableCredential[1]"},
+								},
+							},
+							VerifiableCredential: credentials,
+						}
+						signed, err := integrity.SignVerifiablePresentationJWT(holderSigner, &integrity.JWTVVPParameters{Audience: []string{authorDID.DID.ID}}, vp)
+						require.NoError(tttt, err)
+
+						value := newRequestValue(tttt, router.CreateSubmissionRequest{SubmissionJWT: keyaccess.JWT(signed)})
+						req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/submissions", value)
+						w := httptest.NewRecorder()
+						pRouter.CreateSubmission(newRequestContext(w, req))
+						return w
+					}
+
+					// both input descriptors are fulfilled, by the two copies of the same credential
+					w := submit(firstJWT, firstCopy)
+					assert.Equal(tttt, http.StatusBadRequest, w.Code)
+					assert.Contains(tttt, w.Body.String(), "needs 2 distinct credentials of group<A>, 1 were submitted")
+
+					// with two distinct credentials, the copy is reported
+					w = submit(firstJWT, secondJWT, firstCopy)
+					require.Equal(tttt, http.StatusCreated, w.Code, w.Body.String())
+					var op router.Operation
+					require.NoError(tttt, json.NewDecoder(w.Body).Decode(&op))
+
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/presentations/submissions/"+opstorage.StatusObjectID(op.ID), nil)
+					w = httptest.NewRecorder()
+					pRouter.GetSubmission(newRequestContextWithParams(w, req, map[string]string{"id": opstorage.StatusObjectID(op.ID)}))
+					require.True(tttt, util.Is2xxResponse(w.Code))
+					var resp router.GetSubmissionResponse
+					require.NoError(tttt, json.NewDecoder(w.Body).Decode(&resp))
+					assert.Equal(tttt, []string{fmt.Sprintf("credential<%s> is submitted 2 times, and counts once", firstID)}, resp.Submission.Warnings)
+				})
+
 				ttt.Run("Review submission returns approved submission", func(tttt *testing.T) {
 					s := test.ServiceStorage(tttt)
 					pRouter, didService := setupPresentationRouter(tttt, s)
@@ -887,7 +1310,7 @@ func setupPresentationRouter(t *testing.T, s storage.ServiceStorage) (*router.Pr
 	didService, _ := testDIDService(t, s, keyStoreService, nil)
 	schemaService := testSchemaService(t, s, keyStoreService, didService)
 
-	service, err := presentation.NewPresentationService(s, didService.GetResolver(), schemaService, keyStoreService)
+	service, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, s, didService.GetResolver(), schemaService, keyStoreService)
 	assert.NoError(t, err)
 
 	pRouter, err := router.NewPresentationRouter(service)
@@ -1033,6 +1456,25 @@ func createPresentationDefinition(t *testing.T, pRouter *router.PresentationRout
 	return resp
 }
 
+// signPresentationWithNonce signs the presentation as a JWT like the sdk does, but with the given nonce.
+func signPresentationWithNonce(t *testing.T, signer jwx.Signer, presentation credential.VerifiablePresentation, nonce string) keyaccess.JWT {
+	token := jwt.New()
+	require.NoError(t, token.Set(jwt.AudienceKey, []string{signer.ID}))
+	require.NoError(t, token.Set(jwt.IssuedAtKey, time.Now().Unix()))
+	require.NoError(t, token.Set(jwt.NotBeforeKey, time.Now().Unix()))
+	require.NoError(t, token.Set(jwt.JwtIDKey, uuid.NewString()))
+	require.NoError(t, token.Set(jwt.IssuerKey, presentation.Holder))
+	require.NoError(t, token.Set(integrity.NonceProperty, nonce))
+	presentation.Holder = ""
+	require.NoError(t, token.Set(integrity.VPJWTProperty, presentation))
+
+	headers := jws.NewHeaders()
+	require.NoError(t, headers.Set(jws.KeyIDKey, signer.KID))
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.SignatureAlgorithm(signer.ALG), signer.PrivateKey, jws.WithProtectedHeaders(headers)))
+	require.NoError(t, err)
+	return keyaccess.JWT(signed)
+}
+
 func getSigner(t *testing.T) (jwx.Signer, key.DIDKey) {
 	private, didKey, err := key.GenerateDIDKey(crypto.P256)
 	require.NoError(t, err)
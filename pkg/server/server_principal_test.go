@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
+	"github.com/tbd54566975/ssi-service/pkg/server/middleware"
+	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestPrincipalAttribution(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Run("Test Created By", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, keyStoreFactory := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, keyStoreFactory)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+				// sets the service path of credentials
+				_ = testCredentialRouter(tt, db, keyStoreService, didService, schemaService)
+
+				serviceConfig := config.CredentialServiceConfig{BatchUpdateStatusMaxItems: 10, EventLogEnabled: true}
+				credService, err := credential.NewCredentialService(serviceConfig, db, keyStoreService, didService.GetResolver(), schemaService)
+				require.NoError(tt, err)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(tt, err)
+				didRouter, _ := testDIDRouter(tt, db, keyStoreService, []string{didsdk.KeyMethod.String()}, keyStoreFactory)
+				schemaRouter := testSchemaRouter(tt, db, keyStoreService, didService)
+				webhookRouter := testWebhookRouter(tt, db)
+
+				// the key "hunter2" is named billing, and the key "swordfish" is known by the prefix of its hash
+				engine := gin.New()
+				engine.Use(middleware.Principal([]config.IssuerAPIKeyConfig{
+					{KeyHash: "f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7", Name: "billing", Issuers: []string{"*"}},
+					{KeyHash: "b9f195c5cc7ef6afadbfbc42892ad47d3b24c6bc94bb510c4564a90a14e8b799", Issuers: []string{"*"}},
+				}))
+				engine.PUT("/v1/credentials", credRouter.CreateCredential)
+				engine.GET("/v1/credentials", credRouter.ListCredentials)
+				engine.GET("/v1/credentials/events", credRouter.ListCredentialEvents)
+				engine.GET("/v1/credentials/:id", credRouter.GetCredential)
+				engine.PUT("/v1/credentials/:id/status", credRouter.UpdateCredentialStatus)
+				engine.DELETE("/v1/credentials/:id", credRouter.DeleteCredential)
+				engine.PUT("/v1/dids/:method", didRouter.CreateDIDByMethod)
+				engine.GET("/v1/dids/:method", didRouter.ListDIDsByMethod)
+				engine.GET("/v1/dids/:method/:id", didRouter.GetDIDByMethod)
+				engine.PUT("/v1/schemas", schemaRouter.CreateSchema)
+				engine.GET("/v1/schemas", schemaRouter.ListSchemas)
+				engine.PUT("/v1/webhooks", webhookRouter.CreateWebhook)
+				engine.GET("/v1/webhooks", webhookRouter.ListWebhooks)
+				serve := func(method, url, key string, body any, resp any) {
+					req := httptest.NewRequest(method, url, newRequestValue(tt, body))
+					if key != "" {
+						req.Header.Set(middleware.APIKeyHeader, key)
+					}
+					w := httptest.NewRecorder()
+					engine.ServeHTTP(w, req)
+					require.Less(tt, w.Code, 300, w.Body.String())
+					if resp != nil {
+						require.NoError(tt, json.NewDecoder(w.Body).Decode(resp))
+					}
+				}
+				const billing, other = "billing", "b9f195c5cc7e"
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{Method: didsdk.KeyMethod, KeyType: crypto.Ed25519})
+				require.NoError(tt, err)
+				issue := func(key string) router.CreateCredentialResponse {
+					var created router.CreateCredentialResponse
+					serve(http.MethodPut, "/v1/credentials", key, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						Data:                 map[string]any{"firstName": "Jack"},
+						Revocable:            true,
+					}, &created)
+					return created
+				}
+				byBilling := issue("hunter2")
+				assert.Equal(tt, billing, byBilling.CreatedBy)
+				byOther := issue("swordfish")
+				assert.Equal(tt, other, byOther.CreatedBy)
+				anonymous := issue("")
+				assert.Equal(tt, framework.AnonymousPrincipal, anonymous.CreatedBy)
+
+				var got router.GetCredentialResponse
+				serve(http.MethodGet, "/v1/credentials/"+byOther.ID, "", nil, &got)
+				assert.Equal(tt, other, got.CreatedBy)
+
+				listCredentials := func(createdBy string) []string {
+					var listed router.ListCredentialsResponse
+					serve(http.MethodGet, "/v1/credentials?createdBy="+createdBy, "", nil, &listed)
+					ids := make([]string, 0, len(listed.Credentials))
+					for _, cred := range listed.Credentials {
+						assert.Equal(tt, createdBy, cred.CreatedBy)
+						ids = append(ids, cred.ID)
+					}
+					return ids
+				}
+				assert.ElementsMatch(tt, []string{byBilling.ID}, listCredentials(billing))
+				assert.ElementsMatch(tt, []string{byOther.ID}, listCredentials(other))
+				assert.ElementsMatch(tt, []string{anonymous.ID}, listCredentials(framework.AnonymousPrincipal))
+				assert.Empty(tt, listCredentials("nobody"))
+
+				// status updates and deletions are recorded with the principal which made them
+				serve(http.MethodPut, "/v1/credentials/"+byBilling.ID+"/status", "swordfish", router.UpdateCredentialStatusRequest{Revoked: true}, nil)
+				serve(http.MethodDelete, "/v1/credentials/"+byBilling.ID, "", nil, nil)
+				var events router.ListCredentialEventsResponse
+				serve(http.MethodGet, "/v1/credentials/events", "", nil, &events)
+				var principals []string
+				for _, event := range events.Events {
+					if event.CredentialID == byBilling.ID {
+						principals = append(principals, string(event.Verb)+":"+event.Principal)
+					}
+				}
+				assert.Equal(tt, []string{
+					string(webhook.Create) + ":" + billing,
+					string(webhook.StatusUpdate) + ":" + other,
+					string(webhook.Delete) + ":" + framework.AnonymousPrincipal,
+				}, principals)
+
+				// DIDs
+				var createdDID router.CreateDIDByMethodResponse
+				serve(http.MethodPut, "/v1/dids/key", "hunter2", router.CreateDIDByMethodRequest{KeyType: crypto.Ed25519}, &createdDID)
+				var gotDID router.GetDIDByMethodResponse
+				serve(http.MethodGet, "/v1/dids/key/"+createdDID.DID.ID, "", nil, &gotDID)
+				assert.Equal(tt, billing, gotDID.CreatedBy)
+				var listedDIDs router.ListDIDsByMethodResponse
+				serve(http.MethodGet, "/v1/dids/key?createdBy="+billing, "", nil, &listedDIDs)
+				require.Len(tt, listedDIDs.DIDs, 1)
+				assert.Equal(tt, createdDID.DID.ID, listedDIDs.DIDs[0].ID)
+				assert.Equal(tt, map[string]string{createdDID.DID.ID: billing}, listedDIDs.CreatedBy)
+				var otherDIDs router.ListDIDsByMethodResponse
+				serve(http.MethodGet, "/v1/dids/key?createdBy="+other, "", nil, &otherDIDs)
+				assert.Empty(tt, otherDIDs.DIDs)
+
+				// schemas
+				var createdSchema router.CreateSchemaResponse
+				serve(http.MethodPut, "/v1/schemas", "swordfish", router.CreateSchemaRequest{Name: "test schema", Schema: getTestSchema()}, &createdSchema)
+				assert.Equal(tt, other, createdSchema.CreatedBy)
+				var listedSchemas router.ListSchemasResponse
+				serve(http.MethodGet, "/v1/schemas?createdBy="+other, "", nil, &listedSchemas)
+				require.Len(tt, listedSchemas.Schemas, 1)
+				assert.Equal(tt, createdSchema.ID, listedSchemas.Schemas[0].ID)
+				var billingSchemas router.ListSchemasResponse
+				serve(http.MethodGet, "/v1/schemas?createdBy="+billing, "", nil, &billingSchemas)
+				assert.Empty(tt, billingSchemas.Schemas)
+
+				// webhooks record the principal of each URL
+				for key, url := range map[string]string{"hunter2": "https://example.com/billing", "swordfish": "https://example.com/other"} {
+					serve(http.MethodPut, "/v1/webhooks", key, router.CreateWebhookRequest{Noun: webhook.Credential, Verb: webhook.Create, URL: url}, nil)
+				}
+				var listedWebhooks router.ListWebhooksResponse
+				serve(http.MethodGet, "/v1/webhooks?createdBy="+billing, "", nil, &listedWebhooks)
+				require.Len(tt, listedWebhooks.Webhooks, 1)
+				assert.Equal(tt, []string{"https://example.com/billing"}, listedWebhooks.Webhooks[0].Webhook.URLS)
+				assert.Equal(tt, billing, listedWebhooks.Webhooks[0].Webhook.CreatedBy["https://example.com/billing"])
+				var allWebhooks router.ListWebhooksResponse
+				serve(http.MethodGet, "/v1/webhooks", "", nil, &allWebhooks)
+				require.Len(tt, allWebhooks.Webhooks, 1)
+				assert.Len(tt, allWebhooks.Webhooks[0].Webhook.URLS, 2)
+			})
+		})
+	}
+}
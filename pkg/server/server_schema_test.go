@@ -12,10 +12,12 @@ import (
 	"github.com/TBD54566975/ssi-sdk/credential/schema"
 	"github.com/TBD54566975/ssi-sdk/crypto"
 	"github.com/goccy/go-json"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/server/framework"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
 	"github.com/tbd54566975/ssi-service/pkg/testutil"
@@ -66,6 +68,52 @@ func TestSchemaAPI(t *testing.T) {
 				assert.NotEmpty(tt, resp.Schema)
 			})
 
+			t.Run("Test Localized Error Messages", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaRouter(tt, db, keyStoreService, didService)
+
+				createBadSchema := func(acceptLanguage string) (framework.ErrorResponse, string) {
+					badSchemaRequest := router.CreateSchemaRequest{Schema: getTestSchema()}
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/schemas", newRequestValue(tt, badSchemaRequest))
+					if acceptLanguage != "" {
+						req.Header.Set("Accept-Language", acceptLanguage)
+					}
+					w := httptest.NewRecorder()
+					c := newRequestContext(w, req)
+					schemaService.CreateSchema(c)
+					assert.Equal(tt, http.StatusBadRequest, w.Code)
+
+					var errResp framework.ErrorResponse
+					require.NoError(tt, json.NewDecoder(w.Body).Decode(&errResp))
+					return errResp, w.Header().Get("Content-Language")
+				}
+
+				english, lang := createBadSchema("")
+				assert.Equal(tt, "en", lang)
+				spanish, lang := createBadSchema("es-MX,es;q=0.9,en;q=0.8")
+				assert.Equal(tt, "es", lang)
+				french, lang := createBadSchema("fr-CA, fr;q=0.9")
+				assert.Equal(tt, "fr", lang)
+				// untranslated languages fall back to english
+				german, lang := createBadSchema("de")
+				assert.Equal(tt, "en", lang)
+
+				assert.Equal(tt, framework.CodeValidationFailed, english.Code)
+				for _, errResp := range []framework.ErrorResponse{spanish, french, german} {
+					assert.Equal(tt, english.Code, errResp.Code)
+					assert.Equal(tt, english.Error, errResp.Error)
+				}
+				assert.NotEmpty(tt, english.LocalizedMessage)
+				assert.NotEqual(tt, english.LocalizedMessage, spanish.LocalizedMessage)
+				assert.NotEqual(tt, english.LocalizedMessage, french.LocalizedMessage)
+				assert.NotEqual(tt, spanish.LocalizedMessage, french.LocalizedMessage)
+				assert.Equal(tt, english.LocalizedMessage, german.LocalizedMessage)
+			})
+
 			t.Run("Test Create JsonCredentialSchema Schema", func(tt *testing.T) {
 				bolt := test.ServiceStorage(tt)
 				require.NotEmpty(tt, bolt)
@@ -292,6 +340,135 @@ func TestSchemaAPI(t *testing.T) {
 				schemaService.GetSchema(c)
 				assert.Contains(tt, w.Body.String(), "schema not found")
 			})
+
+			t.Run("Test Create Composed Schema", func(tt *testing.T) {
+				bolt := test.ServiceStorage(tt)
+				require.NotEmpty(tt, bolt)
+
+				keyStoreService, _ := testKeyStoreService(tt, bolt)
+				didService, _ := testDIDService(tt, bolt, keyStoreService, nil)
+				schemaService := testSchemaService(tt, bolt, keyStoreService, didService)
+				schemaRouter, err := router.NewSchemaRouter(schemaService)
+				require.NoError(tt, err)
+				credRouter := testCredentialRouter(tt, bolt, keyStoreService, didService, schemaService)
+
+				createSchema := func(name string, jsonSchema schema.JSONSchema) *httptest.ResponseRecorder {
+					schemaRequestValue := newRequestValue(tt, router.CreateSchemaRequest{Name: name, Schema: jsonSchema})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/schemas", schemaRequestValue)
+					w := httptest.NewRecorder()
+					c := newRequestContext(w, req)
+					schemaRouter.CreateSchema(c)
+					return w
+				}
+
+				// create a base schema
+				w := createSchema("base schema", map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"type":    "object",
+					"properties": map[string]any{
+						"credentialSubject": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"firstName": map[string]any{
+									"type": "string",
+								},
+							},
+							"required": []any{"firstName"},
+						},
+					},
+				})
+				assert.True(tt, util.Is2xxResponse(w.Code))
+				var baseResp router.CreateSchemaResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&baseResp))
+
+				// extend it, referencing the base by its URI
+				w = createSchema("extended schema", map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"allOf": []any{
+						map[string]any{"$ref": baseResp.Schema.ID()},
+						map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"credentialSubject": map[string]any{
+									"type": "object",
+									"properties": map[string]any{
+										"age": map[string]any{
+											"type": "integer",
+										},
+									},
+									"required": []any{"age"},
+								},
+							},
+						},
+					},
+				})
+				assert.True(tt, util.Is2xxResponse(w.Code))
+				var extendedResp router.CreateSchemaResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&extendedResp))
+
+				// the resolved schema embeds the base
+				resolved, _, err := schemaService.Resolve(context.Background(), extendedResp.ID)
+				require.NoError(tt, err)
+				assert.Contains(tt, resolved.String(), "firstName")
+				assert.NotContains(tt, resolved.String(), "$ref")
+
+				// a schema conflicting with the base is rejected at creation
+				w = createSchema("conflicting schema", map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"allOf": []any{
+						map[string]any{"$ref": baseResp.ID},
+						map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"credentialSubject": map[string]any{
+									"type": "object",
+									"properties": map[string]any{
+										"firstName": map[string]any{
+											"type": "integer",
+										},
+									},
+								},
+							},
+						},
+					},
+				})
+				assert.Contains(tt, w.Body.String(), "credentialSubject.firstName: type [string] conflicts with type [integer]")
+
+				// a schema referencing a missing schema is rejected
+				w = createSchema("missing schema", map[string]any{
+					"$schema": "https://json-schema.org/draft-07/schema",
+					"allOf":   []any{map[string]any{"$ref": uuid.NewString()}},
+				})
+				assert.Contains(tt, w.Body.String(), "resolving referenced schema")
+
+				// issuance validates against constraints from both schemas
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  "key",
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				createCredential := func(data map[string]any) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(tt, router.CreateCredentialRequest{
+						Issuer:               issuerDID.DID.ID,
+						VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+						Subject:              "did:abc:456",
+						SchemaID:             extendedResp.ID,
+						Data:                 data,
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+					w := httptest.NewRecorder()
+					c := newRequestContext(w, req)
+					credRouter.CreateCredential(c)
+					return w
+				}
+
+				w = createCredential(map[string]any{"firstName": "Satoshi", "age": 42})
+				assert.True(tt, util.Is2xxResponse(w.Code))
+
+				w = createCredential(map[string]any{"age": 42})
+				assert.False(tt, util.Is2xxResponse(w.Code))
+				assert.Contains(tt, w.Body.String(), "firstName")
+			})
 		})
 	}
 }
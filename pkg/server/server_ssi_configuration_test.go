@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/goccy/go-json"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	wellknown "github.com/tbd54566975/ssi-service/pkg/service/well-known"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestSSIConfigurationAPI(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(t *testing.T) {
+			t.Run("Test Get SSI Configuration", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+
+				issuerDID, err := didService.CreateDIDByMethod(context.Background(), did.CreateDIDRequest{
+					Method:  didsdk.KeyMethod,
+					KeyType: crypto.Ed25519,
+				})
+				require.NoError(tt, err)
+				signer := issuerDID.DID.VerificationMethod[0].ID
+
+				configurationService, err := wellknown.NewSSIConfigurationService(config.SSIConfigurationServiceConfig{Signer: signer, MaxAge: "5m"}, didService, schemaService, keyStoreService)
+				require.NoError(tt, err)
+				configurationRouter, err := router.NewSSIConfigurationRouter(configurationService)
+				require.NoError(tt, err)
+
+				getConfiguration := func(ifNoneMatch string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/.well-known/ssi-configuration", nil)
+					if ifNoneMatch != "" {
+						req.Header.Set("If-None-Match", ifNoneMatch)
+					}
+					w := httptest.NewRecorder()
+					configurationRouter.GetSSIConfiguration(newRequestContext(w, req))
+					return w
+				}
+
+				w := getConfiguration("")
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				etag := w.Header().Get("ETag")
+				assert.NotEmpty(tt, etag)
+				assert.Equal(tt, "public, max-age=300", w.Header().Get("Cache-Control"))
+				bundle := verifySSIConfiguration(tt, w, issuerDID.DID.ID)
+				assert.Empty(tt, bundle.Schemas)
+				assert.Equal(tt, config.GetStatusBase(), bundle.StatusListEndpoint)
+
+				// unchanged bundles are revalidated with their ETag
+				w = getConfiguration(etag)
+				assert.Equal(tt, http.StatusNotModified, w.Code)
+				assert.Equal(tt, etag, w.Header().Get("ETag"))
+
+				createdSchema, err := schemaService.CreateSchema(context.Background(), schema.CreateSchemaRequest{
+					Name:   "license schema",
+					Schema: getLicenseSchema(),
+				})
+				require.NoError(tt, err)
+
+				w = getConfiguration(etag)
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				assert.NotEqual(tt, etag, w.Header().Get("ETag"))
+				bundle = verifySSIConfiguration(tt, w, issuerDID.DID.ID)
+				require.Len(tt, bundle.Schemas, 1)
+				assert.Equal(tt, createdSchema.ID, bundle.Schemas[0].ID)
+				assert.Equal(tt, "license schema", bundle.Schemas[0].Name)
+				assert.Equal(tt, 1, bundle.Schemas[0].Version)
+			})
+
+			t.Run("Test SSI Configuration Disabled", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				keyStoreService, _ := testKeyStoreService(tt, db)
+				didService, _ := testDIDService(tt, db, keyStoreService, nil)
+				schemaService := testSchemaService(tt, db, keyStoreService, didService)
+
+				configurationService, err := wellknown.NewSSIConfigurationService(config.SSIConfigurationServiceConfig{}, didService, schemaService, keyStoreService)
+				require.NoError(tt, err)
+				configurationRouter, err := router.NewSSIConfigurationRouter(configurationService)
+				require.NoError(tt, err)
+
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/.well-known/ssi-configuration", nil)
+				w := httptest.NewRecorder()
+				configurationRouter.GetSSIConfiguration(newRequestContext(w, req))
+				assert.Equal(tt, http.StatusNotFound, w.Code)
+
+				_, err = wellknown.NewSSIConfigurationService(config.SSIConfigurationServiceConfig{Signer: "did:key:abc"}, didService, schemaService, keyStoreService)
+				assert.ErrorContains(tt, err, "not a fully qualified verification method")
+			})
+		})
+	}
+}
+
+// verifySSIConfiguration verifies the signature of the SSI configuration of the response with the key it advertises
+// for the issuer, and returns its claims.
+func verifySSIConfiguration(t *testing.T, w *httptest.ResponseRecorder, issuerID string) wellknown.SSIConfiguration {
+	var resp router.GetSSIConfigurationResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	token := resp.ConfigurationJWT.String()
+
+	parsed, err := jwt.ParseInsecure([]byte(token))
+	require.NoError(t, err)
+	assert.Equal(t, issuerID, parsed.Issuer())
+	claims, err := parsed.AsMap(context.Background())
+	require.NoError(t, err)
+	claimsBytes, err := json.Marshal(claims)
+	require.NoError(t, err)
+	var bundle wellknown.SSIConfiguration
+	require.NoError(t, json.Unmarshal(claimsBytes, &bundle))
+
+	message, err := jws.Parse([]byte(token))
+	require.NoError(t, err)
+	kid := message.Signatures()[0].ProtectedHeaders().KeyID()
+	var advertised *jwx.PublicKeyJWK
+	for _, issuer := range bundle.Issuers {
+		for i := range issuer.Keys {
+			if issuer.ID == issuerID && issuer.Keys[i].KID == kid {
+				advertised = &issuer.Keys[i]
+			}
+		}
+	}
+	require.NotNil(t, advertised, "the signing key<%s> is not advertised", kid)
+	verifier, err := jwx.NewJWXVerifierFromJWK(issuerID, *advertised)
+	require.NoError(t, err)
+	require.NoError(t, verifier.Verify(token))
+	return bundle
+}
@@ -2,12 +2,16 @@ package server
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	manifestsdk "github.com/TBD54566975/ssi-sdk/credential/manifest"
@@ -22,6 +26,7 @@ import (
 	credmodel "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/util"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service"
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
 	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
@@ -29,6 +34,7 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
 	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
@@ -50,6 +56,12 @@ func TestHealthCheckAPI(t *testing.T) {
 	shutdown := make(chan os.Signal, 1)
 	serviceConfig, err := config.LoadConfig("", nil)
 	assert.NoError(t, err)
+	serviceConfig.Services.StorageOptions = []storage.Option{
+		{
+			ID:     storage.BoltDBFilePathOption,
+			Option: filepath.Join(t.TempDir(), "health.db"),
+		},
+	}
 	server, err := NewSSIServer(shutdown, *serviceConfig)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, server)
@@ -105,6 +117,122 @@ func TestReadinessAPI(t *testing.T) {
 	assert.Len(t, resp.ServiceStatuses, 0)
 }
 
+func TestServiceLifecycle(t *testing.T) {
+	newServicesConfig := func(t *testing.T) config.ServicesConfig {
+		// the names of subtests have slashes, so each gets a file in its own directory
+		dbFile := filepath.Join(t.TempDir(), "lifecycle.db")
+		serviceConfig, err := config.LoadConfig("", nil)
+		require.NoError(t, err)
+		serviceConfig.Services.StorageOptions = []storage.Option{
+			{
+				ID:     storage.BoltDBFilePathOption,
+				Option: dbFile,
+			},
+		}
+		return serviceConfig.Services
+	}
+
+	t.Run("services start in dependency order", func(tt *testing.T) {
+		var started []string
+		record := func(name string, dependsOn ...string) service.Component {
+			return service.Component{
+				Name:      name,
+				DependsOn: dependsOn,
+				Start: func(_ *service.SSIService) error {
+					started = append(started, name)
+					return nil
+				},
+			}
+		}
+
+		ssi, err := service.InstantiateSSIService(newServicesConfig(tt),
+			// registered before what they depend on, so only dependencies can put them in order
+			record("after_credential", service.CredentialComponent, "after_keystore"),
+			record("after_keystore", service.KeyStoreComponent),
+		)
+		require.NoError(tt, err)
+		tt.Cleanup(func() { _ = ssi.Stop(context.Background()) })
+		assert.Equal(tt, []string{"after_keystore", "after_credential"}, started)
+
+		// every service reports its readiness
+		services := ssi.GetServices()
+		assert.Len(tt, services, 10)
+		for _, s := range services {
+			assert.True(tt, s.Status().IsReady(), s.Type())
+		}
+	})
+
+	t.Run("shutdown hooks run in reverse order before the storage is closed", func(tt *testing.T) {
+		var stopped []string
+		storageOpen := make(map[string]bool)
+		probe := func(name string, dependsOn ...string) service.Component {
+			return service.Component{
+				Name:      name,
+				DependsOn: dependsOn,
+				Start:     func(_ *service.SSIService) error { return nil },
+				Stop: func(_ context.Context, ssi *service.SSIService) error {
+					stopped = append(stopped, name)
+					storageOpen[name] = ssi.GetStorage().IsOpen()
+					return nil
+				},
+			}
+		}
+
+		ssi, err := service.InstantiateSSIService(newServicesConfig(tt),
+			probe("outer", "inner"),
+			probe("inner", service.CredentialComponent),
+			probe("independent", service.StorageComponent),
+		)
+		require.NoError(tt, err)
+		require.True(tt, ssi.GetStorage().IsOpen())
+
+		require.NoError(tt, ssi.Stop(context.Background()))
+		assert.Equal(tt, []string{"independent", "outer", "inner"}, stopped)
+		assert.Equal(tt, map[string]bool{"outer": true, "inner": true, "independent": true}, storageOpen)
+		assert.False(tt, ssi.GetStorage().IsOpen())
+	})
+
+	t.Run("failing to start stops the started components", func(tt *testing.T) {
+		var stopped []string
+		ssi, err := service.InstantiateSSIService(newServicesConfig(tt),
+			service.Component{
+				Name:      "started",
+				DependsOn: []string{service.StorageComponent},
+				Start:     func(_ *service.SSIService) error { return nil },
+				Stop: func(_ context.Context, _ *service.SSIService) error {
+					stopped = append(stopped, "started")
+					return nil
+				},
+			},
+			service.Component{
+				Name:      "failing",
+				DependsOn: []string{"started"},
+				Start:     func(_ *service.SSIService) error { return errors.New("bad start") },
+			},
+		)
+		assert.Error(tt, err)
+		assert.Contains(tt, err.Error(), "bad start")
+		assert.Nil(tt, ssi)
+		assert.Equal(tt, []string{"started"}, stopped)
+	})
+
+	t.Run("server shutdown stops background workers and services", func(tt *testing.T) {
+		serviceConfig, err := config.LoadConfig("", nil)
+		require.NoError(tt, err)
+		serviceConfig.Services = newServicesConfig(tt)
+		serviceConfig.Services.CredentialConfig.StatusListRefreshInterval = "1ms"
+
+		server, err := NewSSIServer(make(chan os.Signal, 1), *serviceConfig)
+		require.NoError(tt, err)
+		require.True(tt, server.GetStorage().IsOpen())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(tt, server.Shutdown(ctx))
+		assert.False(tt, server.GetStorage().IsOpen())
+	})
+}
+
 func newRequestValue(t *testing.T, data any) io.Reader {
 	dataBytes, err := json.Marshal(data)
 	require.NoError(t, err)
@@ -242,6 +370,7 @@ func testDIDService(t *testing.T, bolt storage.ServiceStorage, keyStore *keystor
 		LocalResolutionMethods: []string{"key", "web", "peer", "pkh"},
 		IONResolverURL:         testIONResolverURL,
 		BatchCreateMaxItems:    100,
+		BatchResolveMaxItems:   100,
 	}
 
 	// create a did service
@@ -290,6 +419,9 @@ func testCredentialService(t *testing.T, db storage.ServiceStorage, keyStore *ke
 	credentialService, err := credential.NewCredentialService(serviceConfig, db, keyStore, did.GetResolver(), schema)
 	require.NoError(t, err)
 	require.NotEmpty(t, credentialService)
+	credentialService.SetDisplayNames(did)
+	credentialService.SetDelegations(did)
+	schema.SetSchemaCredentialIssuer(credentialService)
 	return credentialService
 }
 
@@ -310,7 +442,7 @@ func testCredentialRouter(t *testing.T, bolt storage.ServiceStorage, keyStore *k
 
 func testManifest(t *testing.T, db storage.ServiceStorage, keyStore *keystore.Service, did *did.Service, credential *credential.Service) (*router.ManifestRouter, *manifest.Service) {
 	// create a manifest service
-	manifestService, err := manifest.NewManifestService(db, keyStore, did.GetResolver(), credential, nil)
+	manifestService, err := manifest.NewManifestService(config.ManifestServiceConfig{}, db, keyStore, did.GetResolver(), credential, nil)
 	require.NoError(t, err)
 	require.NotEmpty(t, manifestService)
 
@@ -322,6 +454,15 @@ func testManifest(t *testing.T, db storage.ServiceStorage, keyStore *keystore.Se
 	return manifestRouter, manifestService
 }
 
+func testManifestWithPresentations(t *testing.T, db storage.ServiceStorage, serviceConfig config.ManifestServiceConfig, keyStore *keystore.Service,
+	didService *did.Service, credentialService *credential.Service, presentationService *presentation.Service) *router.ManifestRouter {
+	manifestService, err := manifest.NewManifestService(serviceConfig, db, keyStore, didService.GetResolver(), credentialService, presentationService)
+	require.NoError(t, err)
+	manifestRouter, err := router.NewManifestRouter(manifestService)
+	require.NoError(t, err)
+	return manifestRouter
+}
+
 func testWebhookService(t *testing.T, bolt storage.ServiceStorage) *webhook.Service {
 	serviceConfig := config.WebhookServiceConfig{WebhookTimeout: "10s"}
 
@@ -3,6 +3,8 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net"
@@ -11,16 +13,23 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/gin-gonic/gin"
 	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/internal/verification"
 	"github.com/tbd54566975/ssi-service/pkg/server/router"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
 	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 	"github.com/tbd54566975/ssi-service/pkg/testutil"
@@ -352,7 +361,7 @@ func TestWebhookAPI(t *testing.T) {
 
 				webhookService := testWebhookService(tt, db)
 
-				gotWebhooks, err := webhookService.ListWebhooks(context.Background())
+				gotWebhooks, err := webhookService.ListWebhooks(context.Background(), webhook.ListWebhooksRequest{})
 				assert.NoError(tt, err)
 				assert.Len(tt, gotWebhooks.Webhooks, 0)
 
@@ -377,7 +386,7 @@ func TestWebhookAPI(t *testing.T) {
 				})
 				assert.NoError(tt, err)
 
-				gotWebhooks, err = webhookService.ListWebhooks(context.Background())
+				gotWebhooks, err = webhookService.ListWebhooks(context.Background(), webhook.ListWebhooksRequest{})
 				assert.NoError(tt, err)
 				assert.Len(tt, gotWebhooks.Webhooks, 2)
 			})
@@ -411,6 +420,441 @@ func TestWebhookAPI(t *testing.T) {
 				assert.ErrorContains(tt, err, "webhook does not exist")
 				assert.Empty(tt, gotWebhook)
 			})
+
+			t.Run("Test Templated Webhook Payloads", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				webhookService := testWebhookService(tt, db)
+				webhookRouter, err := router.NewWebhookRouter(webhookService)
+				require.NoError(tt, err)
+
+				received := make(chan []byte, 10)
+				receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(tt, err)
+					received <- body
+				}))
+				defer receiver.Close()
+
+				createWebhook := func(template string) *httptest.ResponseRecorder {
+					requestValue := newRequestValue(tt, router.CreateWebhookRequest{
+						Noun:       webhook.DID,
+						Verb:       webhook.Create,
+						URL:        receiver.URL,
+						Template:   template,
+						SampleData: []byte(`{"id":"did:example:sample"}`),
+					})
+					req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/webhooks", requestValue)
+					w := httptest.NewRecorder()
+					webhookRouter.CreateWebhook(newRequestContext(w, req))
+					return w
+				}
+
+				// templates which cannot be parsed, or fail on the sample event, are rejected
+				w := createWebhook(`{"text": {{json .data.id}`)
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				w = createWebhook(`{"text": {{json .data.name}}}`)
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+				w = createWebhook(`text: {{.data.id}}`)
+				assert.Equal(tt, http.StatusBadRequest, w.Code)
+
+				w = createWebhook(`{"text": {{json (printf "%s %s: %s" .noun .verb .data.id)}}}`)
+				require.Equal(tt, http.StatusCreated, w.Code, w.Body.String())
+
+				// the delivered body is the output of the template
+				webhookService.Publish(context.Background(), webhook.DID, webhook.Create, []byte(`{"id":"did:example:123"}`))
+				select {
+				case body := <-received:
+					assert.JSONEq(tt, `{"text": "DID Create: did:example:123"}`, string(body))
+				case <-time.After(5 * time.Second):
+					require.Fail(tt, "should receive the templated payload")
+				}
+
+				// when the template fails, the event is delivered untransformed with the error
+				webhookService.Publish(context.Background(), webhook.DID, webhook.Create, []byte(`{"name":"unexpected"}`))
+				select {
+				case body := <-received:
+					var payload webhook.Payload
+					require.NoError(tt, json.Unmarshal(body, &payload))
+					assert.Equal(tt, webhook.DID, payload.Noun)
+					assert.JSONEq(tt, `{"name":"unexpected"}`, string(payload.Data))
+					assert.Contains(tt, payload.TemplateError, "executing template")
+				case <-time.After(5 * time.Second):
+					require.Fail(tt, "should receive the untransformed payload")
+				}
+
+				// the history keeps the untransformed events
+				req := httptest.NewRequest(http.MethodGet, "https://ssi-service.com/v1/webhooks/DID/Create/deliveries", nil)
+				w = httptest.NewRecorder()
+				webhookRouter.ListWebhookDeliveries(newRequestContextWithParams(w, req, map[string]string{"noun": "DID", "verb": "Create"}))
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				var resp router.ListWebhookDeliveriesResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(tt, resp.Deliveries, 2)
+				assert.NotEmpty(tt, resp.Deliveries[0].TemplateError)
+				assert.Empty(tt, resp.Deliveries[1].TemplateError)
+				assert.Empty(tt, resp.Deliveries[1].Error)
+				assert.JSONEq(tt, `{"text": "DID Create: did:example:123"}`, resp.Deliveries[1].Body)
+				var event webhook.Payload
+				require.NoError(tt, json.Unmarshal(resp.Deliveries[1].Event, &event))
+				assert.Equal(tt, receiver.URL, event.URL)
+				assert.JSONEq(tt, `{"id":"did:example:123"}`, string(event.Data))
+			})
+
+			t.Run("Test Webhook Test Events", func(tt *testing.T) {
+				db := test.ServiceStorage(tt)
+				require.NotEmpty(tt, db)
+
+				webhookService := testWebhookService(tt, db)
+				webhookRouter, err := router.NewWebhookRouter(webhookService)
+				require.NoError(tt, err)
+
+				received := make(chan []byte, 10)
+				receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(tt, err)
+					received <- body
+				}))
+				defer receiver.Close()
+				failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}))
+				defer failing.Close()
+
+				for _, url := range []string{receiver.URL, failing.URL} {
+					_, err = webhookService.CreateWebhook(context.Background(), webhook.CreateWebhookRequest{Noun: webhook.Credential, Verb: webhook.Create, URL: url})
+					require.NoError(tt, err)
+				}
+
+				testWebhook := func(noun, verb string, request *router.TestWebhookRequest) *httptest.ResponseRecorder {
+					var body io.Reader
+					if request != nil {
+						body = newRequestValue(tt, *request)
+					}
+					req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("https://ssi-service.com/v1/webhooks/%s/%s/test", noun, verb), body)
+					w := httptest.NewRecorder()
+					webhookRouter.TestWebhook(newRequestContextWithParams(w, req, map[string]string{"noun": noun, "verb": verb}))
+					return w
+				}
+
+				// the test event is posted to every URL, and the outcome of each delivery is reported
+				w := testWebhook("Credential", "Create", nil)
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				var resp router.TestWebhookResponse
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(tt, resp.Deliveries, 2)
+				for _, delivery := range resp.Deliveries {
+					if delivery.URL == failing.URL {
+						assert.Contains(tt, delivery.Error, "503")
+					} else {
+						assert.Empty(tt, delivery.Error)
+					}
+				}
+
+				select {
+				case body := <-received:
+					var payload webhook.Payload
+					require.NoError(tt, json.Unmarshal(body, &payload))
+					assert.True(tt, payload.Test)
+					assert.Equal(tt, webhook.Credential, payload.Noun)
+					assert.Equal(tt, webhook.Create, payload.Verb)
+					assert.Equal(tt, receiver.URL, payload.URL)
+					assert.NotEmpty(tt, payload.Data)
+				case <-time.After(5 * time.Second):
+					require.Fail(tt, "should receive the test event")
+				}
+
+				// a URL and data can be chosen
+				w = testWebhook("Credential", "Create", &router.TestWebhookRequest{URL: receiver.URL, Data: []byte(`{"id":"test-credential"}`)})
+				require.Equal(tt, http.StatusOK, w.Code, w.Body.String())
+				require.NoError(tt, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(tt, resp.Deliveries, 1)
+				assert.Equal(tt, receiver.URL, resp.Deliveries[0].URL)
+				select {
+				case body := <-received:
+					var payload webhook.Payload
+					require.NoError(tt, json.Unmarshal(body, &payload))
+					assert.True(tt, payload.Test)
+					assert.JSONEq(tt, `{"id":"test-credential"}`, string(payload.Data))
+				case <-time.After(5 * time.Second):
+					require.Fail(tt, "should receive the test event")
+				}
+
+				// test events are recorded in the deliveries of the webhook
+				deliveries, err := webhookService.ListDeliveries(context.Background(), webhook.ListDeliveriesRequest{Noun: webhook.Credential, Verb: webhook.Create})
+				require.NoError(tt, err)
+				require.Len(tt, deliveries.Deliveries, 3)
+				var event webhook.Payload
+				require.NoError(tt, json.Unmarshal(deliveries.Deliveries[0].Event, &event))
+				assert.True(tt, event.Test)
+
+				// webhooks and URLs which are not registered can't be tested
+				w = testWebhook("DID", "Create", nil)
+				assert.Equal(tt, http.StatusNotFound, w.Code)
+				w = testWebhook("Credential", "Create", &router.TestWebhookRequest{URL: "https://example.com/unknown"})
+				assert.Equal(tt, http.StatusNotFound, w.Code)
+
+				// test events are rate limited
+				engine := gin.New()
+				require.NoError(tt, WebhookAPI(engine.Group("/v1"), webhookService, 1))
+				post := func() int {
+					req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/Credential/Create/test", newRequestValue(tt, router.TestWebhookRequest{URL: receiver.URL}))
+					w := httptest.NewRecorder()
+					engine.ServeHTTP(w, req)
+					return w.Code
+				}
+				assert.Equal(tt, http.StatusOK, post())
+				assert.Equal(tt, http.StatusTooManyRequests, post())
+			})
+		})
+	}
+}
+
+func TestVerificationFailedWebhooks(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(tt *testing.T) {
+			tt.Run("Test Revoked Credential Verification Failures", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+				presService, err := presentation.NewPresentationService(config.PresentationServiceConfig{}, db, didService.GetResolver(), schemaService, keyStoreService)
+				require.NoError(ttt, err)
+				presRouter, err := router.NewPresentationRouter(presService)
+				require.NoError(ttt, err)
+
+				webhookService := testWebhookService(ttt, db)
+				credService.OnVerificationFailed(publishVerificationFailed(webhookService, webhook.Credential))
+				presService.OnVerificationFailed(publishVerificationFailed(webhookService, webhook.Presentation))
+
+				received := make(chan webhook.Payload, 10)
+				receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var payload webhook.Payload
+					assert.NoError(ttt, json.NewDecoder(r.Body).Decode(&payload))
+					received <- payload
+				}))
+				defer receiver.Close()
+				filtered := make(chan struct{}, 10)
+				filteredReceiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					filtered <- struct{}{}
+				}))
+				defer filteredReceiver.Close()
+
+				for _, noun := range []webhook.Noun{webhook.Credential, webhook.Presentation} {
+					_, err = webhookService.CreateWebhook(context.Background(), webhook.CreateWebhookRequest{
+						Noun:         noun,
+						Verb:         webhook.VerificationFailed,
+						URL:          receiver.URL,
+						FailureCodes: []string{verification.Revoked},
+					})
+					require.NoError(ttt, err)
+					_, err = webhookService.CreateWebhook(context.Background(), webhook.CreateWebhookRequest{
+						Noun:         noun,
+						Verb:         webhook.VerificationFailed,
+						URL:          filteredReceiver.URL,
+						FailureCodes: []string{verification.SchemaMismatch},
+					})
+					require.NoError(ttt, err)
+				}
+
+				issuerDID := createDID(ttt, didService)
+				w := httptest.NewRecorder()
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					Revocable:            true,
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+
+				w = httptest.NewRecorder()
+				requestValue = newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+				req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("%s/status", createResp.Credential.ID), requestValue)
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": idFromURI(createResp.Credential.ID)}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				credHash := sha256.Sum256([]byte(createResp.CredentialJWT.String()))
+				receiveFailure := func(noun webhook.Noun) verification.Failure {
+					select {
+					case payload := <-received:
+						assert.Equal(ttt, noun, payload.Noun)
+						assert.Equal(ttt, webhook.VerificationFailed, payload.Verb)
+						var failure verification.Failure
+						require.NoError(ttt, json.Unmarshal(payload.Data, &failure))
+						assert.NotContains(ttt, string(payload.Data), "Jack")
+						return failure
+					case <-time.After(5 * time.Second):
+						require.Fail(ttt, "should receive a verification failure")
+					}
+					return verification.Failure{}
+				}
+
+				// verifying the revoked credential
+				w = httptest.NewRecorder()
+				requestValue = newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: createResp.CredentialJWT})
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", requestValue)
+				credRouter.VerifyCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var verifyCredResp router.VerifyCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&verifyCredResp))
+				assert.False(ttt, verifyCredResp.Verified)
+				assert.Equal(ttt, verification.Revoked, verifyCredResp.ReasonCode)
+
+				failure := receiveFailure(webhook.Credential)
+				assert.Equal(ttt, verification.Revoked, failure.ReasonCode)
+				assert.Equal(ttt, []string{hex.EncodeToString(credHash[:])}, failure.CredentialHashes)
+
+				// presenting the revoked credential
+				holderSigner, holderDID := getSigner(ttt)
+				presentationJWT, err := integrity.SignVerifiablePresentationJWT(holderSigner, &integrity.JWTVVPParameters{Audience: []string{holderSigner.ID}}, credsdk.VerifiablePresentation{
+					Context:              []string{"https://www.w3.org/2018/credentials/v1"},
+					Type:                 []string{"VerifiablePresentation"},
+					Holder:               holderDID.String(),
+					VerifiableCredential: []any{createResp.CredentialJWT},
+				})
+				require.NoError(ttt, err)
+
+				w = httptest.NewRecorder()
+				requestValue = newRequestValue(ttt, router.VerifyPresentationRequest{PresentationJWT: keyaccess.JWTPtr(string(presentationJWT))})
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/presentations/verification", requestValue)
+				presRouter.VerifyPresentation(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var verifyPresResp router.VerifyPresentationResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&verifyPresResp))
+				assert.False(ttt, verifyPresResp.Verified)
+				assert.Equal(ttt, verification.Revoked, verifyPresResp.ReasonCode)
+
+				failure = receiveFailure(webhook.Presentation)
+				assert.Equal(ttt, verification.Revoked, failure.ReasonCode)
+				assert.Equal(ttt, holderDID.String(), failure.Presenter)
+				assert.Equal(ttt, []string{hex.EncodeToString(credHash[:])}, failure.CredentialHashes)
+
+				// failures with other reason codes are not posted to URLs filtering them out
+				w = httptest.NewRecorder()
+				requestValue = newRequestValue(ttt, router.VerifyCredentialRequest{CredentialJWT: keyaccess.JWTPtr("bad")})
+				req = httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials/verification", requestValue)
+				credRouter.VerifyCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				select {
+				case <-received:
+					assert.Fail(ttt, "should not receive failures filtered out")
+				case <-filtered:
+					assert.Fail(ttt, "should not receive failures filtered out")
+				case <-time.After(time.Second):
+				}
+			})
+		})
+	}
+}
+
+func TestWebhookOutbox(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(tt *testing.T) {
+			tt.Run("Test Outbox Delivers Events After Restart", func(ttt *testing.T) {
+				db := test.ServiceStorage(ttt)
+				require.NotEmpty(ttt, db)
+
+				keyStoreService, _ := testKeyStoreService(ttt, db)
+				didService, _ := testDIDService(ttt, db, keyStoreService, nil)
+				schemaService := testSchemaService(ttt, db, keyStoreService, didService)
+				credService := testCredentialService(ttt, db, keyStoreService, didService, schemaService)
+				credService.EnableOutbox()
+				credRouter, err := router.NewCredentialRouter(credService)
+				require.NoError(ttt, err)
+
+				serviceConfig := config.WebhookServiceConfig{WebhookTimeout: "10s", OutboxEnabled: true, OutboxDispatchInterval: "1s"}
+				webhookService, err := webhook.NewWebhookService(serviceConfig, db)
+				require.NoError(ttt, err)
+				assert.True(ttt, webhookService.Outboxed(webhook.Credential, webhook.Create))
+				assert.True(ttt, webhookService.Outboxed(webhook.Credential, webhook.StatusUpdate))
+				assert.False(ttt, webhookService.Outboxed(webhook.Credential, webhook.Delete))
+
+				var available atomic.Bool
+				var attempts atomic.Int64
+				received := make(chan webhook.Payload, 10)
+				receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					attempts.Add(1)
+					if !available.Load() {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					var payload webhook.Payload
+					assert.NoError(ttt, json.NewDecoder(r.Body).Decode(&payload))
+					received <- payload
+				}))
+				defer receiver.Close()
+				for _, verb := range []webhook.Verb{webhook.Create, webhook.StatusUpdate} {
+					_, err = webhookService.CreateWebhook(context.Background(), webhook.CreateWebhookRequest{Noun: webhook.Credential, Verb: verb, URL: receiver.URL})
+					require.NoError(ttt, err)
+				}
+
+				issuerDID := createDID(ttt, didService)
+				w := httptest.NewRecorder()
+				requestValue := newRequestValue(ttt, router.CreateCredentialRequest{
+					Issuer:               issuerDID.DID.ID,
+					VerificationMethodID: issuerDID.DID.VerificationMethod[0].ID,
+					Subject:              "did:abc:456",
+					Data:                 map[string]any{"firstName": "Jack"},
+					Revocable:            true,
+				})
+				req := httptest.NewRequest(http.MethodPut, "https://ssi-service.com/v1/credentials", requestValue)
+				credRouter.CreateCredential(newRequestContext(w, req))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+				var createResp router.CreateCredentialResponse
+				require.NoError(ttt, json.NewDecoder(w.Body).Decode(&createResp))
+
+				w = httptest.NewRecorder()
+				requestValue = newRequestValue(ttt, router.UpdateCredentialStatusRequest{Revoked: true})
+				req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("%s/status", createResp.Credential.ID), requestValue)
+				credRouter.UpdateCredentialStatus(newRequestContextWithParams(w, req, map[string]string{"id": idFromURI(createResp.Credential.ID)}))
+				require.True(ttt, util.Is2xxResponse(w.Code))
+
+				// the dispatcher is not running between the commits and the deliveries, so nothing is delivered
+				assert.Zero(ttt, attempts.Load())
+
+				// while the receiver is down, the created event is kept, and holds back the status update of the credential
+				published, err := webhookService.DispatchOutbox(context.Background())
+				require.NoError(ttt, err)
+				assert.Zero(ttt, published)
+				assert.EqualValues(ttt, 1, attempts.Load())
+
+				// a restarted dispatcher delivers the events, in order
+				available.Store(true)
+				restarted, err := webhook.NewWebhookService(serviceConfig, db)
+				require.NoError(ttt, err)
+				published, err = restarted.DispatchOutbox(context.Background())
+				require.NoError(ttt, err)
+				assert.Equal(ttt, 2, published)
+
+				created := <-received
+				assert.Equal(ttt, webhook.Create, created.Verb)
+				var createdData router.CreateCredentialResponse
+				require.NoError(ttt, json.Unmarshal(created.Data, &createdData))
+				assert.Equal(ttt, createResp.ID, createdData.ID)
+				statusUpdated := <-received
+				assert.Equal(ttt, webhook.StatusUpdate, statusUpdated.Verb)
+				var statusData router.UpdateCredentialStatusResponse
+				require.NoError(ttt, json.Unmarshal(statusUpdated.Data, &statusData))
+				assert.True(ttt, statusData.Revoked)
+
+				// delivered events are removed from the outbox
+				published, err = restarted.DispatchOutbox(context.Background())
+				require.NoError(ttt, err)
+				assert.Zero(ttt, published)
+				assert.Empty(ttt, received)
+			})
 		})
 	}
 }
@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/statusconsistency"
+	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
+)
+
+var ErrStatusConsistencyNotSupported = errors.New("status consistency cannot be checked without the credential service")
+
+// SetCredentialService sets the service checking the consistency of credential statuses, and freezing issuers.
+// Neither is supported until it is set.
+func (s *Service) SetCredentialService(credentialService *credential.Service) {
+	s.credentialService = credentialService
+}
+
+// CheckStatusConsistency starts comparing the stored status of credentials to the bits of their status lists,
+// returning the operation which reports the mismatches once it is done. When repair is requested, the status lists
+// with mismatches are regenerated from the stored status of their credentials.
+func (s Service) CheckStatusConsistency(ctx context.Context, request credential.CheckStatusConsistencyRequest) (*operation.Operation, error) {
+	if s.credentialService == nil {
+		return nil, sdkutil.LoggingError(ErrStatusConsistencyNotSupported)
+	}
+	storedOp := opstorage.StoredOperation{ID: statusconsistency.IDFromCheckID(uuid.NewString())}
+	if err := s.opsStorage.StoreOperation(ctx, storedOp); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "storing status consistency operation")
+	}
+
+	// the check outlives the request starting it
+	go s.checkStatusConsistency(context.WithoutCancel(ctx), storedOp, request)
+
+	return operation.ServiceModel(storedOp)
+}
+
+// checkStatusConsistency checks the consistency of credential statuses, and marks the operation as done with the
+// result.
+func (s Service) checkStatusConsistency(ctx context.Context, storedOp opstorage.StoredOperation, request credential.CheckStatusConsistencyRequest) {
+	logrus.Infof("checking status consistency in operation<%s>, repairing: %t", storedOp.ID, request.Repair)
+
+	storedOp.Done = true
+	result, err := s.credentialService.CheckStatusConsistency(ctx, request)
+	if err != nil {
+		logrus.WithError(err).Errorf("checking status consistency in operation<%s>", storedOp.ID)
+		storedOp.Error = err.Error()
+	} else if storedOp.Response, err = json.Marshal(result); err != nil {
+		storedOp.Error = err.Error()
+	}
+	if err = s.opsStorage.StoreOperation(ctx, storedOp); err != nil {
+		logrus.WithError(err).Errorf("storing result of status consistency operation<%s>", storedOp.ID)
+	}
+}
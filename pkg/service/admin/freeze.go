@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+)
+
+var ErrIssuerFreezeNotSupported = errors.New("issuers cannot be frozen without the credential service")
+
+// FreezeIssuer stops the issuance of the issuer's credentials, and changes to their status, until it is unfrozen.
+func (s Service) FreezeIssuer(ctx context.Context, request credential.FreezeIssuerRequest) (*credential.IssuerFreeze, error) {
+	if s.credentialService == nil {
+		return nil, sdkutil.LoggingError(ErrIssuerFreezeNotSupported)
+	}
+	return s.credentialService.FreezeIssuer(ctx, request)
+}
+
+// UnfreezeIssuer lifts the freeze of an issuer, returning the freeze lifted.
+func (s Service) UnfreezeIssuer(ctx context.Context, request credential.UnfreezeIssuerRequest) (*credential.IssuerFreeze, error) {
+	if s.credentialService == nil {
+		return nil, sdkutil.LoggingError(ErrIssuerFreezeNotSupported)
+	}
+	return s.credentialService.UnfreezeIssuer(ctx, request)
+}
+
+// ListIssuerFreezes lists the freezes of the frozen issuers.
+func (s Service) ListIssuerFreezes(ctx context.Context) (*credential.ListIssuerFreezesResponse, error) {
+	if s.credentialService == nil {
+		return nil, sdkutil.LoggingError(ErrIssuerFreezeNotSupported)
+	}
+	return s.credentialService.ListIssuerFreezes(ctx)
+}
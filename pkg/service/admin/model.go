@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"github.com/goccy/go-json"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
+	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+const (
+	// BundleVersion is the version of the bundle format produced by this service
+	BundleVersion = "1"
+)
+
+// Bundle holds the objects needed to configure an environment, as stored by the service, so that they can be
+// recreated with their original IDs in another deployment.
+type Bundle struct {
+	Version                 string                        `json:"version"`
+	Schemas                 []schema.StoredSchema         `json:"schemas,omitempty"`
+	PresentationDefinitions []prestorage.StoredDefinition `json:"presentationDefinitions,omitempty"`
+	Manifests               []manifeststg.StoredManifest  `json:"manifests,omitempty"`
+	DIDs                    []BundledDID                  `json:"dids,omitempty"`
+
+	// The private keys controlled by the bundled DIDs, encrypted with the passphrase given on export.
+	EncryptedKeys *EncryptedKeys `json:"encryptedKeys,omitempty"`
+}
+
+// BundledDID holds a DID exactly as it is stored, so that data specific to its method (such as ION operations)
+// survives the round trip.
+type BundledDID struct {
+	did.DefaultStoredDID
+	raw json.RawMessage
+}
+
+func (b *BundledDID) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.DefaultStoredDID); err != nil {
+		return err
+	}
+	b.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+func (b BundledDID) MarshalJSON() ([]byte, error) {
+	if len(b.raw) > 0 {
+		return b.raw, nil
+	}
+	return json.Marshal(b.DefaultStoredDID)
+}
+
+// EncryptedKeys is a set of keys encrypted with XChaCha20-Poly1305, using a key derived from a passphrase with Argon2.
+type EncryptedKeys struct {
+	Salt       []byte `json:"salt"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type ExportBundleRequest struct {
+	// When true, DIDs are exported along with their keys, which requires a passphrase.
+	IncludeDIDs bool
+	Passphrase  string
+}
+
+func (r ExportBundleRequest) IsValid() error {
+	if r.IncludeDIDs && r.Passphrase == "" {
+		return ErrPassphraseRequired
+	}
+	return nil
+}
+
+type ExportBundleResponse struct {
+	Bundle Bundle
+}
+
+type ImportBundleRequest struct {
+	Bundle Bundle
+
+	// Required when the bundle contains encrypted keys.
+	Passphrase string
+
+	// When true, items whose ID already exists are replaced. Otherwise, they are skipped.
+	Overwrite bool
+}
+
+type ItemType string
+
+const (
+	SchemaItem                 ItemType = "schema"
+	PresentationDefinitionItem ItemType = "presentationDefinition"
+	ManifestItem               ItemType = "manifest"
+	DIDItem                    ItemType = "did"
+	KeyItem                    ItemType = "key"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusCreated     ImportStatus = "created"
+	ImportStatusOverwritten ImportStatus = "overwritten"
+	ImportStatusSkipped     ImportStatus = "skipped"
+	ImportStatusFailed      ImportStatus = "failed"
+)
+
+// ImportResult describes what happened to a single item of an imported bundle.
+type ImportResult struct {
+	Type ItemType `json:"type"`
+	ID   string   `json:"id"`
+	// The ID the item had in the bundle, when it was imported with a new ID.
+	OriginalID string       `json:"originalId,omitempty"`
+	Status     ImportStatus `json:"status"`
+	Reason     string       `json:"reason,omitempty"`
+}
+
+type ImportBundleResponse struct {
+	Results []ImportResult
+}
+
+type ImportItemRequest struct {
+	// A bundle holding a single presentation definition or manifest, and the schemas it references.
+	Bundle Bundle
+
+	// When true, the items of the bundle keep their IDs. Otherwise, they are given new IDs, and the references
+	// between them are updated.
+	PreserveIDs bool
+
+	// When true, items whose ID already exists are replaced. Otherwise, they are skipped.
+	Overwrite bool
+}
+
+type ImportItemResponse struct {
+	// ID of the imported presentation definition or manifest.
+	ID      string
+	Results []ImportResult
+	// DIDs referenced by the imported item, such as its issuer, which are not stored by the service.
+	MissingIssuerDIDs []string
+}
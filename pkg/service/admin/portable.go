@@ -0,0 +1,361 @@
+package admin
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.einride.tech/aip/filtering"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
+	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// ExportPresentationDefinition collects a presentation definition into a bundle, along with the schemas stored by the
+// service it references, so that it can be imported into another deployment without them.
+func (s Service) ExportPresentationDefinition(ctx context.Context, id string) (*ExportBundleResponse, error) {
+	logrus.Debugf("exporting presentation definition: %s", id)
+
+	definition, err := s.presentationStorage.GetDefinition(ctx, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting presentation definition: %s", id)
+	}
+	schemas, err := s.referencedSchemas(ctx, definition.PresentationDefinition)
+	if err != nil {
+		return nil, err
+	}
+	bundle := Bundle{
+		Version:                 BundleVersion,
+		Schemas:                 schemas,
+		PresentationDefinitions: []prestorage.StoredDefinition{*definition},
+	}
+	return &ExportBundleResponse{Bundle: bundle}, nil
+}
+
+// ExportManifest is like ExportPresentationDefinition, for a credential manifest. The presentation definition of the
+// manifest, if any, is part of the manifest.
+func (s Service) ExportManifest(ctx context.Context, id string) (*ExportBundleResponse, error) {
+	logrus.Debugf("exporting manifest: %s", id)
+
+	storedManifest, err := s.manifestStorage.GetManifest(ctx, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting manifest: %s", id)
+	}
+	schemas, err := s.referencedSchemas(ctx, storedManifest.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	bundle := Bundle{
+		Version:   BundleVersion,
+		Schemas:   schemas,
+		Manifests: []manifeststg.StoredManifest{*storedManifest},
+	}
+	return &ExportBundleResponse{Bundle: bundle}, nil
+}
+
+// referencedSchemas returns the stored schemas referenced by a value, by ID or URI, along with the schemas they are
+// composed of, ordered by ID.
+func (s Service) referencedSchemas(ctx context.Context, value any) ([]schema.StoredSchema, error) {
+	storedSchemas, err := s.schemaStorage.ListSchemas(ctx, filtering.Filter{}, common.Page{Size: -1})
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing schemas")
+	}
+	byID := make(map[string]schema.StoredSchema, len(storedSchemas.Schemas))
+	for _, storedSchema := range storedSchemas.Schemas {
+		byID[storedSchema.ID] = storedSchema
+	}
+
+	referenced := make(map[string]schema.StoredSchema)
+	var collect func(value any) error
+	collect = func(value any) error {
+		generic, err := toGeneric(value)
+		if err != nil {
+			return err
+		}
+		var found []schema.StoredSchema
+		walkStrings(generic, func(s string) string {
+			if id, ok := schema.StoredSchemaID(s); ok {
+				if storedSchema, stored := byID[id]; stored {
+					if _, seen := referenced[id]; !seen {
+						referenced[id] = storedSchema
+						found = append(found, storedSchema)
+					}
+				}
+			}
+			return s
+		})
+		// credential schemas are signed, so only JSON schemas can reference other schemas
+		for _, storedSchema := range found {
+			if storedSchema.Schema == nil {
+				continue
+			}
+			if err = collect(*storedSchema.Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = collect(value); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "collecting referenced schemas")
+	}
+
+	schemas := make([]schema.StoredSchema, 0, len(referenced))
+	for _, storedSchema := range referenced {
+		schemas = append(schemas, storedSchema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].ID < schemas[j].ID })
+	return schemas, nil
+}
+
+// ImportPresentationDefinition imports a bundle produced by ExportPresentationDefinition. The schemas of the bundle
+// are imported first, and the references of the definition to them are updated to their IDs in this service. DIDs the
+// definition references which the service does not store are reported, since credentials from them can't be issued
+// by this deployment.
+func (s Service) ImportPresentationDefinition(ctx context.Context, request ImportItemRequest) (*ImportItemResponse, error) {
+	logrus.Debugf("importing presentation definition, preserving IDs: %t", request.PreserveIDs)
+
+	bundle := request.Bundle
+	if err := checkItemBundle(bundle); err != nil {
+		return nil, err
+	}
+	if len(bundle.PresentationDefinitions) != 1 || len(bundle.Manifests) != 0 {
+		return nil, sdkutil.LoggingNewError("bundle must hold exactly one presentation definition, and no manifest")
+	}
+
+	ids, results := s.importReferencedSchemas(ctx, request)
+	definition := bundle.PresentationDefinitions[0]
+	originalID := definition.ID
+	if !request.PreserveIDs {
+		definition.ID = uuid.NewString()
+	}
+	rewritten, err := rewriteReferences(definition.PresentationDefinition, ids)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "updating references of presentation definition<%s>", originalID)
+	}
+	definition.PresentationDefinition = rewritten
+	definition.PresentationDefinition.ID = definition.ID
+
+	_, getErr := s.presentationStorage.GetDefinition(ctx, definition.ID)
+	result := s.importItem(PresentationDefinitionItem, definition.ID, getErr == nil, nil, request.Overwrite, func() error {
+		if err := exchange.IsValidPresentationDefinition(definition.PresentationDefinition); err != nil {
+			return errors.Wrap(err, "invalid presentation definition")
+		}
+		return s.presentationStorage.StoreDefinition(ctx, definition)
+	})
+	if definition.ID != originalID {
+		result.OriginalID = originalID
+	}
+
+	missingDIDs, err := s.missingDIDs(ctx, definition.PresentationDefinition)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportItemResponse{
+		ID:                definition.ID,
+		Results:           append(results, result),
+		MissingIssuerDIDs: missingDIDs,
+	}, nil
+}
+
+// ImportManifest is like ImportPresentationDefinition, for a bundle produced by ExportManifest. The issuer of the
+// manifest is reported when the service does not store it.
+func (s Service) ImportManifest(ctx context.Context, request ImportItemRequest) (*ImportItemResponse, error) {
+	logrus.Debugf("importing manifest, preserving IDs: %t", request.PreserveIDs)
+
+	bundle := request.Bundle
+	if err := checkItemBundle(bundle); err != nil {
+		return nil, err
+	}
+	if len(bundle.Manifests) != 1 || len(bundle.PresentationDefinitions) != 0 {
+		return nil, sdkutil.LoggingNewError("bundle must hold exactly one manifest, and no presentation definition")
+	}
+
+	ids, results := s.importReferencedSchemas(ctx, request)
+	storedManifest := bundle.Manifests[0]
+	originalID := storedManifest.ID
+	if !request.PreserveIDs {
+		storedManifest.ID = uuid.NewString()
+	}
+	rewritten, err := rewriteReferences(storedManifest.Manifest, ids)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "updating references of manifest<%s>", originalID)
+	}
+	storedManifest.Manifest = rewritten
+	storedManifest.Manifest.ID = storedManifest.ID
+
+	_, getErr := s.manifestStorage.GetManifest(ctx, storedManifest.ID)
+	result := s.importItem(ManifestItem, storedManifest.ID, getErr == nil, nil, request.Overwrite, func() error {
+		if err := storedManifest.Manifest.IsValid(); err != nil {
+			return errors.Wrap(err, "invalid manifest")
+		}
+		return s.manifestStorage.StoreManifest(ctx, storedManifest)
+	})
+	if storedManifest.ID != originalID {
+		result.OriginalID = originalID
+	}
+
+	missingDIDs, err := s.missingDIDs(ctx, []any{storedManifest.IssuerDID, storedManifest.Manifest})
+	if err != nil {
+		return nil, err
+	}
+	return &ImportItemResponse{
+		ID:                storedManifest.ID,
+		Results:           append(results, result),
+		MissingIssuerDIDs: missingDIDs,
+	}, nil
+}
+
+// checkItemBundle makes sure a bundle can be imported as a single item, which is never exported with DIDs or keys.
+func checkItemBundle(bundle Bundle) error {
+	if bundle.Version != BundleVersion {
+		return sdkutil.LoggingNewErrorf("unsupported bundle version: %s", bundle.Version)
+	}
+	if len(bundle.DIDs) != 0 || bundle.EncryptedKeys != nil {
+		return sdkutil.LoggingNewError("bundle must not hold DIDs or keys; import it as a whole instead")
+	}
+	return nil
+}
+
+// importReferencedSchemas imports the schemas of a bundle, giving them new IDs unless the request preserves them. It
+// returns the ID in this service of each schema of the bundle, keyed by its ID in the bundle. Credential schemas keep
+// their ID, since changing it would invalidate their signature.
+func (s Service) importReferencedSchemas(ctx context.Context, request ImportItemRequest) (map[string]string, []ImportResult) {
+	ids := make(map[string]string, len(request.Bundle.Schemas))
+	for _, storedSchema := range request.Bundle.Schemas {
+		ids[storedSchema.ID] = storedSchema.ID
+		if !request.PreserveIDs && storedSchema.Schema != nil {
+			ids[storedSchema.ID] = uuid.NewString()
+		}
+	}
+
+	results := make([]ImportResult, 0, len(request.Bundle.Schemas)+1)
+	for _, storedSchema := range request.Bundle.Schemas {
+		originalID := storedSchema.ID
+		storedSchema.ID = ids[originalID]
+		var rewriteErr error
+		if storedSchema.Schema != nil {
+			var rewritten schemalib.JSONSchema
+			rewritten, rewriteErr = rewriteReferences(*storedSchema.Schema, ids)
+			storedSchema.Schema = &rewritten
+		}
+		_, getErr := s.schemaStorage.GetSchema(ctx, storedSchema.ID)
+		result := s.importItem(SchemaItem, storedSchema.ID, getErr == nil, nil, request.Overwrite, func() error {
+			if rewriteErr != nil {
+				return errors.Wrap(rewriteErr, "updating references of schema")
+			}
+			return s.schemaStorage.StoreSchema(ctx, localizeSchema(storedSchema))
+		})
+		if storedSchema.ID != originalID {
+			result.OriginalID = originalID
+		}
+		results = append(results, result)
+	}
+	return ids, results
+}
+
+// rewriteReferences replaces the references to the schemas of a bundle held by a value with their IDs in this
+// service, returning a copy of the value. References by ID are replaced with the new ID, and references by URI, which
+// may point to the service the bundle was exported from, with the URI of the schema in this service.
+func rewriteReferences[T any](value T, ids map[string]string) (T, error) {
+	var rewritten T
+	generic, err := toGeneric(value)
+	if err != nil {
+		return rewritten, err
+	}
+	schemaPath := config.GetServicePath(framework.Schema)
+	replaced := walkStrings(generic, func(s string) string {
+		if id, ok := ids[s]; ok {
+			return id
+		}
+		if !strings.Contains(s, "://") {
+			return s
+		}
+		if id, ok := ids[s[strings.LastIndex(s, "/")+1:]]; ok {
+			return strings.Join([]string{schemaPath, id}, "/")
+		}
+		return s
+	})
+	replacedBytes, err := json.Marshal(replaced)
+	if err != nil {
+		return rewritten, errors.Wrap(err, "marshalling rewritten value")
+	}
+	err = json.Unmarshal(replacedBytes, &rewritten)
+	return rewritten, err
+}
+
+// missingDIDs returns the DIDs held by a value which the service does not store, ordered and without duplicates. DID
+// URLs, such as verification method IDs, are reported by their DID.
+func (s Service) missingDIDs(ctx context.Context, value any) ([]string, error) {
+	generic, err := toGeneric(value)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "collecting referenced DIDs")
+	}
+	referenced := make(map[string]bool)
+	walkStrings(generic, func(s string) string {
+		if strings.HasPrefix(s, "did:") {
+			id, _, _ := strings.Cut(s, "#")
+			id, _, _ = strings.Cut(id, "?")
+			referenced[id] = true
+		}
+		return s
+	})
+
+	missing := make([]string, 0)
+	for id := range referenced {
+		exists, err := s.didStorage.DIDExists(ctx, id)
+		if err != nil {
+			// DIDs of methods the service does not support can't be stored by it
+			logrus.WithError(err).Warnf("checking whether DID<%s> exists", id)
+		}
+		if !exists {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// toGeneric converts a value to its generic JSON representation.
+func toGeneric(value any) (any, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling value")
+	}
+	var generic any
+	if err = json.Unmarshal(valueBytes, &generic); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling value")
+	}
+	return generic, nil
+}
+
+// walkStrings calls replace on every string value of a generic JSON value, and returns the value with each string
+// replaced. The keys of objects are left untouched.
+func walkStrings(value any, replace func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return replace(v)
+	case []any:
+		for i, element := range v {
+			v[i] = walkStrings(element, replace)
+		}
+		return v
+	case map[string]any:
+		for key, element := range v {
+			v[key] = walkStrings(element, replace)
+		}
+		return v
+	default:
+		return value
+	}
+}
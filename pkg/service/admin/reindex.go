@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/reindex"
+	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
+)
+
+// ReindexCredentials starts rebuilding the indexes of the stored credentials, returning the operation which reports the
+// entries added and removed once it is done. The service keeps serving traffic while the indexes are rebuilt.
+func (s Service) ReindexCredentials(ctx context.Context) (*operation.Operation, error) {
+	storedOp := opstorage.StoredOperation{ID: reindex.IDFromReindexID(uuid.NewString())}
+	if err := s.opsStorage.StoreOperation(ctx, storedOp); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "storing reindex operation")
+	}
+
+	// the reindex outlives the request starting it
+	go s.reindexCredentials(context.WithoutCancel(ctx), storedOp)
+
+	return operation.ServiceModel(storedOp)
+}
+
+// reindexCredentials rebuilds the indexes of the stored credentials, and marks the operation as done with the result.
+func (s Service) reindexCredentials(ctx context.Context, storedOp opstorage.StoredOperation) {
+	logrus.Infof("reindexing credentials in operation<%s>", storedOp.ID)
+
+	storedOp.Done = true
+	result, err := s.credentialStorage.Reindex(ctx, credential.ReindexPageSize)
+	if err != nil {
+		logrus.WithError(err).Errorf("reindexing credentials in operation<%s>", storedOp.ID)
+		storedOp.Error = err.Error()
+	} else if storedOp.Response, err = json.Marshal(result); err != nil {
+		storedOp.Error = err.Error()
+	}
+	if err = s.opsStorage.StoreOperation(ctx, storedOp); err != nil {
+		logrus.WithError(err).Errorf("storing result of reindex operation<%s>", storedOp.ID)
+	}
+}
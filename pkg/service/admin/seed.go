@@ -0,0 +1,396 @@
+package admin
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	manifestsdk "github.com/TBD54566975/ssi-sdk/credential/manifest"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/manifest"
+	manifestmodel "github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
+	presmodel "github.com/tbd54566975/ssi-service/pkg/service/presentation/model"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	// demoNamespace holds the IDs of the seeded demo data, so that it can be deleted.
+	demoNamespace = "demo"
+	demoDataKey   = "data"
+
+	// DemoTag tags the demo data, so that it is told apart from other data. It prefixes the names and IDs of the demo
+	// resources, is the principal recorded as creating the DIDs, credentials, and webhooks, and is set as the tag query
+	// parameter of the webhook URL.
+	DemoTag = "demo"
+	// demoTagParam is the query parameter of the webhook URL set to DemoTag.
+	demoTagParam = "tag"
+)
+
+var (
+	ErrDemoDataSeeded   = errors.New("demo data is already seeded")
+	ErrDemoNotSupported = errors.New("demo data cannot be seeded without the services creating it")
+)
+
+// DemoServices are the services creating and deleting the demo data. Seeding only uses their public APIs, so that it
+// exercises the same paths as integrators do.
+type DemoServices struct {
+	DID          *did.Service
+	Schema       *schema.Service
+	Credential   *credential.Service
+	Presentation *presentation.Service
+	Manifest     *manifest.Service
+	Webhook      *webhook.Service
+}
+
+// SetDemoServices sets the services demo data is seeded with. Seeding is not supported until they are set.
+func (s *Service) SetDemoServices(services DemoServices) {
+	s.demo = &services
+}
+
+// DemoData holds the IDs of the seeded demo resources.
+type DemoData struct {
+	IssuerDID                string        `json:"issuerDid"`
+	VerificationMethodID     string        `json:"verificationMethodId"`
+	SchemaID                 string        `json:"schemaId,omitempty"`
+	PresentationDefinitionID string        `json:"presentationDefinitionId,omitempty"`
+	ManifestID               string        `json:"manifestId,omitempty"`
+	CredentialID             string        `json:"credentialId,omitempty"`
+	CredentialJWT            keyaccess.JWT `json:"credentialJwt,omitempty"`
+	// WebhookURL is the URL registered for credential creation webhooks.
+	WebhookURL string `json:"webhookUrl,omitempty"`
+}
+
+type SeedDemoDataRequest struct {
+	// WebhookURL is the logging sink the demo webhook posts created credentials to.
+	WebhookURL string `json:"webhookUrl" validate:"required"`
+}
+
+// SeedDemoData creates a demo issuer DID, an email schema, a presentation definition requesting an email, a manifest
+// issuing credentials of the schema, a webhook posting created credentials to the given sink, and a revocable email
+// credential of the issuer, which is checked to verify. Each of them is tagged with DemoTag. The demo data is reserved
+// before any of them is created, so that concurrent seeds create it once. When any of them, or storing their IDs,
+// fails, those created before it are deleted along with the reservation. It returns ErrDemoDataSeeded when the demo
+// data is already seeded, or being seeded.
+func (s Service) SeedDemoData(ctx context.Context, request SeedDemoDataRequest) (*DemoData, error) {
+	if s.demo == nil {
+		return nil, ErrDemoNotSupported
+	}
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid seed demo data request")
+	}
+	if err := s.reserveDemoData(ctx); err != nil {
+		return nil, err
+	}
+
+	data := new(DemoData)
+	if err := s.seedDemoData(ctx, request, data); err != nil {
+		s.releaseDemoData(ctx, *data)
+		return nil, sdkutil.LoggingErrorMsg(err, "seeding demo data")
+	}
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		s.releaseDemoData(ctx, *data)
+		return nil, errors.Wrap(err, "marshalling demo data")
+	}
+	if err = s.storage.Write(ctx, demoNamespace, demoDataKey, dataBytes); err != nil {
+		s.releaseDemoData(ctx, *data)
+		return nil, sdkutil.LoggingErrorMsg(err, "storing demo data")
+	}
+	logrus.Infof("seeded demo data of issuer<%s>", data.IssuerDID)
+	return data, nil
+}
+
+// reserveDemoData stores demo data without any resources, which is replaced once they are created. The demo data is
+// checked not to be seeded in the same transaction, which fails when a concurrent seed reserves it first.
+func (s Service) reserveDemoData(ctx context.Context) error {
+	reservationBytes, err := json.Marshal(DemoData{})
+	if err != nil {
+		return errors.Wrap(err, "marshalling demo data reservation")
+	}
+	watchKeys := []storage.WatchKey{{Namespace: demoNamespace, Key: demoDataKey}}
+	_, err = s.storage.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		dataBytes, err := storage.ReadTx(ctx, s.storage, tx, demoNamespace, demoDataKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading demo data")
+		}
+		seeded, err := parseDemoData(dataBytes)
+		if err != nil {
+			return nil, err
+		}
+		if seeded != nil {
+			return nil, ErrDemoDataSeeded
+		}
+		return nil, tx.Write(ctx, demoNamespace, demoDataKey, reservationBytes)
+	}, watchKeys)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrDemoDataSeeded):
+		return ErrDemoDataSeeded
+	case errors.Is(err, storage.ErrConflict):
+		return errors.Wrap(ErrDemoDataSeeded, "reserved by a concurrent seed")
+	}
+	return sdkutil.LoggingErrorMsg(err, "reserving demo data")
+}
+
+// releaseDemoData deletes the resources of data which were created, and the reservation of the demo data, logging
+// what fails to be deleted.
+func (s Service) releaseDemoData(ctx context.Context, data DemoData) {
+	if err := s.deleteDemoData(ctx, data); err != nil {
+		logrus.WithError(err).Error("deleting partially seeded demo data")
+	}
+	if err := s.storage.Delete(ctx, demoNamespace, demoDataKey); err != nil {
+		logrus.WithError(err).Error("deleting demo data reservation")
+	}
+}
+
+// seedDemoData creates the demo resources, setting the ID of each in data as soon as it is created.
+func (s Service) seedDemoData(ctx context.Context, request SeedDemoDataRequest, data *DemoData) error {
+	issuer, err := s.demo.DID.CreateDIDByMethod(ctx, did.CreateDIDRequest{
+		Method:      didsdk.KeyMethod,
+		KeyType:     crypto.Ed25519,
+		DisplayName: "Demo Issuer",
+		CreatedBy:   DemoTag,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating issuer DID")
+	}
+	data.IssuerDID = issuer.DID.ID
+	data.VerificationMethodID = issuer.DID.VerificationMethod[0].ID
+
+	createdSchema, err := s.demo.Schema.CreateSchema(ctx, schema.CreateSchemaRequest{
+		Name:                               "Demo Email",
+		Description:                        "An email address controlled by the subject",
+		Schema:                             demoEmailSchema(),
+		Issuer:                             data.IssuerDID,
+		FullyQualifiedVerificationMethodID: data.VerificationMethodID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating email schema")
+	}
+	data.SchemaID = createdSchema.ID
+
+	definition, err := s.demo.Presentation.CreatePresentationDefinition(ctx, presmodel.CreatePresentationDefinitionRequest{
+		PresentationDefinition: exchange.PresentationDefinition{
+			ID:      DemoTag + "-" + uuid.NewString(),
+			Name:    "Demo Email",
+			Purpose: "Prove control of an email address",
+			InputDescriptors: []exchange.InputDescriptor{{
+				ID: "email",
+				Constraints: &exchange.Constraints{
+					Fields: []exchange.Field{{
+						Path:   []string{"$.vc.credentialSubject.emailAddress", "$.credentialSubject.emailAddress"},
+						Filter: &exchange.Filter{Type: "string"},
+					}},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating presentation definition")
+	}
+	data.PresentationDefinitionID = definition.PresentationDefinition.ID
+
+	manifestName := "Demo Email"
+	createdManifest, err := s.demo.Manifest.CreateManifest(ctx, manifestmodel.CreateManifestRequest{
+		Name:                               &manifestName,
+		IssuerDID:                          data.IssuerDID,
+		FullyQualifiedVerificationMethodID: data.VerificationMethodID,
+		OutputDescriptors: []manifestsdk.OutputDescriptor{{
+			ID:     DemoTag + "-email",
+			Schema: data.SchemaID,
+			Name:   "Demo Email",
+		}},
+		ClaimFormat: &exchange.ClaimFormat{
+			JWTVC: &exchange.JWTType{Alg: []crypto.SignatureAlgorithm{crypto.EdDSA}},
+		},
+		PresentationDefinitionRef: &manifestmodel.PresentationDefinitionRef{ID: &data.PresentationDefinitionID},
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating manifest")
+	}
+	data.ManifestID = createdManifest.Manifest.ID
+
+	webhookURL, err := demoWebhookURL(request.WebhookURL)
+	if err != nil {
+		return err
+	}
+	if _, err = s.demo.Webhook.CreateWebhook(ctx, webhook.CreateWebhookRequest{
+		Noun:      webhook.Credential,
+		Verb:      webhook.Create,
+		URL:       webhookURL,
+		CreatedBy: DemoTag,
+	}); err != nil {
+		return errors.Wrap(err, "creating webhook")
+	}
+	data.WebhookURL = webhookURL
+
+	createdCredential, err := s.demo.Credential.CreateCredential(ctx, credential.CreateCredentialRequest{
+		Issuer:                             data.IssuerDID,
+		FullyQualifiedVerificationMethodID: data.VerificationMethodID,
+		Subject:                            data.IssuerDID,
+		SchemaID:                           data.SchemaID,
+		Data:                               map[string]any{"emailAddress": "demo@example.com"},
+		Revocable:                          true,
+		CreatedBy:                          DemoTag,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating credential")
+	}
+	data.CredentialID = createdCredential.ID
+	if createdCredential.CredentialJWT == nil {
+		return errors.New("credential was not created as a JWT")
+	}
+	data.CredentialJWT = *createdCredential.CredentialJWT
+
+	verified, err := s.demo.Credential.VerifyCredential(ctx, credential.VerifyCredentialRequest{CredentialJWT: &data.CredentialJWT})
+	if err != nil {
+		return errors.Wrap(err, "verifying credential")
+	}
+	if !verified.Verified {
+		return errors.Errorf("credential does not verify: %s", verified.Reason)
+	}
+	return nil
+}
+
+// DeleteDemoData deletes the seeded demo data through the APIs of the services which created it. Every resource is
+// attempted, and the demo data is forgotten even when some of them fail to be deleted, whose errors are returned. The
+// reservation of a seed which did not finish is deleted as well. It does nothing when no demo data is seeded.
+func (s Service) DeleteDemoData(ctx context.Context) error {
+	if s.demo == nil {
+		return ErrDemoNotSupported
+	}
+	data, err := s.getDemoData(ctx)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+	deleteErr := s.deleteDemoData(ctx, *data)
+	if err = s.storage.Delete(ctx, demoNamespace, demoDataKey); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "deleting demo data")
+	}
+	if deleteErr != nil {
+		return sdkutil.LoggingErrorMsg(deleteErr, "deleting demo resources")
+	}
+	logrus.Infof("deleted demo data of issuer<%s>", data.IssuerDID)
+	return nil
+}
+
+// deleteDemoData deletes the resources of data which were created, in the reverse order they are created in.
+func (s Service) deleteDemoData(ctx context.Context, data DemoData) error {
+	ae := sdkutil.NewAppendError()
+	if data.CredentialID != "" {
+		if err := s.demo.Credential.DeleteCredential(ctx, credential.DeleteCredentialRequest{ID: data.CredentialID}); err != nil {
+			ae.Append(errors.Wrapf(err, "deleting credential<%s>", data.CredentialID))
+		}
+	}
+	if data.WebhookURL != "" {
+		if err := s.demo.Webhook.DeleteWebhook(ctx, webhook.DeleteWebhookRequest{Noun: webhook.Credential, Verb: webhook.Create, URL: data.WebhookURL}); err != nil {
+			ae.Append(errors.Wrapf(err, "deleting webhook of url<%s>", data.WebhookURL))
+		}
+	}
+	if data.ManifestID != "" {
+		if err := s.demo.Manifest.DeleteManifest(ctx, manifestmodel.DeleteManifestRequest{ID: data.ManifestID}); err != nil {
+			ae.Append(errors.Wrapf(err, "deleting manifest<%s>", data.ManifestID))
+		}
+	}
+	if data.PresentationDefinitionID != "" {
+		if err := s.demo.Presentation.DeletePresentationDefinition(ctx, presmodel.DeletePresentationDefinitionRequest{ID: data.PresentationDefinitionID}); err != nil {
+			ae.Append(errors.Wrapf(err, "deleting presentation definition<%s>", data.PresentationDefinitionID))
+		}
+	}
+	if data.SchemaID != "" {
+		if err := s.demo.Schema.DeleteSchema(ctx, schema.DeleteSchemaRequest{ID: data.SchemaID}); err != nil {
+			ae.Append(errors.Wrapf(err, "deleting schema<%s>", data.SchemaID))
+		}
+	}
+	if data.IssuerDID != "" {
+		if err := s.demo.DID.SoftDeleteDIDByMethod(ctx, did.DeleteDIDRequest{Method: didsdk.KeyMethod, ID: data.IssuerDID}); err != nil {
+			ae.Append(errors.Wrapf(err, "deleting DID<%s>", data.IssuerDID))
+		}
+	}
+	return ae.Error()
+}
+
+// IsDemoDataSeeded returns whether demo data is seeded, or being seeded.
+func (s Service) IsDemoDataSeeded(ctx context.Context) (bool, error) {
+	data, err := s.getDemoData(ctx)
+	if err != nil {
+		return false, err
+	}
+	return data != nil, nil
+}
+
+// getDemoData returns the seeded demo data, or nil when none is seeded.
+func (s Service) getDemoData(ctx context.Context) (*DemoData, error) {
+	dataBytes, err := s.storage.Read(ctx, demoNamespace, demoDataKey)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "reading demo data")
+	}
+	return parseDemoData(dataBytes)
+}
+
+// parseDemoData returns the demo data stored as dataBytes, or nil when none is stored.
+func parseDemoData(dataBytes []byte) (*DemoData, error) {
+	if len(dataBytes) == 0 {
+		return nil, nil
+	}
+	var data DemoData
+	if err := json.Unmarshal(dataBytes, &data); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "unmarshalling demo data")
+	}
+	return &data, nil
+}
+
+// demoWebhookURL returns the URL of the webhook sink, with its tag query parameter set to DemoTag, so that it is told
+// apart from a webhook of the same sink registered by other means.
+func demoWebhookURL(sinkURL string) (string, error) {
+	parsed, err := url.Parse(sinkURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing webhook url<%s>", sinkURL)
+	}
+	query := parsed.Query()
+	query.Set(demoTagParam, DemoTag)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// demoEmailSchema is the schema of credentials holding an email address of their subject.
+func demoEmailSchema() schemalib.JSONSchema {
+	return schemalib.JSONSchema{
+		"$schema": "https://json-schema.org/draft-07/schema",
+		"type":    "object",
+		"properties": map[string]any{
+			"credentialSubject": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"id": map[string]any{
+						"type": "string",
+					},
+					"emailAddress": map[string]any{
+						"type":   "string",
+						"format": "email",
+					},
+				},
+				"required": []any{"emailAddress"},
+			},
+		},
+	}
+}
@@ -0,0 +1,361 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.einride.tech/aip/filtering"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
+	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+var ErrPassphraseRequired = errors.New("a passphrase is required to export or import keys")
+
+// Service exposes operations which span the data of other services, such as exporting and importing the objects
+// needed to configure an environment.
+type Service struct {
+	schemaStorage       *schema.Storage
+	presentationStorage prestorage.Storage
+	manifestStorage     *manifeststg.Storage
+	didStorage          *did.Storage
+	credentialStorage   *credential.Storage
+	opsStorage          *operation.Storage
+	didMethods          []string
+
+	storage            storage.ServiceStorage
+	compactionInterval time.Duration
+	softQuotaBytes     int64
+	storageStats       *storageStatsCache
+
+	// external dependencies
+	keyStore *keystore.Service
+	// credentialService is nil until it is set, checking the consistency of credential statuses and freezing issuers
+	credentialService *credential.Service
+	// demo is nil until the services seeding demo data are set
+	demo *DemoServices
+}
+
+func (s Service) Type() framework.Type {
+	return framework.Admin
+}
+
+func (s Service) Status() framework.Status {
+	ae := sdkutil.NewAppendError()
+	if s.storage == nil || s.schemaStorage == nil || s.presentationStorage == nil || s.manifestStorage == nil || s.didStorage == nil ||
+		s.credentialStorage == nil || s.opsStorage == nil {
+		ae.AppendString("no storage configured")
+	}
+	if s.keyStore == nil {
+		ae.AppendString("no key store service configured")
+	}
+	if !ae.IsEmpty() {
+		return framework.Status{
+			Status:  framework.StatusNotReady,
+			Message: fmt.Sprintf("admin service is not ready: %s", ae.Error().Error()),
+		}
+	}
+	return framework.Status{Status: framework.StatusReady}
+}
+
+func NewAdminService(config config.ServicesConfig, s storage.ServiceStorage, keyStore *keystore.Service) (*Service, error) {
+	var compactionInterval time.Duration
+	if config.StorageCompactionInterval != "" {
+		var err error
+		if compactionInterval, err = time.ParseDuration(config.StorageCompactionInterval); err != nil || compactionInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid storage compaction interval: %s", config.StorageCompactionInterval)
+		}
+	}
+	if config.StorageSoftQuotaBytes < 0 {
+		return nil, sdkutil.LoggingNewErrorf("invalid storage soft quota: %d", config.StorageSoftQuotaBytes)
+	}
+	schemaStorage, err := schema.NewSchemaStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate schema storage for the admin service")
+	}
+	presentationStorage, err := presentation.NewPresentationStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate presentation storage for the admin service")
+	}
+	manifestStorage, err := manifeststg.NewManifestStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate manifest storage for the admin service")
+	}
+	didStorage, err := did.NewDIDStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate did storage for the admin service")
+	}
+	credentialStorage, err := credential.NewCredentialStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate credential storage for the admin service")
+	}
+	opsStorage, err := operation.NewOperationStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate operation storage for the admin service")
+	}
+	service := Service{
+		schemaStorage:       schemaStorage,
+		presentationStorage: presentationStorage,
+		manifestStorage:     manifestStorage,
+		didStorage:          didStorage,
+		credentialStorage:   credentialStorage,
+		opsStorage:          opsStorage,
+		didMethods:          config.DIDConfig.Methods,
+		storage:             s,
+		compactionInterval:  compactionInterval,
+		softQuotaBytes:      config.StorageSoftQuotaBytes,
+		storageStats:        new(storageStatsCache),
+		keyStore:            keyStore,
+	}
+	if !service.Status().IsReady() {
+		return nil, errors.New(service.Status().Message)
+	}
+	if err = registerStorageStatsGauges(&service); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not register storage stats metrics")
+	}
+	return &service, nil
+}
+
+// ExportBundle collects the schemas, presentation definitions, and manifests stored by the service into a bundle.
+// When requested, DIDs are included along with the keys they control, which are encrypted with the passphrase.
+func (s Service) ExportBundle(ctx context.Context, request ExportBundleRequest) (*ExportBundleResponse, error) {
+	logrus.Debugf("exporting bundle, including DIDs: %t", request.IncludeDIDs)
+
+	if err := request.IsValid(); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid export bundle request")
+	}
+
+	bundle := Bundle{Version: BundleVersion}
+	storedSchemas, err := s.schemaStorage.ListSchemas(ctx, filtering.Filter{}, common.Page{Size: -1})
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing schemas")
+	}
+	bundle.Schemas = storedSchemas.Schemas
+	if bundle.PresentationDefinitions, err = s.presentationStorage.ListDefinitions(ctx); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing presentation definitions")
+	}
+	if bundle.Manifests, err = s.manifestStorage.ListManifests(ctx); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing manifests")
+	}
+
+	if !request.IncludeDIDs {
+		return &ExportBundleResponse{Bundle: bundle}, nil
+	}
+
+	controllers := make([]string, 0)
+	for _, method := range s.didMethods {
+		storedDIDs, err := s.didStorage.ListDIDs(ctx, method, new(BundledDID))
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "listing DIDs for method: %s", method)
+		}
+		for _, storedDID := range storedDIDs {
+			bundledDID := storedDID.(*BundledDID)
+			bundle.DIDs = append(bundle.DIDs, *bundledDID)
+			controllers = append(controllers, bundledDID.ID)
+		}
+	}
+	keys, err := s.keyStore.ListKeysByController(ctx, controllers)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing keys for DIDs")
+	}
+	if bundle.EncryptedKeys, err = encryptKeys(keys, request.Passphrase); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "encrypting keys")
+	}
+	return &ExportBundleResponse{Bundle: bundle}, nil
+}
+
+// ImportBundle recreates every item of the bundle with its original ID. Items whose ID already exists are skipped,
+// unless overwriting is requested. The outcome of each item is reported individually, so a failure on one item does
+// not prevent the others from being imported.
+func (s Service) ImportBundle(ctx context.Context, request ImportBundleRequest) (*ImportBundleResponse, error) {
+	logrus.Debugf("importing bundle, overwriting: %t", request.Overwrite)
+
+	bundle := request.Bundle
+	if bundle.Version != BundleVersion {
+		return nil, sdkutil.LoggingNewErrorf("unsupported bundle version: %s", bundle.Version)
+	}
+	var keys []keystore.StoredKey
+	if bundle.EncryptedKeys != nil {
+		if request.Passphrase == "" {
+			return nil, sdkutil.LoggingError(ErrPassphraseRequired)
+		}
+		var err error
+		if keys, err = decryptKeys(*bundle.EncryptedKeys, request.Passphrase); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "decrypting keys")
+		}
+	}
+
+	// DIDs and their keys are imported first, since the other items may reference them
+	results := make([]ImportResult, 0)
+	for _, bundledDID := range bundle.DIDs {
+		exists, err := s.didStorage.DIDExists(ctx, bundledDID.ID)
+		results = append(results, s.importItem(DIDItem, bundledDID.ID, exists, err, request.Overwrite, func() error {
+			return s.didStorage.StoreDID(ctx, bundledDID)
+		}))
+	}
+	for _, key := range keys {
+		exists, err := s.keyStore.KeyExists(ctx, key.ID)
+		results = append(results, s.importItem(KeyItem, key.ID, exists, err, request.Overwrite, func() error {
+			return s.keyStore.ImportKey(ctx, key)
+		}))
+	}
+	for _, storedSchema := range bundle.Schemas {
+		_, getErr := s.schemaStorage.GetSchema(ctx, storedSchema.ID)
+		results = append(results, s.importItem(SchemaItem, storedSchema.ID, getErr == nil, nil, request.Overwrite, func() error {
+			return s.schemaStorage.StoreSchema(ctx, localizeSchema(storedSchema))
+		}))
+	}
+	for _, definition := range bundle.PresentationDefinitions {
+		_, getErr := s.presentationStorage.GetDefinition(ctx, definition.ID)
+		results = append(results, s.importItem(PresentationDefinitionItem, definition.ID, getErr == nil, nil, request.Overwrite, func() error {
+			return s.presentationStorage.StoreDefinition(ctx, definition)
+		}))
+	}
+	for _, storedManifest := range bundle.Manifests {
+		_, getErr := s.manifestStorage.GetManifest(ctx, storedManifest.ID)
+		results = append(results, s.importItem(ManifestItem, storedManifest.ID, getErr == nil, nil, request.Overwrite, func() error {
+			return s.manifestStorage.StoreManifest(ctx, storedManifest)
+		}))
+	}
+	return &ImportBundleResponse{Results: results}, nil
+}
+
+// importItem stores a single item of a bundle, honoring the overwrite setting for items which already exist
+func (s Service) importItem(itemType ItemType, id string, exists bool, existsErr error, overwrite bool, store func() error) ImportResult {
+	result := ImportResult{Type: itemType, ID: id}
+	switch {
+	case existsErr != nil:
+		result.Status = ImportStatusFailed
+		result.Reason = errors.Wrap(existsErr, "checking for existing item").Error()
+		return result
+	case exists && !overwrite:
+		result.Status = ImportStatusSkipped
+		result.Reason = "an item with this id already exists"
+		return result
+	}
+	if err := store(); err != nil {
+		logrus.WithError(err).Errorf("importing %s<%s>", itemType, id)
+		result.Status = ImportStatusFailed
+		result.Reason = err.Error()
+		return result
+	}
+	result.Status = ImportStatusCreated
+	if exists {
+		result.Status = ImportStatusOverwritten
+	}
+	return result
+}
+
+// localizeSchema points the ID of an imported JSON schema to this service, so that it resolves to the same schema.
+// Credential schemas are left untouched, since changing their ID would invalidate their signature.
+func localizeSchema(storedSchema schema.StoredSchema) schema.StoredSchema {
+	if storedSchema.Schema == nil {
+		return storedSchema
+	}
+	localized := make(schemalib.JSONSchema, len(*storedSchema.Schema))
+	for k, v := range *storedSchema.Schema {
+		localized[k] = v
+	}
+	localized[schemalib.JSONSchemaIDProperty] = strings.Join([]string{config.GetServicePath(framework.Schema), storedSchema.ID}, "/")
+	storedSchema.Schema = &localized
+	return storedSchema
+}
+
+func encryptKeys(keys []keystore.StoredKey, passphrase string) (*EncryptedKeys, error) {
+	keysBytes, err := json.Marshal(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling keys")
+	}
+	salt, err := util.GenerateSalt(util.Argon2SaltSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating salt")
+	}
+	encryptionKey, err := util.Argon2KeyGen(passphrase, salt, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving encryption key")
+	}
+	ciphertext, err := util.XChaCha20Poly1305Encrypt(encryptionKey, keysBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting keys")
+	}
+	return &EncryptedKeys{Salt: salt, Ciphertext: ciphertext}, nil
+}
+
+func decryptKeys(encrypted EncryptedKeys, passphrase string) ([]keystore.StoredKey, error) {
+	encryptionKey, err := util.Argon2KeyGen(passphrase, encrypted.Salt, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving encryption key")
+	}
+	keysBytes, err := util.XChaCha20Poly1305Decrypt(encryptionKey, encrypted.Ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting keys; the passphrase may be incorrect")
+	}
+	var keys []keystore.StoredKey
+	if err = json.Unmarshal(keysBytes, &keys); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling keys")
+	}
+	return keys, nil
+}
+
+// CompactStorage reclaims the space of deleted data. It returns storage.ErrNotSupported when the storage provider does
+// not need compaction.
+func (s Service) CompactStorage(ctx context.Context) (*storage.CompactionResult, error) {
+	result, err := storage.Compact(ctx, s.storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "compacting storage")
+	}
+	s.invalidateStorageStats()
+	return result, nil
+}
+
+// CompactionInterval returns how often the storage is compacted, or 0 when scheduled compaction is disabled.
+func (s Service) CompactionInterval() time.Duration {
+	return s.compactionInterval
+}
+
+// RunStorageCompaction compacts the storage every compaction interval, until the context is done, warning when the
+// compacted storage is still over the soft quota. It returns immediately when scheduled compaction is disabled, or when
+// the storage provider does not need compaction.
+func (s Service) RunStorageCompaction(ctx context.Context) {
+	if s.compactionInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.compactionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.CompactStorage(ctx)
+			if err != nil {
+				if errors.Is(err, storage.ErrNotSupported) {
+					logrus.Infof("storage compaction is not needed by the storage provider, stopping scheduled compaction")
+					return
+				}
+				logrus.WithError(err).Error("compacting storage")
+				continue
+			}
+			s.checkSoftQuota(result.SizeAfterBytes)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// storageStatsTTL is how long storage stats are reused, since computing them reads every key of the storage.
+const storageStatsTTL = 30 * time.Second
+
+// storageStatsCache holds the last storage stats computed, until their TTL expires.
+type storageStatsCache struct {
+	mu        sync.Mutex
+	stats     *storage.Stats
+	expiresAt time.Time
+}
+
+// GetStorageStats reports the space used by the storage, and the number of keys stored in each namespace along with
+// approximately how many bytes they use, warning when the storage is over the soft quota. Stats are cached for
+// storageStatsTTL. It returns storage.ErrNotSupported when the storage provider cannot report them.
+func (s Service) GetStorageStats(ctx context.Context) (*storage.Stats, error) {
+	s.storageStats.mu.Lock()
+	defer s.storageStats.mu.Unlock()
+	if s.storageStats.stats != nil && time.Now().Before(s.storageStats.expiresAt) {
+		return s.storageStats.stats, nil
+	}
+	stats, err := storage.GetStats(ctx, s.storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting storage stats")
+	}
+	s.checkSoftQuota(stats.SizeBytes)
+	s.storageStats.stats = stats
+	s.storageStats.expiresAt = time.Now().Add(storageStatsTTL)
+	return stats, nil
+}
+
+// invalidateStorageStats drops the cached storage stats, such as once compaction changed the size of the storage.
+func (s Service) invalidateStorageStats() {
+	s.storageStats.mu.Lock()
+	defer s.storageStats.mu.Unlock()
+	s.storageStats.stats = nil
+}
+
+// SoftQuotaBytes returns the soft quota on the size of the storage, or 0 when there is none.
+func (s Service) SoftQuotaBytes() int64 {
+	return s.softQuotaBytes
+}
+
+// IsOverSoftQuota returns whether a storage of the given size is over the soft quota.
+func (s Service) IsOverSoftQuota(sizeBytes int64) bool {
+	return s.softQuotaBytes > 0 && sizeBytes > s.softQuotaBytes
+}
+
+// checkSoftQuota logs a warning when a storage of the given size is over the soft quota.
+func (s Service) checkSoftQuota(sizeBytes int64) {
+	if s.IsOverSoftQuota(sizeBytes) {
+		logrus.Warnf("storage size of %d bytes is over its soft quota of %d bytes", sizeBytes, s.softQuotaBytes)
+	}
+}
+
+// registerStorageStatsGauges reports the size of the storage, along with whether it is over the soft quota, and the key
+// count and size of each namespace as gauges, whenever metrics are collected. Nothing is reported when the storage
+// provider cannot report its stats, and no size when it does not store a file.
+func registerStorageStatsGauges(s *Service) error {
+	meter := otel.Meter(config.ServiceName)
+	size, err := meter.Int64ObservableGauge(
+		"ssi_service.storage.size",
+		metric.WithDescription("Size of the storage, labeled with whether it is over the soft quota"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating storage size metric")
+	}
+	keyCount, err := meter.Int64ObservableGauge(
+		"ssi_service.storage.namespace_keys",
+		metric.WithDescription("Number of keys stored in each namespace"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating namespace key count metric")
+	}
+	namespaceSize, err := meter.Int64ObservableGauge(
+		"ssi_service.storage.namespace_size",
+		metric.WithDescription("Approximate size of the keys and values stored in each namespace"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "creating namespace size metric")
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		stats, err := s.GetStorageStats(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotSupported) {
+				return nil
+			}
+			return err
+		}
+		if stats.SizeBytes > 0 {
+			observer.ObserveInt64(size, stats.SizeBytes, metric.WithAttributes(attribute.Bool("over_soft_quota", s.IsOverSoftQuota(stats.SizeBytes))))
+		}
+		for namespace, namespaceStats := range stats.Namespaces {
+			attributes := metric.WithAttributes(attribute.String("namespace", namespace))
+			observer.ObserveInt64(keyCount, int64(namespaceStats.KeyCount), attributes)
+			observer.ObserveInt64(namespaceSize, namespaceStats.SizeBytes, attributes)
+		}
+		return nil
+	}, size, keyCount, namespaceSize)
+	if err != nil {
+		return errors.Wrap(err, "registering storage stats callback")
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package analytics
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"sync"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/benbjohnson/clock"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// EventType is what an event is about.
+type EventType string
+
+const (
+	CredentialIssuance       EventType = "credential_issuance"
+	CredentialVerification   EventType = "credential_verification"
+	PresentationVerification EventType = "presentation_verification"
+)
+
+const (
+	// Succeeded is the outcome of a credential issued.
+	Succeeded = "SUCCEEDED"
+	// Failed is the outcome of a credential which could not be issued, or of a verification which could not complete.
+	Failed = "FAILED"
+	// Verified is the outcome of a verification which succeeded. Verifications which did not succeed have the reason
+	// code of the failure as their outcome, such as "REVOKED".
+	Verified = "VERIFIED"
+)
+
+// latencyBuckets are the upper bounds of the latency buckets, in increasing order.
+var latencyBuckets = []struct {
+	bound time.Duration
+	name  string
+}{
+	{10 * time.Millisecond, "10ms"},
+	{50 * time.Millisecond, "50ms"},
+	{100 * time.Millisecond, "100ms"},
+	{500 * time.Millisecond, "500ms"},
+	{time.Second, "1s"},
+	{5 * time.Second, "5s"},
+}
+
+// Event is an anonymized event about the issuance or verification of credentials, for analytics. It never holds the
+// claims or the subject of credentials, and the DID of the issuer is only held as a salted hash.
+type Event struct {
+	Type EventType `json:"type"`
+	// IssuerHash is the hex encoded HMAC-SHA256 of the DID of the issuer, keyed with the configured salt. Empty when
+	// the issuer is not known, such as for presentations.
+	IssuerHash string `json:"issuerHash,omitempty"`
+	SchemaID   string `json:"schemaId,omitempty"`
+	Outcome    string `json:"outcome"`
+	// LatencyBucket is the upper bound of the latency of the operation, such as "100ms", or "inf" above 5s.
+	LatencyBucket string `json:"latencyBucket"`
+	Timestamp     string `json:"timestamp"`
+}
+
+// Sink receives the events sampled by an emitter.
+type Sink interface {
+	Write(ctx context.Context, event Event) error
+}
+
+// Sampler decides which events are emitted.
+type Sampler interface {
+	Sample() bool
+}
+
+// rateSampler samples events at a rate, drawing from a seeded source so that its decisions can be reproduced.
+type rateSampler struct {
+	mu   sync.Mutex
+	rate float64
+	rand *rand.Rand
+}
+
+// NewSampler returns a sampler keeping the given fraction of events, between 0 and 1. Samplers with the same seed
+// make the same decisions.
+func NewSampler(rate float64, seed int64) (Sampler, error) {
+	if rate < 0 || rate > 1 {
+		return nil, sdkutil.LoggingNewErrorf("invalid analytics sample rate<%v>, which must be between 0 and 1", rate)
+	}
+	return &rateSampler{rate: rate, rand: rand.New(rand.NewSource(seed))}, nil
+}
+
+func (s *rateSampler) Sample() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < s.rate
+}
+
+// Emitter samples events and writes them to its sink. A nil emitter emits nothing.
+type Emitter struct {
+	sink    Sink
+	salt    []byte
+	sampler Sampler
+	Clock   clock.Clock
+}
+
+// NewEmitter returns an emitter writing the events kept by the sampler to the sink, hashing issuers with the salt.
+func NewEmitter(sink Sink, salt string, sampler Sampler) (*Emitter, error) {
+	if salt == "" {
+		return nil, sdkutil.LoggingNewError("analytics require a salt to hash issuers with")
+	}
+	return &Emitter{sink: sink, salt: []byte(salt), sampler: sampler, Clock: clock.New()}, nil
+}
+
+// NewConfiguredEmitter returns the emitter of the configured sink, or nil when analytics are disabled.
+func NewConfiguredEmitter(c config.AnalyticsConfig, db storage.ServiceStorage) (*Emitter, error) {
+	if c.Sink == "" {
+		return nil, nil
+	}
+	sink, err := NewSink(c, db)
+	if err != nil {
+		return nil, err
+	}
+	sampler, err := NewSampler(c.SampleRate, time.Now().UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	return NewEmitter(sink, c.Salt, sampler)
+}
+
+// Emit writes an event about an operation of the given type when it is sampled. The issuer is hashed, and the event
+// is written in its own goroutine, detached from the request, so that it does not add to the latency of the request.
+// It must be called once the changes of the operation, if any, are committed.
+func (e *Emitter) Emit(eventType EventType, issuer, schemaID, outcome string, latency time.Duration) {
+	if e == nil || !e.sampler.Sample() {
+		return
+	}
+	event := Event{
+		Type:          eventType,
+		SchemaID:      schemaID,
+		Outcome:       outcome,
+		LatencyBucket: latencyBucket(latency),
+		Timestamp:     e.Clock.Now().UTC().Format(time.RFC3339),
+	}
+	if issuer != "" {
+		event.IssuerHash = e.Hash(issuer)
+	}
+	go func() {
+		if err := e.sink.Write(context.Background(), event); err != nil {
+			logrus.WithError(err).Warnf("writing %s analytics event", eventType)
+		}
+	}()
+}
+
+// Hash returns the hex encoded HMAC-SHA256 of the value, keyed with the salt of the emitter.
+func (e *Emitter) Hash(value string) string {
+	mac := hmac.New(sha256.New, e.salt)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IssuanceOutcome returns the outcome of issuing a credential, which failed with the error when it is not nil.
+func IssuanceOutcome(err error) string {
+	if err != nil {
+		return Failed
+	}
+	return Succeeded
+}
+
+// VerificationOutcome returns the outcome of a verification, which could not complete when the error is not nil. The
+// reason code of a verification which did not succeed defaults to invalidReasonCode.
+func VerificationOutcome(verified bool, reasonCode, invalidReasonCode string, err error) string {
+	switch {
+	case err != nil:
+		return Failed
+	case verified:
+		return Verified
+	case reasonCode == "":
+		return invalidReasonCode
+	default:
+		return reasonCode
+	}
+}
+
+func latencyBucket(latency time.Duration) string {
+	for _, bucket := range latencyBuckets {
+		if latency <= bucket.bound {
+			return bucket.name
+		}
+	}
+	return "inf"
+}
@@ -0,0 +1,175 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestSampler(t *testing.T) {
+	t.Run("samplers with the same seed make the same decisions", func(tt *testing.T) {
+		first, err := NewSampler(0.25, 42)
+		require.NoError(tt, err)
+		second, err := NewSampler(0.25, 42)
+		require.NoError(tt, err)
+
+		sampled := 0
+		for i := 0; i < 1000; i++ {
+			decision := first.Sample()
+			assert.Equal(tt, decision, second.Sample())
+			if decision {
+				sampled++
+			}
+		}
+		assert.InDelta(tt, 250, sampled, 50)
+	})
+
+	t.Run("rates of 0 and 1 keep none and all events", func(tt *testing.T) {
+		none, err := NewSampler(0, 1)
+		require.NoError(tt, err)
+		all, err := NewSampler(1, 1)
+		require.NoError(tt, err)
+		for i := 0; i < 100; i++ {
+			assert.False(tt, none.Sample())
+			assert.True(tt, all.Sample())
+		}
+	})
+
+	t.Run("rates outside of 0 and 1 are rejected", func(tt *testing.T) {
+		_, err := NewSampler(-0.1, 1)
+		assert.ErrorContains(tt, err, "invalid analytics sample rate")
+		_, err = NewSampler(1.5, 1)
+		assert.ErrorContains(tt, err, "invalid analytics sample rate")
+	})
+}
+
+func TestEmitter(t *testing.T) {
+	newEmitter := func(tt *testing.T, salt string, rate float64) (*Emitter, *recordingSink) {
+		sampler, err := NewSampler(rate, 7)
+		require.NoError(tt, err)
+		sink := new(recordingSink)
+		emitter, err := NewEmitter(sink, salt, sampler)
+		require.NoError(tt, err)
+		return emitter, sink
+	}
+
+	t.Run("emits events with the issuer hashed with the salt", func(tt *testing.T) {
+		emitter, sink := newEmitter(tt, "salt", 1)
+		emitter.Emit(CredentialIssuance, "did:key:issuer", "schema-id", Succeeded, 30*time.Millisecond)
+
+		events := sink.wait(tt, 1)
+		assert.Equal(tt, CredentialIssuance, events[0].Type)
+		assert.Equal(tt, "schema-id", events[0].SchemaID)
+		assert.Equal(tt, Succeeded, events[0].Outcome)
+		assert.Equal(tt, "50ms", events[0].LatencyBucket)
+		assert.NotEmpty(tt, events[0].Timestamp)
+		assert.Equal(tt, emitter.Hash("did:key:issuer"), events[0].IssuerHash)
+		assert.NotContains(tt, events[0].IssuerHash, "did:key:issuer")
+
+		// the hash depends on the salt, so that it can't be recovered by hashing known DIDs
+		otherEmitter, _ := newEmitter(tt, "other salt", 1)
+		assert.NotEqual(tt, emitter.Hash("did:key:issuer"), otherEmitter.Hash("did:key:issuer"))
+	})
+
+	t.Run("emits the events kept by the sampler", func(tt *testing.T) {
+		emitter, sink := newEmitter(tt, "salt", 0.5)
+		sampler, err := NewSampler(0.5, 7)
+		require.NoError(tt, err)
+		expected := 0
+		for i := 0; i < 200; i++ {
+			if sampler.Sample() {
+				expected++
+			}
+			emitter.Emit(CredentialVerification, "did:key:issuer", "", Verified, time.Millisecond)
+		}
+		assert.Len(tt, sink.wait(tt, expected), expected)
+		assert.Less(tt, expected, 200)
+	})
+
+	t.Run("a nil emitter emits nothing", func(tt *testing.T) {
+		var emitter *Emitter
+		emitter.Emit(CredentialIssuance, "did:key:issuer", "", Succeeded, time.Millisecond)
+	})
+
+	t.Run("requires a salt", func(tt *testing.T) {
+		sampler, err := NewSampler(1, 1)
+		require.NoError(tt, err)
+		_, err = NewEmitter(new(recordingSink), "", sampler)
+		assert.ErrorContains(tt, err, "salt")
+	})
+}
+
+func TestLatencyBucket(t *testing.T) {
+	assert.Equal(t, "10ms", latencyBucket(10*time.Millisecond))
+	assert.Equal(t, "100ms", latencyBucket(99*time.Millisecond))
+	assert.Equal(t, "5s", latencyBucket(3*time.Second))
+	assert.Equal(t, "inf", latencyBucket(time.Minute))
+}
+
+func TestOutcomes(t *testing.T) {
+	assert.Equal(t, Succeeded, IssuanceOutcome(nil))
+	assert.Equal(t, Failed, IssuanceOutcome(assert.AnError))
+	assert.Equal(t, Verified, VerificationOutcome(true, "", "INVALID", nil))
+	assert.Equal(t, "REVOKED", VerificationOutcome(false, "REVOKED", "INVALID", nil))
+	assert.Equal(t, "INVALID", VerificationOutcome(false, "", "INVALID", nil))
+	assert.Equal(t, Failed, VerificationOutcome(false, "", "INVALID", assert.AnError))
+}
+
+func TestStorageSink(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(tt *testing.T) {
+			db := test.ServiceStorage(tt)
+			sink, err := NewSink(config.AnalyticsConfig{Sink: StorageSink}, db)
+			require.NoError(tt, err)
+
+			event := Event{Type: CredentialIssuance, SchemaID: "schema-id", Outcome: Succeeded, LatencyBucket: "10ms"}
+			require.NoError(tt, sink.Write(context.Background(), event))
+			require.NoError(tt, sink.Write(context.Background(), event))
+
+			stored, err := db.ReadAll(context.Background(), Namespace)
+			require.NoError(tt, err)
+			require.Len(tt, stored, 2)
+			for _, eventBytes := range stored {
+				var storedEvent Event
+				require.NoError(tt, json.Unmarshal(eventBytes, &storedEvent))
+				assert.Equal(tt, event, storedEvent)
+			}
+		})
+	}
+
+	_, err := NewSink(config.AnalyticsConfig{Sink: "kafka"}, nil)
+	assert.ErrorContains(t, err, "unsupported analytics sink")
+}
+
+// recordingSink records the events written to it.
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *recordingSink) Write(_ context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// wait returns the events once n of them are written, which happens in the background.
+func (s *recordingSink) wait(t *testing.T, n int) []Event {
+	var events []Event
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		events = append([]Event(nil), s.events...)
+		return len(events) >= n
+	}, time.Second, 10*time.Millisecond)
+	return events
+}
@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	LogSink     = "log"
+	StatsDSink  = "statsd"
+	StorageSink = "storage"
+
+	// Namespace is where the storage sink writes events, keyed by IDs sorting them in the order they were written.
+	Namespace = "analytics-event"
+)
+
+// NewSink returns the configured sink.
+func NewSink(c config.AnalyticsConfig, db storage.ServiceStorage) (Sink, error) {
+	switch c.Sink {
+	case LogSink:
+		return logSink{}, nil
+	case StatsDSink:
+		conn, err := net.Dial("udp", c.StatsDAddress)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not dial statsd server<%s>", c.StatsDAddress)
+		}
+		return statsDSink{conn: conn, prefix: c.StatsDPrefix}, nil
+	case StorageSink:
+		if db == nil {
+			return nil, sdkutil.LoggingNewError("no storage configured for the analytics sink")
+		}
+		return storageSink{db: db}, nil
+	default:
+		return nil, sdkutil.LoggingNewErrorf("unsupported analytics sink: %s", c.Sink)
+	}
+}
+
+// logSink logs each event as structured fields.
+type logSink struct{}
+
+func (logSink) Write(_ context.Context, event Event) error {
+	logrus.WithFields(logrus.Fields{
+		"type":          event.Type,
+		"issuerHash":    event.IssuerHash,
+		"schemaId":      event.SchemaID,
+		"outcome":       event.Outcome,
+		"latencyBucket": event.LatencyBucket,
+		"timestamp":     event.Timestamp,
+	}).Info("analytics event")
+	return nil
+}
+
+// statsDSink increments a counter per type, outcome, and latency bucket of events, such as
+// "ssi_service.analytics.credential_issuance.SUCCEEDED.10ms". Issuers and schemas are left out of the counters, which
+// would otherwise be too many.
+type statsDSink struct {
+	conn   net.Conn
+	prefix string
+}
+
+func (s statsDSink) Write(_ context.Context, event Event) error {
+	metric := fmt.Sprintf("%s.%s.%s.%s:1|c", s.prefix, event.Type, event.Outcome, event.LatencyBucket)
+	if _, err := s.conn.Write([]byte(metric)); err != nil {
+		return errors.Wrap(err, "sending statsd counter")
+	}
+	return nil
+}
+
+// storageSink writes events to the Namespace of the storage, for them to be exported later.
+type storageSink struct {
+	db storage.ServiceStorage
+}
+
+func (s storageSink) Write(ctx context.Context, event Event) error {
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshalling analytics event")
+	}
+	return s.db.Write(ctx, Namespace, webhook.NewEventID(), eventBytes)
+}
@@ -0,0 +1,56 @@
+package credential
+
+import (
+	"time"
+
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/analytics"
+)
+
+// SetAnalytics sets the emitter of the analytics events about the credentials the service issues and verifies. It must
+// be set before the service issues or verifies credentials.
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
+// emitBatchIssuance emits the issuance analytics event of each credential of a batch, which failed when the batch did,
+// or when the transaction creating it did.
+func (s Service) emitBatchIssuance(batchRequest BatchCreateCredentialsRequest, response *BatchCreateCredentialsResponse, err error, latency time.Duration) {
+	if s.analytics == nil {
+		return
+	}
+	for i, request := range batchRequest.Requests {
+		outcome := analytics.IssuanceOutcome(err)
+		if err == nil && response.Errors != nil && response.Errors[i] != "" {
+			outcome = analytics.Failed
+		}
+		s.analytics.Emit(analytics.CredentialIssuance, request.Issuer, request.SchemaID, outcome, latency)
+	}
+}
+
+// emitVerification emits the analytics event of a credential verification, with the issuer and schema the credential
+// states, even when it failed verification.
+func (s Service) emitVerification(request VerifyCredentialRequest, response *VerifyCredentialResponse, err error, latency time.Duration) {
+	if s.analytics == nil {
+		return
+	}
+	var verified bool
+	var reasonCode string
+	if response != nil {
+		verified, reasonCode = response.Verified, response.ReasonCode
+	}
+	var issuer, schemaID string
+	if cred := unverifiedCredential(request); cred != nil {
+		// the issuer of an unverified credential may be an object without an ID, which IssuerID does not expect
+		if issuerObject, ok := cred.Issuer.(map[string]any); ok {
+			issuer, _ = issuerObject["id"].(string)
+		} else {
+			issuer = cred.IssuerID()
+		}
+		if cred.CredentialSchema != nil {
+			schemaID = cred.CredentialSchema.ID
+		}
+	}
+	outcome := analytics.VerificationOutcome(verified, reasonCode, verification.InvalidCredential, err)
+	s.analytics.Emit(analytics.CredentialVerification, issuer, schemaID, outcome, latency)
+}
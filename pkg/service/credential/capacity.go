@@ -0,0 +1,115 @@
+package credential
+
+import (
+	"context"
+	"slices"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+)
+
+type GetStatusListCapacityRequest struct {
+	Issuer string `json:"issuer" validate:"required"`
+	// Empty for status lists of credentials issued without a schema.
+	SchemaID string `json:"schemaId,omitempty"`
+	// When empty, the capacity of the revocation, suspension and message status lists is returned.
+	Purpose statussdk.StatusPurpose `json:"purpose,omitempty"`
+}
+
+type StatusListCapacity struct {
+	// ID of the status list credential.
+	StatusListCredentialID string                  `json:"statusListCredentialId"`
+	Purpose                statussdk.StatusPurpose `json:"purpose"`
+
+	// Number of bits in the status list.
+	TotalBits int `json:"totalBits"`
+	// Number of bits of each entry of a message status list.
+	StatusSize int `json:"statusSize,omitempty"`
+	// Number of indexes handed out to credentials.
+	Allocated int `json:"allocated"`
+	// Number of indexes that can still be handed out before issuing a status enabled credential fails.
+	Remaining int `json:"remaining"`
+}
+
+type GetStatusListCapacityResponse struct {
+	// Only status lists which exist are included, since a status list is created with the first credential using it.
+	StatusLists []StatusListCapacity `json:"statusLists"`
+}
+
+// GetStatusListCapacity reports how many indexes are allocated and remaining in the status lists of an issuer and
+// schema. It only reads the current index of each list, rather than its index pool or encoded bitstring.
+func (s Service) GetStatusListCapacity(ctx context.Context, request GetStatusListCapacityRequest) (*GetStatusListCapacityResponse, error) {
+	purposes := []statussdk.StatusPurpose{statussdk.StatusRevocation, statussdk.StatusSuspension, MessageStatusPurpose}
+	if request.Purpose != "" {
+		if !slices.Contains(purposes, request.Purpose) {
+			return nil, sdkutil.LoggingNewErrorf("unsupported status purpose: %s", request.Purpose)
+		}
+		purposes = []statussdk.StatusPurpose{request.Purpose}
+	}
+
+	statusLists := make([]StatusListCapacity, 0, len(purposes))
+	for _, purpose := range purposes {
+		statusListCredential, err := s.storage.GetStatusListCredentialKeyData(ctx, request.Issuer, request.SchemaID, purpose)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting status list credential key data")
+		}
+		if statusListCredential == nil {
+			continue
+		}
+		allocated, err := s.storage.GetStatusListCurrentIndex(ctx, request.Issuer, request.SchemaID, string(purpose))
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "getting current index of %s status list", purpose)
+		}
+		var statusSize int
+		if purpose == MessageStatusPurpose {
+			statusList, err := toMessageStatusList(*statusListCredential.Credential)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading message status list")
+			}
+			statusSize = statusList.StatusSize
+		}
+		statusLists = append(statusLists, StatusListCapacity{
+			StatusListCredentialID: statusListCredential.Credential.ID,
+			Purpose:                purpose,
+			TotalBits:              bitStringLength,
+			StatusSize:             statusSize,
+			Allocated:              allocated,
+			Remaining:              remainingStatusListIndexes(statusSize, allocated),
+		})
+	}
+	return &GetStatusListCapacityResponse{StatusLists: statusLists}, nil
+}
+
+// remainingStatusListIndexes returns how many more indexes can be allocated from a status list whose entries have the
+// given number of bits. The last index of the pool is never handed out, since IncrementStatusListIndexTx refuses to
+// move past it.
+func remainingStatusListIndexes(statusSize, allocated int) int {
+	return max(statusListLength(statusSize)-1-allocated, 0)
+}
+
+// checkStatusListCapacity warns when the number of remaining indexes of a status list falls below the configured
+// threshold, so that operators can act before issuance of status enabled credentials starts failing.
+func (s Service) checkStatusListCapacity(ctx context.Context, issuer, schema string, purpose statussdk.StatusPurpose, statusSize, allocated int) {
+	threshold := s.config.StatusListCapacityThreshold
+	remaining := remainingStatusListIndexes(statusSize, allocated)
+	if threshold <= 0 || remaining >= threshold {
+		return
+	}
+	logrus.Warnf("%s status list for issuer<%s> and schema<%s> has %d indexes remaining, below the threshold of %d",
+		purpose, issuer, schema, remaining, threshold)
+	s.lowCapacity.Add(ctx, 1, metric.WithAttributes(attribute.String("purpose", string(purpose))))
+}
+
+func newLowCapacityCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.status_list.low_capacity",
+		metric.WithDescription("Number of status list indexes allocated while the remaining capacity of the list is below the configured threshold"),
+	)
+}
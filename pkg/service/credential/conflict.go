@@ -0,0 +1,56 @@
+package credential
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// StatusListResource identifies a status list by the issuer, schema, and purpose it is shared by.
+type StatusListResource struct {
+	Issuer string `json:"issuer"`
+	// Empty for the status list of credentials issued without a schema.
+	SchemaID string `json:"schemaId,omitempty"`
+	Purpose  string `json:"purpose"`
+}
+
+func (r StatusListResource) String() string {
+	return fmt.Sprintf("issuer<%s> schema<%s> purpose<%s>", r.Issuer, r.SchemaID, r.Purpose)
+}
+
+// StatusListConflictError is returned when a status list was updated concurrently with the request, so that the
+// request was not applied. It is a storage.ErrConflict, and retrying the request may succeed.
+type StatusListConflictError struct {
+	// StatusLists are the status lists updated by the request, any of which may have been updated concurrently.
+	StatusLists []StatusListResource
+	Err         error
+}
+
+func (e StatusListConflictError) Error() string {
+	statusLists := make([]string, 0, len(e.StatusLists))
+	for _, statusList := range e.StatusLists {
+		statusLists = append(statusLists, statusList.String())
+	}
+	return fmt.Sprintf("status list of %s updated concurrently: %s", strings.Join(statusLists, ", "), e.Err)
+}
+
+func (e StatusListConflictError) Unwrap() error {
+	return e.Err
+}
+
+// Contended returns the status lists which may have been updated concurrently.
+func (e StatusListConflictError) Contended() any {
+	return e.StatusLists
+}
+
+// statusListConflict returns the error as a StatusListConflictError of the given status lists when it is a conflict,
+// and as is otherwise.
+func statusListConflict(err error, statusLists ...StatusListResource) error {
+	if !errors.Is(err, storage.ErrConflict) || len(statusLists) == 0 {
+		return err
+	}
+	return StatusListConflictError{StatusLists: statusLists, Err: err}
+}
@@ -0,0 +1,359 @@
+package credential
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/bits-and-blooms/bitset"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// StatusConsistencyPageSize is the number of stored credentials read at a time while checking the consistency of their
+// status with their status lists.
+const StatusConsistencyPageSize = 1000
+
+const (
+	// StatusBitUnset is the reason of a credential stored as revoked or suspended whose status list bit is not set.
+	StatusBitUnset = "BIT_UNSET"
+	// StatusBitSet is the reason of a credential stored as neither revoked nor suspended whose status list bit is set.
+	StatusBitSet = "BIT_SET"
+	// StatusListMissing is the reason of a credential whose status list credential is not stored.
+	StatusListMissing = "STATUS_LIST_MISSING"
+)
+
+type CheckStatusConsistencyRequest struct {
+	// Repair regenerates the status lists with mismatches from the stored status of their credentials.
+	Repair bool `json:"repair"`
+}
+
+// StatusMismatch is a credential whose stored status does not match the bit of its status list.
+type StatusMismatch struct {
+	CredentialID           string `json:"credentialId"`
+	StatusListCredentialID string `json:"statusListCredentialId"`
+	StatusListIndex        int    `json:"statusListIndex"`
+	StatusPurpose          string `json:"statusPurpose"`
+	// Stored is whether the credential is stored as revoked, or suspended, depending on the purpose of its status list.
+	Stored bool `json:"stored"`
+	// Reason is one of StatusBitUnset, StatusBitSet, or StatusListMissing.
+	Reason string `json:"reason"`
+	// Repaired is true when the status list was regenerated from the stored status of its credentials.
+	Repaired bool `json:"repaired"`
+}
+
+// StatusConsistencyResult is the outcome of a status consistency check.
+type StatusConsistencyResult struct {
+	CheckedAt string `json:"checkedAt"`
+	// The number of credentials with a status entry, and of status lists, checked.
+	Credentials int `json:"credentials"`
+	StatusLists int `json:"statusLists"`
+	// The number of status lists regenerated to repair their mismatches.
+	RepairedStatusLists int              `json:"repairedStatusLists"`
+	Mismatches          []StatusMismatch `json:"mismatches,omitempty"`
+}
+
+// StatusMismatchFunc is called with each mismatch found by CheckStatusConsistency.
+type StatusMismatchFunc func(ctx context.Context, mismatch StatusMismatch)
+
+// OnStatusMismatch sets the function notified of each status mismatch found. It must be set before the service checks
+// the consistency of statuses.
+func (s *Service) OnStatusMismatch(statusMismatch StatusMismatchFunc) {
+	s.statusMismatch = statusMismatch
+}
+
+// statusEntry is the status list entry of a stored credential, along with its stored status.
+type statusEntry struct {
+	credentialID           string
+	statusListCredentialID string
+	index                  int
+	purpose                string
+	// set is whether the bit of the entry should be set
+	set bool
+}
+
+// statusListGroup holds the entries of the credentials of a status list, so that it is decoded once.
+type statusListGroup struct {
+	watchKey storage.WatchKey
+	entries  []statusEntry
+}
+
+// RunStatusConsistencyCheck checks the consistency of the stored status of credentials with their status lists every
+// check interval, repairing mismatches when configured, until the context is done. It returns immediately when the
+// check is not scheduled.
+func (s Service) RunStatusConsistencyCheck(ctx context.Context) {
+	if s.consistencyCheckInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.consistencyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CheckStatusConsistency(ctx, CheckStatusConsistencyRequest{Repair: s.config.StatusConsistencyRepair}); err != nil {
+				logrus.WithError(err).Error("checking status consistency")
+			}
+		}
+	}
+}
+
+// CheckStatusConsistency compares the stored revoked and suspended flags of the credentials issued with a status list
+// of the service to the bits of their status lists. Credentials are read one page at a time, and grouped by status
+// list, so that each status list is decoded once. Each mismatch is counted and notified. When repair is requested, the
+// status lists with mismatches are regenerated from the stored status of their credentials, as status updates do.
+func (s Service) CheckStatusConsistency(ctx context.Context, request CheckStatusConsistencyRequest) (*StatusConsistencyResult, error) {
+	groups, checked, err := s.storage.statusListGroups(ctx, StatusConsistencyPageSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "grouping credentials by status list")
+	}
+	result := StatusConsistencyResult{Credentials: checked, StatusLists: len(groups)}
+
+	var repaired []*credint.Container
+	for _, group := range groups {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		mismatches, err := s.checkStatusList(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		if len(mismatches) > 0 && request.Repair {
+			statusList, err := s.repairStatusList(ctx, group)
+			if err != nil {
+				logrus.WithError(err).Errorf("repairing status list credential with key: %s", group.watchKey.Key)
+			} else {
+				logrus.Infof("repaired status list credential<%s> with %d mismatches", statusList.ID, len(mismatches))
+				repaired = append(repaired, statusList)
+				for i := range mismatches {
+					mismatches[i].Repaired = true
+				}
+			}
+		}
+		for _, mismatch := range mismatches {
+			logrus.Warnf("credential<%s> stored with status %t has a %s mismatch with index %d of status list credential<%s>",
+				mismatch.CredentialID, mismatch.Stored, mismatch.Reason, mismatch.StatusListIndex, mismatch.StatusListCredentialID)
+			s.statusMismatches.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("reason", mismatch.Reason),
+				attribute.Bool("repaired", mismatch.Repaired),
+			))
+			if s.statusMismatch != nil {
+				s.statusMismatch(ctx, mismatch)
+			}
+		}
+		result.Mismatches = append(result.Mismatches, mismatches...)
+	}
+
+	s.publishStatusLists(repaired...)
+	result.RepairedStatusLists = len(repaired)
+	result.CheckedAt = s.Clock.Now().UTC().Format(time.RFC3339)
+	return &result, nil
+}
+
+// checkStatusList returns the entries of the group whose bit in their status list does not match their stored status.
+func (s Service) checkStatusList(ctx context.Context, group statusListGroup) ([]StatusMismatch, error) {
+	mismatch := func(entry statusEntry, reason string) StatusMismatch {
+		return StatusMismatch{
+			CredentialID:           entry.credentialID,
+			StatusListCredentialID: entry.statusListCredentialID,
+			StatusListIndex:        entry.index,
+			StatusPurpose:          entry.purpose,
+			Stored:                 entry.set,
+			Reason:                 reason,
+		}
+	}
+
+	var mismatches []StatusMismatch
+	statusList, err := s.storage.GetStatusListCredentialByWatchKey(ctx, group.watchKey)
+	if err != nil {
+		return nil, err
+	}
+	if statusList == nil || statusList.Credential == nil {
+		for _, entry := range group.entries {
+			mismatches = append(mismatches, mismatch(entry, StatusListMissing))
+		}
+		return mismatches, nil
+	}
+
+	bits, err := statusListBits(*statusList.Credential)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding status list credential<%s>", statusList.LocalCredentialID)
+	}
+	for _, entry := range group.entries {
+		switch published := bits.Test(uint(entry.index)); {
+		case entry.set && !published:
+			mismatches = append(mismatches, mismatch(entry, StatusBitUnset))
+		case !entry.set && published:
+			mismatches = append(mismatches, mismatch(entry, StatusBitSet))
+		}
+	}
+	return mismatches, nil
+}
+
+// repairStatusList regenerates the status list of the group from the stored status of the credentials using it. It watches the same key as status updates do, so that it cannot overwrite a status list
+// regenerated by a concurrent status update.
+func (s Service) repairStatusList(ctx context.Context, group statusListGroup) (*credint.Container, error) {
+	repairFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		entry := group.entries[0]
+		gotCred, err := s.storage.GetCredential(ctx, entry.credentialID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading credential<%s>", entry.credentialID)
+		}
+		var revokedOrSuspendedStatusCreds []credential.VerifiableCredential
+		visit := func(cred StoredCredential) error {
+			if credEntry, ok := statusEntryOf(cred); ok && credEntry.purpose == entry.purpose && credEntry.set {
+				revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, statusOnly(*cred.Credential))
+			}
+			return nil
+		}
+		if err = s.forEachStatusListCredential(ctx, entry.statusListCredentialID, gotCred.Issuer, gotCred.Schema, visit); err != nil {
+			return nil, errors.Wrap(err, "reading credentials of status list")
+		}
+		slcMetadata := StatusListCredentialMetadata{statusListCredentialWatchKey: group.watchKey}
+		return s.regenerateStatusList(ctx, tx, gotCred, revokedOrSuspendedStatusCreds, slcMetadata)
+	}
+	returnValue, err := s.storage.db.Execute(ctx, repairFunc, []storage.WatchKey{group.watchKey})
+	if err != nil {
+		return nil, errors.Wrap(err, "execute")
+	}
+	statusList, ok := returnValue.(*credint.Container)
+	if !ok {
+		return nil, errors.New("casting to status list container")
+	}
+	return statusList, nil
+}
+
+// statusListGroups reads the stored credentials in pages of the given size, and groups the status entries of those
+// issued with a revocation or suspension status list of the service by status list. The groups are returned sorted by
+// key, along with the number of entries grouped.
+func (cs *Storage) statusListGroups(ctx context.Context, pageSize int) ([]statusListGroup, int, error) {
+	groups := make(map[string]*statusListGroup)
+	grouped := 0
+	pageToken := ""
+	for {
+		page, nextPageToken, err := cs.db.ReadPage(ctx, credentialNamespace, pageToken, pageSize)
+		if err != nil {
+			return nil, 0, sdkutil.LoggingErrorMsg(err, "reading page of credentials to check")
+		}
+		for key, data := range page {
+			var stored StoredCredential
+			if err = unmarshalStoredCredential(data, &stored); err != nil {
+				logrus.WithError(err).Warnf("skipping credential entry<%s> which can't be unmarshalled", key)
+				continue
+			}
+			entry, ok := statusEntryOf(stored)
+			if !ok {
+				continue
+			}
+			watchKey := cs.GetStatusListCredentialWatchKey(stored.Issuer, stored.Schema, entry.purpose)
+			group, ok := groups[watchKey.Key]
+			if !ok {
+				group = &statusListGroup{watchKey: watchKey}
+				groups[watchKey.Key] = group
+			}
+			group.entries = append(group.entries, entry)
+			grouped++
+		}
+		if nextPageToken == "" {
+			break
+		}
+		pageToken = nextPageToken
+	}
+
+	sorted := make([]statusListGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Slice(group.entries, func(i, j int) bool { return group.entries[i].credentialID < group.entries[j].credentialID })
+		sorted = append(sorted, *group)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].watchKey.Key < sorted[j].watchKey.Key })
+	return sorted, grouped, nil
+}
+
+// statusEntryOf returns the status list entry of a credential issued with a revocation or suspension status list of
+// the service. Imported credentials, and credentials of message status lists, have none, since they are not stored
+// with a revoked or suspended flag.
+func statusEntryOf(stored StoredCredential) (statusEntry, bool) {
+	if stored.Imported || !stored.HasCredentialStatus() {
+		return statusEntry{}, false
+	}
+	credentialStatus, ok := stored.Credential.CredentialStatus.(map[string]any)
+	if !ok {
+		return statusEntry{}, false
+	}
+	purpose, _ := credentialStatus["statusPurpose"].(string)
+	statusListCredentialURI, _ := credentialStatus["statusListCredential"].(string)
+	index, err := strconv.Atoi(fmt.Sprint(credentialStatus["statusListIndex"]))
+	if err != nil || index < 0 {
+		return statusEntry{}, false
+	}
+	statusListCredentialID, err := parseIDFromURI(statusListCredentialURI)
+	if err != nil {
+		return statusEntry{}, false
+	}
+
+	entry := statusEntry{
+		credentialID:           stored.LocalCredentialID,
+		statusListCredentialID: statusListCredentialID,
+		index:                  index,
+		purpose:                purpose,
+	}
+	switch statussdk.StatusPurpose(purpose) {
+	case statussdk.StatusRevocation:
+		entry.set = stored.Revoked
+	case statussdk.StatusSuspension:
+		entry.set = stored.Suspended
+	default:
+		return statusEntry{}, false
+	}
+	return entry, true
+}
+
+// statusListBits expands the bitstring of a StatusList2021 credential.
+func statusListBits(statusList credential.VerifiableCredential) (*bitset.BitSet, error) {
+	encodedList, ok := statusList.CredentialSubject["encodedList"].(string)
+	if !ok {
+		return nil, errors.New("status list credential has no encoded list")
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encodedList)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding encoded list")
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating gzip reader")
+	}
+	defer zr.Close()
+	uncompressed, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing encoded list")
+	}
+	bits := new(bitset.BitSet)
+	if err = bits.UnmarshalBinary(uncompressed); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling bitstring")
+	}
+	return bits, nil
+}
+
+func newStatusMismatchesCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.status_list.status_mismatches",
+		metric.WithDescription("Number of credentials whose stored status does not match the bit of their status list, by reason and whether it was repaired"),
+	)
+}
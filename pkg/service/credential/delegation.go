@@ -0,0 +1,44 @@
+package credential
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+)
+
+// Delegations looks up the delegations by which DIDs authorize other DIDs to sign credentials on their behalf.
+type Delegations interface {
+	// GetActiveDelegationJWT returns the delegation credential JWT by which the delegator authorizes the delegate, or
+	// nil when there is none which is neither revoked nor expired.
+	GetActiveDelegationJWT(ctx context.Context, delegator, delegate string) (*keyaccess.JWT, error)
+}
+
+// SetDelegations sets where delegations are looked up, so that keys of a DID can sign credentials on behalf of the DIDs
+// which delegated to it. It must be set before the service signs credentials.
+func (s *Service) SetDelegations(delegations Delegations) {
+	s.delegations = delegations
+}
+
+// delegationClaims returns the claims of a credential signed with a key of the delegate on behalf of its issuer, the
+// delegator, which carry the delegation so that verifiers can check it. It fails when there is no active delegation.
+func (s Service) delegationClaims(ctx context.Context, delegator, delegate, keyID string, claims map[string]any) (map[string]any, error) {
+	if s.delegations == nil {
+		return nil, sdkutil.LoggingNewErrorf("key controller<%s> does not match credential issuer<%s> for key<%s>", delegate, delegator, keyID)
+	}
+	delegationJWT, err := s.delegations.GetActiveDelegationJWT(ctx, delegator, delegate)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting delegation of issuer<%s> to DID<%s>", delegator, delegate)
+	}
+	if delegationJWT == nil {
+		return nil, sdkutil.LoggingNewErrorf("key controller<%s> does not match credential issuer<%s> for key<%s>, which did not delegate to it", delegate, delegator, keyID)
+	}
+	delegated := make(map[string]any, len(claims)+1)
+	for claim, value := range claims {
+		delegated[claim] = value
+	}
+	delegated[verification.DelegationClaim] = delegationJWT.String()
+	return delegated, nil
+}
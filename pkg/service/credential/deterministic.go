@@ -0,0 +1,119 @@
+package credential
+
+import (
+	"context"
+	"crypto/sha256"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/gowebpki/jcs"
+	"github.com/pkg/errors"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// deterministicIDNamespace is the namespace of the name-based UUIDs derived for credentials when deterministic IDs
+// are enabled. It must never change, or identical credentials created before and after the change get different IDs.
+var deterministicIDNamespace = uuid.MustParse("6f0b8a53-3c2e-4d6b-9a4f-1e7c2d5b8f90")
+
+// deterministicContent is the content of a credential its deterministic ID is derived from.
+type deterministicContent struct {
+	Issuer  string         `json:"issuer"`
+	Subject string         `json:"subject"`
+	Schema  string         `json:"schema"`
+	Data    map[string]any `json:"data"`
+	// omitted when empty, so that the IDs of credentials created before types could be requested are unchanged
+	Types []string `json:"types,omitempty"`
+}
+
+// newCredentialID returns the UUID of a credential to be created, either random, or derived from the request when
+// deterministic IDs are enabled.
+func (s Service) newCredentialID(request CreateCredentialRequest) (string, error) {
+	if !s.config.DeterministicID {
+		return uuid.NewString(), nil
+	}
+	return deterministicCredentialID(request)
+}
+
+// deterministicCredentialID derives the UUID of a credential from the SHA-256 hash of its issuer, subject, schema, and
+// data, canonicalized with the JSON Canonicalization Scheme (RFC 8785) so that the order of claims does not matter.
+func deterministicCredentialID(request CreateCredentialRequest) (string, error) {
+	// the subject is set as the `id` of the data when the credential is built, so it is left out of the data
+	data := make(map[string]any, len(request.Data))
+	for claim, value := range request.Data {
+		if claim != credential.VerifiableCredentialIDProperty {
+			data[claim] = value
+		}
+	}
+	// the subject type is set as the `type` of the data, like when it is given in the data
+	if request.SubjectType != "" {
+		data[SubjectTypeProperty] = request.SubjectType
+	}
+	contentBytes, err := json.Marshal(deterministicContent{
+		Issuer:  request.Issuer,
+		Subject: request.Subject,
+		Schema:  request.SchemaID,
+		Data:    data,
+		Types:   request.Types,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling credential content")
+	}
+	canonicalBytes, err := jcs.Transform(contentBytes)
+	if err != nil {
+		return "", errors.Wrap(err, "canonicalizing credential content")
+	}
+	return uuid.NewHash(sha256.New(), deterministicIDNamespace, canonicalBytes, 8).String(), nil
+}
+
+// deterministicIDWatchKeys returns the key of the credential the request derives its ID from, so that two identical
+// credentials can't be created concurrently.
+func (s Service) deterministicIDWatchKeys(request CreateCredentialRequest) ([]storage.WatchKey, error) {
+	if !s.config.DeterministicID {
+		return nil, nil
+	}
+	credentialID, err := deterministicCredentialID(request)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "deriving credential id")
+	}
+	return []storage.WatchKey{s.storage.GetCredentialWatchKey(credentialID, request.Issuer, request.Subject, request.SchemaID)}, nil
+}
+
+// existingDeterministicCredential returns the credential previously created with the same deterministic ID, if any,
+// so that creating it again returns it as is. The credential is returned even when it has since been revoked,
+// suspended, or has expired.
+func (s Service) existingDeterministicCredential(ctx context.Context, credentialID string) (*CreateCredentialResponse, error) {
+	if !s.config.DeterministicID {
+		return nil, nil
+	}
+	gotCred, err := s.storage.GetCredentialIfExists(ctx, credentialID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", credentialID)
+	}
+	if gotCred == nil {
+		return nil, nil
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", credentialID)
+	}
+	container := credint.Container{
+		ID:                                 gotCred.LocalCredentialID,
+		FullyQualifiedVerificationMethodID: gotCred.FullyQualifiedVerificationMethodID,
+		Credential:                         gotCred.Credential,
+		CredentialJWT:                      gotCred.CredentialJWT,
+		Revoked:                            gotCred.Revoked,
+		Suspended:                          gotCred.Suspended,
+		StatusValue:                        gotCred.StatusValue,
+		StatusReason:                       gotCred.StatusReason,
+		StatusReasonCode:                   gotCred.StatusReasonCode,
+		RenderMethod:                       gotCred.RenderMethod,
+		MissingClaims:                      gotCred.MissingClaims,
+		AutoRenew:                          gotCred.AutoRenew,
+		PreviousCredential:                 gotCred.PreviousCredential,
+		CreatedBy:                          gotCred.CreatedBy,
+	}
+	return &CreateCredentialResponse{Container: container}, nil
+}
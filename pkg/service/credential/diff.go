@@ -0,0 +1,104 @@
+package credential
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/sirupsen/logrus"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// diffedClaims are the top level claims of credentials which are compared, beyond the credential subject.
+var diffedClaims = []string{"issuer", "credentialSchema", "expirationDate", "credentialSubject"}
+
+type Change struct {
+	// Path of the claim within the credential, such as `credentialSubject.address.city`.
+	Path string     `json:"path"`
+	Type ChangeType `json:"type"`
+	// Value of the claim in the first credential. Unset when the claim was added.
+	Before any `json:"before,omitempty"`
+	// Value of the claim in the second credential. Unset when the claim was removed.
+	After any `json:"after,omitempty"`
+}
+
+type DiffCredentialsResponse struct {
+	// Changes from the first credential to the second one, ordered by path. Empty when they hold the same claims.
+	Changes []Change `json:"changes"`
+}
+
+// DiffCredentials compares the subject, issuer, schema, and expiry of two credentials, such as a credential and the one
+// it was reissued as, so that an auditor can confirm only the intended claims changed. Objects are compared claim by
+// claim, while arrays are compared as a whole.
+func (s Service) DiffCredentials(ctx context.Context, idA, idB string) (*DiffCredentialsResponse, error) {
+	logrus.Debugf("diffing credentials: %s and %s", idA, idB)
+
+	claimsA, err := s.diffableClaims(ctx, idA)
+	if err != nil {
+		return nil, err
+	}
+	claimsB, err := s.diffableClaims(ctx, idB)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	for _, claim := range diffedClaims {
+		changes = diffClaim(changes, claim, claimsA[claim], claimsB[claim])
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return &DiffCredentialsResponse{Changes: changes}, nil
+}
+
+func (s Service) diffableClaims(ctx context.Context, id string) (map[string]any, error) {
+	gotCred, err := s.storage.GetCredential(ctx, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", id)
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", id)
+	}
+	claims, err := credentialClaims(*gotCred.Credential)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting claims of credential<%s>", id)
+	}
+	return claims, nil
+}
+
+// diffClaim appends the changes between two values of the claim at the path, descending into objects.
+func diffClaim(changes []Change, path string, before, after any) []Change {
+	switch {
+	case before == nil && after == nil:
+		return changes
+	case before == nil:
+		return append(changes, Change{Path: path, Type: ChangeAdded, After: after})
+	case after == nil:
+		return append(changes, Change{Path: path, Type: ChangeRemoved, Before: before})
+	}
+
+	beforeObject, beforeIsObject := before.(map[string]any)
+	afterObject, afterIsObject := after.(map[string]any)
+	if !beforeIsObject || !afterIsObject {
+		if !reflect.DeepEqual(before, after) {
+			changes = append(changes, Change{Path: path, Type: ChangeChanged, Before: before, After: after})
+		}
+		return changes
+	}
+	for key, value := range beforeObject {
+		changes = diffClaim(changes, path+"."+key, value, afterObject[key])
+	}
+	for key, value := range afterObject {
+		if _, ok := beforeObject[key]; !ok {
+			changes = diffClaim(changes, path+"."+key, nil, value)
+		}
+	}
+	return changes
+}
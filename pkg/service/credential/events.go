@@ -0,0 +1,301 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ErrInvalidEventCursor is returned when listing the events after a cursor which is not the ID of an event.
+var ErrInvalidEventCursor = errors.New("invalid event cursor")
+
+// eventSequenceKey is the key of the sequence number of the last event appended to the event log.
+const eventSequenceKey = "last"
+
+// Event is an entry of the credential event log, appended in the transaction of the change it is about. Unlike the
+// events of the webhook outbox, which are removed once published, events are kept so that consumers can replay them.
+type Event struct {
+	// ID of the event, its sequence number in the event log, which sorts events in the order they were committed. It is
+	// the cursor to list the events after it.
+	ID string `json:"id"`
+	// Verb is one of Create, StatusUpdate, or Delete.
+	Verb         webhook.Verb `json:"verb"`
+	CredentialID string       `json:"credentialId"`
+	// Data is the created credential for Create events, and the updated status for StatusUpdate events.
+	Data json.RawMessage `json:"data,omitempty"`
+	// Principal which made the change: the name of the API key of its caller, or `anonymous`. Not set for changes
+	// made by the service itself, or before principals were recorded.
+	Principal string `json:"principal,omitempty"`
+	// CreatedAt is the time, to the nanosecond, the event was written.
+	CreatedAt string `json:"createdAt"`
+}
+
+type ListEventsRequest struct {
+	// Cursor is the ID of the last event the consumer processed. Events are listed from the start of the log when it
+	// is empty.
+	Cursor string
+	// The page token, when set, is the cursor of the next page, and takes precedence over Cursor.
+	PageRequest *pagination.PageRequest
+}
+
+type ListEventsResponse struct {
+	Events []Event
+	// Cursor is the ID of the last event listed, or the cursor of the request when no event was listed, from which the
+	// consumer continues once it has processed the events.
+	Cursor        string
+	NextPageToken string
+}
+
+// ListEvents returns the events of the credential event log written after a cursor, in the order they were written.
+// A consumer which stores the cursor of the response once it has processed the events, and lists the events after it
+// again, is delivered every event at least once.
+func (s Service) ListEvents(ctx context.Context, request ListEventsRequest) (*ListEventsResponse, error) {
+	page := request.PageRequest.ToServicePage()
+	cursor := request.Cursor
+	if page.Token != "" {
+		cursor = page.Token
+	}
+	logrus.Debugf("listing credential events after cursor: %s", cursor)
+
+	events, more, err := s.storage.ListEvents(ctx, cursor, page.Size)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not list credential events")
+	}
+	response := ListEventsResponse{Events: events, Cursor: cursor}
+	if len(events) > 0 {
+		response.Cursor = events[len(events)-1].ID
+	}
+	if more {
+		response.NextPageToken = response.Cursor
+	}
+	return &response, nil
+}
+
+// execute executes the business logic in a transaction which appends events to the event log, when it is enabled.
+func (s Service) execute(ctx context.Context, businessLogicFunc storage.BusinessLogicFunc, watchKeys []storage.WatchKey) (any, error) {
+	if !s.config.EventLogEnabled {
+		return s.storage.db.Execute(ctx, businessLogicFunc, watchKeys)
+	}
+	return s.storage.executeWithEventLog(ctx, businessLogicFunc, watchKeys)
+}
+
+// appendEvent appends an event about a credential to the event log in the transaction changing the credential, when
+// the event log is enabled. The transaction must be executed with execute.
+func (s Service) appendEvent(ctx context.Context, tx storage.Tx, verb webhook.Verb, credentialID, principal string, data any) error {
+	if !s.config.EventLogEnabled {
+		return nil
+	}
+	sequence, err := s.storage.nextEventSequenceTx(ctx, tx)
+	if err != nil {
+		return errors.Wrapf(err, "allocating sequence number of %s event of credential<%s>", verb, credentialID)
+	}
+	event := Event{
+		ID:           eventID(sequence),
+		Verb:         verb,
+		CredentialID: credentialID,
+		Principal:    principal,
+		CreatedAt:    time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if data != nil {
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return errors.Wrapf(err, "marshalling data of %s event of credential<%s>", verb, credentialID)
+		}
+		event.Data = dataBytes
+	}
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling %s event of credential<%s>", verb, credentialID)
+	}
+	if err = tx.Write(ctx, credentialEventNamespace, event.ID, eventBytes); err != nil {
+		return errors.Wrapf(err, "writing %s event of credential<%s>", verb, credentialID)
+	}
+	return s.storage.writeStatusEventTx(ctx, tx, event)
+}
+
+// appendDeletedEvent returns the function appending the Delete event of a credential, made by the principal, to the
+// event log, in the transaction deleting it.
+func (s Service) appendDeletedEvent(principal string) OnDeleteFunc {
+	return func(ctx context.Context, tx storage.Tx, deleted StoredCredential) error {
+		return s.appendEvent(ctx, tx, webhook.Delete, deleted.LocalCredentialID, principal, nil)
+	}
+}
+
+// statusAt returns the status the credential with the given ID had at a past time, as last updated by then in the
+// event log, or nil when the event log does not have the Create event of the credential, and so may be missing some of
+// its status updates. Only the status events of the credential are read.
+func (cs *Storage) statusAt(ctx context.Context, credentialID string, at time.Time) (*Status, error) {
+	gotEvents, err := cs.db.ReadPrefix(ctx, credentialStatusEventNamespace, statusEventKey(credentialID, ""))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading status events of credential<%s>", credentialID)
+	}
+	keys := make([]string, 0, len(gotEvents))
+	for key := range gotEvents {
+		keys = append(keys, key)
+	}
+	// keys sort by the IDs of the events, so in the order they were written
+	sort.Strings(keys)
+
+	var created bool
+	var status Status
+	for _, key := range keys {
+		var event Event
+		if err = json.Unmarshal(gotEvents[key], &event); err != nil {
+			return nil, errors.Wrapf(err, "unmarshalling status event<%s>", key)
+		}
+		switch event.Verb {
+		case webhook.Create:
+			created = true
+		case webhook.StatusUpdate:
+			writtenAt, err := time.Parse(time.RFC3339Nano, event.CreatedAt)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing time of credential event<%s>", event.ID)
+			}
+			if writtenAt.After(at) {
+				continue
+			}
+			if err = json.Unmarshal(event.Data, &status); err != nil {
+				return nil, errors.Wrapf(err, "unmarshalling status of credential event<%s>", event.ID)
+			}
+		}
+	}
+	if !created {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+// writeStatusEventTx writes the Create and StatusUpdate events of a credential under its ID as well, so that the status
+// a credential had at a past time is read from its own events. Create events are written without the credential.
+func (cs *Storage) writeStatusEventTx(ctx context.Context, tx storage.Tx, event Event) error {
+	switch event.Verb {
+	case webhook.Create:
+		event.Data = nil
+	case webhook.StatusUpdate:
+	default:
+		return nil
+	}
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling status event of credential<%s>", event.CredentialID)
+	}
+	if err = tx.Write(ctx, credentialStatusEventNamespace, statusEventKey(event.CredentialID, event.ID), eventBytes); err != nil {
+		return errors.Wrapf(err, "writing status event of credential<%s>", event.CredentialID)
+	}
+	return nil
+}
+
+// statusEventKey returns the key of a status event of a credential, which is prefixed with the ID of the credential.
+func statusEventKey(credentialID, eventID string) string {
+	return storage.Join(credentialID, eventID)
+}
+
+// ListEvents returns at most limit events written after the event with the cursor as ID, all of them when limit is -1,
+// along with whether more events follow them. Only the events listed are read.
+func (cs *Storage) ListEvents(ctx context.Context, cursor string, limit int) ([]Event, bool, error) {
+	after := uint64(0)
+	if cursor != "" {
+		var err error
+		if after, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			return nil, false, errors.Wrapf(ErrInvalidEventCursor, "cursor<%s> is not a sequence number", cursor)
+		}
+	}
+	last, err := cs.lastEventSequence(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if last <= after {
+		return []Event{}, false, nil
+	}
+	count := last - after
+	more := limit >= 0 && count > uint64(limit)
+	if more {
+		count = uint64(limit)
+	}
+
+	events := make([]Event, 0, count)
+	for sequence := after + 1; sequence <= after+count; sequence++ {
+		key := eventID(sequence)
+		eventBytes, err := cs.db.Read(ctx, credentialEventNamespace, key)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "reading credential event<%s>", key)
+		}
+		var event Event
+		if err = json.Unmarshal(eventBytes, &event); err != nil {
+			return nil, false, errors.Wrapf(err, "unmarshalling credential event<%s>", key)
+		}
+		events = append(events, event)
+	}
+	return events, more, nil
+}
+
+// eventLogTx is a transaction which appends events to the event log. It tracks the last sequence number it allocated,
+// since reads in a transaction do not see its own writes.
+type eventLogTx struct {
+	storage.Tx
+	lastSequence uint64
+}
+
+// executeWithEventLog executes the business logic in a transaction which watches the sequence number of the last event,
+// so that concurrent transactions appending events do not allocate the same sequence numbers.
+func (cs *Storage) executeWithEventLog(ctx context.Context, businessLogicFunc storage.BusinessLogicFunc, watchKeys []storage.WatchKey) (any, error) {
+	watchKeys = append(watchKeys, storage.WatchKey{Namespace: credentialEventSequenceNamespace, Key: eventSequenceKey})
+	return cs.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		// each attempt of the transaction allocates sequence numbers anew
+		return businessLogicFunc(ctx, &eventLogTx{Tx: tx})
+	}, watchKeys)
+}
+
+// lastEventSequence returns the sequence number of the last event appended to the event log, or 0 when it is empty.
+// Since sequence numbers are allocated in the transactions appending the events, every event up to it is committed.
+func (cs *Storage) lastEventSequence(ctx context.Context) (uint64, error) {
+	sequenceBytes, err := cs.db.Read(ctx, credentialEventSequenceNamespace, eventSequenceKey)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading credential event sequence")
+	}
+	if len(sequenceBytes) == 0 {
+		return 0, nil
+	}
+	sequence, err := strconv.ParseUint(string(sequenceBytes), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing credential event sequence")
+	}
+	return sequence, nil
+}
+
+// nextEventSequenceTx allocates the sequence number of an event in the transaction appending it.
+func (cs *Storage) nextEventSequenceTx(ctx context.Context, tx storage.Tx) (uint64, error) {
+	eventTx, ok := tx.(*eventLogTx)
+	if !ok {
+		return 0, errors.New("transaction does not append to the event log")
+	}
+	if eventTx.lastSequence == 0 {
+		last, err := cs.lastEventSequence(ctx)
+		if err != nil {
+			return 0, err
+		}
+		eventTx.lastSequence = last
+	}
+	eventTx.lastSequence++
+	sequenceBytes := []byte(strconv.FormatUint(eventTx.lastSequence, 10))
+	if err := tx.Write(ctx, credentialEventSequenceNamespace, eventSequenceKey, sequenceBytes); err != nil {
+		return 0, errors.Wrap(err, "writing credential event sequence")
+	}
+	return eventTx.lastSequence, nil
+}
+
+// eventID returns the ID of the event with the given sequence number, padded so that IDs sort by sequence number.
+func eventID(sequence uint64) string {
+	return fmt.Sprintf("%020d", sequence)
+}
@@ -0,0 +1,44 @@
+package credential
+
+import (
+	"context"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// ErrMissingExpectedType is returned when creating a credential against a schema with an expected type, without that
+// type in the types of the request.
+var ErrMissingExpectedType = errors.New("credential is missing the type expected by its schema")
+
+// checkExpectedType rejects a request whose types do not contain the expected type of its schema, if any.
+func (csr CreateCredentialRequest) checkExpectedType() error {
+	if csr.expectedType == "" || sdkutil.Contains(csr.expectedType, csr.Types) {
+		return nil
+	}
+	return sdkutil.LoggingError(errors.Wrapf(ErrMissingExpectedType, "schema<%s> expects type<%s>, but the requested types are %v", csr.SchemaID, csr.expectedType, csr.Types))
+}
+
+// credentialExpectations returns the expectations of the request, along with the type expected by the schema of the
+// credential when the schema is stored by the service.
+func (s Service) credentialExpectations(ctx context.Context, request VerifyCredentialRequest, cred credential.VerifiableCredential) verification.CredentialExpectations {
+	expectations := verification.CredentialExpectations{SchemaID: request.ExpectedSchemaID, Types: request.ExpectedTypes}
+	if cred.CredentialSchema == nil {
+		return expectations
+	}
+	gotSchema, err := s.schema.GetSchema(ctx, schema.GetSchemaRequest{ID: cred.CredentialSchema.ID})
+	if err != nil {
+		logrus.WithError(err).Debugf("not checking the expected type of schema<%s>, which is not stored", cred.CredentialSchema.ID)
+		return expectations
+	}
+	if gotSchema.ExpectedType != "" {
+		types := make([]string, 0, len(expectations.Types)+1)
+		expectations.Types = append(append(types, expectations.Types...), gotSchema.ExpectedType)
+	}
+	return expectations
+}
@@ -0,0 +1,390 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+)
+
+// ExternalStatusSource marks a status read from the status list credential of the issuer of an imported credential.
+const ExternalStatusSource = "external"
+
+// maxStatusListSize bounds the size of fetched status list credentials. A status list of the minimum length is 16KB
+// uncompressed, and far smaller once compressed.
+const maxStatusListSize = 1 << 20
+
+type ImportCredentialRequest struct {
+	DataIntegrityCredential *credential.VerifiableCredential `json:"credential,omitempty"`
+	CredentialJWT           *keyaccess.JWT                   `json:"credentialJwt,omitempty"`
+	// Principal importing the credential, recorded on it.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+// IsValid checks that exactly one of a data integrity credential (with proof) or a credential JWT is present.
+func (icr ImportCredentialRequest) IsValid() error {
+	if icr.DataIntegrityCredential == nil && icr.CredentialJWT == nil {
+		return errors.New("either a credential or a credential JWT must be provided")
+	}
+	if icr.DataIntegrityCredential != nil && icr.CredentialJWT != nil {
+		return errors.New("only one of credential or credential JWT can be provided")
+	}
+	if icr.DataIntegrityCredential != nil && icr.DataIntegrityCredential.Proof == nil {
+		return errors.New("credential must have a proof")
+	}
+	return nil
+}
+
+type ImportCredentialResponse struct {
+	credint.Container
+}
+
+// ImportCredential verifies a credential issued by another party and stores it, so that it can be listed alongside
+// the credentials the service issued, and its status checked against the status list credential of its issuer.
+func (s Service) ImportCredential(ctx context.Context, request ImportCredentialRequest) (*ImportCredentialResponse, error) {
+	logrus.Debugf("importing credential: %+v", request)
+
+	if err := request.IsValid(); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid import credential request")
+	}
+
+	container := credint.Container{
+		ID:            uuid.NewString(),
+		Credential:    request.DataIntegrityCredential,
+		CredentialJWT: request.CredentialJWT,
+		Imported:      true,
+		CreatedBy:     request.CreatedBy,
+	}
+	if request.CredentialJWT != nil {
+		cred, err := credint.ParseVerifiableCredentialFromJWT(request.CredentialJWT.String())
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "parsing credential from jwt")
+		}
+		container.Credential = cred
+	}
+	if container.Credential.ID == "" {
+		return nil, sdkutil.LoggingNewError("credential must have an id to be imported")
+	}
+	if _, ok := container.Credential.Issuer.(string); !ok {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> must have a string issuer to be imported", container.Credential.ID)
+	}
+	if err := s.verifier.VerifyCredential(ctx, container); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "verifying credential<%s>", container.Credential.ID)
+	}
+
+	if err := s.storage.StoreCredential(ctx, StoreCredentialRequest{Container: container}); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store imported credential")
+	}
+	return &ImportCredentialResponse{Container: container}, nil
+}
+
+// externalCredentialStatus evaluates the status of an imported credential against the status list credential of its
+// issuer. When the status list cannot be fetched, verified, or evaluated, the status is reported as unknown rather than
+// as neither revoked nor suspended.
+func (s Service) externalCredentialStatus(ctx context.Context, gotCred *StoredCredential) *GetCredentialStatusResponse {
+	response := GetCredentialStatusResponse{Source: ExternalStatusSource}
+	if !gotCred.HasCredentialStatus() {
+		return &response
+	}
+
+	entry, err := toStatusList2021Entry(gotCred.Credential.CredentialStatus)
+	if err != nil {
+		return unknownStatus(response, err, gotCred.LocalCredentialID)
+	}
+	statusList, stale, err := s.fetchStatusListCredential(ctx, entry.StatusListCredential, gotCred.Issuer)
+	if err != nil {
+		return unknownStatus(response, err, gotCred.LocalCredentialID)
+	}
+	response.Stale = stale
+
+	// the parsed entry is evaluated, since the sdk cannot evaluate entries with missing or non-string properties
+	cred := *gotCred.Credential
+	cred.CredentialStatus = *entry
+	set, err := statussdk.ValidateCredentialInStatusList(cred, *statusList)
+	if err != nil {
+		return unknownStatus(response, err, gotCred.LocalCredentialID)
+	}
+	switch entry.StatusPurpose {
+	case statussdk.StatusRevocation:
+		response.Revoked = set
+	case statussdk.StatusSuspension:
+		response.Suspended = set
+	default:
+		return unknownStatus(response, fmt.Errorf("unsupported status purpose: %s", entry.StatusPurpose), gotCred.LocalCredentialID)
+	}
+	return &response
+}
+
+func unknownStatus(response GetCredentialStatusResponse, err error, credID string) *GetCredentialStatusResponse {
+	logrus.WithError(err).Warnf("could not check the external status of credential<%s>", credID)
+	response.StatusUnknown = true
+	response.StatusUnknownReason = err.Error()
+	return &response
+}
+
+func toStatusList2021Entry(credentialStatus any) (*statussdk.StatusList2021Entry, error) {
+	statusBytes, err := json.Marshal(credentialStatus)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential status")
+	}
+	var entry statussdk.StatusList2021Entry
+	if err = json.Unmarshal(statusBytes, &entry); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential status")
+	}
+	if err = sdkutil.IsValidStruct(entry); err != nil {
+		return nil, errors.Wrap(err, "credential status is not a status list entry")
+	}
+	return &entry, nil
+}
+
+// errStatusListNotFound is returned when the URL of an external status list is not found.
+var errStatusListNotFound = errors.New("status list credential not found")
+
+// fetchStatusListCredential returns the status list credential at the URL, once its signature is verified against the
+// DID of the issuer. Verified status lists are cached for the configured TTL. Once it expires, a status list is still
+// returned for the configured maximum staleness, marked as stale, while it is refreshed in the background. Status
+// lists which are not found are not fetched again for the configured TTL of not found status lists.
+func (s Service) fetchStatusListCredential(ctx context.Context, url, issuer string) (*credential.VerifiableCredential, bool, error) {
+	if s.offline {
+		return nil, false, errors.Wrapf(verification.ErrOffline, "fetching status list credential from %s", url)
+	}
+	now := s.Clock.Now()
+	statusList, stale := s.externalStatusLists.get(url, now)
+	if statusList != nil {
+		if stale {
+			s.staleStatusLists.Add(ctx, 1)
+			if s.externalStatusLists.startRefresh(url) {
+				go s.refreshStatusListCredential(url, issuer)
+			}
+		}
+		return statusList, stale, nil
+	}
+	if s.externalStatusLists.isNotFound(url, now) {
+		return nil, false, errors.Wrapf(errStatusListNotFound, "fetching status list credential from %s, which was recently not found", url)
+	}
+
+	statusList, err := s.downloadStatusListCredential(ctx, url, issuer)
+	if err != nil {
+		return nil, false, err
+	}
+	return statusList, false, nil
+}
+
+// refreshStatusListCredential downloads a stale status list credential again, keeping the stale one when it fails.
+func (s Service) refreshStatusListCredential(url, issuer string) {
+	defer s.externalStatusLists.endRefresh(url)
+	ctx := context.Background()
+	if _, err := s.downloadStatusListCredential(ctx, url, issuer); err != nil {
+		logrus.WithError(err).Warnf("could not refresh stale status list credential from %s", url)
+		s.statusListRefreshFailures.Add(ctx, 1)
+		return
+	}
+	logrus.Debugf("refreshed stale status list credential from %s", url)
+}
+
+// downloadStatusListCredential fetches the status list credential at the URL and verifies it, caching it once
+// verified, or caching that it was not found.
+func (s Service) downloadStatusListCredential(ctx context.Context, url, issuer string) (*credential.VerifiableCredential, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "building request")
+	}
+	req.Header.Set("Accept", "application/jwt, application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching status list credential from %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		s.externalStatusLists.putNotFound(url, s.Clock.Now())
+		return nil, errors.Wrapf(errStatusListNotFound, "fetching status list credential from %s", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching status list credential from %s: unexpected status %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStatusListSize))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading status list credential from %s", url)
+	}
+
+	container, err := parseStatusListCredential(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing status list credential from %s", url)
+	}
+	if err = s.verifier.VerifyCredential(ctx, *container); err != nil {
+		return nil, errors.Wrapf(err, "verifying status list credential from %s", url)
+	}
+	if statusListIssuer, _ := container.Credential.Issuer.(string); statusListIssuer != issuer {
+		return nil, fmt.Errorf("status list credential from %s is issued by %v, not by the credential's issuer %s", url, container.Credential.Issuer, issuer)
+	}
+
+	s.externalStatusLists.put(url, container.Credential, s.Clock.Now())
+	return container.Credential, nil
+}
+
+// parseStatusListCredential accepts a credential JWT, a credential secured with data integrity, or a JSON object with
+// a `credentialJwt` property, such as the status lists served by the service.
+func parseStatusListCredential(body []byte) (*credint.Container, error) {
+	body = bytes.TrimSpace(body)
+	if !bytes.HasPrefix(body, []byte("{")) {
+		return credint.NewCredentialContainerFromJWT(string(body))
+	}
+
+	var wrapped struct {
+		CredentialJWT *keyaccess.JWT `json:"credentialJwt"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling status list credential")
+	}
+	if wrapped.CredentialJWT != nil {
+		return credint.NewCredentialContainerFromJWT(wrapped.CredentialJWT.String())
+	}
+
+	var cred credential.VerifiableCredential
+	if err := json.Unmarshal(body, &cred); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling status list credential")
+	}
+	if cred.Proof == nil {
+		return nil, errors.New("status list credential is not secured")
+	}
+	return &credint.Container{Credential: &cred}, nil
+}
+
+// statusListCache holds verified external status list credentials by URL until their TTL, and then their maximum
+// staleness, expire, along with the URLs of status lists which were not found until their own TTL expires.
+type statusListCache struct {
+	ttl          time.Duration
+	maxStaleness time.Duration
+	notFoundTTL  time.Duration
+
+	mu    sync.Mutex
+	lists map[string]cachedStatusList
+	// expiry of each URL which was not found
+	notFound map[string]time.Time
+	// URLs of the stale status lists being refreshed
+	refreshing map[string]bool
+}
+
+type cachedStatusList struct {
+	credential *credential.VerifiableCredential
+	expiresAt  time.Time
+}
+
+func newStatusListCache(ttl, maxStaleness, notFoundTTL time.Duration) *statusListCache {
+	return &statusListCache{
+		ttl:          ttl,
+		maxStaleness: maxStaleness,
+		notFoundTTL:  notFoundTTL,
+		lists:        make(map[string]cachedStatusList),
+		notFound:     make(map[string]time.Time),
+		refreshing:   make(map[string]bool),
+	}
+}
+
+// get returns the status list cached for the URL, and whether its TTL expired, or nil when there is none or its
+// maximum staleness expired too.
+func (c *statusListCache) get(url string, now time.Time) (*credential.VerifiableCredential, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.lists[url]
+	if !ok {
+		return nil, false
+	}
+	if !now.After(cached.expiresAt) {
+		return cached.credential, false
+	}
+	if !now.After(cached.expiresAt.Add(c.maxStaleness)) {
+		return cached.credential, true
+	}
+	delete(c.lists, url)
+	return nil, false
+}
+
+func (c *statusListCache) put(url string, statusList *credential.VerifiableCredential, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.notFound, url)
+	if c.ttl <= 0 {
+		return
+	}
+	c.lists[url] = cachedStatusList{credential: statusList, expiresAt: now.Add(c.ttl)}
+}
+
+// isNotFound returns whether the URL was not found within the TTL of not found status lists.
+func (c *statusListCache) isNotFound(url string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt, ok := c.notFound[url]
+	if !ok {
+		return false
+	}
+	if now.After(expiresAt) {
+		delete(c.notFound, url)
+		return false
+	}
+	return true
+}
+
+func (c *statusListCache) putNotFound(url string, now time.Time) {
+	if c.notFoundTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notFound[url] = now.Add(c.notFoundTTL)
+}
+
+// startRefresh returns whether the stale status list of the URL should be refreshed, which is when it is not being
+// refreshed already. Each started refresh must be ended with endRefresh.
+func (c *statusListCache) startRefresh(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshing[url] {
+		return false
+	}
+	c.refreshing[url] = true
+	return true
+}
+
+func (c *statusListCache) endRefresh(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.refreshing, url)
+}
+
+func newStaleStatusListsCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.external_status_list.stale_serves",
+		metric.WithDescription("Number of status checks of imported credentials against stale external status lists"),
+	)
+}
+
+func newStatusListRefreshFailuresCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.external_status_list.refresh_failures",
+		metric.WithDescription("Number of failed background refreshes of stale external status lists"),
+	)
+}
+
+func newExternalStatusClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport), Timeout: 10 * time.Second}
+}
@@ -0,0 +1,139 @@
+package credential
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrIssuerFrozen is returned when issuing credentials of a frozen issuer, or updating the status of its credentials.
+	ErrIssuerFrozen = errors.New("issuer is frozen")
+	// ErrIssuerNotFrozen is returned when unfreezing an issuer which is not frozen.
+	ErrIssuerNotFrozen = errors.New("issuer is not frozen")
+)
+
+// IssuerFreeze stops the issuance of an issuer's credentials, and changes to their status, until it is lifted. Unlike
+// revoking the keys of the issuer, it is undone without side effects, and does not affect verifying its credentials.
+type IssuerFreeze struct {
+	Issuer   string `json:"issuer"`
+	Reason   string `json:"reason,omitempty"`
+	FrozenAt string `json:"frozenAt"`
+}
+
+type FreezeIssuerRequest struct {
+	Issuer string `json:"issuer" validate:"required"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type UnfreezeIssuerRequest struct {
+	Issuer string `json:"issuer" validate:"required"`
+}
+
+type ListIssuerFreezesResponse struct {
+	Freezes []IssuerFreeze `json:"freezes"`
+}
+
+// FreezeIssuer freezes an issuer. Freezing a frozen issuer replaces the reason and time of its freeze.
+func (s Service) FreezeIssuer(ctx context.Context, request FreezeIssuerRequest) (*IssuerFreeze, error) {
+	if request.Issuer == "" {
+		return nil, sdkutil.LoggingNewError("issuer is required to freeze it")
+	}
+	freeze := IssuerFreeze{
+		Issuer:   request.Issuer,
+		Reason:   request.Reason,
+		FrozenAt: s.Clock.Now().UTC().Format(time.RFC3339),
+	}
+	if err := s.storage.storeIssuerFreeze(ctx, freeze); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "freezing issuer<%s>", request.Issuer)
+	}
+	logrus.Warnf("issuer<%s> is frozen: %s", freeze.Issuer, freeze.Reason)
+	return &freeze, nil
+}
+
+// UnfreezeIssuer lifts the freeze of an issuer, returning it.
+func (s Service) UnfreezeIssuer(ctx context.Context, request UnfreezeIssuerRequest) (*IssuerFreeze, error) {
+	freeze, err := s.storage.getIssuerFreeze(ctx, request.Issuer)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting freeze of issuer<%s>", request.Issuer)
+	}
+	if freeze == nil {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrIssuerNotFrozen, "issuer<%s>", request.Issuer))
+	}
+	if err = s.storage.deleteIssuerFreeze(ctx, request.Issuer); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "unfreezing issuer<%s>", request.Issuer)
+	}
+	logrus.Warnf("issuer<%s> is unfrozen", freeze.Issuer)
+	return freeze, nil
+}
+
+// ListIssuerFreezes lists the freezes of the frozen issuers, sorted by issuer.
+func (s Service) ListIssuerFreezes(ctx context.Context) (*ListIssuerFreezesResponse, error) {
+	freezes, err := s.storage.listIssuerFreezes(ctx)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing issuer freezes")
+	}
+	sort.Slice(freezes, func(i, j int) bool { return freezes[i].Issuer < freezes[j].Issuer })
+	return &ListIssuerFreezesResponse{Freezes: freezes}, nil
+}
+
+// checkIssuerNotFrozen returns ErrIssuerFrozen, along with the reason of the freeze, when the issuer is frozen.
+func (s Service) checkIssuerNotFrozen(ctx context.Context, issuer string) error {
+	freeze, err := s.storage.getIssuerFreeze(ctx, issuer)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "getting freeze of issuer<%s>", issuer)
+	}
+	if freeze == nil {
+		return nil
+	}
+	return sdkutil.LoggingError(errors.Wrapf(ErrIssuerFrozen, "issuer<%s> was frozen at %s with reason: %q", freeze.Issuer, freeze.FrozenAt, freeze.Reason))
+}
+
+func (cs *Storage) storeIssuerFreeze(ctx context.Context, freeze IssuerFreeze) error {
+	freezeBytes, err := json.Marshal(freeze)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling freeze of issuer<%s>", freeze.Issuer)
+	}
+	return cs.db.Write(ctx, issuerFreezeNamespace, freeze.Issuer, freezeBytes)
+}
+
+// getIssuerFreeze returns the freeze of the issuer, or nil when it is not frozen.
+func (cs *Storage) getIssuerFreeze(ctx context.Context, issuer string) (*IssuerFreeze, error) {
+	freezeBytes, err := cs.db.Read(ctx, issuerFreezeNamespace, issuer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading freeze of issuer<%s>", issuer)
+	}
+	if len(freezeBytes) == 0 {
+		return nil, nil
+	}
+	var freeze IssuerFreeze
+	if err = json.Unmarshal(freezeBytes, &freeze); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling freeze of issuer<%s>", issuer)
+	}
+	return &freeze, nil
+}
+
+func (cs *Storage) deleteIssuerFreeze(ctx context.Context, issuer string) error {
+	return cs.db.Delete(ctx, issuerFreezeNamespace, issuer)
+}
+
+func (cs *Storage) listIssuerFreezes(ctx context.Context) ([]IssuerFreeze, error) {
+	freezesBytes, err := cs.db.ReadAll(ctx, issuerFreezeNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading issuer freezes")
+	}
+	freezes := make([]IssuerFreeze, 0, len(freezesBytes))
+	for issuer, freezeBytes := range freezesBytes {
+		var freeze IssuerFreeze
+		if err = json.Unmarshal(freezeBytes, &freeze); err != nil {
+			return nil, errors.Wrapf(err, "unmarshalling freeze of issuer<%s>", issuer)
+		}
+		freezes = append(freezes, freeze)
+	}
+	return freezes, nil
+}
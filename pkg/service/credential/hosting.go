@@ -0,0 +1,192 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+)
+
+const (
+	// HostingUnreachable is the reason of a status list credential which could not be fetched from its public URI.
+	HostingUnreachable = "UNREACHABLE"
+	// HostingStale is the reason of a status list credential whose public URI serves another credential than the one
+	// stored, such as a copy from before its last update.
+	HostingStale = "STALE"
+)
+
+// StatusListHostingMismatch is a status list credential which is not served as stored from its public URI.
+type StatusListHostingMismatch struct {
+	ID  string `json:"id"`
+	URI string `json:"uri"`
+	// Reason is one of HostingUnreachable or HostingStale.
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// StatusListHostingHealth is the outcome of the last status list hosting check, reported in the details of the status
+// of the service.
+type StatusListHostingHealth struct {
+	CheckedAt  string                      `json:"checkedAt"`
+	Mismatches []StatusListHostingMismatch `json:"mismatches,omitempty"`
+}
+
+// StatusListHostingMismatchFunc is called with each mismatch found by RunStatusListHostingCheck.
+type StatusListHostingMismatchFunc func(ctx context.Context, mismatch StatusListHostingMismatch)
+
+// statusListHosting holds the outcome of the last status list hosting check.
+type statusListHosting struct {
+	mu     sync.Mutex
+	health *StatusListHostingHealth
+}
+
+func (h *statusListHosting) get() *StatusListHostingHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.health
+}
+
+func (h *statusListHosting) set(health StatusListHostingHealth) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.health = &health
+}
+
+// RunStatusListHostingCheck checks the status list credentials are served as stored right away, and then every hosting
+// check interval, until the context is done. It returns immediately when the hosting check is disabled, or when
+// verification is offline.
+func (s Service) RunStatusListHostingCheck(ctx context.Context, onMismatch StatusListHostingMismatchFunc) {
+	if !s.config.StatusListHostingCheck || s.offline {
+		return
+	}
+	check := func() {
+		mismatches, err := s.CheckStatusListHosting(ctx)
+		if err != nil {
+			logrus.WithError(err).Error("checking status list hosting")
+			return
+		}
+		if onMismatch == nil {
+			return
+		}
+		for _, mismatch := range mismatches {
+			onMismatch(ctx, mismatch)
+		}
+	}
+	check()
+	if s.hostingCheckInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.hostingCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// CheckStatusListHosting fetches each status list credential from its public URI, at the configured rate, and returns
+// those which could not be fetched, or which are not served as stored. The outcome is counted, and reported in the
+// details of the status of the service until the next check.
+func (s Service) CheckStatusListHosting(ctx context.Context) ([]StatusListHostingMismatch, error) {
+	watchKeys, err := s.storage.ListStatusListCredentialWatchKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing status list credentials")
+	}
+
+	var limiter <-chan time.Time
+	if s.config.StatusListHostingCheckRate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(s.config.StatusListHostingCheckRate))
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+	var mismatches []StatusListHostingMismatch
+	for _, watchKey := range watchKeys {
+		gotStatusList, err := s.storage.GetStatusListCredentialByWatchKey(ctx, watchKey)
+		if err != nil {
+			return nil, err
+		}
+		if gotStatusList == nil || gotStatusList.Credential == nil || gotStatusList.CredentialJWT == nil {
+			continue
+		}
+		if limiter != nil {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-limiter:
+			}
+		}
+
+		mismatch := s.checkStatusListHosted(ctx, *gotStatusList)
+		outcome := "served"
+		if mismatch != nil {
+			logrus.Warnf("status list credential<%s> is not served as stored from %s: %s", mismatch.ID, mismatch.URI, mismatch.Message)
+			outcome = mismatch.Reason
+			mismatches = append(mismatches, *mismatch)
+		}
+		s.hostingChecks.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+	}
+
+	s.hosting.set(StatusListHostingHealth{CheckedAt: s.Clock.Now().UTC().Format(time.RFC3339), Mismatches: mismatches})
+	return mismatches, nil
+}
+
+// checkStatusListHosted returns the mismatch of a status list credential whose public URI does not serve it as stored,
+// or nil when it does.
+func (s Service) checkStatusListHosted(ctx context.Context, statusList StoredCredential) *StatusListHostingMismatch {
+	uri := statusList.Credential.ID
+	mismatch := func(reason, message string) *StatusListHostingMismatch {
+		return &StatusListHostingMismatch{ID: statusList.LocalCredentialID, URI: uri, Reason: reason, Message: message}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return mismatch(HostingUnreachable, err.Error())
+	}
+	req.Header.Set("Accept", "application/jwt, application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return mismatch(HostingUnreachable, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return mismatch(HostingUnreachable, fmt.Sprintf("unexpected status %d", resp.StatusCode))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxStatusListSize))
+	if err != nil {
+		return mismatch(HostingUnreachable, err.Error())
+	}
+
+	served, err := parseStatusListCredential(body)
+	if err != nil {
+		return mismatch(HostingStale, fmt.Sprintf("the served status list credential could not be parsed: %s", err))
+	}
+	if served.CredentialJWT == nil || served.CredentialJWT.String() != statusList.CredentialJWT.String() {
+		issued := ""
+		if served.Credential != nil {
+			issued = served.Credential.IssuanceDate
+		}
+		return mismatch(HostingStale, fmt.Sprintf("the served status list credential issued at %q is not the stored one issued at %q", issued, statusList.Credential.IssuanceDate))
+	}
+	return nil
+}
+
+func newHostingChecksCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.status_list.hosting_checks",
+		metric.WithDescription("Number of status list credentials fetched from their public URI by the hosting check, by outcome"),
+	)
+}
@@ -0,0 +1,43 @@
+package credential
+
+import (
+	"reflect"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// ErrInjectedClaimConflict is returned when a request for a credential has a claim its schema injects, with another
+// value, and the schema rejects such conflicts.
+var ErrInjectedClaimConflict = errors.New("claim conflicts with a claim injected by the schema")
+
+// injectClaims merges the claims injected by the schema the credential is requested against, if any, into the data of
+// the request, resolving the claims the request also has with the conflict policy of the schema. The data of the
+// request is copied, so that the caller's is left unchanged.
+func injectClaims(request CreateCredentialRequest, injection *schema.ClaimInjection) (CreateCredentialRequest, error) {
+	if injection == nil || len(injection.Claims) == 0 {
+		return request, nil
+	}
+	data := make(map[string]any, len(request.Data)+len(injection.Claims))
+	for claim, value := range request.Data {
+		data[claim] = value
+	}
+	for claim, injected := range injection.Claims {
+		requested, ok := data[claim]
+		if !ok || reflect.DeepEqual(requested, injected) {
+			data[claim] = injected
+			continue
+		}
+		switch injection.ConflictPolicy {
+		case schema.ClientWinsClaimConflict:
+		case schema.ServerWinsClaimConflict:
+			data[claim] = injected
+		default:
+			return request, sdkutil.LoggingError(errors.Wrapf(ErrInjectedClaimConflict, "schema<%s> injects claim<%s> with another value than requested", request.SchemaID, claim))
+		}
+	}
+	request.Data = data
+	return request, nil
+}
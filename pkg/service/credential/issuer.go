@@ -0,0 +1,79 @@
+package credential
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+// DisplayNames looks up the display names of DIDs managed by the service, keyed by DID. DIDs without a display name
+// are left out.
+type DisplayNames interface {
+	GetDisplayNames(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+// SetDisplayNames sets where the display names of issuers are looked up. It must be set before the service expands
+// issuers.
+func (s *Service) SetDisplayNames(displayNames DisplayNames) {
+	s.displayNames = displayNames
+}
+
+// ExpandIssuers sets the display name of the issuer of each container, looking up the display names of all distinct
+// issuers at once. Containers whose issuer has no display name, such as issuers not managed by the service, are left
+// unchanged.
+func (s Service) ExpandIssuers(ctx context.Context, containers []credint.Container) error {
+	issuers := make([]string, 0, len(containers))
+	for _, container := range containers {
+		if container.Credential != nil {
+			issuers = append(issuers, container.Credential.IssuerID())
+		}
+	}
+	displayNames, err := s.issuerDisplayNames(ctx, issuers)
+	if err != nil {
+		return err
+	}
+	for i, container := range containers {
+		if container.Credential != nil {
+			containers[i].IssuerDisplayName = displayNames[container.Credential.IssuerID()]
+		}
+	}
+	return nil
+}
+
+// ExpandMetadataIssuers is like ExpandIssuers, for the metadata of credentials.
+func (s Service) ExpandMetadataIssuers(ctx context.Context, metadata []CredentialMetadata) error {
+	issuers := make([]string, 0, len(metadata))
+	for _, m := range metadata {
+		issuers = append(issuers, m.Issuer)
+	}
+	displayNames, err := s.issuerDisplayNames(ctx, issuers)
+	if err != nil {
+		return err
+	}
+	for i, m := range metadata {
+		metadata[i].IssuerDisplayName = displayNames[m.Issuer]
+	}
+	return nil
+}
+
+// issuerDisplayNames looks up the display names of the distinct issuers at once.
+func (s Service) issuerDisplayNames(ctx context.Context, issuers []string) (map[string]string, error) {
+	if s.displayNames == nil || len(issuers) == 0 {
+		return nil, nil
+	}
+	distinct := make([]string, 0, len(issuers))
+	seen := make(map[string]bool, len(issuers))
+	for _, issuer := range issuers {
+		if !seen[issuer] {
+			seen[issuer] = true
+			distinct = append(distinct, issuer)
+		}
+	}
+	displayNames, err := s.displayNames.GetDisplayNames(ctx, distinct)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not get display names of issuers")
+	}
+	return displayNames, nil
+}
@@ -0,0 +1,92 @@
+package credential
+
+import (
+	"context"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+)
+
+// KeyStatus reports whether the key a credential was issued with is still trusted by its issuer.
+type KeyStatus struct {
+	// Fully qualified ID of the verification method the credential was issued with.
+	VerificationMethodID string `json:"verificationMethodId"`
+
+	// Whether the verification method appears in the issuer's resolved DID document.
+	InDIDDocument bool `json:"inDidDocument"`
+
+	// Why the issuer's DID document could not be resolved, in which case InDIDDocument is false.
+	ResolutionError string `json:"resolutionError,omitempty"`
+
+	// Whether the key is held by the service. Only the DID document is checked for keys which are not.
+	Managed bool `json:"managed"`
+
+	// Whether the service revoked the key, which is how keys are rotated out.
+	Revoked   bool   `json:"revoked"`
+	RevokedAt string `json:"revokedAt,omitempty"`
+
+	// Valid is true when the verification method is in the DID document, and the key is not revoked.
+	Valid bool `json:"valid"`
+
+	// When the issuer's DID document was resolved, in RFC3339 format.
+	ResolvedAt string `json:"resolvedAt"`
+}
+
+// GetKeyStatus resolves the DID document of the issuer of the given credential, and looks up the key the credential
+// was issued with, to report whether the key is still valid. For credentials whose verification method was not
+// recorded, such as some imported credentials, the key ID of the header of the JWT is used.
+func (s Service) GetKeyStatus(ctx context.Context, container credint.Container) (*KeyStatus, error) {
+	if container.Credential == nil {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> has no credential to get the key status of", container.ID)
+	}
+	issuer := container.Credential.IssuerID()
+	verificationMethodID := container.FullyQualifiedVerificationMethodID
+	if verificationMethodID == "" && container.HasJWTCredential() {
+		headers, _, _, err := integrity.ParseVerifiableCredentialFromJWT(container.CredentialJWT.String())
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "parsing jwt of credential<%s>", container.ID)
+		}
+		verificationMethodID = headers.KeyID()
+	}
+	if verificationMethodID == "" {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> has no verification method", container.ID)
+	}
+
+	status := KeyStatus{
+		VerificationMethodID: did.FullyQualifiedVerificationMethodID(issuer, verificationMethodID),
+		ResolvedAt:           time.Now().UTC().Format(time.RFC3339),
+	}
+	resolved, err := s.didResolver.Resolve(ctx, issuer)
+	if err != nil {
+		status.ResolutionError = errors.Wrapf(err, "resolving issuer<%s>", issuer).Error()
+	} else {
+		for _, vm := range resolved.Document.VerificationMethod {
+			if did.FullyQualifiedVerificationMethodID(issuer, vm.ID) == status.VerificationMethodID {
+				status.InDIDDocument = true
+				break
+			}
+		}
+	}
+
+	managed, err := s.keyStore.KeyExists(ctx, status.VerificationMethodID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "checking whether key<%s> is managed", status.VerificationMethodID)
+	}
+	if managed {
+		gotKey, err := s.keyStore.GetKey(ctx, keystore.GetKeyRequest{ID: status.VerificationMethodID})
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "getting key<%s>", status.VerificationMethodID)
+		}
+		status.Managed = true
+		status.Revoked = gotKey.Revoked
+		status.RevokedAt = gotKey.RevokedAt
+	}
+	status.Valid = status.InDIDDocument && !status.Revoked
+	return &status, nil
+}
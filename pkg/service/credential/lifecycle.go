@@ -0,0 +1,78 @@
+package credential
+
+import (
+	"context"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// lifecycleState returns the lifecycle state of a credential, in which being revoked takes precedence over being
+// suspended, which takes precedence over being expired.
+func lifecycleState(revoked, suspended, expired bool) credint.LifecycleState {
+	switch {
+	case revoked:
+		return credint.LifecycleRevoked
+	case suspended:
+		return credint.LifecycleSuspended
+	case expired:
+		return credint.LifecycleExpired
+	default:
+		return credint.LifecycleActive
+	}
+}
+
+// expiryCutoff is the time credentials which expired before are EXPIRED, which is the time of the service, less the
+// configured skew.
+func (s Service) expiryCutoff() time.Time {
+	return s.Clock.Now().Add(-s.expirySkew)
+}
+
+// lifecycleStateOf returns the lifecycle state of a stored credential at the current time of the service.
+func (s Service) lifecycleStateOf(revoked, suspended bool, expirationDate string) credint.LifecycleState {
+	return lifecycleState(revoked, suspended, isExpiredAt(expirationDate, s.expiryCutoff()))
+}
+
+// lifecycleFilterVars adds the variables which depend on the time a credential is filtered at to its filter variables:
+// `expired`, and `state`, its lifecycle state.
+type lifecycleFilterVars struct {
+	vars           storage.FilterVarsMapper
+	revoked        bool
+	suspended      bool
+	expirationDate string
+	expiryCutoff   time.Time
+}
+
+func (v lifecycleFilterVars) FilterVariablesMap() map[string]any {
+	vars := v.vars.FilterVariablesMap()
+	expired := isExpiredAt(v.expirationDate, v.expiryCutoff)
+	vars["expired"] = expired
+	vars["state"] = string(lifecycleState(v.revoked, v.suspended, expired))
+	return vars
+}
+
+// WasCredentialDeleted returns whether the event log records the deletion of the credential with the given ID. It is
+// always false when the event log is not enabled.
+func (cs *Storage) WasCredentialDeleted(ctx context.Context, id string) (bool, error) {
+	gotEvents, err := cs.db.ReadAll(ctx, credentialEventNamespace)
+	if err != nil {
+		return false, errors.Wrap(err, "reading credential events")
+	}
+	for key, eventBytes := range gotEvents {
+		var event Event
+		if err = json.Unmarshal(eventBytes, &event); err != nil {
+			logrus.WithError(err).Warnf("skipping credential event<%s>", key)
+			continue
+		}
+		if event.Verb == webhook.Delete && event.CredentialID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,428 @@
+package credential
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// MessageStatusPurpose is the status purpose of status lists whose entries hold one of several status values, each
+// explained by a message, such as "pending" or "under review", rather than a single revoked or suspended bit. It
+// follows the `statusSize` and `statusMessage` properties of the Bitstring Status List.
+const MessageStatusPurpose statussdk.StatusPurpose = "message"
+
+// StatusMessage is one of the values an entry of a message status list can hold, and what it means.
+type StatusMessage struct {
+	// Hexadecimal status value, e.g. `0x1`.
+	Status  string `json:"status" validate:"required"`
+	Message string `json:"message" validate:"required"`
+}
+
+// MessageStatus describes the message status list of a credential. The list is created with the first credential of
+// the issuer and schema added to it, and later credentials must describe it the same way.
+type MessageStatus struct {
+	// Number of bits of each entry of the list.
+	Size int `json:"size" validate:"required,min=1,max=8"`
+	// The message of each value an entry can hold, of which there must be 2^size.
+	Messages []StatusMessage `json:"messages" validate:"required,dive"`
+}
+
+func (ms MessageStatus) IsValid() error {
+	if err := sdkutil.IsValidStruct(ms); err != nil {
+		return err
+	}
+	if len(ms.Messages) != 1<<ms.Size {
+		return fmt.Errorf("a status size of %d requires %d status messages, but %d were given", ms.Size, 1<<ms.Size, len(ms.Messages))
+	}
+	seen := make(map[uint64]bool, len(ms.Messages))
+	for _, message := range ms.Messages {
+		value, err := parseStatusValue(message.Status)
+		if err != nil {
+			return err
+		}
+		if value >= 1<<ms.Size {
+			return fmt.Errorf("status<%s> does not fit in a status size of %d", message.Status, ms.Size)
+		}
+		if seen[value] {
+			return fmt.Errorf("status<%s> has more than one message", message.Status)
+		}
+		seen[value] = true
+	}
+	return nil
+}
+
+// equal returns whether both describe the same status values with the same messages.
+func (ms MessageStatus) equal(other MessageStatus) bool {
+	if ms.Size != other.Size || len(ms.Messages) != len(other.Messages) {
+		return false
+	}
+	for _, message := range other.Messages {
+		value, err := parseStatusValue(message.Status)
+		if err != nil {
+			return false
+		}
+		if got, ok := ms.message(value); !ok || got != message.Message {
+			return false
+		}
+	}
+	return true
+}
+
+// message returns the message of a status value.
+func (ms MessageStatus) message(value uint64) (string, bool) {
+	for _, message := range ms.Messages {
+		if got, err := parseStatusValue(message.Status); err == nil && got == value {
+			return message.Message, true
+		}
+	}
+	return "", false
+}
+
+// resolve returns the status value, and its message, given either a status value or a message of the list.
+func (ms MessageStatus) resolve(statusValue string) (uint64, string, error) {
+	if value, err := parseStatusValue(statusValue); err == nil {
+		if message, ok := ms.message(value); ok {
+			return value, message, nil
+		}
+	}
+	for _, message := range ms.Messages {
+		if message.Message == statusValue {
+			value, err := parseStatusValue(message.Status)
+			return value, message.Message, err
+		}
+	}
+	return 0, "", fmt.Errorf("status value<%s> is not one of the status list's messages", statusValue)
+}
+
+func parseStatusValue(status string) (uint64, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(status), "0x"), 16, 8)
+	if err != nil || !strings.HasPrefix(strings.ToLower(status), "0x") {
+		return 0, fmt.Errorf("status<%s> is not a hexadecimal value such as 0x1", status)
+	}
+	return value, nil
+}
+
+func formatStatusValue(value uint64) string {
+	return fmt.Sprintf("0x%x", value)
+}
+
+// messageStatusEntry is the credentialStatus of a credential in a message status list.
+type messageStatusEntry struct {
+	statussdk.StatusList2021Entry
+	StatusSize    int             `json:"statusSize"`
+	StatusMessage []StatusMessage `json:"statusMessage"`
+}
+
+func (e messageStatusEntry) messageStatus() MessageStatus {
+	return MessageStatus{Size: e.StatusSize, Messages: e.StatusMessage}
+}
+
+// toMessageStatusEntry returns the credentialStatus of a credential if it is an entry of a message status list.
+func toMessageStatusEntry(credentialStatus any) (*messageStatusEntry, bool) {
+	if credentialStatus == nil {
+		return nil, false
+	}
+	statusBytes, err := json.Marshal(credentialStatus)
+	if err != nil {
+		return nil, false
+	}
+	var entry messageStatusEntry
+	if err = json.Unmarshal(statusBytes, &entry); err != nil || entry.StatusPurpose != MessageStatusPurpose {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// messageStatusList is the credential subject of a message status list credential.
+type messageStatusList struct {
+	ID            string                  `json:"id"`
+	Type          string                  `json:"type"`
+	StatusPurpose statussdk.StatusPurpose `json:"statusPurpose"`
+	// GZIP compressed bitstring, base64url encoded with the `u` multibase prefix.
+	EncodedList   string          `json:"encodedList"`
+	StatusSize    int             `json:"statusSize"`
+	StatusMessage []StatusMessage `json:"statusMessage"`
+}
+
+func toMessageStatusList(statusListCredential credential.VerifiableCredential) (*messageStatusList, error) {
+	subjectBytes, err := json.Marshal(statusListCredential.CredentialSubject)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshalling subject of status list credential<%s>", statusListCredential.ID)
+	}
+	var statusList messageStatusList
+	if err = json.Unmarshal(subjectBytes, &statusList); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling subject of status list credential<%s>", statusListCredential.ID)
+	}
+	if statusList.StatusPurpose != MessageStatusPurpose {
+		return nil, fmt.Errorf("status list credential<%s> has purpose<%s> rather than %s", statusListCredential.ID, statusList.StatusPurpose, MessageStatusPurpose)
+	}
+	return &statusList, nil
+}
+
+// generateMessageStatusListCredential generates a message status list credential, in which the entry at each index of
+// values holds its status value.
+func generateMessageStatusListCredential(id, issuer string, status MessageStatus, values map[int]uint64) (*credential.VerifiableCredential, error) {
+	encodedList, err := encodeMessageStatusList(status.Size, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding message status list")
+	}
+	statusListJSON, err := sdkutil.ToJSONMap(messageStatusList{
+		ID:            id,
+		Type:          statussdk.StatusList2021Type,
+		StatusPurpose: MessageStatusPurpose,
+		EncodedList:   encodedList,
+		StatusSize:    status.Size,
+		StatusMessage: status.Messages,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not turn message status list to JSON")
+	}
+
+	builder := credential.NewVerifiableCredentialBuilder()
+	if err = builder.SetID(id); err != nil {
+		return nil, errors.Wrap(err, "setting status list credential id")
+	}
+	if err = builder.SetIssuer(issuer); err != nil {
+		return nil, errors.Wrap(err, "setting status list credential issuer")
+	}
+	if err = builder.AddContext(statussdk.StatusList2021Context); err != nil {
+		return nil, errors.Wrap(err, "setting status list credential context")
+	}
+	if err = builder.AddType(statussdk.StatusList2021CreddentialType); err != nil {
+		return nil, errors.Wrap(err, "setting status list credential type")
+	}
+	if err = builder.SetCredentialSubject(statusListJSON); err != nil {
+		return nil, errors.Wrap(err, "setting status list credential subject")
+	}
+	return builder.Build()
+}
+
+// encodeMessageStatusList sets the bits of the entry at each index to its value, most significant bit first, then
+// compresses and encodes the bitstring.
+func encodeMessageStatusList(size int, values map[int]uint64) (string, error) {
+	bitstring := make([]byte, bitStringLength/8)
+	for index, value := range values {
+		for bit := 0; bit < size; bit++ {
+			if value&(1<<(size-1-bit)) == 0 {
+				continue
+			}
+			position := index*size + bit
+			if position >= bitStringLength {
+				return "", fmt.Errorf("status list index<%d> is out of range", index)
+			}
+			bitstring[position/8] |= 1 << (7 - position%8)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(bitstring); err != nil {
+		return "", errors.Wrap(err, "compressing status list bitstring")
+	}
+	if err := zw.Close(); err != nil {
+		return "", errors.Wrap(err, "closing gzip writer")
+	}
+	return "u" + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeMessageStatusValue returns the value of the entry at an index of an encoded message status list.
+func decodeMessageStatusValue(encodedList string, size, index int) (uint64, error) {
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(encodedList, "u"))
+	if err != nil {
+		return 0, errors.Wrap(err, "decoding status list bitstring")
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return 0, errors.Wrap(err, "decompressing status list bitstring")
+	}
+	bitstring, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, errors.Wrap(err, "decompressing status list bitstring")
+	}
+
+	var value uint64
+	for bit := 0; bit < size; bit++ {
+		position := index*size + bit
+		if position/8 >= len(bitstring) {
+			return 0, fmt.Errorf("status list index<%d> is out of range", index)
+		}
+		value <<= 1
+		if bitstring[position/8]&(1<<(7-position%8)) != 0 {
+			value |= 1
+		}
+	}
+	return value, nil
+}
+
+// updateMessageStatus sets the status value of a credential in a message status list, and regenerates the list.
+func (s Service) updateMessageStatus(ctx context.Context, tx storage.Tx, gotCred *StoredCredential, request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata) (*UpdateCredentialStatusResponse, error) {
+	if request.Revoked || request.Suspended {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> uses a message status list, so only its status value can be updated", request.ID)
+	}
+	entry, ok := toMessageStatusEntry(gotCred.Credential.CredentialStatus)
+	if !ok {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> does not have a message status entry", request.ID)
+	}
+	messageStatus := entry.messageStatus()
+	value, message, err := messageStatus.resolve(request.StatusValue)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "resolving status value of credential<%s>", request.ID)
+	}
+	statusValue := formatStatusValue(value)
+	response := UpdateCredentialStatusResponse{Status: Status{StatusValue: statusValue, StatusMessage: message, Reason: request.Reason, ReasonCode: request.ReasonCode}}
+	if gotCred.StatusValue == statusValue {
+		logrus.Warn("request and credential have same status, no action is needed")
+		response.Reason, response.ReasonCode = gotCred.StatusReason, gotCred.StatusReasonCode
+		return &response, nil
+	}
+
+	container := credint.Container{
+		ID:                                 gotCred.LocalCredentialID,
+		FullyQualifiedVerificationMethodID: gotCred.FullyQualifiedVerificationMethodID,
+		Credential:                         gotCred.Credential,
+		CredentialJWT:                      gotCred.CredentialJWT,
+		StatusValue:                        statusValue,
+		StatusReason:                       request.Reason,
+		StatusReasonCode:                   request.ReasonCode,
+		RenderMethod:                       gotCred.RenderMethod,
+		MissingClaims:                      gotCred.MissingClaims,
+		Receipt:                            gotCred.Receipt,
+		SchemaVersion:                      gotCred.SchemaVersion,
+		AutoRenew:                          gotCred.AutoRenew,
+		PreviousCredential:                 gotCred.PreviousCredential,
+		CreatedBy:                          gotCred.CreatedBy,
+	}
+	if err = s.storage.StoreCredentialTx(ctx, tx, StoreCredentialRequest{Container: container}); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential")
+	}
+
+	statusListCredentialID, err := parseIDFromURI(entry.StatusListCredential)
+	if err != nil {
+		return nil, err
+	}
+	// the credential is set based on the request, since the transaction's write is not visible to reads
+	values := make(map[int]uint64)
+	visit := func(cred StoredCredential) error {
+		credEntry, ok := toMessageStatusEntry(cred.Credential.CredentialStatus)
+		if !ok || credEntry.StatusListCredential != entry.StatusListCredential || cred.LocalCredentialID == gotCred.LocalCredentialID {
+			return nil
+		}
+		if err := setMessageStatusValue(values, *credEntry, cred.StatusValue); err != nil {
+			return errors.Wrapf(err, "getting status of credential<%s>", cred.LocalCredentialID)
+		}
+		return nil
+	}
+	if err = s.forEachSetStatusListCredential(ctx, statusListCredentialID, gotCred.Issuer, gotCred.Schema, visit); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting credentials of status list<%s>", statusListCredentialID)
+	}
+	if err = setMessageStatusValue(values, *entry, statusValue); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting status of credential<%s>", gotCred.LocalCredentialID)
+	}
+
+	generatedStatusListCredential, err := generateMessageStatusListCredential(entry.StatusListCredential, gotCred.Issuer, messageStatus, values)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not generate status list")
+	}
+	generatedStatusListCredential.CredentialSchema = gotCred.Credential.CredentialSchema
+	statusListCredJWT, err := s.signCredentialJWT(ctx, gotCred.FullyQualifiedVerificationMethodID, *generatedStatusListCredential, s.jwtClaimsPolicy, nil)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
+	}
+	statusListContainer := credint.Container{
+		ID:                                 statusListCredentialID,
+		FullyQualifiedVerificationMethodID: gotCred.FullyQualifiedVerificationMethodID,
+		Credential:                         generatedStatusListCredential,
+		CredentialJWT:                      statusListCredJWT,
+	}
+	if err = s.storage.StoreStatusListCredentialTx(ctx, tx, StoreCredentialRequest{Container: statusListContainer}, slcMetadata); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential status list")
+	}
+
+	response.statusList = &statusListContainer
+	return &response, nil
+}
+
+func setMessageStatusValue(values map[int]uint64, entry messageStatusEntry, statusValue string) error {
+	if statusValue == "" {
+		return nil
+	}
+	index, err := strconv.Atoi(entry.StatusListIndex)
+	if err != nil {
+		return errors.Wrapf(err, "parsing status list index<%s>", entry.StatusListIndex)
+	}
+	value, err := parseStatusValue(statusValue)
+	if err != nil {
+		return err
+	}
+	values[index] = value
+	return nil
+}
+
+// messageStatusOf returns the current status value and message of a credential in a message status list, as held by
+// the status list credential stored by the service. The first message status entry of the credential is used. Empty values are returned for other credentials, and for status
+// lists not stored by the service.
+func (s Service) messageStatusOf(ctx context.Context, cred credential.VerifiableCredential) (string, string, error) {
+	var entry *messageStatusEntry
+	for _, status := range statusEntries(cred.CredentialStatus) {
+		if messageEntry, ok := toMessageStatusEntry(status); ok {
+			entry = messageEntry
+			break
+		}
+	}
+	if entry == nil {
+		return "", "", nil
+	}
+	statusListCredentialID, err := parseIDFromURI(entry.StatusListCredential)
+	if err != nil {
+		return "", "", nil
+	}
+	gotStatusList, err := s.storage.GetStatusListCredential(ctx, statusListCredentialID)
+	if err != nil || gotStatusList == nil || gotStatusList.Credential == nil || gotStatusList.Credential.ID != entry.StatusListCredential {
+		logrus.Debugf("status list credential<%s> is not stored by the service", entry.StatusListCredential)
+		return "", "", nil
+	}
+	statusList, err := toMessageStatusList(*gotStatusList.Credential)
+	if err != nil {
+		return "", "", err
+	}
+	index, err := strconv.Atoi(entry.StatusListIndex)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "parsing status list index<%s>", entry.StatusListIndex)
+	}
+	value, err := decodeMessageStatusValue(statusList.EncodedList, statusList.StatusSize, index)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "decoding status list credential<%s>", entry.StatusListCredential)
+	}
+	message, _ := MessageStatus{Size: statusList.StatusSize, Messages: statusList.StatusMessage}.message(value)
+	return formatStatusValue(value), message, nil
+}
+
+// checkMessageStatusList makes sure an existing message status list holds the status values and messages requested
+// for a credential added to it.
+func checkMessageStatusList(statusListCredential credential.VerifiableCredential, requested MessageStatus) error {
+	statusList, err := toMessageStatusList(statusListCredential)
+	if err != nil {
+		return err
+	}
+	existing := MessageStatus{Size: statusList.StatusSize, Messages: statusList.StatusMessage}
+	if !existing.equal(requested) {
+		return sdkutil.LoggingNewErrorf("message status list<%s> has a status size of %d and messages %v, which differ from those requested",
+			statusListCredential.ID, existing.Size, existing.Messages)
+	}
+	return nil
+}
@@ -2,18 +2,54 @@ package credential
 
 import (
 	"fmt"
+	"strings"
 
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
 	"github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
 	"github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	schemaint "github.com/tbd54566975/ssi-service/internal/schema"
 	"github.com/tbd54566975/ssi-service/pkg/service/common"
 )
 
 type BatchCreateCredentialsRequest struct {
 	Requests []CreateCredentialRequest
+	// ContinueOnError keeps creating the chunks of the batch after a chunk fails.
+	ContinueOnError bool
 }
 
 type BatchCreateCredentialsResponse struct {
+	// Credentials in the same order as the requests. The credentials of requests which failed are empty.
 	Credentials []credential.Container
+	// Warnings of each credential, in the same order as Credentials.
+	Warnings [][]string
+	// IDs of the credentials revoked and replaced by each credential, in the same order as Credentials.
+	ReplacedCredentialIDs [][]string
+	// Errors of each request, in the same order as Credentials, empty for the credentials created. Unset when every
+	// credential was created.
+	Errors []string
+	// Violations of its schema by the credential of each request which failed for not complying with it, in the same
+	// order as Credentials. Unset when every credential was created.
+	Violations [][]schemaint.Violation
+}
+
+const (
+	// JWTProofFormat signs credentials as vc-jwt.
+	JWTProofFormat = "JWT"
+	// DataIntegrityProofFormat signs credentials with an embedded Data Integrity proof, in addition to signing them as
+	// vc-jwt. Proofs of Ed25519 keys are of the Ed25519Signature2020 suite, and those of other keys of the
+	// JsonWebSignature2020 suite.
+	DataIntegrityProofFormat = "DataIntegrity"
+)
+
+// ValidateProofFormat returns an error unless the proof format is supported, or empty.
+func ValidateProofFormat(proofFormat string) error {
+	switch proofFormat {
+	case "", JWTProofFormat, DataIntegrityProofFormat:
+		return nil
+	}
+	return errors.Errorf("unsupported proof format<%s>", proofFormat)
 }
 
 type CreateCredentialRequest struct {
@@ -22,15 +58,54 @@ type CreateCredentialRequest struct {
 	// `did:ion:EiDpQBo_nEfuLVeppgmPVQNEhtrnZLWFsB9ziZUuaKCJ3Q#83526c36-136c-423b-a57a-f190b83ae531`.
 	FullyQualifiedVerificationMethodID string `json:"issuerVerificationMethodId" validate:"required"`
 	Subject                            string `json:"subject" validate:"required"`
+	// SubjectType is optional. If present, such as `Organization`, it is set as the `type` of the credential subject,
+	// alongside its `id`. The data may only contain the same type.
+	SubjectType string `json:"subjectType,omitempty"`
 	// A context is optional. If not present, we'll apply default, required context values.
 	Context string `json:"context,omitempty"`
+	// Types are added to the credential's type, after VerifiableCredential.
+	Types []string `json:"types,omitempty"`
 	// A schema ID is optional. If present, we'll attempt to look it up and validate the data against it.
 	SchemaID    string         `json:"schemaId,omitempty"`
 	Data        map[string]any `json:"data,omitempty"`
 	Expiry      string         `json:"expiry,omitempty"`
 	Revocable   bool           `json:"revocable,omitempty"`
 	Suspendable bool           `json:"suspendable,omitempty"`
-	Evidence    []any          `json:"evidence,omitempty"`
+	// When set, the credential is added to a message status list, starting with the status value `0x0`.
+	MessageStatus *MessageStatus `json:"messageStatus,omitempty"`
+	Evidence      []any          `json:"evidence,omitempty"`
+	// When UniqueSubject is set, the subject may hold at most one active credential of the schema from the issuer.
+	UniqueSubject bool `json:"uniqueSubject,omitempty"`
+	// When ReplaceExisting is set, an active credential of the schema the subject holds from the issuer is revoked and
+	// replaced, instead of the creation being rejected. It implies UniqueSubject.
+	ReplaceExisting bool `json:"replaceExisting,omitempty"`
+	// JWTClaims are added to the top level of the credential's JWT, such as `vct`. Reserved claims, which the
+	// credential is encoded into, cannot be set.
+	JWTClaims map[string]any `json:"jwtClaims,omitempty"`
+	// JWTClaimsProfile overrides the configured preset setting which registered claims of the credential's JWT are set
+	// from the credential, such as keyaccess.JWTVCIssuanceProfile.
+	JWTClaimsProfile string `json:"jwtClaimsProfile,omitempty"`
+	// ProofFormat is JWTProofFormat when empty. With DataIntegrityProofFormat, the credential is also signed with an
+	// embedded proof, and both representations are stored.
+	ProofFormat string `json:"proofFormat,omitempty"`
+	// When AutoRenew is set, the credential is re-issued with the same data and schema before it expires. Requires an
+	// expiry.
+	AutoRenew *credential.AutoRenewPolicy `json:"autoRenew,omitempty"`
+	// Principal creating the credential, recorded on it. Credentials re-issued under an auto-renew policy are
+	// attributed to the principal which created the first credential.
+	CreatedBy string `json:"createdBy,omitempty"`
+
+	// the render method of the schema the credential is requested against, if any
+	renderMethod *credential.RenderMethod
+	// the expected claims of the schema the credential is requested against, if any
+	expectedClaims []string
+	// the expected type of the schema the credential is requested against, if any
+	expectedType string
+	// the subject credential quota of the schema the credential is requested against, if it overrides the service's
+	subjectQuota *int
+	// the credential the credential is re-issued in place of, and the ID of its renewal chain, when auto-renewing
+	previousCredential string
+	renewalChainID     string
 	// TODO(gabe) support more capabilities like signature type, format, evidence, and more.
 }
 
@@ -38,6 +113,14 @@ type CreateCredentialRequest struct {
 // containing either a Data Integrity Proofed credential or a VC-JWT representation.
 type CreateCredentialResponse struct {
 	credential.Container `json:"credential,omitempty"`
+	// Non-fatal findings about the request, each prefixed with its code.
+	Warnings []string `json:"warnings,omitempty"`
+	// IDs of the credentials revoked and replaced by the created credential.
+	ReplacedCredentialIDs []string `json:"replacedCredentialIds,omitempty"`
+
+	// the status list credential regenerated by revoking the replaced credentials, if any, which is published once the
+	// creation is committed
+	statusList *credential.Container
 }
 
 type GetCredentialRequest struct {
@@ -65,27 +148,95 @@ type ListCredentialsResponse struct {
 	NextPageToken string                 `json:"nextPageToken,omitempty"`
 }
 
+// CredentialMetadata describes a credential without its claims or proof, for listing many credentials cheaply.
+type CredentialMetadata struct {
+	ID             string `json:"id"`
+	Issuer         string `json:"issuer"`
+	Subject        string `json:"subject,omitempty"`
+	Schema         string `json:"schema,omitempty"`
+	SchemaVersion  int    `json:"schemaVersion,omitempty"`
+	Revoked        bool   `json:"revoked"`
+	Suspended      bool   `json:"suspended"`
+	IssuanceDate   string `json:"issuanceDate,omitempty"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+	Acknowledged   bool   `json:"acknowledged"`
+	CreatedBy      string `json:"createdBy,omitempty"`
+	// Whether the credential is currently usable, derived from whether it is revoked, suspended, or expired.
+	LifecycleState credential.LifecycleState `json:"lifecycleState"`
+
+	// Display name of the issuer. Only set when requested with `expand=issuer`.
+	IssuerDisplayName string `json:"issuerDisplayName,omitempty"`
+}
+
+type ListCredentialMetadataResponse struct {
+	Credentials   []CredentialMetadata `json:"credentials,omitempty"`
+	NextPageToken string               `json:"nextPageToken,omitempty"`
+}
+
 type DeleteCredentialRequest struct {
 	ID string `json:"id" validate:"required"`
+	// Principal deleting the credential, recorded in the event log.
+	Principal string `json:"principal,omitempty"`
 }
 
 type GetCredentialStatusRequest struct {
 	ID string `json:"id" validate:"required"`
 }
 
+// GetPublicCredentialStatusResponse holds only whether a credential is revoked or suspended, so that it can be shared
+// with anyone who knows the ID of the credential.
+type GetPublicCredentialStatusResponse struct {
+	Revoked   bool `json:"revoked"`
+	Suspended bool `json:"suspended"`
+}
+
 type GetCredentialStatusResponse struct {
 	Revoked   bool `json:"revoked" validate:"required"`
 	Suspended bool `json:"suspended" validate:"required"`
+	// Only set for credentials using a message status list.
+	StatusValue   string `json:"statusValue,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// Why the status was last updated, and the code of that reason, when given with the update.
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reasonCode,omitempty"`
+
+	// Set to ExternalStatusSource for imported credentials, whose status is read from their issuer's status list.
+	Source string `json:"source,omitempty"`
+	// Set when the status of an imported credential could not be checked, in which case Revoked and Suspended are
+	// meaningless.
+	StatusUnknown       bool   `json:"statusUnknown,omitempty"`
+	StatusUnknownReason string `json:"statusUnknownReason,omitempty"`
+	// Set when the status of an imported credential was checked against a cached status list whose TTL expired, while
+	// it is refreshed.
+	Stale bool `json:"stale,omitempty"`
+
+	// Whether the credential is currently usable. Not set when its status is unknown.
+	LifecycleState credential.LifecycleState `json:"lifecycleState,omitempty"`
 }
 
 type UpdateCredentialStatusRequest struct {
 	ID        string `json:"id" validate:"required"`
 	Revoked   bool   `json:"revoked" validate:"required"`
 	Suspended bool   `json:"suspended" validate:"required"`
+	// Status value of a credential using a message status list, given either as a value such as `0x1` or as one of the
+	// list's messages.
+	StatusValue string `json:"statusValue,omitempty"`
+	// Verification method the status is updated with. Required when the status list of the credential is bound to a
+	// verification method, in which case it must be that one.
+	VerificationMethodID string `json:"verificationMethodId,omitempty"`
+	// Why the status is updated, such as for an auditor, and the code of that reason. When reason codes are configured,
+	// the code must be one of them.
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Principal updating the status, recorded in the event log.
+	Principal string `json:"principal,omitempty"`
 }
 
 type UpdateCredentialStatusResponse struct {
 	Status
+
+	// the status list credential regenerated by the update, if any, which is published once the update is committed
+	statusList *credential.Container
 }
 
 type Status struct {
@@ -93,6 +244,12 @@ type Status struct {
 	ID        string `json:"id,omitempty"`
 	Revoked   bool   `json:"revoked" validate:"required"`
 	Suspended bool   `json:"suspended" validate:"required"`
+	// Only set for credentials using a message status list.
+	StatusValue   string `json:"statusValue,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// Why the status was last updated, and the code of that reason, when given with the update.
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reasonCode,omitempty"`
 }
 
 type BatchUpdateCredentialStatusRequest struct {
@@ -112,14 +269,37 @@ type GetCredentialStatusListResponse struct {
 }
 
 func (csr CreateCredentialRequest) isStatusValid() bool {
-	if csr.Revocable && csr.Suspendable {
-		return false
+	statuses := 0
+	for _, hasStatus := range []bool{csr.Revocable, csr.Suspendable, csr.MessageStatus != nil} {
+		if hasStatus {
+			statuses++
+		}
 	}
-	return true
+	return statuses <= 1
 }
 
 func (csr CreateCredentialRequest) hasStatus() bool {
-	return csr.Suspendable || csr.Revocable
+	return csr.Suspendable || csr.Revocable || csr.MessageStatus != nil
+}
+
+// statusPurpose returns the purpose of the status list of a credential which has a status.
+func (csr CreateCredentialRequest) statusPurpose() statussdk.StatusPurpose {
+	switch {
+	case csr.Suspendable:
+		return statussdk.StatusSuspension
+	case csr.MessageStatus != nil:
+		return MessageStatusPurpose
+	default:
+		return statussdk.StatusRevocation
+	}
+}
+
+// statusSize returns the number of bits of each entry of the status list of a credential.
+func (csr CreateCredentialRequest) statusSize() int {
+	if csr.MessageStatus == nil {
+		return 0
+	}
+	return csr.MessageStatus.Size
 }
 
 func (csr CreateCredentialRequest) hasEvidence() bool {
@@ -148,5 +328,26 @@ func (csr CreateCredentialRequest) IsValid() error {
 	if err := util.IsValidStruct(csr); err != nil {
 		return err
 	}
+	if csr.MessageStatus != nil {
+		if err := csr.MessageStatus.IsValid(); err != nil {
+			return errors.Wrap(err, "invalid message status")
+		}
+	}
+	if err := keyaccess.ValidateJWTClaims(csr.JWTClaims); err != nil {
+		return errors.Wrap(err, "invalid jwt claims")
+	}
+	if csr.JWTClaimsProfile != "" {
+		if _, err := keyaccess.GetJWTClaimsPolicy(csr.JWTClaimsProfile); err != nil {
+			return err
+		}
+	}
+	if err := ValidateProofFormat(csr.ProofFormat); err != nil {
+		return err
+	}
+	// verification methods of other DIDs may sign on behalf of the issuer when it delegated to them, which is checked
+	// when the credential is signed
+	if strings.HasPrefix(csr.FullyQualifiedVerificationMethodID, "did:") {
+		return nil
+	}
 	return common.ValidateVerificationMethodID(csr.FullyQualifiedVerificationMethodID, csr.Issuer)
 }
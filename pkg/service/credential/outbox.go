@@ -0,0 +1,47 @@
+package credential
+
+import (
+	"context"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// EnableOutbox writes the Credential Create and StatusUpdate events to the webhook outbox, in the transactions creating
+// credentials and updating their status. It must be set before the service changes credentials.
+func (s *Service) EnableOutbox() {
+	s.outbox = true
+}
+
+// writeCreatedEvent writes the Credential Create event of a credential to the outbox, with the data of the response
+// of the route creating it, and appends it to the event log.
+func (s Service) writeCreatedEvent(ctx context.Context, tx storage.Tx, response CreateCredentialResponse) error {
+	data := struct {
+		credint.Container
+		Warnings              []string `json:"warnings,omitempty"`
+		ReplacedCredentialIDs []string `json:"replacedCredentialIds,omitempty"`
+	}{
+		Container:             response.Container,
+		Warnings:              response.Warnings,
+		ReplacedCredentialIDs: response.ReplacedCredentialIDs,
+	}
+	if s.outbox {
+		if err := webhook.WriteOutboxEventTx(ctx, tx, webhook.Credential, webhook.Create, response.ID, data); err != nil {
+			return err
+		}
+	}
+	return s.appendEvent(ctx, tx, webhook.Create, response.ID, response.CreatedBy, data)
+}
+
+// writeStatusUpdatedEvent writes the Credential StatusUpdate event of a credential to the outbox, with its updated
+// status, and appends it to the event log along with the principal which updated it.
+func (s Service) writeStatusUpdatedEvent(ctx context.Context, tx storage.Tx, id, principal string, status Status) error {
+	status.ID = id
+	if s.outbox {
+		if err := webhook.WriteOutboxEventTx(ctx, tx, webhook.Credential, webhook.StatusUpdate, id, status); err != nil {
+			return err
+		}
+	}
+	return s.appendEvent(ctx, tx, webhook.StatusUpdate, id, principal, status)
+}
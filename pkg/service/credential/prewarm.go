@@ -0,0 +1,102 @@
+package credential
+
+import (
+	"context"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+type EnsureStatusListRequest struct {
+	Issuer string `json:"issuer" validate:"required"`
+	// Verification method of the issuer that signs the status list when it is created, unless status lists of the
+	// issuer and schema are bound to another verification method.
+	FullyQualifiedVerificationMethodID string `json:"fullyQualifiedVerificationMethodId" validate:"required"`
+	// Empty for the status list of credentials issued without a schema.
+	SchemaID string `json:"schemaId,omitempty"`
+	// One of revocation or suspension. Message status lists are created along with their first credential, which
+	// carries the status messages of the list.
+	Purpose statussdk.StatusPurpose `json:"purpose" validate:"required"`
+}
+
+func (r EnsureStatusListRequest) IsValid() error {
+	if err := sdkutil.IsValidStruct(r); err != nil {
+		return err
+	}
+	if r.Purpose != statussdk.StatusRevocation && r.Purpose != statussdk.StatusSuspension {
+		return errors.Errorf("unsupported status purpose: %s", r.Purpose)
+	}
+	return common.ValidateVerificationMethodID(r.FullyQualifiedVerificationMethodID, r.Issuer)
+}
+
+type EnsureStatusListResponse struct {
+	StatusListCredentialID string `json:"statusListCredentialId"`
+	// Whether the status list was created by the request, rather than found.
+	Created bool `json:"created"`
+}
+
+// EnsureStatusList creates the status list credential and index pool of an issuer, schema and purpose when they do
+// not exist yet, so that credentials issued against them in bulk only consume indexes of the pool, rather than
+// contending to create the list. It is idempotent: an existing status list is returned as is.
+func (s Service) EnsureStatusList(ctx context.Context, request EnsureStatusListRequest) (*EnsureStatusListResponse, error) {
+	if err := request.IsValid(); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid ensure status list request")
+	}
+
+	purpose := string(request.Purpose)
+	statusMetadata := StatusListCredentialMetadata{
+		statusListCredentialWatchKey:   s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, purpose),
+		statusListIndexPoolWatchKey:    s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, request.SchemaID, purpose),
+		statusListCurrentIndexWatchKey: s.storage.GetStatusListCurrentIndexWatchKey(request.Issuer, request.SchemaID, purpose),
+	}
+	watchKeys := []storage.WatchKey{
+		statusMetadata.statusListCredentialWatchKey,
+		statusMetadata.statusListIndexPoolWatchKey,
+		statusMetadata.statusListCurrentIndexWatchKey,
+	}
+
+	ensureFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		return s.ensureStatusList(ctx, tx, request, statusMetadata)
+	}
+	returnValue, err := s.storage.db.Execute(ctx, ensureFunc, watchKeys)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "ensuring %s status list of issuer<%s> and schema<%s>", request.Purpose, request.Issuer, request.SchemaID)
+	}
+	response, ok := returnValue.(*EnsureStatusListResponse)
+	if !ok {
+		return nil, errors.New("problem casting to EnsureStatusListResponse")
+	}
+	return response, nil
+}
+
+func (s Service) ensureStatusList(ctx context.Context, tx storage.Tx, request EnsureStatusListRequest, statusMetadata StatusListCredentialMetadata) (*EnsureStatusListResponse, error) {
+	existing, err := s.storage.GetStatusListCredentialKeyData(ctx, request.Issuer, request.SchemaID, request.Purpose)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting status list credential key data")
+	}
+	if existing != nil {
+		return &EnsureStatusListResponse{StatusListCredentialID: existing.Credential.ID}, nil
+	}
+
+	verificationMethodID := s.statusListVerificationMethodID(request.Issuer, request.SchemaID, request.Purpose, request.FullyQualifiedVerificationMethodID)
+	statusRequest := CreateCredentialRequest{
+		Issuer:      request.Issuer,
+		SchemaID:    request.SchemaID,
+		Revocable:   request.Purpose == statussdk.StatusRevocation,
+		Suspendable: request.Purpose == statussdk.StatusSuspension,
+	}
+	statusListContainer, err := s.newStatusListCredential(ctx, statusRequest, request.Issuer, verificationMethodID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.storage.CreateUnallocatedStatusListCredentialTx(ctx, tx, StoreCredentialRequest{Container: *statusListContainer}, statusMetadata); err != nil {
+		return nil, errors.Wrap(err, "creating status list credential")
+	}
+	logrus.Infof("created %s status list<%s> of issuer<%s> and schema<%s>", request.Purpose, statusListContainer.ID, request.Issuer, request.SchemaID)
+	return &EnsureStatusListResponse{StatusListCredentialID: statusListContainer.Credential.ID, Created: true}, nil
+}
@@ -0,0 +1,186 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/oauth2/google"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+const (
+	// statusListIDPlaceholder is replaced with the ID of the status list credential in the URL of an HTTP publisher
+	statusListIDPlaceholder = "{id}"
+
+	statusListContentType = "application/jwt"
+	gcsBaseURL            = "https://storage.googleapis.com"
+	gcsReadWriteScope     = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// StatusListPublisher writes signed status list credentials to a location outside the service, so that verifiers can
+// fetch revocation data without depending on the service's availability.
+type StatusListPublisher interface {
+	Publish(ctx context.Context, statusList credint.Container) error
+}
+
+// NewStatusListPublisher returns the publisher described by the config, or nil when publication is disabled.
+func NewStatusListPublisher(cfg config.StatusListPublisherConfig) (StatusListPublisher, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case config.HTTPStatusListPublisher:
+		if cfg.URL == "" {
+			return nil, errors.New("a url is required for the http status list publisher")
+		}
+		return &HTTPStatusListPublisher{
+			client:        &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+			url:           cfg.URL,
+			authorization: cfg.Authorization,
+		}, nil
+	case config.GCSStatusListPublisher:
+		if cfg.Bucket == "" {
+			return nil, errors.New("a bucket is required for the gcs status list publisher")
+		}
+		client, err := google.DefaultClient(context.Background(), gcsReadWriteScope)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading google cloud credentials")
+		}
+		// the XML API of cloud storage accepts plain PUT requests
+		return &HTTPStatusListPublisher{
+			client: client,
+			url:    strings.Join([]string{gcsBaseURL, cfg.Bucket, path.Join(cfg.Prefix, statusListIDPlaceholder)}, "/"),
+		}, nil
+	case config.S3StatusListPublisher:
+		if cfg.Bucket == "" {
+			return nil, errors.New("a bucket is required for the s3 status list publisher")
+		}
+		sess, err := session.NewSession(&aws.Config{Region: aws.String(cfg.Region)})
+		if err != nil {
+			return nil, errors.Wrap(err, "creating aws session")
+		}
+		return &S3StatusListPublisher{client: s3.New(sess), bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+	default:
+		return nil, fmt.Errorf("unsupported status list publisher type: %s", cfg.Type)
+	}
+}
+
+// HTTPStatusListPublisher PUTs the signed status list credential to a URL.
+type HTTPStatusListPublisher struct {
+	client        *http.Client
+	url           string
+	authorization string
+}
+
+func (p HTTPStatusListPublisher) Publish(ctx context.Context, statusList credint.Container) error {
+	if statusList.CredentialJWT == nil {
+		return errors.New("status list credential is not signed")
+	}
+	url := strings.ReplaceAll(p.url, statusListIDPlaceholder, statusList.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, strings.NewReader(statusList.CredentialJWT.String()))
+	if err != nil {
+		return errors.Wrap(err, "building request")
+	}
+	req.Header.Set("Content-Type", statusListContentType)
+	if p.authorization != "" {
+		req.Header.Set("Authorization", p.authorization)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "publishing to %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("publishing to %s: unexpected status %d: %s", url, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// S3StatusListPublisher writes the signed status list credential to an S3 bucket.
+type S3StatusListPublisher struct {
+	client *s3.S3
+	bucket string
+	prefix string
+}
+
+func (p S3StatusListPublisher) Publish(ctx context.Context, statusList credint.Container) error {
+	if statusList.CredentialJWT == nil {
+		return errors.New("status list credential is not signed")
+	}
+	key := path.Join(p.prefix, statusList.ID)
+	_, err := p.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucket),
+		Key:         aws.String(key),
+		Body:        strings.NewReader(statusList.CredentialJWT.String()),
+		ContentType: aws.String(statusListContentType),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "writing s3://%s/%s", p.bucket, key)
+	}
+	return nil
+}
+
+// publishStatusLists publishes the given status list credentials in the background. Publication is best-effort: it is
+// retried with an exponential backoff, and failures are logged and counted, but never returned to the caller, since
+// the status lists are already stored by the service.
+func (s Service) publishStatusLists(statusLists ...*credint.Container) {
+	if s.publisher == nil {
+		return
+	}
+	for _, statusList := range statusLists {
+		if statusList == nil {
+			continue
+		}
+		go s.publishStatusList(*statusList)
+	}
+}
+
+func (s Service) publishStatusList(statusList credint.Container) {
+	cfg := s.config.StatusListPublisher
+	backoff, err := time.ParseDuration(cfg.RetryBackoff)
+	if err != nil {
+		backoff = time.Second
+	}
+	maxAttempts := max(cfg.MaxAttempts, 1)
+
+	ctx := context.Background()
+	for attempt := 1; ; attempt++ {
+		err = s.publisher.Publish(ctx, statusList)
+		if err == nil {
+			logrus.Debugf("published status list credential<%s>", statusList.ID)
+			s.publications.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "success")))
+			return
+		}
+		if attempt >= maxAttempts {
+			break
+		}
+		logrus.WithError(err).Warnf("publishing status list credential<%s>, attempt %d of %d", statusList.ID, attempt, maxAttempts)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	logrus.WithError(err).Errorf("giving up publishing status list credential<%s> after %d attempts", statusList.ID, maxAttempts)
+	s.publications.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "failure")))
+}
+
+func newPublicationsCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.status_list.publications",
+		metric.WithDescription("Number of status list credentials published to an external store, by outcome"),
+	)
+}
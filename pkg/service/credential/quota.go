@@ -0,0 +1,84 @@
+package credential
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ErrSubjectQuotaExceeded is returned when creating a credential for a subject which already holds as many credentials
+// from the issuer as its quota allows.
+var ErrSubjectQuotaExceeded = errors.New("subject credential quota exceeded")
+
+// subjectQuota returns how many credentials the subject of a credential may hold from its issuer: the quota of the
+// schema the credential is requested against when it has one, or otherwise the quota of the service. 0 means no quota.
+func (s Service) subjectQuota(request CreateCredentialRequest) int {
+	if request.subjectQuota != nil {
+		return *request.subjectQuota
+	}
+	return s.config.SubjectCredentialQuota
+}
+
+// subjectQuotaWatchKeys returns the keys to watch while creating a credential under a quota, so that credentials for
+// the subject created concurrently can't exceed it.
+func (s Service) subjectQuotaWatchKeys(request CreateCredentialRequest) []storage.WatchKey {
+	if s.subjectQuota(request) <= 0 {
+		return nil
+	}
+	return []storage.WatchKey{s.storage.GetSubjectQuotaWatchKey(request.Issuer, request.Subject)}
+}
+
+// checkSubjectQuota rejects a credential when its subject already holds as many credentials from the issuer as the
+// quota allows, counting every stored credential, whether revoked or expired, since they all remain in the wallet of
+// the subject. Otherwise, the credential is recorded against the quota of the subject.
+func (s Service) checkSubjectQuota(ctx context.Context, tx storage.Tx, request CreateCredentialRequest, credentialID string) error {
+	quota := s.subjectQuota(request)
+	if quota <= 0 {
+		return nil
+	}
+	count, err := s.storage.CountCredentialsByIssuerAndSubject(ctx, request.Issuer, request.Subject)
+	if err != nil {
+		return errors.Wrap(err, "counting credentials of subject")
+	}
+	if count >= quota {
+		return sdkutil.LoggingError(errors.Wrapf(ErrSubjectQuotaExceeded, "subject<%s> holds %d credentials from issuer<%s>, the quota is %d",
+			request.Subject, count, request.Issuer, quota))
+	}
+	return s.storage.StoreSubjectCredentialIDTx(ctx, tx, s.storage.GetSubjectQuotaWatchKey(request.Issuer, request.Subject), credentialID)
+}
+
+// checkBatchSubjectQuotas rejects a batch which would take a subject beyond its quota, since credentials created
+// earlier in a transaction are not counted by the quota of later ones.
+func (s Service) checkBatchSubjectQuotas(ctx context.Context, requests []CreateCredentialRequest) error {
+	type issuerSubject struct{ issuer, subject string }
+	batchCounts := make(map[issuerSubject]int)
+	for _, request := range requests {
+		if s.subjectQuota(request) > 0 {
+			batchCounts[issuerSubject{issuer: request.Issuer, subject: request.Subject}]++
+		}
+	}
+	storedCounts := make(map[issuerSubject]int)
+	for _, request := range requests {
+		quota := s.subjectQuota(request)
+		if quota <= 0 {
+			continue
+		}
+		pair := issuerSubject{issuer: request.Issuer, subject: request.Subject}
+		storedCount, ok := storedCounts[pair]
+		if !ok {
+			var err error
+			if storedCount, err = s.storage.CountCredentialsByIssuerAndSubject(ctx, request.Issuer, request.Subject); err != nil {
+				return errors.Wrap(err, "counting credentials of subject")
+			}
+			storedCounts[pair] = storedCount
+		}
+		if count := storedCount + batchCounts[pair]; count > quota {
+			return sdkutil.LoggingError(errors.Wrapf(ErrSubjectQuotaExceeded, "batch would give subject<%s> %d credentials from issuer<%s>, the quota is %d",
+				request.Subject, count, request.Issuer, quota))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package credential
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	didint "github.com/tbd54566975/ssi-service/internal/did"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ReceiptCredentialIDClaim is the claim of a receipt JWT referencing the credential it acknowledges, either by its ID
+// within the service, or by its `id`.
+const ReceiptCredentialIDClaim = "credentialId"
+
+var (
+	// ErrInvalidReceipt is returned when a receipt is not a JWT referencing the credential, with a valid signature.
+	ErrInvalidReceipt = errors.New("invalid receipt")
+	// ErrReceiptSignerNotSubject is returned when a receipt is signed by a DID other than the subject of the credential.
+	ErrReceiptSignerNotSubject = errors.New("receipt is not signed by the subject of the credential")
+)
+
+type StoreReceiptRequest struct {
+	ID         string        `json:"id" validate:"required"`
+	ReceiptJWT keyaccess.JWT `json:"receiptJwt" validate:"required"`
+}
+
+type StoreReceiptResponse struct {
+	Receipt credint.Receipt `json:"receipt"`
+}
+
+// StoreReceipt verifies a receipt JWT with which the subject of a credential acknowledges that it was delivered to
+// them, however it was delivered, and stores it alongside the credential. The receipt must be issued by the subject
+// of the credential, reference the credential with its ReceiptCredentialIDClaim, and be signed with a key of the
+// subject's DID document. A receipt replaces any receipt stored before it.
+func (s Service) StoreReceipt(ctx context.Context, request StoreReceiptRequest) (*StoreReceiptResponse, error) {
+	logrus.Debugf("storing receipt of credential: %s", request.ID)
+
+	gotCred, err := s.storage.GetCredential(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
+	}
+
+	signature, token, err := util.ParseJWT(request.ReceiptJWT)
+	if err != nil {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidReceipt, "parsing receipt JWT: %s", err))
+	}
+	referenced, _ := token.Get(ReceiptCredentialIDClaim)
+	if referenced != gotCred.LocalCredentialID && referenced != gotCred.Credential.ID {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidReceipt, "receipt references credential<%v>, not credential<%s>", referenced, request.ID))
+	}
+
+	signer := token.Issuer()
+	kid := signature.ProtectedHeaders().KeyID()
+	if kid == "" {
+		return nil, sdkutil.LoggingError(errors.Wrap(ErrInvalidReceipt, "receipt JWT does not contain a kid"))
+	}
+	// the kid may be a DID URL, whose DID must be the signer's
+	if strings.HasPrefix(kid, "did:") && !strings.HasPrefix(kid, signer+"#") {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidReceipt, "kid<%s> is not a key of the receipt issuer<%s>", kid, signer))
+	}
+	if signer != gotCred.Subject {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrReceiptSignerNotSubject, "receipt is signed by<%s>, credential<%s> has subject<%s>", signer, request.ID, gotCred.Subject))
+	}
+	if err = didint.VerifyTokenFromDID(ctx, s.didResolver, signer, kid, request.ReceiptJWT); err != nil {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidReceipt, "verifying receipt signature: %s", err))
+	}
+
+	receipt := credint.Receipt{
+		ReceiptJWT:           request.ReceiptJWT,
+		VerificationMethodID: did.FullyQualifiedVerificationMethodID(signer, kid),
+		AcknowledgedAt:       time.Now().UTC().Format(time.RFC3339),
+	}
+	if err = s.storage.StoreReceipt(ctx, *gotCred, receipt); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not store receipt of credential: %s", request.ID)
+	}
+	return &StoreReceiptResponse{Receipt: receipt}, nil
+}
+
+// StoreReceipt stores the receipt alongside the given credential. The credential is read again before it is written,
+// so that concurrent updates of its status are not lost.
+func (cs *Storage) StoreReceipt(ctx context.Context, stored StoredCredential, receipt credint.Receipt) error {
+	watchKeys := []storage.WatchKey{cs.GetCredentialWatchKey(stored.LocalCredentialID, stored.Issuer, stored.Subject, stored.Schema)}
+	_, err := cs.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		current, err := cs.GetCredential(ctx, stored.LocalCredentialID)
+		if err != nil {
+			return nil, err
+		}
+		current.Receipt = &receipt
+		currentBytes, err := util.CanonicalJSON(current)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling credential with receipt")
+		}
+		return nil, tx.Write(ctx, credentialNamespace, current.Key, currentBytes)
+	}, watchKeys)
+	return err
+}
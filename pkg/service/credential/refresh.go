@@ -0,0 +1,186 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// StatusListRefreshedFunc is called with each status list credential re-signed by RunStatusListRefresh.
+type StatusListRefreshedFunc func(ctx context.Context, statusList credint.Container)
+
+// StatusListRefreshInterval returns how often status list credentials are re-signed, or 0 when re-signing is disabled.
+func (s Service) StatusListRefreshInterval() time.Duration {
+	return s.refreshInterval
+}
+
+// RunStatusListRefresh re-signs the status list credentials every refresh interval, until the context is done. It
+// returns immediately when re-signing is disabled.
+func (s Service) RunStatusListRefresh(ctx context.Context, onRefreshed StatusListRefreshedFunc) {
+	if s.refreshInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshed, err := s.RefreshStatusLists(ctx)
+			if err != nil {
+				logrus.WithError(err).Error("refreshing status list credentials")
+				continue
+			}
+			if onRefreshed == nil {
+				continue
+			}
+			for _, statusList := range refreshed {
+				onRefreshed(ctx, statusList)
+			}
+		}
+	}
+}
+
+// RefreshStatusLists re-signs every status list credential which is not excluded through config, keeping its
+// bitstring, with a fresh issuance date and the configured validity. A status list which fails to be re-signed is
+// logged and counted, and does not prevent the others from being re-signed. The re-signed status lists are returned.
+func (s Service) RefreshStatusLists(ctx context.Context) ([]credint.Container, error) {
+	watchKeys, err := s.storage.ListStatusListCredentialWatchKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing status list credentials")
+	}
+
+	refreshed := make([]credint.Container, 0, len(watchKeys))
+	for _, watchKey := range watchKeys {
+		statusList, err := s.refreshStatusList(ctx, watchKey)
+		if err != nil {
+			logrus.WithError(err).Errorf("refreshing status list credential with key: %s", watchKey.Key)
+			s.refreshes.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "failure")))
+			continue
+		}
+		if statusList == nil {
+			continue
+		}
+		logrus.Debugf("refreshed status list credential<%s>", statusList.ID)
+		s.refreshes.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", "success")))
+		refreshed = append(refreshed, *statusList)
+	}
+
+	for i := range refreshed {
+		s.publishStatusLists(&refreshed[i])
+	}
+	return refreshed, nil
+}
+
+// refreshStatusList re-signs the status list credential stored under the watch key. It watches the same key as status
+// updates do, so that re-signing cannot overwrite a status list regenerated by a concurrent status update.
+func (s Service) refreshStatusList(ctx context.Context, watchKey storage.WatchKey) (*credint.Container, error) {
+	refreshFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		return s.refreshStatusListBusinessLogic(ctx, tx, watchKey)
+	}
+	returnValue, err := s.storage.db.Execute(ctx, refreshFunc, []storage.WatchKey{watchKey})
+	if err != nil {
+		return nil, errors.Wrap(err, "execute")
+	}
+	statusList, ok := returnValue.(*credint.Container)
+	if !ok {
+		return nil, errors.New("casting to status list container")
+	}
+	return statusList, nil
+}
+
+func (s Service) refreshStatusListBusinessLogic(ctx context.Context, tx storage.Tx, watchKey storage.WatchKey) (*credint.Container, error) {
+	gotStatusList, err := s.storage.GetStatusListCredentialByWatchKey(ctx, watchKey)
+	if err != nil {
+		return nil, err
+	}
+	if gotStatusList == nil || !gotStatusList.IsValid() || gotStatusList.Credential == nil {
+		return nil, sdkutil.LoggingNewErrorf("status list credential with key<%s> is not valid", watchKey.Key)
+	}
+	if sdkutil.Contains(gotStatusList.LocalCredentialID, s.config.StatusListRefreshExclusions) {
+		return nil, nil
+	}
+
+	statusListCred, err := credint.CopyCredential(*gotStatusList.Credential)
+	if err != nil {
+		return nil, errors.Wrap(err, "copying status list credential")
+	}
+	now := time.Now()
+	statusListCred.IssuanceDate = now.Format(time.RFC3339)
+	statusListCred.ExpirationDate = ""
+	if s.refreshValidity > 0 {
+		statusListCred.ExpirationDate = now.Add(s.refreshValidity).Format(time.RFC3339)
+	}
+
+	verificationMethodID, err := s.signingVerificationMethodID(ctx, gotStatusList.Issuer, gotStatusList.FullyQualifiedVerificationMethodID)
+	if err != nil {
+		return nil, err
+	}
+	statusListCredJWT, err := s.signCredentialJWT(ctx, verificationMethodID, *statusListCred, s.jwtClaimsPolicy, nil)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
+	}
+
+	statusListContainer := credint.Container{
+		ID:                                 gotStatusList.LocalCredentialID,
+		FullyQualifiedVerificationMethodID: verificationMethodID,
+		Credential:                         statusListCred,
+		CredentialJWT:                      statusListCredJWT,
+	}
+	storageRequest := StoreCredentialRequest{Container: statusListContainer}
+	slcMetadata := StatusListCredentialMetadata{statusListCredentialWatchKey: watchKey}
+	if err = s.storage.StoreStatusListCredentialTx(ctx, tx, storageRequest, slcMetadata); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential status list")
+	}
+	return &statusListContainer, nil
+}
+
+// signingVerificationMethodID returns the verification method a status list credential is re-signed with. This is the
+// one it was last signed with, unless its key has since been revoked, in which case it is the most recently created key
+// of the issuer which is not revoked.
+func (s Service) signingVerificationMethodID(ctx context.Context, issuer, verificationMethodID string) (string, error) {
+	keyStoreID := did.FullyQualifiedVerificationMethodID(issuer, verificationMethodID)
+	gotKey, err := s.keyStore.GetKey(ctx, keystore.GetKeyRequest{ID: keyStoreID})
+	if err == nil && !gotKey.Revoked {
+		return verificationMethodID, nil
+	}
+
+	keys, err := s.keyStore.ListKeysByController(ctx, []string{issuer})
+	if err != nil {
+		return "", errors.Wrap(err, "listing keys of issuer")
+	}
+	var latest *keystore.StoredKey
+	for i, key := range keys {
+		if key.Revoked {
+			continue
+		}
+		if latest == nil || key.CreatedAt > latest.CreatedAt {
+			latest = &keys[i]
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("issuer<%s> has no key which is not revoked", issuer)
+	}
+	return latest.ID, nil
+}
+
+func newRefreshesCounter() (metric.Int64Counter, error) {
+	return otel.Meter(config.ServiceName).Int64Counter(
+		"ssi_service.status_list.refreshes",
+		metric.WithDescription("Number of status list credentials re-signed on a schedule, by outcome"),
+	)
+}
@@ -0,0 +1,183 @@
+package credential
+
+import (
+	"context"
+	"sort"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/util"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ReindexPageSize is the number of stored credentials read at a time while reindexing.
+const ReindexPageSize = 100
+
+// ReindexResult reports the changes made to the indexes of the stored credentials.
+type ReindexResult struct {
+	// The number of stored credential entries scanned.
+	Scanned int `json:"scanned"`
+
+	// The number of entries written with the index values derived from their credential.
+	Added int `json:"added"`
+
+	// The number of entries whose index values diverged from their credential, which were replaced or deleted.
+	Removed int `json:"removed"`
+}
+
+// Reindex rebuilds the indexes of the stored credentials from the credentials themselves, scanning the credential
+// namespace in pages of the given size. The indexes are the issuer, subject, and schema in the key of each entry, and
+// its denormalized issuer, subject, schema, and issuance date, which listing credentials filters on. The status of a
+// credential is kept as stored, since it is not derived from the credential.
+//
+// Entries are repaired one at a time, and never truncated, so that it is safe to reindex while the service serves
+// traffic: an entry which is updated concurrently is read again before it is repaired, and an entry whose key diverged
+// is merged into the entry at its rebuilt key, which is kept when it was written concurrently.
+func (cs *Storage) Reindex(ctx context.Context, pageSize int) (*ReindexResult, error) {
+	var result ReindexResult
+	pageToken := ""
+	for {
+		page, nextPageToken, err := cs.db.ReadPage(ctx, credentialNamespace, pageToken, pageSize)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "reading page of credentials to reindex")
+		}
+		keys := make([]string, 0, len(page))
+		for key := range page {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.Scanned++
+			added, removed, err := cs.reindexCredential(ctx, key, page[key])
+			if err != nil {
+				return nil, errors.Wrapf(err, "reindexing credential entry<%s>", key)
+			}
+			result.Added += added
+			result.Removed += removed
+		}
+		if nextPageToken == "" {
+			return &result, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// reindexCredential repairs the indexes of the entry at the given key, returning the number of entries added and
+// removed.
+func (cs *Storage) reindexCredential(ctx context.Context, key string, data []byte) (added int, removed int, err error) {
+	var stored StoredCredential
+	if err = unmarshalStoredCredential(data, &stored); err != nil {
+		logrus.WithError(err).Warnf("skipping credential entry<%s> which can't be unmarshalled", key)
+		return 0, 0, nil
+	}
+	indexed, err := indexedCredential(stored)
+	if err != nil {
+		logrus.WithError(err).Warnf("skipping credential entry<%s> whose indexes can't be derived", key)
+		return 0, 0, nil
+	}
+	if indexed.Key == key && indexed.hasIndexesOf(stored) {
+		return 0, 0, nil
+	}
+
+	watchKeys := []storage.WatchKey{
+		{Namespace: credentialNamespace, Key: key},
+		{Namespace: credentialNamespace, Key: indexed.Key},
+	}
+	changes, err := cs.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		// read the entry again, so that concurrent updates of its status are not lost
+		current, err := cs.db.Read(ctx, credentialNamespace, key)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading credential entry")
+		}
+		if len(current) == 0 {
+			return reindexChanges{}, nil
+		}
+		if indexed.Key != key {
+			existing, err := cs.db.Read(ctx, credentialNamespace, indexed.Key)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading rebuilt credential entry")
+			}
+			// the rebuilt entry was written by a more recent update of the credential, which is kept
+			if len(existing) > 0 {
+				if err = tx.Delete(ctx, credentialNamespace, key); err != nil {
+					return nil, errors.Wrap(err, "deleting diverged credential entry")
+				}
+				return reindexChanges{removed: 1}, nil
+			}
+		}
+		var currentStored StoredCredential
+		if err = unmarshalStoredCredential(current, &currentStored); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling credential entry")
+		}
+		currentIndexed, err := indexedCredential(currentStored)
+		if err != nil {
+			return nil, err
+		}
+		indexedBytes, err := util.CanonicalJSON(currentIndexed)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling reindexed credential")
+		}
+		if err = tx.Write(ctx, credentialNamespace, currentIndexed.Key, indexedBytes); err != nil {
+			return nil, errors.Wrap(err, "writing reindexed credential")
+		}
+		if currentIndexed.Key != key {
+			if err = tx.Delete(ctx, credentialNamespace, key); err != nil {
+				return nil, errors.Wrap(err, "deleting diverged credential entry")
+			}
+		}
+		// the entry is either replaced in place, or moved to its rebuilt key
+		return reindexChanges{added: 1, removed: 1}, nil
+	}, watchKeys)
+	if err != nil {
+		return 0, 0, err
+	}
+	result := changes.(reindexChanges)
+	return result.added, result.removed, nil
+}
+
+// reindexChanges are the numbers of entries added and removed by the transaction repairing a single entry.
+type reindexChanges struct {
+	added   int
+	removed int
+}
+
+// indexedCredential returns the stored credential with the index values derived from its credential.
+func indexedCredential(stored StoredCredential) (*StoredCredential, error) {
+	cred := stored.Credential
+	if stored.HasJWTCredential() {
+		parsed, err := credint.ParseVerifiableCredentialFromJWT(stored.CredentialJWT.String())
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing credential from jwt")
+		}
+		cred = parsed
+	}
+	if cred == nil {
+		return nil, errors.New("stored entry has no credential")
+	}
+	issuer, ok := cred.Issuer.(string)
+	if !ok {
+		return nil, errors.Errorf("credential issuer is not a string: %v", cred.Issuer)
+	}
+	schema := ""
+	if cred.CredentialSchema != nil {
+		schema = cred.CredentialSchema.ID
+	}
+
+	indexed := stored
+	indexed.Issuer = issuer
+	indexed.Subject = cred.CredentialSubject.GetID()
+	indexed.SubjectType = subjectType(cred.CredentialSubject)
+	indexed.Schema = schema
+	indexed.IssuanceDate = cred.IssuanceDate
+	indexed.Key = createPrefixKey(stored.LocalCredentialID, indexed.Issuer, indexed.Subject, indexed.Schema)
+	return &indexed, nil
+}
+
+// hasIndexesOf returns whether the index values of the stored credential are the same as those of another.
+func (sc *StoredCredential) hasIndexesOf(other StoredCredential) bool {
+	return sc.Key == other.Key && sc.Issuer == other.Issuer && sc.Subject == other.Subject &&
+		sc.SubjectType == other.SubjectType && sc.Schema == other.Schema && sc.IssuanceDate == other.IssuanceDate
+}
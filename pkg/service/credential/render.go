@@ -0,0 +1,134 @@
+package credential
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+)
+
+const svgMediaType = "image/svg+xml"
+
+// ErrNoRenderTemplate is returned when rendering a credential whose render method has no template the service can
+// render.
+var ErrNoRenderTemplate = errors.New("credential has no render template")
+
+// renderPlaceholder matches placeholders such as `{{credentialSubject.firstName}}` in render templates.
+var renderPlaceholder = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+type GetCredentialRenderRequest struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type GetCredentialRenderResponse struct {
+	// MediaType of Render, such as `image/svg+xml`.
+	MediaType string
+	Render    []byte
+}
+
+// GetCredentialRender renders a credential with the SVG template of its render method, for clients which can't render
+// the credential themselves.
+func (s Service) GetCredentialRender(ctx context.Context, request GetCredentialRenderRequest) (*GetCredentialRenderResponse, error) {
+	logrus.Debugf("rendering credential: %s", request.ID)
+
+	gotCred, err := s.storage.GetCredential(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
+	}
+
+	renderMethod := gotCred.RenderMethod
+	if renderMethod == nil || renderMethod.Type != credint.SVGRenderingTemplate || renderMethod.Template == "" {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrNoRenderTemplate, "credential<%s>", request.ID))
+	}
+	claims, err := credentialClaims(*gotCred.Credential)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting claims of credential<%s>", request.ID)
+	}
+	return &GetCredentialRenderResponse{
+		MediaType: svgMediaType,
+		Render:    []byte(renderSVGTemplate(renderMethod.Template, claims)),
+	}, nil
+}
+
+// credentialClaims returns the credential as a generic map, keeping numbers as they were issued.
+func credentialClaims(cred credential.VerifiableCredential) (map[string]any, error) {
+	credBytes, err := json.Marshal(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling credential")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(credBytes))
+	decoder.UseNumber()
+	var claims map[string]any
+	if err = decoder.Decode(&claims); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling credential")
+	}
+	return claims, nil
+}
+
+// renderSVGTemplate replaces each placeholder of the template with the claim at its dot separated path, escaped for
+// XML. Array elements are addressed by their index, e.g. `{{evidence.0.id}}`. Placeholders of claims the credential
+// does not have are replaced with an empty string.
+func renderSVGTemplate(template string, claims map[string]any) string {
+	return renderPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		path := renderPlaceholder.FindStringSubmatch(placeholder)[1]
+		value, ok := claimAt(claims, strings.Split(path, "."))
+		if !ok {
+			return ""
+		}
+		var escaped bytes.Buffer
+		_ = xml.EscapeText(&escaped, []byte(claimString(value)))
+		return escaped.String()
+	})
+}
+
+func claimAt(value any, path []string) (any, bool) {
+	for _, segment := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+// claimString formats scalar claims as they are, and objects or arrays as JSON.
+func claimString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]any, []any:
+		valueBytes, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(valueBytes)
+	default:
+		return fmt.Sprint(v)
+	}
+}
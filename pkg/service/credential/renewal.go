@@ -0,0 +1,354 @@
+package credential
+
+import (
+	"context"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ErrInvalidAutoRenew is returned when creating a credential with an auto-renew policy which cannot be applied to it.
+var ErrInvalidAutoRenew = errors.New("invalid auto-renew policy")
+
+// Reasons a renewal chain is no longer renewed.
+const (
+	RenewalStoppedCredentialRevoked = "credential revoked"
+	RenewalStoppedPolicyExpired     = "policy expired"
+	RenewalStoppedMaxRenewals       = "maximum renewals reached"
+	RenewalStoppedIssuerKeyRevoked  = "issuer key revoked"
+)
+
+// renewalChain is the state of the credentials re-issued in place of one another under an auto-renew policy. It is
+// stored under the ID of the first credential of the chain, which is the ID of the chain.
+type renewalChain struct {
+	ID string `json:"id"`
+	// Request is the request the credentials of the chain are re-issued with, their expiry aside.
+	Request CreateCredentialRequest `json:"request"`
+	// Validity is how long after its issuance each credential of the chain expires.
+	Validity time.Duration `json:"validity"`
+	// CredentialIDs are the IDs of the credentials of the chain, oldest first.
+	CredentialIDs []string `json:"credentialIds"`
+	// IssuedAt is when the last credential of the chain was issued, in RFC3339 format.
+	IssuedAt string `json:"issuedAt"`
+	// StoppedReason is why the chain is no longer renewed, if it is not.
+	StoppedReason string `json:"stoppedReason,omitempty"`
+}
+
+func (rc renewalChain) currentID() string {
+	return rc.CredentialIDs[len(rc.CredentialIDs)-1]
+}
+
+type GetCredentialHistoryRequest struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type GetCredentialHistoryResponse struct {
+	// Credentials of the renewal chain of the credential, oldest first. Only the credential itself when it was not
+	// created with an auto-renew policy.
+	Credentials []credint.Container `json:"credentials"`
+	// Why the chain is no longer renewed, such as "credential revoked". Empty while it is renewed.
+	StoppedReason string `json:"stoppedReason,omitempty"`
+}
+
+// checkAutoRenew rejects a request with an auto-renew policy which cannot be applied to the requested credential.
+// Credentials are re-issued before they expire, so the policy requires an expiry later than its renewal period.
+func (s Service) checkAutoRenew(request CreateCredentialRequest) error {
+	policy := request.AutoRenew
+	if policy == nil {
+		return nil
+	}
+	every, err := time.ParseDuration(policy.Every)
+	if err != nil || every <= 0 {
+		return sdkutil.LoggingError(errors.Wrapf(ErrInvalidAutoRenew, "every<%s> is not a positive duration", policy.Every))
+	}
+	if request.Expiry == "" {
+		return sdkutil.LoggingError(errors.Wrap(ErrInvalidAutoRenew, "credential has no expiry"))
+	}
+	expiresAt, err := time.Parse(time.RFC3339, request.Expiry)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not parse expiry for credential: %s", request.Expiry)
+	}
+	if !s.Clock.Now().Add(every).Before(expiresAt) {
+		return sdkutil.LoggingError(errors.Wrapf(ErrInvalidAutoRenew, "credential expires at %s, before it is renewed every %s", request.Expiry, policy.Every))
+	}
+	if policy.Until != "" {
+		if _, err = time.Parse(time.RFC3339, policy.Until); err != nil {
+			return sdkutil.LoggingError(errors.Wrapf(ErrInvalidAutoRenew, "could not parse until<%s>", policy.Until))
+		}
+	}
+	if policy.RevokePrevious && !request.Revocable {
+		return sdkutil.LoggingError(errors.Wrap(ErrInvalidAutoRenew, "previous credentials cannot be revoked, since the credential is not revocable"))
+	}
+	return nil
+}
+
+// renewalChainWatchKeys returns the key of the renewal chain a credential is re-issued into, so that a credential is
+// not re-issued twice by concurrent renewals.
+func (s Service) renewalChainWatchKeys(request CreateCredentialRequest) []storage.WatchKey {
+	if request.renewalChainID == "" {
+		return nil
+	}
+	return []storage.WatchKey{{Namespace: credentialRenewalNamespace, Key: request.renewalChainID}}
+}
+
+// storeRenewalChainTx starts a renewal chain with a credential created with an auto-renew policy, or appends a
+// re-issued credential to its chain.
+func (s Service) storeRenewalChainTx(ctx context.Context, tx storage.Tx, request CreateCredentialRequest, container credint.Container) error {
+	if request.AutoRenew == nil {
+		return nil
+	}
+	if request.renewalChainID == "" {
+		issuedAt, err := time.Parse(time.RFC3339, container.Credential.IssuanceDate)
+		if err != nil {
+			return errors.Wrap(err, "parsing issuance date")
+		}
+		expiresAt, err := time.Parse(time.RFC3339, container.Credential.ExpirationDate)
+		if err != nil {
+			return errors.Wrap(err, "parsing expiration date")
+		}
+		chain := renewalChain{
+			ID:            container.ID,
+			Request:       request,
+			Validity:      expiresAt.Sub(issuedAt),
+			CredentialIDs: []string{container.ID},
+			IssuedAt:      container.Credential.IssuanceDate,
+		}
+		return s.storage.storeRenewalChain(ctx, tx, chain)
+	}
+
+	chain, err := s.storage.getRenewalChain(ctx, request.renewalChainID)
+	if err != nil {
+		return err
+	}
+	if chain == nil {
+		return sdkutil.LoggingNewErrorf("renewal chain<%s> does not exist", request.renewalChainID)
+	}
+	chain.CredentialIDs = append(chain.CredentialIDs, container.ID)
+	chain.IssuedAt = container.Credential.IssuanceDate
+	return s.storage.storeRenewalChain(ctx, tx, *chain)
+}
+
+// RenewalInterval returns how often credentials are checked for renewal, or 0 when they are not renewed.
+func (s Service) RenewalInterval() time.Duration {
+	return s.renewalInterval
+}
+
+// RunCredentialRenewal re-issues the credentials due for renewal every renewal interval, until the context is done. It
+// returns immediately when credentials are not renewed.
+func (s Service) RunCredentialRenewal(ctx context.Context) {
+	if s.renewalInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.renewalInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RenewCredentials(ctx); err != nil {
+				logrus.WithError(err).Error("renewing credentials")
+			}
+		}
+	}
+}
+
+// RenewCredentials re-issues the last credential of each renewal chain whose renewal period has passed since it was
+// issued, with the data and schema of the first credential of the chain and the same validity. A chain is stopped,
+// and never renewed again, once its credential is revoked, its policy expires, its maximum renewals are reached, or
+// the key its credentials are issued with is revoked. A chain which fails to be renewed is logged, and does not
+// prevent the others from being renewed. The re-issued credentials are returned.
+func (s Service) RenewCredentials(ctx context.Context) ([]credint.Container, error) {
+	chains, err := s.storage.listRenewalChains(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing renewal chains")
+	}
+
+	renewed := make([]credint.Container, 0)
+	for _, chain := range chains {
+		if chain.StoppedReason != "" {
+			continue
+		}
+		container, err := s.renewCredential(ctx, chain)
+		if err != nil {
+			logrus.WithError(err).Errorf("renewing credential<%s> of renewal chain<%s>", chain.currentID(), chain.ID)
+			continue
+		}
+		if container != nil {
+			logrus.Debugf("renewed credential<%s> with credential<%s>", chain.currentID(), container.ID)
+			renewed = append(renewed, *container)
+		}
+	}
+	return renewed, nil
+}
+
+// renewCredential re-issues the last credential of the chain when it is due, or stops the chain. It returns nil when
+// no credential was re-issued.
+func (s Service) renewCredential(ctx context.Context, chain renewalChain) (*credint.Container, error) {
+	current, err := s.storage.GetCredential(ctx, chain.currentID())
+	if err != nil {
+		return nil, errors.Wrap(err, "getting credential")
+	}
+	now := s.Clock.Now()
+	reason, err := s.renewalStoppedReason(ctx, chain, *current, now)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		logrus.Infof("stopping renewal chain<%s>: %s", chain.ID, reason)
+		chain.StoppedReason = reason
+		return nil, s.storage.storeRenewalChain(ctx, s.storage.db, chain)
+	}
+
+	issuedAt, err := time.Parse(time.RFC3339, chain.IssuedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing issuance date")
+	}
+	every, err := time.ParseDuration(chain.Request.AutoRenew.Every)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing renewal period")
+	}
+	if now.Before(issuedAt.Add(every)) {
+		return nil, nil
+	}
+
+	// the chain is a single entitlement, which the subject holds one credential of at a time
+	request := chain.Request
+	request.Expiry = now.Add(chain.Validity).Format(time.RFC3339)
+	request.UniqueSubject = false
+	request.ReplaceExisting = false
+	request.previousCredential = current.LocalCredentialID
+	request.renewalChainID = chain.ID
+	created, err := s.CreateCredential(ctx, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "re-issuing credential")
+	}
+
+	if chain.Request.AutoRenew.RevokePrevious {
+		revokeRequest := UpdateCredentialStatusRequest{
+			ID:        current.LocalCredentialID,
+			Revoked:   true,
+			Reason:    "renewed by credential " + created.ID,
+			Principal: chain.Request.CreatedBy,
+		}
+		if _, err = s.UpdateCredentialStatus(ctx, revokeRequest); err != nil {
+			logrus.WithError(err).Errorf("revoking credential<%s> renewed by credential<%s>", current.LocalCredentialID, created.ID)
+		}
+	}
+	return &created.Container, nil
+}
+
+// renewalStoppedReason returns why the chain is no longer renewed, or an empty string when it still is.
+func (s Service) renewalStoppedReason(ctx context.Context, chain renewalChain, current StoredCredential, now time.Time) (string, error) {
+	policy := chain.Request.AutoRenew
+	if current.Revoked {
+		return RenewalStoppedCredentialRevoked, nil
+	}
+	if policy.MaxRenewals > 0 && len(chain.CredentialIDs)-1 >= policy.MaxRenewals {
+		return RenewalStoppedMaxRenewals, nil
+	}
+	if policy.Until != "" {
+		until, err := time.Parse(time.RFC3339, policy.Until)
+		if err != nil {
+			return "", errors.Wrap(err, "parsing until")
+		}
+		if !now.Before(until) {
+			return RenewalStoppedPolicyExpired, nil
+		}
+	}
+	gotKey, err := s.keyStore.GetKeyDetails(ctx, keystore.GetKeyDetailsRequest{ID: current.FullyQualifiedVerificationMethodID})
+	if err != nil {
+		return "", errors.Wrap(err, "getting issuer key")
+	}
+	if gotKey.Revoked {
+		return RenewalStoppedIssuerKeyRevoked, nil
+	}
+	return "", nil
+}
+
+// GetCredentialHistory returns the renewal chain the given credential belongs to.
+func (s Service) GetCredentialHistory(ctx context.Context, request GetCredentialHistoryRequest) (*GetCredentialHistoryResponse, error) {
+	logrus.Debugf("getting history of credential: %s", request.ID)
+
+	// the ID of a chain is the ID of its first credential, which is found by following each previous credential
+	chainID := request.ID
+	for {
+		gotCred, err := s.storage.GetCredentialIfExists(ctx, chainID)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", chainID)
+		}
+		if gotCred == nil || gotCred.LocalCredentialID != chainID {
+			return nil, sdkutil.LoggingError(errors.Wrapf(ErrCredentialNotFound, "credential<%s>", chainID))
+		}
+		if gotCred.PreviousCredential == "" {
+			break
+		}
+		chainID = gotCred.PreviousCredential
+	}
+
+	chain, err := s.storage.getRenewalChain(ctx, chainID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get renewal chain of credential: %s", request.ID)
+	}
+	credentialIDs := []string{request.ID}
+	var response GetCredentialHistoryResponse
+	if chain != nil {
+		credentialIDs = chain.CredentialIDs
+		response.StoppedReason = chain.StoppedReason
+	}
+	for _, id := range credentialIDs {
+		gotCred, err := s.GetCredential(ctx, GetCredentialRequest{ID: id})
+		if err != nil {
+			return nil, err
+		}
+		response.Credentials = append(response.Credentials, gotCred.Container)
+	}
+	return &response, nil
+}
+
+func (cs *Storage) storeRenewalChain(ctx context.Context, tx storage.Tx, chain renewalChain) error {
+	chainBytes, err := json.Marshal(chain)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling renewal chain<%s>", chain.ID)
+	}
+	return tx.Write(ctx, credentialRenewalNamespace, chain.ID, chainBytes)
+}
+
+// getRenewalChain returns the renewal chain with the given ID, or nil when there is none.
+func (cs *Storage) getRenewalChain(ctx context.Context, id string) (*renewalChain, error) {
+	chainBytes, err := cs.db.Read(ctx, credentialRenewalNamespace, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading renewal chain<%s>", id)
+	}
+	if len(chainBytes) == 0 {
+		return nil, nil
+	}
+	var chain renewalChain
+	if err = json.Unmarshal(chainBytes, &chain); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling renewal chain<%s>", id)
+	}
+	return &chain, nil
+}
+
+func (cs *Storage) listRenewalChains(ctx context.Context) ([]renewalChain, error) {
+	chainsBytes, err := cs.db.ReadAll(ctx, credentialRenewalNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading renewal chains")
+	}
+	chains := make([]renewalChain, 0, len(chainsBytes))
+	for id, chainBytes := range chainsBytes {
+		var chain renewalChain
+		if err = json.Unmarshal(chainBytes, &chain); err != nil {
+			return nil, errors.Wrapf(err, "unmarshalling renewal chain<%s>", id)
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
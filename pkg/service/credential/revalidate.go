@@ -0,0 +1,90 @@
+package credential
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/sirupsen/logrus"
+	"go.einride.tech/aip/filtering"
+
+	schemaint "github.com/tbd54566975/ssi-service/internal/schema"
+	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
+	"github.com/tbd54566975/ssi-service/pkg/service/common"
+)
+
+// revalidationBatchSize bounds how many credentials are read at once when revalidating without a page size, so that
+// large sets of credentials are streamed through rather than loaded in memory.
+const revalidationBatchSize = 100
+
+type RevalidationFailure struct {
+	// ID of the credential within the service.
+	ID string `json:"id"`
+	// Why the credential no longer complies with the schema.
+	Reason string `json:"reason"`
+}
+
+type RevalidateCredentialsForSchemaResponse struct {
+	// Checked is the number of credentials of the schema which were revalidated.
+	Checked int `json:"checked"`
+	// Failures lists the credentials which no longer comply with the schema.
+	Failures []RevalidationFailure `json:"failures,omitempty"`
+	// NextPageToken is used to revalidate the next page of credentials. Empty when all credentials were revalidated.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// FailedIDs returns the IDs of the credentials which no longer comply with the schema.
+func (r RevalidateCredentialsForSchemaResponse) FailedIDs() []string {
+	ids := make([]string, 0, len(r.Failures))
+	for _, failure := range r.Failures {
+		ids = append(ids, failure.ID)
+	}
+	return ids
+}
+
+// RevalidateCredentialsForSchema validates the credentials referencing the schema against its current version, such as
+// after a schema fix, and returns those which no longer comply. It is a read-only audit: failing credentials are left
+// untouched. Pages of the requested size are counted over all stored credentials, so a page may hold fewer credentials
+// of the schema. Without a page size, every credential is revalidated, reading them in batches.
+func (s Service) RevalidateCredentialsForSchema(ctx context.Context, schemaID string, request pagination.PageRequest) (*RevalidateCredentialsForSchemaResponse, error) {
+	logrus.Debugf("revalidating credentials of schema: %s", schemaID)
+
+	currentSchema, _, err := s.schema.Resolve(ctx, schemaID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get schema: %s", schemaID)
+	}
+
+	page := request.ToServicePage()
+	streamAll := page.Size == -1
+	if streamAll {
+		page.Size = revalidationBatchSize
+	}
+
+	var response RevalidateCredentialsForSchemaResponse
+	for {
+		gotCreds, err := s.storage.ListCredentials(ctx, filtering.Filter{}, page, s.expiryCutoff())
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not list credentials")
+		}
+		for _, cred := range gotCreds.StoredCredentials {
+			if cred.Schema != schemaID || cred.Credential == nil {
+				continue
+			}
+			response.Checked++
+			if err = schemaint.IsCredentialValidForJSONSchema(*cred.Credential, *currentSchema, s.config.SchemaFormatAssertions...); err != nil {
+				response.Failures = append(response.Failures, RevalidationFailure{
+					ID:     cred.LocalCredentialID,
+					Reason: err.Error(),
+				})
+			}
+		}
+
+		if !streamAll || gotCreds.NextPageToken == "" {
+			response.NextPageToken = gotCreds.NextPageToken
+			break
+		}
+		page = &common.Page{Token: gotCreds.NextPageToken, Size: revalidationBatchSize}
+	}
+
+	logrus.Debugf("revalidated %d credentials of schema<%s>, %d no longer comply", response.Checked, schemaID, len(response.Failures))
+	return &response, nil
+}
@@ -0,0 +1,144 @@
+package credential
+
+import (
+	"context"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ErrSchemaCredentialRevoked is returned when creating a credential against a JsonSchemaCredential schema whose schema
+// credential is revoked, or whose revocation status cannot be checked.
+var ErrSchemaCredentialRevoked = errors.New("schema credential is revoked")
+
+// IssueRevocableSchemaCredential adds a revocation status entry to a schema credential, signs it with the key of the
+// verification method, and stores it as the credential with the given ID, so that it is revoked like other
+// credentials. The status list is the one of the issuer's credentials without a schema.
+func (s Service) IssueRevocableSchemaCredential(ctx context.Context, id string, cred credential.VerifiableCredential, fullyQualifiedVerificationMethodID string) (*keyaccess.JWT, error) {
+	request := CreateCredentialRequest{
+		Issuer:                             cred.IssuerID(),
+		FullyQualifiedVerificationMethodID: fullyQualifiedVerificationMethodID,
+		Revocable:                          true,
+	}
+	if err := s.checkIssuerNotFrozen(ctx, request.Issuer); err != nil {
+		return nil, err
+	}
+	if err := s.checkVerificationMethod(ctx, request); err != nil {
+		return nil, err
+	}
+
+	purpose := string(statussdk.StatusRevocation)
+	statusMetadata := StatusListCredentialMetadata{
+		statusListCredentialWatchKey:   s.storage.GetStatusListCredentialWatchKey(request.Issuer, "", purpose),
+		statusListIndexPoolWatchKey:    s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, "", purpose),
+		statusListCurrentIndexWatchKey: s.storage.GetStatusListCurrentIndexWatchKey(request.Issuer, "", purpose),
+	}
+	watchKeys := []storage.WatchKey{
+		statusMetadata.statusListCredentialWatchKey,
+		statusMetadata.statusListIndexPoolWatchKey,
+		statusMetadata.statusListCurrentIndexWatchKey,
+	}
+
+	issueFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		statusEntry, err := s.createStatusListEntryForCredential(ctx, id, request, tx, statusMetadata)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not create status list entry for schema credential")
+		}
+		cred.CredentialStatus = statusEntry
+		credJWT, err := s.signCredentialJWT(ctx, fullyQualifiedVerificationMethodID, cred, s.jwtClaimsPolicy, nil)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "signing schema credential")
+		}
+		container := credint.Container{
+			ID:                                 id,
+			FullyQualifiedVerificationMethodID: fullyQualifiedVerificationMethodID,
+			Credential:                         &cred,
+			CredentialJWT:                      credJWT,
+		}
+		if err = s.storage.StoreCredentialTx(ctx, tx, StoreCredentialRequest{Container: container}); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "saving schema credential")
+		}
+		if err = s.writeCreatedEvent(ctx, tx, CreateCredentialResponse{Container: container}); err != nil {
+			return nil, err
+		}
+		return credJWT, nil
+	}
+	returnValue, err := s.execute(ctx, issueFunc, watchKeys)
+	if err != nil {
+		err = statusListConflict(err, StatusListResource{Issuer: request.Issuer, Purpose: purpose})
+		return nil, errors.Wrapf(err, "issuing schema credential<%s>", cred.ID)
+	}
+	credJWT, ok := returnValue.(*keyaccess.JWT)
+	if !ok {
+		return nil, errors.New("problem casting to JWT")
+	}
+	return credJWT, nil
+}
+
+// checkSchemaCredentialStatus rejects creating credentials against a JsonSchemaCredential schema whose schema
+// credential is revoked. Revocation entries with a status list stored by the service are checked locally, and others
+// against the status list fetched from their URL. A status which cannot be checked is treated as revoked.
+func (s Service) checkSchemaCredentialStatus(ctx context.Context, schemaID string) error {
+	if s.config.AllowRevokedSchemaCredentials {
+		return nil
+	}
+	gotSchema, err := s.schema.GetSchema(ctx, schema.GetSchemaRequest{ID: schemaID})
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "getting schema<%s>", schemaID)
+	}
+	if gotSchema.CredentialSchema == nil {
+		return nil
+	}
+	schemaCred, err := credint.ParseVerifiableCredentialFromJWT(gotSchema.CredentialSchema.String())
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "parsing schema credential of schema<%s>", schemaID)
+	}
+
+	for _, status := range statusEntries(schemaCred.CredentialStatus) {
+		entry, err := toStatusList2021Entry(status)
+		if err != nil || entry.StatusPurpose != statussdk.StatusRevocation {
+			continue
+		}
+		revoked, err := s.isRevokedInStatusList(ctx, *schemaCred, *entry)
+		if err != nil {
+			return sdkutil.LoggingError(errors.Wrapf(ErrSchemaCredentialRevoked, "status of schema credential<%s> in status list<%s> cannot be checked: %s", schemaCred.ID, entry.StatusListCredential, err.Error()))
+		}
+		if revoked {
+			return sdkutil.LoggingError(errors.Wrapf(ErrSchemaCredentialRevoked, "schema credential<%s> is revoked in status list<%s>", schemaCred.ID, entry.StatusListCredential))
+		}
+	}
+	return nil
+}
+
+// isRevokedInStatusList returns whether the revocation entry of a credential is set in its status list, which is read
+// from storage when the service stores it, and fetched otherwise.
+func (s Service) isRevokedInStatusList(ctx context.Context, cred credential.VerifiableCredential, entry statussdk.StatusList2021Entry) (bool, error) {
+	result, err := s.statusChecker.checkStatusEntry(ctx, cred, entry, nil)
+	if err != nil {
+		return false, err
+	}
+	if result != nil {
+		return result.Set, nil
+	}
+
+	logrus.Debugf("fetching status list credential<%s> of credential<%s>", entry.StatusListCredential, cred.ID)
+	statusList, _, err := s.fetchStatusListCredential(ctx, entry.StatusListCredential, cred.IssuerID())
+	if err != nil {
+		return false, err
+	}
+	// the parsed entry is evaluated, since the sdk cannot evaluate entries with missing or non-string properties
+	cred.CredentialStatus = entry
+	set, err := statussdk.ValidateCredentialInStatusList(cred, *statusList)
+	if err != nil {
+		return false, errors.Wrap(err, "checking revocation status")
+	}
+	return set, nil
+}
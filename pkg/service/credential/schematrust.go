@@ -0,0 +1,66 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// SchemaTrustResult is the result of checking that the schema of a credential is a JsonSchemaCredential issued by one
+// of the trusted schema authorities.
+type SchemaTrustResult struct {
+	SchemaID string `json:"schemaId"`
+	// Issuer of the JsonSchemaCredential of the schema, once its signature is verified.
+	Issuer  string `json:"issuer,omitempty"`
+	Trusted bool   `json:"trusted"`
+	// Why the schema is not trusted.
+	Reason string `json:"reason,omitempty"`
+}
+
+// checkSchemaTrust returns whether the schema of a credential is a JsonSchemaCredential stored by the service, whose
+// signature verifies, and which is issued by one of the configured trusted schema authorities. It returns nil for a
+// credential without a schema.
+func (s Service) checkSchemaTrust(ctx context.Context, cred credential.VerifiableCredential) *SchemaTrustResult {
+	if cred.CredentialSchema == nil {
+		return nil
+	}
+	result := SchemaTrustResult{SchemaID: cred.CredentialSchema.ID}
+	gotSchema, err := s.schema.GetSchema(ctx, schema.GetSchemaRequest{ID: cred.CredentialSchema.ID})
+	if err != nil {
+		result.Reason = fmt.Sprintf("schema<%s> is not stored by the service", cred.CredentialSchema.ID)
+		return &result
+	}
+	if gotSchema.Type != schemalib.JSONSchemaCredentialType || gotSchema.CredentialSchema == nil {
+		result.Reason = fmt.Sprintf("schema<%s> is a %s, which is not issued by an authority", cred.CredentialSchema.ID, gotSchema.Type)
+		return &result
+	}
+	issuer, err := s.verifier.VerifySchemaCredential(ctx, *gotSchema.CredentialSchema)
+	if err != nil {
+		result.Reason = fmt.Sprintf("schema<%s> could not be verified: %s", cred.CredentialSchema.ID, err.Error())
+		return &result
+	}
+	result.Issuer = issuer
+	if !sdkutil.Contains(issuer, s.config.TrustedSchemaAuthorities) {
+		result.Reason = fmt.Sprintf("schema<%s> is issued by %s, which is not a trusted schema authority", cred.CredentialSchema.ID, issuer)
+		return &result
+	}
+	result.Trusted = true
+	return &result
+}
+
+// schemaTrustFailure returns the response of a credential whose schema is not trusted.
+func schemaTrustFailure(result SchemaTrustResult, statusResults []StatusResult) *VerifyCredentialResponse {
+	return &VerifyCredentialResponse{
+		Verified:      false,
+		Reason:        fmt.Sprintf("%s: %s", verification.UntrustedSchema, result.Reason),
+		ReasonCode:    verification.UntrustedSchema,
+		StatusResults: statusResults,
+		SchemaTrust:   &result,
+	}
+}
@@ -0,0 +1,61 @@
+package credential
+
+import (
+	"context"
+
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// pinnedSchemaResolver resolves a schema at the version a credential was created against, and other schemas at their
+// current version.
+type pinnedSchemaResolver struct {
+	schema   *schema.Service
+	schemaID string
+	version  int
+}
+
+func (r pinnedSchemaResolver) Resolve(ctx context.Context, id string) (*schemalib.JSONSchema, schemalib.VCJSONSchemaType, error) {
+	if id != r.schemaID {
+		return r.schema.Resolve(ctx, id)
+	}
+	resolved, schemaType, _, err := r.schema.ResolveVersion(ctx, id, r.version)
+	return resolved, schemaType, err
+}
+
+// schemaVersionVerifier returns the verifier of the credential of the request. A credential stored by the service is
+// validated against the version of its schema it was created against, rather than the current one, so that replacing
+// a schema does not change the outcome of verifying the credentials created before.
+func (s Service) schemaVersionVerifier(ctx context.Context, request VerifyCredentialRequest) *verification.Verifier {
+	cred := request.DataIntegrityCredential
+	if request.CredentialJWT != nil {
+		parsed, err := credint.ParseVerifiableCredentialFromJWT(request.CredentialJWT.String())
+		if err != nil {
+			// the verifier reports the malformed credential
+			return s.verifier
+		}
+		cred = parsed
+	}
+	if cred == nil || cred.ID == "" || cred.CredentialSchema == nil {
+		return s.verifier
+	}
+
+	stored, err := s.storage.GetCredentialIfExists(ctx, cred.ID)
+	if err != nil {
+		logrus.WithError(err).Warnf("could not look up the schema version of credential<%s>", cred.ID)
+		return s.verifier
+	}
+	if stored == nil || stored.SchemaVersion == 0 || stored.Credential == nil ||
+		stored.Credential.ID != cred.ID || stored.Schema != cred.CredentialSchema.ID {
+		return s.verifier
+	}
+	return s.verifier.WithSchemaResolver(pinnedSchemaResolver{
+		schema:   s.schema,
+		schemaID: stored.Schema,
+		version:  stored.SchemaVersion,
+	})
+}
@@ -3,27 +3,38 @@ package credential
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
 	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	"github.com/TBD54566975/ssi-sdk/cryptosuite"
 	"github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/benbjohnson/clock"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/tbd54566975/ssi-service/config"
 	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	schemaint "github.com/tbd54566975/ssi-service/internal/schema"
+	"github.com/tbd54566975/ssi-service/internal/signing"
+	"github.com/tbd54566975/ssi-service/internal/util"
 	"github.com/tbd54566975/ssi-service/internal/verification"
 	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
+	"github.com/tbd54566975/ssi-service/pkg/service/analytics"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 	"github.com/tbd54566975/ssi-service/pkg/service/schema"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 	"go.einride.tech/aip/filtering"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type Service struct {
@@ -31,9 +42,72 @@ type Service struct {
 	config   config.CredentialServiceConfig
 	verifier *verification.Verifier
 
+	// publisher is nil when status lists are not published to an external store
+	publisher    StatusListPublisher
+	publications metric.Int64Counter
+	lowCapacity  metric.Int64Counter
+
+	// refreshInterval is 0 when status lists are not re-signed on a schedule
+	refreshInterval time.Duration
+	refreshValidity time.Duration
+	refreshes       metric.Int64Counter
+
+	// hostingCheckInterval is 0 when the hosting of status lists is only checked at startup
+	hostingCheckInterval time.Duration
+	hosting              *statusListHosting
+	hostingChecks        metric.Int64Counter
+
+	// consistencyCheckInterval is 0 when the consistency of statuses is only checked through the admin API
+	consistencyCheckInterval time.Duration
+	statusMismatches         metric.Int64Counter
+	// statusMismatch is nil when status mismatches are not notified
+	statusMismatch StatusMismatchFunc
+
+	// renewalInterval is 0 when credentials are not auto-renewed
+	renewalInterval time.Duration
+	// batchTimeout is 0 when batches are only bounded by their request
+	batchTimeout time.Duration
+
+	// maxValidity is 0 when the validity of credentials is not bounded
+	maxValidity time.Duration
+	// expirySkew is how long after their expiration date credentials are still active
+	expirySkew time.Duration
+	// jwtClaimsPolicy sets the registered claims of the JWTs of credentials created without a profile of their own, and
+	// of status list credentials
+	jwtClaimsPolicy keyaccess.JWTClaimsPolicy
+
+	// verification methods signing status lists other than the ones their credentials are issued with
+	statusListSigners statusListSigners
+	// signingPool is nil when signing is not bounded
+	signingPool *signing.Pool
+
+	// status list credentials of the issuers of imported credentials
+	externalStatusLists       *statusListCache
+	staleStatusLists          metric.Int64Counter
+	statusListRefreshFailures metric.Int64Counter
+	httpClient                *http.Client
+	// offline is true when external status lists are not fetched
+	offline bool
+
+	statusChecker StatusChecker
+	// verificationFailed is nil when failed verifications are not notified
+	verificationFailed verification.FailureFunc
+	// displayNames is nil when issuers cannot be expanded
+	displayNames DisplayNames
+	// delegations is nil when credentials can only be signed with keys of their issuer
+	delegations Delegations
+	// outbox is true when events about credentials are written to the webhook outbox
+	outbox bool
+	// analytics is nil when analytics are disabled
+	analytics *analytics.Emitter
+
+	// Clock is the time credentials are issued at, and auto-renewed against
+	Clock clock.Clock
+
 	// external dependencies
-	keyStore *keystore.Service
-	schema   *schema.Service
+	keyStore    *keystore.Service
+	schema      *schema.Service
+	didResolver resolution.Resolver
 }
 
 func (s Service) Type() framework.Type {
@@ -60,7 +134,11 @@ func (s Service) Status() framework.Status {
 			Message: fmt.Sprintf("credential service is not ready: %s", ae.Error().Error()),
 		}
 	}
-	return framework.Status{Status: framework.StatusReady}
+	status := framework.Status{Status: framework.StatusReady}
+	if health := s.hosting.get(); health != nil && len(health.Mismatches) > 0 {
+		status.Details = map[string]any{"statusListHosting": health}
+	}
+	return status
 }
 
 func (s Service) Config() config.CredentialServiceConfig {
@@ -73,16 +151,153 @@ func NewCredentialService(config config.CredentialServiceConfig, s storage.Servi
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the credential service")
 	}
-	verifier, err := verification.NewVerifiableDataVerifier(didResolver, schema)
+	verifier, err := verification.NewVerifiableDataVerifier(didResolver, schema, verification.WithAssertedFormats(config.SchemaFormatAssertions...))
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate verifier for the credential service")
 	}
+	publisher, err := NewStatusListPublisher(config.StatusListPublisher)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate status list publisher for the credential service")
+	}
+	publications, err := newPublicationsCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate status list publication metrics")
+	}
+	lowCapacity, err := newLowCapacityCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate status list capacity metrics")
+	}
+	var refreshInterval, refreshValidity time.Duration
+	if config.StatusListRefreshInterval != "" {
+		if refreshInterval, err = time.ParseDuration(config.StatusListRefreshInterval); err != nil || refreshInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid status list refresh interval: %s", config.StatusListRefreshInterval)
+		}
+	}
+	if config.StatusListRefreshValidity != "" {
+		if refreshValidity, err = time.ParseDuration(config.StatusListRefreshValidity); err != nil || refreshValidity <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid status list refresh validity: %s", config.StatusListRefreshValidity)
+		}
+	}
+	var renewalInterval time.Duration
+	if config.RenewalInterval != "" {
+		if renewalInterval, err = time.ParseDuration(config.RenewalInterval); err != nil || renewalInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid renewal interval: %s", config.RenewalInterval)
+		}
+	}
+	var batchTimeout time.Duration
+	if config.BatchTimeout != "" {
+		if batchTimeout, err = time.ParseDuration(config.BatchTimeout); err != nil || batchTimeout <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid batch timeout: %s", config.BatchTimeout)
+		}
+	}
+	var maxValidity time.Duration
+	if config.MaxValidityDuration != "" {
+		if maxValidity, err = time.ParseDuration(config.MaxValidityDuration); err != nil || maxValidity <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid max validity duration: %s", config.MaxValidityDuration)
+		}
+	}
+	var expirySkew time.Duration
+	if config.ExpiryClockSkew != "" {
+		if expirySkew, err = time.ParseDuration(config.ExpiryClockSkew); err != nil || expirySkew < 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid expiry clock skew: %s", config.ExpiryClockSkew)
+		}
+	}
+	jwtClaimsPolicy, err := keyaccess.GetJWTClaimsPolicy(config.JWTClaimsProfile)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid jwt claims profile")
+	}
+	var hostingCheckInterval time.Duration
+	if config.StatusListHostingCheckInterval != "" {
+		if hostingCheckInterval, err = time.ParseDuration(config.StatusListHostingCheckInterval); err != nil || hostingCheckInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid status list hosting check interval: %s", config.StatusListHostingCheckInterval)
+		}
+	}
+	var consistencyCheckInterval time.Duration
+	if config.StatusConsistencyCheckInterval != "" {
+		if consistencyCheckInterval, err = time.ParseDuration(config.StatusConsistencyCheckInterval); err != nil || consistencyCheckInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid status consistency check interval: %s", config.StatusConsistencyCheckInterval)
+		}
+	}
+	var externalStatusListCacheTTL time.Duration
+	if config.ExternalStatusListCacheTTL != "" {
+		if externalStatusListCacheTTL, err = time.ParseDuration(config.ExternalStatusListCacheTTL); err != nil || externalStatusListCacheTTL < 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid external status list cache ttl: %s", config.ExternalStatusListCacheTTL)
+		}
+	}
+	var externalStatusListMaxStaleness time.Duration
+	if config.ExternalStatusListMaxStaleness != "" {
+		if externalStatusListMaxStaleness, err = time.ParseDuration(config.ExternalStatusListMaxStaleness); err != nil || externalStatusListMaxStaleness < 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid external status list max staleness: %s", config.ExternalStatusListMaxStaleness)
+		}
+	}
+	var externalStatusListNotFoundTTL time.Duration
+	if config.ExternalStatusListNotFoundTTL != "" {
+		if externalStatusListNotFoundTTL, err = time.ParseDuration(config.ExternalStatusListNotFoundTTL); err != nil || externalStatusListNotFoundTTL < 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid external status list not found ttl: %s", config.ExternalStatusListNotFoundTTL)
+		}
+	}
+	refreshes, err := newRefreshesCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate status list refresh metrics")
+	}
+	hostingChecks, err := newHostingChecksCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate status list hosting check metrics")
+	}
+	statusMismatches, err := newStatusMismatchesCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate status consistency check metrics")
+	}
+	staleStatusLists, err := newStaleStatusListsCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate external status list metrics")
+	}
+	statusListRefreshFailures, err := newStatusListRefreshFailuresCounter()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate external status list metrics")
+	}
+	statusListSigners, err := newStatusListSigners(config.StatusListSigningKeys)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid status list signing keys")
+	}
+	if config.VerificationReceiptSigner != "" && !strings.Contains(config.VerificationReceiptSigner, "#") {
+		return nil, sdkutil.LoggingNewErrorf("verification receipt signer<%s> is not a fully qualified verification method", config.VerificationReceiptSigner)
+	}
+	signingPool, err := signing.NewPool(config.SigningPoolSize, config.SigningQueueDepth)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the signing pool")
+	}
 	service := Service{
-		storage:  credentialStorage,
-		config:   config,
-		verifier: verifier,
-		keyStore: keyStore,
-		schema:   schema,
+		storage:                   credentialStorage,
+		config:                    config,
+		verifier:                  verifier,
+		publisher:                 publisher,
+		publications:              publications,
+		lowCapacity:               lowCapacity,
+		refreshInterval:           refreshInterval,
+		refreshValidity:           refreshValidity,
+		refreshes:                 refreshes,
+		hostingCheckInterval:      hostingCheckInterval,
+		hosting:                   new(statusListHosting),
+		hostingChecks:             hostingChecks,
+		consistencyCheckInterval:  consistencyCheckInterval,
+		statusMismatches:          statusMismatches,
+		renewalInterval:           renewalInterval,
+		batchTimeout:              batchTimeout,
+		maxValidity:               maxValidity,
+		expirySkew:                expirySkew,
+		jwtClaimsPolicy:           jwtClaimsPolicy,
+		statusListSigners:         statusListSigners,
+		signingPool:               signingPool,
+		externalStatusLists:       newStatusListCache(externalStatusListCacheTTL, externalStatusListMaxStaleness, externalStatusListNotFoundTTL),
+		staleStatusLists:          staleStatusLists,
+		statusListRefreshFailures: statusListRefreshFailures,
+		httpClient:                newExternalStatusClient(),
+		statusChecker:             StatusChecker{storage: credentialStorage},
+		keyStore:                  keyStore,
+		schema:                    schema,
+		didResolver:               didResolver,
+		Clock:                     clock.New(),
 	}
 	if !service.Status().IsReady() {
 		return nil, errors.New(service.Status().Message)
@@ -90,20 +305,37 @@ func NewCredentialService(config config.CredentialServiceConfig, s storage.Servi
 	return &service, nil
 }
 
+// CreateCredential creates a credential, emitting its issuance analytics event once it is committed, or has failed.
 func (s Service) CreateCredential(ctx context.Context, request CreateCredentialRequest) (*CreateCredentialResponse, error) {
+	start := time.Now()
+	created, err := s.createAndStoreCredential(ctx, request)
+	s.analytics.Emit(analytics.CredentialIssuance, request.Issuer, request.SchemaID, analytics.IssuanceOutcome(err), time.Since(start))
+	return created, err
+}
+
+func (s Service) createAndStoreCredential(ctx context.Context, request CreateCredentialRequest) (*CreateCredentialResponse, error) {
 	if err := request.IsValid(); err != nil {
 		return nil, errors.Wrap(err, "validating request")
 	}
+	if err := s.checkIssuerNotFrozen(ctx, request.Issuer); err != nil {
+		return nil, err
+	}
+	request, err := s.applySchemaPolicies(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkAutoRenew(request); err != nil {
+		return nil, err
+	}
+	if err = s.checkVerificationMethod(ctx, request); err != nil {
+		return nil, err
+	}
 
 	watchKeys := make([]storage.WatchKey, 0)
 
 	var statusMetadata StatusListCredentialMetadata
 	if request.hasStatus() && request.isStatusValid() {
-		statusPurpose := statussdk.StatusRevocation
-
-		if request.Suspendable {
-			statusPurpose = statussdk.StatusSuspension
-		}
+		statusPurpose := request.statusPurpose()
 
 		statusListCredentialWatchKey := s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
 		statusListCredentialIndexPoolWatchKey := s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
@@ -113,12 +345,24 @@ func (s Service) CreateCredential(ctx context.Context, request CreateCredentialR
 		watchKeys = append(watchKeys, statusListCredentialIndexPoolWatchKey)
 		watchKeys = append(watchKeys, statusListCredentialCurrentIndexWatchKey)
 
-		statusMetadata = StatusListCredentialMetadata{statusListCredentialWatchKey: statusListCredentialWatchKey, statusListIndexPoolWatchKey: statusListCredentialIndexPoolWatchKey, statusListCurrentIndexWatchKey: statusListCredentialCurrentIndexWatchKey}
+		statusMetadata = StatusListCredentialMetadata{statusListCredentialWatchKey: statusListCredentialWatchKey, statusListIndexPoolWatchKey: statusListCredentialIndexPoolWatchKey, statusListCurrentIndexWatchKey: statusListCredentialCurrentIndexWatchKey, statusSize: request.statusSize()}
 	}
 
+	watchKeys = append(watchKeys, s.uniquenessWatchKeys(request)...)
+	watchKeys = append(watchKeys, s.subjectQuotaWatchKeys(request)...)
+	deterministicIDWatchKeys, err := s.deterministicIDWatchKeys(request)
+	if err != nil {
+		return nil, err
+	}
+	watchKeys = append(watchKeys, deterministicIDWatchKeys...)
+	watchKeys = append(watchKeys, s.renewalChainWatchKeys(request)...)
+
 	returnFunc := s.createCredentialFunc(request, statusMetadata)
-	returnValue, err := s.storage.db.Execute(ctx, returnFunc, watchKeys)
+	returnValue, err := s.execute(ctx, returnFunc, watchKeys)
 	if err != nil {
+		if request.hasStatus() && request.isStatusValid() {
+			err = statusListConflict(err, StatusListResource{Issuer: request.Issuer, SchemaID: request.SchemaID, Purpose: string(request.statusPurpose())})
+		}
 		return nil, errors.Wrap(err, "execute")
 	}
 
@@ -127,6 +371,7 @@ func (s Service) CreateCredential(ctx context.Context, request CreateCredentialR
 		return nil, errors.New("problem casting to CreateCredentialResponse")
 	}
 
+	s.publishStatusLists(credResponse.statusList)
 	return credResponse, nil
 }
 
@@ -137,15 +382,35 @@ func (s Service) createCredentialFunc(request CreateCredentialRequest, slcMetada
 }
 
 func (s Service) createCredential(ctx context.Context, request CreateCredentialRequest, tx storage.Tx, statusMetadata StatusListCredentialMetadata) (*CreateCredentialResponse, error) {
-	logrus.Debugf("creating credential: %+v", request)
+	logrus.Debugf("creating credential of issuer: %s", request.Issuer)
 
 	if !request.isStatusValid() {
 		return nil, sdkutil.LoggingNewError("credential may have at most one status")
 	}
 
+	credentialID, err := s.newCredentialID(request)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "deriving credential id")
+	}
+	existing, err := s.existingDeterministicCredential(ctx, credentialID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		logrus.Debugf("credential<%s> was already created with identical content", credentialID)
+		return existing, nil
+	}
+
+	replaced, err := s.checkSubjectUniqueness(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.checkSubjectQuota(ctx, tx, request, credentialID); err != nil {
+		return nil, err
+	}
+
 	builder := credential.NewVerifiableCredentialBuilder()
-	credentialID := uuid.NewString()
-	credentialURI := config.GetServicePath(framework.Credential) + "/" + credentialID
+	credentialURI := s.credentialURI(credentialID)
 	if err := builder.SetID(credentialURI); err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not build credential when setting id: %s", credentialURI)
 	}
@@ -158,10 +423,16 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 	if id, ok := request.Data[credential.VerifiableCredentialIDProperty]; ok && id != request.Subject {
 		return nil, sdkutil.LoggingNewErrorf("cannot set subject<%s>, data already contains a different ID value: %s", request.Subject, id)
 	}
+	if err := request.checkSubjectType(); err != nil {
+		return nil, err
+	}
 
 	// set subject value
 	subject := credential.CredentialSubject(request.Data)
 	subject[credential.VerifiableCredentialIDProperty] = request.Subject
+	if request.SubjectType != "" {
+		subject[SubjectTypeProperty] = request.SubjectType
+	}
 	if err := builder.SetCredentialSubject(subject); err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not set subject: %+v", subject)
 	}
@@ -173,15 +444,23 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 		}
 	}
 
+	if len(request.Types) > 0 {
+		if err := builder.AddType(request.Types); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not add types to credential: %v", request.Types)
+		}
+	}
+
 	// if a schema value exists, verify we can access it, validate the data against it, then set it
 	var knownSchema *schemalib.JSONSchema
+	var schemaVersion int
 	if request.SchemaID != "" {
-		// resolve schema and save it for validation later
-		gotSchema, schemaType, err := s.schema.Resolve(ctx, request.SchemaID)
+		// resolve schema and save it for validation later, along with its version which the credential is pinned to
+		gotSchema, schemaType, gotVersion, err := s.schema.ResolveVersion(ctx, request.SchemaID, 0)
 		if err != nil {
 			return nil, sdkutil.LoggingErrorMsgf(err, "failed to create credential; could not get schema: %s", request.SchemaID)
 		}
 		knownSchema = gotSchema
+		schemaVersion = gotVersion
 		credSchema := credential.CredentialSchema{
 			ID:   request.SchemaID,
 			Type: schemaType.String(),
@@ -189,6 +468,14 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 		if err = builder.SetCredentialSchema(credSchema); err != nil {
 			return nil, sdkutil.LoggingErrorMsgf(err, "could not set JSON Schema for credential: %s", request.SchemaID)
 		}
+		if err = request.checkExpectedType(); err != nil {
+			return nil, err
+		}
+		if schemaType == schemalib.JSONSchemaCredentialType {
+			if err = s.checkSchemaCredentialStatus(ctx, request.SchemaID); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// if an expiry value exists, set it
@@ -198,10 +485,20 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 		}
 	}
 
-	if err := builder.SetIssuanceDate(time.Now().Format(time.RFC3339)); err != nil {
+	issuedAt := s.Clock.Now()
+	if err := s.checkValidity(request.Expiry, issuedAt); err != nil {
+		return nil, err
+	}
+	if err := builder.SetIssuanceDate(issuedAt.Format(time.RFC3339)); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not set credential issuance date")
 	}
 
+	missingClaims := missingExpectedClaims(request.Data, request.expectedClaims)
+	warnings, err := s.checkWarnings(ctx, request, knownSchema, missingClaims)
+	if err != nil {
+		return nil, err
+	}
+
 	if request.hasStatus() {
 		statusEntry, err := s.createStatusListEntryForCredential(ctx, builder.ID, request, tx, statusMetadata)
 		if err != nil {
@@ -210,6 +507,10 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 		if err = builder.SetCredentialStatus(statusEntry); err != nil {
 			return nil, sdkutil.LoggingErrorMsg(err, "could not set credential status")
 		}
+		// the terms of the status entry are defined by the context of status lists
+		if err = builder.AddContext(statussdk.StatusList2021Context); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "could not add status list context to credential")
+		}
 	}
 
 	if request.hasEvidence() {
@@ -229,28 +530,58 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 
 	// verify the built schema complies with the schema we've set
 	if knownSchema != nil {
-		if err = schemalib.IsCredentialValidForJSONSchema(*cred, *knownSchema); err != nil {
-			return nil, sdkutil.LoggingErrorMsgf(err, "credential data does not comply with the provided schema: %s", request.SchemaID)
+		if err = schemaint.IsCredentialValidForJSONSchema(*cred, *knownSchema, s.config.SchemaFormatAssertions...); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(s.schemaViolationError(ctx, err, request.SchemaID), "credential data does not comply with the provided schema: %s", request.SchemaID)
 		}
 	}
 
-	// TODO(gabe) support Data Integrity creds too https://github.com/TBD54566975/ssi-service/issues/105
 	credCopy, err := credint.CopyCredential(*cred)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not copy credential")
 	}
-	credJWT, err := s.signCredentialJWT(ctx, request.FullyQualifiedVerificationMethodID, *credCopy)
+	jwtClaimsPolicy := s.jwtClaimsPolicy
+	if request.JWTClaimsProfile != "" {
+		if jwtClaimsPolicy, err = keyaccess.GetJWTClaimsPolicy(request.JWTClaimsProfile); err != nil {
+			return nil, sdkutil.LoggingError(err)
+		}
+	}
+	credJWT, err := s.signCredentialJWT(ctx, request.FullyQualifiedVerificationMethodID, *credCopy, jwtClaimsPolicy, request.JWTClaims)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "signing credential")
 	}
+	// the credential signed with an embedded proof is stored along with its JWT
+	storedCred := cred
+	if request.ProofFormat == DataIntegrityProofFormat {
+		if storedCred, err = s.signCredentialDataIntegrity(ctx, request.FullyQualifiedVerificationMethodID, *cred); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "signing credential with data integrity proof")
+		}
+	}
 
 	container := credint.Container{
 		ID:                                 credentialID,
 		FullyQualifiedVerificationMethodID: request.FullyQualifiedVerificationMethodID,
-		Credential:                         cred,
+		Credential:                         storedCred,
 		CredentialJWT:                      credJWT,
 		Revoked:                            false,
 		Suspended:                          false,
+		RenderMethod:                       request.renderMethod,
+		MissingClaims:                      missingClaims,
+		SchemaVersion:                      schemaVersion,
+		AutoRenew:                          request.AutoRenew,
+		PreviousCredential:                 request.previousCredential,
+		CreatedBy:                          request.CreatedBy,
+	}
+	if request.MessageStatus != nil {
+		container.StatusValue = formatStatusValue(0)
+	}
+
+	statusList, err := s.replaceSubjectCredentials(ctx, tx, request, credentialID, replaced)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "replacing credentials of subject")
+	}
+	var replacedIDs []string
+	for _, cred := range replaced {
+		replacedIDs = append(replacedIDs, cred.LocalCredentialID)
 	}
 
 	credentialStorageRequest := StoreCredentialRequest{
@@ -259,19 +590,57 @@ func (s Service) createCredential(ctx context.Context, request CreateCredentialR
 	if err = s.storage.StoreCredentialTx(ctx, tx, credentialStorageRequest); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "saving credential")
 	}
+	if err = s.storeRenewalChainTx(ctx, tx, request, container); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "saving renewal chain")
+	}
+
+	response := CreateCredentialResponse{Container: container, Warnings: warnings, ReplacedCredentialIDs: replacedIDs, statusList: statusList}
+	if err = s.writeCreatedEvent(ctx, tx, response); err != nil {
+		return nil, err
+	}
+	s.logCreatedCredential(credentialID, cred)
+	return &response, nil
+}
 
-	return &CreateCredentialResponse{Container: container}, nil
+// logCreatedCredential logs the ID of a created credential, along with the credential itself, its configured claims
+// redacted, when payloads are logged.
+func (s Service) logCreatedCredential(id string, cred *credential.VerifiableCredential) {
+	if !s.config.LogCredentialPayloads {
+		logrus.Debugf("created credential: %s", id)
+		return
+	}
+	redacted, err := util.RedactJSONPaths(cred, s.config.LogRedactedPaths)
+	if err != nil {
+		logrus.WithError(err).Warnf("created credential: %s, which could not be redacted to be logged", id)
+		return
+	}
+	logrus.WithField("credential", string(redacted)).Infof("created credential: %s", id)
 }
 
-// signCredentialJWT signs a credential and returns it as a vc-jwt
-func (s Service) signCredentialJWT(ctx context.Context, verificationMethodID string, cred credential.VerifiableCredential) (*keyaccess.JWT, error) {
+// signCredentialJWT signs a credential and returns it as a vc-jwt, with its registered claims set as the policy says,
+// and the additional top level claims, if any. It runs in the signing pool, failing with a signing.BusyError when the
+// pool is saturated.
+func (s Service) signCredentialJWT(ctx context.Context, verificationMethodID string, cred credential.VerifiableCredential, policy keyaccess.JWTClaimsPolicy, claims map[string]any) (*keyaccess.JWT, error) {
+	var credToken *keyaccess.JWT
+	err := s.signingPool.Do(ctx, func() error {
+		var err error
+		credToken, err = s.signCredentialJWTUnpooled(ctx, verificationMethodID, cred, policy, claims)
+		return err
+	})
+	return credToken, err
+}
+
+func (s Service) signCredentialJWTUnpooled(ctx context.Context, verificationMethodID string, cred credential.VerifiableCredential, policy keyaccess.JWTClaimsPolicy, claims map[string]any) (*keyaccess.JWT, error) {
 	keyStoreID := did.FullyQualifiedVerificationMethodID(cred.IssuerID(), verificationMethodID)
 	gotKey, err := s.keyStore.GetKey(ctx, keystore.GetKeyRequest{ID: keyStoreID})
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "getting key for signing credential<%s>", verificationMethodID)
 	}
 	if gotKey.Controller != cred.Issuer.(string) {
-		return nil, sdkutil.LoggingNewErrorf("key controller<%s> does not match credential issuer<%s> for key<%s>", gotKey.Controller, cred.Issuer, verificationMethodID)
+		// keys of another DID may only sign on behalf of the issuer when it delegated to that DID
+		if claims, err = s.delegationClaims(ctx, cred.IssuerID(), gotKey.Controller, verificationMethodID, claims); err != nil {
+			return nil, err
+		}
 	}
 	if gotKey.Revoked {
 		return nil, sdkutil.LoggingNewErrorf("cannot use revoked key<%s>", gotKey.ID)
@@ -280,16 +649,77 @@ func (s Service) signCredentialJWT(ctx context.Context, verificationMethodID str
 	if err != nil {
 		return nil, errors.Wrapf(err, "creating key access for signing credential with key<%s>", gotKey.ID)
 	}
-	credToken, err := keyAccess.SignVerifiableCredential(cred)
+	credToken, err := keyAccess.SignVerifiableCredentialWithPolicy(cred, policy, claims)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not sign credential with key<%s>", gotKey.ID)
 	}
 	return credToken, nil
 }
 
+// signCredentialDataIntegrity returns a copy of the credential signed with an embedded JsonWebSignature2020 proof made
+// with the key of the verification method. Unlike JWTs, the proof cannot carry a delegation, so the key must be one of
+// the issuer.
+func (s Service) signCredentialDataIntegrity(ctx context.Context, verificationMethodID string, cred credential.VerifiableCredential) (*credential.VerifiableCredential, error) {
+	var signedCred *credential.VerifiableCredential
+	err := s.signingPool.Do(ctx, func() error {
+		keyStoreID := did.FullyQualifiedVerificationMethodID(cred.IssuerID(), verificationMethodID)
+		gotKey, err := s.keyStore.GetKey(ctx, keystore.GetKeyRequest{ID: keyStoreID})
+		if err != nil {
+			return sdkutil.LoggingErrorMsgf(err, "getting key for signing credential<%s>", verificationMethodID)
+		}
+		if gotKey.Controller != cred.IssuerID() {
+			return sdkutil.LoggingNewErrorf("data integrity proofs can only be signed with keys of the issuer, not with key<%s>", gotKey.ID)
+		}
+		if gotKey.Revoked {
+			return sdkutil.LoggingNewErrorf("cannot use revoked key<%s>", gotKey.ID)
+		}
+		keyAccess, err := keyaccess.NewDataIntegrityKeyAccess(cred.IssuerID(), keyStoreID, gotKey.Key)
+		if err != nil {
+			return errors.Wrapf(err, "creating key access for signing credential with key<%s>", gotKey.ID)
+		}
+		if signedCred, err = credint.CopyCredential(cred); err != nil {
+			return errors.Wrap(err, "copying credential")
+		}
+		// the terms of the proof are defined by the context of its suite, which the credential must reference
+		contexts, err := cryptosuite.GetContextsFromProvable(signedCred)
+		if err != nil {
+			return errors.Wrap(err, "getting contexts of credential")
+		}
+		signedCred.Context = cryptosuite.EnsureRequiredContexts(contexts, keyAccess.CryptoSuite.RequiredContexts())
+		// a proof does not cover the terms which the contexts of the credential do not define
+		if err = keyaccess.CheckTermsDefined(signedCred); err != nil {
+			return errors.Wrap(err, "checking terms of credential")
+		}
+		if _, err = keyAccess.Sign(signedCred); err != nil {
+			return errors.Wrapf(err, "could not sign credential with key<%s>", gotKey.ID)
+		}
+		return nil
+	})
+	return signedCred, err
+}
+
 type VerifyCredentialRequest struct {
 	DataIntegrityCredential *credential.VerifiableCredential `json:"credential,omitempty"`
 	CredentialJWT           *keyaccess.JWT                   `json:"credentialJwt,omitempty"`
+
+	// When set, the credential's credentialSchema must have this ID.
+	ExpectedSchemaID string `json:"expectedSchemaId,omitempty"`
+	// When set, the credential's type must contain every one of these types.
+	ExpectedTypes []string `json:"expectedTypes,omitempty"`
+	// When set, the signature is checked with this key, rather than with the key resolved from the issuer's DID
+	// document, and the credential must be signed with the key ID of this key.
+	PinnedIssuerKey *jwx.PublicKeyJWK `json:"pinnedIssuerKey,omitempty"`
+	// When set, the parsed credential is returned as UnverifiedCredential when verification fails.
+	ReturnCredentialOnFailure bool `json:"returnCredentialOnFailure,omitempty"`
+	// When set, the schema of the credential, if any, must be a JsonSchemaCredential issued by one of the configured
+	// trusted schema authorities.
+	RequireTrustedSchema bool `json:"requireTrustedSchema,omitempty"`
+	// When set, a verification receipt signed by the configured verification receipt signer is returned.
+	ReturnReceipt bool `json:"returnReceipt,omitempty"`
+	// When set, the credential is verified as of this past time: its validity period is checked at that time, and the
+	// status of its entries with status lists stored by the service is reconstructed from the event log. Signatures
+	// are checked with the current DID documents of their signers.
+	AtTime *time.Time `json:"atTime,omitempty"`
 }
 
 // IsValid checks if the request is valid, meaning there is at least one data integrity (with proof)
@@ -301,12 +731,39 @@ func (vcr VerifyCredentialRequest) IsValid() error {
 	if (vcr.DataIntegrityCredential != nil && vcr.DataIntegrityCredential.Proof != nil) && vcr.CredentialJWT != nil {
 		return errors.New("only one of credential or credential JWT can be provided")
 	}
+	if vcr.PinnedIssuerKey != nil && vcr.PinnedIssuerKey.KID == "" {
+		return errors.New("pinned issuer key must have a kid")
+	}
+	if vcr.AtTime != nil && vcr.AtTime.After(time.Now()) {
+		return errors.New("the time to verify the credential at cannot be in the future")
+	}
 	return nil
 }
 
 type VerifyCredentialResponse struct {
 	Verified bool   `json:"verified"`
 	Reason   string `json:"reason,omitempty"`
+	// Set to verification.SchemaMismatch or verification.TypeMismatch when the credential is valid but does not
+	// satisfy the expected schema or types, and to verification.Revoked or verification.Suspended when its status
+	// list stored by the service has its status set. Set to verification.KeyMismatch when it is not signed with the
+	// pinned issuer key, and to verification.UntrustedSchema when its schema is not issued by a trusted authority.
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// Current status value and message of a credential using a message status list stored by the service.
+	StatusValue   string `json:"statusValue,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// Results of checking each revocation and suspension status entry of the credential whose status list is stored by
+	// the service.
+	StatusResults []StatusResult `json:"statusResults,omitempty"`
+	// Set when verifying as of a past time which the event log does not cover for a status entry, in which case
+	// Verified does not account for the status of that entry.
+	StatusUnknown bool `json:"statusUnknown,omitempty"`
+	// Result of checking the schema of the credential is issued by a trusted authority, when the request requires it.
+	SchemaTrust *SchemaTrustResult `json:"schemaTrust,omitempty"`
+	// The parsed credential, which must not be trusted, when verification failed and the request asked for it. Nil
+	// when the credential could not be parsed.
+	UnverifiedCredential *credential.VerifiableCredential `json:"unverifiedCredential,omitempty"`
+	// A JWT signed by the service attesting to the outcome of the verification, when the request asked for it.
+	VerificationReceipt *keyaccess.JWT `json:"verificationReceipt,omitempty"`
 }
 
 // VerifyCredential does three levels of verification on a credential:
@@ -314,26 +771,161 @@ type VerifyCredentialResponse struct {
 // 2. Makes sure the credential has is not expired
 // 3. Makes sure the credential complies with the VC Data Model
 // 4. If the credential has a schema, makes sure its data complies with the schema
-// LATER: Makes sure the credential has not been revoked, other checks.
+// 5. For each revocation or suspension status entry with a status list stored by the service, makes sure its status is
+// not set. The credential status may be a single entry or an array of them.
+// 6. If expected, makes sure the credential has the expected schema and types, including the type expected by its
+// schema when the schema is stored by the service
+// 7. If required, makes sure the schema of the credential is a JsonSchemaCredential issued by a trusted authority
+// 8. If the credential uses a message status list stored by the service, returns its current status message
+// Failed verifications are notified to the function set with OnVerificationFailed, if any, and return the unverified
+// credential when the request sets ReturnCredentialOnFailure. A verification receipt is returned when the request
+// sets ReturnReceipt, failing with ErrVerificationReceiptsDisabled when no verification receipt signer is configured.
+// When the request sets AtTime, the credential is verified as of that past time, with the status of its entries as it
+// was then, and without its message status.
+// The analytics event of each verification is emitted once it completes.
 func (s Service) VerifyCredential(ctx context.Context, request VerifyCredentialRequest) (*VerifyCredentialResponse, error) {
+	start := time.Now()
+	response, err := s.verifyAndNotify(ctx, request)
+	s.emitVerification(request, response, err, time.Since(start))
+	return response, err
+}
+
+func (s Service) verifyAndNotify(ctx context.Context, request VerifyCredentialRequest) (*VerifyCredentialResponse, error) {
 	logrus.Debugf("verifying credential: %+v", request)
 
 	if err := request.IsValid(); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "invalid verify credential request")
 	}
+	if request.ReturnReceipt && s.config.VerificationReceiptSigner == "" {
+		return nil, sdkutil.LoggingError(ErrVerificationReceiptsDisabled)
+	}
+
+	response, err := s.verifyCredential(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if !response.Verified {
+		failure := verification.Failure{ReasonCode: response.ReasonCode}
+		if failure.ReasonCode == "" {
+			failure.ReasonCode = verification.InvalidCredential
+		}
+		if request.CredentialJWT != nil {
+			failure.CredentialHashes = []string{verification.HashCredential(request.CredentialJWT.String())}
+		} else {
+			failure.CredentialHashes = []string{verification.HashCredential(request.DataIntegrityCredential)}
+		}
+		s.verificationFailed.Notify(failure)
+		if request.ReturnCredentialOnFailure {
+			response.UnverifiedCredential = unverifiedCredential(request)
+		}
+	}
+	if request.ReturnReceipt {
+		if response.VerificationReceipt, err = s.signVerificationReceipt(ctx, request, *response); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "signing verification receipt")
+		}
+	}
+	return response, nil
+}
+
+// unverifiedCredential parses the credential of the request without verifying it, returning nil when it can't be
+// parsed.
+func unverifiedCredential(request VerifyCredentialRequest) *credential.VerifiableCredential {
+	if request.CredentialJWT == nil {
+		return request.DataIntegrityCredential
+	}
+	cred, err := credint.ParseVerifiableCredentialFromJWT(request.CredentialJWT.String())
+	if err != nil {
+		logrus.WithError(err).Debug("could not parse unverified credential")
+		return nil
+	}
+	return cred
+}
+
+// signatureFailure returns the response of a credential whose signature, or data, could not be verified, with the
+// verification.KeyMismatch reason code when it is not signed with the pinned key.
+func signatureFailure(err error) *VerifyCredentialResponse {
+	response := VerifyCredentialResponse{Verified: false, Reason: err.Error()}
+	var keyMismatchErr verification.KeyMismatchError
+	if errors.As(err, &keyMismatchErr) {
+		response.ReasonCode = verification.KeyMismatch
+	}
+	return &response
+}
 
+func (s Service) verifyCredential(ctx context.Context, request VerifyCredentialRequest) (*VerifyCredentialResponse, error) {
+	verifier := s.schemaVersionVerifier(ctx, request)
+	if request.AtTime != nil {
+		verifier = verifier.AsOf(*request.AtTime)
+	}
+	verifiedCred := request.DataIntegrityCredential
 	if request.CredentialJWT != nil {
-		err := s.verifier.VerifyJWTCredential(ctx, *request.CredentialJWT)
+		var err error
+		if request.PinnedIssuerKey != nil {
+			err = verifier.VerifyJWTCredentialWithKey(ctx, *request.CredentialJWT, *request.PinnedIssuerKey)
+		} else {
+			err = verifier.VerifyJWTCredential(ctx, *request.CredentialJWT)
+		}
+		if err != nil {
+			return signatureFailure(err), nil
+		}
+		container, err := credint.NewCredentialContainerFromJWT(request.CredentialJWT.String())
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "parsing verified credential")
+		}
+		verifiedCred = container.Credential
+	} else {
+		var err error
+		if request.PinnedIssuerKey != nil {
+			err = verifier.VerifyDataIntegrityCredentialWithKey(ctx, *request.DataIntegrityCredential, *request.PinnedIssuerKey)
+		} else {
+			err = verifier.VerifyDataIntegrityCredential(ctx, *request.DataIntegrityCredential)
+		}
 		if err != nil {
-			return &VerifyCredentialResponse{Verified: false, Reason: err.Error()}, nil
+			return signatureFailure(err), nil
 		}
+	}
+
+	var statusResults []StatusResult
+	var err error
+	if request.AtTime != nil {
+		statusResults, err = s.statusChecker.CheckStatusesAt(ctx, *verifiedCred, *request.AtTime)
 	} else {
-		if err := s.verifier.VerifyDataIntegrityCredential(ctx, *request.DataIntegrityCredential); err != nil {
-			return &VerifyCredentialResponse{Verified: false, Reason: err.Error()}, nil
+		statusResults, err = s.statusChecker.CheckStatuses(ctx, *verifiedCred)
+	}
+	if err != nil {
+		var statusErr verification.StatusError
+		if errors.As(err, &statusErr) {
+			return &VerifyCredentialResponse{Verified: false, Reason: statusErr.Error(), ReasonCode: statusErr.Reason, StatusResults: statusResults}, nil
 		}
+		return nil, sdkutil.LoggingErrorMsg(err, "checking credential status")
 	}
 
-	return &VerifyCredentialResponse{Verified: true}, nil
+	// expectations are checked after the signature, so that a mismatch can be trusted
+	expectations := s.credentialExpectations(ctx, request, *verifiedCred)
+	if err := verification.CheckCredentialExpectations(*verifiedCred, expectations); err != nil {
+		var expectationErr verification.ExpectationError
+		if errors.As(err, &expectationErr) {
+			return &VerifyCredentialResponse{Verified: false, Reason: expectationErr.Error(), ReasonCode: expectationErr.Reason, StatusResults: statusResults}, nil
+		}
+		return nil, sdkutil.LoggingErrorMsg(err, "checking credential expectations")
+	}
+
+	var schemaTrust *SchemaTrustResult
+	if request.RequireTrustedSchema {
+		if schemaTrust = s.checkSchemaTrust(ctx, *verifiedCred); schemaTrust != nil && !schemaTrust.Trusted {
+			return schemaTrustFailure(*schemaTrust, statusResults), nil
+		}
+	}
+
+	response := VerifyCredentialResponse{Verified: true, StatusResults: statusResults, StatusUnknown: hasUnknownStatus(statusResults), SchemaTrust: schemaTrust}
+	if request.AtTime != nil {
+		// the message status of a credential is its current one
+		return &response, nil
+	}
+	if response.StatusValue, response.StatusMessage, err = s.messageStatusOf(ctx, *verifiedCred); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "getting message status of credential")
+	}
+	return &response, nil
 }
 
 func (s Service) GetCredential(ctx context.Context, request GetCredentialRequest) (*GetCredentialResponse, error) {
@@ -348,11 +940,23 @@ func (s Service) GetCredential(ctx context.Context, request GetCredentialRequest
 	}
 	response := GetCredentialResponse{
 		credint.Container{
-			ID:            gotCred.LocalCredentialID,
-			Credential:    gotCred.Credential,
-			CredentialJWT: gotCred.CredentialJWT,
-			Revoked:       gotCred.Revoked,
-			Suspended:     gotCred.Suspended,
+			ID:                 gotCred.LocalCredentialID,
+			Credential:         gotCred.Credential,
+			CredentialJWT:      gotCred.CredentialJWT,
+			Revoked:            gotCred.Revoked,
+			Suspended:          gotCred.Suspended,
+			StatusValue:        gotCred.StatusValue,
+			StatusReason:       gotCred.StatusReason,
+			StatusReasonCode:   gotCred.StatusReasonCode,
+			Imported:           gotCred.Imported,
+			RenderMethod:       gotCred.RenderMethod,
+			MissingClaims:      gotCred.MissingClaims,
+			Receipt:            gotCred.Receipt,
+			SchemaVersion:      gotCred.SchemaVersion,
+			AutoRenew:          gotCred.AutoRenew,
+			PreviousCredential: gotCred.PreviousCredential,
+			CreatedBy:          gotCred.CreatedBy,
+			LifecycleState:     s.lifecycleStateOf(gotCred.Revoked, gotCred.Suspended, gotCred.GetExpirationDate()),
 		},
 	}
 	return &response, nil
@@ -361,7 +965,8 @@ func (s Service) GetCredential(ctx context.Context, request GetCredentialRequest
 func (s Service) ListCredentials(ctx context.Context, filter filtering.Filter, request pagination.PageRequest) (*ListCredentialsResponse, error) {
 	logrus.Debugf("listing credential(s) ")
 
-	gotCreds, err := s.storage.ListCredentials(ctx, filter, request.ToServicePage())
+	expiryCutoff := s.expiryCutoff()
+	gotCreds, err := s.storage.ListCredentials(ctx, filter, request.ToServicePage(), expiryCutoff)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not list credential(s)")
 	}
@@ -369,11 +974,23 @@ func (s Service) ListCredentials(ctx context.Context, filter filtering.Filter, r
 	creds := make([]credint.Container, 0, len(gotCreds.StoredCredentials))
 	for _, cred := range gotCreds.StoredCredentials {
 		container := credint.Container{
-			ID:            cred.LocalCredentialID,
-			Credential:    cred.Credential,
-			CredentialJWT: cred.CredentialJWT,
-			Revoked:       cred.Revoked,
-			Suspended:     cred.Suspended,
+			ID:                 cred.LocalCredentialID,
+			Credential:         cred.Credential,
+			CredentialJWT:      cred.CredentialJWT,
+			Revoked:            cred.Revoked,
+			Suspended:          cred.Suspended,
+			StatusValue:        cred.StatusValue,
+			StatusReason:       cred.StatusReason,
+			StatusReasonCode:   cred.StatusReasonCode,
+			Imported:           cred.Imported,
+			RenderMethod:       cred.RenderMethod,
+			MissingClaims:      cred.MissingClaims,
+			Receipt:            cred.Receipt,
+			SchemaVersion:      cred.SchemaVersion,
+			AutoRenew:          cred.AutoRenew,
+			PreviousCredential: cred.PreviousCredential,
+			CreatedBy:          cred.CreatedBy,
+			LifecycleState:     lifecycleState(cred.Revoked, cred.Suspended, isExpiredAt(cred.GetExpirationDate(), expiryCutoff)),
 		}
 		creds = append(creds, container)
 	}
@@ -385,23 +1002,104 @@ func (s Service) ListCredentials(ctx context.Context, filter filtering.Filter, r
 	return &response, nil
 }
 
+// ListCredentialMetadata lists credentials like ListCredentials, but returns only their metadata. Stored credentials
+// are decoded into their metadata alone, so that the credentials and their JWTs are neither decoded nor returned.
+func (s Service) ListCredentialMetadata(ctx context.Context, filter filtering.Filter, request pagination.PageRequest) (*ListCredentialMetadataResponse, error) {
+	logrus.Debugf("listing credential metadata")
+
+	expiryCutoff := s.expiryCutoff()
+	gotMetadata, err := s.storage.ListCredentialMetadata(ctx, filter, request.ToServicePage(), expiryCutoff)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not list credential metadata")
+	}
+
+	metadata := make([]CredentialMetadata, 0, len(gotMetadata.Metadata))
+	for _, m := range gotMetadata.Metadata {
+		metadata = append(metadata, CredentialMetadata{
+			ID:             m.LocalCredentialID,
+			Issuer:         m.Issuer,
+			Subject:        m.Subject,
+			Schema:         m.Schema,
+			SchemaVersion:  m.SchemaVersion,
+			Revoked:        m.Revoked,
+			Suspended:      m.Suspended,
+			IssuanceDate:   m.IssuanceDate,
+			ExpirationDate: m.GetExpirationDate(),
+			Acknowledged:   m.Receipt != nil,
+			CreatedBy:      m.CreatedBy,
+			LifecycleState: lifecycleState(m.Revoked, m.Suspended, isExpiredAt(m.GetExpirationDate(), expiryCutoff)),
+		})
+	}
+
+	response := ListCredentialMetadataResponse{
+		Credentials:   metadata,
+		NextPageToken: gotMetadata.NextPageToken,
+	}
+	return &response, nil
+}
+
+// GetCredentialStatus returns the status of a credential, along with its lifecycle state. The status of a credential
+// which is no longer stored is DELETED when the event log records its deletion.
 func (s Service) GetCredentialStatus(ctx context.Context, request GetCredentialStatusRequest) (*GetCredentialStatusResponse, error) {
 	logrus.Debugf("getting credential status: %s", request.ID)
 
 	gotCred, err := s.storage.GetCredential(ctx, request.ID)
 	if err != nil {
+		if deleted, deletedErr := s.storage.WasCredentialDeleted(ctx, localCredentialID(request.ID)); deletedErr != nil {
+			logrus.WithError(deletedErr).Warnf("checking whether credential<%s> was deleted", request.ID)
+		} else if deleted {
+			return &GetCredentialStatusResponse{LifecycleState: credint.LifecycleDeleted}, nil
+		}
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
 	}
 	if !gotCred.IsValid() {
 		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
 	}
+	if gotCred.Imported {
+		response := s.externalCredentialStatus(ctx, gotCred)
+		if !response.StatusUnknown {
+			response.LifecycleState = s.lifecycleStateOf(response.Revoked, response.Suspended, gotCred.GetExpirationDate())
+		}
+		return response, nil
+	}
 	response := GetCredentialStatusResponse{
-		Revoked:   gotCred.Revoked,
-		Suspended: gotCred.Suspended,
+		Revoked:        gotCred.Revoked,
+		Suspended:      gotCred.Suspended,
+		StatusValue:    gotCred.StatusValue,
+		Reason:         gotCred.StatusReason,
+		ReasonCode:     gotCred.StatusReasonCode,
+		LifecycleState: s.lifecycleStateOf(gotCred.Revoked, gotCred.Suspended, gotCred.GetExpirationDate()),
+	}
+	if entry, ok := toMessageStatusEntry(gotCred.Credential.CredentialStatus); ok && gotCred.StatusValue != "" {
+		value, err := parseStatusValue(gotCred.StatusValue)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "parsing status value of credential: %s", request.ID)
+		}
+		response.StatusMessage, _ = entry.messageStatus().message(value)
 	}
 	return &response, nil
 }
 
+// ErrCredentialNotFound is returned when looking up the public status of a credential the service has not issued,
+// including one it has since deleted, and when verifying by ID a credential the service does not store.
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// GetPublicCredentialStatus returns only whether a credential issued by the service is revoked or suspended. Imported
+// credentials are not issued by the service, so like deleted ones they are reported with ErrCredentialNotFound.
+func (s Service) GetPublicCredentialStatus(ctx context.Context, request GetCredentialStatusRequest) (*GetPublicCredentialStatusResponse, error) {
+	logrus.Debugf("getting public credential status: %s", request.ID)
+
+	gotCred, err := s.storage.GetCredentialIfExists(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+	}
+	// credentials are read by the prefix of their key, so a partial ID must not match the credential it starts
+	if gotCred == nil || gotCred.LocalCredentialID != localCredentialID(request.ID) || !gotCred.IsValid() || gotCred.Imported {
+		return nil, ErrCredentialNotFound
+	}
+	return &GetPublicCredentialStatusResponse{Revoked: gotCred.Revoked, Suspended: gotCred.Suspended}, nil
+}
+
 func (s Service) GetCredentialStatusList(ctx context.Context, request GetCredentialStatusListRequest) (*GetCredentialStatusListResponse, error) {
 	logrus.Debugf("getting credential status list: %s", request.ID)
 
@@ -424,20 +1122,41 @@ func (s Service) GetCredentialStatusList(ctx context.Context, request GetCredent
 	return &response, nil
 }
 
+// ErrStatusReasonCodeNotPermitted is returned when updating the status of a credential with a reason code other than
+// the configured ones.
+var ErrStatusReasonCodeNotPermitted = errors.New("status reason code is not permitted")
+
+// checkStatusReasonCode rejects a status update with a reason code outside the configured enumeration, if any.
+func (s Service) checkStatusReasonCode(request UpdateCredentialStatusRequest) error {
+	if request.ReasonCode == "" || len(s.config.StatusReasonCodes) == 0 || sdkutil.Contains(request.ReasonCode, s.config.StatusReasonCodes) {
+		return nil
+	}
+	return sdkutil.LoggingError(errors.Wrapf(ErrStatusReasonCodeNotPermitted, "reason code<%s> of credential<%s> must be one of %v", request.ReasonCode, request.ID, s.config.StatusReasonCodes))
+}
+
 func (s Service) UpdateCredentialStatus(ctx context.Context, request UpdateCredentialStatusRequest) (*UpdateCredentialStatusResponse, error) {
+	if err := s.checkStatusReasonCode(request); err != nil {
+		return nil, err
+	}
+	if err := s.checkStatusReason(request); err != nil {
+		return nil, err
+	}
 
-	statusListCredentialWatchKey, err := s.statusListCredentialWatchKey(ctx, request.ID)
+	statusListCredentialWatchKey, statusList, err := s.statusListCredentialWatchKey(ctx, request.ID)
 	if err != nil {
 		return nil, err
 	}
+	if err = s.checkIssuerNotFrozen(ctx, statusList.Issuer); err != nil {
+		return nil, err
+	}
 
 	slcMetadata := StatusListCredentialMetadata{statusListCredentialWatchKey: *statusListCredentialWatchKey}
 	watchKeys := []storage.WatchKey{*statusListCredentialWatchKey}
 	returnFunc := s.updateCredentialStatusFunc(request, slcMetadata)
 
-	returnValue, err := s.storage.db.Execute(ctx, returnFunc, watchKeys)
+	returnValue, err := s.execute(ctx, returnFunc, watchKeys)
 	if err != nil {
-		return nil, errors.Wrap(err, "execute")
+		return nil, errors.Wrap(statusListConflict(err, *statusList), "execute")
 	}
 
 	credResponse, ok := returnValue.(*UpdateCredentialStatusResponse)
@@ -445,12 +1164,20 @@ func (s Service) UpdateCredentialStatus(ctx context.Context, request UpdateCrede
 		return nil, errors.New("casting to UpdateCredentialStatusResponse")
 	}
 
+	s.publishStatusLists(credResponse.statusList)
 	return credResponse, nil
 }
 
 func (s Service) updateCredentialStatusFunc(request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata) storage.BusinessLogicFunc {
 	return func(ctx context.Context, tx storage.Tx) (any, error) {
-		return s.updateCredentialStatusBusinessLogic(ctx, tx, request, slcMetadata)
+		response, err := s.updateCredentialStatusBusinessLogic(ctx, tx, request, slcMetadata)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.writeStatusUpdatedEvent(ctx, tx, request.ID, request.Principal, response.Status); err != nil {
+			return nil, err
+		}
+		return response, nil
 	}
 }
 
@@ -469,27 +1196,45 @@ func (s Service) updateCredentialStatusBusinessLogic(ctx context.Context, tx sto
 		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
 	}
 
+	if gotCred.HasCredentialStatus() && gotCred.GetStatusPurpose() == string(MessageStatusPurpose) {
+		return s.updateMessageStatus(ctx, tx, gotCred, request, slcMetadata)
+	}
+	if request.StatusValue != "" {
+		return nil, sdkutil.LoggingNewErrorf("credential<%s> does not use a message status list, so its status value cannot be set", request.ID)
+	}
+	if err = s.checkStatusSigner(gotCred, request.VerificationMethodID); err != nil {
+		return nil, err
+	}
+
 	// if the request is the same as what the current credential is there is no action
 	if gotCred.Revoked == request.Revoked && gotCred.Suspended == request.Suspended {
 		logrus.Warn("request and credential have same status, no action is needed")
-		response := UpdateCredentialStatusResponse{Status{
-			Revoked:   gotCred.Revoked,
-			Suspended: gotCred.Suspended,
+		response := UpdateCredentialStatusResponse{Status: Status{
+			Revoked:    gotCred.Revoked,
+			Suspended:  gotCred.Suspended,
+			Reason:     gotCred.StatusReason,
+			ReasonCode: gotCred.StatusReasonCode,
 		}}
 		return &response, nil
 	}
 
-	container, err := updateCredentialStatus(ctx, tx, s, gotCred, request, slcMetadata)
+	container, statusListContainer, err := updateCredentialStatus(ctx, tx, s, gotCred, request, slcMetadata)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "updating credential")
 	}
 
-	response := UpdateCredentialStatusResponse{Status{Revoked: container.Revoked, Suspended: container.Suspended}}
+	response := UpdateCredentialStatusResponse{
+		Status:     Status{Revoked: container.Revoked, Suspended: container.Suspended, Reason: container.StatusReason, ReasonCode: container.StatusReasonCode},
+		statusList: statusListContainer,
+	}
 	return &response, nil
 }
 
-func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCred *StoredCredential, request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata) (*credint.Container, error) {
-	// store the credential with updated status
+// updateCredentialStatus stores the credential with the requested status and regenerates its status list credential.
+// Credentials of the same status list whose status was already set to the requested one earlier in the transaction are
+// passed as pending, since the transaction's writes are not visible to reads.
+func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCred *StoredCredential, request UpdateCredentialStatusRequest, slcMetadata StatusListCredentialMetadata, pending ...StoredCredential) (*credint.Container, *credint.Container, error) {
+	// store the credential with updated status, returning it along with the regenerated status list credential
 	container := credint.Container{
 		ID:                                 gotCred.LocalCredentialID,
 		FullyQualifiedVerificationMethodID: gotCred.FullyQualifiedVerificationMethodID,
@@ -497,6 +1242,15 @@ func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCr
 		CredentialJWT:                      gotCred.CredentialJWT,
 		Revoked:                            request.Revoked,
 		Suspended:                          request.Suspended,
+		StatusReason:                       request.Reason,
+		StatusReasonCode:                   request.ReasonCode,
+		RenderMethod:                       gotCred.RenderMethod,
+		MissingClaims:                      gotCred.MissingClaims,
+		Receipt:                            gotCred.Receipt,
+		SchemaVersion:                      gotCred.SchemaVersion,
+		AutoRenew:                          gotCred.AutoRenew,
+		PreviousCredential:                 gotCred.PreviousCredential,
+		CreatedBy:                          gotCred.CreatedBy,
 	}
 
 	storageRequest := StoreCredentialRequest{
@@ -504,50 +1258,88 @@ func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCr
 	}
 
 	if err := s.storage.StoreCredentialTx(ctx, tx, storageRequest); err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential")
+		return nil, nil, sdkutil.LoggingErrorMsg(err, "could not store credential")
 	}
 
-	statusListCredentialURI := gotCred.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
-
-	if len(statusListCredentialURI) == 0 {
-		return nil, sdkutil.LoggingNewErrorf("problem with getting status list credential id")
+	pendingIDs := make(map[string]bool, len(pending))
+	for _, cred := range pending {
+		pendingIDs[cred.Credential.ID] = true
 	}
 
-	statusListCredentialID, err := parseIDFromURI(statusListCredentialURI)
-	if err != nil {
-		return nil, err
+	// the status list keeps the purpose of the credential's entry, also when its status is being cleared
+	statusPurpose := statussdk.StatusPurpose(gotCred.GetStatusPurpose())
+	requestedPurpose := statussdk.StatusRevocation
+	if request.Suspended {
+		requestedPurpose = statussdk.StatusSuspension
+	}
+	if (request.Revoked || request.Suspended) && requestedPurpose != statusPurpose {
+		return nil, nil, sdkutil.LoggingNewErrorf("credential<%s> has a different status purpose<%s> value than the status credential<%s>", gotCred.Credential.ID, statusPurpose, requestedPurpose)
+	}
+	isSet := func(revoked, suspended bool) bool {
+		if statusPurpose == statussdk.StatusSuspension {
+			return suspended
+		}
+		return revoked
 	}
 
-	creds, err := s.storage.GetCredentialsByIssuerAndSchema(ctx, gotCred.Issuer, gotCred.Schema)
-	if err != nil {
-		return nil, sdkutil.LoggingNewErrorf("problem with getting status list credential for issuer: %s schema: %s", gotCred.Issuer, gotCred.Schema)
+	gotEntry, ok := statusEntryOf(*gotCred)
+	if !ok {
+		return nil, nil, sdkutil.LoggingNewErrorf("credential<%s> has no status list entry", gotCred.LocalCredentialID)
 	}
 
+	// only the status entries of the credentials whose bit is set are kept, so that memory is bounded by the status list
 	var revokedOrSuspendedStatusCreds []credential.VerifiableCredential
-	for _, cred := range creds {
+	visit := func(cred StoredCredential) error {
 		// we add the current cred to the creds list based on request, not on what could be in stale database that the tx has not updated yet
-		if cred.Credential.ID == gotCred.Credential.ID {
-			continue
+		if cred.Credential.ID == gotCred.Credential.ID || pendingIDs[cred.Credential.ID] {
+			return nil
 		}
 
-		if request.Revoked && cred.Credential.CredentialStatus != nil && cred.Revoked {
-			revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, *cred.Credential)
-		} else if request.Suspended && cred.Credential.CredentialStatus != nil && cred.Suspended {
-			revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, *cred.Credential)
+		if cred.Credential.CredentialStatus != nil && isSet(cred.Revoked, cred.Suspended) {
+			revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, statusOnly(*cred.Credential))
 		}
+		return nil
+	}
+	if err := s.forEachSetStatusListCredential(ctx, gotEntry.statusListCredentialID, gotCred.Issuer, gotCred.Schema, visit); err != nil {
+		return nil, nil, sdkutil.LoggingErrorMsgf(err, "problem with getting status list credential for issuer: %s schema: %s", gotCred.Issuer, gotCred.Schema)
 	}
 
 	// add current one since it has not been saved yet and won't be available in the creds array
-	if request.Revoked == true || request.Suspended == true {
-		revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, *gotCred.Credential)
+	if isSet(request.Revoked, request.Suspended) {
+		revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, statusOnly(*gotCred.Credential))
+		for _, cred := range pending {
+			revokedOrSuspendedStatusCreds = append(revokedOrSuspendedStatusCreds, statusOnly(*cred.Credential))
+		}
+	}
+
+	statusListContainer, err := s.regenerateStatusList(ctx, tx, gotCred, revokedOrSuspendedStatusCreds, slcMetadata)
+	if err != nil {
+		return nil, nil, err
 	}
+	return &container, statusListContainer, nil
+}
 
-	statusPurpose := statussdk.StatusRevocation
+// statusOnly returns the parts of a credential the bit of its status entry is generated from, so that the credentials
+// whose bit is set can be collected without holding the rest of them.
+func statusOnly(cred credential.VerifiableCredential) credential.VerifiableCredential {
+	return credential.VerifiableCredential{ID: cred.ID, CredentialStatus: cred.CredentialStatus}
+}
 
-	if request.Suspended {
-		statusPurpose = statussdk.StatusSuspension
+// regenerateStatusList signs and stores the status list credential of the status entry of the credential, with the
+// bits of the given credentials set, and the bits of all other credentials cleared.
+func (s Service) regenerateStatusList(ctx context.Context, tx storage.Tx, gotCred *StoredCredential, revokedOrSuspendedStatusCreds []credential.VerifiableCredential, slcMetadata StatusListCredentialMetadata) (*credint.Container, error) {
+	statusListCredentialURI := gotCred.Credential.CredentialStatus.(map[string]any)["statusListCredential"].(string)
+
+	if len(statusListCredentialURI) == 0 {
+		return nil, sdkutil.LoggingNewErrorf("problem with getting status list credential id")
+	}
+
+	statusListCredentialID, err := parseIDFromURI(statusListCredentialURI)
+	if err != nil {
+		return nil, err
 	}
 
+	statusPurpose := statussdk.StatusPurpose(gotCred.GetStatusPurpose())
 	generatedStatusListCredential, err := statussdk.GenerateStatusList2021Credential(statusListCredentialURI, gotCred.Issuer, statusPurpose, revokedOrSuspendedStatusCreds)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not generate status list")
@@ -555,7 +1347,8 @@ func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCr
 
 	generatedStatusListCredential.CredentialSchema = gotCred.Credential.CredentialSchema
 
-	statusListCredJWT, err := s.signCredentialJWT(ctx, gotCred.FullyQualifiedVerificationMethodID, *generatedStatusListCredential)
+	verificationMethodID := s.statusListVerificationMethodID(gotCred.Issuer, gotCred.Schema, statusPurpose, gotCred.FullyQualifiedVerificationMethodID)
+	statusListCredJWT, err := s.signCredentialJWT(ctx, verificationMethodID, *generatedStatusListCredential, s.jwtClaimsPolicy, nil)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
 	}
@@ -563,12 +1356,12 @@ func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCr
 	// store the status list credential
 	statusListContainer := credint.Container{
 		ID:                                 statusListCredentialID,
-		FullyQualifiedVerificationMethodID: gotCred.FullyQualifiedVerificationMethodID,
+		FullyQualifiedVerificationMethodID: verificationMethodID,
 		Credential:                         generatedStatusListCredential,
 		CredentialJWT:                      statusListCredJWT,
 	}
 
-	storageRequest = StoreCredentialRequest{
+	storageRequest := StoreCredentialRequest{
 		Container: statusListContainer,
 	}
 
@@ -576,11 +1369,48 @@ func updateCredentialStatus(ctx context.Context, tx storage.Tx, s Service, gotCr
 		return nil, sdkutil.LoggingErrorMsg(err, "could not store credential status list")
 	}
 
-	return &container, nil
+	return &statusListContainer, nil
+}
+
+// credentialURI returns the `id` of the credential with the given UUID under the configured credential ID scheme.
+func (s Service) credentialURI(credentialID string) string {
+	switch s.config.IDScheme {
+	case "", config.URLCredentialIDScheme:
+		return config.GetServicePath(framework.Credential) + "/" + credentialID
+	case config.URNCredentialIDScheme:
+		return "urn:uuid:" + credentialID
+	default:
+		return s.config.IDScheme + credentialID
+	}
+}
+
+// statusEntryID returns the `id` of the status entry of the credential with the given `id`. URL credential IDs keep
+// the `/status` path used before other schemes were supported, while other IDs get a `#status` fragment.
+func (s Service) statusEntryID(credentialURI string) string {
+	switch s.config.IDScheme {
+	case "", config.URLCredentialIDScheme:
+		return credentialURI + "/status"
+	default:
+		return credentialURI + "#status"
+	}
+}
+
+const uuidStandardFormLen = 36
+
+// localCredentialID returns the ID a credential is stored under, given either that ID or the full `id` of the
+// credential under any credential ID scheme, all of which end with the UUID of the credential.
+func localCredentialID(id string) string {
+	if len(id) <= uuidStandardFormLen {
+		return id
+	}
+	localID := id[len(id)-uuidStandardFormLen:]
+	if _, err := uuid.Parse(localID); err != nil {
+		return id
+	}
+	return localID
 }
 
 func parseIDFromURI(uri string) (string, error) {
-	const uuidStandardFormLen = 36
 	if len(uri) < uuidStandardFormLen {
 		return "", sdkutil.LoggingNewErrorf("cannot infer status list credential id from %q", uri)
 	}
@@ -591,104 +1421,311 @@ func (s Service) DeleteCredential(ctx context.Context, request DeleteCredentialR
 
 	logrus.Debugf("deleting credential: %s", request.ID)
 
-	if err := s.storage.DeleteCredential(ctx, request.ID); err != nil {
+	var onDelete OnDeleteFunc
+	if s.config.EventLogEnabled {
+		onDelete = s.appendDeletedEvent(request.Principal)
+	}
+	if err := s.storage.DeleteCredential(ctx, request.ID, onDelete); err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "deleting credential with id: %s", request.ID)
 	}
 
 	return nil
 }
 
+// ErrBatchTooLarge is returned when a batch holds more credentials of an issuer, schema, and status purpose than
+// BatchCreateMaxItems.
+var ErrBatchTooLarge = errors.New("batch has too many credentials")
+
+// batchCreateItem is a credential of a batch, ready to be created in a transaction watching its keys.
+type batchCreateItem struct {
+	request        CreateCredentialRequest
+	statusMetadata StatusListCredentialMetadata
+	watchKeys      []storage.WatchKey
+}
+
+// BatchCreateCredentials creates the credentials of a batch in transactions of at most BatchCreateChunkSize
+// credentials, or in a single transaction when it is not set. When a transaction fails, the credentials of the
+// transactions committed before it are kept and reported along with the errors of the others. Unless ContinueOnError
+// is set, the credentials after the failed transaction are not attempted. An error is returned when no credential is
+// created. The issuance analytics event of each credential is emitted once the batch has been attempted.
+//
+// Credentials of a batch may be issued by several issuers. Each credential is validated against its own issuer, and
+// the credentials sharing an issuer, schema, and status purpose share its status list, whose indexes are allocated
+// within the transaction.
 func (s Service) BatchCreateCredentials(ctx context.Context, batchRequest BatchCreateCredentialsRequest) (*BatchCreateCredentialsResponse, error) {
-	watchKeys := make([]storage.WatchKey, 0, len(batchRequest.Requests)*3)
+	start := time.Now()
+	ctx, cancel := s.withBatchTimeout(ctx)
+	defer cancel()
+	created, err := s.batchCreateCredentials(ctx, batchRequest)
+	s.emitBatchIssuance(batchRequest, created, err, time.Since(start))
+	return created, err
+}
+
+func (s Service) batchCreateCredentials(ctx context.Context, batchRequest BatchCreateCredentialsRequest) (*BatchCreateCredentialsResponse, error) {
+	if err := s.checkBatchSize(batchRequest.Requests); err != nil {
+		return nil, err
+	}
+	requests := make([]CreateCredentialRequest, 0, len(batchRequest.Requests))
+	for i, request := range batchRequest.Requests {
+		if err := batchAborted(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.checkIssuerNotFrozen(ctx, request.Issuer); err != nil {
+			return nil, errors.Wrapf(err, "credential %d of batch", i)
+		}
+		request, err := s.applySchemaPolicies(ctx, request)
+		if err != nil {
+			return nil, errors.Wrapf(err, "credential %d of batch", i)
+		}
+		if err = s.checkAutoRenew(request); err != nil {
+			return nil, errors.Wrapf(err, "credential %d of batch", i)
+		}
+		if err = s.checkVerificationMethod(ctx, request); err != nil {
+			return nil, errors.Wrapf(err, "credential %d of batch", i)
+		}
+		requests = append(requests, request)
+	}
+	if err := s.checkBatchSubjectQuotas(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	items := make([]batchCreateItem, 0, len(requests))
+	uniqueSubjects := make(map[storage.WatchKey]bool)
+	uniqueCredentials := make(map[storage.WatchKey]bool)
+	statusMetadatas := make(map[statusListBinding]StatusListCredentialMetadata)
+	for i, request := range requests {
+		watchKeys := s.subjectQuotaWatchKeys(request)
+
+		// credentials created earlier in the batch are not visible to the uniqueness check of later ones
+		if request.isUnique() {
+			subjectWatchKey := s.storage.GetSubjectWatchKey(request.Issuer, request.SchemaID, request.Subject)
+			if uniqueSubjects[subjectWatchKey] {
+				return nil, sdkutil.LoggingNewErrorf("credential %d of batch: batch contains more than one credential of schema<%s> from issuer<%s> for subject<%s>", i, request.SchemaID, request.Issuer, request.Subject)
+			}
+			uniqueSubjects[subjectWatchKey] = true
+			watchKeys = append(watchKeys, s.uniquenessWatchKeys(request)...)
+		}
+
+		// nor are they visible when looking for a credential with the same deterministic ID
+		deterministicIDWatchKeys, err := s.deterministicIDWatchKeys(request)
+		if err != nil {
+			return nil, errors.Wrapf(err, "credential %d of batch", i)
+		}
+		for _, watchKey := range deterministicIDWatchKeys {
+			if uniqueCredentials[watchKey] {
+				return nil, sdkutil.LoggingNewErrorf("credential %d of batch: batch contains more than one credential with identical content for subject<%s>", i, request.Subject)
+			}
+			uniqueCredentials[watchKey] = true
+		}
+		watchKeys = append(watchKeys, deterministicIDWatchKeys...)
 
-	funcs := make([]storage.BusinessLogicFunc, 0, len(batchRequest.Requests))
-	for _, request := range batchRequest.Requests {
 		var statusMetadata StatusListCredentialMetadata
 		if request.hasStatus() && request.isStatusValid() {
-			statusPurpose := statussdk.StatusRevocation
+			statusPurpose := request.statusPurpose()
+			binding := statusListBinding{issuer: request.Issuer, schema: request.SchemaID, purpose: statusPurpose}
+			var ok bool
+			if statusMetadata, ok = statusMetadatas[binding]; !ok {
+				statusMetadata = StatusListCredentialMetadata{
+					statusListCredentialWatchKey:   s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statusPurpose)),
+					statusListIndexPoolWatchKey:    s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, request.SchemaID, string(statusPurpose)),
+					statusListCurrentIndexWatchKey: s.storage.GetStatusListCurrentIndexWatchKey(request.Issuer, request.SchemaID, string(statusPurpose)),
+					statusSize:                     request.statusSize(),
+				}
+				statusMetadatas[binding] = statusMetadata
+			}
+			watchKeys = append(watchKeys, statusMetadata.statusListCredentialWatchKey,
+				statusMetadata.statusListIndexPoolWatchKey, statusMetadata.statusListCurrentIndexWatchKey)
+		}
+
+		items = append(items, batchCreateItem{request: request, statusMetadata: statusMetadata, watchKeys: watchKeys})
+	}
 
-			if request.Suspendable {
-				statusPurpose = statussdk.StatusSuspension
+	resp := &BatchCreateCredentialsResponse{
+		Credentials:           make([]credint.Container, len(items)),
+		Warnings:              make([][]string, len(items)),
+		ReplacedCredentialIDs: make([][]string, len(items)),
+		Errors:                make([]string, len(items)),
+		Violations:            make([][]schemaint.Violation, len(items)),
+	}
+	chunkSize := s.config.BatchCreateChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(items)
+	}
+	var firstErr error
+	created := 0
+	for start := 0; start < len(items); start += chunkSize {
+		end := min(start+chunkSize, len(items))
+		if err := s.createBatchChunk(ctx, items[start:end], resp, start); err != nil {
+			logrus.WithError(err).Warnf("creating credentials %d to %d of batch", start, end-1)
+			if firstErr == nil {
+				firstErr = err
 			}
+			for i := start; i < end; i++ {
+				resp.Errors[i] = err.Error()
+			}
+			// once the batch is aborted, the remaining chunks would be aborted too
+			if !batchRequest.ContinueOnError || errors.Is(err, storage.ErrAborted) {
+				for i := end; i < len(items); i++ {
+					resp.Errors[i] = "not attempted after an earlier chunk of the batch failed"
+				}
+				break
+			}
+			continue
+		}
+		created += end - start
+	}
 
-			statusListCredentialWatchKey := s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
-			statusListCredentialIndexPoolWatchKey := s.storage.GetStatusListIndexPoolWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
-			statusListCredentialCurrentIndexWatchKey := s.storage.GetStatusListCurrentIndexWatchKey(request.Issuer, request.SchemaID, string(statusPurpose))
+	if created == 0 && firstErr != nil {
+		return nil, errors.Wrap(firstErr, "execute")
+	}
+	if firstErr == nil {
+		resp.Errors = nil
+		resp.Violations = nil
+	}
+	return resp, nil
+}
 
-			watchKeys = append(watchKeys, statusListCredentialWatchKey)
-			watchKeys = append(watchKeys, statusListCredentialIndexPoolWatchKey)
-			watchKeys = append(watchKeys, statusListCredentialCurrentIndexWatchKey)
+// checkBatchSize checks that a batch holds at most BatchCreateMaxItems credentials of each issuer, schema, and status
+// purpose, credentials without a status counting apart, so that a batch holds as many credentials as allowed for each
+// status list it updates.
+func (s Service) checkBatchSize(requests []CreateCredentialRequest) error {
+	counts := make(map[statusListBinding]int)
+	for _, request := range requests {
+		binding := statusListBinding{issuer: request.Issuer, schema: request.SchemaID}
+		if request.hasStatus() {
+			binding.purpose = request.statusPurpose()
+		}
+		counts[binding]++
+		if counts[binding] > s.config.BatchCreateMaxItems {
+			return sdkutil.LoggingError(errors.Wrapf(ErrBatchTooLarge, "max number of requests is %d for each issuer, schema, and status purpose", s.config.BatchCreateMaxItems))
+		}
+	}
+	return nil
+}
 
-			statusMetadata = StatusListCredentialMetadata{
-				statusListCredentialWatchKey:   statusListCredentialWatchKey,
-				statusListIndexPoolWatchKey:    statusListCredentialIndexPoolWatchKey,
-				statusListCurrentIndexWatchKey: statusListCredentialCurrentIndexWatchKey,
+// createBatchChunk creates the credentials of a chunk of a batch in a single transaction, setting them in the
+// response at the offset of the chunk. Its status lists are published once the transaction is committed.
+func (s Service) createBatchChunk(ctx context.Context, items []batchCreateItem, resp *BatchCreateCredentialsResponse, offset int) error {
+	// credentials sharing a status list watch its keys once
+	watchKeys := make([]storage.WatchKey, 0, len(items)*3)
+	watched := make(map[storage.WatchKey]bool, len(items)*3)
+	for _, item := range items {
+		for _, watchKey := range item.watchKeys {
+			if !watched[watchKey] {
+				watched[watchKey] = true
+				watchKeys = append(watchKeys, watchKey)
 			}
 		}
-
-		funcs = append(funcs, s.createCredentialFunc(request, statusMetadata))
 	}
 
-	returnFunc := storage.BusinessLogicFunc(func(ctx context.Context, tx storage.Tx) (any, error) {
-		resp := new(BatchCreateCredentialsResponse)
-		resp.Credentials = make([]credint.Container, len(batchRequest.Requests))
-		for i, f := range funcs {
-			credRespAny, err := f(ctx, tx)
-			if err != nil {
+	var statusLists []*credint.Container
+	// the index of the credential whose creation failed the transaction, if any
+	failed := -1
+	_, err := s.execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		// the transaction may be retried, so only the status lists of the last attempt are published, and the indexes of
+		// each status list are allocated anew
+		statusLists = make([]*credint.Container, 0, len(items))
+		failed = -1
+		allocations := make(map[storage.WatchKey]*statusListAllocation)
+		for i, item := range items {
+			// the transaction is rolled back once the batch is aborted, so the remaining credentials are not created
+			if err := batchAborted(ctx); err != nil {
 				return nil, err
 			}
-			credResp, ok := credRespAny.(*CreateCredentialResponse)
-			if !ok {
-				return nil, errors.New("problem casting to CreateCredentialResponse")
+			statusMetadata := item.statusMetadata
+			if statusMetadata.statusListCredentialWatchKey.Key != "" {
+				allocation, ok := allocations[statusMetadata.statusListCredentialWatchKey]
+				if !ok {
+					allocation = new(statusListAllocation)
+					allocations[statusMetadata.statusListCredentialWatchKey] = allocation
+				}
+				statusMetadata.allocation = allocation
+			}
+			credResp, err := s.createCredential(ctx, item.request, tx, statusMetadata)
+			if err != nil {
+				failed = offset + i
+				return nil, errors.Wrapf(err, "credential %d of batch", offset+i)
 			}
-			resp.Credentials[i] = credResp.Container
+			resp.Credentials[offset+i] = credResp.Container
+			resp.Warnings[offset+i] = credResp.Warnings
+			resp.ReplacedCredentialIDs[offset+i] = credResp.ReplacedCredentialIDs
+			statusLists = append(statusLists, credResp.statusList)
 		}
-		return resp, nil
-	})
-
-	returnValue, err := s.storage.db.Execute(ctx, returnFunc, watchKeys)
+		return nil, nil
+	}, watchKeys)
 	if err != nil {
-		return nil, errors.Wrap(err, "execute")
-	}
-
-	credResponse, ok := returnValue.(*BatchCreateCredentialsResponse)
-	if !ok {
-		return nil, errors.New("problem casting to BatchCreateCredentialsResponse")
+		// clear the credentials set by the transaction before it failed
+		for i := range items {
+			resp.Credentials[offset+i] = credint.Container{}
+			resp.Warnings[offset+i] = nil
+			resp.ReplacedCredentialIDs[offset+i] = nil
+		}
+		var validationErr *schemaint.ValidationError
+		if failed >= 0 && errors.As(err, &validationErr) {
+			resp.Violations[failed] = validationErr.Violations
+		}
+		return err
 	}
 
-	return credResponse, nil
+	s.publishStatusLists(statusLists...)
+	return nil
 }
 
 func (s Service) BatchUpdateCredentialStatus(ctx context.Context, batchRequest BatchUpdateCredentialStatusRequest) (*BatchUpdateCredentialStatusResponse, error) {
+	ctx, cancel := s.withBatchTimeout(ctx)
+	defer cancel()
 	watchKeys := make([]storage.WatchKey, 0, len(batchRequest.Requests))
+	statusLists := make([]StatusListResource, 0, len(batchRequest.Requests))
 	updateFuncs := make([]storage.BusinessLogicFunc, 0, len(batchRequest.Requests))
 	for _, request := range batchRequest.Requests {
-		statusListCredentialWatchKey, err := s.statusListCredentialWatchKey(ctx, request.ID)
+		if err := batchAborted(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.checkStatusReasonCode(request); err != nil {
+			return nil, err
+		}
+		if err := s.checkStatusReason(request); err != nil {
+			return nil, err
+		}
+		statusListCredentialWatchKey, statusList, err := s.statusListCredentialWatchKey(ctx, request.ID)
 		if err != nil {
 			return nil, err
 		}
+		if err = s.checkIssuerNotFrozen(ctx, statusList.Issuer); err != nil {
+			return nil, err
+		}
 		watchKeys = append(watchKeys, *statusListCredentialWatchKey)
+		if !slices.Contains(statusLists, *statusList) {
+			statusLists = append(statusLists, *statusList)
+		}
 
 		slcMetadata := StatusListCredentialMetadata{statusListCredentialWatchKey: *statusListCredentialWatchKey}
 		returnFunc := s.updateCredentialStatusFunc(request, slcMetadata)
 		updateFuncs = append(updateFuncs, returnFunc)
 	}
-	returnValue, err := s.storage.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+	var updatedStatusLists []*credint.Container
+	returnValue, err := s.execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		// the transaction may be retried, so only the status lists of the last attempt are published
+		updatedStatusLists = make([]*credint.Container, 0, len(updateFuncs))
 		batchResponse := BatchUpdateCredentialStatusResponse{
 			CredentialStatuses: make([]Status, 0, len(batchRequest.Requests)),
 		}
 		for i, updateFunc := range updateFuncs {
+			if err := batchAborted(ctx); err != nil {
+				return nil, err
+			}
 			updateResp, err := updateFunc(ctx, tx)
 			if err != nil {
 				return nil, err
 			}
 			batchResponse.CredentialStatuses = append(batchResponse.CredentialStatuses, updateResp.(*UpdateCredentialStatusResponse).Status)
+			updatedStatusLists = append(updatedStatusLists, updateResp.(*UpdateCredentialStatusResponse).statusList)
 			batchResponse.CredentialStatuses[i].ID = batchRequest.Requests[i].ID
 		}
 		return &batchResponse, nil
 	}, watchKeys)
 	if err != nil {
-		return nil, errors.Wrap(err, "execute")
+		return nil, errors.Wrap(statusListConflict(err, statusLists...), "execute")
 	}
 
 	batchResponse, ok := returnValue.(*BatchUpdateCredentialStatusResponse)
@@ -696,33 +1733,55 @@ func (s Service) BatchUpdateCredentialStatus(ctx context.Context, batchRequest B
 		return nil, errors.New("casting to BatchUpdateCredentialStatusResponse")
 	}
 
+	s.publishStatusLists(updatedStatusLists...)
 	return batchResponse, nil
 }
 
-func (s Service) statusListCredentialWatchKey(ctx context.Context, id string) (*storage.WatchKey, error) {
+// withBatchTimeout bounds the context of a batch by the configured batch timeout, if any.
+func (s Service) withBatchTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.batchTimeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.batchTimeout)
+}
+
+// batchAborted returns a storage.AbortedError once the context of a batch is done, so that the rest of the batch is
+// not processed, and the transaction in progress, if any, is rolled back.
+func batchAborted(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return storage.AbortedError{Cause: err}
+	}
+	return nil
+}
+
+func (s Service) statusListCredentialWatchKey(ctx context.Context, id string) (*storage.WatchKey, *StatusListResource, error) {
 	gotCred, err := s.storage.GetCredential(ctx, id)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading credential")
+		return nil, nil, errors.Wrap(err, "reading credential")
 	}
 
+	if gotCred.Imported {
+		return nil, nil, sdkutil.LoggingNewErrorf("credential %q was imported, so its status can only be changed by its issuer", gotCred.LocalCredentialID)
+	}
 	if !gotCred.HasCredentialStatus() {
-		return nil, sdkutil.LoggingNewErrorf("credential %q has no credentialStatus field", gotCred.LocalCredentialID)
+		return nil, nil, sdkutil.LoggingNewErrorf("credential %q has no credentialStatus field", gotCred.LocalCredentialID)
 	}
 
 	statusPurpose := gotCred.GetStatusPurpose()
 	if len(statusPurpose) == 0 {
-		return nil, sdkutil.LoggingNewErrorf("status purpose could not be derived from credential status")
+		return nil, nil, sdkutil.LoggingNewErrorf("status purpose could not be derived from credential status")
 	}
 
 	statusListCredential, err := s.storage.GetStatusListCredentialKeyData(ctx, gotCred.Issuer, gotCred.Schema, statussdk.StatusPurpose(statusPurpose))
 	if err != nil {
-		return nil, errors.Wrap(err, "getting status list watch key uuid data")
+		return nil, nil, errors.Wrap(err, "getting status list watch key uuid data")
 	}
 
 	if statusListCredential == nil {
-		return nil, errors.Wrap(err, "status list credential should exist in order to update")
+		return nil, nil, errors.Wrap(err, "status list credential should exist in order to update")
 	}
 
 	statusListCredentialWatchKey := s.storage.GetStatusListCredentialWatchKey(gotCred.Issuer, gotCred.Schema, statusPurpose)
-	return &statusListCredentialWatchKey, nil
+	statusList := StatusListResource{Issuer: gotCred.Issuer, SchemaID: gotCred.Schema, Purpose: statusPurpose}
+	return &statusListCredentialWatchKey, &statusList, nil
 }
@@ -0,0 +1,84 @@
+package credential
+
+import (
+	"fmt"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/config"
+)
+
+// ErrStatusSignerNotPermitted is returned when updating the status of a credential with a verification method other
+// than the one its status list is bound to.
+var ErrStatusSignerNotPermitted = errors.New("verification method is not permitted to update the status")
+
+// statusListBinding identifies the status lists of an issuer, schema, and purpose. An empty schema identifies the
+// status lists of every schema of the issuer.
+type statusListBinding struct {
+	issuer  string
+	schema  string
+	purpose statussdk.StatusPurpose
+}
+
+// statusListSigners holds the fully qualified verification methods status lists are bound to.
+type statusListSigners map[statusListBinding]string
+
+func newStatusListSigners(configs []config.StatusListSigningKeyConfig) (statusListSigners, error) {
+	signers := make(statusListSigners, len(configs))
+	for _, c := range configs {
+		if c.Issuer == "" || c.VerificationMethodID == "" {
+			return nil, errors.New("status list signing keys must have an issuer and a verification method")
+		}
+		purpose := statussdk.StatusPurpose(c.Purpose)
+		if purpose != statussdk.StatusRevocation && purpose != statussdk.StatusSuspension {
+			return nil, fmt.Errorf("status list signing key of issuer<%s> has unsupported purpose<%s>", c.Issuer, c.Purpose)
+		}
+		binding := statusListBinding{issuer: c.Issuer, schema: c.Schema, purpose: purpose}
+		if _, ok := signers[binding]; ok {
+			return nil, fmt.Errorf("issuer<%s> has more than one %s signing key for schema<%s>", c.Issuer, c.Purpose, c.Schema)
+		}
+		signers[binding] = did.FullyQualifiedVerificationMethodID(c.Issuer, c.VerificationMethodID)
+	}
+	return signers, nil
+}
+
+// boundVerificationMethodID returns the verification method the status lists of the issuer, schema, and purpose are
+// bound to, falling back to the binding of the issuer for every schema.
+func (s statusListSigners) boundVerificationMethodID(issuer, schemaID string, purpose statussdk.StatusPurpose) (string, bool) {
+	if verificationMethodID, ok := s[statusListBinding{issuer: issuer, schema: schemaID, purpose: purpose}]; ok {
+		return verificationMethodID, true
+	}
+	verificationMethodID, ok := s[statusListBinding{issuer: issuer, purpose: purpose}]
+	return verificationMethodID, ok
+}
+
+// statusListVerificationMethodID returns the verification method signing the status list of the issuer, schema, and
+// purpose. This is the one it is bound to, or else the one its credentials are issued with.
+func (s Service) statusListVerificationMethodID(issuer, schemaID string, purpose statussdk.StatusPurpose, credentialVerificationMethodID string) string {
+	if verificationMethodID, ok := s.statusListSigners.boundVerificationMethodID(issuer, schemaID, purpose); ok {
+		return verificationMethodID
+	}
+	return credentialVerificationMethodID
+}
+
+// checkStatusSigner rejects a status update requested with a verification method other than the one signing the
+// status list of the credential. A status list bound to a verification method can only be updated by requests naming
+// it, while the requested verification method is optional for other status lists. The key of the verification method
+// is then checked against the key store when the status list is signed.
+func (s Service) checkStatusSigner(gotCred *StoredCredential, requestedVerificationMethodID string) error {
+	purpose := statussdk.StatusPurpose(gotCred.GetStatusPurpose())
+	bound, ok := s.statusListSigners.boundVerificationMethodID(gotCred.Issuer, gotCred.Schema, purpose)
+	if !ok {
+		if requestedVerificationMethodID == "" {
+			return nil
+		}
+		bound = did.FullyQualifiedVerificationMethodID(gotCred.Issuer, gotCred.FullyQualifiedVerificationMethodID)
+	}
+	if requestedVerificationMethodID == "" || did.FullyQualifiedVerificationMethodID(gotCred.Issuer, requestedVerificationMethodID) != bound {
+		return sdkutil.LoggingError(errors.Wrapf(ErrStatusSignerNotPermitted, "%s status of credential<%s> must be updated with verification method<%s>", purpose, gotCred.LocalCredentialID, bound))
+	}
+	return nil
+}
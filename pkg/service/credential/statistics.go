@@ -0,0 +1,185 @@
+package credential
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// StatisticsPageSize is the number of stored credentials read at a time while computing the statistics of an issuer.
+const StatisticsPageSize = 1000
+
+type GetIssuerStatisticsRequest struct {
+	Issuer string `json:"issuer" validate:"required"`
+}
+
+// IssuerCredentialCounts counts the credentials of an issuer by status.
+type IssuerCredentialCounts struct {
+	Total int `json:"total"`
+	// Credentials which are neither revoked, suspended, nor expired.
+	Active    int `json:"active"`
+	Revoked   int `json:"revoked"`
+	Suspended int `json:"suspended"`
+	// Expired credentials which are not revoked nor suspended.
+	Expired int `json:"expired"`
+
+	// Number of credentials by schema ID, with credentials issued without a schema counted under an empty ID.
+	BySchema map[string]int `json:"bySchema"`
+}
+
+type GetIssuerStatisticsResponse struct {
+	Issuer      string                 `json:"issuer"`
+	Credentials IssuerCredentialCounts `json:"credentials"`
+
+	// The capacity of each status list of the issuer, across its schemas and purposes.
+	StatusLists []StatusListCapacity `json:"statusLists"`
+	// Number of indexes allocated, and remaining, across the status lists of the issuer.
+	AllocatedIndexes int `json:"allocatedIndexes"`
+	RemainingIndexes int `json:"remainingIndexes"`
+	// Ratio of allocated indexes to the indexes of the status lists of the issuer, between 0 and 1.
+	IndexUtilization float64 `json:"indexUtilization"`
+}
+
+// GetIssuerStatistics summarizes the credentials and status lists of an issuer. Credentials are scanned one page at a
+// time, decoding only the metadata of those of the issuer, so that the summary of issuers with many credentials does
+// not hold them all in memory.
+func (s Service) GetIssuerStatistics(ctx context.Context, request GetIssuerStatisticsRequest) (*GetIssuerStatisticsResponse, error) {
+	logrus.Debugf("getting statistics of issuer: %s", request.Issuer)
+
+	counts, err := s.storage.CountCredentialsByIssuer(ctx, request.Issuer, StatisticsPageSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "counting credentials of issuer")
+	}
+	response := GetIssuerStatisticsResponse{
+		Issuer:      request.Issuer,
+		Credentials: *counts,
+		StatusLists: make([]StatusListCapacity, 0),
+	}
+
+	statusLists, err := s.storage.ListStatusListsByIssuer(ctx, request.Issuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing status lists of issuer")
+	}
+	for _, statusList := range statusLists {
+		capacity, err := s.GetStatusListCapacity(ctx, GetStatusListCapacityRequest{
+			Issuer:   request.Issuer,
+			SchemaID: statusList.Schema,
+			Purpose:  statusList.Purpose,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting capacity of %s status list of schema<%s>", statusList.Purpose, statusList.Schema)
+		}
+		for _, listCapacity := range capacity.StatusLists {
+			response.StatusLists = append(response.StatusLists, listCapacity)
+			response.AllocatedIndexes += listCapacity.Allocated
+			response.RemainingIndexes += listCapacity.Remaining
+		}
+	}
+	if total := response.AllocatedIndexes + response.RemainingIndexes; total > 0 {
+		response.IndexUtilization = float64(response.AllocatedIndexes) / float64(total)
+	}
+	return &response, nil
+}
+
+// CountCredentialsByIssuer counts the credentials of an issuer by status and schema, reading the credential namespace
+// in pages of the given size. Only entries whose key contains the issuer are decoded.
+func (cs *Storage) CountCredentialsByIssuer(ctx context.Context, issuer string, pageSize int) (*IssuerCredentialCounts, error) {
+	counts := IssuerCredentialCounts{BySchema: make(map[string]int)}
+	query := storage.Join("", "is", issuer, "su", "")
+	now := time.Now()
+	pageToken := ""
+	for {
+		page, nextPageToken, err := cs.db.ReadPage(ctx, credentialNamespace, pageToken, pageSize)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while counting creds of issuer: %s", issuer)
+		}
+		for key, data := range page {
+			if !strings.Contains(key, query) {
+				continue
+			}
+			var metadata StoredCredentialMetadata
+			if err = json.Unmarshal(data, &metadata); err != nil {
+				logrus.WithError(err).Warnf("skipping credential entry<%s> which can't be unmarshalled", key)
+				continue
+			}
+			if metadata.Issuer != issuer {
+				continue
+			}
+			counts.add(metadata, now)
+		}
+		if nextPageToken == "" {
+			return &counts, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+func (c *IssuerCredentialCounts) add(metadata StoredCredentialMetadata, now time.Time) {
+	c.Total++
+	c.BySchema[metadata.Schema]++
+	switch {
+	case metadata.Revoked:
+		c.Revoked++
+	case metadata.Suspended:
+		c.Suspended++
+	case isExpiredAt(metadata.GetExpirationDate(), now):
+		c.Expired++
+	default:
+		c.Active++
+	}
+}
+
+// isExpiredAt returns whether an RFC3339 expiration date, if any, has passed at the given time.
+func isExpiredAt(expirationDate string, now time.Time) bool {
+	if expirationDate == "" {
+		return false
+	}
+	expiration, err := time.Parse(time.RFC3339, expirationDate)
+	return err == nil && expiration.Before(now)
+}
+
+// IssuerStatusList identifies a status list of an issuer.
+type IssuerStatusList struct {
+	Schema  string
+	Purpose statussdk.StatusPurpose
+}
+
+// ListStatusListsByIssuer returns the schema and purpose of each status list of an issuer, from the keys of their
+// status list credentials.
+func (cs *Storage) ListStatusListsByIssuer(ctx context.Context, issuer string) ([]IssuerStatusList, error) {
+	prefix := storage.Join("is", issuer, "sc", "")
+	purposeSeparator := storage.Join("", "sp", "")
+	statusListCredentials, err := cs.db.ReadPrefix(ctx, statusListCredentialNamespace, prefix)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read status list credentials of issuer: %s", issuer)
+	}
+	statusLists := make([]IssuerStatusList, 0, len(statusListCredentials))
+	for key := range statusListCredentials {
+		schemaAndPurpose := strings.TrimPrefix(key, prefix)
+		separator := strings.LastIndex(schemaAndPurpose, purposeSeparator)
+		if separator < 0 {
+			logrus.Warnf("skipping status list credential with malformed key: %s", key)
+			continue
+		}
+		statusLists = append(statusLists, IssuerStatusList{
+			Schema:  schemaAndPurpose[:separator],
+			Purpose: statussdk.StatusPurpose(schemaAndPurpose[separator+len(purposeSeparator):]),
+		})
+	}
+	sort.Slice(statusLists, func(i, j int) bool {
+		if statusLists[i].Schema != statusLists[j].Schema {
+			return statusLists[i].Schema < statusLists[j].Schema
+		}
+		return statusLists[i].Purpose < statusLists[j].Purpose
+	})
+	return statusLists, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
@@ -13,38 +14,115 @@ import (
 
 	"github.com/tbd54566975/ssi-service/config"
 	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
+// applySchemaPolicies applies the status and uniqueness policies, the render method, the validity duration, the
+// injected claims, and the expectations of the schema the credential is requested against, if any.
+func (s Service) applySchemaPolicies(ctx context.Context, request CreateCredentialRequest) (CreateCredentialRequest, error) {
+	if request.SchemaID == "" {
+		return request, nil
+	}
+	gotSchema, err := s.schema.GetSchema(ctx, schema.GetSchemaRequest{ID: request.SchemaID})
+	if err != nil {
+		return request, sdkutil.LoggingErrorMsgf(err, "failed to create credential; could not get schema: %s", request.SchemaID)
+	}
+	if policy := gotSchema.UniquenessPolicy; policy != nil {
+		request.UniqueSubject = true
+		request.ReplaceExisting = request.ReplaceExisting || policy.ReplaceExisting
+	}
+	request.renderMethod = gotSchema.RenderMethod
+	request.expectedClaims = gotSchema.ExpectedClaims
+	request.expectedType = gotSchema.ExpectedType
+	request.subjectQuota = gotSchema.SubjectCredentialQuota
+	if request.Expiry == "" && gotSchema.ValidityDuration != "" {
+		validity, err := schema.ParseValidityDuration(gotSchema.ValidityDuration)
+		if err != nil {
+			return request, sdkutil.LoggingErrorMsgf(err, "schema<%s> has an invalid validity duration", request.SchemaID)
+		}
+		request.Expiry = s.Clock.Now().Add(validity).Format(time.RFC3339)
+	}
+	if request, err = injectClaims(request, gotSchema.ClaimInjection); err != nil {
+		return request, err
+	}
+	return applyStatusPolicy(request, gotSchema.StatusPolicy)
+}
+
+// applyStatusPolicy applies the status policy of the schema the credential is requested against, if any. A request
+// which asks for neither a revocable nor a suspendable credential is given the default status of the policy, and a
+// request which asks for a status other than a mandatory default is rejected.
+func applyStatusPolicy(request CreateCredentialRequest, policy *schema.StatusPolicy) (CreateCredentialRequest, error) {
+	if policy == nil {
+		return request, nil
+	}
+
+	if !request.hasStatus() {
+		request.Revocable = policy.Default == schema.RevocableStatusPolicy
+		request.Suspendable = policy.Default == schema.SuspendableStatusPolicy
+		return request, nil
+	}
+	requested := schema.RevocableStatusPolicy
+	if request.Suspendable {
+		requested = schema.SuspendableStatusPolicy
+	} else if request.MessageStatus != nil {
+		requested = schema.StatusPolicyType(MessageStatusPurpose)
+	}
+	if policy.Mandatory && requested != policy.Default {
+		return request, sdkutil.LoggingNewErrorf("schema<%s> requires credentials to have status<%s>, but status<%s> was requested", request.SchemaID, policy.Default, requested)
+	}
+	return request, nil
+}
+
 func (s Service) createStatusListEntryForCredential(ctx context.Context, credID string, request CreateCredentialRequest,
-	tx storage.Tx, statusMetadata StatusListCredentialMetadata) (*statussdk.StatusList2021Entry, error) {
+	tx storage.Tx, statusMetadata StatusListCredentialMetadata) (any, error) {
 	issuerID := request.Issuer
-	fullyQualifiedVerificationMethodID := request.FullyQualifiedVerificationMethodID
 	schemaID := request.SchemaID
 
-	statusPurpose := statussdk.StatusRevocation
-	if request.Suspendable {
-		statusPurpose = statussdk.StatusSuspension
-	}
+	statusPurpose := request.statusPurpose()
+	fullyQualifiedVerificationMethodID := s.statusListVerificationMethodID(issuerID, schemaID, statusPurpose, request.FullyQualifiedVerificationMethodID)
 
 	var statusCred *credential.VerifiableCredential
 	var statusListCredentialID string
-	var randomIndex int
+	var randomIndex, allocated int
 	var err error
+	allocation := statusMetadata.allocation
+	if allocation != nil && allocation.statusList != nil {
+		// the list was read or created for an earlier credential of the batch, whose writes are not visible yet
+		return s.allocatedStatusListEntry(ctx, credID, request, tx, statusMetadata)
+	}
 	statusListCredential, err := s.storage.GetStatusListCredentialKeyData(ctx, issuerID, schemaID, statusPurpose)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting status list credential key data")
 	}
 
+	if statusListCredential != nil && allocation != nil {
+		if request.MessageStatus != nil {
+			if err = checkMessageStatusList(*statusListCredential.Credential, *request.MessageStatus); err != nil {
+				return nil, err
+			}
+		}
+		allocation.statusList = statusListCredential.Credential
+		return s.allocatedStatusListEntry(ctx, credID, request, tx, statusMetadata)
+	}
 	if statusListCredential == nil {
 		// creates status list credential with random index
-		randomIndex, statusCred, err = s.createStatusListCredential(ctx, tx, statusPurpose, issuerID, fullyQualifiedVerificationMethodID, statusMetadata)
+		randomIndex, statusCred, err = s.createStatusListCredential(ctx, tx, request, issuerID, fullyQualifiedVerificationMethodID, statusMetadata)
 		if err != nil {
 			return nil, sdkutil.LoggingErrorMsgf(err, "problem with getting status list credential")
 		}
 
 		statusListCredentialID = statusCred.ID
+		allocated = 1
+		if allocation != nil {
+			allocation.statusList = statusCred
+		}
 	} else {
+		if request.MessageStatus != nil {
+			if err = checkMessageStatusList(*statusListCredential.Credential, *request.MessageStatus); err != nil {
+				return nil, err
+			}
+		}
 		randomIndex, err = s.storage.GetNextStatusListRandomIndex(ctx, statusMetadata)
 		if err != nil {
 			return nil, sdkutil.LoggingErrorMsg(err, "problem with getting status list index")
@@ -54,46 +132,98 @@ func (s Service) createStatusListEntryForCredential(ctx context.Context, credID
 		if err = s.storage.IncrementStatusListIndexTx(ctx, tx, statusMetadata); err != nil {
 			return nil, errors.Wrap(err, "incrementing status list index")
 		}
+		// the increment is only visible once the transaction commits
+		if allocated, err = s.storage.GetStatusListCurrentIndex(ctx, issuerID, schemaID, string(statusPurpose)); err != nil {
+			return nil, errors.Wrap(err, "getting status list index")
+		}
+		allocated++
+	}
+	s.checkStatusListCapacity(ctx, issuerID, schemaID, statusPurpose, statusMetadata.statusSize, allocated)
+	return s.statusListEntry(credID, request, statusListCredentialID, randomIndex), nil
+}
+
+// allocatedStatusListEntry creates the status entry of a credential of a batch from the status list the batch shares,
+// which was read or created in the transaction.
+func (s Service) allocatedStatusListEntry(ctx context.Context, credID string, request CreateCredentialRequest,
+	tx storage.Tx, statusMetadata StatusListCredentialMetadata) (any, error) {
+	statusList := statusMetadata.allocation.statusList
+	if request.MessageStatus != nil {
+		if err := checkMessageStatusList(*statusList, *request.MessageStatus); err != nil {
+			return nil, err
+		}
+	}
+	randomIndex, allocated, err := s.storage.AllocateStatusListIndexTx(ctx, tx, statusMetadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "allocating status list index")
 	}
+	statusPurpose := request.statusPurpose()
+	s.checkStatusListCapacity(ctx, request.Issuer, request.SchemaID, statusPurpose, statusMetadata.statusSize, allocated)
+	return s.statusListEntry(credID, request, statusList.ID, randomIndex), nil
+}
 
+// statusListEntry returns the status entry of a credential at the given index of a status list.
+func (s Service) statusListEntry(credID string, request CreateCredentialRequest, statusListCredentialID string, randomIndex int) any {
+	statusPurpose := request.statusPurpose()
 	indexStr := strconv.Itoa(randomIndex)
-	return &statussdk.StatusList2021Entry{
-		ID:                   fmt.Sprintf(`%s/status`, credID),
+	entry := statussdk.StatusList2021Entry{
+		ID:                   s.statusEntryID(credID),
 		Type:                 statussdk.StatusList2021EntryType,
 		StatusPurpose:        statusPurpose,
 		StatusListIndex:      indexStr,
 		StatusListCredential: statusListCredentialID,
-	}, nil
+	}
+	if request.MessageStatus != nil {
+		return &messageStatusEntry{
+			StatusList2021Entry: entry,
+			StatusSize:          request.MessageStatus.Size,
+			StatusMessage:       request.MessageStatus.Messages,
+		}
+	}
+	return &entry
 }
 
-func (s Service) createStatusListCredential(ctx context.Context, tx storage.Tx, statusPurpose statussdk.StatusPurpose, issuerID, fullyQualifiedVerificationMethodID string, slcMetadata StatusListCredentialMetadata) (int, *credential.VerifiableCredential, error) {
-	statusListID := uuid.NewString()
-	statusListURI := fmt.Sprintf("%s/%s", config.GetStatusBase(), statusListID)
-	generatedStatusListCredential, err := statussdk.GenerateStatusList2021Credential(statusListURI, issuerID, statusPurpose, []credential.VerifiableCredential{})
+func (s Service) createStatusListCredential(ctx context.Context, tx storage.Tx, request CreateCredentialRequest, issuerID, fullyQualifiedVerificationMethodID string, slcMetadata StatusListCredentialMetadata) (int, *credential.VerifiableCredential, error) {
+	statusListContainer, err := s.newStatusListCredential(ctx, request, issuerID, fullyQualifiedVerificationMethodID)
 	if err != nil {
-		return -1, nil, sdkutil.LoggingErrorMsg(err, "could not generate status list")
+		return -1, nil, err
 	}
 
-	statusListCredJWT, err := s.signCredentialJWT(ctx, fullyQualifiedVerificationMethodID, *generatedStatusListCredential)
-	if err != nil {
-		return -1, nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
+	statusListStorageRequest := StoreCredentialRequest{
+		Container: *statusListContainer,
 	}
 
-	statusListContainer := credint.Container{
-		ID:                                 statusListID,
-		FullyQualifiedVerificationMethodID: fullyQualifiedVerificationMethodID,
-		Credential:                         generatedStatusListCredential,
-		CredentialJWT:                      statusListCredJWT,
+	randomIndex, err := s.storage.CreateStatusListCredentialTx(ctx, tx, statusListStorageRequest, slcMetadata)
+	if err != nil {
+		return -1, nil, errors.Wrap(err, "creating status list credential")
 	}
 
-	statusListStorageRequest := StoreCredentialRequest{
-		Container: statusListContainer,
+	return randomIndex, statusListContainer.Credential, nil
+}
+
+// newStatusListCredential generates and signs an empty status list credential for the status of the request.
+func (s Service) newStatusListCredential(ctx context.Context, request CreateCredentialRequest, issuerID, fullyQualifiedVerificationMethodID string) (*credint.Container, error) {
+	statusListID := uuid.NewString()
+	statusListURI := fmt.Sprintf("%s/%s", config.GetStatusBase(), statusListID)
+	var generatedStatusListCredential *credential.VerifiableCredential
+	var err error
+	if request.MessageStatus != nil {
+		generatedStatusListCredential, err = generateMessageStatusListCredential(statusListURI, issuerID, *request.MessageStatus, nil)
+	} else {
+		generatedStatusListCredential, err = statussdk.GenerateStatusList2021Credential(statusListURI, issuerID, request.statusPurpose(), []credential.VerifiableCredential{})
+	}
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not generate status list")
 	}
 
-	randomIndex, err := s.storage.CreateStatusListCredentialTx(ctx, tx, statusListStorageRequest, slcMetadata)
+	statusListCredJWT, err := s.signCredentialJWT(ctx, fullyQualifiedVerificationMethodID, *generatedStatusListCredential, s.jwtClaimsPolicy, nil)
 	if err != nil {
-		return -1, nil, errors.Wrap(err, "creating status list credential")
+		return nil, sdkutil.LoggingErrorMsg(err, "could not sign status list credential")
 	}
 
-	return randomIndex, generatedStatusListCredential, nil
+	return &credint.Container{
+		ID:                                 statusListID,
+		FullyQualifiedVerificationMethodID: fullyQualifiedVerificationMethodID,
+		Credential:                         generatedStatusListCredential,
+		CredentialJWT:                      statusListCredJWT,
+	}, nil
 }
@@ -0,0 +1,91 @@
+package credential
+
+import (
+	"context"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+// StatusAction is a status update of a credential with a fixed intent, such as revoking it.
+type StatusAction string
+
+const (
+	RevokeStatusAction    StatusAction = "revoke"
+	SuspendStatusAction   StatusAction = "suspend"
+	UnsuspendStatusAction StatusAction = "unsuspend"
+)
+
+var (
+	// ErrStatusActionConflict is returned when a status action does not apply to the status of a credential, such as
+	// suspending a credential whose status list is a revocation list, or revoking a credential issued without status.
+	ErrStatusActionConflict = errors.New("status action conflicts with the status of the credential")
+	// ErrStatusReasonRequired is returned when revoking or suspending a credential without a reason, while reasons
+	// are required.
+	ErrStatusReasonRequired = errors.New("status reason is required")
+)
+
+type StatusActionRequest struct {
+	ID                   string       `json:"id" validate:"required"`
+	Action               StatusAction `json:"action" validate:"required"`
+	VerificationMethodID string       `json:"verificationMethodId,omitempty"`
+	Reason               string       `json:"reason,omitempty"`
+	ReasonCode           string       `json:"reasonCode,omitempty"`
+	// Principal applying the action, recorded in the event log.
+	Principal string `json:"principal,omitempty"`
+}
+
+// statusPurpose returns the status purpose a credential must have for the action to apply to it.
+func (a StatusAction) statusPurpose() (statussdk.StatusPurpose, error) {
+	switch a {
+	case RevokeStatusAction:
+		return statussdk.StatusRevocation, nil
+	case SuspendStatusAction, UnsuspendStatusAction:
+		return statussdk.StatusSuspension, nil
+	default:
+		return "", errors.Errorf("unknown status action: %s", a)
+	}
+}
+
+// ApplyStatusAction updates the status of a credential with a fixed intent. Unlike UpdateCredentialStatus, the action
+// must match the status purpose of the credential, so that, for instance, a credential which can only be revoked is
+// never given a suspended status.
+func (s Service) ApplyStatusAction(ctx context.Context, request StatusActionRequest) (*UpdateCredentialStatusResponse, error) {
+	purpose, err := request.Action.statusPurpose()
+	if err != nil {
+		return nil, sdkutil.LoggingError(err)
+	}
+
+	gotCred, err := s.storage.GetCredential(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
+	}
+	if !gotCred.HasCredentialStatus() {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrStatusActionConflict, "cannot %s credential<%s> which was issued without status", request.Action, request.ID))
+	}
+	if gotPurpose := gotCred.GetStatusPurpose(); gotPurpose != string(purpose) {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrStatusActionConflict, "cannot %s credential<%s> whose status purpose is %s", request.Action, request.ID, gotPurpose))
+	}
+
+	return s.UpdateCredentialStatus(ctx, UpdateCredentialStatusRequest{
+		ID:                   request.ID,
+		Revoked:              request.Action == RevokeStatusAction,
+		Suspended:            request.Action == SuspendStatusAction,
+		VerificationMethodID: request.VerificationMethodID,
+		Reason:               request.Reason,
+		ReasonCode:           request.ReasonCode,
+		Principal:            request.Principal,
+	})
+}
+
+// checkStatusReason rejects revoking or suspending a credential without a reason, when reasons are required.
+func (s Service) checkStatusReason(request UpdateCredentialStatusRequest) error {
+	if !s.config.RequireStatusReason || (!request.Revoked && !request.Suspended) || request.Reason != "" {
+		return nil
+	}
+	return sdkutil.LoggingError(errors.Wrapf(ErrStatusReasonRequired, "revoking or suspending credential<%s>", request.ID))
+}
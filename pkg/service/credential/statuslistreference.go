@@ -0,0 +1,297 @@
+package credential
+
+import (
+	"context"
+	"sort"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// StatusListPageSize is the number of stored credentials, or of references to a status list, read at a time while
+// regenerating a status list.
+const StatusListPageSize = 1000
+
+// StatusListReference is a credential whose status entry points at a status list credential. It is stored with the
+// status of the credential, which is written along with the credential.
+type StatusListReference struct {
+	CredentialID  string                  `json:"credentialId"`
+	StatusPurpose statussdk.StatusPurpose `json:"statusPurpose"`
+	// Index of the credential within the status list.
+	StatusListIndex string `json:"statusListIndex"`
+	Revoked         bool   `json:"revoked"`
+	Suspended       bool   `json:"suspended"`
+	// The status value of the credential, for message status lists.
+	StatusValue string `json:"statusValue,omitempty"`
+}
+
+// isSet returns whether the entry of the credential in the status list is set: its bit for revocation and suspension
+// status lists, or a non-zero status value for message status lists.
+func (r StatusListReference) isSet() bool {
+	switch r.StatusPurpose {
+	case statussdk.StatusSuspension:
+		return r.Suspended
+	case MessageStatusPurpose:
+		if r.StatusValue == "" {
+			return false
+		}
+		// values which can't be parsed are visited, so that they are reported
+		value, err := parseStatusValue(r.StatusValue)
+		return err != nil || value != 0
+	default:
+		return r.Revoked
+	}
+}
+
+type ListStatusListCredentialsRequest struct {
+	// ID of the status list credential.
+	ID          string
+	PageRequest *pagination.PageRequest
+}
+
+type ListStatusListCredentialsResponse struct {
+	Credentials   []StatusListReference
+	NextPageToken string
+}
+
+// ListStatusListCredentials returns the credentials whose status entries point at a status list credential, along
+// with their index within the list and their current status, ordered by credential ID.
+func (s Service) ListStatusListCredentials(ctx context.Context, request ListStatusListCredentialsRequest) (*ListStatusListCredentialsResponse, error) {
+	logrus.Debugf("listing credentials of status list: %s", request.ID)
+
+	if _, err := s.storage.GetStatusListCredential(ctx, request.ID); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get status list credential: %s", request.ID)
+	}
+
+	page := request.PageRequest.ToServicePage()
+	references, nextPageToken, err := s.storage.ListStatusListReferences(ctx, request.ID, page.Token, page.Size)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not list credentials of status list: %s", request.ID)
+	}
+
+	credentials := make([]StatusListReference, 0, len(references))
+	for _, reference := range references {
+		gotCred, err := s.storage.GetCredentialIfExists(ctx, reference.CredentialID)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", reference.CredentialID)
+		}
+		if gotCred == nil {
+			logrus.Warnf("credential<%s> of status list<%s> no longer exists", reference.CredentialID, request.ID)
+			continue
+		}
+		reference.Revoked = gotCred.Revoked
+		reference.Suspended = gotCred.Suspended
+		credentials = append(credentials, reference)
+	}
+	return &ListStatusListCredentialsResponse{Credentials: credentials, NextPageToken: nextPageToken}, nil
+}
+
+// ListStatusListReferences returns at most limit references to a status list whose key sorts after the page token,
+// all of them when limit is -1, along with the token of the next page when more references follow them.
+func (cs *Storage) ListStatusListReferences(ctx context.Context, statusListID, pageToken string, limit int) ([]StatusListReference, string, error) {
+	referenceValues, err := cs.db.ReadAll(ctx, statusListReferencesNamespace(statusListID))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "reading references to status list<%s>", statusListID)
+	}
+	keys := make([]string, 0, len(referenceValues))
+	for key := range referenceValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keys = keys[sort.Search(len(keys), func(i int) bool { return keys[i] > pageToken }):]
+	var nextPageToken string
+	if limit >= 0 && len(keys) > limit {
+		keys = keys[:limit]
+		nextPageToken = keys[len(keys)-1]
+	}
+
+	references := make([]StatusListReference, 0, len(keys))
+	for _, key := range keys {
+		var reference StatusListReference
+		if err = json.Unmarshal(referenceValues[key], &reference); err != nil {
+			return nil, "", errors.Wrapf(err, "unmarshalling status list reference<%s>", key)
+		}
+		references = append(references, reference)
+	}
+	return references, nextPageToken, nil
+}
+
+// ForEachStatusListReference calls visit with each reference to a status list, in no particular order. References are
+// read a page of the given size at a time, so that no more than a page is held in memory.
+func (cs *Storage) ForEachStatusListReference(ctx context.Context, statusListID string, pageSize int, visit func(reference StatusListReference) error) error {
+	namespace := statusListReferencesNamespace(statusListID)
+	pageToken := ""
+	for {
+		page, nextPageToken, err := cs.db.ReadPage(ctx, namespace, pageToken, pageSize)
+		if err != nil {
+			return errors.Wrapf(err, "reading page of references to status list<%s>", statusListID)
+		}
+		for key, referenceBytes := range page {
+			var reference StatusListReference
+			if err = json.Unmarshal(referenceBytes, &reference); err != nil {
+				return errors.Wrapf(err, "unmarshalling status list reference<%s>", key)
+			}
+			if err = visit(reference); err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// statusListReferencesNamespace returns the namespace of the references to a status list, which are keyed by the ID of
+// the credential, so that they are paged through without reading those of other status lists.
+func statusListReferencesNamespace(statusListID string) string {
+	return storage.MakeNamespace(statusListReferenceNamespace, statusListID)
+}
+
+// storeStatusListReferencesTx records the index and the status of the credential within each status list its status
+// entries point at, so that the credentials of a status list can be listed without reading every credential, and only
+// those whose entry is set are read while regenerating it.
+func (cs *Storage) storeStatusListReferencesTx(ctx context.Context, tx storage.Tx, container credint.Container) error {
+	for _, entry := range statusListEntries(container.Credential) {
+		statusListID, err := parseIDFromURI(entry.StatusListCredential)
+		if err != nil {
+			return errors.Wrapf(err, "parsing status list of credential<%s>", container.ID)
+		}
+		referenceBytes, err := json.Marshal(StatusListReference{
+			CredentialID:    container.ID,
+			StatusPurpose:   entry.StatusPurpose,
+			StatusListIndex: entry.StatusListIndex,
+			Revoked:         container.Revoked,
+			Suspended:       container.Suspended,
+			StatusValue:     container.StatusValue,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "marshalling status list reference of credential<%s>", container.ID)
+		}
+		if err = tx.Write(ctx, statusListReferencesNamespace(statusListID), container.ID, referenceBytes); err != nil {
+			return errors.Wrapf(err, "writing status list reference of credential<%s>", container.ID)
+		}
+	}
+	return nil
+}
+
+// deleteStatusListReferencesTx removes the references of a deleted credential to its status lists. Credentials stored
+// before references were recorded have none to remove.
+func (cs *Storage) deleteStatusListReferencesTx(ctx context.Context, tx storage.Tx, deleted StoredCredential) error {
+	for _, entry := range statusListEntries(deleted.Credential) {
+		statusListID, err := parseIDFromURI(entry.StatusListCredential)
+		if err != nil {
+			continue
+		}
+		namespace := statusListReferencesNamespace(statusListID)
+		referenceBytes, err := cs.db.Read(ctx, namespace, deleted.LocalCredentialID)
+		if err != nil {
+			return errors.Wrapf(err, "reading status list reference of credential<%s>", deleted.LocalCredentialID)
+		}
+		if len(referenceBytes) == 0 {
+			continue
+		}
+		if err = tx.Delete(ctx, namespace, deleted.LocalCredentialID); err != nil {
+			return errors.Wrapf(err, "deleting status list reference of credential<%s>", deleted.LocalCredentialID)
+		}
+	}
+	return nil
+}
+
+// statusListEntries returns the status list entries of a credential, skipping entries of other types.
+func statusListEntries(cred *credential.VerifiableCredential) []statussdk.StatusList2021Entry {
+	if cred == nil {
+		return nil
+	}
+	var entries []statussdk.StatusList2021Entry
+	for _, status := range statusEntries(cred.CredentialStatus) {
+		entry, err := toStatusList2021Entry(status)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+	return entries
+}
+
+// markStatusListIndexedTx records that the references to a new status list are all recorded, since every credential
+// using it is stored after it.
+func (cs *Storage) markStatusListIndexedTx(ctx context.Context, tx storage.Tx, statusListID string) error {
+	if err := tx.Write(ctx, statusListIndexedNamespace, statusListID, []byte("true")); err != nil {
+		return errors.Wrapf(err, "marking status list<%s> as indexed", statusListID)
+	}
+	return nil
+}
+
+// isStatusListIndexed returns whether the references to the status list are all recorded. Status lists created before
+// references were recorded may be used by credentials without one.
+func (cs *Storage) isStatusListIndexed(ctx context.Context, statusListID string) (bool, error) {
+	indexed, err := cs.db.Read(ctx, statusListIndexedNamespace, statusListID)
+	if err != nil {
+		return false, errors.Wrapf(err, "reading whether status list<%s> is indexed", statusListID)
+	}
+	return len(indexed) > 0, nil
+}
+
+// forEachStatusListCredential calls visit with each stored credential with a status entry pointing at the status list,
+// which is of the issuer and schema. When the references to the status list are all recorded, they are paged through
+// StatusListPageSize at a time, and only the credentials they point at are read, one at a time. Otherwise, the
+// credentials of the issuer and schema are read a page of StatusListPageSize at a time.
+func (s Service) forEachStatusListCredential(ctx context.Context, statusListID, issuer, schema string, visit CredentialVisitor) error {
+	return s.forEachReferencedCredential(ctx, statusListID, issuer, schema, false, visit)
+}
+
+// forEachSetStatusListCredential is like forEachStatusListCredential, but when the references to the status list are
+// all recorded, only the credentials whose entry is set, as recorded by their reference, are read and visited. Status
+// lists are regenerated from those alone.
+func (s Service) forEachSetStatusListCredential(ctx context.Context, statusListID, issuer, schema string, visit CredentialVisitor) error {
+	return s.forEachReferencedCredential(ctx, statusListID, issuer, schema, true, visit)
+}
+
+func (s Service) forEachReferencedCredential(ctx context.Context, statusListID, issuer, schema string, onlySet bool, visit CredentialVisitor) error {
+	indexed, err := s.storage.isStatusListIndexed(ctx, statusListID)
+	if err != nil {
+		return err
+	}
+	if !indexed {
+		return s.storage.ForEachCredentialByIssuerAndSchema(ctx, issuer, schema, StatusListPageSize, func(cred StoredCredential) error {
+			if !usesStatusList(cred.Credential, statusListID) {
+				return nil
+			}
+			return visit(cred)
+		})
+	}
+
+	return s.storage.ForEachStatusListReference(ctx, statusListID, StatusListPageSize, func(reference StatusListReference) error {
+		if onlySet && !reference.isSet() {
+			return nil
+		}
+		gotCred, err := s.storage.GetCredentialIfExists(ctx, reference.CredentialID)
+		if err != nil {
+			return errors.Wrapf(err, "reading credential<%s> of status list<%s>", reference.CredentialID, statusListID)
+		}
+		// the credential may have been deleted since its reference was read
+		if gotCred == nil {
+			return nil
+		}
+		return visit(*gotCred)
+	})
+}
+
+// usesStatusList returns whether a status entry of the credential points at the status list.
+func usesStatusList(cred *credential.VerifiableCredential, statusListID string) bool {
+	for _, entry := range statusListEntries(cred) {
+		if id, err := parseIDFromURI(entry.StatusListCredential); err == nil && id == statusListID {
+			return true
+		}
+	}
+	return false
+}
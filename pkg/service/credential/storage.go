@@ -1,13 +1,15 @@
 package credential
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
-	"github.com/TBD54566975/ssi-sdk/credential/integrity"
 	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
@@ -37,24 +39,66 @@ type StoredCredential struct {
 	// ID of the credential that identifies it within ssi service.
 	LocalCredentialID string `json:"LocalCredentialId"`
 
-	// only one of these fields should be present
+	// both fields are present for credentials signed with a Data Integrity proof as well as a JWT
 	Credential    *credential.VerifiableCredential `json:"credential,omitempty"`
 	CredentialJWT *keyaccess.JWT                   `json:"token,omitempty"`
 
 	Issuer                             string `json:"issuer"`
 	FullyQualifiedVerificationMethodID string `json:"fullyQualifiedVerificationMethodId"`
 	Subject                            string `json:"subject"`
+	SubjectType                        string `json:"subjectType,omitempty"`
 	Schema                             string `json:"schema"`
 	IssuanceDate                       string `json:"issuanceDate"`
 	Revoked                            bool   `json:"revoked"`
 	Suspended                          bool   `json:"suspended"`
+	StatusValue                        string `json:"statusValue,omitempty"`
+	StatusReason                       string `json:"statusReason,omitempty"`
+	StatusReasonCode                   string `json:"statusReasonCode,omitempty"`
+	Imported                           bool   `json:"imported,omitempty"`
+
+	RenderMethod  *credint.RenderMethod `json:"renderMethod,omitempty"`
+	MissingClaims []string              `json:"missingClaims,omitempty"`
+
+	Receipt *credint.Receipt `json:"receipt,omitempty"`
+
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
+	AutoRenew          *credint.AutoRenewPolicy `json:"autoRenew,omitempty"`
+	PreviousCredential string                   `json:"previousCredential,omitempty"`
+	CreatedBy          string                   `json:"createdBy,omitempty"`
+
+	// ContentHash is the hash of the canonical JSON of the credential, as returned by util.ContentHash, for checking
+	// its integrity and finding duplicates. It is empty for credentials stored before it was introduced.
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// HasIntactContent returns whether the credential hashes to its content hash. Credentials stored without a content
+// hash are assumed to be intact.
+func (sc *StoredCredential) HasIntactContent() (bool, error) {
+	if sc.ContentHash == "" {
+		return true, nil
+	}
+	contentHash, err := util.ContentHash(sc.Credential)
+	if err != nil {
+		return false, errors.Wrap(err, "hashing stored credential")
+	}
+	return contentHash == sc.ContentHash, nil
 }
 
 func (sc *StoredCredential) FilterVariablesMap() map[string]any {
 	return map[string]any{
-		"issuer":  sc.Issuer,
-		"schema":  sc.Schema,
-		"subject": sc.Subject,
+		"issuer":        sc.Issuer,
+		"schema":        sc.Schema,
+		"schemaVersion": int64(sc.SchemaVersion),
+		"subject":       sc.Subject,
+		"subjectType":   sc.SubjectType,
+		"acknowledged":  sc.Receipt != nil,
+		"createdBy":     sc.CreatedBy,
+		"revoked":       sc.Revoked,
+		"suspended":     sc.Suspended,
+		// "true" and "false" are parsed as identifiers, so they are passed the values they evaluate to
+		"true":  true,
+		"false": false,
 	}
 }
 
@@ -68,6 +112,22 @@ type StatusListCredentialMetadata struct {
 	statusListCredentialWatchKey   storage.WatchKey
 	statusListIndexPoolWatchKey    storage.WatchKey
 	statusListCurrentIndexWatchKey storage.WatchKey
+	// number of bits of each entry of the list, which is only set for message status lists
+	statusSize int
+	// allocation is shared by the credentials of a batch using the list in a transaction, and nil otherwise
+	allocation *statusListAllocation
+}
+
+// statusListAllocation is the state of a status list shared by the credentials of a batch created in a transaction.
+// Reads in a transaction do not see its writes, so the list is read or created once, and its indexes are then handed
+// out from memory, each credential getting its own.
+type statusListAllocation struct {
+	// statusList is the status list credential, once read or created in the transaction
+	statusList *credential.VerifiableCredential
+	// pool is the index pool of the list
+	pool []int
+	// allocated is the number of indexes of the pool handed out, including those of the transaction
+	allocated int
 }
 
 func (sc *StoredCredential) IsValid() bool {
@@ -82,6 +142,14 @@ func (sc *StoredCredential) HasJWTCredential() bool {
 	return sc.CredentialJWT != nil
 }
 
+// GetExpirationDate returns the expiration date of the credential, or an empty string when it does not expire.
+func (sc *StoredCredential) GetExpirationDate() string {
+	if sc.Credential == nil {
+		return ""
+	}
+	return sc.Credential.ExpirationDate
+}
+
 func (sc *StoredCredential) HasCredentialStatus() bool {
 	return sc != nil && sc.Credential != nil && sc.Credential.CredentialStatus != nil
 }
@@ -95,6 +163,14 @@ const (
 	statusListCredentialNamespace          = "status-list-credential"
 	statusListCredentialIndexPoolNamespace = "status-list-index-pool"
 	statusListCredentialCurrentIndex       = "status-list-current-index"
+	credentialSubjectNamespace             = "credential-subject"
+	credentialEventNamespace               = "credential-event"
+	credentialEventSequenceNamespace       = "credential-event-sequence"
+	credentialStatusEventNamespace         = "credential-status-event"
+	credentialRenewalNamespace             = "credential-renewal"
+	statusListReferenceNamespace           = "status-list-reference"
+	statusListIndexedNamespace             = "status-list-indexed"
+	issuerFreezeNamespace                  = "issuer-freeze"
 
 	// A a minimum revocation bitString length of 131,072, or 16KB uncompressed
 	bitStringLength = 8 * 1024 * 16
@@ -171,7 +247,7 @@ func (cs *Storage) IncrementStatusListIndexTx(ctx context.Context, tx storage.Tx
 		return sdkutil.LoggingErrorMsg(err, "unmarshalling unique numbers")
 	}
 
-	if statusListIndex.Index >= bitStringLength-1 {
+	if statusListIndex.Index >= statusListLength(slcMetadata.statusSize)-1 {
 		return sdkutil.LoggingErrorMsg(err, "no more indexes available for status list index")
 	}
 
@@ -187,40 +263,132 @@ func (cs *Storage) IncrementStatusListIndexTx(ctx context.Context, tx storage.Tx
 	return nil
 }
 
+// AllocateStatusListIndexTx hands out the next index of a status list shared by the credentials of a batch, reading its
+// index pool on the first allocation of the transaction, and returns it along with the number of indexes allocated.
+func (cs *Storage) AllocateStatusListIndexTx(ctx context.Context, tx storage.Tx, slcMetadata StatusListCredentialMetadata) (int, int, error) {
+	allocation := slcMetadata.allocation
+	if allocation.pool == nil {
+		gotUniqueNumBytes, err := cs.db.Read(ctx, slcMetadata.statusListIndexPoolWatchKey.Namespace, slcMetadata.statusListIndexPoolWatchKey.Key)
+		if err != nil {
+			return -1, 0, sdkutil.LoggingErrorMsgf(err, "reading status list")
+		}
+		if err = json.Unmarshal(gotUniqueNumBytes, &allocation.pool); err != nil {
+			return -1, 0, sdkutil.LoggingErrorMsgf(err, "unmarshalling unique numbers")
+		}
+		gotCurrentListIndexBytes, err := cs.db.Read(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key)
+		if err != nil {
+			return -1, 0, sdkutil.LoggingErrorMsgf(err, "could not get list index")
+		}
+		var statusListIndex StatusListIndex
+		if err = json.Unmarshal(gotCurrentListIndexBytes, &statusListIndex); err != nil {
+			return -1, 0, sdkutil.LoggingErrorMsgf(err, "unmarshalling status list index")
+		}
+		allocation.allocated = statusListIndex.Index
+	}
+
+	if allocation.allocated >= statusListLength(slcMetadata.statusSize)-1 || allocation.allocated >= len(allocation.pool) {
+		return -1, 0, sdkutil.LoggingNewError("no more indexes available for status list index")
+	}
+	index := allocation.pool[allocation.allocated]
+	allocation.allocated++
+
+	statusListIndexBytes, err := json.Marshal(StatusListIndex{Index: allocation.allocated})
+	if err != nil {
+		return -1, 0, sdkutil.LoggingErrorMsg(err, "could not marshal status list index bytes")
+	}
+	if err = tx.Write(ctx, slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key, statusListIndexBytes); err != nil {
+		return -1, 0, sdkutil.LoggingErrorMsg(err, "problem writing current list index to db")
+	}
+	return index, allocation.allocated, nil
+}
+
+// GetStatusListCurrentIndex returns the position of the next index to be allocated from the index pool of the status
+// list, which is also the number of indexes allocated so far.
+func (cs *Storage) GetStatusListCurrentIndex(ctx context.Context, issuer, schema, statusPurpose string) (int, error) {
+	watchKey := cs.GetStatusListCurrentIndexWatchKey(issuer, schema, statusPurpose)
+	gotCurrentListIndexBytes, err := cs.db.Read(ctx, watchKey.Namespace, watchKey.Key)
+	if err != nil {
+		return -1, sdkutil.LoggingErrorMsg(err, "could not get list index")
+	}
+	if len(gotCurrentListIndexBytes) == 0 {
+		return 0, nil
+	}
+
+	var statusListIndex StatusListIndex
+	if err = json.Unmarshal(gotCurrentListIndexBytes, &statusListIndex); err != nil {
+		return -1, sdkutil.LoggingErrorMsg(err, "unmarshalling status list index")
+	}
+	return statusListIndex.Index, nil
+}
+
+func (cs *Storage) StoreCredential(ctx context.Context, request StoreCredentialRequest) error {
+	wc, err := cs.getStoreCredentialWriteContext(request, credentialNamespace)
+	if err != nil {
+		return errors.Wrap(err, "building stored credential")
+	}
+	return cs.db.Write(ctx, wc.namespace, wc.key, wc.value)
+}
+
 func (cs *Storage) StoreCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest) error {
 	wc, err := cs.getStoreCredentialWriteContext(request, credentialNamespace)
 	if err != nil {
 		return errors.Wrap(err, "building stored credential")
 
 	}
-	return tx.Write(ctx, wc.namespace, wc.key, wc.value)
+	if err = tx.Write(ctx, wc.namespace, wc.key, wc.value); err != nil {
+		return err
+	}
+	return cs.storeStatusListReferencesTx(ctx, tx, request.Container)
 }
 
 // CreateStatusListCredentialTx creates a new status list credential with the provided metadata and stores it in the database as a transaction.
 // The function generates a unique random number and stores it along with the metadata in the database and then returns it
 func (cs *Storage) CreateStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata) (int, error) {
+	randUniqueList, err := cs.createStatusListCredentialTx(ctx, tx, request, slcMetadata, 1)
+	if err != nil {
+		return -1, err
+	}
+	return randUniqueList[0], nil
+}
+
+// CreateUnallocatedStatusListCredentialTx creates a new status list credential and its index pool like
+// CreateStatusListCredentialTx, without handing out any index, so that the first credential using the list is
+// allocated the first index of the pool.
+func (cs *Storage) CreateUnallocatedStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata) error {
+	_, err := cs.createStatusListCredentialTx(ctx, tx, request, slcMetadata, 0)
+	return err
+}
 
-	randUniqueList := randomUniqueNum(bitStringLength)
+// createStatusListCredentialTx stores a status list credential along with a new index pool, of which allocated indexes
+// are already handed out, and returns the pool.
+func (cs *Storage) createStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata, allocated int) ([]int, error) {
+	randUniqueList := randomUniqueNum(statusListLength(slcMetadata.statusSize))
 	uniqueNumBytes, err := json.Marshal(randUniqueList)
 	if err != nil {
-		return -1, sdkutil.LoggingErrorMsg(err, "could not marshal random unique numbers")
+		return nil, sdkutil.LoggingErrorMsg(err, "could not marshal random unique numbers")
 	}
 
 	if err := tx.Write(context.Background(), slcMetadata.statusListIndexPoolWatchKey.Namespace, slcMetadata.statusListIndexPoolWatchKey.Key, uniqueNumBytes); err != nil {
-		return -1, sdkutil.LoggingErrorMsg(err, "problem writing status list indexes to db")
+		return nil, sdkutil.LoggingErrorMsg(err, "problem writing status list indexes to db")
 	}
 
-	// Set the index to 1 since this is a new statusListCredential
-	statusListIndexBytes, err := json.Marshal(StatusListIndex{Index: 1})
+	statusListIndexBytes, err := json.Marshal(StatusListIndex{Index: allocated})
 	if err != nil {
-		return -1, sdkutil.LoggingErrorMsg(err, "could not marshal status list index bytes")
+		return nil, sdkutil.LoggingErrorMsg(err, "could not marshal status list index bytes")
 	}
 
 	if err := tx.Write(context.Background(), slcMetadata.statusListCurrentIndexWatchKey.Namespace, slcMetadata.statusListCurrentIndexWatchKey.Key, statusListIndexBytes); err != nil {
-		return -1, sdkutil.LoggingErrorMsg(err, "problem writing current list index to db")
+		return nil, sdkutil.LoggingErrorMsg(err, "problem writing current list index to db")
 	}
 
-	return randUniqueList[0], cs.StoreStatusListCredentialTx(ctx, tx, request, slcMetadata)
+	if slcMetadata.allocation != nil {
+		slcMetadata.allocation.pool = randUniqueList
+		slcMetadata.allocation.allocated = allocated
+	}
+	if err := cs.markStatusListIndexedTx(ctx, tx, request.ID); err != nil {
+		return nil, err
+	}
+	return randUniqueList, cs.StoreStatusListCredentialTx(ctx, tx, request, slcMetadata)
 }
 
 func (cs *Storage) StoreStatusListCredentialTx(ctx context.Context, tx storage.Tx, request StoreCredentialRequest, slcMetadata StatusListCredentialMetadata) error {
@@ -234,7 +402,7 @@ func (cs *Storage) StoreStatusListCredentialTx(ctx context.Context, tx storage.T
 		return errors.Wrap(err, "building stored credential")
 	}
 
-	storedCredBytes, err := json.Marshal(storedCredential)
+	storedCredBytes, err := util.CanonicalJSON(storedCredential)
 	if err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "could not store request: %s", storedCredential.LocalCredentialID)
 	}
@@ -256,7 +424,7 @@ func (cs *Storage) GetStatusListCredential(ctx context.Context, id string) (*Sto
 			continue
 		}
 		var cred StoredCredential
-		if err = json.Unmarshal(credBytes, &cred); err != nil {
+		if err = unmarshalStoredCredential(credBytes, &cred); err != nil {
 			logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
 		}
 		if cred.LocalCredentialID == id {
@@ -287,7 +455,7 @@ func (cs *Storage) getStoreCredentialWriteContext(request StoreCredentialRequest
 		return nil, errors.Wrap(err, "building stored credential")
 	}
 
-	storedCredBytes, err := json.Marshal(storedCredential)
+	storedCredBytes, err := util.CanonicalJSON(storedCredential)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not store request: %s", storedCredential.LocalCredentialID)
 	}
@@ -303,10 +471,10 @@ func (cs *Storage) getStoreCredentialWriteContext(request StoreCredentialRequest
 
 // buildStoredCredential generically parses a store credential request and returns the object to be stored
 func buildStoredCredential(request StoreCredentialRequest) (*StoredCredential, error) {
-	// assume we have a Data Integrity credential
+	// assume we have a Data Integrity credential, which is kept when it is stored along with its JWT
 	cred := request.Credential
-	if request.HasJWTCredential() {
-		_, _, parsedCred, err := integrity.ParseVerifiableCredentialFromJWT(request.CredentialJWT.String())
+	if request.HasJWTCredential() && !request.HasDataIntegrityCredential() {
+		parsedCred, err := credint.ParseVerifiableCredentialFromJWT(request.CredentialJWT.String())
 		if err != nil {
 			return nil, errors.Wrap(err, "parsing credential from jwt")
 		}
@@ -325,6 +493,10 @@ func buildStoredCredential(request StoreCredentialRequest) (*StoredCredential, e
 	if cred.CredentialSchema != nil {
 		schema = cred.CredentialSchema.ID
 	}
+	contentHash, err := util.ContentHash(cred)
+	if err != nil {
+		return nil, errors.Wrap(err, "hashing credential")
+	}
 	return &StoredCredential{
 		Key:                                createPrefixKey(credID, issuer, subject, schema),
 		LocalCredentialID:                  credID,
@@ -333,10 +505,23 @@ func buildStoredCredential(request StoreCredentialRequest) (*StoredCredential, e
 		Issuer:                             issuer,
 		FullyQualifiedVerificationMethodID: request.FullyQualifiedVerificationMethodID,
 		Subject:                            subject,
+		SubjectType:                        subjectType(cred.CredentialSubject),
 		Schema:                             schema,
 		IssuanceDate:                       cred.IssuanceDate,
 		Revoked:                            request.Revoked,
 		Suspended:                          request.Suspended,
+		StatusValue:                        request.StatusValue,
+		StatusReason:                       request.StatusReason,
+		StatusReasonCode:                   request.StatusReasonCode,
+		Imported:                           request.Imported,
+		RenderMethod:                       request.RenderMethod,
+		MissingClaims:                      request.MissingClaims,
+		Receipt:                            request.Receipt,
+		SchemaVersion:                      request.SchemaVersion,
+		AutoRenew:                          request.AutoRenew,
+		PreviousCredential:                 request.PreviousCredential,
+		CreatedBy:                          request.CreatedBy,
+		ContentHash:                        contentHash,
 	}, nil
 }
 
@@ -344,8 +529,24 @@ func (cs *Storage) GetCredential(ctx context.Context, id string) (*StoredCredent
 	return cs.getCredential(ctx, id, credentialNamespace)
 }
 
+// GetCredentialIfExists returns the credential with the given ID, or nil when there is none.
+func (cs *Storage) GetCredentialIfExists(ctx context.Context, id string) (*StoredCredential, error) {
+	prefixValues, err := cs.db.ReadPrefix(ctx, credentialNamespace, localCredentialID(id))
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential from storage: %s", id)
+	}
+	for _, credBytes := range prefixValues {
+		var stored StoredCredential
+		if err = unmarshalStoredCredential(credBytes, &stored); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling stored credential: %s", id)
+		}
+		return &stored, nil
+	}
+	return nil, nil
+}
+
 func (cs *Storage) getCredential(ctx context.Context, id string, namespace string) (*StoredCredential, error) {
-	prefixValues, err := cs.db.ReadPrefix(ctx, namespace, id)
+	prefixValues, err := cs.db.ReadPrefix(ctx, namespace, localCredentialID(id))
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential from storage: %s", id)
 	}
@@ -364,13 +565,20 @@ func (cs *Storage) getCredential(ctx context.Context, id string, namespace strin
 	}
 
 	var stored StoredCredential
-	if err = json.Unmarshal(credBytes, &stored); err != nil {
+	if err = unmarshalStoredCredential(credBytes, &stored); err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling stored credential: %s", id)
 	}
+	if intact, err := stored.HasIntactContent(); err != nil {
+		logrus.WithError(err).Warnf("could not check the content hash of stored credential<%s>", id)
+	} else if !intact {
+		logrus.Warnf("stored credential<%s> does not match its content hash", id)
+	}
 	return &stored, nil
 }
 
-func (cs *Storage) ListCredentials(ctx context.Context, filter filtering.Filter, page *common.Page) (*StoredCredentials, error) {
+// ListCredentials returns a page of the credentials the filter includes. The `expired` and `state` filter variables
+// are evaluated against the given expiry cutoff, before which credentials are expired.
+func (cs *Storage) ListCredentials(ctx context.Context, filter filtering.Filter, page *common.Page, expiryCutoff time.Time) (*StoredCredentials, error) {
 	token, size := page.ToStorageArgs()
 	creds, nextPageToken, err := cs.db.ReadPage(ctx, credentialNamespace, token, size)
 	if err != nil {
@@ -385,10 +593,16 @@ func (cs *Storage) ListCredentials(ctx context.Context, filter filtering.Filter,
 	storedCreds := make([]StoredCredential, 0, len(creds))
 	for i, cred := range creds {
 		var nextCred StoredCredential
-		if err = json.Unmarshal(cred, &nextCred); err != nil {
+		if err = unmarshalStoredCredential(cred, &nextCred); err != nil {
 			logrus.WithError(err).WithField("idx", i).Warnf("Skipping operation")
 		}
-		include, err := shouldInclude(&nextCred)
+		include, err := shouldInclude(lifecycleFilterVars{
+			vars:           &nextCred,
+			revoked:        nextCred.Revoked,
+			suspended:      nextCred.Suspended,
+			expirationDate: nextCred.GetExpirationDate(),
+			expiryCutoff:   expiryCutoff,
+		})
 		// We explicitly ignore evaluation errors and simply include them in the result.
 		if err != nil || include {
 			storedCreds = append(storedCreds, nextCred)
@@ -401,63 +615,171 @@ func (cs *Storage) ListCredentials(ctx context.Context, filter filtering.Filter,
 	}, nil
 }
 
-// GetCredentialsByIssuerAndSchema gets all credentials stored with a prefix key containing the issuer value
-// The method is greedy, meaning if multiple values are found...and some fail during processing, we will
-// return only the successful values and log an error for the failures.
-func (cs *Storage) GetCredentialsByIssuerAndSchema(ctx context.Context, issuer string, schema string) ([]StoredCredential, error) {
-	return cs.getCredentialsByIssuerAndSchema(ctx, issuer, schema, credentialNamespace)
+// StoredCredentialMetadata is the projection of a StoredCredential onto its metadata. Decoding stored credentials into
+// it skips the credential and its JWT, which are most of what is stored.
+type StoredCredentialMetadata struct {
+	LocalCredentialID string `json:"LocalCredentialId"`
+	Issuer            string `json:"issuer"`
+	Subject           string `json:"subject"`
+	SubjectType       string `json:"subjectType,omitempty"`
+	Schema            string `json:"schema"`
+	SchemaVersion     int    `json:"schemaVersion,omitempty"`
+	IssuanceDate      string `json:"issuanceDate"`
+	Revoked           bool   `json:"revoked"`
+	Suspended         bool   `json:"suspended"`
+	CreatedBy         string `json:"createdBy,omitempty"`
+
+	// Credential holds only the expiration date of the credential, since it is not stored alongside the other metadata.
+	Credential *struct {
+		ExpirationDate string `json:"expirationDate,omitempty"`
+	} `json:"credential,omitempty"`
+
+	// Receipt holds only when the credential was acknowledged, leaving out the JWT of the receipt.
+	Receipt *struct {
+		AcknowledgedAt string `json:"acknowledgedAt"`
+	} `json:"receipt,omitempty"`
+}
+
+func (sc *StoredCredentialMetadata) FilterVariablesMap() map[string]any {
+	return map[string]any{
+		"issuer":        sc.Issuer,
+		"schema":        sc.Schema,
+		"schemaVersion": int64(sc.SchemaVersion),
+		"subject":       sc.Subject,
+		"subjectType":   sc.SubjectType,
+		"acknowledged":  sc.Receipt != nil,
+		"createdBy":     sc.CreatedBy,
+		"revoked":       sc.Revoked,
+		"suspended":     sc.Suspended,
+		"true":          true,
+		"false":         false,
+	}
 }
 
-func (cs *Storage) GetStatusListCredentialsByIssuerSchemaPurpose(ctx context.Context, issuer string, schema string, statusPurpose statussdk.StatusPurpose) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, statusListCredentialNamespace)
+// GetExpirationDate returns the expiration date of the credential, or an empty string when it does not expire.
+func (sc *StoredCredentialMetadata) GetExpirationDate() string {
+	if sc.Credential == nil {
+		return ""
+	}
+	return sc.Credential.ExpirationDate
+}
+
+type StoredCredentialMetadataPage struct {
+	Metadata      []StoredCredentialMetadata
+	NextPageToken string
+}
+
+// ListCredentialMetadata is like ListCredentials, but only decodes the metadata of each credential.
+func (cs *Storage) ListCredentialMetadata(ctx context.Context, filter filtering.Filter, page *common.Page, expiryCutoff time.Time) (*StoredCredentialMetadataPage, error) {
+	token, size := page.ToStorageArgs()
+	creds, nextPageToken, err := cs.db.ReadPage(ctx, credentialNamespace, token, size)
 	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
+		return nil, errors.Wrap(err, "reading all creds before filtering")
 	}
 
-	query := storage.Join("sc", schema, "sp", string(statusPurpose))
-	var issuerSchemaKeys []string
-	for _, k := range keys {
-		if strings.Contains(k, issuer) && strings.HasSuffix(k, query) {
-			issuerSchemaKeys = append(issuerSchemaKeys, k)
-		}
+	shouldInclude, err := storage.NewIncludeFunc(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(issuerSchemaKeys) == 0 {
-		logrus.Warnf("no status list credentials found for issuer: %s schema %s and status purpose %s", util.SanitizeLog(issuer), util.SanitizeLog(schema), util.SanitizeLog(string(statusPurpose)))
-		return nil, nil
+	metadata := make([]StoredCredentialMetadata, 0, len(creds))
+	for i, cred := range creds {
+		var nextMetadata StoredCredentialMetadata
+		if err = json.Unmarshal(cred, &nextMetadata); err != nil {
+			logrus.WithError(err).WithField("idx", i).Warnf("Skipping operation")
+			continue
+		}
+		include, err := shouldInclude(lifecycleFilterVars{
+			vars:           &nextMetadata,
+			revoked:        nextMetadata.Revoked,
+			suspended:      nextMetadata.Suspended,
+			expirationDate: nextMetadata.GetExpirationDate(),
+			expiryCutoff:   expiryCutoff,
+		})
+		// We explicitly ignore evaluation errors and simply include them in the result.
+		if err != nil || include {
+			metadata = append(metadata, nextMetadata)
+		}
 	}
 
-	// now get each credential by key
-	storedCreds := make([]StoredCredential, 0, len(issuerSchemaKeys))
-	for _, key := range issuerSchemaKeys {
-		credBytes, err := cs.db.Read(ctx, statusListCredentialNamespace, key)
+	return &StoredCredentialMetadataPage{
+		Metadata:      metadata,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// CredentialVisitor is called with each credential an iteration visits. Returning an error stops the iteration.
+type CredentialVisitor func(cred StoredCredential) error
+
+// ForEachCredentialByIssuerAndSchema calls visit with each credential stored with a prefix key containing the issuer
+// value and ending in the schema value. Credentials are read a page of the given size at a time, so that no more than a
+// page is held in memory. Credentials which fail to unmarshal are logged and skipped.
+func (cs *Storage) ForEachCredentialByIssuerAndSchema(ctx context.Context, issuer, schema string, pageSize int, visit CredentialVisitor) error {
+	query := storage.Join("sc", schema)
+	pageToken := ""
+	for {
+		page, nextPageToken, err := cs.db.ReadPage(ctx, credentialNamespace, pageToken, pageSize)
 		if err != nil {
-			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-			return nil, err
+			return sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
 		}
-
-		var cred StoredCredential
-		if err = json.Unmarshal(credBytes, &cred); err != nil {
-			logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
+		keys := make([]string, 0, len(page))
+		for key := range page {
+			if strings.Contains(key, issuer) && strings.HasSuffix(key, query) {
+				keys = append(keys, key)
+			}
 		}
-
-		storedCreds = append(storedCreds, cred)
+		sort.Strings(keys)
+		for _, key := range keys {
+			var cred StoredCredential
+			if err = unmarshalStoredCredential(page[key], &cred); err != nil {
+				logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
+				continue
+			}
+			if err = visit(cred); err != nil {
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
 	}
+}
 
-	if len(storedCreds) == 0 {
-		logrus.Infof("no credentials able to be retrieved for issuer: %s", issuerSchemaKeys)
+// GetCredentialsByIssuerSubjectAndSchema gets all credentials stored with a prefix key ending in the issuer, subject and
+// schema values.
+func (cs *Storage) GetCredentialsByIssuerSubjectAndSchema(ctx context.Context, issuer, subject, schema string) ([]StoredCredential, error) {
+	keys, err := cs.db.ReadAllKeys(ctx, credentialNamespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for subject: %s", subject)
 	}
 
+	query := storage.Join("is", issuer, "su", subject, "sc", schema)
+	var storedCreds []StoredCredential
+	for _, k := range keys {
+		if !strings.HasSuffix(k, query) {
+			continue
+		}
+		credBytes, err := cs.db.Read(ctx, credentialNamespace, k)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential with key: %s", k)
+		}
+		var cred StoredCredential
+		if err = unmarshalStoredCredential(credBytes, &cred); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling credential with key: %s", k)
+		}
+		storedCreds = append(storedCreds, cred)
+	}
 	return storedCreds, nil
 }
 
-func (cs *Storage) getCredentialsByIssuerAndSchema(ctx context.Context, issuer string, schema string, namespace string) ([]StoredCredential, error) {
-	keys, err := cs.db.ReadAllKeys(ctx, namespace)
+func (cs *Storage) GetStatusListCredentialsByIssuerSchemaPurpose(ctx context.Context, issuer string, schema string, statusPurpose statussdk.StatusPurpose) ([]StoredCredential, error) {
+	keys, err := cs.db.ReadAllKeys(ctx, statusListCredentialNamespace)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while searching for creds for issuer: %s", issuer)
 	}
 
-	query := storage.Join("sc", schema)
+	query := storage.Join("sc", schema, "sp", string(statusPurpose))
 	var issuerSchemaKeys []string
 	for _, k := range keys {
 		if strings.Contains(k, issuer) && strings.HasSuffix(k, query) {
@@ -466,23 +788,25 @@ func (cs *Storage) getCredentialsByIssuerAndSchema(ctx context.Context, issuer s
 	}
 
 	if len(issuerSchemaKeys) == 0 {
-		logrus.Warnf("no credentials found for issuer: %s and schema %s", util.SanitizeLog(issuer), util.SanitizeLog(schema))
+		logrus.Warnf("no status list credentials found for issuer: %s schema %s and status purpose %s", util.SanitizeLog(issuer), util.SanitizeLog(schema), util.SanitizeLog(string(statusPurpose)))
 		return nil, nil
 	}
 
 	// now get each credential by key
-	var storedCreds []StoredCredential
+	storedCreds := make([]StoredCredential, 0, len(issuerSchemaKeys))
 	for _, key := range issuerSchemaKeys {
-		credBytes, err := cs.db.Read(ctx, namespace, key)
+		credBytes, err := cs.db.Read(ctx, statusListCredentialNamespace, key)
 		if err != nil {
 			logrus.WithError(err).Errorf("could not read credential with key: %s", key)
-		} else {
-			var cred StoredCredential
-			if err = json.Unmarshal(credBytes, &cred); err != nil {
-				logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
-			}
-			storedCreds = append(storedCreds, cred)
+			return nil, err
+		}
+
+		var cred StoredCredential
+		if err = unmarshalStoredCredential(credBytes, &cred); err != nil {
+			logrus.WithError(err).Errorf("unmarshalling credential with key: %s", key)
 		}
+
+		storedCreds = append(storedCreds, cred)
 	}
 
 	if len(storedCreds) == 0 {
@@ -492,15 +816,20 @@ func (cs *Storage) getCredentialsByIssuerAndSchema(ctx context.Context, issuer s
 	return storedCreds, nil
 }
 
-func (cs *Storage) DeleteCredential(ctx context.Context, id string) error {
-	return cs.deleteCredential(ctx, id, credentialNamespace)
+// OnDeleteFunc is called in the transaction deleting a credential, with the deleted credential.
+type OnDeleteFunc func(ctx context.Context, tx storage.Tx, deleted StoredCredential) error
+
+// DeleteCredential deletes a credential, calling onDelete, when set, in the transaction deleting it, which may append
+// events to the event log.
+func (cs *Storage) DeleteCredential(ctx context.Context, id string, onDelete OnDeleteFunc) error {
+	return cs.deleteCredential(ctx, id, credentialNamespace, onDelete)
 }
 
 func (cs *Storage) DeleteStatusListCredential(ctx context.Context, id string) error {
-	return cs.deleteCredential(ctx, id, statusListCredentialNamespace)
+	return cs.deleteCredential(ctx, id, statusListCredentialNamespace, nil)
 }
 
-func (cs *Storage) deleteCredential(ctx context.Context, id string, namespace string) error {
+func (cs *Storage) deleteCredential(ctx context.Context, id string, namespace string, onDelete OnDeleteFunc) error {
 	credDoesNotExistMsg := fmt.Sprintf("credential does not exist, cannot delete: %s", id)
 
 	// first get the credential to regenerate the prefix key
@@ -522,8 +851,25 @@ func (cs *Storage) deleteCredential(ctx context.Context, id string, namespace st
 	}
 
 	// re-create the prefix key to delete
-	prefix := createPrefixKey(id, gotCred.Issuer, gotCred.Subject, gotCred.Schema)
-	if err = cs.db.Delete(ctx, namespace, prefix); err != nil {
+	prefix := createPrefixKey(gotCred.LocalCredentialID, gotCred.Issuer, gotCred.Subject, gotCred.Schema)
+	watchKeys := []storage.WatchKey{{Namespace: namespace, Key: prefix}}
+	deleteFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		if err := tx.Delete(ctx, namespace, prefix); err != nil {
+			return nil, err
+		}
+		if err := cs.deleteStatusListReferencesTx(ctx, tx, *gotCred); err != nil {
+			return nil, err
+		}
+		if onDelete == nil {
+			return nil, nil
+		}
+		return nil, onDelete(ctx, tx, *gotCred)
+	}
+	execute := cs.db.Execute
+	if onDelete != nil {
+		execute = cs.executeWithEventLog
+	}
+	if _, err = execute(ctx, deleteFunc, watchKeys); err != nil {
 		return sdkutil.LoggingErrorMsgf(err, "deleting credential: %s", id)
 	}
 	return nil
@@ -541,6 +887,50 @@ func (cs *Storage) GetStatusListCurrentIndexWatchKey(issuer, schema, statusPurpo
 	return storage.WatchKey{Namespace: statusListCredentialCurrentIndex, Key: getStatusListKey(issuer, schema, statusPurpose)}
 }
 
+// GetCredentialWatchKey returns the key a credential is stored under, which is watched so that a credential with a
+// deterministic ID is not created concurrently.
+func (cs *Storage) GetCredentialWatchKey(id, issuer, subject, schema string) storage.WatchKey {
+	return storage.WatchKey{Namespace: credentialNamespace, Key: createPrefixKey(id, issuer, subject, schema)}
+}
+
+// GetSubjectWatchKey returns the key of the last credential created for a subject by an issuer against a schema, which
+// is watched so that credentials which must be unique to the subject are not created concurrently.
+func (cs *Storage) GetSubjectWatchKey(issuer, schema, subject string) storage.WatchKey {
+	return storage.WatchKey{Namespace: credentialSubjectNamespace, Key: storage.Join("is", issuer, "sc", schema, "su", subject)}
+}
+
+// GetSubjectQuotaWatchKey returns the key of the last credential created for a subject by an issuer under a quota,
+// which is watched so that concurrent credentials for the subject can't exceed the quota.
+func (cs *Storage) GetSubjectQuotaWatchKey(issuer, subject string) storage.WatchKey {
+	return storage.WatchKey{Namespace: credentialSubjectNamespace, Key: storage.Join("is", issuer, "su", subject)}
+}
+
+// CountCredentialsByIssuerAndSubject counts the credentials stored with a prefix key containing the issuer and
+// subject values, whatever their schema.
+func (cs *Storage) CountCredentialsByIssuerAndSubject(ctx context.Context, issuer, subject string) (int, error) {
+	keys, err := cs.db.ReadAllKeys(ctx, credentialNamespace)
+	if err != nil {
+		return 0, sdkutil.LoggingErrorMsgf(err, "could not read credential storage while counting creds for subject: %s", subject)
+	}
+
+	query := storage.Join("", "is", issuer, "su", subject, "sc", "")
+	count := 0
+	for _, k := range keys {
+		if strings.Contains(k, query) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// StoreSubjectCredentialIDTx records the ID of the last credential created for the subject of the watch key.
+func (cs *Storage) StoreSubjectCredentialIDTx(ctx context.Context, tx storage.Tx, watchKey storage.WatchKey, id string) error {
+	if err := tx.Write(ctx, watchKey.Namespace, watchKey.Key, []byte(id)); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not store credential<%s> of subject", id)
+	}
+	return nil
+}
+
 func (cs *Storage) GetStatusListCredentialKeyData(ctx context.Context, issuer string, schema string, statusPurpose statussdk.StatusPurpose) (*StoredCredential, error) {
 	storedStatusListCreds, err := cs.GetStatusListCredentialsByIssuerSchemaPurpose(ctx, issuer, schema, statusPurpose)
 	if err != nil {
@@ -561,6 +951,53 @@ func (cs *Storage) GetStatusListCredentialKeyData(ctx context.Context, issuer st
 	return &storedStatusListCreds[0], nil
 }
 
+// ListStatusListCredentialWatchKeys returns the watch keys of every status list credential, one per
+// <issuer, schema, statusPurpose> triplet.
+func (cs *Storage) ListStatusListCredentialWatchKeys(ctx context.Context) ([]storage.WatchKey, error) {
+	keys, err := cs.db.ReadAllKeys(ctx, statusListCredentialNamespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not read status list credential storage")
+	}
+	watchKeys := make([]storage.WatchKey, 0, len(keys))
+	for _, key := range keys {
+		watchKeys = append(watchKeys, storage.WatchKey{Namespace: statusListCredentialNamespace, Key: key})
+	}
+	return watchKeys, nil
+}
+
+// GetStatusListCredentialByWatchKey returns the status list credential stored under the watch key, or nil when there
+// is none.
+func (cs *Storage) GetStatusListCredentialByWatchKey(ctx context.Context, watchKey storage.WatchKey) (*StoredCredential, error) {
+	credBytes, err := cs.db.Read(ctx, watchKey.Namespace, watchKey.Key)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not read status list credential with key: %s", watchKey.Key)
+	}
+	if len(credBytes) == 0 {
+		return nil, nil
+	}
+	var stored StoredCredential
+	if err = unmarshalStoredCredential(credBytes, &stored); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "unmarshalling status list credential with key: %s", watchKey.Key)
+	}
+	return &stored, nil
+}
+
+// unmarshalStoredCredential decodes numbers as json.Number, so that large integer claims keep their precision.
+func unmarshalStoredCredential(data []byte, stored *StoredCredential) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(stored)
+}
+
+// statusListLength returns the number of entries of a status list whose entries have the given number of bits. Entries
+// of message status lists span several bits, and the last entry is left out so that index zero need not be used.
+func statusListLength(statusSize int) int {
+	if statusSize <= 1 {
+		return bitStringLength
+	}
+	return bitStringLength/statusSize - 1
+}
+
 func getStatusListKey(issuer, schema, statusPurpose string) string {
 	return storage.Join("is", issuer, "sc", schema, "sp", statusPurpose)
 }
@@ -0,0 +1,40 @@
+package credential
+
+import (
+	"github.com/TBD54566975/ssi-sdk/credential"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+)
+
+// SubjectTypeProperty is the property of the credential subject holding its type, such as `Organization`.
+const SubjectTypeProperty = "type"
+
+// checkSubjectType checks that the subject type of the request does not conflict with a type set in its data.
+func (r CreateCredentialRequest) checkSubjectType() error {
+	if r.SubjectType == "" {
+		return nil
+	}
+	if dataType, ok := r.Data[SubjectTypeProperty]; ok && dataType != r.SubjectType {
+		return sdkutil.LoggingNewErrorf("cannot set subject type<%s>, data already contains a different type value: %v", r.SubjectType, dataType)
+	}
+	return nil
+}
+
+// subjectType returns the type of a credential subject, which is the first type when it has several, or an empty
+// string when it has none.
+func subjectType(subject credential.CredentialSubject) string {
+	switch subjectType := subject[SubjectTypeProperty].(type) {
+	case string:
+		return subjectType
+	case []any:
+		if len(subjectType) > 0 {
+			if first, ok := subjectType[0].(string); ok {
+				return first
+			}
+		}
+	case []string:
+		if len(subjectType) > 0 {
+			return subjectType[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+package credential
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// SubjectCredential describes a credential held by a subject, with the fields a wallet needs to display it.
+type SubjectCredential struct {
+	ID                string `json:"id"`
+	Issuer            string `json:"issuer"`
+	IssuerDisplayName string `json:"issuerDisplayName,omitempty"`
+	Schema            string `json:"schema,omitempty"`
+	// Name of the schema, when the schema is stored by the service and has one.
+	SchemaName     string `json:"schemaName,omitempty"`
+	IssuanceDate   string `json:"issuanceDate,omitempty"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+	Expired        bool   `json:"expired"`
+	Revoked        bool   `json:"revoked"`
+	Suspended      bool   `json:"suspended"`
+}
+
+type ListSubjectCredentialsRequest struct {
+	// DID of the subject.
+	Subject string
+	// Whether to list credentials which expired.
+	IncludeExpired bool
+	// Whether to list credentials which were revoked. Suspended credentials are always listed.
+	IncludeRevoked bool
+	PageRequest    *pagination.PageRequest
+}
+
+type ListSubjectCredentialsResponse struct {
+	Credentials   []SubjectCredential
+	NextPageToken string
+}
+
+// ListSubjectCredentials returns the credentials of a subject, newest first. The schema names and issuer display names
+// of each page are looked up once per distinct schema and issuer. The page token is the number of credentials listed
+// in the previous pages.
+func (s Service) ListSubjectCredentials(ctx context.Context, request ListSubjectCredentialsRequest) (*ListSubjectCredentialsResponse, error) {
+	logrus.Debugf("listing credentials of subject: %s", request.Subject)
+
+	if request.Subject == "" {
+		return nil, sdkutil.LoggingNewError("cannot list credentials without a subject")
+	}
+
+	page := request.PageRequest.ToServicePage()
+	offset := 0
+	if page.Token != "" {
+		parsed, err := strconv.Atoi(page.Token)
+		if err != nil || parsed < 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid page token<%s>", page.Token)
+		}
+		offset = parsed
+	}
+
+	metadata, err := s.storage.ListCredentialMetadataBySubject(ctx, request.Subject)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not list credentials of subject: %s", request.Subject)
+	}
+
+	now := s.Clock.Now()
+	credentials := make([]SubjectCredential, 0, len(metadata))
+	for _, m := range metadata {
+		expired := isExpiredAt(m.GetExpirationDate(), now)
+		if (expired && !request.IncludeExpired) || (m.Revoked && !request.IncludeRevoked) {
+			continue
+		}
+		credentials = append(credentials, SubjectCredential{
+			ID:             m.LocalCredentialID,
+			Issuer:         m.Issuer,
+			Schema:         m.Schema,
+			IssuanceDate:   m.IssuanceDate,
+			ExpirationDate: m.GetExpirationDate(),
+			Expired:        expired,
+			Revoked:        m.Revoked,
+			Suspended:      m.Suspended,
+		})
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		if credentials[i].IssuanceDate != credentials[j].IssuanceDate {
+			return credentials[i].IssuanceDate > credentials[j].IssuanceDate
+		}
+		return credentials[i].ID < credentials[j].ID
+	})
+
+	credentials = credentials[min(offset, len(credentials)):]
+	var nextPageToken string
+	if page.Size >= 0 && len(credentials) > page.Size {
+		credentials = credentials[:page.Size]
+		nextPageToken = strconv.Itoa(offset + page.Size)
+	}
+
+	if err = s.expandSubjectCredentials(ctx, credentials); err != nil {
+		return nil, err
+	}
+	return &ListSubjectCredentialsResponse{Credentials: credentials, NextPageToken: nextPageToken}, nil
+}
+
+// expandSubjectCredentials sets the schema name and issuer display name of each credential. Schemas which can no
+// longer be resolved, such as deleted ones, are left without a name.
+func (s Service) expandSubjectCredentials(ctx context.Context, credentials []SubjectCredential) error {
+	issuers := make([]string, 0, len(credentials))
+	schemaNames := make(map[string]string)
+	for _, cred := range credentials {
+		issuers = append(issuers, cred.Issuer)
+		if cred.Schema == "" {
+			continue
+		}
+		if _, ok := schemaNames[cred.Schema]; ok {
+			continue
+		}
+		schemaNames[cred.Schema] = ""
+		resolved, _, err := s.schema.Resolve(ctx, cred.Schema)
+		if err != nil {
+			logrus.WithError(err).Warnf("could not resolve schema<%s> of credential<%s>", cred.Schema, cred.ID)
+			continue
+		}
+		if name, ok := (*resolved)[schemalib.JSONSchemaNameProperty].(string); ok {
+			schemaNames[cred.Schema] = name
+		}
+	}
+
+	displayNames, err := s.issuerDisplayNames(ctx, issuers)
+	if err != nil {
+		return err
+	}
+	for i, cred := range credentials {
+		credentials[i].SchemaName = schemaNames[cred.Schema]
+		credentials[i].IssuerDisplayName = displayNames[cred.Issuer]
+	}
+	return nil
+}
+
+// ListCredentialMetadataBySubject returns the metadata of the credentials stored with a prefix key containing the
+// subject value, whatever their issuer and schema.
+func (cs *Storage) ListCredentialMetadataBySubject(ctx context.Context, subject string) ([]StoredCredentialMetadata, error) {
+	keys, err := cs.db.ReadAllKeys(ctx, credentialNamespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading credential keys of subject<%s>", subject)
+	}
+
+	query := storage.Join("", "su", subject, "sc", "")
+	var metadata []StoredCredentialMetadata
+	for _, key := range keys {
+		if !strings.Contains(key, query) {
+			continue
+		}
+		credBytes, err := cs.db.Read(ctx, credentialNamespace, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading credential<%s>", key)
+		}
+		// the credential may have been deleted since its key was read
+		if len(credBytes) == 0 {
+			continue
+		}
+		var nextMetadata StoredCredentialMetadata
+		if err = json.Unmarshal(credBytes, &nextMetadata); err != nil {
+			logrus.WithError(err).Warnf("skipping credential<%s> of subject<%s>", key, subject)
+			continue
+		}
+		if nextMetadata.Subject == subject {
+			metadata = append(metadata, nextMetadata)
+		}
+	}
+	return metadata, nil
+}
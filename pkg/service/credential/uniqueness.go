@@ -0,0 +1,100 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// ErrActiveCredentialExists is returned when creating a credential which must be unique to its subject, while the
+// subject already holds an active credential of the schema from the issuer.
+var ErrActiveCredentialExists = errors.New("subject already holds an active credential")
+
+func (csr CreateCredentialRequest) isUnique() bool {
+	return csr.UniqueSubject || csr.ReplaceExisting
+}
+
+// uniquenessWatchKeys returns the keys to watch while creating a credential which must be unique to its subject, so
+// that two credentials for the subject can't be created concurrently.
+func (s Service) uniquenessWatchKeys(request CreateCredentialRequest) []storage.WatchKey {
+	if !request.isUnique() {
+		return nil
+	}
+	watchKeys := []storage.WatchKey{s.storage.GetSubjectWatchKey(request.Issuer, request.SchemaID, request.Subject)}
+	if request.ReplaceExisting {
+		watchKeys = append(watchKeys, s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statussdk.StatusRevocation)))
+	}
+	return watchKeys
+}
+
+// checkSubjectUniqueness returns the active credentials the credential replaces when it must be unique to its subject.
+// When the credential does not replace existing ones, the creation is rejected if there are any.
+func (s Service) checkSubjectUniqueness(ctx context.Context, request CreateCredentialRequest) ([]StoredCredential, error) {
+	if !request.isUnique() {
+		return nil, nil
+	}
+	creds, err := s.storage.GetCredentialsByIssuerSubjectAndSchema(ctx, request.Issuer, request.Subject, request.SchemaID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting credentials of subject")
+	}
+
+	var active []StoredCredential
+	for _, cred := range creds {
+		if cred.Revoked || isExpired(cred) {
+			continue
+		}
+		if !request.ReplaceExisting {
+			return nil, sdkutil.LoggingError(errors.Wrapf(ErrActiveCredentialExists, "subject<%s> holds credential<%s> of schema<%s> from issuer<%s>",
+				request.Subject, cred.LocalCredentialID, request.SchemaID, request.Issuer))
+		}
+		if !cred.HasCredentialStatus() || cred.GetStatusPurpose() != string(statussdk.StatusRevocation) {
+			return nil, sdkutil.LoggingNewErrorf("credential<%s> cannot be replaced since it is not revocable", cred.LocalCredentialID)
+		}
+		active = append(active, cred)
+	}
+	return active, nil
+}
+
+// replaceSubjectCredentials revokes the credentials replaced by a credential which must be unique to its subject, and
+// records it as the last credential created for the subject. It returns the regenerated revocation status list
+// credential, if any credentials were revoked.
+func (s Service) replaceSubjectCredentials(ctx context.Context, tx storage.Tx, request CreateCredentialRequest, credentialID string, replaced []StoredCredential) (*credint.Container, error) {
+	if !request.isUnique() {
+		return nil, nil
+	}
+
+	slcMetadata := StatusListCredentialMetadata{
+		statusListCredentialWatchKey: s.storage.GetStatusListCredentialWatchKey(request.Issuer, request.SchemaID, string(statussdk.StatusRevocation)),
+	}
+	var statusList *credint.Container
+	for i := range replaced {
+		var err error
+		revokeRequest := UpdateCredentialStatusRequest{
+			ID:        replaced[i].LocalCredentialID,
+			Revoked:   true,
+			Reason:    fmt.Sprintf("replaced by credential<%s>", credentialID),
+			Principal: request.CreatedBy,
+		}
+		if _, statusList, err = updateCredentialStatus(ctx, tx, s, &replaced[i], revokeRequest, slcMetadata, replaced[:i]...); err != nil {
+			return nil, errors.Wrapf(err, "revoking replaced credential<%s>", replaced[i].LocalCredentialID)
+		}
+	}
+
+	watchKey := s.storage.GetSubjectWatchKey(request.Issuer, request.SchemaID, request.Subject)
+	if err := s.storage.StoreSubjectCredentialIDTx(ctx, tx, watchKey, credentialID); err != nil {
+		return nil, err
+	}
+	return statusList, nil
+}
+
+// isExpired returns whether the expiration date of the credential has passed.
+func isExpired(cred StoredCredential) bool {
+	return cred.Credential != nil && isExpiredAt(cred.Credential.ExpirationDate, time.Now())
+}
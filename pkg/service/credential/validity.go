@@ -0,0 +1,38 @@
+package credential
+
+import (
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrValidityExceedsMaximum is returned when creating a credential which expires further from its issuance than
+	// the configured maximum validity duration.
+	ErrValidityExceedsMaximum = errors.New("requested validity exceeds maximum")
+	// ErrExpiryRequired is returned when creating a credential without an expiry while one is required.
+	ErrExpiryRequired = errors.New("credential must have an expiry")
+)
+
+// checkValidity enforces the configured validity window on a credential issued at the given time. Credentials without
+// an expiry are only rejected when an expiry is required.
+func (s Service) checkValidity(expiry string, issuedAt time.Time) error {
+	if expiry == "" {
+		if s.config.RequireExpiry {
+			return sdkutil.LoggingError(ErrExpiryRequired)
+		}
+		return nil
+	}
+	if s.maxValidity <= 0 {
+		return nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not parse expiry for credential: %s", expiry)
+	}
+	if maxExpiresAt := issuedAt.Add(s.maxValidity); expiresAt.After(maxExpiresAt) {
+		return sdkutil.LoggingError(errors.Wrapf(ErrValidityExceedsMaximum, "expiry<%s> is later than %s", expiry, maxExpiresAt.Format(time.RFC3339)))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// StatusChecker checks credentials against the revocation and suspension status lists stored by the service.
+type StatusChecker struct {
+	storage *Storage
+}
+
+func NewStatusChecker(db storage.ServiceStorage) (*StatusChecker, error) {
+	credentialStorage, err := NewCredentialStorage(db)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the status checker")
+	}
+	return &StatusChecker{storage: credentialStorage}, nil
+}
+
+// StatusResult is the outcome of checking a revocation or suspension status entry of a credential against its status
+// list stored by the service.
+type StatusResult struct {
+	StatusPurpose        statussdk.StatusPurpose `json:"statusPurpose"`
+	StatusListCredential string                  `json:"statusListCredential"`
+	StatusListIndex      string                  `json:"statusListIndex"`
+	// Set is true when the status is set, meaning the credential is revoked or suspended.
+	Set bool `json:"set"`
+	// Unknown is true when checking the status as of a past time which the event log does not cover for the
+	// credential, in which case Set is false.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// CheckStatus returns a verification.StatusError when the credential has been revoked or suspended. Only status lists
+// stored by the service are checked, so credentials of other issuers are never reported as revoked or suspended.
+func (c StatusChecker) CheckStatus(ctx context.Context, cred credential.VerifiableCredential) error {
+	_, err := c.CheckStatuses(ctx, cred)
+	return err
+}
+
+// CheckStatuses checks each revocation and suspension status entry of the credential, whose `credentialStatus` may be
+// a single entry or an array of them, and returns the result of each entry with a status list stored by the service.
+// Along with the results, a verification.StatusError is returned when any entry is set, giving precedence to
+// revocation over suspension.
+func (c StatusChecker) CheckStatuses(ctx context.Context, cred credential.VerifiableCredential) ([]StatusResult, error) {
+	return c.checkStatuses(ctx, cred, nil)
+}
+
+// CheckStatusesAt checks the status entries of the credential like CheckStatuses, but as they were at a past time,
+// reconstructed from the status updates of the credential in the event log. The event log covers a credential when it
+// has its Create event, and the result of each entry of a credential it does not cover is Unknown.
+func (c StatusChecker) CheckStatusesAt(ctx context.Context, cred credential.VerifiableCredential, at time.Time) ([]StatusResult, error) {
+	return c.checkStatuses(ctx, cred, &at)
+}
+
+// checkStatuses checks the status entries of the credential at the given time, or at the current time when it is nil.
+func (c StatusChecker) checkStatuses(ctx context.Context, cred credential.VerifiableCredential, at *time.Time) ([]StatusResult, error) {
+	var results []StatusResult
+	for _, status := range statusEntries(cred.CredentialStatus) {
+		result, err := c.checkStatusEntry(ctx, cred, status, at)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+
+	for _, purpose := range []statussdk.StatusPurpose{statussdk.StatusRevocation, statussdk.StatusSuspension} {
+		for _, result := range results {
+			if !result.Set || result.StatusPurpose != purpose {
+				continue
+			}
+			if purpose == statussdk.StatusSuspension {
+				return results, verification.StatusError{Reason: verification.Suspended, Message: fmt.Sprintf("credential<%s> is suspended", cred.ID)}
+			}
+			return results, verification.StatusError{Reason: verification.Revoked, Message: fmt.Sprintf("credential<%s> is revoked", cred.ID)}
+		}
+	}
+	return results, nil
+}
+
+// checkStatusEntry returns the result of a status entry of the credential, or nil when the entry is not a revocation
+// or suspension entry with a status list stored by the service.
+func (c StatusChecker) checkStatusEntry(ctx context.Context, cred credential.VerifiableCredential, status any, at *time.Time) (*StatusResult, error) {
+	entry, err := toStatusList2021Entry(status)
+	if err != nil || (entry.StatusPurpose != statussdk.StatusRevocation && entry.StatusPurpose != statussdk.StatusSuspension) {
+		return nil, nil
+	}
+	statusListCredentialID, err := parseIDFromURI(entry.StatusListCredential)
+	if err != nil {
+		return nil, nil
+	}
+	gotStatusList, err := c.storage.GetStatusListCredential(ctx, statusListCredentialID)
+	if err != nil || gotStatusList == nil || gotStatusList.Credential == nil || gotStatusList.Credential.ID != entry.StatusListCredential {
+		logrus.Debugf("status list credential<%s> is not stored by the service", entry.StatusListCredential)
+		return nil, nil
+	}
+	if at != nil {
+		return c.statusEntryAt(ctx, cred.ID, *entry, *at)
+	}
+
+	// the parsed entry is evaluated, since the sdk cannot evaluate entries with missing or non-string properties
+	cred.CredentialStatus = *entry
+	set, err := statussdk.ValidateCredentialInStatusList(cred, *gotStatusList.Credential)
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking %s status of credential<%s>", entry.StatusPurpose, cred.ID)
+	}
+	return &StatusResult{
+		StatusPurpose:        entry.StatusPurpose,
+		StatusListCredential: entry.StatusListCredential,
+		StatusListIndex:      entry.StatusListIndex,
+		Set:                  set,
+	}, nil
+}
+
+// statusEntryAt returns the result of a status entry of the credential with the given ID at a past time, from the
+// status updates of the credential in the event log.
+func (c StatusChecker) statusEntryAt(ctx context.Context, credID string, entry statussdk.StatusList2021Entry, at time.Time) (*StatusResult, error) {
+	result := StatusResult{
+		StatusPurpose:        entry.StatusPurpose,
+		StatusListCredential: entry.StatusListCredential,
+		StatusListIndex:      entry.StatusListIndex,
+	}
+	var status *Status
+	if localID, err := parseIDFromURI(credID); err == nil {
+		if status, err = c.storage.statusAt(ctx, localID, at); err != nil {
+			return nil, errors.Wrapf(err, "reconstructing status of credential<%s>", credID)
+		}
+	}
+	switch {
+	case status == nil:
+		result.Unknown = true
+	case entry.StatusPurpose == statussdk.StatusRevocation:
+		result.Set = status.Revoked
+	default:
+		result.Set = status.Suspended
+	}
+	return &result, nil
+}
+
+// hasUnknownStatus returns whether the status of any of the results is unknown.
+func hasUnknownStatus(results []StatusResult) bool {
+	for _, result := range results {
+		if result.Unknown {
+			return true
+		}
+	}
+	return false
+}
+
+// statusEntries returns the entries of a `credentialStatus`, which is either a single entry or an array of them.
+func statusEntries(credentialStatus any) []any {
+	switch status := credentialStatus.(type) {
+	case nil:
+		return nil
+	case []any:
+		return status
+	case []map[string]any:
+		entries := make([]any, 0, len(status))
+		for _, entry := range status {
+			entries = append(entries, entry)
+		}
+		return entries
+	default:
+		return []any{credentialStatus}
+	}
+}
+
+// OnVerificationFailed sets the function notified of each failed credential verification. It must be set before the
+// service verifies credentials.
+func (s *Service) OnVerificationFailed(verificationFailed verification.FailureFunc) {
+	s.verificationFailed = verificationFailed
+}
+
+// SetOfflineVerification makes verification fail closed with verification.ErrOffline on any check which needs network
+// access, resolving DIDs with the given resolver, which must not make outbound calls. The status lists of imported
+// credentials are then reported as unknown, while the status lists hosted by the service are still checked. It must
+// be set before the service verifies credentials.
+func (s *Service) SetOfflineVerification(didResolver resolution.Resolver) {
+	s.didResolver = didResolver
+	s.verifier = s.verifier.Offline(didResolver)
+	s.offline = true
+}
@@ -0,0 +1,78 @@
+package credential
+
+import (
+	"context"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrVerificationMethodNotFound is returned when creating a credential with a verification method which is not in
+	// the DID document of the issuer.
+	ErrVerificationMethodNotFound = errors.New("verification method is not in the DID document of the issuer")
+	// ErrVerificationMethodNotAuthorized is returned when creating a credential with a verification method which the
+	// issuer does not reference from the assertionMethod relationship of its DID document.
+	ErrVerificationMethodNotAuthorized = errors.New("verification method is not authorized to issue credentials")
+)
+
+// checkVerificationMethod resolves the DID document of the issuer of the request, and rejects the request when its
+// verification method is not in the document, or, unless the check is relaxed, is not authorized for assertionMethod.
+func (s Service) checkVerificationMethod(ctx context.Context, request CreateCredentialRequest) error {
+	resolved, err := s.didResolver.Resolve(ctx, request.Issuer)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not resolve issuer<%s>", request.Issuer)
+	}
+	// a verification method of another DID is looked up in the document of that DID, which may sign on behalf of the
+	// issuer when the issuer delegated to it, as checked when the credential is signed
+	controller := request.Issuer
+	if signer, _, _ := strings.Cut(request.FullyQualifiedVerificationMethodID, "#"); strings.HasPrefix(signer, "did:") && signer != request.Issuer {
+		controller = signer
+		if resolved, err = s.didResolver.Resolve(ctx, controller); err != nil {
+			return sdkutil.LoggingErrorMsgf(err, "could not resolve DID<%s> of verification method<%s>", controller, request.FullyQualifiedVerificationMethodID)
+		}
+	}
+	document := resolved.Document
+	verificationMethodID := did.FullyQualifiedVerificationMethodID(controller, request.FullyQualifiedVerificationMethodID)
+
+	found := false
+	for _, vm := range document.VerificationMethod {
+		if did.FullyQualifiedVerificationMethodID(controller, vm.ID) == verificationMethodID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return sdkutil.LoggingError(errors.Wrapf(ErrVerificationMethodNotFound, "verification method %s not found in DID document", verificationMethodID))
+	}
+
+	if s.config.RelaxAssertionMethodCheck {
+		return nil
+	}
+	for _, assertionMethod := range document.AssertionMethod {
+		if id := verificationMethodSetID(assertionMethod); id != "" && did.FullyQualifiedVerificationMethodID(controller, id) == verificationMethodID {
+			return nil
+		}
+	}
+	return sdkutil.LoggingError(errors.Wrapf(ErrVerificationMethodNotAuthorized, "method %s not authorized for assertionMethod", verificationMethodID))
+}
+
+// verificationMethodSetID returns the ID of an entry of a verification relationship, which is either the ID of a
+// verification method of the document, or an embedded verification method.
+func verificationMethodSetID(set did.VerificationMethodSet) string {
+	switch method := set.(type) {
+	case string:
+		return method
+	case did.VerificationMethod:
+		return method.ID
+	case *did.VerificationMethod:
+		return method.ID
+	case map[string]any:
+		id, _ := method["id"].(string)
+		return id
+	default:
+		return ""
+	}
+}
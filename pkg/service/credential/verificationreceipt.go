@@ -0,0 +1,112 @@
+package credential
+
+import (
+	"context"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+)
+
+// ErrVerificationReceiptsDisabled is returned when a verification receipt is requested, but no verification receipt
+// signer is configured.
+var ErrVerificationReceiptsDisabled = errors.New("verification receipts are not enabled")
+
+// The checks listed by verification receipts.
+const (
+	SignatureCheck     = "signature"
+	ExpiryCheck        = "expiry"
+	DataModelCheck     = "dataModel"
+	SchemaCheck        = "schema"
+	StatusCheck        = "status"
+	ExpectationsCheck  = "expectations"
+	TrustedSchemaCheck = "trustedSchema"
+)
+
+// signVerificationReceipt returns a JWT signed with the configured verification receipt signer, attesting to the
+// outcome of verifying the credential of the request at the current time, or as of its AtTime. Its claims are:
+//   - iss, the DID of the signer, and iat, the time of the verification
+//   - jti, a unique ID of the receipt
+//   - credentialId, the ID of the credential, as stated by the credential when it failed verification
+//   - credentialHash, the hex encoded SHA-256 hash of the credential as it was verified
+//   - verified, and reasonCode when it is not
+//   - checks, the checks performed, in the order they were performed
+//   - atTime, the time the credential was verified as of, when the request set one
+func (s Service) signVerificationReceipt(ctx context.Context, request VerifyCredentialRequest, response VerifyCredentialResponse) (*keyaccess.JWT, error) {
+	signer := s.config.VerificationReceiptSigner
+	gotKey, err := s.keyStore.GetKey(ctx, keystore.GetKeyRequest{ID: signer})
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting verification receipt signing key<%s>", signer)
+	}
+	if gotKey.Revoked {
+		return nil, sdkutil.LoggingNewErrorf("cannot use revoked key<%s>", gotKey.ID)
+	}
+
+	var credentialHash string
+	if request.CredentialJWT != nil {
+		credentialHash = verification.HashCredential(request.CredentialJWT.String())
+	} else {
+		credentialHash = verification.HashCredential(request.DataIntegrityCredential)
+	}
+	builder := jwt.NewBuilder().
+		Issuer(gotKey.Controller).
+		IssuedAt(s.Clock.Now()).
+		JwtID(uuid.NewString()).
+		Claim("credentialHash", credentialHash).
+		Claim("verified", response.Verified).
+		Claim("checks", verificationChecks(request, response))
+	if cred := unverifiedCredential(request); cred != nil && cred.ID != "" {
+		builder.Claim("credentialId", cred.ID)
+	}
+	if request.AtTime != nil {
+		builder.Claim("atTime", request.AtTime.UTC().Format(time.RFC3339Nano))
+	}
+	if !response.Verified {
+		reasonCode := response.ReasonCode
+		if reasonCode == "" {
+			reasonCode = verification.InvalidCredential
+		}
+		builder.Claim("reasonCode", reasonCode)
+	}
+	token, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "building verification receipt")
+	}
+
+	keyAccess, err := keyaccess.NewJWKKeyAccess(signer, gotKey.ID, gotKey.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating key access for signing verification receipt with key<%s>", gotKey.ID)
+	}
+	receipt, err := keyAccess.SignJSON(token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signing verification receipt with key<%s>", gotKey.ID)
+	}
+	return receipt, nil
+}
+
+// verificationChecks returns the checks performed to reach the outcome of a verification, which stops at the first
+// check failing. The signature, expiry, data model, and schema of a credential are checked together.
+func verificationChecks(request VerifyCredentialRequest, response VerifyCredentialResponse) []string {
+	checks := []string{SignatureCheck, ExpiryCheck, DataModelCheck, SchemaCheck}
+	switch {
+	case response.Verified:
+	case response.ReasonCode == verification.Revoked || response.ReasonCode == verification.Suspended:
+		return append(checks, StatusCheck)
+	case response.ReasonCode == verification.SchemaMismatch || response.ReasonCode == verification.TypeMismatch:
+		return append(checks, StatusCheck, ExpectationsCheck)
+	case response.ReasonCode == verification.UntrustedSchema:
+	default:
+		return checks
+	}
+	checks = append(checks, StatusCheck, ExpectationsCheck)
+	if request.RequireTrustedSchema {
+		checks = append(checks, TrustedSchemaCheck)
+	}
+	return checks
+}
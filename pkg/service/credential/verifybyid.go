@@ -0,0 +1,54 @@
+package credential
+
+import (
+	"context"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type VerifyCredentialByIDRequest struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type VerifyCredentialByIDResponse struct {
+	VerifyCredentialResponse
+	// Revoked and suspended flags stored with the credential, which are reported alongside the verification result so
+	// that a discrepancy with its status list is visible.
+	Revoked   bool `json:"revoked"`
+	Suspended bool `json:"suspended"`
+}
+
+// VerifyCredentialByID verifies a credential stored by the service, as VerifyCredential does with the credential
+// passed to it, and returns the verification result with the stored status flags of the credential. Credentials which
+// are not stored are reported with ErrCredentialNotFound.
+func (s Service) VerifyCredentialByID(ctx context.Context, request VerifyCredentialByIDRequest) (*VerifyCredentialByIDResponse, error) {
+	logrus.Debugf("verifying credential by id: %s", request.ID)
+
+	gotCred, err := s.storage.GetCredentialIfExists(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get credential: %s", request.ID)
+	}
+	// credentials are read by the prefix of their key, so a partial ID must not match the credential it starts
+	if gotCred == nil || gotCred.LocalCredentialID != localCredentialID(request.ID) {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrCredentialNotFound, "verifying credential<%s>", request.ID))
+	}
+	if !gotCred.IsValid() {
+		return nil, sdkutil.LoggingNewErrorf("credential returned is not valid: %s", request.ID)
+	}
+
+	verifyRequest := VerifyCredentialRequest{CredentialJWT: gotCred.CredentialJWT}
+	if !gotCred.HasJWTCredential() {
+		verifyRequest = VerifyCredentialRequest{DataIntegrityCredential: gotCred.Credential}
+	}
+	verified, err := s.VerifyCredential(ctx, verifyRequest)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyCredentialByIDResponse{
+		VerifyCredentialResponse: *verified,
+		Revoked:                  gotCred.Revoked,
+		Suspended:                gotCred.Suspended,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package credential
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	schemaint "github.com/tbd54566975/ssi-service/internal/schema"
+	"github.com/tbd54566975/ssi-service/internal/util"
+)
+
+// maxViolationValueLength caps the length of the JSON of the offending values reported in schema violations, so that
+// large claims don't flood error responses and logs.
+const maxViolationValueLength = 256
+
+// schemaViolationError returns the error of a credential which does not comply with its schema with the offending
+// values of its violations redacted, at the paths of LogRedactedPaths, and truncated. The violations are logged at the
+// debug level along with the ID of the request.
+func (s Service) schemaViolationError(ctx context.Context, err error, schemaID string) error {
+	var validationErr *schemaint.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	reported := make([]schemaint.Violation, 0, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		violation.Actual = s.reportedViolationValue(violation.Pointer, violation.Actual)
+		reported = append(reported, violation)
+	}
+	if logrus.IsLevelEnabled(logrus.DebugLevel) {
+		violationsJSON, marshalErr := json.Marshal(reported)
+		if marshalErr != nil {
+			logrus.WithError(marshalErr).Warn("could not marshal schema violations to be logged")
+		}
+		logrus.WithFields(logrus.Fields{"requestId": requestID(ctx), "violations": string(violationsJSON)}).
+			Debugf("credential does not comply with schema<%s>", schemaID)
+	}
+	// the message of the error is kept, without the violations as found
+	return &schemaint.ValidationError{Violations: reported, Err: errors.New(validationErr.Error())}
+}
+
+// reportedViolationValue returns the offending value of a violation at a JSON pointer of a credential, redacted and
+// truncated.
+func (s Service) reportedViolationValue(pointer string, value any) any {
+	if value == nil {
+		return nil
+	}
+	path := claimPath(pointer)
+	var nested []string
+	for _, redacted := range s.config.LogRedactedPaths {
+		if path == redacted || strings.HasPrefix(path, redacted+".") {
+			return util.RedactedValue
+		}
+		if path == "" {
+			nested = append(nested, redacted)
+		} else if rest, ok := strings.CutPrefix(redacted, path+"."); ok {
+			nested = append(nested, rest)
+		}
+	}
+	valueJSON, err := util.RedactJSONPaths(value, nested)
+	if err != nil {
+		logrus.WithError(err).Warnf("could not redact the offending value at<%s>", pointer)
+		return nil
+	}
+	if len(valueJSON) > maxViolationValueLength {
+		return strings.ToValidUTF8(string(valueJSON[:maxViolationValueLength]), "") + "..."
+	}
+	if len(nested) == 0 {
+		return value
+	}
+	var redacted any
+	if err = json.Unmarshal(valueJSON, &redacted); err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// claimPath returns the dot separated path, as in LogRedactedPaths, of a JSON pointer of a credential. Array indexes
+// are left out, since redacted paths apply to each element of the arrays along them.
+func claimPath(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	var segments []string
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if _, err := strconv.Atoi(token); err == nil {
+			continue
+		}
+		segments = append(segments, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+	}
+	return strings.Join(segments, ".")
+}
+
+// requestID returns the ID of the trace of the request, which identifies it in logs, or an empty string when the
+// request is not traced.
+func requestID(ctx context.Context) string {
+	spanContext := trace.SpanFromContext(ctx).SpanContext()
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
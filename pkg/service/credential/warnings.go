@@ -0,0 +1,143 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+)
+
+const (
+	// WarningUnresolvableSubject is given when the subject of a credential is a DID that cannot be resolved.
+	WarningUnresolvableSubject = "UNRESOLVABLE_SUBJECT"
+	// WarningLongExpiry is given when a credential expires more than maxExpiryWithoutWarning after issuance.
+	WarningLongExpiry = "LONG_EXPIRY"
+	// WarningUndefinedClaim is given when the schema of a credential allows additional properties in the subject, and
+	// the subject has claims which the schema does not define.
+	WarningUndefinedClaim = "UNDEFINED_CLAIM"
+	// WarningMissingExpectedClaim is given when the subject of a credential is missing claims its schema expects,
+	// without requiring them.
+	WarningMissingExpectedClaim = "MISSING_EXPECTED_CLAIM"
+
+	maxExpiryWithoutWarning = 10 * 365 * 24 * time.Hour
+)
+
+// warning is a finding about a credential request which does not prevent the credential from being issued, unless its
+// code is promoted to an error through config.
+type warning struct {
+	code    string
+	message string
+}
+
+func (w warning) String() string {
+	return fmt.Sprintf("%s: %s", w.code, w.message)
+}
+
+// checkWarnings collects the warnings of a credential request, and returns an error when any of them has a code which
+// is promoted to an error.
+func (s Service) checkWarnings(ctx context.Context, request CreateCredentialRequest, knownSchema *schemalib.JSONSchema, missingClaims []string) ([]string, error) {
+	var warnings []warning
+	if w := s.checkSubjectResolvable(ctx, request.Subject); w != nil {
+		warnings = append(warnings, *w)
+	}
+	if w := checkExpiry(request.Expiry); w != nil {
+		warnings = append(warnings, *w)
+	}
+	if knownSchema != nil {
+		if w := checkUndefinedClaims(request.Data, *knownSchema); w != nil {
+			warnings = append(warnings, *w)
+		}
+	}
+	if len(missingClaims) > 0 {
+		warnings = append(warnings, warning{code: WarningMissingExpectedClaim, message: fmt.Sprintf("expected claims [%s] are missing", strings.Join(missingClaims, ", "))})
+	}
+
+	result := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		if sdkutil.Contains(w.code, s.config.PromotedWarnings) {
+			return nil, sdkutil.LoggingNewErrorf("warning promoted to error: %s", w)
+		}
+		result = append(result, w.String())
+	}
+	return result, nil
+}
+
+func (s Service) checkSubjectResolvable(ctx context.Context, subject string) *warning {
+	if !strings.HasPrefix(subject, "did:") {
+		return nil
+	}
+	if _, err := s.didResolver.Resolve(ctx, subject); err != nil {
+		return &warning{code: WarningUnresolvableSubject, message: fmt.Sprintf("subject<%s> could not be resolved: %s", subject, err)}
+	}
+	return nil
+}
+
+func checkExpiry(expiry string) *warning {
+	if expiry == "" {
+		return nil
+	}
+	// invalid expiry values are rejected when building the credential
+	expiresAt, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return nil
+	}
+	if time.Until(expiresAt) > maxExpiryWithoutWarning {
+		return &warning{code: WarningLongExpiry, message: fmt.Sprintf("expiry<%s> is more than 10 years away", expiry)}
+	}
+	return nil
+}
+
+// checkUndefinedClaims looks for claims not defined under `properties.credentialSubject.properties` of the schema.
+// When the schema forbids additional properties, such claims fail validation instead.
+func checkUndefinedClaims(data map[string]any, knownSchema schemalib.JSONSchema) *warning {
+	properties, _ := knownSchema["properties"].(map[string]any)
+	subjectSchema, _ := properties["credentialSubject"].(map[string]any)
+	definedClaims, ok := subjectSchema["properties"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	if additional, ok := subjectSchema["additionalProperties"].(bool); ok && !additional {
+		return nil
+	}
+
+	var undefined []string
+	for claim := range data {
+		if claim == credential.VerifiableCredentialIDProperty {
+			continue
+		}
+		if _, ok = definedClaims[claim]; !ok {
+			undefined = append(undefined, claim)
+		}
+	}
+	if len(undefined) == 0 {
+		return nil
+	}
+	sort.Strings(undefined)
+	return &warning{code: WarningUndefinedClaim, message: fmt.Sprintf("claims [%s] are not defined in the schema", strings.Join(undefined, ", "))}
+}
+
+// missingExpectedClaims returns the expected claims which are absent from, or null in, the subject of a credential.
+// Nested claims are given as dot separated paths, such as `address.city`.
+func missingExpectedClaims(data map[string]any, expectedClaims []string) []string {
+	var missing []string
+	for _, claim := range expectedClaims {
+		var value any = data
+		for _, segment := range strings.Split(claim, ".") {
+			object, ok := value.(map[string]any)
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[segment]
+		}
+		if value == nil {
+			missing = append(missing, claim)
+		}
+	}
+	return missing
+}
@@ -63,10 +63,19 @@ func (s *BatchService) BatchCreateDIDs(ctx context.Context, batchReq BatchCreate
 		// accumulate all writes
 		// execute all writes s.t. if one write fails, then watchKey is written from elsewhere
 		for _, request := range batchReq.Requests {
+			// the transaction is rolled back once the request is done, so the remaining DIDs are not created
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
 			didResponse, err := handler.CreateDID(ctx, request)
 			if err != nil {
 				return nil, err
 			}
+			if metadata := request.metadata(); metadata != nil {
+				if err = didStorage.StoreMetadata(ctx, didResponse.DID.ID, *metadata); err != nil {
+					return nil, err
+				}
+			}
 			batchResponse.DIDs = append(batchResponse.DIDs, didResponse.DID)
 		}
 		return &batchResponse, nil
@@ -0,0 +1,74 @@
+package did
+
+import (
+	"context"
+	"sync"
+
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/sirupsen/logrus"
+)
+
+// BatchResolveConcurrency is how many DIDs of a batch are resolved at once.
+const BatchResolveConcurrency = 10
+
+type BatchResolveDIDsRequest struct {
+	DIDs []string `json:"dids" validate:"required"`
+}
+
+// BatchResolveDIDResult is the resolution result of a DID of a batch, which has either the resolved document or the
+// error it could not be resolved with.
+type BatchResolveDIDResult struct {
+	DID                 string                       `json:"did"`
+	ResolutionMetadata  *resolution.Metadata         `json:"didResolutionMetadata,omitempty"`
+	DIDDocument         *didsdk.Document             `json:"didDocument,omitempty"`
+	DIDDocumentMetadata *resolution.DocumentMetadata `json:"didDocumentMetadata,omitempty"`
+	Error               string                       `json:"error,omitempty"`
+}
+
+type BatchResolveDIDsResponse struct {
+	// Results are in the order of the DIDs of the request.
+	Results []BatchResolveDIDResult `json:"results"`
+}
+
+// BatchResolveDIDs resolves the DIDs of the batch concurrently, with the same resolver as single DIDs. A DID which
+// cannot be resolved, such as a malformed one, has an error as its result, and does not fail the batch.
+func (s *Service) BatchResolveDIDs(ctx context.Context, request BatchResolveDIDsRequest) (*BatchResolveDIDsResponse, error) {
+	if maxItems := s.config.BatchResolveMaxItems; len(request.DIDs) > maxItems {
+		return nil, sdkutil.LoggingNewErrorf("max number of DIDs is %d", maxItems)
+	}
+
+	results := make([]BatchResolveDIDResult, len(request.DIDs))
+	sem := make(chan struct{}, BatchResolveConcurrency)
+	var wg sync.WaitGroup
+	for i, did := range request.DIDs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, did string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.batchResolveDID(ctx, did)
+		}(i, did)
+	}
+	wg.Wait()
+	return &BatchResolveDIDsResponse{Results: results}, nil
+}
+
+func (s *Service) batchResolveDID(ctx context.Context, did string) BatchResolveDIDResult {
+	result := BatchResolveDIDResult{DID: did}
+	if did == "" {
+		result.Error = "cannot resolve empty DID"
+		return result
+	}
+	resolved, err := s.Resolve(ctx, did)
+	if err != nil {
+		logrus.WithError(err).Debugf("could not resolve DID<%s> of batch", did)
+		result.Error = err.Error()
+		return result
+	}
+	result.ResolutionMetadata = &resolved.Metadata
+	result.DIDDocument = &resolved.Document
+	result.DIDDocumentMetadata = resolved.DocumentMetadata
+	return result
+}
@@ -0,0 +1,186 @@
+package did
+
+import (
+	"context"
+	"encoding/base64"
+
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+)
+
+// KeyStatus describes whether the service can sign with the key of a verification method. Keys held by the service do
+// not expire, so a key is either usable, revoked, or missing.
+type KeyStatus string
+
+const (
+	KeyStatusUsable  KeyStatus = "usable"
+	KeyStatusRevoked KeyStatus = "revoked"
+	// KeyStatusMissing means the service does not hold the private key of the verification method.
+	KeyStatusMissing KeyStatus = "missing"
+)
+
+type VerificationMethodKeyStatus struct {
+	// Fully qualified ID of the verification method, which is also the ID of its key in the key store.
+	ID        string    `json:"id"`
+	Status    KeyStatus `json:"status"`
+	RevokedAt string    `json:"revokedAt,omitempty"`
+}
+
+type ControlledDID struct {
+	ID                  string                        `json:"id"`
+	Method              didsdk.Method                 `json:"method"`
+	VerificationMethods []VerificationMethodKeyStatus `json:"verificationMethods"`
+
+	// CanSign is false when none of the verification methods has a usable key, meaning the keys of the DID should be
+	// rotated.
+	CanSign bool `json:"canSign"`
+}
+
+type ListControlledDIDsResponse struct {
+	DIDs          []ControlledDID `json:"dids"`
+	NextPageToken string          `json:"nextPageToken,omitempty"`
+}
+
+// controlledDIDsPageToken keeps track of the method being listed, since the DIDs of each method are stored separately
+type controlledDIDsPageToken struct {
+	Method string `json:"method"`
+	Token  string `json:"token,omitempty"`
+}
+
+// ListControlledDIDs lists the DIDs of every configured method, along with the status of the key behind each of their
+// verification methods. Soft deleted DIDs are not included.
+func (s *Service) ListControlledDIDs(ctx context.Context, page *common.Page) (*ListControlledDIDsResponse, error) {
+	methods := s.config.Methods
+	if len(methods) == 0 {
+		return &ListControlledDIDsResponse{}, nil
+	}
+
+	token, size := page.ToStorageArgs()
+	current := controlledDIDsPageToken{Method: methods[0]}
+	if token != "" {
+		decoded, err := decodeControlledDIDsPageToken(token)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "invalid page token")
+		}
+		current = *decoded
+	}
+	methodIndex := -1
+	for i, m := range methods {
+		if m == current.Method {
+			methodIndex = i
+		}
+	}
+	if methodIndex == -1 {
+		return nil, sdkutil.LoggingNewErrorf("page token refers to unsupported method: %s", current.Method)
+	}
+
+	storedDIDs := make([]StoredDID, 0)
+	storedDIDMethods := make([]string, 0)
+	nextPageToken := ""
+	for methodIndex < len(methods) {
+		remaining := -1
+		if size != -1 {
+			remaining = size - len(storedDIDs)
+		}
+		gotDIDs, err := s.storage.ListDIDsPage(ctx, methods[methodIndex], &common.Page{Token: current.Token, Size: remaining}, new(DefaultStoredDID))
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "listing DIDs for method: %s", methods[methodIndex])
+		}
+		storedDIDs = append(storedDIDs, gotDIDs.DIDs...)
+		for range gotDIDs.DIDs {
+			storedDIDMethods = append(storedDIDMethods, methods[methodIndex])
+		}
+
+		// move on to the next method once this one is exhausted
+		if gotDIDs.NextPageToken != "" {
+			current = controlledDIDsPageToken{Method: methods[methodIndex], Token: gotDIDs.NextPageToken}
+		} else {
+			methodIndex++
+			if methodIndex < len(methods) {
+				current = controlledDIDsPageToken{Method: methods[methodIndex]}
+			}
+		}
+		if size != -1 && len(storedDIDs) >= size {
+			if methodIndex < len(methods) {
+				if nextPageToken, err = encodeControlledDIDsPageToken(current); err != nil {
+					return nil, sdkutil.LoggingErrorMsg(err, "encoding page token")
+				}
+			}
+			break
+		}
+	}
+
+	controllers := make([]string, 0, len(storedDIDs))
+	for _, storedDID := range storedDIDs {
+		controllers = append(controllers, storedDID.GetID())
+	}
+	keys, err := s.keyStore.ListKeysByController(ctx, controllers)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing keys of DIDs")
+	}
+	keysByID := make(map[string]keystore.StoredKey, len(keys))
+	for _, key := range keys {
+		keysByID[key.ID] = key
+	}
+
+	controlledDIDs := make([]ControlledDID, 0, len(storedDIDs))
+	for i, storedDID := range storedDIDs {
+		if storedDID.IsSoftDeleted() {
+			continue
+		}
+		controlledDIDs = append(controlledDIDs, toControlledDID(storedDID, didsdk.Method(storedDIDMethods[i]), keysByID))
+	}
+	return &ListControlledDIDsResponse{DIDs: controlledDIDs, NextPageToken: nextPageToken}, nil
+}
+
+func toControlledDID(storedDID StoredDID, method didsdk.Method, keysByID map[string]keystore.StoredKey) ControlledDID {
+	document := storedDID.GetDocument()
+	controlled := ControlledDID{
+		ID:                  storedDID.GetID(),
+		Method:              method,
+		VerificationMethods: make([]VerificationMethodKeyStatus, 0, len(document.VerificationMethod)),
+	}
+	for _, vm := range document.VerificationMethod {
+		vmStatus := VerificationMethodKeyStatus{
+			ID:     didsdk.FullyQualifiedVerificationMethodID(controlled.ID, vm.ID),
+			Status: KeyStatusMissing,
+		}
+		if key, ok := keysByID[vmStatus.ID]; ok {
+			vmStatus.Status = KeyStatusUsable
+			if key.Revoked {
+				vmStatus.Status = KeyStatusRevoked
+				vmStatus.RevokedAt = key.RevokedAt
+			}
+		}
+		if vmStatus.Status == KeyStatusUsable {
+			controlled.CanSign = true
+		}
+		controlled.VerificationMethods = append(controlled.VerificationMethods, vmStatus)
+	}
+	return controlled
+}
+
+func encodeControlledDIDsPageToken(token controlledDIDsPageToken) (string, error) {
+	tokenBytes, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
+}
+
+func decodeControlledDIDsPageToken(token string) (*controlledDIDsPageToken, error) {
+	tokenBytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding token")
+	}
+	var decoded controlledDIDsPageToken
+	if err = json.Unmarshal(tokenBytes, &decoded); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling token")
+	}
+	return &decoded, nil
+}
@@ -0,0 +1,133 @@
+package did
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+)
+
+var (
+	// ErrDelegationNotFound is returned when the delegator has no delegation with the ID.
+	ErrDelegationNotFound = errors.New("delegation not found")
+	// ErrInvalidDelegation is returned when a delegation credential cannot be verified, or does not authorize a DID.
+	ErrInvalidDelegation = errors.New("invalid delegation")
+)
+
+// Delegation is a delegation credential by which a DID, the delegator, authorizes another DID, the delegate, to sign
+// credentials naming the delegator as their issuer. It is verified when created, so that credentials can be signed on
+// behalf of the delegator for as long as it is neither revoked nor expired.
+type Delegation struct {
+	ID            string        `json:"id"`
+	Delegator     string        `json:"delegator"`
+	Delegate      string        `json:"delegate"`
+	DelegationJWT keyaccess.JWT `json:"delegationJwt"`
+	CreatedAt     string        `json:"createdAt"`
+	// ExpiresAt is the expiration date of the delegation credential, if any.
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	Revoked   bool   `json:"revoked"`
+	RevokedAt string `json:"revokedAt,omitempty"`
+}
+
+// IsActiveAt returns whether credentials can be signed under the delegation at the given time.
+func (d Delegation) IsActiveAt(t time.Time) bool {
+	if d.Revoked {
+		return false
+	}
+	if d.ExpiresAt == "" {
+		return true
+	}
+	expiresAt, err := time.Parse(time.RFC3339, d.ExpiresAt)
+	return err == nil && expiresAt.After(t)
+}
+
+// CreateDelegation verifies a delegation credential issued by the delegator, and stores it. Its subject is the
+// delegate, which may then sign credentials on behalf of the delegator.
+func (s *Service) CreateDelegation(ctx context.Context, request CreateDelegationRequest) (*Delegation, error) {
+	_, _, cred, err := integrity.ParseVerifiableCredentialFromJWT(request.DelegationJWT.String())
+	if err != nil {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidDelegation, "parsing delegation credential: %s", err))
+	}
+	delegate := cred.CredentialSubject.GetID()
+	if delegate == "" {
+		return nil, sdkutil.LoggingError(errors.Wrap(ErrInvalidDelegation, "delegation credential has no subject"))
+	}
+	if delegate == request.Delegator {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidDelegation, "DID<%s> cannot delegate to itself", delegate))
+	}
+	if err = verification.VerifyDelegation(ctx, s.resolver, request.DelegationJWT, request.Delegator, delegate); err != nil {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidDelegation, "%s", err))
+	}
+
+	delegation := Delegation{
+		ID:            uuid.NewString(),
+		Delegator:     request.Delegator,
+		Delegate:      delegate,
+		DelegationJWT: request.DelegationJWT,
+		CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+		ExpiresAt:     cred.ExpirationDate,
+	}
+	if err = s.storage.StoreDelegation(ctx, delegation); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "storing delegation of DID<%s>", request.Delegator)
+	}
+	return &delegation, nil
+}
+
+// ListDelegations returns the delegations of a delegator, revoked ones included, oldest first.
+func (s *Service) ListDelegations(ctx context.Context, delegator string) ([]Delegation, error) {
+	delegations, err := s.storage.ListDelegations(ctx, delegator)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(delegations, func(i, j int) bool {
+		if delegations[i].CreatedAt != delegations[j].CreatedAt {
+			return delegations[i].CreatedAt < delegations[j].CreatedAt
+		}
+		return delegations[i].ID < delegations[j].ID
+	})
+	return delegations, nil
+}
+
+// RevokeDelegation revokes a delegation of a delegator, so that its delegate can no longer sign credentials on behalf
+// of the delegator. Credentials signed before stay valid.
+func (s *Service) RevokeDelegation(ctx context.Context, request RevokeDelegationRequest) (*Delegation, error) {
+	delegation, err := s.storage.GetDelegation(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if delegation == nil || delegation.Delegator != request.Delegator {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrDelegationNotFound, "delegation<%s> of DID<%s>", request.ID, request.Delegator))
+	}
+	if delegation.Revoked {
+		return delegation, nil
+	}
+	delegation.Revoked = true
+	delegation.RevokedAt = time.Now().UTC().Format(time.RFC3339)
+	if err = s.storage.StoreDelegation(ctx, *delegation); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "storing revoked delegation<%s>", request.ID)
+	}
+	return delegation, nil
+}
+
+// GetActiveDelegationJWT returns the delegation credential JWT by which the delegator authorizes the delegate, among
+// its delegations which are neither revoked nor expired, or nil when there is none.
+func (s *Service) GetActiveDelegationJWT(ctx context.Context, delegator, delegate string) (*keyaccess.JWT, error) {
+	delegations, err := s.ListDelegations(ctx, delegator)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := len(delegations) - 1; i >= 0; i-- {
+		if delegations[i].Delegate == delegate && delegations[i].IsActiveAt(now) {
+			return delegations[i].DelegationJWT.Ptr(), nil
+		}
+	}
+	return nil, nil
+}
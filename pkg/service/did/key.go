@@ -2,6 +2,7 @@ package did
 
 import (
 	"context"
+	gocrypto "crypto"
 	"fmt"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
@@ -37,10 +38,13 @@ func (h *keyHandler) GetMethod() did.Method {
 }
 
 func (h *keyHandler) CreateDID(ctx context.Context, request CreateDIDRequest) (*CreateDIDResponse, error) {
-	logrus.Debugf("creating DID: %+v", request)
+	// the seed is the private key, so it is left out of the logs
+	logged := request
+	logged.Seed = nil
+	logrus.Debugf("creating DID: %+v", logged)
 
-	// create the DID
-	privKey, doc, err := key.GenerateDIDKey(request.KeyType)
+	// create the DID, deriving its key from the seed when one is given
+	privKey, doc, err := createDIDKey(request.KeyType, request.Seed)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating did:key")
 	}
@@ -83,6 +87,29 @@ func (h *keyHandler) CreateDID(ctx context.Context, request CreateDIDRequest) (*
 	return &CreateDIDResponse{DID: storedDID.DID}, nil
 }
 
+// createDIDKey creates a did:key with a new key, or with the key derived from the seed when there is one.
+func createDIDKey(kt crypto.KeyType, seed []byte) (gocrypto.PrivateKey, *key.DIDKey, error) {
+	if len(seed) == 0 {
+		return key.GenerateDIDKey(kt)
+	}
+	if !key.IsSupportedDIDKeyType(kt) {
+		return nil, nil, fmt.Errorf("unsupported did:key type: %s", kt)
+	}
+	pubKey, privKey, err := deriveKeyFromSeed(kt, seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKeyBytes, err := crypto.PubKeyToBytes(pubKey)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "converting public key to bytes")
+	}
+	doc, err := key.CreateDIDKey(kt, pubKeyBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privKey, doc, nil
+}
+
 func (h *keyHandler) GetDID(ctx context.Context, request GetDIDRequest) (*GetDIDResponse, error) {
 	logrus.Debugf("getting DID: %+v", request)
 
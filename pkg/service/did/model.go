@@ -4,9 +4,13 @@ import (
 	gocrypto "crypto"
 
 	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	"github.com/TBD54566975/ssi-sdk/did/ion"
 	"github.com/TBD54566975/ssi-sdk/did/resolution"
+	"go.einride.tech/aip/filtering"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/service/common"
 )
 
@@ -24,6 +28,13 @@ type ResolveDIDResponse struct {
 	DIDDocumentMetadata *resolution.DocumentMetadata `json:"didDocumentMetadata,omitempty"`
 }
 
+type ResolveVerificationMethodResponse struct {
+	// ID is the fully qualified ID of the verification method.
+	ID           string           `json:"id"`
+	KeyType      crypto.KeyType   `json:"keyType"`
+	PublicKeyJWK jwx.PublicKeyJWK `json:"publicKeyJwk"`
+}
+
 type CreateDIDRequestOptions interface {
 	Method() didsdk.Method
 }
@@ -33,6 +44,21 @@ type CreateDIDRequest struct {
 	Method  didsdk.Method           `json:"method" validate:"required"`
 	KeyType crypto.KeyType          `validate:"required"`
 	Options CreateDIDRequestOptions `json:"options"`
+	// DisplayName is an optional human readable name of the DID, stored as its metadata.
+	DisplayName string `json:"displayName,omitempty"`
+	// Seed is an optional seed the key of a did:key is derived from, instead of generating it, so that the same DID
+	// can be created again. It must have the length of a private key of the key type. Only supported by did:key.
+	Seed []byte `json:"seed,omitempty"`
+	// CreatedBy is the principal creating the DID, stored as its metadata.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+// metadata returns the metadata the DID is created with, or nil when there is none.
+func (r CreateDIDRequest) metadata() *Metadata {
+	if r.DisplayName == "" && r.CreatedBy == "" {
+		return nil
+	}
+	return &Metadata{DisplayName: r.DisplayName, CreatedBy: r.CreatedBy}
 }
 
 // CreateDIDResponse is the JSON-serializable response for creating a DID
@@ -55,7 +81,15 @@ type GetDIDRequest struct {
 
 // GetDIDResponse is the JSON-serializable response for getting a DID
 type GetDIDResponse struct {
-	DID didsdk.Document `json:"did"`
+	DID         didsdk.Document `json:"did"`
+	DisplayName string          `json:"displayName,omitempty"`
+	CreatedBy   string          `json:"createdBy,omitempty"`
+}
+
+type UpdateDIDMetadataRequest struct {
+	Method      didsdk.Method `json:"method" validate:"required"`
+	ID          string        `json:"id" validate:"required"`
+	DisplayName string        `json:"displayName"`
 }
 
 type GetKeyFromDIDRequest struct {
@@ -71,13 +105,18 @@ type GetKeyFromDIDResponse struct {
 type ListDIDsRequest struct {
 	Method  didsdk.Method `json:"method" validate:"required"`
 	Deleted bool          `json:"deleted"`
+	// Filter over the `createdBy` of the metadata of the DIDs. All DIDs are listed when it is empty.
+	Filter filtering.Filter
 
 	PageRequest *common.Page
 }
 
 // ListDIDsResponse is the JSON-serializable response for getting all DIDs for a given method
 type ListDIDsResponse struct {
-	DIDs          []didsdk.Document `json:"dids"`
+	DIDs []didsdk.Document `json:"dids"`
+	// CreatedBy is the principal which created each DID listed, keyed by the DID, for the DIDs created since principals
+	// were recorded.
+	CreatedBy     map[string]string `json:"createdBy,omitempty"`
 	NextPageToken string
 }
 
@@ -96,6 +135,19 @@ type UpdateIONDIDResponse struct {
 	DID didsdk.Document `json:"did"`
 }
 
+type CreateDelegationRequest struct {
+	// Delegator is the DID on whose behalf the delegate may sign credentials. It need not be managed by the service.
+	Delegator string `json:"delegator" validate:"required"`
+	// DelegationJWT is a credential JWT issued by the delegator, of type DelegationCredential, whose subject is the
+	// delegate.
+	DelegationJWT keyaccess.JWT `json:"delegationJwt" validate:"required"`
+}
+
+type RevokeDelegationRequest struct {
+	Delegator string `json:"delegator" validate:"required"`
+	ID        string `json:"id" validate:"required"`
+}
+
 type UpdateRequestStatus string
 
 func (s UpdateRequestStatus) Bytes() []byte {
@@ -0,0 +1,86 @@
+package did
+
+import (
+	"context"
+
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	didresolution "github.com/TBD54566975/ssi-sdk/did/resolution"
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+
+	didint "github.com/tbd54566975/ssi-service/internal/did"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+)
+
+// selfDescribingMethods are the DID methods whose documents are derived from the DID itself, so that resolving them
+// makes no outbound call.
+var selfDescribingMethods = []didsdk.Method{didsdk.KeyMethod, didsdk.JWKMethod, didsdk.PeerMethod, didsdk.PKHMethod}
+
+// offlineResolver resolves the DIDs managed by the service from storage, and DIDs of self describing methods from
+// the DID itself. Resolving any other DID would need network access, and fails with verification.ErrOffline.
+type offlineResolver struct {
+	storage *Storage
+	local   didresolution.Resolver
+}
+
+// GetOfflineResolver returns a resolver which never makes outbound calls, for verifying in offline mode.
+func (s *Service) GetOfflineResolver() (didresolution.Resolver, error) {
+	methods := make([]string, 0, len(selfDescribingMethods))
+	for _, method := range selfDescribingMethods {
+		methods = append(methods, method.String())
+	}
+	local, err := didint.BuildMultiMethodResolver(methods)
+	if err != nil {
+		return nil, errors.Wrap(err, "instantiating offline DID resolver")
+	}
+	return &offlineResolver{storage: s.storage, local: local}, nil
+}
+
+func (r offlineResolver) Resolve(ctx context.Context, did string, opts ...didresolution.Option) (*didresolution.Result, error) {
+	stored, err := r.getStoredDID(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		return &didresolution.Result{Document: stored.DID}, nil
+	}
+
+	method, err := didresolution.GetMethodForDID(did)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting method from DID")
+	}
+	for _, selfDescribing := range selfDescribingMethods {
+		if method == selfDescribing {
+			return r.local.Resolve(ctx, did, opts...)
+		}
+	}
+	return nil, errors.Wrapf(verification.ErrOffline, "resolving DID<%s>", did)
+}
+
+func (r offlineResolver) Methods() []didsdk.Method {
+	return r.local.Methods()
+}
+
+// getStoredDID returns the DID as stored by the service, or nil when the service does not manage it, or has soft
+// deleted it.
+func (r offlineResolver) getStoredDID(ctx context.Context, did string) (*DefaultStoredDID, error) {
+	ns, err := getNamespaceForDID(did)
+	if err != nil {
+		return nil, nil
+	}
+	docBytes, err := r.storage.db.Read(ctx, ns, did)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading DID<%s>", did)
+	}
+	if len(docBytes) == 0 {
+		return nil, nil
+	}
+	var stored DefaultStoredDID
+	if err = json.Unmarshal(docBytes, &stored); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling stored DID<%s>", did)
+	}
+	if stored.SoftDeleted {
+		return nil, nil
+	}
+	return &stored, nil
+}
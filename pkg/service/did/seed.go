@@ -0,0 +1,82 @@
+package did
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSeed is returned when a key cannot be derived from the seed given for it.
+var ErrInvalidSeed = errors.New("invalid seed")
+
+// ecdsaCurves are the curves of the ECDSA key types keys can be derived from seeds for.
+var ecdsaCurves = map[crypto.KeyType]elliptic.Curve{
+	crypto.P256: elliptic.P256(),
+	crypto.P384: elliptic.P384(),
+	crypto.P521: elliptic.P521(),
+}
+
+// seedLength returns the length of the seeds keys of the key type are derived from.
+func seedLength(kt crypto.KeyType) (int, error) {
+	switch kt {
+	case crypto.Ed25519:
+		return ed25519.SeedSize, nil
+	case crypto.X25519:
+		return x25519.SeedSize, nil
+	case crypto.SECP256k1:
+		return secp.PrivKeyBytesLen, nil
+	}
+	if curve, ok := ecdsaCurves[kt]; ok {
+		return (curve.Params().BitSize + 7) / 8, nil
+	}
+	return 0, fmt.Errorf("%w: keys of type<%s> cannot be derived from a seed", ErrInvalidSeed, kt)
+}
+
+// deriveKeyFromSeed deterministically derives a key pair of the key type from a seed, which is used as the private key
+// itself. The seed must have the length of a private key of the key type. Seeds should only be used for environments
+// that need to be reproducible, since anyone knowing the seed knows the private key.
+func deriveKeyFromSeed(kt crypto.KeyType, seed []byte) (gocrypto.PublicKey, gocrypto.PrivateKey, error) {
+	length, err := seedLength(kt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(seed) != length {
+		return nil, nil, fmt.Errorf("%w: seed for key type<%s> must be %d bytes, got %d", ErrInvalidSeed, kt, length, len(seed))
+	}
+
+	switch kt {
+	case crypto.Ed25519:
+		privKey := ed25519.NewKeyFromSeed(seed)
+		return privKey.Public(), privKey, nil
+	case crypto.X25519:
+		privKey, err := x25519.NewKeyFromSeed(seed)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "deriving x25519 key")
+		}
+		return privKey.Public(), privKey, nil
+	case crypto.SECP256k1:
+		var scalar secp.ModNScalar
+		if overflow := scalar.SetByteSlice(seed); overflow || scalar.IsZero() {
+			return nil, nil, fmt.Errorf("%w: seed is not a valid private key of type<%s>", ErrInvalidSeed, kt)
+		}
+		privKey := secp.NewPrivateKey(&scalar)
+		return *privKey.PubKey(), *privKey, nil
+	}
+
+	curve := ecdsaCurves[kt]
+	d := new(big.Int).SetBytes(seed)
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, nil, fmt.Errorf("%w: seed is not a valid private key of type<%s>", ErrInvalidSeed, kt)
+	}
+	privKey := ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: curve}, D: d}
+	privKey.PublicKey.X, privKey.PublicKey.Y = curve.ScalarBaseMult(seed)
+	return privKey.PublicKey, privKey, nil
+}
@@ -3,19 +3,25 @@ package did
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	didsdk "github.com/TBD54566975/ssi-sdk/did"
 	didresolution "github.com/TBD54566975/ssi-sdk/did/resolution"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
+	"go.einride.tech/aip/filtering"
 
 	"github.com/tbd54566975/ssi-service/config"
 	"github.com/tbd54566975/ssi-service/pkg/service/did/resolution"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/webhook"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
+// ErrDIDNotFound is returned when the DID is not managed by the service.
+var ErrDIDNotFound = errors.New("DID not found")
+
 type Service struct {
 	config  config.DIDServiceConfig
 	storage *Storage
@@ -30,6 +36,9 @@ type Service struct {
 	keyStore          *keystore.Service
 	keyStoreFactory   keystore.ServiceFactory
 	didStorageFactory StorageFactory
+
+	// outbox is true when DID Create events are written to the webhook outbox
+	outbox bool
 }
 
 func (s *Service) Type() framework.Type {
@@ -165,11 +174,79 @@ func (s *Service) GetSupportedMethods() GetSupportedMethodsResponse {
 }
 
 func (s *Service) CreateDIDByMethod(ctx context.Context, request CreateDIDRequest) (*CreateDIDResponse, error) {
+	if len(request.Seed) > 0 && request.Method != didsdk.KeyMethod {
+		return nil, sdkutil.LoggingNewErrorf("seeds are only supported for method<%s>", didsdk.KeyMethod)
+	}
 	handler, err := s.getHandler(request.Method)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get handler for method<%s>", request.Method)
 	}
-	return handler.CreateDID(ctx, request)
+	if s.outbox && request.Method == didsdk.KeyMethod {
+		return s.createKeyDIDWithEvent(ctx, request)
+	}
+	createDIDResponse, err := handler.CreateDID(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if metadata := request.metadata(); metadata != nil {
+		if err = s.storage.StoreMetadata(ctx, createDIDResponse.DID.ID, *metadata); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "storing metadata of DID<%s>", createDIDResponse.DID.ID)
+		}
+	}
+	if s.outbox {
+		// DIDs of other methods may be registered outside the service, such as with an ION node, so their event can
+		// only be written once they are created
+		if err = webhook.WriteOutboxEventTx(ctx, s.storage.db, webhook.DID, webhook.Create, createDIDResponse.DID.ID, createDIDResponse); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "writing create event of DID<%s>", createDIDResponse.DID.ID)
+		}
+	}
+	return createDIDResponse, nil
+}
+
+// EnableOutbox writes the DID Create events to the webhook outbox. did:key DIDs are created in the transaction writing
+// their event. It must be set before the service creates DIDs.
+func (s *Service) EnableOutbox() {
+	s.outbox = true
+}
+
+// createKeyDIDWithEvent creates a did:key DID, along with its metadata and its DID Create event, in a transaction.
+func (s *Service) createKeyDIDWithEvent(ctx context.Context, request CreateDIDRequest) (*CreateDIDResponse, error) {
+	returnValue, err := s.storage.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		keyStore, err := s.keyStoreFactory(tx)
+		if err != nil {
+			return nil, err
+		}
+		didStorage, err := s.didStorageFactory(tx)
+		if err != nil {
+			return nil, err
+		}
+		handler, err := NewKeyHandler(didStorage, keyStore)
+		if err != nil {
+			return nil, err
+		}
+		createDIDResponse, err := handler.CreateDID(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if metadata := request.metadata(); metadata != nil {
+			if err = didStorage.StoreMetadata(ctx, createDIDResponse.DID.ID, *metadata); err != nil {
+				return nil, sdkutil.LoggingErrorMsgf(err, "storing metadata of DID<%s>", createDIDResponse.DID.ID)
+			}
+		}
+		if err = webhook.WriteOutboxEventTx(ctx, tx, webhook.DID, webhook.Create, createDIDResponse.DID.ID, createDIDResponse); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "writing create event of DID<%s>", createDIDResponse.DID.ID)
+		}
+		return createDIDResponse, nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	createDIDResponse, ok := returnValue.(*CreateDIDResponse)
+	if !ok {
+		return nil, errors.New("problem casting to CreateDIDResponse")
+	}
+	return createDIDResponse, nil
 }
 
 func (s *Service) UpdateIONDID(ctx context.Context, request UpdateIONDIDRequest) (*UpdateIONDIDResponse, error) {
@@ -189,7 +266,68 @@ func (s *Service) GetDIDByMethod(ctx context.Context, request GetDIDRequest) (*G
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get handler for method<%s>", request.Method)
 	}
-	return handler.GetDID(ctx, request)
+	gotDID, err := handler.GetDID(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := s.storage.GetMetadata(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		gotDID.DisplayName = metadata.DisplayName
+		gotDID.CreatedBy = metadata.CreatedBy
+	}
+	return gotDID, nil
+}
+
+// UpdateDIDMetadata replaces the display name of a DID managed by the service. An empty display name clears it. The
+// principal which created the DID is kept.
+func (s *Service) UpdateDIDMetadata(ctx context.Context, request UpdateDIDMetadataRequest) (*GetDIDResponse, error) {
+	notFoundErr := errors.Wrapf(ErrDIDNotFound, "DID<%s> of method<%s>", request.ID, request.Method)
+	if !strings.HasPrefix(request.ID, "did:"+request.Method.String()+":") {
+		return nil, sdkutil.LoggingError(notFoundErr)
+	}
+	exists, err := s.storage.DIDExists(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "checking whether DID<%s> exists", request.ID)
+	}
+	if !exists {
+		return nil, sdkutil.LoggingError(notFoundErr)
+	}
+	metadata, err := s.storage.GetMetadata(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		metadata = new(Metadata)
+	}
+	metadata.DisplayName = request.DisplayName
+	if err = s.storage.StoreMetadata(ctx, request.ID, *metadata); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "storing metadata of DID<%s>", request.ID)
+	}
+	return s.GetDIDByMethod(ctx, GetDIDRequest{Method: request.Method, ID: request.ID})
+}
+
+// GetDisplayNames returns the display names of the DIDs which have one, keyed by DID. The metadata of each distinct
+// DID is read once, so that the display names of a page of credentials can be looked up together.
+func (s *Service) GetDisplayNames(ctx context.Context, ids []string) (map[string]string, error) {
+	displayNames := make(map[string]string)
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		metadata, err := s.storage.GetMetadata(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if metadata != nil && metadata.DisplayName != "" {
+			displayNames[id] = metadata.DisplayName
+		}
+	}
+	return displayNames, nil
 }
 
 func (s *Service) GetKeyFromDID(ctx context.Context, request GetKeyFromDIDRequest) (*GetKeyFromDIDResponse, error) {
@@ -215,10 +353,53 @@ func (s *Service) ListDIDsByMethod(ctx context.Context, request ListDIDsRequest)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get handler for method<%s>", request.Method)
 	}
+	var listed *ListDIDsResponse
 	if request.Deleted {
-		return handler.ListDeletedDIDs(ctx)
+		listed, err = handler.ListDeletedDIDs(ctx)
+	} else {
+		listed, err = handler.ListDIDs(ctx, request.PageRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.withCreatedBy(ctx, *listed, request.Filter)
+}
+
+// withCreatedBy adds the principal which created each DID listed to the response, leaving out the DIDs whose metadata
+// the filter excludes.
+func (s *Service) withCreatedBy(ctx context.Context, listed ListDIDsResponse, filter filtering.Filter) (*ListDIDsResponse, error) {
+	shouldInclude, err := storage.NewIncludeFunc(filter)
+	if err != nil {
+		return nil, err
+	}
+	response := ListDIDsResponse{
+		DIDs:          make([]didsdk.Document, 0, len(listed.DIDs)),
+		NextPageToken: listed.NextPageToken,
+	}
+	for _, document := range listed.DIDs {
+		metadata, err := s.storage.GetMetadata(ctx, document.ID)
+		if err != nil {
+			return nil, err
+		}
+		if metadata == nil {
+			metadata = new(Metadata)
+		}
+		include, err := shouldInclude(metadata)
+		if err != nil {
+			return nil, errors.Wrapf(err, "filtering DID<%s>", document.ID)
+		}
+		if !include {
+			continue
+		}
+		response.DIDs = append(response.DIDs, document)
+		if metadata.CreatedBy != "" {
+			if response.CreatedBy == nil {
+				response.CreatedBy = make(map[string]string)
+			}
+			response.CreatedBy[document.ID] = metadata.CreatedBy
+		}
 	}
-	return handler.ListDIDs(ctx, request.PageRequest)
+	return &response, nil
 }
 
 func (s *Service) SoftDeleteDIDByMethod(ctx context.Context, request DeleteDIDRequest) error {
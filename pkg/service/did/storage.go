@@ -29,6 +29,8 @@ var (
 		webNamespace: storage.MakeNamespace(namespace, webNamespace),
 		ionNamespace: storage.MakeNamespace(namespace, ionNamespace),
 	}
+	metadataNamespace   = storage.MakeNamespace(namespace, "metadata")
+	delegationNamespace = storage.MakeNamespace(namespace, "delegation")
 )
 
 // StoredDID is a DID that has been stored in the database. It is an interface to allow
@@ -131,6 +133,90 @@ func (ds *Storage) GetDIDDefault(ctx context.Context, id string) (*DefaultStored
 	return outType, nil
 }
 
+// Metadata is what the service records about a DID it manages, beyond its document.
+type Metadata struct {
+	// DisplayName is a human readable name of the DID, such as the name of the organization issuing with it.
+	DisplayName string `json:"displayName,omitempty"`
+	// CreatedBy is the principal which created the DID: the name of the API key of its caller, or `anonymous`.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+func (m *Metadata) FilterVariablesMap() map[string]any {
+	return map[string]any{
+		"createdBy": m.CreatedBy,
+	}
+}
+
+// StoreMetadata stores the metadata of a DID, replacing any stored before.
+func (ds *Storage) StoreMetadata(ctx context.Context, id string, metadata Metadata) error {
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not marshal metadata of DID: %s", id)
+	}
+	return ds.tx.Write(ctx, metadataNamespace, id, metadataBytes)
+}
+
+// GetMetadata returns the metadata of a DID, or nil when none is stored.
+func (ds *Storage) GetMetadata(ctx context.Context, id string) (*Metadata, error) {
+	metadataBytes, err := ds.db.Read(ctx, metadataNamespace, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get metadata of DID: %s", id)
+	}
+	if len(metadataBytes) == 0 {
+		return nil, nil
+	}
+	var metadata Metadata
+	if err = json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not unmarshal metadata of DID: %s", id)
+	}
+	return &metadata, nil
+}
+
+// StoreDelegation stores a delegation, replacing any stored before with the same ID.
+func (ds *Storage) StoreDelegation(ctx context.Context, delegation Delegation) error {
+	delegationBytes, err := json.Marshal(delegation)
+	if err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "could not marshal delegation: %s", delegation.ID)
+	}
+	return ds.tx.Write(ctx, delegationNamespace, delegation.ID, delegationBytes)
+}
+
+// GetDelegation returns a delegation, or nil when none is stored with the ID.
+func (ds *Storage) GetDelegation(ctx context.Context, id string) (*Delegation, error) {
+	delegationBytes, err := ds.db.Read(ctx, delegationNamespace, id)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not get delegation: %s", id)
+	}
+	if len(delegationBytes) == 0 {
+		return nil, nil
+	}
+	var delegation Delegation
+	if err = json.Unmarshal(delegationBytes, &delegation); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not unmarshal delegation: %s", id)
+	}
+	return &delegation, nil
+}
+
+// ListDelegations returns the delegations of a delegator, revoked ones included.
+func (ds *Storage) ListDelegations(ctx context.Context, delegator string) ([]Delegation, error) {
+	gotDelegations, err := ds.db.ReadAll(ctx, delegationNamespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "could not list delegations of DID: %s", delegator)
+	}
+	delegations := make([]Delegation, 0, len(gotDelegations))
+	for id, delegationBytes := range gotDelegations {
+		var delegation Delegation
+		if err = json.Unmarshal(delegationBytes, &delegation); err != nil {
+			logrus.WithError(err).Errorf("could not unmarshal delegation: %s", id)
+			continue
+		}
+		if delegation.Delegator == delegator {
+			delegations = append(delegations, delegation)
+		}
+	}
+	return delegations, nil
+}
+
 // DIDExists returns true if DID exists, false if not
 func (ds *Storage) DIDExists(ctx context.Context, id string) (bool, error) {
 	ns, err := getNamespaceForDID(id)
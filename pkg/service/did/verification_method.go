@@ -0,0 +1,85 @@
+package did
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/crypto"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+)
+
+var (
+	// ErrInvalidVerificationMethodID is returned when resolving a verification method ID which is not a DID followed by
+	// a fragment.
+	ErrInvalidVerificationMethodID = errors.New("verification method ID must be a DID followed by a fragment")
+	// ErrVerificationMethodNotFound is returned when the DID document of a verification method does not have it.
+	ErrVerificationMethodNotFound = errors.New("verification method not found")
+)
+
+// ResolveVerificationMethod returns the public key of a fully qualified verification method, such as
+// `did:key:z6Mk...#z6Mk...`. Keys of DIDs controlled by the service are read from the key store, while those of other
+// DIDs are read from their resolved DID documents.
+func (s *Service) ResolveVerificationMethod(ctx context.Context, fullyQualifiedID string) (*ResolveVerificationMethodResponse, error) {
+	didID, fragment, found := strings.Cut(fullyQualifiedID, "#")
+	if !found || fragment == "" || !strings.HasPrefix(didID, "did:") {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrInvalidVerificationMethodID, "resolving verification method<%s>", fullyQualifiedID))
+	}
+
+	controlled, err := s.keyStore.KeyExists(ctx, fullyQualifiedID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "checking key store for verification method<%s>", fullyQualifiedID)
+	}
+	if controlled {
+		gotKey, err := s.keyStore.GetKeyDetails(ctx, keystore.GetKeyDetailsRequest{ID: fullyQualifiedID})
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "getting key of verification method<%s>", fullyQualifiedID)
+		}
+		return &ResolveVerificationMethodResponse{ID: fullyQualifiedID, KeyType: gotKey.Type, PublicKeyJWK: gotKey.PublicKeyJWK}, nil
+	}
+
+	resolved, err := s.Resolve(ctx, didID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "resolving DID<%s> of verification method<%s>", didID, fullyQualifiedID)
+	}
+	publicKey, err := didsdk.GetKeyFromVerificationMethod(resolved.Document, fullyQualifiedID)
+	if err != nil {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrVerificationMethodNotFound, "DID<%s> has no verification method<%s>: %s", didID, fullyQualifiedID, err))
+	}
+	publicKeyJWK, err := jwx.PublicKeyToPublicKeyJWK(fullyQualifiedID, publicKey)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "converting key of verification method<%s> to a JWK", fullyQualifiedID)
+	}
+	keyType, err := keyTypeFromJWK(*publicKeyJWK)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "getting key type of verification method<%s>", fullyQualifiedID)
+	}
+	return &ResolveVerificationMethodResponse{ID: fullyQualifiedID, KeyType: keyType, PublicKeyJWK: *publicKeyJWK}, nil
+}
+
+// keyTypeFromJWK returns the key type of a public key JWK from its key type and curve.
+func keyTypeFromJWK(jwk jwx.PublicKeyJWK) (crypto.KeyType, error) {
+	switch {
+	case jwk.KTY == "OKP" && jwk.CRV == "Ed25519":
+		return crypto.Ed25519, nil
+	case jwk.KTY == "OKP" && jwk.CRV == "X25519":
+		return crypto.X25519, nil
+	case jwk.KTY == "EC" && jwk.CRV == "secp256k1":
+		return crypto.SECP256k1, nil
+	case jwk.KTY == "EC" && jwk.CRV == "P-256":
+		return crypto.P256, nil
+	case jwk.KTY == "EC" && jwk.CRV == "P-384":
+		return crypto.P384, nil
+	case jwk.KTY == "EC" && jwk.CRV == "P-521":
+		return crypto.P521, nil
+	case jwk.KTY == "RSA":
+		return crypto.RSA, nil
+	default:
+		return "", fmt.Errorf("unsupported key type<%s> and curve<%s>", jwk.KTY, jwk.CRV)
+	}
+}
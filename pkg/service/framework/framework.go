@@ -18,6 +18,9 @@ const (
 	Operation        Type = "operation"
 	Webhook          Type = "webhook"
 	DIDConfiguration Type = "did_configuration"
+	IssuerMetadata   Type = "issuer_metadata"
+	SSIConfiguration Type = "ssi_configuration"
+	Admin            Type = "admin"
 
 	StatusReady    StatusState = "ready"
 	StatusNotReady StatusState = "not_ready"
@@ -34,6 +37,10 @@ type Status struct {
 
 	// When `status` is `"not_ready"`, message contains an explanation of why it's not ready.
 	Message string `json:"message,omitempty"`
+
+	// Details about the health of the service which do not make it not ready, such as the status list credentials its
+	// status endpoint does not serve as stored.
+	Details any `json:"details,omitempty"`
 }
 
 func (s Status) IsReady() bool {
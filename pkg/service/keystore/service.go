@@ -100,6 +100,36 @@ func (s Service) StoreKey(ctx context.Context, request StoreKeyRequest) error {
 	return nil
 }
 
+// ImportKey stores a key exactly as given, preserving metadata such as when it was created and whether it was revoked.
+func (s Service) ImportKey(ctx context.Context, key StoredKey) error {
+	if !crypto.IsSupportedKeyType(key.KeyType) {
+		return sdkutil.LoggingNewErrorf("unsupported key type: %s", key.KeyType)
+	}
+	if err := s.storage.StoreKey(ctx, key); err != nil {
+		return sdkutil.LoggingErrorMsgf(err, "importing key: %s", key.ID)
+	}
+	return nil
+}
+
+// KeyExists returns true if a key is stored with the given id
+func (s Service) KeyExists(ctx context.Context, id string) (bool, error) {
+	exists, err := s.storage.KeyExists(ctx, id)
+	if err != nil {
+		return false, sdkutil.LoggingErrorMsgf(err, "checking existence of key: %s", id)
+	}
+	return exists, nil
+}
+
+// ListKeysByController returns the stored keys, including their private material, controlled by any of the given
+// controllers.
+func (s Service) ListKeysByController(ctx context.Context, controllers []string) ([]StoredKey, error) {
+	keys, err := s.storage.ListKeysByController(ctx, controllers)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "listing keys by controller")
+	}
+	return keys, nil
+}
+
 func (s Service) GetKey(ctx context.Context, request GetKeyRequest) (*GetKeyResponse, error) {
 	logrus.Debugf("getting key: %+v", request)
 
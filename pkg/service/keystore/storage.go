@@ -11,6 +11,7 @@ import (
 	"github.com/goccy/go-json"
 	"github.com/mr-tron/base58"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/tbd54566975/ssi-service/internal/encryption"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
@@ -264,6 +265,42 @@ func (kss *Storage) GetKey(ctx context.Context, id string) (*StoredKey, error) {
 	return &stored, nil
 }
 
+// KeyExists returns true if a key is stored with the given id
+func (kss *Storage) KeyExists(ctx context.Context, id string) (bool, error) {
+	return kss.db.Exists(ctx, namespace, id)
+}
+
+// ListKeysByController returns every stored key whose controller is one of the given controllers. Entries which
+// cannot be decrypted or unmarshalled are skipped.
+func (kss *Storage) ListKeysByController(ctx context.Context, controllers []string) ([]StoredKey, error) {
+	controllerSet := make(map[string]bool, len(controllers))
+	for _, controller := range controllers {
+		controllerSet[controller] = true
+	}
+
+	storedKeys, err := kss.db.ReadAll(ctx, namespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "reading all keys")
+	}
+	keys := make([]StoredKey, 0)
+	for id, storedKeyBytes := range storedKeys {
+		decryptedKey, err := kss.decrypter.Decrypt(ctx, storedKeyBytes, nil)
+		if err != nil {
+			logrus.WithError(err).Errorf("could not decrypt key: %s", id)
+			continue
+		}
+		var stored StoredKey
+		if err = json.Unmarshal(decryptedKey, &stored); err != nil {
+			logrus.WithError(err).Errorf("unmarshalling stored key: %s", id)
+			continue
+		}
+		if controllerSet[stored.Controller] {
+			keys = append(keys, stored)
+		}
+	}
+	return keys, nil
+}
+
 func (kss *Storage) GetKeyDetails(ctx context.Context, id string) (*KeyDetails, error) {
 	stored, err := kss.GetKey(ctx, id)
 	if err != nil {
@@ -0,0 +1,293 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/benbjohnson/clock"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+const (
+	namespace = "leader-lease"
+
+	// BackgroundWorkersLease is the name of the lease which the background workers run under.
+	BackgroundWorkersLease = "background-workers"
+)
+
+// Lease is held by a single replica at a time, until it expires without being renewed.
+type Lease struct {
+	Holder    string `json:"holder"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// Elector acquires and renews a lease stored in the storage shared by the replicas of the service, so that only the
+// replica holding it runs the background workers. When the holder stops renewing the lease, such as when it crashes,
+// another replica takes it over once it expires. A replica stops considering itself the leader as soon as it fails to
+// renew the lease, but the workers it ran may briefly overlap with those of the next leader, so jobs must still be
+// idempotent. The replica also stops considering itself the leader once the lease it last acquired or renewed expires,
+// even when it did not get to renew it, such as when renewals are stuck on the storage.
+type Elector struct {
+	db            storage.ServiceStorage
+	name          string
+	holderID      string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	Clock         clock.Clock
+
+	// leaseExpiresAt is the expiry, in Unix nanoseconds by Clock, of the lease this replica last acquired or renewed, or 0
+	// when it does not hold the lease.
+	leaseExpiresAt atomic.Int64
+}
+
+// NewElector returns an elector for the lease with the given name, which is not held until the elector is run.
+func NewElector(db storage.ServiceStorage, name string, config config.LeaderElectionConfig) (*Elector, error) {
+	if db == nil {
+		return nil, sdkutil.LoggingNewError("no storage configured for leader election")
+	}
+	leaseDuration, err := time.ParseDuration(config.LeaseDuration)
+	if err != nil || leaseDuration <= 0 {
+		return nil, sdkutil.LoggingNewErrorf("invalid lease duration: %s", config.LeaseDuration)
+	}
+	renewInterval, err := time.ParseDuration(config.RenewInterval)
+	if err != nil || renewInterval <= 0 || renewInterval >= leaseDuration {
+		return nil, sdkutil.LoggingNewErrorf("invalid renew interval<%s>, which must be shorter than the lease duration", config.RenewInterval)
+	}
+	holderID := config.HolderID
+	if holderID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "ssi-service"
+		}
+		holderID = fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+	}
+
+	elector := Elector{
+		db:            db,
+		name:          name,
+		holderID:      holderID,
+		leaseDuration: leaseDuration,
+		renewInterval: renewInterval,
+		Clock:         clock.New(),
+	}
+	if err = elector.registerLeaderGauge(); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate leader election metrics")
+	}
+	return &elector, nil
+}
+
+// HolderID identifies this replica as the holder of the lease.
+func (e *Elector) HolderID() string {
+	return e.holderID
+}
+
+// IsLeader returns whether this replica held the lease when it last tried to acquire or renew it, and the lease it
+// acquired or renewed then has not expired since.
+func (e *Elector) IsLeader() bool {
+	_, leading := e.heldUntil()
+	return leading
+}
+
+// heldUntil returns when the lease held by this replica expires, and whether it is held and has not expired yet.
+func (e *Elector) heldUntil() (time.Time, bool) {
+	expiresAt := e.leaseExpiresAt.Load()
+	if expiresAt == 0 {
+		return time.Time{}, false
+	}
+	expiry := time.Unix(0, expiresAt)
+	return expiry, e.Clock.Now().Before(expiry)
+}
+
+// TryAcquire acquires the lease when it is not held or has expired, or renews it when this replica holds it, and
+// returns whether this replica holds it. This replica is not the leader when the lease cannot be read or written. The
+// lease is held until the lease duration after TryAcquire was called, since it was written with that expiry.
+func (e *Elector) TryAcquire(ctx context.Context) (bool, error) {
+	now := e.Clock.Now()
+	expiresAt := now.Add(e.leaseDuration)
+	acquireFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		current, err := e.GetLease(ctx)
+		if err != nil {
+			return false, err
+		}
+		if current != nil && current.Holder != e.holderID {
+			expiresAt, err := time.Parse(time.RFC3339Nano, current.ExpiresAt)
+			if err != nil {
+				return false, errors.Wrapf(err, "parsing expiry of lease<%s>", e.name)
+			}
+			if now.Before(expiresAt) {
+				return false, nil
+			}
+			logrus.Infof("lease<%s> of holder<%s> expired at %s", e.name, current.Holder, current.ExpiresAt)
+		}
+		lease := Lease{Holder: e.holderID, ExpiresAt: expiresAt.Format(time.RFC3339Nano)}
+		leaseBytes, err := json.Marshal(lease)
+		if err != nil {
+			return false, errors.Wrapf(err, "marshalling lease<%s>", e.name)
+		}
+		return true, tx.Write(ctx, namespace, e.name, leaseBytes)
+	}
+	result, err := e.db.Execute(ctx, acquireFunc, []storage.WatchKey{{Namespace: namespace, Key: e.name}})
+	if err != nil {
+		e.setLeaseExpiry(time.Time{})
+		return false, errors.Wrapf(err, "acquiring lease<%s>", e.name)
+	}
+	leading, ok := result.(bool)
+	if !ok {
+		e.setLeaseExpiry(time.Time{})
+		return false, errors.New("casting lease acquisition result")
+	}
+	if !leading {
+		expiresAt = time.Time{}
+	}
+	e.setLeaseExpiry(expiresAt)
+	return leading, nil
+}
+
+// Release gives up the lease when this replica holds it, so that another replica takes it over without waiting for
+// it to expire.
+func (e *Elector) Release(ctx context.Context) error {
+	e.setLeaseExpiry(time.Time{})
+	releaseFunc := func(ctx context.Context, tx storage.Tx) (any, error) {
+		current, err := e.GetLease(ctx)
+		if err != nil || current == nil || current.Holder != e.holderID {
+			return nil, err
+		}
+		return nil, tx.Delete(ctx, namespace, e.name)
+	}
+	if _, err := e.db.Execute(ctx, releaseFunc, []storage.WatchKey{{Namespace: namespace, Key: e.name}}); err != nil {
+		return errors.Wrapf(err, "releasing lease<%s>", e.name)
+	}
+	return nil
+}
+
+// GetLease returns the lease as stored, or nil when no replica holds it.
+func (e *Elector) GetLease(ctx context.Context) (*Lease, error) {
+	leaseBytes, err := e.db.Read(ctx, namespace, e.name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading lease<%s>", e.name)
+	}
+	if len(leaseBytes) == 0 {
+		return nil, nil
+	}
+	var lease Lease
+	if err = json.Unmarshal(leaseBytes, &lease); err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling lease<%s>", e.name)
+	}
+	return &lease, nil
+}
+
+// Run tries to acquire or renew the lease every renew interval, until the context is done, after which the lease is
+// released.
+func (e *Elector) Run(ctx context.Context) {
+	defer func() {
+		if err := e.Release(context.WithoutCancel(ctx)); err != nil {
+			logrus.WithError(err).Warn("releasing leader lease")
+		}
+	}()
+	ticker := e.Clock.Ticker(e.renewInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := e.TryAcquire(ctx); err != nil {
+			logrus.WithError(err).Error("acquiring leader lease")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunWhileLeading runs the worker while this replica is the leader, which is checked every renew interval, and when the
+// lease it holds expires. The context of the worker is canceled, and the worker waited for, as soon as this replica is
+// no longer the leader, and the worker is run again once it is.
+func (e *Elector) RunWhileLeading(ctx context.Context, run func(ctx context.Context)) {
+	var cancel context.CancelFunc
+	var done chan struct{}
+	stop := func() {
+		if cancel == nil {
+			return
+		}
+		cancel()
+		<-done
+		cancel = nil
+	}
+	defer stop()
+
+	ticker := e.Clock.Ticker(e.renewInterval)
+	defer ticker.Stop()
+	for {
+		expiresAt, leading := e.heldUntil()
+		if !leading {
+			stop()
+		} else if cancel == nil {
+			workerCtx, cancelWorker := context.WithCancel(ctx)
+			cancel = cancelWorker
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				run(workerCtx)
+			}()
+		}
+		// the lease is checked again when it expires, in case it is not renewed by then
+		var expiry *clock.Timer
+		var expired <-chan time.Time
+		if leading {
+			expiry = e.Clock.Timer(expiresAt.Sub(e.Clock.Now()))
+			expired = expiry.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		case <-expired:
+		}
+		if expiry != nil {
+			expiry.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// setLeaseExpiry records the expiry of the lease held by this replica, which is zero when it does not hold it.
+func (e *Elector) setLeaseExpiry(expiresAt time.Time) {
+	var expiresAtNanos int64
+	if !expiresAt.IsZero() {
+		expiresAtNanos = expiresAt.UnixNano()
+	}
+	if previous := e.leaseExpiresAt.Swap(expiresAtNanos); (previous != 0) != (expiresAtNanos != 0) {
+		logrus.Infof("holder<%s> is the leader of lease<%s>: %t", e.holderID, e.name, expiresAtNanos != 0)
+	}
+}
+
+// registerLeaderGauge reports 1 while this replica is the leader, and 0 otherwise, by holder, so that the current
+// leader is the holder reporting 1.
+func (e *Elector) registerLeaderGauge() error {
+	_, err := otel.Meter(config.ServiceName).Int64ObservableGauge(
+		"ssi_service.leader",
+		metric.WithDescription("Whether this replica holds the lease to run background workers, by holder"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			var leading int64
+			if e.IsLeader() {
+				leading = 1
+			}
+			observer.Observe(leading, metric.WithAttributes(attribute.String("lease", e.name), attribute.String("holder", e.holderID)))
+			return nil
+		}),
+	)
+	return err
+}
@@ -0,0 +1,128 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/testutil"
+)
+
+func TestElector(t *testing.T) {
+	for _, test := range testutil.TestDatabases {
+		t.Run(test.Name, func(tt *testing.T) {
+			db := test.ServiceStorage(tt)
+			mockClock := clock.NewMock()
+			mockClock.Set(time.Date(2023, 06, 23, 0, 0, 0, 0, time.UTC))
+			newElector := func(holderID string) *Elector {
+				elector, err := NewElector(db, BackgroundWorkersLease, config.LeaderElectionConfig{HolderID: holderID, LeaseDuration: "30s", RenewInterval: "10s"})
+				require.NoError(tt, err)
+				elector.Clock = mockClock
+				return elector
+			}
+			first, second := newElector("first"), newElector("second")
+
+			// each scheduler renews or acquires the lease on its tick, and only runs the job while it holds it
+			executions := make(map[string]int)
+			tick := func(elector *Elector) {
+				leading, err := elector.TryAcquire(context.Background())
+				require.NoError(tt, err)
+				if leading {
+					executions[elector.HolderID()]++
+				}
+			}
+
+			for i := 0; i < 5; i++ {
+				tick(first)
+				tick(second)
+				mockClock.Add(10 * time.Second)
+			}
+			assert.Equal(tt, 5, executions["first"])
+			assert.Zero(tt, executions["second"])
+			assert.True(tt, first.IsLeader())
+			assert.False(tt, second.IsLeader())
+			lease, err := second.GetLease(context.Background())
+			require.NoError(tt, err)
+			assert.Equal(tt, "first", lease.Holder)
+
+			// the first scheduler stops renewing, and the second takes over once the lease, last renewed 10s ago, lapses
+			tick(second)
+			mockClock.Add(19 * time.Second)
+			tick(second)
+			assert.Zero(tt, executions["second"])
+			mockClock.Add(time.Second)
+			tick(second)
+			assert.Equal(tt, 1, executions["second"])
+
+			// the first scheduler no longer runs the job once it finds the lease taken over
+			tick(first)
+			assert.Equal(tt, 5, executions["first"])
+			assert.False(tt, first.IsLeader())
+
+			// releasing the lease hands it over without waiting for it to expire
+			require.NoError(tt, second.Release(context.Background()))
+			assert.False(tt, second.IsLeader())
+			tick(first)
+			assert.Equal(tt, 6, executions["first"])
+		})
+	}
+}
+
+func TestElectorLeaseExpiry(t *testing.T) {
+	db := testutil.TestDatabases[0].ServiceStorage(t)
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2023, 06, 23, 0, 0, 0, 0, time.UTC))
+	elector, err := NewElector(db, BackgroundWorkersLease, config.LeaderElectionConfig{HolderID: "first", LeaseDuration: "30s", RenewInterval: "10s"})
+	require.NoError(t, err)
+	elector.Clock = mockClock
+
+	leading, err := elector.TryAcquire(context.Background())
+	require.NoError(t, err)
+	require.True(t, leading)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started, stopped := make(chan struct{}), make(chan struct{})
+	go elector.RunWhileLeading(ctx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+	})
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "worker did not start")
+	}
+
+	// the lease is not renewed, so it is lost once it expires, and the worker is stopped
+	mockClock.Add(29 * time.Second)
+	assert.True(t, elector.IsLeader())
+	mockClock.Add(time.Second)
+	assert.False(t, elector.IsLeader())
+	assert.Eventually(t, func() bool {
+		mockClock.Add(0)
+		select {
+		case <-stopped:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewElector(t *testing.T) {
+	db := testutil.TestDatabases[0].ServiceStorage(t)
+
+	_, err := NewElector(db, BackgroundWorkersLease, config.LeaderElectionConfig{LeaseDuration: "10s", RenewInterval: "10s"})
+	assert.ErrorContains(t, err, "must be shorter than the lease duration")
+
+	elector, err := NewElector(db, BackgroundWorkersLease, config.LeaderElectionConfig{LeaseDuration: "30s", RenewInterval: "10s"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, elector.HolderID())
+	assert.False(t, elector.IsLeader())
+}
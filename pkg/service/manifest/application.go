@@ -7,9 +7,12 @@ import (
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/pkg/errors"
 
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	didint "github.com/tbd54566975/ssi-service/internal/did"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
 
 	"github.com/TBD54566975/ssi-sdk/credential/manifest"
@@ -20,8 +23,8 @@ import (
 
 // validateCredentialApplication validates the credential application's signature(s) in addition to making sure it
 // is a valid credential application, and complies with its corresponding manifest. it returns the ids of unfulfilled
-// input descriptors along with an error if validation fails.
-func (s Service) validateCredentialApplication(ctx context.Context, credManifest manifest.CredentialManifest, request model.SubmitApplicationRequest) (inputDescriptorIDs []string, err error) {
+// input descriptors along with an error if validation fails, and warnings about the application which did not fail it.
+func (s Service) validateCredentialApplication(ctx context.Context, credManifest manifest.CredentialManifest, request model.SubmitApplicationRequest) (inputDescriptorIDs []string, warnings []string, err error) {
 	// parse headers
 	headers, err := keyaccess.GetJWTHeaders([]byte(request.ApplicationJWT.String()))
 	if err != nil {
@@ -76,6 +79,20 @@ func (s Service) validateCredentialApplication(ctx context.Context, credManifest
 		return
 	}
 
+	// copies of a credential in several formats count once against the submission requirements
+	if credManifest.PresentationDefinition != nil && credApp.PresentationSubmission != nil {
+		var distinctErr error
+		warnings, distinctErr = verification.CheckDistinctSubmission(*credManifest.PresentationDefinition, *credApp.PresentationSubmission, request.ApplicationJSON, submittedCredentials(request.Credentials))
+		if distinctErr != nil {
+			if errors.Is(distinctErr, verification.ErrUnmetSubmissionRequirement) {
+				err = errresp.NewErrorResponseWithError(DenialResponse, distinctErr)
+				return
+			}
+			err = sdkutil.LoggingErrorMsgf(distinctErr, "could not check distinct credentials of application: %s", credApp.ID)
+			return
+		}
+	}
+
 	// signature and validity checks for each credential submitted with the application
 	for _, credentialContainer := range request.Credentials {
 		verificationResult, verificationErr := s.credential.VerifyCredential(ctx, credential.VerifyCredentialRequest{
@@ -95,3 +112,16 @@ func (s Service) validateCredentialApplication(ctx context.Context, credManifest
 	}
 	return
 }
+
+// submittedCredentials returns each credential submitted with an application, as a JWT or as a JSON object.
+func submittedCredentials(containers []credint.Container) []any {
+	submitted := make([]any, 0, len(containers))
+	for _, container := range containers {
+		if container.HasJWTCredential() {
+			submitted = append(submitted, container.CredentialJWT.String())
+		} else if container.Credential != nil {
+			submitted = append(submitted, *container.Credential)
+		}
+	}
+	return submitted
+}
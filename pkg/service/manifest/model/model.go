@@ -72,6 +72,9 @@ type SubmitApplicationRequest struct {
 	Credentials     []cred.Container                  `json:"credentials,omitempty"`
 	ApplicationJWT  keyaccess.JWT                     `json:"applicationJwt,omitempty" validate:"required"`
 	ApplicationJSON map[string]any                    `json:"applicationJson,omitempty"`
+	// PresentationJWT is an optional verifiable presentation submitted with the application, which is verified
+	// when the application is submitted.
+	PresentationJWT *keyaccess.JWT `json:"presentationJwt,omitempty"`
 }
 
 type SubmitApplicationResponse struct {
@@ -89,10 +92,16 @@ type GetApplicationResponse struct {
 	// SubmissionApplicationResponse is guaranteed to exist.
 	Status      string
 	Application manifestsdk.CredentialApplication `json:"application"`
+	// PresentationVerification is only set when the application was submitted with a verifiable presentation.
+	PresentationVerification *storage.PresentationVerification `json:"presentationVerification,omitempty"`
+	Warnings                 []string                          `json:"warnings,omitempty"`
 }
 
 type ListApplicationsResponse struct {
 	Applications []manifestsdk.CredentialApplication `json:"applications,omitempty"`
+	// PresentationVerifications maps the ID of each application submitted with a verifiable presentation to the
+	// outcome of its verification.
+	PresentationVerifications map[string]storage.PresentationVerification `json:"presentationVerifications,omitempty"`
 }
 
 type DeleteApplicationRequest struct {
@@ -0,0 +1,213 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/exchange"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/credential/manifest"
+	statussdk "github.com/TBD54566975/ssi-sdk/credential/status"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/goccy/go-json"
+
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
+	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
+	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
+)
+
+// Names of the checks run on the verifiable presentation submitted with an application.
+const (
+	SignatureCheck     = "signature"
+	StatusCheck        = "status"
+	HolderBindingCheck = "holderBinding"
+	DefinitionCheck    = "definition"
+)
+
+// verifyApplicationPresentation runs every check on the verifiable presentation submitted with an application. Checks
+// which cannot run because the presentation could not be parsed are reported as failed.
+func (s Service) verifyApplicationPresentation(ctx context.Context, credManifest manifest.CredentialManifest, applicantDID string, token keyaccess.JWT) *manifeststg.PresentationVerification {
+	checks := []manifeststg.PresentationCheck{s.checkPresentationSignature(ctx, token)}
+
+	_, _, vp, err := integrity.ParseVerifiablePresentationFromJWT(token.String())
+	if err == nil {
+		var creds []credint.Container
+		if creds, err = credint.NewCredentialContainerFromArray(vp.VerifiableCredential); err == nil {
+			checks = append(checks,
+				s.checkPresentationStatus(ctx, creds),
+				checkPresentationHolderBinding(vp.Holder, applicantDID, creds),
+				checkPresentationDefinition(credManifest, *vp),
+			)
+		}
+	}
+	if err != nil {
+		reason := fmt.Sprintf("presentation could not be parsed: %s", err)
+		for _, name := range []string{StatusCheck, HolderBindingCheck, DefinitionCheck} {
+			checks = append(checks, manifeststg.PresentationCheck{Name: name, Reason: reason})
+		}
+	}
+
+	verified := true
+	for _, check := range checks {
+		verified = verified && check.Passed
+	}
+	return &manifeststg.PresentationVerification{
+		Verified:   verified,
+		Checks:     checks,
+		VerifiedAt: s.Clock.Now().Format(time.RFC3339),
+	}
+}
+
+// checkPresentationSignature verifies the signature of the presentation, and the signature and static validity of
+// each credential in it.
+func (s Service) checkPresentationSignature(ctx context.Context, token keyaccess.JWT) manifeststg.PresentationCheck {
+	check := manifeststg.PresentationCheck{Name: SignatureCheck}
+	if s.presentationSvc == nil {
+		check.Reason = "no presentation service configured"
+		return check
+	}
+	resp, err := s.presentationSvc.VerifyPresentation(ctx, presentation.VerifyPresentationRequest{PresentationJWT: &token})
+	if err != nil {
+		check.Reason = err.Error()
+		return check
+	}
+	check.Passed = resp.Verified
+	check.Reason = resp.Reason
+	return check
+}
+
+// checkPresentationStatus makes sure no credential in the presentation is revoked or suspended. Only status lists
+// hosted by this service are checked.
+func (s Service) checkPresentationStatus(ctx context.Context, creds []credint.Container) manifeststg.PresentationCheck {
+	check := manifeststg.PresentationCheck{Name: StatusCheck, Passed: true}
+	var unchecked []string
+	for _, cred := range creds {
+		if cred.Credential == nil || cred.Credential.CredentialStatus == nil {
+			continue
+		}
+		statusMap, ok := cred.Credential.CredentialStatus.(map[string]any)
+		if !ok || !isStatusList2021Entry(statusMap) {
+			unchecked = append(unchecked, cred.Credential.ID)
+			continue
+		}
+		statusListURI := statusMap["statusListCredential"].(string)
+		if !strings.HasPrefix(statusListURI, config.GetStatusBase()+"/") {
+			unchecked = append(unchecked, cred.Credential.ID)
+			continue
+		}
+		statusList, err := s.credential.GetCredentialStatusList(ctx, credential.GetCredentialStatusListRequest{
+			ID: statusListURI[strings.LastIndex(statusListURI, "/")+1:],
+		})
+		if err != nil {
+			return failedCheck(check, "could not get status list<%s> of credential<%s>: %s", statusListURI, cred.Credential.ID, err)
+		}
+		set, err := statussdk.ValidateCredentialInStatusList(*cred.Credential, *statusList.Credential)
+		if err != nil {
+			return failedCheck(check, "could not check status of credential<%s>: %s", cred.Credential.ID, err)
+		}
+		if set {
+			return failedCheck(check, "credential<%s> is %s", cred.Credential.ID, statusDescription(statusMap["statusPurpose"]))
+		}
+	}
+	if len(unchecked) > 0 {
+		check.Reason = fmt.Sprintf("status lists not hosted by this service were not checked for credential(s): %s", strings.Join(unchecked, ", "))
+	}
+	return check
+}
+
+// checkPresentationHolderBinding makes sure the presentation was made by the applicant, and that the applicant is the
+// subject of each credential in it.
+func checkPresentationHolderBinding(holder, applicantDID string, creds []credint.Container) manifeststg.PresentationCheck {
+	check := manifeststg.PresentationCheck{Name: HolderBindingCheck}
+	if holder != applicantDID {
+		return failedCheck(check, "presentation holder<%s> is not the applicant<%s>", holder, applicantDID)
+	}
+	for _, cred := range creds {
+		if cred.Credential == nil {
+			continue
+		}
+		if subject := cred.Credential.CredentialSubject.GetID(); subject != holder {
+			return failedCheck(check, "subject<%s> of credential<%s> is not the holder<%s>", subject, cred.Credential.ID, holder)
+		}
+	}
+	check.Passed = true
+	return check
+}
+
+// checkPresentationDefinition makes sure the presentation submission of the presentation satisfies the presentation
+// definition of the manifest. Credentials submitted more than once are reported in the reason of the passed check.
+func checkPresentationDefinition(credManifest manifest.CredentialManifest, vp credsdk.VerifiablePresentation) manifeststg.PresentationCheck {
+	check := manifeststg.PresentationCheck{Name: DefinitionCheck, Passed: true}
+	if credManifest.PresentationDefinition == nil {
+		check.Reason = "manifest has no presentation definition"
+		return check
+	}
+	if _, err := exchange.VerifyPresentationSubmissionVP(*credManifest.PresentationDefinition, vp); err != nil {
+		return failedCheck(check, "presentation does not satisfy definition<%s>: %s", credManifest.PresentationDefinition.ID, err)
+	}
+
+	// copies of a credential in several formats count once against the submission requirements
+	var submission exchange.PresentationSubmission
+	submissionBytes, err := json.Marshal(vp.PresentationSubmission)
+	if err == nil {
+		err = json.Unmarshal(submissionBytes, &submission)
+	}
+	if err != nil {
+		return failedCheck(check, "could not parse presentation submission: %s", err)
+	}
+	vpJSON, err := sdkutil.ToJSONMap(vp)
+	if err != nil {
+		return failedCheck(check, "could not turn presentation into json: %s", err)
+	}
+	warnings, err := verification.CheckDistinctSubmission(*credManifest.PresentationDefinition, submission, vpJSON, vp.VerifiableCredential)
+	if err != nil {
+		return failedCheck(check, "presentation does not satisfy definition<%s>: %s", credManifest.PresentationDefinition.ID, err)
+	}
+	check.Reason = strings.Join(warnings, "; ")
+	return check
+}
+
+func failedCheck(check manifeststg.PresentationCheck, format string, args ...any) manifeststg.PresentationCheck {
+	check.Passed = false
+	check.Reason = fmt.Sprintf(format, args...)
+	return check
+}
+
+// isStatusList2021Entry returns whether the credential status has every property of a StatusList2021Entry, which the
+// ssi-sdk expects when checking a credential against its status list.
+func isStatusList2021Entry(statusMap map[string]any) bool {
+	if statusMap["type"] != statussdk.StatusList2021EntryType {
+		return false
+	}
+	for _, property := range []string{"id", "statusPurpose", "statusListIndex", "statusListCredential"} {
+		if _, ok := statusMap[property].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func statusDescription(purpose any) string {
+	if purpose == string(statussdk.StatusSuspension) {
+		return "suspended"
+	}
+	return "revoked"
+}
+
+// presentationFailureReason summarizes the failed checks of a presentation verification.
+func presentationFailureReason(verification manifeststg.PresentationVerification) string {
+	var reasons []string
+	for _, check := range verification.Checks {
+		if !check.Passed {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", check.Name, check.Reason))
+		}
+	}
+	return "presentation verification failed: " + strings.Join(reasons, "; ")
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tbd54566975/ssi-service/config"
 	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/service/common"
@@ -35,6 +36,7 @@ import (
 const requestNamespace = "manifest_request"
 
 type Service struct {
+	config                  config.ManifestServiceConfig
 	storage                 *manifeststg.Storage
 	opsStorage              *operation.Storage
 	issuanceTemplateStorage *issuance.Storage
@@ -76,7 +78,7 @@ func (s Service) Status() framework.Status {
 	return framework.Status{Status: framework.StatusReady}
 }
 
-func NewManifestService(s storage.ServiceStorage, keyStore *keystore.Service, didResolver resolution.Resolver, credential *credential.Service, presentationSvc *presentation.Service) (*Service, error) {
+func NewManifestService(config config.ManifestServiceConfig, s storage.ServiceStorage, keyStore *keystore.Service, didResolver resolution.Resolver, credential *credential.Service, presentationSvc *presentation.Service) (*Service, error) {
 	manifestStorage, err := manifeststg.NewManifestStorage(s)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate storage for the manifest service")
@@ -91,6 +93,7 @@ func NewManifestService(s storage.ServiceStorage, keyStore *keystore.Service, di
 	}
 	requestStorage := common.NewRequestStorage(s, requestNamespace)
 	return &Service{
+		config:                  config,
 		storage:                 manifestStorage,
 		opsStorage:              opsStorage,
 		issuanceTemplateStorage: issuanceStorage,
@@ -286,7 +289,7 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 	opID := opcredential.IDFromResponseID(applicationID)
 
 	// validate the application
-	unfulfilledInputDescriptorIDs, validationErr := s.validateCredentialApplication(ctx, gotManifest.Manifest, request)
+	unfulfilledInputDescriptorIDs, warnings, validationErr := s.validateCredentialApplication(ctx, gotManifest.Manifest, request)
 	if validationErr != nil {
 		resp := errresp.GetErrorResponse(validationErr)
 		if resp.ErrorType == DenialResponse {
@@ -312,16 +315,24 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 		return nil, sdkutil.LoggingErrorMsg(validationErr, "could not validate application")
 	}
 
-	// store the application
+	// verify the presentation submitted with the application, if any
 	applicantDID := request.ApplicantDID
+	var presentationVerification *manifeststg.PresentationVerification
+	if request.PresentationJWT != nil {
+		presentationVerification = s.verifyApplicationPresentation(ctx, gotManifest.Manifest, applicantDID, *request.PresentationJWT)
+	}
+
+	// store the application
 	storageRequest := manifeststg.StoredApplication{
-		ID:             applicationID,
-		Status:         opcredential.StatusPending,
-		ManifestID:     manifestID,
-		ApplicantDID:   applicantDID,
-		Application:    request.Application,
-		Credentials:    request.Credentials,
-		ApplicationJWT: request.ApplicationJWT,
+		ID:                       applicationID,
+		Status:                   opcredential.StatusPending,
+		ManifestID:               manifestID,
+		ApplicantDID:             applicantDID,
+		Application:              request.Application,
+		Credentials:              request.Credentials,
+		ApplicationJWT:           request.ApplicationJWT,
+		PresentationVerification: presentationVerification,
+		Warnings:                 warnings,
 	}
 	if err = s.storage.StoreApplication(ctx, storageRequest); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not store application")
@@ -332,6 +343,23 @@ func (s Service) ProcessApplicationSubmission(ctx context.Context, request model
 		return nil, errors.Wrap(err, "storing operation")
 	}
 
+	// applications with a presentation which failed verification are never fulfilled automatically
+	if presentationVerification != nil && !presentationVerification.Verified {
+		reason := presentationFailureReason(*presentationVerification)
+		if !s.config.DenyUnverifiedPresentations {
+			logrus.Warnf("application<%s> is left pending for review: %s", applicationID, reason)
+			return operation.ServiceModel(*storedOp)
+		}
+		if _, err = s.ReviewApplication(ctx, model.ReviewApplicationRequest{ID: applicationID, Approved: false, Reason: reason}); err != nil {
+			return nil, errors.Wrap(err, "denying application")
+		}
+		deniedOp, err := s.opsStorage.GetOperation(ctx, opID)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetching operation")
+		}
+		return operation.ServiceModel(deniedOp)
+	}
+
 	autoStoredOp, err := s.attemptAutomaticIssuance(ctx, request, manifestID, applicantDID, applicationID, *gotManifest)
 	if err != nil {
 		return nil, err
@@ -470,7 +498,11 @@ func (s Service) GetApplication(ctx context.Context, request model.GetApplicatio
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not get application: %s", request.ID)
 	}
 
-	response := model.GetApplicationResponse{Application: gotApp.Application}
+	response := model.GetApplicationResponse{
+		Application:              gotApp.Application,
+		PresentationVerification: gotApp.PresentationVerification,
+		Warnings:                 gotApp.Warnings,
+	}
 	return &response, nil
 }
 
@@ -483,11 +515,18 @@ func (s Service) ListApplications(ctx context.Context) (*model.ListApplicationsR
 	}
 
 	apps := make([]manifest.CredentialApplication, 0, len(gotApps))
+	var verifications map[string]manifeststg.PresentationVerification
 	for _, cred := range gotApps {
 		apps = append(apps, cred.Application)
+		if cred.PresentationVerification != nil {
+			if verifications == nil {
+				verifications = make(map[string]manifeststg.PresentationVerification)
+			}
+			verifications[cred.ID] = *cred.PresentationVerification
+		}
 	}
 
-	response := model.ListApplicationsResponse{Applications: apps}
+	response := model.ListApplicationsResponse{Applications: apps, PresentationVerifications: verifications}
 	return &response, nil
 }
 
@@ -40,6 +40,29 @@ type StoredApplication struct {
 	Application    manifest.CredentialApplication `json:"application"`
 	Credentials    []cred.Container               `json:"credentials"`
 	ApplicationJWT keyaccess.JWT                  `json:"applicationJwt"`
+
+	// PresentationVerification is only set when the application was submitted with a verifiable presentation.
+	PresentationVerification *PresentationVerification `json:"presentationVerification,omitempty"`
+	// Warnings are findings about the application which did not deny it, such as credentials submitted more than
+	// once.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PresentationVerification is the outcome of verifying the verifiable presentation submitted with an application.
+type PresentationVerification struct {
+	// Verified is true when every check passed.
+	Verified bool                `json:"verified"`
+	Checks   []PresentationCheck `json:"checks"`
+	// VerifiedAt is the RFC3339 time at which the presentation was verified.
+	VerifiedAt string `json:"verifiedAt"`
+}
+
+// PresentationCheck is the outcome of one of the checks run on a verifiable presentation, such as its signature.
+type PresentationCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Reason explains why the check failed, or why it was not applicable.
+	Reason string `json:"reason,omitempty"`
 }
 
 type StoredResponse struct {
@@ -0,0 +1,13 @@
+package reindex
+
+import "fmt"
+
+const (
+	// ParentResource is the prefix of the reindex parent resource.
+	ParentResource = "admin/reindexes"
+)
+
+// IDFromReindexID returns an operation ID from the ID of a reindex.
+func IDFromReindexID(id string) string {
+	return fmt.Sprintf("%s/%s", ParentResource, id)
+}
@@ -10,10 +10,13 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	credsvc "github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	manifestmodel "github.com/tbd54566975/ssi-service/pkg/service/manifest/model"
 	manifeststg "github.com/tbd54566975/ssi-service/pkg/service/manifest/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/reindex"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/statusconsistency"
 	opstorage "github.com/tbd54566975/ssi-service/pkg/service/operation/storage"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation/model"
@@ -96,6 +99,18 @@ func ServiceModel(op opstorage.StoredOperation) (*Operation, error) {
 				return nil, errors.Wrap(err, "unmarshalling cred response")
 			}
 			newOp.Result.Response = manifestmodel.ServiceModel(&s)
+		case strings.HasPrefix(op.ID, reindex.ParentResource):
+			var r credsvc.ReindexResult
+			if err := json.Unmarshal(op.Response, &r); err != nil {
+				return nil, errors.Wrap(err, "unmarshalling reindex response")
+			}
+			newOp.Result.Response = r
+		case strings.HasPrefix(op.ID, statusconsistency.ParentResource):
+			var r credsvc.StatusConsistencyResult
+			if err := json.Unmarshal(op.Response, &r); err != nil {
+				return nil, errors.Wrap(err, "unmarshalling status consistency response")
+			}
+			newOp.Result.Response = r
 		default:
 			return nil, errors.New("unknown response type")
 		}
@@ -0,0 +1,13 @@
+package statusconsistency
+
+import "fmt"
+
+const (
+	// ParentResource is the prefix of the status consistency check parent resource.
+	ParentResource = "admin/status-consistency-checks"
+)
+
+// IDFromCheckID returns an operation ID from the ID of a status consistency check.
+func IDFromCheckID(id string) string {
+	return fmt.Sprintf("%s/%s", ParentResource, id)
+}
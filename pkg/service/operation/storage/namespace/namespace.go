@@ -4,12 +4,16 @@ import (
 	"strings"
 
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/credential"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/reindex"
+	"github.com/tbd54566975/ssi-service/pkg/service/operation/statusconsistency"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation/submission"
 )
 
 const (
 	namespace                   = "operation_submission"
 	credentialResponseNamespace = "operation_credential_response"
+	reindexNamespace            = "operation_reindex"
+	statusConsistencyNamespace  = "operation_status_consistency"
 )
 
 // FromID returns a namespace from a given operation ID. An empty string is returned when the namespace cannot
@@ -30,6 +34,10 @@ func FromParent(parent string) string {
 		return namespace
 	case credential.ParentResource:
 		return credentialResponseNamespace
+	case reindex.ParentResource:
+		return reindexNamespace
+	case statusconsistency.ParentResource:
+		return statusConsistencyNamespace
 	default:
 		return ""
 	}
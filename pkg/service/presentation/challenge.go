@@ -0,0 +1,72 @@
+package presentation
+
+import (
+	"context"
+	"time"
+
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	prestorage "github.com/tbd54566975/ssi-service/pkg/service/presentation/storage"
+)
+
+const defaultChallengeSweepInterval = time.Minute
+
+type CreateChallengeResponse struct {
+	Challenge string `json:"challenge"`
+	// ExpiresAt is empty when challenges never expire.
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// CreateChallenge creates a challenge for holders to answer with the `nonce` of their presentations. It can be used to
+// verify a batch of presentations once, before it expires.
+func (s Service) CreateChallenge(ctx context.Context) (*CreateChallengeResponse, error) {
+	challenge := prestorage.StoredChallenge{Challenge: uuid.NewString()}
+	if s.challengeTTL > 0 {
+		challenge.ExpiresAt = time.Now().Add(s.challengeTTL).UTC().Format(time.RFC3339)
+	}
+	if err := s.storage.StoreChallenge(ctx, challenge); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store challenge")
+	}
+	return &CreateChallengeResponse{Challenge: challenge.Challenge, ExpiresAt: challenge.ExpiresAt}, nil
+}
+
+// ChallengeTTL returns how long created challenges can be used, or 0 when they never expire.
+func (s Service) ChallengeTTL() time.Duration {
+	return s.challengeTTL
+}
+
+// RunChallengeSweeper purges expired challenges every sweep interval, until the context is done. It returns
+// immediately when challenges never expire.
+func (s Service) RunChallengeSweeper(ctx context.Context) {
+	if s.challengeTTL == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.challengeSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeExpiredChallenges(ctx); err != nil {
+				logrus.WithError(err).Error("purging expired challenges")
+			}
+		}
+	}
+}
+
+// PurgeExpiredChallenges deletes the challenges which have expired, used or not, returning how many were deleted.
+// Deleted challenges can't be replayed, since only challenges created by the service are accepted once they expire.
+func (s Service) PurgeExpiredChallenges(ctx context.Context) (int, error) {
+	purged, err := s.storage.DeleteExpiredChallenges(ctx, time.Now())
+	if err != nil {
+		return purged, errors.Wrap(err, "deleting expired challenges")
+	}
+	if purged > 0 {
+		logrus.Debugf("purged %d expired challenges", purged)
+	}
+	return purged, nil
+}
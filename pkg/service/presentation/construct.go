@@ -0,0 +1,103 @@
+package presentation
+
+import (
+	"context"
+	"time"
+
+	credsdk "github.com/TBD54566975/ssi-sdk/credential"
+	"github.com/TBD54566975/ssi-sdk/credential/integrity"
+	"github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+)
+
+// defaultPresentationValidity is how long a constructed presentation is valid for when the request has no expiration.
+const defaultPresentationValidity = 5 * time.Minute
+
+type ConstructPresentationRequest struct {
+	// CredentialIDs are the IDs of the credentials stored in the service to present. Each of them must be stored as a
+	// JWT.
+	CredentialIDs []string `json:"credentialIds" validate:"required,min=1"`
+
+	// HolderDID is the DID presenting the credentials, and VerificationMethodID the verification method of its DID
+	// document whose key, stored in the service, signs the presentation.
+	HolderDID            string `json:"holderDid" validate:"required"`
+	VerificationMethodID string `json:"verificationMethodId" validate:"required"`
+
+	// Audience is the verifier the presentation is addressed to, and Nonce the nonce it gave the holder.
+	Audience string `json:"audience" validate:"required"`
+	Nonce    string `json:"nonce" validate:"required"`
+
+	// Expiration defaults to defaultPresentationValidity after the presentation is constructed.
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+type ConstructPresentationResponse struct {
+	PresentationJWT keyaccess.JWT `json:"presentationJwt"`
+}
+
+// ConstructPresentation builds and signs a presentation of credentials stored in the service following the JWT VC
+// Presentation Profile: the holder is the `iss` of the JWT, which is addressed to the verifier with `aud`, answers its
+// `nonce`, is valid from `nbf` until `exp`, and embeds the credentials as compact JWTs in `vp.verifiableCredential`.
+func (s Service) ConstructPresentation(ctx context.Context, request ConstructPresentationRequest) (*ConstructPresentationResponse, error) {
+	logrus.Debugf("constructing presentation: %+v", request)
+
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid construct presentation request")
+	}
+
+	now := time.Now()
+	expiration := now.Add(defaultPresentationValidity)
+	if request.Expiration != nil {
+		if !request.Expiration.After(now) {
+			return nil, sdkutil.LoggingNewErrorf("expiration<%s> is not in the future", request.Expiration.Format(time.RFC3339))
+		}
+		expiration = *request.Expiration
+	}
+
+	credentialJWTs := make([]any, 0, len(request.CredentialIDs))
+	for _, id := range request.CredentialIDs {
+		gotCred, err := s.credentialStorage.GetCredentialIfExists(ctx, id)
+		if err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "getting credential<%s>", id)
+		}
+		if gotCred == nil {
+			return nil, sdkutil.LoggingNewErrorf("credential<%s> not found", id)
+		}
+		if gotCred.CredentialJWT == nil {
+			return nil, sdkutil.LoggingNewErrorf("credential<%s> is not stored as a JWT", id)
+		}
+		credentialJWTs = append(credentialJWTs, gotCred.CredentialJWT.String())
+	}
+
+	vp := credsdk.VerifiablePresentation{
+		Context:              []any{credsdk.VerifiableCredentialsLinkedDataContext},
+		Type:                 []string{credsdk.VerifiablePresentationType},
+		VerifiableCredential: credentialJWTs,
+	}
+	token, err := jwt.NewBuilder().
+		Issuer(request.HolderDID).
+		Audience([]string{request.Audience}).
+		IssuedAt(now).
+		NotBefore(now).
+		Expiration(expiration).
+		JwtID("urn:uuid:"+uuid.NewString()).
+		Claim(integrity.NonceProperty, request.Nonce).
+		Claim(integrity.VPJWTProperty, vp).
+		Build()
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "building presentation jwt")
+	}
+
+	keyStoreID := did.FullyQualifiedVerificationMethodID(request.HolderDID, request.VerificationMethodID)
+	presentationJWT, err := s.keystore.Sign(ctx, keyStoreID, token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "signing presentation with KID %q", request.VerificationMethodID)
+	}
+	return &ConstructPresentationResponse{PresentationJWT: *presentationJWT}, nil
+}
@@ -77,6 +77,9 @@ type Submission struct {
 	Reason string `json:"reason,omitempty"`
 	// The verifiable presentation containing the presentation_submission along with the credentials presented.
 	VerifiablePresentation *credsdk.VerifiablePresentation `json:"verifiablePresentation,omitempty"`
+	// Findings about the submission which did not reject it, such as a credential submitted in several formats, which
+	// counts once against the submission requirements of the definition.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 func (r Submission) GetSubmission() *exchange.PresentationSubmission {
@@ -121,6 +124,7 @@ func ServiceModel(storedSubmission *storage.StoredSubmission) Submission {
 		Status:                 storedSubmission.Status.String(),
 		Reason:                 storedSubmission.Reason,
 		VerifiablePresentation: &storedSubmission.VerifiablePresentation,
+		Warnings:               storedSubmission.Warnings,
 	}
 }
 
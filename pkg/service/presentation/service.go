@@ -3,6 +3,7 @@ package presentation
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	"github.com/TBD54566975/ssi-sdk/credential/integrity"
@@ -12,10 +13,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/tbd54566975/ssi-service/config"
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	didint "github.com/tbd54566975/ssi-service/internal/did"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/internal/verification"
+	"github.com/tbd54566975/ssi-service/pkg/service/analytics"
 	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation"
@@ -37,6 +42,18 @@ type Service struct {
 	schema     *schema.Service
 	verifier   *verification.Verifier
 	reqStorage common.RequestStorage
+	// credentialStorage holds the credentials presentations are constructed from
+	credentialStorage *credential.Storage
+
+	statusChecker *credential.StatusChecker
+	// verificationFailed is nil when failed verifications are not notified
+	verificationFailed verification.FailureFunc
+	// analytics is nil when analytics are disabled
+	analytics *analytics.Emitter
+
+	// challengeTTL is 0 when challenges never expire
+	challengeTTL           time.Duration
+	challengeSweepInterval time.Duration
 }
 
 func (s Service) Type() framework.Type {
@@ -57,7 +74,7 @@ func (s Service) Status() framework.Status {
 	return framework.Status{Status: framework.StatusReady}
 }
 
-func NewPresentationService(s storage.ServiceStorage,
+func NewPresentationService(config config.PresentationServiceConfig, s storage.ServiceStorage,
 	resolver resolution.Resolver, schema *schema.Service, keystore *keystore.Service) (*Service, error) {
 	presentationStorage, err := NewPresentationStorage(s)
 	if err != nil {
@@ -71,15 +88,39 @@ func NewPresentationService(s storage.ServiceStorage,
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate verifier")
 	}
+	statusChecker, err := credential.NewStatusChecker(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate credential status checker")
+	}
+	credentialStorage, err := credential.NewCredentialStorage(s)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate credential storage for the presentation service")
+	}
 	requestStorage := common.NewRequestStorage(s, presentationRequestNamespace)
+	var challengeTTL time.Duration
+	if config.ChallengeTTL != "" {
+		if challengeTTL, err = time.ParseDuration(config.ChallengeTTL); err != nil || challengeTTL <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid challenge ttl: %s", config.ChallengeTTL)
+		}
+	}
+	challengeSweepInterval := defaultChallengeSweepInterval
+	if config.ChallengeSweepInterval != "" {
+		if challengeSweepInterval, err = time.ParseDuration(config.ChallengeSweepInterval); err != nil || challengeSweepInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid challenge sweep interval: %s", config.ChallengeSweepInterval)
+		}
+	}
 	service := Service{
-		storage:    presentationStorage,
-		keystore:   keystore,
-		opsStorage: opsStorage,
-		resolver:   resolver,
-		schema:     schema,
-		verifier:   verifier,
-		reqStorage: requestStorage,
+		storage:                presentationStorage,
+		keystore:               keystore,
+		opsStorage:             opsStorage,
+		resolver:               resolver,
+		schema:                 schema,
+		verifier:               verifier,
+		reqStorage:             requestStorage,
+		credentialStorage:      credentialStorage,
+		statusChecker:          statusChecker,
+		challengeTTL:           challengeTTL,
+		challengeSweepInterval: challengeSweepInterval,
 	}
 	if !service.Status().IsReady() {
 		return nil, errors.New(service.Status().Message)
@@ -89,11 +130,29 @@ func NewPresentationService(s storage.ServiceStorage,
 
 type VerifyPresentationRequest struct {
 	PresentationJWT *keyaccess.JWT `json:"presentationJwt,omitempty" validate:"required"`
+
+	// Expectations on the credentials submitted in the presentation, keyed by the ID of the input descriptor they
+	// were submitted for.
+	ExpectedCredentials map[string]verification.CredentialExpectations `json:"expectedCredentials,omitempty"`
+
+	// Audience, when set, is the verifier the presentation must be addressed to, as presentations following the JWT
+	// VC Presentation Profile are. Otherwise, the audience of the presentation, when it has one, must be its holder.
+	Audience string `json:"audience,omitempty"`
+	// Nonce, when set, is the nonce the presentation must carry.
+	Nonce string `json:"nonce,omitempty"`
 }
 
 type VerifyPresentationResponse struct {
 	Verified bool   `json:"verified"`
 	Reason   string `json:"reason,omitempty"`
+	// Set to verification.SchemaMismatch or verification.TypeMismatch when a submitted credential is valid but does
+	// not satisfy the expected schema or types, and to verification.Revoked or verification.Suspended when the status
+	// of a submitted credential is set in a status list stored by the service. Set to verification.InvalidCredential
+	// when a submitted credential fails verification.
+	ReasonCode string `json:"reasonCode,omitempty"`
+	// CredentialResults are the results of verifying each credential in the presentation, whatever its format, once
+	// the signature of the presentation is verified.
+	CredentialResults []verification.CredentialResult `json:"credentialResults,omitempty"`
 }
 
 // VerifyPresentation does a series of verification on a presentation:
@@ -104,20 +163,213 @@ type VerifyPresentationResponse struct {
 //     a. Makes sure the verification has a valid signature
 //     b. Makes sure the verification is not expired
 //     c. Makes sure the verification complies with the VC Data Model
+//     d. If the verification uses a revocation or suspension status list stored by the service, makes sure its
+//     status is not set
+//  5. For each input descriptor with expectations, makes sure the credential submitted for it has the expected
+//     schema and types
+//  6. When the request has an audience or a nonce, makes sure the presentation is addressed to the audience and
+//     carries the nonce
+//
+// Failed verifications are notified to the function set with OnVerificationFailed, if any. The analytics event of each
+// verification is emitted once it completes, without an issuer or schema, which a presentation has several of.
 func (s Service) VerifyPresentation(ctx context.Context, request VerifyPresentationRequest) (*VerifyPresentationResponse, error) {
+	start := time.Now()
+	response, err := s.verifyAndNotify(ctx, request)
+	var verified bool
+	var reasonCode string
+	if response != nil {
+		verified, reasonCode = response.Verified, response.ReasonCode
+	}
+	outcome := analytics.VerificationOutcome(verified, reasonCode, verification.InvalidPresentation, err)
+	s.analytics.Emit(analytics.PresentationVerification, "", "", outcome, time.Since(start))
+	return response, err
+}
+
+func (s Service) verifyAndNotify(ctx context.Context, request VerifyPresentationRequest) (*VerifyPresentationResponse, error) {
 	logrus.Debugf("verifying presentation: %+v", request)
 
 	if err := sdkutil.IsValidStruct(request); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "invalid verify presentation request")
 	}
 
-	if err := s.verifier.VerifyJWTPresentation(ctx, *request.PresentationJWT); err != nil {
+	response, err := s.verifyPresentation(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if !response.Verified {
+		s.verificationFailed.Notify(presentationFailure(*request.PresentationJWT, response.ReasonCode))
+	}
+	return response, nil
+}
+
+func (s Service) verifyPresentation(ctx context.Context, request VerifyPresentationRequest) (*VerifyPresentationResponse, error) {
+	credentialResults, err := s.verifier.VerifyJWTPresentationCredentials(ctx, *request.PresentationJWT, request.Audience)
+	if err != nil {
 		return &VerifyPresentationResponse{Verified: false, Reason: err.Error()}, nil
 	}
+	if request.Nonce != "" {
+		if reason := checkPresentationNonce(*request.PresentationJWT, request.Nonce); reason != "" {
+			return &VerifyPresentationResponse{Verified: false, Reason: reason}, nil
+		}
+	}
+	response, err := s.checkVerifiedPresentation(ctx, request, credentialResults)
+	if err != nil {
+		return nil, err
+	}
+	response.CredentialResults = credentialResults
+	return response, nil
+}
+
+// checkVerifiedPresentation checks the credentials of a presentation whose signature is verified: that each of them
+// was verified, that their status is not set, and that they satisfy the expectations of the request.
+func (s Service) checkVerifiedPresentation(ctx context.Context, request VerifyPresentationRequest, credentialResults []verification.CredentialResult) (*VerifyPresentationResponse, error) {
+	if err := verification.CredentialResultsError(credentialResults); err != nil {
+		return &VerifyPresentationResponse{Verified: false, Reason: err.Error(), ReasonCode: verification.InvalidCredential}, nil
+	}
+
+	_, _, pres, err := integrity.ParseVerifiablePresentationFromJWT(request.PresentationJWT.String())
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "parsing verified presentation")
+	}
+	creds, err := credint.NewCredentialContainerFromArray(pres.VerifiableCredential)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "parsing credentials of verified presentation")
+	}
+	for _, cred := range creds {
+		if cred.Credential == nil {
+			continue
+		}
+		if err = s.statusChecker.CheckStatus(ctx, *cred.Credential); err != nil {
+			var statusErr verification.StatusError
+			if errors.As(err, &statusErr) {
+				return &VerifyPresentationResponse{Verified: false, Reason: statusErr.Error(), ReasonCode: statusErr.Reason}, nil
+			}
+			return nil, sdkutil.LoggingErrorMsg(err, "checking status of presented credentials")
+		}
+	}
+
+	if len(request.ExpectedCredentials) > 0 {
+		if err = verification.CheckPresentationExpectations(*pres, request.ExpectedCredentials); err != nil {
+			resp := VerifyPresentationResponse{Verified: false, Reason: err.Error()}
+			var expectationErr verification.ExpectationError
+			if errors.As(err, &expectationErr) {
+				resp.ReasonCode = expectationErr.Reason
+			}
+			return &resp, nil
+		}
+	}
 
 	return &VerifyPresentationResponse{Verified: true}, nil
 }
 
+// presentationFailure describes a failed presentation verification with the holder the presentation claims to be made
+// by, and the hashes of the credentials in it, when it can be parsed.
+func presentationFailure(presentationJWT keyaccess.JWT, reasonCode string) verification.Failure {
+	failure := verification.Failure{ReasonCode: reasonCode}
+	if failure.ReasonCode == "" {
+		failure.ReasonCode = verification.InvalidPresentation
+	}
+	_, token, pres, err := integrity.ParseVerifiablePresentationFromJWT(presentationJWT.String())
+	if err != nil {
+		return failure
+	}
+	failure.Presenter = pres.Holder
+	if failure.Presenter == "" {
+		failure.Presenter = token.Issuer()
+	}
+	for _, cred := range pres.VerifiableCredential {
+		failure.CredentialHashes = append(failure.CredentialHashes, verification.HashCredential(cred))
+	}
+	return failure
+}
+
+// OnVerificationFailed sets the function notified of each failed presentation verification. It must be set before the
+// service verifies presentations.
+func (s *Service) OnVerificationFailed(verificationFailed verification.FailureFunc) {
+	s.verificationFailed = verificationFailed
+}
+
+// SetAnalytics sets the emitter of the analytics events about the presentations the service verifies. It must be set
+// before the service verifies presentations.
+func (s *Service) SetAnalytics(emitter *analytics.Emitter) {
+	s.analytics = emitter
+}
+
+// SetOfflineVerification makes verification fail closed with verification.ErrOffline on any check which needs network
+// access, resolving DIDs with the given resolver, which must not make outbound calls. It must be set before the
+// service verifies presentations.
+func (s *Service) SetOfflineVerification(resolver resolution.Resolver) {
+	s.resolver = resolver
+	s.verifier = s.verifier.Offline(resolver)
+}
+
+type BatchVerifyPresentationsRequest struct {
+	// The challenge shared by all presentations in the batch. Each presentation must carry it as its `nonce` claim.
+	Challenge string `json:"challenge" validate:"required"`
+
+	PresentationJWTs []keyaccess.JWT `json:"presentationJwts" validate:"required,min=1"`
+}
+
+type BatchVerifyPresentationsResponse struct {
+	// The verification results, in the same order as the presentations in the request.
+	Results []VerifyPresentationResponse `json:"results"`
+}
+
+// BatchVerifyPresentations verifies each presentation as VerifyPresentation does, and additionally makes sure it was
+// created in response to the given challenge. The challenge is consumed once for the whole batch, so that it can't
+// be replayed in another request. Presentations repeated within the batch fail verification.
+func (s Service) BatchVerifyPresentations(ctx context.Context, request BatchVerifyPresentationsRequest) (*BatchVerifyPresentationsResponse, error) {
+	logrus.Debugf("verifying batch of %d presentations", len(request.PresentationJWTs))
+
+	if err := sdkutil.IsValidStruct(request); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "invalid batch verify presentations request")
+	}
+
+	// once challenges expire, only challenges created by the service are accepted, so that expiry can't be bypassed
+	if err := s.storage.ConsumeChallenge(ctx, request.Challenge, s.challengeTTL > 0); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "consuming challenge<%s>", request.Challenge)
+	}
+
+	results := make([]VerifyPresentationResponse, 0, len(request.PresentationJWTs))
+	seen := make(map[keyaccess.JWT]bool, len(request.PresentationJWTs))
+	for i := range request.PresentationJWTs {
+		presentationJWT := request.PresentationJWTs[i]
+		if seen[presentationJWT] {
+			results = append(results, VerifyPresentationResponse{Verified: false, Reason: "presentation was already submitted in this batch"})
+			continue
+		}
+		seen[presentationJWT] = true
+
+		result, err := s.VerifyPresentation(ctx, VerifyPresentationRequest{PresentationJWT: &presentationJWT})
+		if err != nil {
+			return nil, errors.Wrapf(err, "verifying presentation %d", i)
+		}
+		if result.Verified {
+			if reason := checkPresentationNonce(presentationJWT, request.Challenge); reason != "" {
+				result = &VerifyPresentationResponse{Verified: false, Reason: reason}
+			}
+		}
+		results = append(results, *result)
+	}
+	return &BatchVerifyPresentationsResponse{Results: results}, nil
+}
+
+// checkPresentationNonce returns the reason why the presentation does not answer the challenge, if any.
+func checkPresentationNonce(presentationJWT keyaccess.JWT, challenge string) string {
+	_, token, _, err := integrity.ParseVerifiablePresentationFromJWT(presentationJWT.String())
+	if err != nil {
+		return err.Error()
+	}
+	nonce, ok := token.Get(integrity.NonceProperty)
+	if !ok {
+		return "presentation does not have a nonce"
+	}
+	if nonce != challenge {
+		return fmt.Sprintf("presentation nonce<%v> does not match the challenge", nonce)
+	}
+	return ""
+}
+
 // CreatePresentationDefinition houses the main service logic for presentation definition creation. It validates the input, and
 // produces a presentation definition value that conforms with the PresentationDefinition specification.
 func (s Service) CreatePresentationDefinition(ctx context.Context,
@@ -250,9 +502,20 @@ func (s Service) CreateSubmission(ctx context.Context, request model.CreateSubmi
 		return nil, errors.Wrap(err, "verifying presentation submission vp")
 	}
 
+	// copies of a credential in several formats count once against the submission requirements
+	vpJSON, err := sdkutil.ToJSONMap(request.Presentation)
+	if err != nil {
+		return nil, errors.Wrap(err, "turning presentation into json")
+	}
+	warnings, err := verification.CheckDistinctSubmission(storedDefinition.PresentationDefinition, request.Submission, vpJSON, request.Presentation.VerifiableCredential)
+	if err != nil {
+		return nil, errors.Wrap(err, "checking distinct credentials of presentation submission")
+	}
+
 	storedSubmission := presentationstorage.StoredSubmission{
 		Status:                 submission.StatusPending,
 		VerifiablePresentation: request.Presentation,
+		Warnings:               warnings,
 	}
 
 	// TODO(andres): IO requests should be done in parallel, once we have context wired up.
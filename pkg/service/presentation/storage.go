@@ -3,6 +3,7 @@ package presentation
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
@@ -21,6 +22,7 @@ import (
 
 const (
 	presentationDefinitionNamespace = "presentation_definition"
+	presentationChallengeNamespace  = "presentation_challenge"
 )
 
 type Storage struct {
@@ -189,3 +191,66 @@ func (ps *Storage) ListDefinitions(ctx context.Context) ([]prestorage.StoredDefi
 	}
 	return ts, nil
 }
+
+func (ps *Storage) StoreChallenge(ctx context.Context, challenge prestorage.StoredChallenge) error {
+	jsonBytes, err := json.Marshal(challenge)
+	if err != nil {
+		return errors.Wrap(err, "marshalling challenge")
+	}
+	return ps.db.Write(ctx, presentationChallengeNamespace, challenge.Challenge, jsonBytes)
+}
+
+func (ps *Storage) ConsumeChallenge(ctx context.Context, challenge string, requireStored bool) error {
+	watchKeys := []storage.WatchKey{{Namespace: presentationChallengeNamespace, Key: challenge}}
+	_, err := ps.db.Execute(ctx, func(ctx context.Context, tx storage.Tx) (any, error) {
+		storedBytes, err := ps.db.Read(ctx, presentationChallengeNamespace, challenge)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading challenge")
+		}
+		stored := prestorage.StoredChallenge{Challenge: challenge}
+		if len(storedBytes) == 0 {
+			if requireStored {
+				return nil, prestorage.ErrChallengeNotFound
+			}
+		} else if err = json.Unmarshal(storedBytes, &stored); err != nil {
+			return nil, errors.Wrap(err, "unmarshalling challenge")
+		}
+
+		now := time.Now().UTC()
+		if stored.ConsumedAt != "" {
+			return nil, prestorage.ErrChallengeConsumed
+		}
+		if stored.IsExpired(now) {
+			return nil, prestorage.ErrChallengeExpired
+		}
+		stored.ConsumedAt = now.Format(time.RFC3339)
+		jsonBytes, err := json.Marshal(stored)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling challenge")
+		}
+		return nil, tx.Write(ctx, presentationChallengeNamespace, challenge, jsonBytes)
+	}, watchKeys)
+	return err
+}
+
+func (ps *Storage) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int, error) {
+	challenges, err := ps.db.ReadAll(ctx, presentationChallengeNamespace)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading all challenges")
+	}
+	deleted := 0
+	for key, value := range challenges {
+		var stored prestorage.StoredChallenge
+		if err = json.Unmarshal(value, &stored); err != nil {
+			return deleted, errors.Wrapf(err, "unmarshalling challenge with key <%s>", key)
+		}
+		if !stored.IsExpired(now) {
+			continue
+		}
+		if err = ps.db.Delete(ctx, presentationChallengeNamespace, key); err != nil {
+			return deleted, errors.Wrapf(err, "deleting challenge with key <%s>", key)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
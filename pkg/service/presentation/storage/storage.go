@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/TBD54566975/ssi-sdk/credential"
 	"github.com/TBD54566975/ssi-sdk/credential/exchange"
@@ -20,6 +21,7 @@ type StoredDefinition struct {
 type Storage interface {
 	DefinitionStorage
 	SubmissionStorage
+	ChallengeStorage
 }
 
 type DefinitionStorage interface {
@@ -33,6 +35,8 @@ type StoredSubmission struct {
 	Status                 submission.Status                 `json:"status"`
 	Reason                 string                            `json:"reason"`
 	VerifiablePresentation credential.VerifiablePresentation `json:"vp"`
+	// Warnings are findings about the submission which did not reject it, such as credentials submitted more than once.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type StoredSubmissions struct {
@@ -54,3 +58,39 @@ type SubmissionStorage interface {
 }
 
 var ErrSubmissionNotFound = errors.New("submission not found")
+
+// StoredChallenge records a challenge created by the service, or which presentations were verified against, so that
+// it can't be replayed.
+type StoredChallenge struct {
+	Challenge  string `json:"challenge"`
+	ConsumedAt string `json:"consumedAt,omitempty"`
+	// ExpiresAt is empty for challenges which never expire.
+	ExpiresAt string `json:"expiresAt,omitempty"`
+}
+
+// IsExpired reports whether the challenge expired before the given time.
+func (sc StoredChallenge) IsExpired(now time.Time) bool {
+	if sc.ExpiresAt == "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, sc.ExpiresAt)
+	return err == nil && !now.Before(expiresAt)
+}
+
+type ChallengeStorage interface {
+	// StoreChallenge records a challenge created by the service, which can then be consumed until it expires.
+	StoreChallenge(ctx context.Context, challenge StoredChallenge) error
+	// ConsumeChallenge records the challenge as used. It fails with ErrChallengeConsumed when the challenge was
+	// already used, and with ErrChallengeExpired when it expired. Challenges not created by the service fail with
+	// ErrChallengeNotFound when requireStored is set.
+	ConsumeChallenge(ctx context.Context, challenge string, requireStored bool) error
+	// DeleteExpiredChallenges deletes the challenges which expired before the given time, returning how many were
+	// deleted.
+	DeleteExpiredChallenges(ctx context.Context, now time.Time) (int, error)
+}
+
+var (
+	ErrChallengeConsumed = errors.New("challenge has already been used")
+	ErrChallengeExpired  = errors.New("challenge expired")
+	ErrChallengeNotFound = errors.New("challenge was not created by the service")
+)
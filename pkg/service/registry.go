@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+)
+
+// Component is a part of the SSIService, such as a service or a background worker, which is started once all the
+// components it depends on are started, and stopped before any of them is stopped.
+type Component struct {
+	// Name identifies the component within the registry, and is used by other components to depend on it.
+	Name string
+
+	// DependsOn lists the names of the components which must be started before this one.
+	DependsOn []string
+
+	// Start builds the component and assigns it to the SSIService, reading its dependencies from it.
+	Start func(s *SSIService) error
+
+	// Stop releases what the component holds, such as background workers or connections. Optional.
+	Stop func(ctx context.Context, s *SSIService) error
+
+	// Service returns the service built by the component, so its status is included in readiness checks. Optional.
+	Service func(s *SSIService) framework.Service
+}
+
+// Registry starts components in the order of their dependencies, and stops them in the reverse order.
+type Registry struct {
+	components map[string]Component
+	// registered keeps the registration order, which is used to break ties so the start order is deterministic.
+	registered []string
+	started    []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{components: make(map[string]Component)}
+}
+
+// Register adds a component to the registry. Component names must be unique.
+func (r *Registry) Register(components ...Component) error {
+	for _, c := range components {
+		if c.Name == "" {
+			return errors.New("component must have a name")
+		}
+		if c.Start == nil {
+			return fmt.Errorf("component<%s> must have a start function", c.Name)
+		}
+		if _, ok := r.components[c.Name]; ok {
+			return fmt.Errorf("component<%s> is already registered", c.Name)
+		}
+		r.components[c.Name] = c
+		r.registered = append(r.registered, c.Name)
+	}
+	return nil
+}
+
+// Order resolves the order in which components are started, such that every component comes after all the
+// components it depends on. It is an error for a dependency to be missing, or for dependencies to form a cycle.
+func (r *Registry) Order() ([]string, error) {
+	dependents := make(map[string][]string)
+	remaining := make(map[string]int)
+	for _, name := range r.registered {
+		deps := r.components[name].DependsOn
+		remaining[name] = len(deps)
+		for _, dep := range deps {
+			if _, ok := r.components[dep]; !ok {
+				return nil, fmt.Errorf("component<%s> depends on unregistered component<%s>", name, dep)
+			}
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	position := make(map[string]int, len(r.registered))
+	for i, name := range r.registered {
+		position[name] = i
+	}
+	var ready []string
+	for _, name := range r.registered {
+		if remaining[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	order := make([]string, 0, len(r.registered))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return position[ready[i]] < position[ready[j]] })
+		next := ready[0]
+		ready = ready[1:]
+		order = append(order, next)
+		for _, dependent := range dependents[next] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(r.registered) {
+		var cyclic []string
+		for _, name := range r.registered {
+			if remaining[name] > 0 {
+				cyclic = append(cyclic, name)
+			}
+		}
+		return nil, fmt.Errorf("components have cyclic dependencies: %s", strings.Join(cyclic, ", "))
+	}
+	return order, nil
+}
+
+// Start starts all components in the order of their dependencies. When a component fails to start, the components
+// which already started are stopped.
+func (r *Registry) Start(s *SSIService) error {
+	order, err := r.Order()
+	if err != nil {
+		return errors.Wrap(err, "resolving component order")
+	}
+	for _, name := range order {
+		if err = r.components[name].Start(s); err != nil {
+			if stopErr := r.Stop(context.Background(), s); stopErr != nil {
+				logrus.WithError(stopErr).Warn("stopping components after failing to start")
+			}
+			return errors.Wrapf(err, "starting component<%s>", name)
+		}
+		logrus.Debugf("started component<%s>", name)
+		r.started = append(r.started, name)
+	}
+	return nil
+}
+
+// Stop stops the started components in the reverse order they were started, so no component is stopped while another
+// one depending on it is running. All components are stopped even when some fail to, and the first error is returned.
+func (r *Registry) Stop(ctx context.Context, s *SSIService) error {
+	var firstErr error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		name := r.started[i]
+		stop := r.components[name].Stop
+		if stop == nil {
+			continue
+		}
+		if err := stop(ctx, s); err != nil {
+			logrus.WithError(err).Warnf("stopping component<%s>", name)
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "stopping component<%s>", name)
+			}
+			continue
+		}
+		logrus.Debugf("stopped component<%s>", name)
+	}
+	r.started = nil
+	return firstErr
+}
+
+// Services returns the services of the started components, in the order they were started.
+func (r *Registry) Services(s *SSIService) []framework.Service {
+	var services []framework.Service
+	for _, name := range r.started {
+		if service := r.components[name].Service; service != nil {
+			services = append(services, service(s))
+		}
+	}
+	return services
+}
+
+// BackgroundWorker returns a component which runs the worker until it is stopped. Stopping it cancels the context of
+// the worker and waits for it to return, so it is never running once the components it depends on are stopped. When
+// leader election is enabled, the worker only runs while this replica is the leader.
+func BackgroundWorker(name string, dependsOn []string, run func(ctx context.Context, s *SSIService)) Component {
+	var cancel context.CancelFunc
+	var done chan struct{}
+	return Component{
+		Name:      name,
+		DependsOn: append([]string{LeaderElectionComponent}, dependsOn...),
+		Start: func(s *SSIService) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				if s.Leader == nil {
+					run(ctx, s)
+					return
+				}
+				s.Leader.RunWhileLeading(ctx, func(ctx context.Context) { run(ctx, s) })
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context, _ *SSIService) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "waiting for worker<%s> to return", name)
+			}
+		},
+	}
+}
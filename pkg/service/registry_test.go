@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryOrder(t *testing.T) {
+	component := func(name string, dependsOn ...string) Component {
+		return Component{Name: name, DependsOn: dependsOn, Start: func(_ *SSIService) error { return nil }}
+	}
+
+	t.Run("dependencies come first, ties keep registration order", func(tt *testing.T) {
+		registry := NewRegistry()
+		require.NoError(tt, registry.Register(
+			component("credential", "schema", "keystore"),
+			component("schema", "did"),
+			component("webhook", "storage"),
+			component("did", "keystore"),
+			component("keystore", "storage"),
+			component("storage"),
+		))
+
+		order, err := registry.Order()
+		require.NoError(tt, err)
+		assert.Equal(tt, []string{"storage", "webhook", "keystore", "did", "schema", "credential"}, order)
+	})
+
+	t.Run("duplicate names are rejected", func(tt *testing.T) {
+		registry := NewRegistry()
+		require.NoError(tt, registry.Register(component("storage")))
+		err := registry.Register(component("storage"))
+		assert.ErrorContains(tt, err, "component<storage> is already registered")
+	})
+
+	t.Run("missing dependencies are rejected", func(tt *testing.T) {
+		registry := NewRegistry()
+		require.NoError(tt, registry.Register(component("did", "keystore")))
+		_, err := registry.Order()
+		assert.ErrorContains(tt, err, "component<did> depends on unregistered component<keystore>")
+	})
+
+	t.Run("cycles are rejected", func(tt *testing.T) {
+		registry := NewRegistry()
+		require.NoError(tt, registry.Register(
+			component("storage"),
+			component("a", "storage", "b"),
+			component("b", "a"),
+		))
+		_, err := registry.Order()
+		assert.ErrorContains(tt, err, "components have cyclic dependencies: a, b")
+	})
+}
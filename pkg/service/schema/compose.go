@@ -0,0 +1,234 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/service/framework"
+)
+
+const (
+	allOfProperty                = "allOf"
+	refProperty                  = "$ref"
+	typeProperty                 = "type"
+	constProperty                = "const"
+	propertiesProperty           = "properties"
+	requiredProperty             = "required"
+	additionalPropertiesProperty = "additionalProperties"
+)
+
+// composeSchema resolves each member of the schema's allOf which references a schema stored by this service,
+// replacing the reference with the contents of the referenced schema. Referenced schemas are composed recursively.
+// References to schemas outside the service are left untouched. The given schema is not modified.
+func (s Service) composeSchema(ctx context.Context, jsonSchema schema.JSONSchema, visited map[string]bool) (schema.JSONSchema, error) {
+	allOf, ok := jsonSchema[allOfProperty].([]any)
+	if !ok {
+		return jsonSchema, nil
+	}
+
+	composedAllOf := make([]any, 0, len(allOf))
+	for _, member := range allOf {
+		memberMap, ok := member.(map[string]any)
+		if !ok {
+			composedAllOf = append(composedAllOf, member)
+			continue
+		}
+		ref, ok := memberMap[refProperty].(string)
+		if !ok || len(memberMap) != 1 {
+			composedAllOf = append(composedAllOf, member)
+			continue
+		}
+		id, ok := StoredSchemaID(ref)
+		if !ok {
+			composedAllOf = append(composedAllOf, member)
+			continue
+		}
+		if visited[id] {
+			return nil, fmt.Errorf("schema<%s> is referenced in a cycle", id)
+		}
+
+		referenced, _, err := s.resolveStoredSchema(ctx, id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving referenced schema<%s>", ref)
+		}
+		visited[id] = true
+		composedReferenced, err := s.composeSchema(ctx, *referenced, visited)
+		delete(visited, id)
+		if err != nil {
+			return nil, err
+		}
+		composedAllOf = append(composedAllOf, map[string]any(withoutIdentifiers(composedReferenced)))
+	}
+
+	composed := make(schema.JSONSchema, len(jsonSchema))
+	for k, v := range jsonSchema {
+		composed[k] = v
+	}
+	composed[allOfProperty] = composedAllOf
+	return composed, nil
+}
+
+// StoredSchemaID returns the ID of the schema stored by this service that the given reference points to, if any.
+// References can either be the schema's ID, or its fully qualified URI.
+func StoredSchemaID(ref string) (string, bool) {
+	servicePath := config.GetServicePath(framework.Schema) + "/"
+	if strings.HasPrefix(ref, servicePath) {
+		return strings.TrimPrefix(ref, servicePath), true
+	}
+	if _, err := uuid.Parse(ref); err == nil {
+		return ref, true
+	}
+	return "", false
+}
+
+// withoutIdentifiers removes the properties which identify a schema, so that it can be embedded in another one
+func withoutIdentifiers(jsonSchema schema.JSONSchema) schema.JSONSchema {
+	embedded := make(schema.JSONSchema, len(jsonSchema))
+	for k, v := range jsonSchema {
+		switch k {
+		case schema.JSONSchemaIDProperty, schema.JSONSchemaSchemaProperty, schema.JSONSchemaNameProperty, schema.JSONSchemaDescriptionProperty:
+			continue
+		}
+		embedded[k] = v
+	}
+	return embedded
+}
+
+// checkComposedConstraints returns an error describing every pair of constraints in a composed schema that cannot
+// both be satisfied, such as two members of the allOf declaring a different type for the same property.
+func checkComposedConstraints(composed schema.JSONSchema) error {
+	members := flattenAllOf(composed)
+	var conflicts []string
+	for i := 0; i < len(members); i++ {
+		for j := i + 1; j < len(members); j++ {
+			conflicts = append(conflicts, findConflicts("", members[i], members[j])...)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("composed schema has conflicting constraints: %s", strings.Join(conflicts, "; "))
+}
+
+// flattenAllOf returns the schema itself, without its allOf, along with every member of the allOf, recursively
+func flattenAllOf(jsonSchema map[string]any) []map[string]any {
+	root := make(map[string]any, len(jsonSchema))
+	for k, v := range jsonSchema {
+		if k != allOfProperty {
+			root[k] = v
+		}
+	}
+	members := []map[string]any{root}
+	allOf, _ := jsonSchema[allOfProperty].([]any)
+	for _, member := range allOf {
+		if memberMap, ok := member.(map[string]any); ok {
+			members = append(members, flattenAllOf(memberMap)...)
+		}
+	}
+	return members
+}
+
+func findConflicts(path string, a, b map[string]any) []string {
+	location := path
+	if location == "" {
+		location = "root"
+	}
+
+	var conflicts []string
+	aTypes, bTypes := schemaTypes(a), schemaTypes(b)
+	if len(aTypes) > 0 && len(bTypes) > 0 && !typesIntersect(aTypes, bTypes) {
+		conflicts = append(conflicts, fmt.Sprintf("%s: type %v conflicts with type %v", location, aTypes, bTypes))
+	}
+
+	aConst, aHasConst := a[constProperty]
+	bConst, bHasConst := b[constProperty]
+	if aHasConst && bHasConst && !reflect.DeepEqual(aConst, bConst) {
+		conflicts = append(conflicts, fmt.Sprintf("%s: const %v conflicts with const %v", location, aConst, bConst))
+	}
+
+	conflicts = append(conflicts, findDisallowedRequired(location, a, b)...)
+	conflicts = append(conflicts, findDisallowedRequired(location, b, a)...)
+
+	aProperties, _ := a[propertiesProperty].(map[string]any)
+	bProperties, _ := b[propertiesProperty].(map[string]any)
+	for _, name := range sortedKeys(aProperties) {
+		aProperty, aOK := aProperties[name].(map[string]any)
+		bProperty, bOK := bProperties[name].(map[string]any)
+		if !aOK || !bOK {
+			continue
+		}
+		propertyPath := name
+		if path != "" {
+			propertyPath = path + "." + name
+		}
+		conflicts = append(conflicts, findConflicts(propertyPath, aProperty, bProperty)...)
+	}
+	return conflicts
+}
+
+// findDisallowedRequired reports properties required by b which closed cannot contain, since it does not allow
+// additional properties
+func findDisallowedRequired(location string, closed, b map[string]any) []string {
+	if additional, ok := closed[additionalPropertiesProperty].(bool); !ok || additional {
+		return nil
+	}
+	properties, _ := closed[propertiesProperty].(map[string]any)
+	required, _ := b[requiredProperty].([]any)
+	var conflicts []string
+	for _, r := range required {
+		name, ok := r.(string)
+		if !ok {
+			continue
+		}
+		if _, ok = properties[name]; !ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s: required property %q is not allowed by a schema without additional properties", location, name))
+		}
+	}
+	return conflicts
+}
+
+func schemaTypes(s map[string]any) []string {
+	switch t := s[typeProperty].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if typeString, ok := v.(string); ok {
+				types = append(types, typeString)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+func typesIntersect(a, b []string) bool {
+	for _, aType := range a {
+		for _, bType := range b {
+			// every integer is also a number
+			if aType == bType || (aType == "integer" && bType == "number") || (aType == "number" && bType == "integer") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,11 +1,17 @@
 package schema
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/TBD54566975/ssi-sdk/credential/schema"
 	"github.com/TBD54566975/ssi-sdk/util"
 	"github.com/tbd54566975/ssi-service/pkg/server/pagination"
 	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"go.einride.tech/aip/filtering"
 
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 )
 
@@ -17,6 +23,89 @@ type CreateSchemaRequest struct {
 	// If both are present the schema will be signed by the issuer's private key with the specified KID
 	Issuer                             string `json:"issuer,omitempty"`
 	FullyQualifiedVerificationMethodID string `json:"fullyQualifiedVerificationMethodId,omitempty"`
+
+	// StatusPolicy is optional. If present, it is applied to credentials created against the schema.
+	StatusPolicy *StatusPolicy `json:"statusPolicy,omitempty"`
+
+	// UniquenessPolicy is optional. If present, a subject may hold at most one active credential of the schema from
+	// each issuer.
+	UniquenessPolicy *UniquenessPolicy `json:"uniquenessPolicy,omitempty"`
+
+	// RenderMethod is optional. If present, it is attached to credentials created against the schema.
+	RenderMethod *credint.RenderMethod `json:"renderMethod,omitempty"`
+
+	// ExpectedType is optional. If present, credentials created against the schema must have this type.
+	ExpectedType string `json:"expectedType,omitempty"`
+
+	// ValidityDuration is optional. If present, such as "24h", credentials created against the schema without an
+	// expiry expire this long after they are issued.
+	ValidityDuration string `json:"validityDuration,omitempty"`
+
+	// Revocable is optional, and only for credential schemas. If set, the schema credential has a revocation status,
+	// and revoking it stops the creation of credentials against the schema.
+	Revocable bool `json:"revocable,omitempty"`
+
+	// CreatedBy is the principal, such as the name of an API key, on whose behalf the schema is created.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+type StatusPolicyType string
+
+const (
+	RevocableStatusPolicy   StatusPolicyType = "revocable"
+	SuspendableStatusPolicy StatusPolicyType = "suspendable"
+	NoStatusPolicy          StatusPolicyType = "none"
+)
+
+// StatusPolicy is the status credentials created against a schema have when the request for the credential asks for
+// neither a revocable nor a suspendable credential.
+type StatusPolicy struct {
+	Default StatusPolicyType `json:"default" validate:"required,oneof=revocable suspendable none"`
+	// When Mandatory is set, requests for a credential with a status other than Default are rejected.
+	Mandatory bool `json:"mandatory,omitempty"`
+}
+
+// UniquenessPolicy limits each subject to one active, which is neither revoked nor expired, credential of a schema
+// per issuer.
+type UniquenessPolicy struct {
+	// When ReplaceExisting is set, the active credential is revoked and replaced by a newly created one, instead of
+	// the creation being rejected.
+	ReplaceExisting bool `json:"replaceExisting,omitempty"`
+}
+
+// ClaimConflictPolicy is what happens when a request for a credential has a claim which a schema injects.
+type ClaimConflictPolicy string
+
+const (
+	// RejectClaimConflict rejects the request, unless its claim has the injected value.
+	RejectClaimConflict ClaimConflictPolicy = "reject"
+	// ClientWinsClaimConflict keeps the claim of the request.
+	ClientWinsClaimConflict ClaimConflictPolicy = "client-wins"
+	// ServerWinsClaimConflict replaces the claim of the request with the injected value.
+	ServerWinsClaimConflict ClaimConflictPolicy = "server-wins"
+)
+
+// ClaimInjection holds fixed claims the service adds to the credential subject of credentials created against a
+// schema, before they are validated against it, so that clients need not include them.
+type ClaimInjection struct {
+	// Claims are the claims of the credential subject to inject, such as `jurisdiction`.
+	Claims map[string]any `json:"claims"`
+	// ConflictPolicy is what happens when a request has an injected claim, which is rejecting it when unset.
+	ConflictPolicy ClaimConflictPolicy `json:"conflictPolicy,omitempty" validate:"omitempty,oneof=reject client-wins server-wins"`
+}
+
+// IsValid makes sure there are injected claims, which are claims of the credential subject other than its ID.
+func (ci ClaimInjection) IsValid() error {
+	if err := util.IsValidStruct(ci); err != nil {
+		return err
+	}
+	if len(ci.Claims) == 0 {
+		return fmt.Errorf("claim injection has no claims")
+	}
+	if _, ok := ci.Claims["id"]; ok {
+		return fmt.Errorf("the id of the credential subject cannot be injected")
+	}
+	return nil
 }
 
 // IsCredentialSchemaRequest returns true if the request is for a credential schema
@@ -28,6 +117,19 @@ func (csr CreateSchemaRequest) IsValid() error {
 	if err := util.IsValidStruct(csr); err != nil {
 		return err
 	}
+	if csr.RenderMethod != nil {
+		if err := csr.RenderMethod.IsValid(); err != nil {
+			return err
+		}
+	}
+	if csr.ValidityDuration != "" {
+		if _, err := ParseValidityDuration(csr.ValidityDuration); err != nil {
+			return err
+		}
+	}
+	if csr.Revocable && !csr.IsCredentialSchemaRequest() {
+		return fmt.Errorf("only credential schemas can be revocable")
+	}
 	if csr.FullyQualifiedVerificationMethodID != "" && csr.Issuer != "" {
 		return common.ValidateVerificationMethodID(csr.FullyQualifiedVerificationMethodID, csr.Issuer)
 	}
@@ -39,9 +141,18 @@ type CreateSchemaResponse struct {
 	Type             schema.VCJSONSchemaType `json:"type"`
 	Schema           *schema.JSONSchema      `json:"schema,omitempty"`
 	CredentialSchema *keyaccess.JWT          `json:"credentialSchema,omitempty"`
+	StatusPolicy     *StatusPolicy           `json:"statusPolicy,omitempty"`
+	UniquenessPolicy *UniquenessPolicy       `json:"uniquenessPolicy,omitempty"`
+	RenderMethod     *credint.RenderMethod   `json:"renderMethod,omitempty"`
+	ExpectedType     string                  `json:"expectedType,omitempty"`
+	ValidityDuration string                  `json:"validityDuration,omitempty"`
+	CreatedBy        string                  `json:"createdBy,omitempty"`
+	Version          int                     `json:"version"`
 }
 
 type ListSchemasRequest struct {
+	// Filter is an AIP-160 filter on the principal which created the schemas, as `createdBy`.
+	Filter      filtering.Filter
 	PageRequest *pagination.PageRequest
 }
 
@@ -59,6 +170,96 @@ type GetSchemaResponse struct {
 	Type             schema.VCJSONSchemaType `json:"type"`
 	Schema           *schema.JSONSchema      `json:"schema,omitempty"`
 	CredentialSchema *keyaccess.JWT          `json:"credentialSchema,omitempty"`
+	StatusPolicy     *StatusPolicy           `json:"statusPolicy,omitempty"`
+	UniquenessPolicy *UniquenessPolicy       `json:"uniquenessPolicy,omitempty"`
+	RenderMethod     *credint.RenderMethod   `json:"renderMethod,omitempty"`
+	ExpectedClaims   []string                `json:"expectedClaims,omitempty"`
+	ExpectedType     string                  `json:"expectedType,omitempty"`
+	// SubjectCredentialQuota overrides the quota of credentials each subject may hold from an issuer, when set.
+	SubjectCredentialQuota *int `json:"subjectCredentialQuota,omitempty"`
+	// ValidityDuration is how long after they are issued credentials created without an expiry expire, when set.
+	ValidityDuration string `json:"validityDuration,omitempty"`
+	// ClaimInjection holds the claims injected into credentials created against the schema, when set.
+	ClaimInjection *ClaimInjection `json:"claimInjection,omitempty"`
+	// CreatedBy is the principal which created the schema, when known.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// Version of the JSON schema, which starts at 1.
+	Version int `json:"version"`
+}
+
+// UpdateSchemaRequest updates the service-level settings of a schema, or replaces its JSON schema with a new version.
+// Fields left unset are unchanged.
+type UpdateSchemaRequest struct {
+	ID string `json:"id" validate:"required"`
+
+	// Schema replaces the JSON schema with a new version. Earlier versions are kept, since credentials created against
+	// them are validated against the version they were created against. Credential schemas, which are signed, cannot
+	// be replaced.
+	Schema *schema.JSONSchema `json:"schema,omitempty"`
+
+	// ExpectedClaims replaces the expected claims of the schema. An empty list removes them.
+	ExpectedClaims *[]string `json:"expectedClaims,omitempty"`
+
+	// ExpectedType replaces the expected type of the schema. An empty string removes it.
+	ExpectedType *string `json:"expectedType,omitempty"`
+
+	// SubjectCredentialQuota overrides the quota of credentials each subject may hold from an issuer when creating
+	// credentials against the schema. 0 lifts the quota, and a negative value removes the override.
+	SubjectCredentialQuota *int `json:"subjectCredentialQuota,omitempty"`
+
+	// ValidityDuration replaces how long after they are issued credentials created without an expiry against the
+	// schema expire. An empty string removes it.
+	ValidityDuration *string `json:"validityDuration,omitempty"`
+
+	// ClaimInjection replaces the claims injected into credentials created against the schema. Injecting no claims
+	// removes it.
+	ClaimInjection *ClaimInjection `json:"claimInjection,omitempty"`
+}
+
+func (usr UpdateSchemaRequest) IsValid() error {
+	if err := util.IsValidStruct(usr); err != nil {
+		return err
+	}
+	if usr.ValidityDuration != nil && *usr.ValidityDuration != "" {
+		if _, err := ParseValidityDuration(*usr.ValidityDuration); err != nil {
+			return err
+		}
+	}
+	if usr.ClaimInjection != nil && len(usr.ClaimInjection.Claims) > 0 {
+		if err := usr.ClaimInjection.IsValid(); err != nil {
+			return err
+		}
+	}
+	if usr.ExpectedClaims != nil {
+		return validateExpectedClaims(*usr.ExpectedClaims)
+	}
+	return nil
+}
+
+// ParseValidityDuration parses the validity duration of a schema, such as "24h", which must be positive.
+func ParseValidityDuration(validityDuration string) (time.Duration, error) {
+	validity, err := time.ParseDuration(validityDuration)
+	if err != nil || validity <= 0 {
+		return 0, fmt.Errorf("invalid validity duration<%s>, which must be a positive duration such as 24h", validityDuration)
+	}
+	return validity, nil
+}
+
+// validateExpectedClaims makes sure each expected claim is a path to a claim of the credential subject, such as
+// `email` or `address.city`.
+func validateExpectedClaims(claims []string) error {
+	for _, claim := range claims {
+		for _, segment := range strings.Split(claim, ".") {
+			if segment == "" {
+				return fmt.Errorf("expected claim<%s> is not a valid claim path", claim)
+			}
+		}
+	}
+	return nil
+}
+
+type UpdateSchemaResponse struct {
+	GetSchemaResponse
 }
 
 type DeleteSchemaRequest struct {
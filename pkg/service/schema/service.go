@@ -30,8 +30,9 @@ type Service struct {
 	storage *Storage
 
 	// external dependencies
-	keyStore *keystore.Service
-	resolver resolution.Resolver
+	keyStore          *keystore.Service
+	resolver          resolution.Resolver
+	schemaCredentials SchemaCredentialIssuer
 }
 
 func (s Service) Type() framework.Type {
@@ -75,6 +76,19 @@ func NewSchemaService(s storage.ServiceStorage, keyStore *keystore.Service,
 	return &service, nil
 }
 
+// SchemaCredentialIssuer issues the schema credentials of revocable credential schemas.
+type SchemaCredentialIssuer interface {
+	// IssueRevocableSchemaCredential adds a revocation status entry to the schema credential, signs it with the key of
+	// the verification method, and stores it as the credential with the given ID, so that it can be revoked.
+	IssueRevocableSchemaCredential(ctx context.Context, id string, cred credential.VerifiableCredential, fullyQualifiedVerificationMethodID string) (*keyaccess.JWT, error)
+}
+
+// SetSchemaCredentialIssuer sets the issuer of the schema credentials of revocable credential schemas. It must be set
+// before the service creates revocable credential schemas.
+func (s *Service) SetSchemaCredentialIssuer(issuer SchemaCredentialIssuer) {
+	s.schemaCredentials = issuer
+}
+
 // CreateSchema houses the main service logic for schema creation. It validates the input, and
 // produces a schema value that conforms with the VC JSON Schema specification.
 func (s Service) CreateSchema(ctx context.Context, request CreateSchemaRequest) (*CreateSchemaResponse, error) {
@@ -84,17 +98,10 @@ func (s Service) CreateSchema(ctx context.Context, request CreateSchemaRequest)
 		return nil, sdkutil.LoggingErrorMsgf(err, "validating schema request: %+v", request)
 	}
 
-	// validate the schema
+	// validate the schema, along with any stored schemas it is composed of
 	jsonSchema := request.Schema
-	schemaBytes, err := json.Marshal(jsonSchema)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not marshal schema in request")
-	}
-	if err = schemalib.IsValidJSONSchema(string(schemaBytes)); err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "provided value is not a valid JSON schema")
-	}
-	if !schema.IsSupportedJSONSchemaVersion(jsonSchema.Schema()) {
-		return nil, sdkutil.LoggingNewErrorf("unsupported schema version: %s", jsonSchema.Schema())
+	if err := s.validateJSONSchema(ctx, jsonSchema, make(map[string]bool)); err != nil {
+		return nil, err
 	}
 	if jsonSchema.ID() != "" {
 		logrus.Infof("schema has id: %s, which is being overwritten", jsonSchema.ID())
@@ -118,10 +125,19 @@ func (s Service) CreateSchema(ctx context.Context, request CreateSchemaRequest)
 	schemaURI := strings.Join([]string{config.GetServicePath(framework.Schema), schemaID}, "/")
 
 	// create schema for storage
-	storedSchema := StoredSchema{ID: schemaID}
+	storedSchema := StoredSchema{
+		ID:               schemaID,
+		StatusPolicy:     request.StatusPolicy,
+		UniquenessPolicy: request.UniquenessPolicy,
+		RenderMethod:     request.RenderMethod,
+		ExpectedType:     request.ExpectedType,
+		ValidityDuration: request.ValidityDuration,
+		CreatedBy:        request.CreatedBy,
+		Version:          1,
+	}
 	if request.IsCredentialSchemaRequest() {
 		jsonSchema[schema.JSONSchemaIDProperty] = schemaID
-		credSchema, err := s.createCredentialSchema(ctx, jsonSchema, schemaURI, request.Issuer, request.FullyQualifiedVerificationMethodID)
+		credSchema, err := s.createCredentialSchema(ctx, schemaID, jsonSchema, schemaURI, request)
 		if err != nil {
 			return nil, sdkutil.LoggingErrorMsg(err, "could not create credential schema")
 		}
@@ -133,7 +149,7 @@ func (s Service) CreateSchema(ctx context.Context, request CreateSchemaRequest)
 		storedSchema.Schema = &jsonSchema
 	}
 	// store schema
-	if err = s.storage.StoreSchema(ctx, storedSchema); err != nil {
+	if err := s.storage.StoreSchema(ctx, storedSchema); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not store schema")
 	}
 
@@ -142,11 +158,43 @@ func (s Service) CreateSchema(ctx context.Context, request CreateSchemaRequest)
 		Type:             storedSchema.Type,
 		Schema:           storedSchema.Schema,
 		CredentialSchema: storedSchema.CredentialSchema,
+		StatusPolicy:     storedSchema.StatusPolicy,
+		UniquenessPolicy: storedSchema.UniquenessPolicy,
+		RenderMethod:     storedSchema.RenderMethod,
+		ExpectedType:     storedSchema.ExpectedType,
+		ValidityDuration: storedSchema.ValidityDuration,
+		CreatedBy:        storedSchema.CreatedBy,
+		Version:          storedSchema.Version,
 	}, nil
 }
 
-// createCredentialSchema creates a credential schema, and signs it with the issuer's key and kid
-func (s Service) createCredentialSchema(ctx context.Context, jsonSchema schema.JSONSchema, schemaURI, issuer, fullyQualifiedVerificationMethodID string) (*keyaccess.JWT, error) {
+// validateJSONSchema makes sure a JSON schema is valid, along with any stored schemas it is composed of. Schemas in
+// visited can't be composed, so that a schema doesn't reference itself.
+func (s Service) validateJSONSchema(ctx context.Context, jsonSchema schema.JSONSchema, visited map[string]bool) error {
+	composedSchema, err := s.composeSchema(ctx, jsonSchema, visited)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not compose schema")
+	}
+	schemaBytes, err := json.Marshal(composedSchema)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not marshal schema in request")
+	}
+	if err = schemalib.IsValidJSONSchema(string(schemaBytes)); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "provided value is not a valid JSON schema")
+	}
+	if err = checkComposedConstraints(composedSchema); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "provided schema cannot be composed")
+	}
+	if !schema.IsSupportedJSONSchemaVersion(jsonSchema.Schema()) {
+		return sdkutil.LoggingNewErrorf("unsupported schema version: %s", jsonSchema.Schema())
+	}
+	return nil
+}
+
+// createCredentialSchema creates a credential schema, and signs it with the issuer's key and kid. A revocable credential
+// schema is issued by the schema credential issuer, as the credential with the ID of the schema.
+func (s Service) createCredentialSchema(ctx context.Context, schemaID string, jsonSchema schema.JSONSchema, schemaURI string, request CreateSchemaRequest) (*keyaccess.JWT, error) {
+	issuer := request.Issuer
 	builder := credential.NewVerifiableCredentialBuilder()
 	if err := builder.SetID(schemaURI); err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "building credential when setting id: %s", schemaURI)
@@ -168,7 +216,13 @@ func (s Service) createCredentialSchema(ctx context.Context, jsonSchema schema.J
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not build credential schema")
 	}
-	return s.signCredentialSchema(ctx, *cred, issuer, fullyQualifiedVerificationMethodID)
+	if request.Revocable {
+		if s.schemaCredentials == nil {
+			return nil, sdkutil.LoggingNewError("no schema credential issuer configured for revocable credential schemas")
+		}
+		return s.schemaCredentials.IssueRevocableSchemaCredential(ctx, schemaID, *cred, request.FullyQualifiedVerificationMethodID)
+	}
+	return s.signCredentialSchema(ctx, *cred, issuer, request.FullyQualifiedVerificationMethodID)
 }
 
 // signCredentialSchema signs a credential schema with the issuer's key and kid as a  VC JWT
@@ -198,17 +252,27 @@ func (s Service) signCredentialSchema(ctx context.Context, cred credential.Verif
 func (s Service) ListSchemas(ctx context.Context, request ListSchemasRequest) (*ListSchemasResponse, error) {
 	logrus.Debug("listing all schemas")
 
-	storedSchemas, err := s.storage.ListSchemas(ctx, *request.PageRequest.ToServicePage())
+	storedSchemas, err := s.storage.ListSchemas(ctx, request.Filter, *request.PageRequest.ToServicePage())
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "error getting schemas")
 	}
 	schemas := make([]GetSchemaResponse, 0, len(storedSchemas.Schemas))
 	for _, stored := range storedSchemas.Schemas {
 		schemas = append(schemas, GetSchemaResponse{
-			ID:               stored.ID,
-			Type:             stored.Type,
-			Schema:           stored.Schema,
-			CredentialSchema: stored.CredentialSchema,
+			ID:                     stored.ID,
+			Type:                   stored.Type,
+			Schema:                 stored.Schema,
+			CredentialSchema:       stored.CredentialSchema,
+			StatusPolicy:           stored.StatusPolicy,
+			UniquenessPolicy:       stored.UniquenessPolicy,
+			RenderMethod:           stored.RenderMethod,
+			ExpectedClaims:         stored.ExpectedClaims,
+			ExpectedType:           stored.ExpectedType,
+			SubjectCredentialQuota: stored.SubjectCredentialQuota,
+			ValidityDuration:       stored.ValidityDuration,
+			ClaimInjection:         stored.ClaimInjection,
+			CreatedBy:              stored.CreatedBy,
+			Version:                stored.CurrentVersion(),
 		})
 	}
 
@@ -227,13 +291,118 @@ func (s Service) GetSchema(ctx context.Context, request GetSchemaRequest) (*GetS
 		return nil, sdkutil.LoggingNewErrorf("schema with id<%s> could not be found", request.ID)
 	}
 	return &GetSchemaResponse{
-		ID:               gotSchema.ID,
-		Type:             gotSchema.Type,
-		Schema:           gotSchema.Schema,
-		CredentialSchema: gotSchema.CredentialSchema,
+		ID:                     gotSchema.ID,
+		Type:                   gotSchema.Type,
+		Schema:                 gotSchema.Schema,
+		CredentialSchema:       gotSchema.CredentialSchema,
+		StatusPolicy:           gotSchema.StatusPolicy,
+		UniquenessPolicy:       gotSchema.UniquenessPolicy,
+		RenderMethod:           gotSchema.RenderMethod,
+		ExpectedClaims:         gotSchema.ExpectedClaims,
+		ExpectedType:           gotSchema.ExpectedType,
+		SubjectCredentialQuota: gotSchema.SubjectCredentialQuota,
+		ValidityDuration:       gotSchema.ValidityDuration,
+		ClaimInjection:         gotSchema.ClaimInjection,
+		CreatedBy:              gotSchema.CreatedBy,
+		Version:                gotSchema.CurrentVersion(),
 	}, nil
 }
 
+// UpdateSchema updates the expected claims, subject credential quota, validity duration, and injected claims of a
+// schema, and replaces its JSON schema with a new version when one is given. Credentials already created against the
+// schema are unchanged.
+func (s Service) UpdateSchema(ctx context.Context, request UpdateSchemaRequest) (*UpdateSchemaResponse, error) {
+	logrus.Debugf("updating schema: %+v", request)
+
+	if err := request.IsValid(); err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "validating update schema request: %+v", request)
+	}
+
+	gotSchema, err := s.storage.GetSchema(ctx, request.ID)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsgf(err, "error getting schema: %s", request.ID)
+	}
+	if gotSchema == nil {
+		return nil, sdkutil.LoggingNewErrorf("schema with id<%s> could not be found", request.ID)
+	}
+	if request.ExpectedClaims != nil {
+		gotSchema.ExpectedClaims = *request.ExpectedClaims
+		if len(gotSchema.ExpectedClaims) == 0 {
+			gotSchema.ExpectedClaims = nil
+		}
+	}
+	if request.ExpectedType != nil {
+		gotSchema.ExpectedType = *request.ExpectedType
+	}
+	if quota := request.SubjectCredentialQuota; quota != nil {
+		gotSchema.SubjectCredentialQuota = quota
+		if *quota < 0 {
+			gotSchema.SubjectCredentialQuota = nil
+		}
+	}
+	if request.ValidityDuration != nil {
+		gotSchema.ValidityDuration = *request.ValidityDuration
+	}
+	if injection := request.ClaimInjection; injection != nil {
+		gotSchema.ClaimInjection = injection
+		if len(injection.Claims) == 0 {
+			gotSchema.ClaimInjection = nil
+		}
+	}
+	if request.Schema != nil {
+		if err = s.replaceJSONSchema(ctx, gotSchema, *request.Schema); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.storage.StoreSchema(ctx, *gotSchema); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not store schema")
+	}
+
+	return &UpdateSchemaResponse{
+		GetSchemaResponse: GetSchemaResponse{
+			ID:                     gotSchema.ID,
+			Type:                   gotSchema.Type,
+			Schema:                 gotSchema.Schema,
+			CredentialSchema:       gotSchema.CredentialSchema,
+			StatusPolicy:           gotSchema.StatusPolicy,
+			UniquenessPolicy:       gotSchema.UniquenessPolicy,
+			RenderMethod:           gotSchema.RenderMethod,
+			ExpectedClaims:         gotSchema.ExpectedClaims,
+			ExpectedType:           gotSchema.ExpectedType,
+			SubjectCredentialQuota: gotSchema.SubjectCredentialQuota,
+			ValidityDuration:       gotSchema.ValidityDuration,
+			ClaimInjection:         gotSchema.ClaimInjection,
+			CreatedBy:              gotSchema.CreatedBy,
+			Version:                gotSchema.CurrentVersion(),
+		},
+	}, nil
+}
+
+// replaceJSONSchema keeps the current version of a stored schema, and replaces its JSON schema with the next version.
+// The replacement keeps the ID of the schema, along with its name and description unless it has its own.
+func (s Service) replaceJSONSchema(ctx context.Context, stored *StoredSchema, replacement schema.JSONSchema) error {
+	if stored.Type != schema.JSONSchemaType || stored.Schema == nil {
+		return sdkutil.LoggingNewErrorf("schema<%s> of type %s cannot be replaced", stored.ID, stored.Type)
+	}
+	if err := s.validateJSONSchema(ctx, replacement, map[string]bool{stored.ID: true}); err != nil {
+		return err
+	}
+	current := *stored.Schema
+	for _, property := range []string{schema.JSONSchemaNameProperty, schema.JSONSchemaDescriptionProperty} {
+		if _, ok := replacement[property]; !ok && current[property] != nil {
+			replacement[property] = current[property]
+		}
+	}
+	replacement[schema.JSONSchemaIDProperty] = current[schema.JSONSchemaIDProperty]
+
+	if err := s.storage.StoreSchemaVersion(ctx, *stored); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "could not keep current version of schema")
+	}
+	stored.Version = stored.CurrentVersion() + 1
+	stored.Schema = &replacement
+	return nil
+}
+
 func (s Service) DeleteSchema(ctx context.Context, request DeleteSchemaRequest) error {
 	logrus.Debugf("deleting schema: %s", request.ID)
 
@@ -244,17 +413,52 @@ func (s Service) DeleteSchema(ctx context.Context, request DeleteSchemaRequest)
 	return nil
 }
 
-// Resolve wraps our get schema method for exposing schema access to other services
+// Resolve wraps our get schema method for exposing schema access to other services. Stored schemas referenced in
+// the schema's allOf are resolved and embedded, so the returned schema can be used for validation as-is.
 func (s Service) Resolve(ctx context.Context, id string) (*schema.JSONSchema, schema.VCJSONSchemaType, error) {
-	gotSchemaResponse, err := s.GetSchema(ctx, GetSchemaRequest{ID: id})
+	resolved, schemaType, _, err := s.ResolveVersion(ctx, id, 0)
+	return resolved, schemaType, err
+}
+
+// ResolveVersion is like Resolve, but resolves the given version of the schema, or its current version when 0, and
+// returns the version it resolved. Stored schemas referenced in the schema's allOf are resolved at their current version.
+func (s Service) ResolveVersion(ctx context.Context, id string, version int) (*schema.JSONSchema, schema.VCJSONSchemaType, int, error) {
+	stored, err := s.storage.GetSchema(ctx, id)
+	if err != nil {
+		return nil, "", 0, sdkutil.LoggingErrorMsg(err, "resolving schema")
+	}
+	if version != 0 && version != stored.CurrentVersion() {
+		if stored, err = s.storage.GetSchemaVersion(ctx, id, version); err != nil {
+			return nil, "", 0, sdkutil.LoggingErrorMsg(err, "resolving schema version")
+		}
+	}
+	resolved, schemaType, err := jsonSchemaOf(*stored)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	composed, err := s.composeSchema(ctx, *resolved, map[string]bool{id: true})
+	if err != nil {
+		return nil, "", 0, sdkutil.LoggingErrorMsgf(err, "composing schema<%s>", id)
+	}
+	return &composed, schemaType, stored.CurrentVersion(), nil
+}
+
+// resolveStoredSchema returns the JSON schema stored for the given id, as it was provided at creation
+func (s Service) resolveStoredSchema(ctx context.Context, id string) (*schema.JSONSchema, schema.VCJSONSchemaType, error) {
+	gotSchema, err := s.storage.GetSchema(ctx, id)
 	if err != nil {
 		return nil, "", sdkutil.LoggingErrorMsg(err, "resolving schema")
 	}
-	switch gotSchemaResponse.Type {
+	return jsonSchemaOf(*gotSchema)
+}
+
+// jsonSchemaOf returns the JSON schema of a stored schema, which is signed within a credential schema.
+func jsonSchemaOf(stored StoredSchema) (*schema.JSONSchema, schema.VCJSONSchemaType, error) {
+	switch stored.Type {
 	case schema.JSONSchemaType:
-		return gotSchemaResponse.Schema, schema.JSONSchemaType, nil
+		return stored.Schema, schema.JSONSchemaType, nil
 	case schema.JSONSchemaCredentialType:
-		_, _, cred, err := parsing.ToCredential(gotSchemaResponse.CredentialSchema.String())
+		_, _, cred, err := parsing.ToCredential(stored.CredentialSchema.String())
 		if err != nil {
 			return nil, "", sdkutil.LoggingErrorMsg(err, "converting credential schema from jwt to credential map")
 		}
@@ -272,6 +476,6 @@ func (s Service) Resolve(ctx context.Context, id string) (*schema.JSONSchema, sc
 		}
 		return &s, schema.JSONSchemaCredentialType, nil
 	default:
-		return nil, "", sdkutil.LoggingNewErrorf("unknown schema type: %s", gotSchemaResponse.Type)
+		return nil, "", sdkutil.LoggingNewErrorf("unknown schema type: %s", stored.Type)
 	}
 }
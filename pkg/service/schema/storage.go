@@ -2,6 +2,7 @@ package schema
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/TBD54566975/ssi-sdk/credential/schema"
 	"github.com/TBD54566975/ssi-sdk/util"
@@ -9,13 +10,17 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/tbd54566975/ssi-service/pkg/service/common"
+	"go.einride.tech/aip/filtering"
 
+	credint "github.com/tbd54566975/ssi-service/internal/credential"
 	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
 const (
 	namespace = "schema"
+	// versionNamespace holds the versions of schemas which were replaced by a newer version.
+	versionNamespace = "schema-version"
 )
 
 type StoredSchemas struct {
@@ -28,6 +33,40 @@ type StoredSchema struct {
 	Type             schema.VCJSONSchemaType `json:"type"`
 	Schema           *schema.JSONSchema      `json:"schema,omitempty"`
 	CredentialSchema *keyaccess.JWT          `json:"credentialSchema,omitempty"`
+	StatusPolicy     *StatusPolicy           `json:"statusPolicy,omitempty"`
+	UniquenessPolicy *UniquenessPolicy       `json:"uniquenessPolicy,omitempty"`
+	RenderMethod     *credint.RenderMethod   `json:"renderMethod,omitempty"`
+	// ExpectedClaims are claims of the credential subject which credentials of the schema should have, without the
+	// schema requiring them. Credentials missing them are issued with a warning.
+	ExpectedClaims []string `json:"expectedClaims,omitempty"`
+	// ExpectedType is a type credentials of the schema must have, which they are checked for when they are created
+	// and verified.
+	ExpectedType string `json:"expectedType,omitempty"`
+	// SubjectCredentialQuota overrides the quota of credentials each subject may hold from an issuer, when set.
+	SubjectCredentialQuota *int `json:"subjectCredentialQuota,omitempty"`
+	// ValidityDuration is how long after they are issued credentials created without an expiry expire, when set.
+	ValidityDuration string `json:"validityDuration,omitempty"`
+	// ClaimInjection holds the claims injected into credentials of the schema before they are validated, when set.
+	ClaimInjection *ClaimInjection `json:"claimInjection,omitempty"`
+	// CreatedBy is the principal which created the schema. Empty for schemas stored before creators were recorded.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// Version of the JSON schema, incremented each time it is replaced. 0 for schemas stored before schemas were
+	// versioned, which are at version 1.
+	Version int `json:"version,omitempty"`
+}
+
+func (ss StoredSchema) FilterVariablesMap() map[string]any {
+	return map[string]any{
+		"createdBy": ss.CreatedBy,
+	}
+}
+
+// CurrentVersion returns the version of the JSON schema of the stored schema.
+func (ss StoredSchema) CurrentVersion() int {
+	if ss.Version == 0 {
+		return 1
+	}
+	return ss.Version
 }
 
 type Storage struct {
@@ -69,13 +108,18 @@ func (s *Storage) GetSchema(ctx context.Context, id string) (*StoredSchema, erro
 }
 
 // ListSchemas attempts to get all stored schemas. It will return those it can even if it has trouble with some.
-func (s *Storage) ListSchemas(ctx context.Context, page common.Page) (*StoredSchemas, error) {
+func (s *Storage) ListSchemas(ctx context.Context, filter filtering.Filter, page common.Page) (*StoredSchemas, error) {
 	token, size := page.ToStorageArgs()
 	gotSchemas, nextPageToken, err := s.db.ReadPage(ctx, namespace, token, size)
 	if err != nil {
 		return nil, errors.Wrap(err, "reading page of schemas")
 	}
 
+	shouldInclude, err := storage.NewIncludeFunc(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	stored := make([]StoredSchema, 0, len(gotSchemas))
 	for _, schemaBytes := range gotSchemas {
 		var nextSchema StoredSchema
@@ -83,6 +127,11 @@ func (s *Storage) ListSchemas(ctx context.Context, page common.Page) (*StoredSch
 			logrus.WithError(err).Errorf("could not unmarshal stored schema: %s", string(schemaBytes))
 			continue
 		}
+		include, err := shouldInclude(nextSchema)
+		// evaluation errors are ignored, and the schema is included
+		if err == nil && !include {
+			continue
+		}
 		stored = append(stored, nextSchema)
 	}
 	return &StoredSchemas{
@@ -91,9 +140,55 @@ func (s *Storage) ListSchemas(ctx context.Context, page common.Page) (*StoredSch
 	}, nil
 }
 
+// StoreSchemaVersion keeps a version of a schema, before it is replaced by a newer version.
+func (s *Storage) StoreSchemaVersion(ctx context.Context, schema StoredSchema) error {
+	schema.Version = schema.CurrentVersion()
+	schemaBytes, err := json.Marshal(schema)
+	if err != nil {
+		return util.LoggingErrorMsgf(err, "could not store version %d of schema: %s", schema.Version, schema.ID)
+	}
+	return s.db.Write(ctx, versionNamespace, getSchemaVersionKey(schema.ID, schema.Version), schemaBytes)
+}
+
+// GetSchemaVersion returns a version of a schema which was replaced by a newer version.
+func (s *Storage) GetSchemaVersion(ctx context.Context, id string, version int) (*StoredSchema, error) {
+	schemaBytes, err := s.db.Read(ctx, versionNamespace, getSchemaVersionKey(id, version))
+	if err != nil {
+		return nil, util.LoggingErrorMsgf(err, "could not get version %d of schema: %s", version, id)
+	}
+	if len(schemaBytes) == 0 {
+		return nil, util.LoggingNewErrorf("version %d of schema<%s> not found", version, id)
+	}
+	var stored StoredSchema
+	if err = json.Unmarshal(schemaBytes, &stored); err != nil {
+		return nil, util.LoggingErrorMsgf(err, "could not unmarshal version %d of stored schema: %s", version, id)
+	}
+	return &stored, nil
+}
+
+// DeleteSchema deletes a schema, along with its replaced versions.
 func (s *Storage) DeleteSchema(ctx context.Context, id string) error {
-	if err := s.db.Delete(ctx, namespace, id); err != nil {
+	schemaBytes, err := s.db.Read(ctx, namespace, id)
+	if err != nil {
+		return util.LoggingErrorMsgf(err, "reading schema to delete: %s", id)
+	}
+	if len(schemaBytes) > 0 {
+		var stored StoredSchema
+		if err = json.Unmarshal(schemaBytes, &stored); err != nil {
+			return util.LoggingErrorMsgf(err, "could not unmarshal stored schema: %s", id)
+		}
+		for version := 1; version < stored.CurrentVersion(); version++ {
+			if err = s.db.Delete(ctx, versionNamespace, getSchemaVersionKey(id, version)); err != nil {
+				return util.LoggingErrorMsgf(err, "deleting version %d of schema: %s", version, id)
+			}
+		}
+	}
+	if err = s.db.Delete(ctx, namespace, id); err != nil {
 		return util.LoggingErrorMsgf(err, "deleting schema: %s", id)
 	}
 	return nil
 }
+
+func getSchemaVersionKey(id string, version int) string {
+	return storage.Join(id, strconv.Itoa(version))
+}
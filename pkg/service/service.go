@@ -1,17 +1,21 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/pkg/errors"
 
 	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/pkg/service/admin"
+	"github.com/tbd54566975/ssi-service/pkg/service/analytics"
 	"github.com/tbd54566975/ssi-service/pkg/service/credential"
 	"github.com/tbd54566975/ssi-service/pkg/service/did"
 	"github.com/tbd54566975/ssi-service/pkg/service/framework"
 	"github.com/tbd54566975/ssi-service/pkg/service/issuance"
 	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/leader"
 	"github.com/tbd54566975/ssi-service/pkg/service/manifest"
 	"github.com/tbd54566975/ssi-service/pkg/service/operation"
 	"github.com/tbd54566975/ssi-service/pkg/service/presentation"
@@ -32,22 +36,41 @@ type SSIService struct {
 	Presentation     *presentation.Service
 	Operation        *operation.Service
 	Webhook          *webhook.Service
-	storage          storage.ServiceStorage
 	BatchDID         *did.BatchService
 	DIDConfiguration *wellknown.DIDConfigurationService
+	IssuerMetadata   *wellknown.IssuerMetadataService
+	SSIConfiguration *wellknown.SSIConfigurationService
+	Admin            *admin.Service
+	// Leader is nil when leader election is disabled, in which case background workers run on every replica
+	Leader *leader.Elector
+	// Analytics is nil when analytics are disabled
+	Analytics *analytics.Emitter
+
+	registry           *Registry
+	storage            storage.ServiceStorage
+	unencryptedStorage storage.ServiceStorage
+	keyStoreFactory    keystore.ServiceFactory
 }
 
 // InstantiateSSIService creates a new instance of the SSIS which instantiates all services and their
-// dependencies independent of transport.
-func InstantiateSSIService(config config.ServicesConfig) (*SSIService, error) {
+// dependencies independent of transport. Additional components, such as background workers, are started along with
+// the services they depend on.
+func InstantiateSSIService(config config.ServicesConfig, components ...Component) (*SSIService, error) {
 	if err := validateServiceConfig(config); err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate SSI Service, invalid config")
 	}
-	service, err := instantiateServices(config)
-	if err != nil {
+	registry := NewRegistry()
+	if err := registry.Register(serviceComponents(config)...); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not register the ssi services")
+	}
+	if err := registry.Register(components...); err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not register the ssi service components")
+	}
+	service := SSIService{registry: registry}
+	if err := registry.Start(&service); err != nil {
 		return nil, sdkutil.LoggingErrorMsgf(err, "could not instantiate the ssi service")
 	}
-	return service, nil
+	return &service, nil
 }
 
 func validateServiceConfig(config config.ServicesConfig) error {
@@ -66,112 +89,285 @@ func validateServiceConfig(config config.ServicesConfig) error {
 	return nil
 }
 
-// instantiateServices begins all instantiates and their dependencies
-func instantiateServices(config config.ServicesConfig) (*SSIService, error) {
-	unencryptedStorageProvider, err := storage.NewStorage(storage.Type(config.StorageProvider), config.StorageOptions...)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsgf(err, "could not instantiate storage provider: %s", config.StorageProvider)
-	}
-
-	storageEncrypter, storageDecrypter, err := keystore.NewServiceEncryption(unencryptedStorageProvider, config.AppLevelEncryptionConfiguration, keystore.ServiceDataEncryptionKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating app level encrypter")
-	}
-	storageProvider := unencryptedStorageProvider
-	if storageEncrypter != nil && storageDecrypter != nil {
-		storageProvider = storage.NewEncryptedWrapper(unencryptedStorageProvider, storageEncrypter, storageDecrypter)
-	}
-
-	webhookService, err := webhook.NewWebhookService(config.WebhookConfig, storageProvider)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the webhook service")
-	}
-
-	keyEncrypter, keyDecrypter, err := keystore.NewServiceEncryption(unencryptedStorageProvider, config.KeyStoreConfig.EncryptionConfig, keystore.ServiceKeyEncryptionKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating keystore encrypter")
-	}
-	keyStoreServiceFactory := keystore.NewKeyStoreServiceFactory(config.KeyStoreConfig, storageProvider, keyEncrypter, keyDecrypter)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the keystore service factory")
-	}
-
-	keyStoreService, err := keyStoreServiceFactory(storageProvider)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate KeyStore service")
-	}
-
-	batchDIDService, err := did.NewBatchDIDService(config.DIDConfig, storageProvider, keyStoreServiceFactory)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate batch DID service")
-	}
-
-	didService, err := did.NewDIDService(config.DIDConfig, storageProvider, keyStoreService, keyStoreServiceFactory)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the DID service")
-	}
-	didResolver := didService.GetResolver()
-
-	schemaService, err := schema.NewSchemaService(storageProvider, keyStoreService, didResolver)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the schema service")
-	}
-
-	issuanceService, err := issuance.NewIssuanceService(storageProvider)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the issuance service")
-	}
-
-	credentialService, err := credential.NewCredentialService(config.CredentialConfig, storageProvider, keyStoreService, didResolver, schemaService)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the credential service")
-	}
+// Names of the components registered by serviceComponents.
+const (
+	StorageComponent          = "storage"
+	KeyStoreComponent         = string(framework.KeyStore)
+	DIDComponent              = string(framework.DID)
+	BatchDIDComponent         = "batch_did"
+	SchemaComponent           = string(framework.Schema)
+	IssuanceComponent         = string(framework.Issuance)
+	CredentialComponent       = string(framework.Credential)
+	PresentationComponent     = string(framework.Presentation)
+	ManifestComponent         = string(framework.Manifest)
+	OperationComponent        = string(framework.Operation)
+	WebhookComponent          = string(framework.Webhook)
+	AdminComponent            = string(framework.Admin)
+	DIDConfigurationComponent = string(framework.DIDConfiguration)
+	IssuerMetadataComponent   = string(framework.IssuerMetadata)
+	SSIConfigurationComponent = string(framework.SSIConfiguration)
+	LeaderElectionComponent   = "leader_election"
+	AnalyticsComponent        = "analytics"
+)
 
-	presentationService, err := presentation.NewPresentationService(storageProvider, didResolver, schemaService, keyStoreService)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the presentation service")
-	}
+// serviceComponents declares all services and what each of them depends on, so the registry can start them in order.
+func serviceComponents(config config.ServicesConfig) []Component {
+	return []Component{
+		{
+			Name: StorageComponent,
+			Start: func(s *SSIService) error {
+				unencryptedStorageProvider, err := storage.NewStorage(storage.Type(config.StorageProvider), config.StorageOptions...)
+				if err != nil {
+					return sdkutil.LoggingErrorMsgf(err, "could not instantiate storage provider: %s", config.StorageProvider)
+				}
+				s.unencryptedStorage = unencryptedStorageProvider
 
-	manifestService, err := manifest.NewManifestService(storageProvider, keyStoreService, didResolver, credentialService, presentationService)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the manifest service")
+				storageEncrypter, storageDecrypter, err := keystore.NewServiceEncryption(unencryptedStorageProvider, config.AppLevelEncryptionConfiguration, keystore.ServiceDataEncryptionKey)
+				if err != nil {
+					_ = unencryptedStorageProvider.Close()
+					return errors.Wrap(err, "creating app level encrypter")
+				}
+				s.storage = unencryptedStorageProvider
+				if storageEncrypter != nil && storageDecrypter != nil {
+					s.storage = storage.NewEncryptedWrapper(unencryptedStorageProvider, storageEncrypter, storageDecrypter)
+				}
+				return nil
+			},
+			Stop: func(_ context.Context, s *SSIService) error {
+				return s.unencryptedStorage.Close()
+			},
+		},
+		{
+			Name:      AnalyticsComponent,
+			DependsOn: []string{StorageComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Analytics, err = analytics.NewConfiguredEmitter(config.Analytics, s.storage)
+				return errors.Wrap(err, "could not instantiate the analytics emitter")
+			},
+		},
+		{
+			Name:      WebhookComponent,
+			DependsOn: []string{StorageComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Webhook, err = webhook.NewWebhookService(config.WebhookConfig, s.storage)
+				return errors.Wrap(err, "could not instantiate the webhook service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.Webhook },
+		},
+		{
+			Name:      KeyStoreComponent,
+			DependsOn: []string{StorageComponent},
+			Start: func(s *SSIService) error {
+				keyEncrypter, keyDecrypter, err := keystore.NewServiceEncryption(s.unencryptedStorage, config.KeyStoreConfig.EncryptionConfig, keystore.ServiceKeyEncryptionKey)
+				if err != nil {
+					return errors.Wrap(err, "creating keystore encrypter")
+				}
+				s.keyStoreFactory = keystore.NewKeyStoreServiceFactory(config.KeyStoreConfig, s.storage, keyEncrypter, keyDecrypter)
+				s.KeyStore, err = s.keyStoreFactory(s.storage)
+				return errors.Wrap(err, "could not instantiate KeyStore service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.KeyStore },
+		},
+		{
+			Name:      BatchDIDComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent},
+			Start: func(s *SSIService) (err error) {
+				s.BatchDID, err = did.NewBatchDIDService(config.DIDConfig, s.storage, s.keyStoreFactory)
+				return errors.Wrap(err, "could not instantiate batch DID service")
+			},
+		},
+		{
+			Name:      DIDComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent},
+			Start: func(s *SSIService) (err error) {
+				s.DID, err = did.NewDIDService(config.DIDConfig, s.storage, s.KeyStore, s.keyStoreFactory)
+				return errors.Wrap(err, "could not instantiate the DID service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.DID },
+		},
+		{
+			Name:      SchemaComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent, DIDComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Schema, err = schema.NewSchemaService(s.storage, s.KeyStore, s.DID.GetResolver())
+				return errors.Wrap(err, "could not instantiate the schema service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.Schema },
+		},
+		{
+			Name:      IssuanceComponent,
+			DependsOn: []string{StorageComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Issuance, err = issuance.NewIssuanceService(s.storage)
+				return errors.Wrap(err, "could not instantiate the issuance service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.Issuance },
+		},
+		{
+			Name:      CredentialComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent, DIDComponent, SchemaComponent, AnalyticsComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Credential, err = credential.NewCredentialService(config.CredentialConfig, s.storage, s.KeyStore, s.DID.GetResolver(), s.Schema)
+				if err != nil {
+					return errors.Wrap(err, "could not instantiate the credential service")
+				}
+				s.Credential.SetDisplayNames(s.DID)
+				s.Credential.SetDelegations(s.DID)
+				s.Schema.SetSchemaCredentialIssuer(s.Credential)
+				s.Credential.SetAnalytics(s.Analytics)
+				if config.OfflineVerification {
+					offlineResolver, err := s.DID.GetOfflineResolver()
+					if err != nil {
+						return err
+					}
+					s.Credential.SetOfflineVerification(offlineResolver)
+				}
+				return nil
+			},
+			Service: func(s *SSIService) framework.Service { return s.Credential },
+		},
+		{
+			Name:      PresentationComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent, DIDComponent, SchemaComponent, AnalyticsComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Presentation, err = presentation.NewPresentationService(config.PresentationConfig, s.storage, s.DID.GetResolver(), s.Schema, s.KeyStore)
+				if err != nil {
+					return errors.Wrap(err, "could not instantiate the presentation service")
+				}
+				s.Presentation.SetAnalytics(s.Analytics)
+				if config.OfflineVerification {
+					offlineResolver, err := s.DID.GetOfflineResolver()
+					if err != nil {
+						return err
+					}
+					s.Presentation.SetOfflineVerification(offlineResolver)
+				}
+				return nil
+			},
+			Service: func(s *SSIService) framework.Service { return s.Presentation },
+		},
+		{
+			Name:      ManifestComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent, DIDComponent, CredentialComponent, PresentationComponent},
+			Start: func(s *SSIService) (err error) {
+				didResolver := s.DID.GetResolver()
+				if config.OfflineVerification {
+					if didResolver, err = s.DID.GetOfflineResolver(); err != nil {
+						return err
+					}
+				}
+				s.Manifest, err = manifest.NewManifestService(config.ManifestConfig, s.storage, s.KeyStore, didResolver, s.Credential, s.Presentation)
+				return errors.Wrap(err, "could not instantiate the manifest service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.Manifest },
+		},
+		{
+			Name:      OperationComponent,
+			DependsOn: []string{StorageComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Operation, err = operation.NewOperationService(s.storage)
+				return errors.Wrap(err, "could not instantiate the operation service")
+			},
+			Service: func(s *SSIService) framework.Service { return s.Operation },
+		},
+		{
+			Name: AdminComponent,
+			DependsOn: []string{StorageComponent, KeyStoreComponent, DIDComponent, SchemaComponent, CredentialComponent,
+				PresentationComponent, ManifestComponent, WebhookComponent},
+			Start: func(s *SSIService) (err error) {
+				s.Admin, err = admin.NewAdminService(config, s.storage, s.KeyStore)
+				if err != nil {
+					return errors.Wrap(err, "could not instantiate the admin service")
+				}
+				s.Admin.SetCredentialService(s.Credential)
+				s.Admin.SetDemoServices(admin.DemoServices{
+					DID:          s.DID,
+					Schema:       s.Schema,
+					Credential:   s.Credential,
+					Presentation: s.Presentation,
+					Manifest:     s.Manifest,
+					Webhook:      s.Webhook,
+				})
+				return nil
+			},
+			Service: func(s *SSIService) framework.Service { return s.Admin },
+		},
+		{
+			Name:      DIDConfigurationComponent,
+			DependsOn: []string{KeyStoreComponent, DIDComponent, SchemaComponent},
+			Start: func(s *SSIService) error {
+				s.DIDConfiguration, _ = wellknown.NewDIDConfigurationService(s.KeyStore, s.DID.GetResolver(), s.Schema)
+				return nil
+			},
+		},
+		{
+			Name:      IssuerMetadataComponent,
+			DependsOn: []string{DIDComponent, SchemaComponent},
+			Start: func(s *SSIService) (err error) {
+				s.IssuerMetadata, err = wellknown.NewIssuerMetadataService(config.IssuerMetadataConfig, s.DID, s.Schema)
+				return errors.Wrap(err, "could not instantiate the issuer metadata service")
+			},
+		},
+		{
+			Name:      SSIConfigurationComponent,
+			DependsOn: []string{KeyStoreComponent, DIDComponent, SchemaComponent},
+			Start: func(s *SSIService) (err error) {
+				s.SSIConfiguration, err = wellknown.NewSSIConfigurationService(config.SSIConfigurationConfig, s.DID, s.Schema, s.KeyStore)
+				return errors.Wrap(err, "could not instantiate the ssi configuration service")
+			},
+		},
+		leaderElectionComponent(config.LeaderElection),
 	}
+}
 
-	operationService, err := operation.NewOperationService(storageProvider)
-	if err != nil {
-		return nil, sdkutil.LoggingErrorMsg(err, "could not instantiate the operation service")
+// leaderElectionComponent returns a component which keeps acquiring or renewing the lease of the background workers
+// until it is stopped, when leader election is enabled. Stopping it releases the lease.
+func leaderElectionComponent(config config.LeaderElectionConfig) Component {
+	var cancel context.CancelFunc
+	var done chan struct{}
+	return Component{
+		Name:      LeaderElectionComponent,
+		DependsOn: []string{StorageComponent},
+		Start: func(s *SSIService) (err error) {
+			if !config.Enabled {
+				return nil
+			}
+			s.Leader, err = leader.NewElector(s.storage, leader.BackgroundWorkersLease, config)
+			if err != nil {
+				return errors.Wrap(err, "could not instantiate the leader elector")
+			}
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				s.Leader.Run(ctx)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context, _ *SSIService) error {
+			if cancel == nil {
+				return nil
+			}
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return errors.Wrap(ctx.Err(), "waiting for the leader elector to release its lease")
+			}
+		},
 	}
-
-	didConfigurationService, _ := wellknown.NewDIDConfigurationService(keyStoreService, didResolver, schemaService)
-	return &SSIService{
-		KeyStore:         keyStoreService,
-		DID:              didService,
-		BatchDID:         batchDIDService,
-		Schema:           schemaService,
-		Issuance:         issuanceService,
-		Credential:       credentialService,
-		Manifest:         manifestService,
-		Presentation:     presentationService,
-		Operation:        operationService,
-		Webhook:          webhookService,
-		DIDConfiguration: didConfigurationService,
-		storage:          storageProvider,
-	}, nil
 }
 
-// GetServices returns all services
+// GetServices returns all services, in the order they were started
 func (s *SSIService) GetServices() []framework.Service {
-	return []framework.Service{
-		s.KeyStore,
-		s.DID,
-		s.Schema,
-		s.Issuance,
-		s.Credential,
-		s.Manifest,
-		s.Presentation,
-		s.Operation,
-		s.Webhook,
-	}
+	return s.registry.Services(s)
+}
+
+// Stop stops all services and components in the reverse order they were started, closing the storage last.
+func (s *SSIService) Stop(ctx context.Context) error {
+	return s.registry.Stop(ctx, s)
 }
 
 func (s *SSIService) GetStorage() storage.ServiceStorage {
@@ -3,8 +3,14 @@ package webhook
 import (
 	"encoding/json"
 	"net/url"
+
+	"github.com/pkg/errors"
+	"go.einride.tech/aip/filtering"
 )
 
+// ErrWebhookNotFound is returned when testing a webhook, or a URL of it, which is not registered.
+var ErrWebhookNotFound = errors.New("webhook does not exist")
+
 // In the context of webhooks, it's common to use noun.verb notation to describe events,
 // such as "credential.create" or "schema.delete".
 type (
@@ -21,6 +27,8 @@ const (
 	Presentation = Noun("Presentation")
 	Application  = Noun("Application")
 	Submission   = Noun("Submission")
+	StatusList   = Noun("StatusList")
+	Issuer       = Noun("Issuer")
 )
 
 // Supported Verbs
@@ -28,12 +36,37 @@ const (
 	BatchCreate = Verb("BatchCreate")
 	Create      = Verb("Create")
 	Delete      = Verb("Delete")
+	Refresh     = Verb("Refresh")
+	// StatusUpdate and BatchStatusUpdate are published when the status of credentials is updated, along with the
+	// reason given, if any.
+	StatusUpdate      = Verb("StatusUpdate")
+	BatchStatusUpdate = Verb("BatchStatusUpdate")
+	// VerificationFailed is published for failed credential and presentation verifications, when enabled.
+	VerificationFailed = Verb("VerificationFailed")
+	// HostingMismatch is published for status list credentials which are not served as stored from their public URI,
+	// when the hosting check is enabled.
+	HostingMismatch = Verb("HostingMismatch")
+	// StatusMismatch is published for credentials whose stored status does not match the bit of their status list,
+	// when the status consistency check finds them.
+	StatusMismatch = Verb("StatusMismatch")
+	// Freeze and Unfreeze are published when an issuer is frozen through the admin API, and when its freeze is lifted.
+	Freeze   = Verb("Freeze")
+	Unfreeze = Verb("Unfreeze")
 )
 
 type Webhook struct {
 	Noun Noun     `json:"noun" validate:"required"`
 	Verb Verb     `json:"verb" validate:"required"`
 	URLS []string `json:"urls" validate:"required"`
+	// FailureCodes limits VerificationFailed webhooks of a URL to failures with one of the reason codes, keyed by URL.
+	// URLs without failure codes receive every failure.
+	FailureCodes map[string][]string `json:"failureCodes,omitempty"`
+	// Templates transform the payloads posted to a URL, keyed by URL. URLs without a template receive the payload as
+	// is.
+	Templates map[string]string `json:"templates,omitempty"`
+	// CreatedBy holds the principal which registered each URL, keyed by URL. URLs registered before principals were
+	// recorded have none.
+	CreatedBy map[string]string `json:"createdBy,omitempty"`
 }
 
 type Payload struct {
@@ -41,12 +74,64 @@ type Payload struct {
 	Verb Verb            `json:"verb" validate:"required"`
 	URL  string          `json:"url" validate:"required"`
 	Data json.RawMessage `json:"data,omitempty"`
+	// TemplateError is set when the template of the URL failed, in which case the payload is posted untransformed.
+	TemplateError string `json:"templateError,omitempty"`
+	// Test is set for test events, which are about no actual change.
+	Test bool `json:"test,omitempty"`
 }
 
 type CreateWebhookRequest struct {
 	Noun Noun   `json:"noun" validate:"required"`
 	Verb Verb   `json:"verb" validate:"required"`
 	URL  string `json:"url" validate:"required"`
+	// Reason codes of the failures posted to the URL. Only valid for the VerificationFailed verb.
+	FailureCodes []string `json:"failureCodes,omitempty"`
+	// Template is a Go template transforming the payloads posted to the URL into another JSON document.
+	Template string `json:"template,omitempty"`
+	// SampleData is the data of the sample event the template is validated against. Defaults to an empty object.
+	SampleData json.RawMessage `json:"sampleData,omitempty"`
+	// CreatedBy is the principal, such as the name of an API key, registering the URL.
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+// Delivery records a payload posted to a URL, along with the event before it was transformed by a template.
+type Delivery struct {
+	ID   string `json:"id"`
+	Noun Noun   `json:"noun"`
+	Verb Verb   `json:"verb"`
+	URL  string `json:"url"`
+	// Event is the untransformed payload.
+	Event json.RawMessage `json:"event"`
+	// Body is what was posted to the URL, which differs from the event when the URL has a template.
+	Body          string `json:"body"`
+	TemplateError string `json:"templateError,omitempty"`
+	// Error is set when the payload could not be posted, or the URL did not respond with a 2xx status.
+	Error       string `json:"error,omitempty"`
+	DeliveredAt string `json:"deliveredAt"`
+}
+
+type ListDeliveriesRequest struct {
+	Noun Noun `json:"noun" validate:"required"`
+	Verb Verb `json:"verb" validate:"required"`
+}
+
+type ListDeliveriesResponse struct {
+	// Deliveries of the webhook, most recent first.
+	Deliveries []Delivery `json:"deliveries"`
+}
+
+type TestWebhookRequest struct {
+	Noun Noun `json:"noun" validate:"required"`
+	Verb Verb `json:"verb" validate:"required"`
+	// URL limits the test event to one URL of the webhook. The event is posted to every URL when empty.
+	URL string `json:"url,omitempty"`
+	// Data of the test event. Defaults to an object with a random `id`.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type TestWebhookResponse struct {
+	// Deliveries of the test event, one per URL.
+	Deliveries []Delivery `json:"deliveries"`
 }
 
 type CreateWebhookResponse struct {
@@ -62,6 +147,11 @@ type GetWebhookResponse struct {
 	Webhook Webhook `json:"webhook"`
 }
 
+type ListWebhooksRequest struct {
+	// Filter is an AIP-160 filter on the principal which registered the URLs of the webhooks, as `createdBy`.
+	Filter filtering.Filter
+}
+
 type ListWebhooksResponse struct {
 	Webhooks []Webhook `json:"webhooks,omitempty"`
 }
@@ -95,6 +185,18 @@ func (cwr DeleteWebhookRequest) IsValid() bool {
 }
 
 func (cwr CreateWebhookRequest) IsValid() bool {
+	if cwr.Verb == VerificationFailed && cwr.Noun != Credential && cwr.Noun != Presentation {
+		return false
+	}
+	if (cwr.Verb == HostingMismatch || cwr.Verb == StatusMismatch) && cwr.Noun != StatusList {
+		return false
+	}
+	if (cwr.Verb == Freeze || cwr.Verb == Unfreeze) != (cwr.Noun == Issuer) {
+		return false
+	}
+	if len(cwr.FailureCodes) > 0 && cwr.Verb != VerificationFailed {
+		return false
+	}
 	if cwr.Noun.IsValid() && cwr.Verb.isValid() && isValidURL(cwr.URL) {
 		return true
 	}
@@ -103,7 +205,7 @@ func (cwr CreateWebhookRequest) IsValid() bool {
 
 func (n Noun) IsValid() bool {
 	switch n {
-	case Credential, DID, Manifest, Schema, Presentation, Application, Submission:
+	case Credential, DID, Manifest, Schema, Presentation, Application, Submission, StatusList, Issuer:
 		return true
 	}
 	return false
@@ -111,13 +213,27 @@ func (n Noun) IsValid() bool {
 
 func (v Verb) isValid() bool {
 	switch v {
-	case Create, Delete:
+	case Create, Delete, Refresh, VerificationFailed, HostingMismatch, StatusMismatch, Freeze, Unfreeze:
 		return true
 	default:
 		return false
 	}
 }
 
+// accepts returns whether the URL receives VerificationFailed webhooks for failures with the reason code.
+func (wh Webhook) accepts(url, reasonCode string) bool {
+	codes, ok := wh.FailureCodes[url]
+	if !ok {
+		return true
+	}
+	for _, code := range codes {
+		if code == reasonCode {
+			return true
+		}
+	}
+	return false
+}
+
 // isValidURL checks if there were any errors during parsing and if the parsed DIDWebID has a non-empty Scheme and Host.
 // currently we support any scheme including http, https, ftp ...
 func isValidURL(urlStr string) bool {
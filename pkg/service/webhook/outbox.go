@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/pkg/storage"
+)
+
+// OutboxEvent is an event written to the outbox in the transaction of the change it is about, which is published by
+// the outbox dispatcher once the transaction is committed.
+type OutboxEvent struct {
+	ID   string `json:"id"`
+	Noun Noun   `json:"noun"`
+	Verb Verb   `json:"verb"`
+	// ResourceID identifies what the event is about, such as the ID of a credential. The events of a resource are
+	// published in the order they were written.
+	ResourceID string          `json:"resourceId"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	// Attempts counts the failed attempts at publishing the event, the last of which failed with LastError.
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// outboxVerbs are the verbs of each noun whose events are written to the outbox when it is enabled, instead of being
+// published with the responses of the routes causing them.
+var outboxVerbs = map[Noun][]Verb{
+	Credential: {Create, StatusUpdate},
+	DID:        {Create},
+}
+
+// Outboxed returns whether the events of the noun and verb are published from the outbox.
+func (s Service) Outboxed(noun Noun, verb Verb) bool {
+	if !s.config.OutboxEnabled {
+		return false
+	}
+	for _, outboxVerb := range outboxVerbs[noun] {
+		if outboxVerb == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteOutboxEventTx writes an event about a resource to the outbox in the transaction changing the resource, so that
+// the event is published if, and only if, the change is committed.
+func WriteOutboxEventTx(ctx context.Context, tx storage.Tx, noun Noun, verb Verb, resourceID string, data any) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrapf(err, "marshalling data of %s:%s event", noun, verb)
+	}
+	event := OutboxEvent{ID: NewEventID(), Noun: noun, Verb: verb, ResourceID: resourceID, Data: dataBytes}
+	return writeOutboxEvent(ctx, tx, event)
+}
+
+// lastEventTime is the time in the last event ID.
+var lastEventTime atomic.Int64
+
+// NewEventID returns an ID which sorts events by the time they were written, and in the order they were written by
+// this process when they were written within the same nanosecond.
+func NewEventID() string {
+	for {
+		last := lastEventTime.Load()
+		now := time.Now().UnixNano()
+		if now <= last {
+			now = last + 1
+		}
+		if lastEventTime.CompareAndSwap(last, now) {
+			return fmt.Sprintf("%020d-%s", now, uuid.NewString())
+		}
+	}
+}
+
+// RunOutboxDispatcher dispatches the outbox every dispatch interval until the context is done. It returns right away
+// when the outbox is not enabled. Since events are only removed from the outbox once published, the events left when
+// the dispatcher stops are published when it runs again.
+func (s Service) RunOutboxDispatcher(ctx context.Context) {
+	if s.outboxInterval == 0 {
+		return
+	}
+	ticker := time.NewTicker(s.outboxInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DispatchOutbox(ctx); err != nil {
+				logrus.WithError(err).Error("dispatching outbox")
+			}
+		}
+	}
+}
+
+// DispatchOutbox publishes the events of the outbox in the order they were written, removing each event once it was
+// posted to every URL of its webhook. An event is removed after it is published, so it is published again when the
+// dispatcher stops in between: events are published at least once. An event which fails to be published is kept to be
+// retried by the next dispatch, and holds back the later events of its resource, so that they are not published out
+// of order. The number of events published is returned.
+func (s Service) DispatchOutbox(ctx context.Context) (int, error) {
+	events, err := s.storage.ListOutboxEvents(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "listing outbox events")
+	}
+
+	heldBack := make(map[string]bool)
+	published := 0
+	for _, event := range events {
+		resource := storage.Join(string(event.Noun), event.ResourceID)
+		if heldBack[resource] {
+			continue
+		}
+		if err = s.publish(ctx, event.Noun, event.Verb, event.Data, func(Webhook, string) bool { return true }); err != nil {
+			logrus.WithError(err).Warnf("publishing outbox event<%s>, retrying with the next dispatch", event.ID)
+			heldBack[resource] = true
+			event.Attempts++
+			event.LastError = err.Error()
+			if err = s.storage.StoreOutboxEvent(ctx, event); err != nil {
+				logrus.WithError(err).Errorf("recording failed attempt of outbox event<%s>", event.ID)
+			}
+			continue
+		}
+		if err = s.storage.DeleteOutboxEvent(ctx, event.ID); err != nil {
+			return published, errors.Wrapf(err, "removing published outbox event<%s>", event.ID)
+		}
+		published++
+	}
+	return published, nil
+}
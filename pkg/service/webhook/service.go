@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/gin-gonic/gin"
 	"github.com/goccy/go-json"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
@@ -28,6 +31,8 @@ type Service struct {
 	config          config.WebhookServiceConfig
 	httpClient      *http.Client
 	timeoutDuration time.Duration
+	// outboxInterval is 0 when the outbox is not enabled
+	outboxInterval time.Duration
 }
 
 func (s Service) Type() framework.Type {
@@ -65,12 +70,19 @@ func NewWebhookService(config config.WebhookServiceConfig, s storage.ServiceStor
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "parsing webhook timeout")
 	}
+	var outboxInterval time.Duration
+	if config.OutboxEnabled {
+		if outboxInterval, err = time.ParseDuration(config.OutboxDispatchInterval); err != nil || outboxInterval <= 0 {
+			return nil, sdkutil.LoggingNewErrorf("invalid outbox dispatch interval: %s", config.OutboxDispatchInterval)
+		}
+	}
 
 	service := Service{
 		storage:         webhookStorage,
 		config:          config,
 		httpClient:      client,
 		timeoutDuration: duration,
+		outboxInterval:  outboxInterval,
 	}
 
 	if !service.Status().IsReady() {
@@ -82,13 +94,24 @@ func NewWebhookService(config config.WebhookServiceConfig, s storage.ServiceStor
 func (s Service) CreateWebhook(ctx context.Context, request CreateWebhookRequest) (*CreateWebhookResponse, error) {
 	logrus.Debugf("creating webhook: %+v", request)
 
+	if request.Template != "" {
+		sampleData := request.SampleData
+		if len(sampleData) == 0 {
+			sampleData = []byte("{}")
+		}
+		sample := Payload{Noun: request.Noun, Verb: request.Verb, URL: request.URL, Data: sampleData}
+		if err := validateTemplate(request.Template, sample); err != nil {
+			return nil, sdkutil.LoggingErrorMsgf(err, "validating template of webhook for %s", request.URL)
+		}
+	}
+
 	webhook, err := s.storage.GetWebhook(ctx, string(request.Noun), string(request.Verb))
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "get webhook")
 	}
 
 	if webhook == nil {
-		webhook = &Webhook{request.Noun, request.Verb, []string{request.URL}}
+		webhook = &Webhook{Noun: request.Noun, Verb: request.Verb, URLS: []string{request.URL}}
 	} else {
 		exists := false
 		for _, v := range webhook.URLS {
@@ -103,6 +126,33 @@ func (s Service) CreateWebhook(ctx context.Context, request CreateWebhookRequest
 		}
 	}
 
+	// the failure codes of a URL are replaced each time it is registered
+	delete(webhook.FailureCodes, request.URL)
+	if len(request.FailureCodes) > 0 {
+		if webhook.FailureCodes == nil {
+			webhook.FailureCodes = make(map[string][]string)
+		}
+		webhook.FailureCodes[request.URL] = request.FailureCodes
+	}
+
+	// as is the template
+	delete(webhook.Templates, request.URL)
+	if request.Template != "" {
+		if webhook.Templates == nil {
+			webhook.Templates = make(map[string]string)
+		}
+		webhook.Templates[request.URL] = request.Template
+	}
+
+	// and the principal which registered it
+	delete(webhook.CreatedBy, request.URL)
+	if request.CreatedBy != "" {
+		if webhook.CreatedBy == nil {
+			webhook.CreatedBy = make(map[string]string)
+		}
+		webhook.CreatedBy[request.URL] = request.CreatedBy
+	}
+
 	err = s.storage.StoreWebhook(ctx, string(request.Noun), string(request.Verb), *webhook)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "store webhook")
@@ -126,16 +176,51 @@ func (s Service) GetWebhook(ctx context.Context, request GetWebhookRequest) (*Ge
 	return &GetWebhookResponse{Webhook: *webhook}, nil
 }
 
-// ListWebhooks returns all webhooks in storage.
-func (s Service) ListWebhooks(ctx context.Context) (*ListWebhooksResponse, error) {
+// ListWebhooks returns all webhooks in storage. When the request has a filter, the webhooks only have the URLs whose
+// principal the filter includes, and webhooks left without URLs are left out.
+func (s Service) ListWebhooks(ctx context.Context, request ListWebhooksRequest) (*ListWebhooksResponse, error) {
 	logrus.Debug("listing all webhooks")
 
 	webhooks, err := s.storage.ListWebhooks(ctx)
 	if err != nil {
 		return nil, sdkutil.LoggingErrorMsg(err, "list webhooks")
 	}
+	if request.Filter.CheckedExpr == nil {
+		return &ListWebhooksResponse{Webhooks: webhooks}, nil
+	}
+
+	shouldInclude, err := storage.NewIncludeFunc(request.Filter)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "creating filter of webhooks")
+	}
+	filtered := make([]Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		var urls []string
+		for _, url := range webhook.URLS {
+			include, err := shouldInclude(urlCreator(webhook.CreatedBy[url]))
+			if err != nil {
+				return nil, sdkutil.LoggingErrorMsgf(err, "filtering url<%s> of webhook", url)
+			}
+			if include {
+				urls = append(urls, url)
+			}
+		}
+		if len(urls) == 0 {
+			continue
+		}
+		webhook.URLS = urls
+		filtered = append(filtered, webhook)
+	}
+	return &ListWebhooksResponse{Webhooks: filtered}, nil
+}
+
+// urlCreator is the principal which registered a URL of a webhook, which webhooks are filtered by.
+type urlCreator string
 
-	return &ListWebhooksResponse{Webhooks: webhooks}, nil
+func (c urlCreator) FilterVariablesMap() map[string]any {
+	return map[string]any{
+		"createdBy": string(c),
+	}
 }
 
 // DeleteWebhook deletes a webhook from the storage by removing a given DIDWebID from the list of URLs associated with the webhook.
@@ -160,6 +245,9 @@ func (s Service) DeleteWebhook(ctx context.Context, request DeleteWebhookRequest
 	}
 
 	webhook.URLS = append(webhook.URLS[:index], webhook.URLS[index+1:]...)
+	delete(webhook.FailureCodes, request.URL)
+	delete(webhook.Templates, request.URL)
+	delete(webhook.CreatedBy, request.URL)
 
 	// if the webhook has no more URLS delete the entire webhook entity
 	if len(webhook.URLS) == 0 {
@@ -170,56 +258,177 @@ func (s Service) DeleteWebhook(ctx context.Context, request DeleteWebhookRequest
 }
 
 func (s Service) GetSupportedNouns() GetSupportedNounsResponse {
-	return GetSupportedNounsResponse{Nouns: []Noun{Credential, DID, Manifest, Schema, Presentation}}
+	return GetSupportedNounsResponse{Nouns: []Noun{Credential, DID, Manifest, Schema, Presentation, StatusList}}
 }
 
 func (s Service) GetSupportedVerbs() GetSupportedVerbsResponse {
-	return GetSupportedVerbsResponse{Verbs: []Verb{Create, Delete}}
+	return GetSupportedVerbsResponse{Verbs: []Verb{Create, Delete, Refresh, StatusUpdate, BatchStatusUpdate, VerificationFailed, HostingMismatch, StatusMismatch}}
 }
 
 // TODO: consider returning an error to be handled by the gin middleware
 func (s Service) PublishWebhook(c *gin.Context, noun Noun, verb Verb, payloadReader io.Reader) {
-	timeoutCtx, cancel := context.WithTimeout(c.Copy(), s.timeoutDuration)
+	payloadBytes, err := io.ReadAll(payloadReader)
+	if err != nil {
+		logrus.WithError(err).Error("converting payload to bytes")
+		return
+	}
+	s.Publish(c.Copy(), noun, verb, payloadBytes)
+}
+
+// Publish posts the payload to every URL registered for the noun and verb. It is used for events which do not
+// originate from an HTTP request, such as background tasks.
+func (s Service) Publish(ctx context.Context, noun Noun, verb Verb, payloadBytes []byte) {
+	_ = s.publish(ctx, noun, verb, payloadBytes, func(Webhook, string) bool { return true })
+}
+
+// PublishVerificationFailed posts the payload of a failed verification to every URL registered for the noun and the
+// VerificationFailed verb, unless the failure codes of the URL do not include the reason code.
+func (s Service) PublishVerificationFailed(ctx context.Context, noun Noun, reasonCode string, payloadBytes []byte) {
+	_ = s.publish(ctx, noun, VerificationFailed, payloadBytes, func(webhook Webhook, url string) bool {
+		return webhook.accepts(url, reasonCode)
+	})
+}
+
+// publish posts the payload to the URLs of the webhook of the noun and verb which are included, recording each delivery.
+// An error is returned when the webhook could not be read, or the payload could not be posted to some of the URLs.
+func (s Service) publish(ctx context.Context, noun Noun, verb Verb, payloadBytes []byte, include func(webhook Webhook, url string) bool) error {
+	_, err := s.deliver(ctx, Payload{Noun: noun, Verb: verb, Data: payloadBytes}, include)
+	return err
+}
+
+// deliver is like publish, for a payload whose URL is set to each URL it is posted to. The deliveries are returned,
+// including those which failed.
+func (s Service) deliver(ctx context.Context, payload Payload, include func(webhook Webhook, url string) bool) ([]Delivery, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.timeoutDuration)
 	defer cancel()
 
-	nounString := string(noun)
-	verbString := string(verb)
+	nounString := string(payload.Noun)
+	verbString := string(payload.Verb)
 	webhook, err := s.storage.GetWebhook(timeoutCtx, nounString, verbString)
 	if err != nil {
 		logrus.WithError(err).Debugf("getting webhook: %s:%s", nounString, verbString)
-		return
+		return nil, errors.Wrapf(err, "getting webhook: %s:%s", nounString, verbString)
 	}
 
 	if webhook == nil {
 		logrus.Debugf("webhook does not exist: %s:%s", nounString, verbString)
-		return
-	}
-
-	payloadBytes, err := io.ReadAll(payloadReader)
-	if err != nil {
-		logrus.WithError(err).Error("converting payload to bytes")
-		return
+		return nil, nil
 	}
 
 	var wg sync.WaitGroup
-	postPayload := Payload{Noun: noun, Verb: verb, Data: payloadBytes}
+	var mu sync.Mutex
+	var failedURLs []string
+	var deliveries []Delivery
 	for _, url := range webhook.URLS {
+		if !include(*webhook, url) {
+			continue
+		}
+		postPayload := payload
 		postPayload.URL = url
-		postJSONData, err := json.Marshal(postPayload)
+		event, err := json.Marshal(postPayload)
 		if err != nil {
 			logrus.WithError(err).Error("marshalling payload")
 			continue
 		}
+		delivery := Delivery{ID: newDeliveryID(), Noun: payload.Noun, Verb: payload.Verb, URL: url, Event: event, Body: string(event)}
+		if tmpl, ok := webhook.Templates[url]; ok {
+			delivery.Body, delivery.TemplateError = transformPayload(tmpl, postPayload, event)
+		}
 
 		wg.Add(1)
-		go func(url, data string) {
+		go func(delivery Delivery) {
 			defer wg.Done()
-			if err = s.post(timeoutCtx, url, data); err != nil {
-				logrus.WithError(err).Errorf("posting payload to %s", url)
+			if err := s.post(timeoutCtx, delivery.URL, delivery.Body); err != nil {
+				logrus.WithError(err).Errorf("posting payload to %s", delivery.URL)
+				delivery.Error = err.Error()
+				mu.Lock()
+				failedURLs = append(failedURLs, delivery.URL)
+				mu.Unlock()
 			}
-		}(url, string(postJSONData))
+			delivery.DeliveredAt = time.Now().Format(time.RFC3339)
+			// the delivery is recorded even when posting it timed out
+			if err := s.storage.StoreDelivery(ctx, delivery); err != nil {
+				logrus.WithError(err).Errorf("recording delivery to %s", delivery.URL)
+			}
+			mu.Lock()
+			deliveries = append(deliveries, delivery)
+			mu.Unlock()
+		}(delivery)
 	}
 	wg.Wait()
+	if len(failedURLs) > 0 {
+		return deliveries, errors.Errorf("posting payload of %s:%s failed for urls: %v", nounString, verbString, failedURLs)
+	}
+	return deliveries, nil
+}
+
+// TestWebhook posts a test event to the URLs of a webhook, or to one of them, so that integrators can validate their
+// receiver. The event goes through the same delivery as actual events, including templates and the delivery history,
+// and is marked as a test. The deliveries are returned once made, or once the webhook timeout is reached, whether or not
+// they succeeded.
+func (s Service) TestWebhook(ctx context.Context, request TestWebhookRequest) (*TestWebhookResponse, error) {
+	logrus.Debugf("testing webhook: %s-%s", request.Noun, request.Verb)
+
+	webhook, err := s.storage.GetWebhook(ctx, string(request.Noun), string(request.Verb))
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "get webhook")
+	}
+	if webhook == nil {
+		return nil, sdkutil.LoggingError(ErrWebhookNotFound)
+	}
+	if request.URL != "" && !slices.Contains(webhook.URLS, request.URL) {
+		return nil, sdkutil.LoggingError(errors.Wrapf(ErrWebhookNotFound, "url<%s> is not registered", request.URL))
+	}
+
+	data := request.Data
+	if len(data) == 0 {
+		if data, err = json.Marshal(map[string]string{"id": uuid.NewString()}); err != nil {
+			return nil, sdkutil.LoggingErrorMsg(err, "marshalling test event data")
+		}
+	}
+	payload := Payload{Noun: request.Noun, Verb: request.Verb, Data: data, Test: true}
+	deliveries, err := s.deliver(ctx, payload, func(_ Webhook, url string) bool {
+		return request.URL == "" || request.URL == url
+	})
+	if err != nil && len(deliveries) == 0 {
+		return nil, sdkutil.LoggingErrorMsg(err, "delivering test event")
+	}
+	// failed deliveries are reported with their error
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].URL < deliveries[j].URL })
+	return &TestWebhookResponse{Deliveries: deliveries}, nil
+}
+
+// transformPayload applies the template of a URL to the event, returning the body to post. When the template fails,
+// the untransformed payload is posted instead, marked with the error.
+func transformPayload(tmpl string, payload Payload, event []byte) (body string, templateError string) {
+	transformed, err := applyTemplate(tmpl, event)
+	if err == nil {
+		return string(transformed), ""
+	}
+	logrus.WithError(err).Warnf("applying template of webhook for %s", payload.URL)
+	payload.TemplateError = err.Error()
+	marked, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		logrus.WithError(marshalErr).Error("marshalling payload")
+		return string(event), payload.TemplateError
+	}
+	return string(marked), payload.TemplateError
+}
+
+// newDeliveryID returns an ID which sorts deliveries by the time they were made.
+func newDeliveryID() string {
+	return fmt.Sprintf("%020d-%s", time.Now().UnixNano(), uuid.NewString())
+}
+
+// ListDeliveries returns the most recent deliveries of the webhook of the noun and verb, most recent first.
+func (s Service) ListDeliveries(ctx context.Context, request ListDeliveriesRequest) (*ListDeliveriesResponse, error) {
+	logrus.Debugf("listing deliveries of webhook: %s-%s", request.Noun, request.Verb)
+
+	deliveries, err := s.storage.ListDeliveries(ctx, string(request.Noun), string(request.Verb))
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "list deliveries")
+	}
+	return &ListDeliveriesResponse{Deliveries: deliveries}, nil
 }
 
 func (s Service) post(ctx context.Context, url string, json string) error {
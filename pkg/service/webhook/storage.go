@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"context"
+	"sort"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
 	"github.com/goccy/go-json"
@@ -11,7 +12,14 @@ import (
 	"github.com/tbd54566975/ssi-service/pkg/storage"
 )
 
-const webhookNamespace = "webhook"
+const (
+	webhookNamespace  = "webhook"
+	deliveryNamespace = "webhook-delivery"
+	outboxNamespace   = "webhook-outbox"
+
+	// maxDeliveries is how many of the most recent deliveries of each webhook are kept.
+	maxDeliveries = 100
+)
 
 type Storage struct {
 	db storage.ServiceStorage
@@ -72,6 +80,93 @@ func (whs *Storage) DeleteWebhook(ctx context.Context, noun, verb string) error
 	return whs.db.Delete(ctx, webhookNamespace, getWebhookKey(noun, verb))
 }
 
+// StoreDelivery records a delivery of the webhook of the noun and verb, dropping the oldest deliveries beyond
+// maxDeliveries.
+func (whs *Storage) StoreDelivery(ctx context.Context, delivery Delivery) error {
+	deliveryBytes, err := json.Marshal(delivery)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "delivery marshal")
+	}
+	if err = whs.db.Write(ctx, deliveryNamespace, getDeliveryKey(string(delivery.Noun), string(delivery.Verb), delivery.ID), deliveryBytes); err != nil {
+		return sdkutil.LoggingErrorMsg(err, "write delivery")
+	}
+
+	deliveries, err := whs.ListDeliveries(ctx, string(delivery.Noun), string(delivery.Verb))
+	if err != nil {
+		return err
+	}
+	for i := maxDeliveries; i < len(deliveries); i++ {
+		if err = whs.db.Delete(ctx, deliveryNamespace, getDeliveryKey(string(delivery.Noun), string(delivery.Verb), deliveries[i].ID)); err != nil {
+			return sdkutil.LoggingErrorMsg(err, "delete delivery")
+		}
+	}
+	return nil
+}
+
+// ListDeliveries returns the deliveries of the webhook of the noun and verb, most recent first.
+func (whs *Storage) ListDeliveries(ctx context.Context, noun, verb string) ([]Delivery, error) {
+	gotDeliveries, err := whs.db.ReadPrefix(ctx, deliveryNamespace, getDeliveryKey(noun, verb, ""))
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not get deliveries")
+	}
+
+	deliveries := make([]Delivery, 0, len(gotDeliveries))
+	for _, deliveryBytes := range gotDeliveries {
+		var delivery Delivery
+		if err = json.Unmarshal(deliveryBytes, &delivery); err != nil {
+			logrus.WithError(err).Warn("unmarshal delivery")
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	// delivery IDs start with the time of delivery
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	return deliveries, nil
+}
+
+// StoreOutboxEvent replaces an event of the outbox, such as to record a failed attempt at publishing it.
+func (whs *Storage) StoreOutboxEvent(ctx context.Context, event OutboxEvent) error {
+	return writeOutboxEvent(ctx, whs.db, event)
+}
+
+// ListOutboxEvents returns the events of the outbox in the order they were written.
+func (whs *Storage) ListOutboxEvents(ctx context.Context) ([]OutboxEvent, error) {
+	gotEvents, err := whs.db.ReadAll(ctx, outboxNamespace)
+	if err != nil {
+		return nil, sdkutil.LoggingErrorMsg(err, "could not get outbox events")
+	}
+
+	events := make([]OutboxEvent, 0, len(gotEvents))
+	for _, eventBytes := range gotEvents {
+		var event OutboxEvent
+		if err = json.Unmarshal(eventBytes, &event); err != nil {
+			logrus.WithError(err).Warn("unmarshal outbox event")
+			continue
+		}
+		events = append(events, event)
+	}
+	// event IDs start with the time the event was written
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (whs *Storage) DeleteOutboxEvent(ctx context.Context, id string) error {
+	return whs.db.Delete(ctx, outboxNamespace, id)
+}
+
+// writeOutboxEvent writes an event of the outbox with a transaction, or with the storage itself.
+func writeOutboxEvent(ctx context.Context, tx storage.Tx, event OutboxEvent) error {
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return sdkutil.LoggingErrorMsg(err, "outbox event marshal")
+	}
+	return tx.Write(ctx, outboxNamespace, event.ID, eventBytes)
+}
+
+func getDeliveryKey(noun, verb, id string) string {
+	return storage.Join(noun, verb, id)
+}
+
 func getWebhookKey(noun, verb string) string {
 	return storage.Join(noun, verb)
 }
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidTemplate is returned when registering a webhook with a template which cannot be parsed, or which does not
+// produce JSON from the sample event.
+var ErrInvalidTemplate = errors.New("invalid webhook template")
+
+// templateFuncs are available to webhook templates, in addition to the builtin functions of text/template.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, such as a string to be quoted or an object to be embedded as is.
+	"json": func(v any) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// validateTemplate rejects a template which cannot be parsed, or which fails or does not produce JSON when applied to
+// the sample event.
+func validateTemplate(text string, sample Payload) error {
+	sampleBytes, err := json.Marshal(sample)
+	if err != nil {
+		return errors.Wrap(err, "marshalling sample event")
+	}
+	if _, err = applyTemplate(text, sampleBytes); err != nil {
+		return errors.Wrap(ErrInvalidTemplate, err.Error())
+	}
+	return nil
+}
+
+// applyTemplate executes a Go template with an event, which it reads by the JSON names of its fields, such as
+// `{{.noun}}` or `{{.data.id}}`. The output must be JSON.
+func applyTemplate(text string, event []byte) ([]byte, error) {
+	tmpl, err := template.New("webhook").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing template")
+	}
+	var data map[string]any
+	if err = json.Unmarshal(event, &data); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling event")
+	}
+	var out bytes.Buffer
+	if err = tmpl.Execute(&out, data); err != nil {
+		return nil, errors.Wrap(err, "executing template")
+	}
+	if !json.Valid(out.Bytes()) {
+		return nil, fmt.Errorf("template output is not JSON: %s", out.String())
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,256 @@
+package wellknown
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/TBD54566975/ssi-sdk/credential"
+	schemalib "github.com/TBD54566975/ssi-sdk/credential/schema"
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
+	didsdk "github.com/TBD54566975/ssi-sdk/did"
+	sdkutil "github.com/TBD54566975/ssi-sdk/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/i18n"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// IssuerMetadataService builds the OpenID4VCI credential issuer metadata, which wallets use to discover which
+// credentials the service can issue and how.
+type IssuerMetadataService struct {
+	config config.IssuerMetadataServiceConfig
+
+	// external dependencies
+	did    *did.Service
+	schema *schema.Service
+}
+
+func NewIssuerMetadataService(config config.IssuerMetadataServiceConfig, didService *did.Service, schemaService *schema.Service) (*IssuerMetadataService, error) {
+	if didService == nil {
+		return nil, errors.New("did service cannot be nil")
+	}
+	if schemaService == nil {
+		return nil, errors.New("schema service cannot be nil")
+	}
+	if len(config.CredentialFormats) == 0 {
+		config.CredentialFormats = []string{JWTVCJSONFormat}
+	}
+	for _, format := range config.CredentialFormats {
+		if !sdkutil.Contains(format, supportedCredentialFormats) {
+			return nil, fmt.Errorf("unsupported credential format<%s>, must be one of: %v", format, supportedCredentialFormats)
+		}
+	}
+	return &IssuerMetadataService{
+		config: config,
+		did:    didService,
+		schema: schemaService,
+	}, nil
+}
+
+func (s IssuerMetadataService) Type() svcframework.Type {
+	return svcframework.IssuerMetadata
+}
+
+func (s IssuerMetadataService) Status() svcframework.Status {
+	return svcframework.Status{
+		Status: svcframework.StatusReady,
+	}
+}
+
+var _ svcframework.Service = (*IssuerMetadataService)(nil)
+
+// GetIssuerMetadata returns the credential issuer metadata. Every schema is advertised as a credential configuration in
+// each configured format, signed with any of the algorithms of the keys of the DIDs controlled by the service.
+func (s IssuerMetadataService) GetIssuerMetadata(ctx context.Context, request GetIssuerMetadataRequest) (*IssuerMetadata, error) {
+	signingAlgs, err := s.signingAlgorithms(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "gathering signing algorithms")
+	}
+	bindingMethods := s.bindingMethods()
+
+	schemas, err := s.schema.ListSchemas(ctx, schema.ListSchemasRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing schemas")
+	}
+	configurations := make(map[string]CredentialConfiguration, len(schemas.Schemas)*len(s.config.CredentialFormats))
+	for _, gotSchema := range schemas.Schemas {
+		// credential schemas are only stored signed, so resolving is needed to get to the JSON schema
+		jsonSchema, _, err := s.schema.Resolve(ctx, gotSchema.ID)
+		if err != nil {
+			logrus.WithError(err).Warnf("leaving schema<%s> out of issuer metadata", gotSchema.ID)
+			continue
+		}
+		for _, format := range s.config.CredentialFormats {
+			configurations[CredentialConfigurationID(gotSchema.ID, format)] = CredentialConfiguration{
+				Format:                               format,
+				CryptographicBindingMethodsSupported: bindingMethods,
+				CredentialSigningAlgValuesSupported:  signingAlgs,
+				CredentialDefinition: CredentialDefinition{
+					Type:              []string{credential.VerifiableCredentialType},
+					CredentialSubject: s.claims(*jsonSchema),
+				},
+				Display: s.credentialDisplay(*jsonSchema),
+			}
+		}
+	}
+
+	metadata := IssuerMetadata{
+		CredentialIssuer:                  s.config.CredentialIssuer,
+		CredentialEndpoint:                s.config.CredentialEndpoint,
+		CredentialConfigurationsSupported: configurations,
+	}
+	if metadata.CredentialIssuer == "" {
+		metadata.CredentialIssuer = config.GetAPIBase()
+	}
+	if metadata.CredentialEndpoint == "" {
+		metadata.CredentialEndpoint = config.GetServicePath(svcframework.Credential)
+	}
+	metadata.Display = s.issuerDisplay(request.AcceptLanguage)
+	return &metadata, nil
+}
+
+// issuerDisplay returns the configured displays of the issuer. When the locale of a display best matches the
+// Accept-Language header, as negotiated for error messages, only that display is returned.
+func (s IssuerMetadataService) issuerDisplay(acceptLanguage string) []Display {
+	var displays []Display
+	if s.config.DisplayName != "" {
+		displays = append(displays, Display{Name: s.config.DisplayName, Locale: s.config.DisplayLocale})
+	}
+	for _, display := range s.config.Displays {
+		displays = append(displays, Display{Name: display.Name, Locale: display.Locale})
+	}
+	if len(displays) < 2 {
+		return displays
+	}
+
+	locales := make([]string, 0, len(displays))
+	for _, display := range displays {
+		locales = append(locales, display.Locale)
+	}
+	if locale := i18n.Negotiate(acceptLanguage, locales); locale != "" {
+		for _, display := range displays {
+			if display.Locale == locale {
+				return []Display{display}
+			}
+		}
+	}
+	return displays
+}
+
+// signingAlgorithms returns the JWS algorithms of the keys of every DID controlled by the service.
+func (s IssuerMetadataService) signingAlgorithms(ctx context.Context) ([]string, error) {
+	algs := make(map[string]bool)
+	for _, method := range s.did.GetSupportedMethods().Methods {
+		dids, err := s.did.ListDIDsByMethod(ctx, did.ListDIDsRequest{Method: method})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing DIDs for method<%s>", method)
+		}
+		for _, doc := range dids.DIDs {
+			for _, vm := range doc.VerificationMethod {
+				if alg := verificationMethodAlg(vm); alg != "" {
+					algs[alg] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(algs), nil
+}
+
+// verificationMethodAlg returns the JWS algorithm of the key of a verification method, or an empty string for keys
+// which cannot sign, such as key agreement keys.
+func verificationMethodAlg(vm didsdk.VerificationMethod) string {
+	if vm.PublicKeyJWK == nil {
+		return ""
+	}
+	alg := vm.PublicKeyJWK.ALG
+	if alg == "" {
+		var err error
+		if alg, err = jwx.AlgFromKeyAndCurve(vm.PublicKeyJWK.KTY, vm.PublicKeyJWK.CRV); err != nil {
+			return ""
+		}
+	}
+	if !jwx.IsSupportedJWXSigningVerificationAlgorithm(alg) && !jwx.IsExperimentalJWXSigningVerificationAlgorithm(alg) {
+		return ""
+	}
+	return alg
+}
+
+// bindingMethods returns the DID methods holders may bind credentials to, which are those the service can resolve.
+func (s IssuerMetadataService) bindingMethods() []string {
+	didConfig := s.did.Config()
+	methods := make([]string, 0, len(didConfig.LocalResolutionMethods)+len(didConfig.UniversalResolverMethods))
+	for _, resolutionMethods := range [][]string{didConfig.LocalResolutionMethods, didConfig.UniversalResolverMethods} {
+		for _, method := range resolutionMethods {
+			bindingMethod := "did:" + method
+			if !sdkutil.Contains(bindingMethod, methods) {
+				methods = append(methods, bindingMethod)
+			}
+		}
+	}
+	return methods
+}
+
+// claims returns the claims of the credential subject described by a schema. The properties of `credentialSubject`
+// are used when the schema describes a whole credential, and the top level properties otherwise.
+func (s IssuerMetadataService) claims(jsonSchema schemalib.JSONSchema) map[string]ClaimMetadata {
+	properties, _ := jsonSchema["properties"].(map[string]any)
+	required, _ := jsonSchema["required"].([]any)
+	if subject, ok := properties["credentialSubject"].(map[string]any); ok {
+		properties, _ = subject["properties"].(map[string]any)
+		required, _ = subject["required"].([]any)
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+
+	claims := make(map[string]ClaimMetadata, len(properties))
+	for name, property := range properties {
+		if name == "id" {
+			continue
+		}
+		claim := ClaimMetadata{Mandatory: sdkutil.Contains(name, toStrings(required))}
+		if propertyMap, ok := property.(map[string]any); ok {
+			if title, ok := propertyMap["title"].(string); ok && title != "" {
+				claim.Display = []Display{{Name: title, Locale: s.config.DisplayLocale}}
+			}
+		}
+		claims[name] = claim
+	}
+	return claims
+}
+
+func (s IssuerMetadataService) credentialDisplay(jsonSchema schemalib.JSONSchema) []CredentialDisplay {
+	if jsonSchema.Name() == "" {
+		return nil
+	}
+	return []CredentialDisplay{{Name: jsonSchema.Name(), Description: jsonSchema.Description(), Locale: s.config.DisplayLocale}}
+}
+
+// CredentialConfigurationID returns the identifier of the credential configuration of a schema in a format.
+func CredentialConfigurationID(schemaID, format string) string {
+	return fmt.Sprintf("%s_%s", schemaID, format)
+}
+
+func toStrings(values []any) []string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
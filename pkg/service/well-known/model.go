@@ -1,9 +1,11 @@
 package wellknown
 
 import (
+	"github.com/TBD54566975/ssi-sdk/crypto/jwx"
 	"github.com/goccy/go-json"
 	"github.com/pkg/errors"
 	"github.com/tbd54566975/ssi-service/internal/credential"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
 )
 
 type CreateDIDConfigurationRequest struct {
@@ -70,3 +72,90 @@ type VerifyDIDConfigurationResponse struct {
 	// When Verified == false, the reason why it wasn't verified.
 	Reason string `json:"reason,omitempty"`
 }
+
+// Credential formats defined by OpenID4VCI for W3C Verifiable Credentials.
+const (
+	JWTVCJSONFormat   = "jwt_vc_json"
+	JWTVCJSONLDFormat = "jwt_vc_json-ld"
+	LDPVCFormat       = "ldp_vc"
+
+	OpenIDCredentialIssuerLocationSuffix = "/.well-known/openid-credential-issuer"
+)
+
+var supportedCredentialFormats = []string{JWTVCJSONFormat, JWTVCJSONLDFormat, LDPVCFormat}
+
+type GetIssuerMetadataRequest struct {
+	// AcceptLanguage is the Accept-Language header of the request, which selects the display of the issuer.
+	AcceptLanguage string
+}
+
+// IssuerMetadata is the credential issuer metadata according to
+// https://openid.net/specs/openid-4-verifiable-credential-issuance-1_0.html#name-credential-issuer-metadata-p
+type IssuerMetadata struct {
+	CredentialIssuer   string    `json:"credential_issuer"`
+	CredentialEndpoint string    `json:"credential_endpoint"`
+	Display            []Display `json:"display,omitempty"`
+
+	// Keyed by the ID of each credential configuration, as returned by CredentialConfigurationID.
+	CredentialConfigurationsSupported map[string]CredentialConfiguration `json:"credential_configurations_supported"`
+}
+
+type Display struct {
+	Name   string `json:"name,omitempty"`
+	Locale string `json:"locale,omitempty"`
+}
+
+// CredentialConfiguration describes a credential which can be issued from a schema, in a format.
+type CredentialConfiguration struct {
+	Format                               string               `json:"format"`
+	CryptographicBindingMethodsSupported []string             `json:"cryptographic_binding_methods_supported,omitempty"`
+	CredentialSigningAlgValuesSupported  []string             `json:"credential_signing_alg_values_supported,omitempty"`
+	CredentialDefinition                 CredentialDefinition `json:"credential_definition"`
+	Display                              []CredentialDisplay  `json:"display,omitempty"`
+}
+
+type CredentialDefinition struct {
+	Type              []string                 `json:"type"`
+	CredentialSubject map[string]ClaimMetadata `json:"credentialSubject,omitempty"`
+}
+
+type ClaimMetadata struct {
+	Mandatory bool      `json:"mandatory,omitempty"`
+	Display   []Display `json:"display,omitempty"`
+}
+
+type CredentialDisplay struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+}
+
+const SSIConfigurationLocationSuffix = "/.well-known/ssi-configuration"
+
+// SSIConfiguration bundles the issuer DIDs managed by the service, their public keys, the schemas, and the status list
+// endpoint, which is everything verifiers need to verify the credentials of the service.
+type SSIConfiguration struct {
+	Issuers            []IssuerKeys    `json:"issuers"`
+	Schemas            []SchemaSummary `json:"schemas"`
+	StatusListEndpoint string          `json:"statusListEndpoint"`
+}
+
+// IssuerKeys are the public keys of an issuer DID, as JWKs whose kid is the fully qualified verification method.
+type IssuerKeys struct {
+	ID   string             `json:"id"`
+	Keys []jwx.PublicKeyJWK `json:"keys"`
+}
+
+type SchemaSummary struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+type GetSSIConfigurationResponse struct {
+	// ConfigurationJWT is the SSIConfiguration as the claims of a JWT signed with the configured signer.
+	ConfigurationJWT keyaccess.JWT
+	// ETag changes whenever the bundled resources do.
+	ETag string
+}
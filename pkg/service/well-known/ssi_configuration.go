@@ -0,0 +1,205 @@
+package wellknown
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/tbd54566975/ssi-service/config"
+	"github.com/tbd54566975/ssi-service/internal/keyaccess"
+	"github.com/tbd54566975/ssi-service/pkg/service/did"
+	svcframework "github.com/tbd54566975/ssi-service/pkg/service/framework"
+	"github.com/tbd54566975/ssi-service/pkg/service/keystore"
+	"github.com/tbd54566975/ssi-service/pkg/service/schema"
+)
+
+// ErrSSIConfigurationDisabled is returned when the SSI configuration is requested, but no signer is configured.
+var ErrSSIConfigurationDisabled = errors.New("the ssi configuration is not enabled")
+
+// SSIConfigurationService builds the SSI configuration, a signed bundle of what verifiers need to verify the
+// credentials of the service, so that they can fetch it periodically instead of making a request per resource.
+type SSIConfigurationService struct {
+	config config.SSIConfigurationServiceConfig
+	maxAge time.Duration
+
+	// external dependencies
+	did      *did.Service
+	schema   *schema.Service
+	keyStore *keystore.Service
+
+	// signed is the last bundle signed, which is served until the resources it bundles change
+	signed *signedSSIConfiguration
+}
+
+type signedSSIConfiguration struct {
+	mu               sync.Mutex
+	etag             string
+	configurationJWT keyaccess.JWT
+}
+
+func NewSSIConfigurationService(config config.SSIConfigurationServiceConfig, didService *did.Service, schemaService *schema.Service, keyStore *keystore.Service) (*SSIConfigurationService, error) {
+	if didService == nil {
+		return nil, errors.New("did service cannot be nil")
+	}
+	if schemaService == nil {
+		return nil, errors.New("schema service cannot be nil")
+	}
+	if keyStore == nil {
+		return nil, errors.New("key store service cannot be nil")
+	}
+	if config.Signer != "" && !strings.Contains(config.Signer, "#") {
+		return nil, errors.Errorf("ssi configuration signer<%s> is not a fully qualified verification method", config.Signer)
+	}
+	var maxAge time.Duration
+	if config.MaxAge != "" {
+		var err error
+		if maxAge, err = time.ParseDuration(config.MaxAge); err != nil {
+			return nil, errors.Wrapf(err, "parsing ssi configuration max age<%s>", config.MaxAge)
+		}
+	}
+	return &SSIConfigurationService{
+		config:   config,
+		maxAge:   maxAge,
+		did:      didService,
+		schema:   schemaService,
+		keyStore: keyStore,
+		signed:   new(signedSSIConfiguration),
+	}, nil
+}
+
+func (s SSIConfigurationService) Type() svcframework.Type {
+	return svcframework.SSIConfiguration
+}
+
+func (s SSIConfigurationService) Status() svcframework.Status {
+	return svcframework.Status{
+		Status: svcframework.StatusReady,
+	}
+}
+
+var _ svcframework.Service = (*SSIConfigurationService)(nil)
+
+// MaxAge is how long verifiers may cache the SSI configuration before revalidating it.
+func (s SSIConfigurationService) MaxAge() time.Duration {
+	return s.maxAge
+}
+
+// GetSSIConfiguration returns the SSI configuration signed with the configured signer, along with its ETag. The bundle
+// is built from the current DIDs, keys, and schemas on every request, but only signed again when it changed, so that
+// its ETag and signature stay the same for as long as the resources it bundles do.
+func (s SSIConfigurationService) GetSSIConfiguration(ctx context.Context) (*GetSSIConfigurationResponse, error) {
+	if s.config.Signer == "" {
+		return nil, ErrSSIConfigurationDisabled
+	}
+
+	bundle, err := s.bundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundleBytes, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling ssi configuration")
+	}
+	digest := sha256.Sum256(bundleBytes)
+	etag := hex.EncodeToString(digest[:])
+
+	s.signed.mu.Lock()
+	defer s.signed.mu.Unlock()
+	if s.signed.etag != etag {
+		configurationJWT, err := s.keyStore.Sign(ctx, s.config.Signer, bundle)
+		if err != nil {
+			return nil, errors.Wrap(err, "signing ssi configuration")
+		}
+		s.signed.etag = etag
+		s.signed.configurationJWT = *configurationJWT
+	}
+	return &GetSSIConfigurationResponse{ConfigurationJWT: s.signed.configurationJWT, ETag: s.signed.etag}, nil
+}
+
+// bundle returns the SSI configuration of the current resources, in a stable order.
+func (s SSIConfigurationService) bundle(ctx context.Context) (*SSIConfiguration, error) {
+	issuers, err := s.issuers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := s.schema.ListSchemas(ctx, schema.ListSchemasRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing schemas")
+	}
+	summaries := make([]SchemaSummary, 0, len(schemas.Schemas))
+	for _, gotSchema := range schemas.Schemas {
+		summary := SchemaSummary{ID: gotSchema.ID, Type: string(gotSchema.Type), Version: gotSchema.Version}
+		// credential schemas are only stored signed, so resolving is needed to get to their name
+		if jsonSchema, _, err := s.schema.Resolve(ctx, gotSchema.ID); err == nil {
+			summary.Name = jsonSchema.Name()
+		} else {
+			logrus.WithError(err).Warnf("leaving the name of schema<%s> out of the ssi configuration", gotSchema.ID)
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+
+	return &SSIConfiguration{
+		Issuers:            issuers,
+		Schemas:            summaries,
+		StatusListEndpoint: config.GetStatusBase(),
+	}, nil
+}
+
+// issuers returns the DIDs controlled by the service, with the public keys of their keys in the keystore which are
+// not revoked. DIDs without such keys cannot issue, and are left out.
+func (s SSIConfigurationService) issuers(ctx context.Context) ([]IssuerKeys, error) {
+	var dids []string
+	for _, method := range s.did.GetSupportedMethods().Methods {
+		listed, err := s.did.ListDIDsByMethod(ctx, did.ListDIDsRequest{Method: method})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing DIDs for method<%s>", method)
+		}
+		for _, doc := range listed.DIDs {
+			dids = append(dids, doc.ID)
+		}
+	}
+	if len(dids) == 0 {
+		return nil, nil
+	}
+
+	keys, err := s.keyStore.ListKeysByController(ctx, dids)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing keys of DIDs")
+	}
+	byController := make(map[string]*IssuerKeys)
+	for _, key := range keys {
+		if key.Revoked {
+			continue
+		}
+		details, err := s.keyStore.GetKeyDetails(ctx, keystore.GetKeyDetailsRequest{ID: key.ID})
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting public key<%s>", key.ID)
+		}
+		publicKey := details.PublicKeyJWK
+		publicKey.KID = key.ID
+		issuer, ok := byController[key.Controller]
+		if !ok {
+			issuer = &IssuerKeys{ID: key.Controller}
+			byController[key.Controller] = issuer
+		}
+		issuer.Keys = append(issuer.Keys, publicKey)
+	}
+
+	issuers := make([]IssuerKeys, 0, len(byController))
+	for _, issuer := range byController {
+		sort.Slice(issuer.Keys, func(i, j int) bool { return issuer.Keys[i].KID < issuer.Keys[j].KID })
+		issuers = append(issuers, *issuer)
+	}
+	sort.Slice(issuers, func(i, j int) bool { return issuers[i].ID < issuers[j].ID })
+	return issuers, nil
+}
@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	sdkutil "github.com/TBD54566975/ssi-sdk/util"
@@ -27,13 +30,34 @@ const (
 
 type BoltDB struct {
 	db *bolt.DB
+
+	// writes is held for writing by compaction, which pauses writes while live data is copied to a fresh file
+	writes sync.RWMutex
+	// swap is held for writing while the compacted file replaces the open one, which pauses all operations
+	swap sync.RWMutex
+}
+
+// view runs a read-only transaction, which cannot overlap with a compacted file being swapped in.
+func (b *BoltDB) view(fn func(tx *bolt.Tx) error) error {
+	b.swap.RLock()
+	defer b.swap.RUnlock()
+	return b.db.View(fn)
+}
+
+// update runs a read-write transaction, which cannot overlap with a compaction.
+func (b *BoltDB) update(fn func(tx *bolt.Tx) error) error {
+	b.writes.RLock()
+	defer b.writes.RUnlock()
+	b.swap.RLock()
+	defer b.swap.RUnlock()
+	return b.db.Update(fn)
 }
 
 func (b *BoltDB) ReadPage(_ context.Context, namespace string, pageToken string, pageSize int) (map[string][]byte, string, error) {
 	result := make(map[string][]byte)
 	var nextCursorToReturn []byte
 
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			logrus.Warnf("namespace<%s> does not exist", namespace)
@@ -55,7 +79,7 @@ func (b *BoltDB) ReadPage(_ context.Context, namespace string, pageToken string,
 				break
 			}
 
-			result[string(k)] = v
+			result[string(k)] = bytes.Clone(v)
 
 			k, v = cursor.Next()
 			nextCursorToReturn = k
@@ -105,11 +129,15 @@ func processBoltOptions(filePath string, opts ...Option) (string, error) {
 
 // URI return filepath of boltDB,
 func (b *BoltDB) URI() string {
+	b.swap.RLock()
+	defer b.swap.RUnlock()
 	return b.db.Path()
 }
 
 // IsOpen return if db was opened
 func (b *BoltDB) IsOpen() bool {
+	b.swap.RLock()
+	defer b.swap.RUnlock()
 	if b.db == nil {
 		return false
 	}
@@ -121,6 +149,8 @@ func (b *BoltDB) Type() Type {
 }
 
 func (b *BoltDB) Close() error {
+	b.swap.Lock()
+	defer b.swap.Unlock()
 	return b.db.Close()
 }
 
@@ -132,13 +162,13 @@ func (b *BoltDB) Exists(_ context.Context, namespace, key string) (bool, error)
 	exists := true
 	var result []byte
 
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			exists = false
 			return nil
 		}
-		result = bucket.Get([]byte(key))
+		result = bytes.Clone(bucket.Get([]byte(key)))
 		return nil
 	})
 
@@ -154,10 +184,37 @@ func (btx *boltTx) Write(_ context.Context, namespace, key string, value []byte)
 	return writeFunc(namespace, key, value)(btx.tx)
 }
 
-// Execute runs the provided function within a transaction. Any failure during execution results in a rollback.
+var _ TxReader = (*boltTx)(nil)
+
+func (btx *boltTx) Read(_ context.Context, namespace, key string) ([]byte, error) {
+	bucket := btx.tx.Bucket([]byte(namespace))
+	if bucket == nil {
+		return nil, nil
+	}
+	return bytes.Clone(bucket.Get([]byte(key))), nil
+}
+
+func (btx *boltTx) Delete(_ context.Context, namespace, key string) error {
+	bucket := btx.tx.Bucket([]byte(namespace))
+	if bucket == nil {
+		return sdkutil.LoggingNewErrorf("namespace<%s> does not exist", namespace)
+	}
+	return bucket.Delete([]byte(key))
+}
+
+// Execute runs the provided function within a transaction. Any failure during execution results in a rollback, as
+// does the context being done, in which case an AbortedError is returned.
 // It is recommended to not open transactions within businessLogicFunc, as there are situation in which the interplay
 // between transactions may cause deadlocks.
 func (b *BoltDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, _ []WatchKey) (any, error) {
+	b.writes.RLock()
+	defer b.writes.RUnlock()
+	b.swap.RLock()
+	defer b.swap.RUnlock()
+	if err := ctx.Err(); err != nil {
+		return nil, AbortedError{Cause: err}
+	}
+
 	t, err := b.db.Begin(true)
 	if err != nil {
 		return nil, errors.Wrap(err, "beginning transaction")
@@ -174,13 +231,16 @@ func (b *BoltDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFun
 		}
 	}()
 
-	// If an error is returned from the function then rollback and return error.
+	// If an error is returned from the function, or the context is done, then rollback and return error.
 	result, err := businessLogicFunc(ctx, &bTx)
-	if err != nil {
+	if ctxErr := ctx.Err(); err != nil || ctxErr != nil {
 		if rollbackErr := t.Rollback(); rollbackErr != nil {
 			logrus.Errorf("problem rolling back %s", rollbackErr)
 			return nil, errors.Wrap(rollbackErr, "rolling back transaction")
 		}
+		if ctxErr != nil {
+			return nil, AbortedError{Cause: ctxErr}
+		}
 		return nil, errors.Wrap(err, "executing business logic func")
 	}
 
@@ -191,7 +251,7 @@ func (b *BoltDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFun
 }
 
 func (b *BoltDB) Write(_ context.Context, namespace string, key string, value []byte) error {
-	return b.db.Update(writeFunc(namespace, key, value))
+	return b.update(writeFunc(namespace, key, value))
 }
 
 func writeFunc(namespace string, key string, value []byte) func(tx *bolt.Tx) error {
@@ -209,7 +269,7 @@ func (b *BoltDB) WriteMany(_ context.Context, namespaces, keys []string, values
 		return errors.New("namespaces, keys, and values, are not of equal length")
 	}
 
-	return b.db.Update(func(tx *bolt.Tx) error {
+	return b.update(func(tx *bolt.Tx) error {
 		for i := range namespaces {
 			bucket, err := tx.CreateBucketIfNotExists([]byte(namespaces[i]))
 			if err != nil {
@@ -225,13 +285,13 @@ func (b *BoltDB) WriteMany(_ context.Context, namespaces, keys []string, values
 
 func (b *BoltDB) Read(_ context.Context, namespace, key string) ([]byte, error) {
 	var result []byte
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			logrus.Warnf("namespace<%s> does not exist", namespace)
 			return nil
 		}
-		result = bucket.Get([]byte(key))
+		result = bytes.Clone(bucket.Get([]byte(key)))
 		return nil
 	})
 	return result, err
@@ -240,7 +300,7 @@ func (b *BoltDB) Read(_ context.Context, namespace, key string) ([]byte, error)
 // ReadPrefix does a prefix query within a namespace.
 func (b *BoltDB) ReadPrefix(_ context.Context, namespace, prefix string) (map[string][]byte, error) {
 	result := make(map[string][]byte)
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			logrus.Warnf("namespace<%s> does not exist", namespace)
@@ -249,7 +309,7 @@ func (b *BoltDB) ReadPrefix(_ context.Context, namespace, prefix string) (map[st
 		cursor := bucket.Cursor()
 		prefix := []byte(prefix)
 		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
-			result[string(k)] = v
+			result[string(k)] = bytes.Clone(v)
 		}
 		return nil
 	})
@@ -258,7 +318,7 @@ func (b *BoltDB) ReadPrefix(_ context.Context, namespace, prefix string) (map[st
 
 func (b *BoltDB) ReadAll(_ context.Context, namespace string) (map[string][]byte, error) {
 	result := make(map[string][]byte)
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			logrus.Warnf("namespace<%s> does not exist", namespace)
@@ -266,7 +326,7 @@ func (b *BoltDB) ReadAll(_ context.Context, namespace string) (map[string][]byte
 		}
 		cursor := bucket.Cursor()
 		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			result[string(k)] = v
+			result[string(k)] = bytes.Clone(v)
 		}
 		return nil
 	})
@@ -275,7 +335,7 @@ func (b *BoltDB) ReadAll(_ context.Context, namespace string) (map[string][]byte
 
 func (b *BoltDB) ReadAllKeys(_ context.Context, namespace string) ([]string, error) {
 	var result []string
-	err := b.db.View(func(tx *bolt.Tx) error {
+	err := b.view(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			logrus.Warnf("namespace<%s> does not exist", namespace)
@@ -291,7 +351,7 @@ func (b *BoltDB) ReadAllKeys(_ context.Context, namespace string) ([]string, err
 }
 
 func (b *BoltDB) Delete(_ context.Context, namespace, key string) error {
-	return b.db.Update(func(tx *bolt.Tx) error {
+	return b.update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket([]byte(namespace))
 		if bucket == nil {
 			return sdkutil.LoggingNewErrorf("namespace<%s> does not exist", namespace)
@@ -301,7 +361,7 @@ func (b *BoltDB) Delete(_ context.Context, namespace, key string) error {
 }
 
 func (b *BoltDB) DeleteNamespace(_ context.Context, namespace string) error {
-	return b.db.Update(func(tx *bolt.Tx) error {
+	return b.update(func(tx *bolt.Tx) error {
 		if err := tx.DeleteBucket([]byte(namespace)); err != nil {
 			return sdkutil.LoggingErrorMsgf(err, "deleting namespace<%s>", namespace)
 		}
@@ -309,6 +369,137 @@ func (b *BoltDB) DeleteNamespace(_ context.Context, namespace string) error {
 	})
 }
 
+// compactionTxMaxSize bounds how many bytes are copied in each transaction during compaction.
+const compactionTxMaxSize = 64 * 1024 * 1024
+
+var (
+	_ StatsReporter = (*BoltDB)(nil)
+	_ Compactor     = (*BoltDB)(nil)
+)
+
+// Stats reports the size of the bolt file, an estimate of the space compaction would reclaim, from the free pages left
+// by deleted data, and the number of keys of each bucket along with the bytes of their keys and values. It runs in a
+// read transaction, which does not block writes.
+func (b *BoltDB) Stats(_ context.Context) (*Stats, error) {
+	b.swap.RLock()
+	defer b.swap.RUnlock()
+
+	info, err := os.Stat(b.db.Path())
+	if err != nil {
+		return nil, errors.Wrap(err, "reading bolt file size")
+	}
+	stats := Stats{Type: Bolt, SizeBytes: info.Size(), Namespaces: make(map[string]NamespaceStats)}
+	err = b.db.View(func(tx *bolt.Tx) error {
+		if err := tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			var namespaceStats NamespaceStats
+			if err := bucket.ForEach(func(k, v []byte) error {
+				namespaceStats.KeyCount++
+				namespaceStats.SizeBytes += int64(len(k) + len(v))
+				return nil
+			}); err != nil {
+				return err
+			}
+			stats.Namespaces[string(name)] = namespaceStats
+			return nil
+		}); err != nil {
+			return err
+		}
+		freeBytes := int64(b.db.Stats().FreePageN * b.db.Info().PageSize)
+		if inUse := tx.Size() - freeBytes; stats.SizeBytes > inUse {
+			stats.ReclaimableBytes = stats.SizeBytes - inUse
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "reading bolt stats")
+	}
+	return &stats, nil
+}
+
+// Compact copies the live data of the bolt file to a fresh file, which then atomically replaces it. Writes are paused
+// while the data is copied, and reads only while the files are swapped. The original file is kept until the compacted
+// one has been fully written and synced, and replaces it with a rename, so a crash never loses data: at worst, a
+// leftover compacted file is removed by the next compaction.
+func (b *BoltDB) Compact(ctx context.Context) (*CompactionResult, error) {
+	b.writes.Lock()
+	defer b.writes.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	path := b.URI()
+	before, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading bolt file size")
+	}
+
+	compactedPath := path + ".compact"
+	if err = os.Remove(compactedPath); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "removing leftover compacted file")
+	}
+	compacted, err := bolt.Open(compactedPath, 0600, &bolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "opening compacted file")
+	}
+	if err = bolt.Compact(compacted, b.db, compactionTxMaxSize); err != nil {
+		_ = compacted.Close()
+		_ = os.Remove(compactedPath)
+		return nil, errors.Wrap(err, "copying live data")
+	}
+	if err = compacted.Close(); err != nil {
+		_ = os.Remove(compactedPath)
+		return nil, errors.Wrap(err, "closing compacted file")
+	}
+
+	if err = b.swapIn(compactedPath, path); err != nil {
+		return nil, err
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading compacted bolt file size")
+	}
+
+	result := CompactionResult{SizeBeforeBytes: before.Size(), SizeAfterBytes: after.Size(), Duration: time.Since(start)}
+	logrus.Infof("compacted bolt file %s from %d to %d bytes in %s", path, result.SizeBeforeBytes, result.SizeAfterBytes, result.Duration)
+	return &result, nil
+}
+
+// swapIn replaces the open bolt file with the compacted one, and opens it. When the compacted file cannot replace the
+// original one, the original one is opened again.
+func (b *BoltDB) swapIn(compactedPath, path string) error {
+	b.swap.Lock()
+	defer b.swap.Unlock()
+
+	if err := b.db.Close(); err != nil {
+		_ = os.Remove(compactedPath)
+		return errors.Wrap(err, "closing bolt file")
+	}
+	swapErr := os.Rename(compactedPath, path)
+	if swapErr == nil {
+		swapErr = syncDir(filepath.Dir(path))
+	} else {
+		_ = os.Remove(compactedPath)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return errors.Wrap(err, "reopening bolt file")
+	}
+	b.db = db
+	return errors.Wrap(swapErr, "replacing bolt file with compacted file")
+}
+
+// syncDir makes a rename within the directory durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 // UpdaterWithMap is a json map based Updater implementation. The key/values from the map are used to update the
 // unmarshalled JSON representation of the stored data.
 type UpdaterWithMap struct {
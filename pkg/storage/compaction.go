@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotSupported is returned when the storage provider does not support an optional operation.
+var ErrNotSupported = errors.New("not supported by the storage provider")
+
+// Stats describes the space used by a storage provider, and the objects stored in each of its namespaces.
+type Stats struct {
+	Type Type `json:"type"`
+	// SizeBytes is the size of the storage on disk, for storage providers which store a file.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// ReclaimableBytes estimates how much of SizeBytes compaction would reclaim, such as pages of deleted data.
+	ReclaimableBytes int64 `json:"reclaimableBytes,omitempty"`
+	// Namespaces describes the objects stored in each namespace.
+	Namespaces map[string]NamespaceStats `json:"namespaces"`
+}
+
+// NamespaceStats describes the objects stored in a namespace.
+type NamespaceStats struct {
+	KeyCount int `json:"keyCount"`
+	// SizeBytes approximates the space used by the namespace, as the total size of its keys and values.
+	SizeBytes int64 `json:"sizeBytes"`
+}
+
+// StatsReporter is implemented by storage providers which can report the space they use. It is optional, so callers
+// go through GetStats, which returns ErrNotSupported for the other providers.
+type StatsReporter interface {
+	Stats(ctx context.Context) (*Stats, error)
+}
+
+// CompactionResult describes the outcome of a compaction.
+type CompactionResult struct {
+	SizeBeforeBytes int64         `json:"sizeBeforeBytes"`
+	SizeAfterBytes  int64         `json:"sizeAfterBytes"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// Compactor is implemented by storage providers which do not reclaim the space of deleted data by themselves.
+type Compactor interface {
+	Compact(ctx context.Context) (*CompactionResult, error)
+}
+
+// unwrapper is implemented by storage which wraps another one, such as the EncryptedWrapper.
+type unwrapper interface {
+	Unwrap() ServiceStorage
+}
+
+// GetStats returns the stats of the storage, or of the storage it wraps. It returns ErrNotSupported when the storage
+// provider cannot report them.
+func GetStats(ctx context.Context, s ServiceStorage) (*Stats, error) {
+	for {
+		if reporter, ok := s.(StatsReporter); ok {
+			return reporter.Stats(ctx)
+		}
+		wrapper, ok := s.(unwrapper)
+		if !ok {
+			return nil, ErrNotSupported
+		}
+		s = wrapper.Unwrap()
+	}
+}
+
+// Compact compacts the storage, or the storage it wraps. It returns ErrNotSupported when the storage provider does not
+// need compaction.
+func Compact(ctx context.Context, s ServiceStorage) (*CompactionResult, error) {
+	for {
+		if compactor, ok := s.(Compactor); ok {
+			return compactor.Compact(ctx)
+		}
+		wrapper, ok := s.(unwrapper)
+		if !ok {
+			return nil, ErrNotSupported
+		}
+		s = wrapper.Unwrap()
+	}
+}
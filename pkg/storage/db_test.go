@@ -92,7 +92,7 @@ func setupPostgresDB(t *testing.T) *SQLDB {
 	return s.(*SQLDB)
 }
 
-func setupRedisDB(t *testing.T) *RedisDB {
+func setupRedisDB(t *testing.T, opts ...Option) *RedisDB {
 	server := miniredis.RunT(t)
 	options := []Option{
 		{
@@ -104,7 +104,7 @@ func setupRedisDB(t *testing.T) *RedisDB {
 			Option: "test-password",
 		},
 	}
-	db, err := NewStorage(Redis, options...)
+	db, err := NewStorage(Redis, append(options, opts...)...)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, db)
 
@@ -445,6 +445,38 @@ func TestDBExists_FalseAfterDeletingKey(t *testing.T) {
 	}
 }
 
+func TestBoltReadAfterTransaction(t *testing.T) {
+	db, err := NewStorage(Bolt, Option{
+		ID:     BoltDBFilePathOption,
+		Option: filepath.Join(t.TempDir(), "read.db"),
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// a value too large for the namespace's bucket to be inlined, which bolt would copy, is read from its memory map
+	namespace := "read"
+	value := make([]byte, 4096)
+	_, err = rand.Read(value)
+	require.NoError(t, err)
+	require.NoError(t, db.Write(ctx, namespace, "key", value))
+
+	read, err := db.Read(ctx, namespace, "key")
+	require.NoError(t, err)
+	prefixed, err := db.ReadPrefix(ctx, namespace, "k")
+	require.NoError(t, err)
+	all, err := db.ReadAll(ctx, namespace)
+	require.NoError(t, err)
+	page, _, err := db.ReadPage(ctx, namespace, "", -1)
+	require.NoError(t, err)
+
+	// closing the store unmaps its memory, which values that were not copied out of their transaction point into
+	require.NoError(t, db.Close())
+	assert.Equal(t, value, read)
+	assert.Equal(t, value, prefixed["key"])
+	assert.Equal(t, value, all["key"])
+	assert.Equal(t, value, page["key"])
+}
+
 type testStruct struct {
 	Status int    `json:"status"`
 	Reason string `json:"reason"`
@@ -542,6 +574,72 @@ func TestDB_Execute(t *testing.T) {
 	}
 }
 
+func TestDB_ReadTx(t *testing.T) {
+	for _, dbImpl := range getDBImplementations(t) {
+		db := dbImpl
+		require.NoError(t, db.Write(context.Background(), "hello", "my_key", []byte(`some bytes`)))
+		watchKeys := []WatchKey{{Namespace: "hello", Key: "my_key"}}
+		result, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+			_, ok := tx.(TxReader)
+			assert.True(t, ok, db.Type())
+			value, err := ReadTx(ctx, db, tx, "hello", "my_key")
+			if err != nil {
+				return nil, err
+			}
+			missing, err := ReadTx(ctx, db, tx, "hello", "missing_key")
+			if err != nil {
+				return nil, err
+			}
+			assert.Empty(t, missing)
+			return value, tx.Write(ctx, "hello", "my_key", append(value, []byte(` and more`)...))
+		}, watchKeys)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`some bytes`), result)
+		value, err := db.Read(context.Background(), "hello", "my_key")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`some bytes and more`), value)
+	}
+}
+
+func TestRedisExecuteConflict(t *testing.T) {
+	watchKey := WatchKey{Namespace: "hello", Key: "my_key"}
+	// the watched key is written concurrently on the first attempt of the transaction only
+	conflictingExecute := func(db *RedisDB) (int, error) {
+		attempts := 0
+		_, err := db.Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+			attempts++
+			if attempts == 1 {
+				if err := db.Write(ctx, watchKey.Namespace, watchKey.Key, []byte(`concurrent bytes`)); err != nil {
+					return nil, err
+				}
+			}
+			return nil, tx.Write(ctx, watchKey.Namespace, watchKey.Key, []byte(`some bytes`))
+		}, []WatchKey{watchKey})
+		return attempts, err
+	}
+
+	attempts, err := conflictingExecute(setupRedisDB(t))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts, err = conflictingExecute(setupRedisDB(t, Option{ID: RedisConflictRetriesOption, Option: false}))
+	assert.ErrorIs(t, err, ErrConflict)
+	var conflictErr ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, []WatchKey{watchKey}, conflictErr.WatchKeys)
+	assert.Equal(t, 1, attempts)
+
+	// failures other than conflicts are not retried
+	attempts = 0
+	_, err = setupRedisDB(t).Execute(context.Background(), func(ctx context.Context, tx Tx) (any, error) {
+		attempts++
+		return nil, fmt.Errorf("failing")
+	}, []WatchKey{watchKey})
+	assert.ErrorContains(t, err, "failing")
+	assert.NotErrorIs(t, err, ErrConflict)
+	assert.Equal(t, 1, attempts)
+}
+
 func TestDB_UpdatedSubmissionAndOperationTxFn(t *testing.T) {
 	for _, dbImpl := range getDBImplementations(t) {
 		db := dbImpl
@@ -657,3 +755,55 @@ func TestDB_UpdatedSubmissionAndOperationTxFn(t *testing.T) {
 		}
 	}
 }
+
+func TestBoltCompaction(t *testing.T) {
+	db := setupBoltDB(t)
+	ctx := context.Background()
+
+	// populate the store, then delete most of it, leaving free pages behind
+	value := make([]byte, 1024)
+	_, err := rand.Read(value)
+	require.NoError(t, err)
+	namespace := "compaction"
+	for i := 0; i < 2000; i++ {
+		require.NoError(t, db.Write(ctx, namespace, fmt.Sprintf("key-%d", i), value))
+	}
+	for i := 10; i < 2000; i++ {
+		require.NoError(t, db.Delete(ctx, namespace, fmt.Sprintf("key-%d", i)))
+	}
+
+	stats, err := GetStats(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, Bolt, stats.Type)
+	assert.Equal(t, 10, stats.Namespaces[namespace].KeyCount)
+	assert.Positive(t, stats.ReclaimableBytes)
+
+	result, err := Compact(ctx, db)
+	require.NoError(t, err)
+	assert.Less(t, result.SizeAfterBytes, result.SizeBeforeBytes)
+	assert.NoFileExists(t, db.URI()+".compact")
+
+	// the remaining data is intact, and the store can still be written to
+	remaining, err := db.ReadAll(ctx, namespace)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 10)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, value, remaining[fmt.Sprintf("key-%d", i)])
+	}
+	require.NoError(t, db.Write(ctx, namespace, "after", value))
+
+	stats, err = GetStats(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, 11, stats.Namespaces[namespace].KeyCount)
+	assert.Less(t, stats.SizeBytes, result.SizeBeforeBytes)
+
+	// the encrypted wrapper compacts the bolt store it wraps
+	key := make([]byte, 32)
+	encrypted := NewEncryptedWrapper(db, encryption.NewXChaCha20Poly1305EncrypterWithKey(key), encryption.NewXChaCha20Poly1305EncrypterWithKey(key))
+	_, err = Compact(ctx, encrypted)
+	assert.NoError(t, err)
+
+	// other providers don't need compaction
+	_, err = Compact(ctx, setupRedisDB(t))
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
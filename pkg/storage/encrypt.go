@@ -22,6 +22,11 @@ func NewEncryptedWrapper(s ServiceStorage, encrypter encryption.Encrypter, decry
 	}
 }
 
+// Unwrap returns the storage the values are encrypted for.
+func (e EncryptedWrapper) Unwrap() ServiceStorage {
+	return e.s
+}
+
 func (e EncryptedWrapper) Init(opts ...Option) error {
 	return e.s.Init(opts...)
 }
@@ -131,21 +136,39 @@ func (e EncryptedWrapper) DeleteNamespace(ctx context.Context, namespace string)
 }
 
 type encryptedTx struct {
-	tx        Tx
-	encrypter encryption.Encrypter
+	tx      Tx
+	storage EncryptedWrapper
+}
+
+var _ TxReader = (*encryptedTx)(nil)
+
+func (m encryptedTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	storedBytes, err := ReadTx(ctx, m.storage.s, m.tx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	decryptedData, err := m.storage.decrypter.Decrypt(ctx, storedBytes, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting data")
+	}
+	return decryptedData, nil
 }
 
 func (m encryptedTx) Write(ctx context.Context, namespace, key string, value []byte) error {
-	encryptedData, err := m.encrypter.Encrypt(ctx, value, nil)
+	encryptedData, err := m.storage.encrypter.Encrypt(ctx, value, nil)
 	if err != nil {
 		return errors.Wrap(err, "encrypting data")
 	}
 	return m.tx.Write(ctx, namespace, key, encryptedData)
 }
 
+func (m encryptedTx) Delete(ctx context.Context, namespace, key string) error {
+	return m.tx.Delete(ctx, namespace, key)
+}
+
 func (e EncryptedWrapper) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, watchKeys []WatchKey) (any, error) {
 	return e.s.Execute(ctx, func(ctx context.Context, tx Tx) (any, error) {
-		return businessLogicFunc(ctx, encryptedTx{tx: tx, encrypter: e.encrypter})
+		return businessLogicFunc(ctx, encryptedTx{tx: tx, storage: e})
 	}, watchKeys)
 }
 
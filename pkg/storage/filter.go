@@ -2,6 +2,7 @@ package storage
 
 import (
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -55,8 +56,35 @@ func simpleEquals(lhs ref.Val, rhs ref.Val) ref.Val {
 	return lhs.Equal(rhs)
 }
 
+func logicalAnd(lhs ref.Val, rhs ref.Val) ref.Val {
+	return types.Bool(lhs == types.True && rhs == types.True)
+}
+
+func logicalOr(lhs ref.Val, rhs ref.Val) ref.Val {
+	return types.Bool(lhs == types.True || rhs == types.True)
+}
+
+func logicalNot(val ref.Val) ref.Val {
+	return types.Bool(val != types.True)
+}
+
 func newCelEnv() (*cel.Env, error) {
 	return cel.NewEnv(
+		cel.Function(filtering.FunctionAnd,
+			cel.Overload(filtering.FunctionOverloadAndBool,
+				[]*cel.Type{cel.BoolType, cel.BoolType},
+				cel.BoolType,
+				cel.BinaryBinding(logicalAnd))),
+		cel.Function(filtering.FunctionOr,
+			cel.Overload(filtering.FunctionOverloadOrBool,
+				[]*cel.Type{cel.BoolType, cel.BoolType},
+				cel.BoolType,
+				cel.BinaryBinding(logicalOr))),
+		cel.Function(filtering.FunctionNot,
+			cel.Overload(filtering.FunctionOverloadNotBool,
+				[]*cel.Type{cel.BoolType},
+				cel.BoolType,
+				cel.UnaryBinding(logicalNot))),
 		cel.Function("=",
 			cel.Overload("=_bool",
 				[]*cel.Type{cel.BoolType, cel.BoolType},
@@ -65,5 +93,9 @@ func newCelEnv() (*cel.Env, error) {
 			cel.Overload("=_string",
 				[]*cel.Type{cel.StringType, cel.StringType},
 				cel.BoolType,
+				cel.BinaryBinding(simpleEquals)),
+			cel.Overload("=_int",
+				[]*cel.Type{cel.IntType, cel.IntType},
+				cel.BoolType,
 				cel.BinaryBinding(simpleEquals))))
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -24,10 +25,15 @@ const (
 	RedisScanBatchSize           = 1000
 	MaxElapsedTime               = 6 * time.Second
 	RedisAddressOption OptionKey = "redis-address-option"
+	// RedisConflictRetriesOption turns off retrying transactions which conflict with a concurrent update when false, so
+	// that the conflict is returned right away. Conflicts are retried for up to MaxElapsedTime by default.
+	RedisConflictRetriesOption OptionKey = "redis-conflict-retries-option"
 )
 
 type RedisDB struct {
 	db *goredislib.Client
+	// retryConflicts is true when transactions conflicting with a concurrent update are retried
+	retryConflicts bool
 }
 
 func (b *RedisDB) ReadPage(ctx context.Context, namespace string, pageToken string, pageSize int) (map[string][]byte, string, error) {
@@ -51,10 +57,26 @@ func (b *RedisDB) ReadPage(ctx context.Context, namespace string, pageToken stri
 	return results, nextCursor, nil
 }
 
-var _ ServiceStorage = (*RedisDB)(nil)
+var (
+	_ ServiceStorage = (*RedisDB)(nil)
+	_ StatsReporter  = (*RedisDB)(nil)
+)
 
 type redisTx struct {
 	pipe goredislib.Pipeliner
+	// tx is the connection watching the keys of the transaction, which reads go through
+	tx *goredislib.Tx
+}
+
+var _ TxReader = (*redisTx)(nil)
+
+func (rtx *redisTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	res, err := rtx.tx.Get(ctx, getRedisKey(namespace, key)).Bytes()
+	// Nil reply returned by Redis when key does not exist.
+	if errors.Is(err, goredislib.Nil) {
+		return res, nil
+	}
+	return res, err
 }
 
 func (rtx *redisTx) Write(ctx context.Context, namespace, key string, value []byte) error {
@@ -62,11 +84,25 @@ func (rtx *redisTx) Write(ctx context.Context, namespace, key string, value []by
 	return rtx.pipe.Set(ctx, nameSpaceKey, value, 0).Err()
 }
 
+func (rtx *redisTx) Delete(ctx context.Context, namespace, key string) error {
+	nameSpaceKey := getRedisKey(namespace, key)
+	return rtx.pipe.Del(ctx, nameSpaceKey).Err()
+}
+
 func (b *RedisDB) Init(opts ...Option) error {
 	address, password, err := processRedisOptions(opts...)
 	if err != nil {
 		return errors.Wrap(err, "processing redis options")
 	}
+	retryConflicts := true
+	for _, opt := range opts {
+		if opt.ID != RedisConflictRetriesOption {
+			continue
+		}
+		if retryConflicts, err = strconv.ParseBool(fmt.Sprint(opt.Option)); err != nil {
+			return errors.Wrap(err, "redis conflict retries must be a boolean")
+		}
+	}
 	client := goredislib.NewClient(&goredislib.Options{
 		Addr:     address,
 		Password: password,
@@ -81,12 +117,13 @@ func (b *RedisDB) Init(opts ...Option) error {
 	}
 
 	b.db = client
+	b.retryConflicts = retryConflicts
 
 	return nil
 }
 
 func processRedisOptions(opts ...Option) (address, password string, err error) {
-	if len(opts) != 2 {
+	if len(opts) < 2 {
 		return "", "", errors.New("redis options must contain address and password")
 	}
 	for _, opt := range opts {
@@ -150,10 +187,14 @@ func (b *RedisDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFu
 	txf := func(tx *goredislib.Tx) error {
 		// Operation is commited only if the watched keys remain unchanged.
 		_, err := tx.TxPipelined(ctx, func(pipe goredislib.Pipeliner) error {
-			redisTx := redisTx{pipe}
+			redisTx := redisTx{pipe: pipe, tx: tx}
 			var err error
 
 			finalOutput, err = businessLogicFunc(ctx, &redisTx)
+			// the queued writes are discarded when the context is done
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return AbortedError{Cause: ctxErr}
+			}
 			if err != nil {
 				return err
 			}
@@ -171,11 +212,22 @@ func (b *RedisDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFu
 	expBackoff := backoff.NewExponentialBackOff()
 	expBackoff.MaxElapsedTime = MaxElapsedTime
 
+	// only conflicts are retried, since any other failure would fail again
 	err := backoff.Retry(func() error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return backoff.Permanent(AbortedError{Cause: ctxErr})
+		}
 		err := b.db.Watch(ctx, txf, watchKeysStr...)
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil && !errors.Is(err, ErrAborted) {
+			return backoff.Permanent(AbortedError{Cause: ctxErr})
+		}
 		if err != nil && errors.Is(err, goredislib.TxFailedErr) {
+			conflictErr := ConflictError{WatchKeys: watchKeys}
+			if !b.retryConflicts {
+				return backoff.Permanent(conflictErr)
+			}
 			logrus.Warn("Optimistic lock lost. Retrying..")
-			return err
+			return conflictErr
 		}
 		return backoff.Permanent(err)
 	}, expBackoff)
@@ -430,6 +482,45 @@ func txWithUpdater(ctx context.Context, namespace, key string, updater Updater,
 	return data, nil
 }
 
+// Stats scans every key, counting the keys and the bytes of the keys and values of each namespace. Since keys may
+// contain the separator, the namespace of a key is its part before the first separator, so namespaces which contain
+// the separator are reported under their first part. The size of the storage is not reported, as redis keeps it in
+// memory.
+func (b *RedisDB) Stats(ctx context.Context) (*Stats, error) {
+	stats := Stats{Type: Redis, Namespaces: make(map[string]NamespaceStats)}
+	var cursor uint64
+	for {
+		keys, nextCursor, err := b.db.Scan(ctx, cursor, "*", RedisScanBatchSize).Result()
+		if err != nil {
+			return nil, errors.Wrap(err, "scan error")
+		}
+		pipe := b.db.Pipeline()
+		lengths := make([]*goredislib.IntCmd, len(keys))
+		for i, key := range keys {
+			lengths[i] = pipe.StrLen(ctx, key)
+		}
+		if len(keys) > 0 {
+			if _, err = pipe.Exec(ctx); err != nil {
+				return nil, errors.Wrap(err, "getting value lengths")
+			}
+		}
+		for i, key := range keys {
+			namespace, _, found := strings.Cut(key, ":")
+			if !found {
+				continue
+			}
+			namespaceStats := stats.Namespaces[namespace]
+			namespaceStats.KeyCount++
+			namespaceStats.SizeBytes += int64(len(key)) + lengths[i].Val()
+			stats.Namespaces[namespace] = namespaceStats
+		}
+		if nextCursor == 0 {
+			return &stats, nil
+		}
+		cursor = nextCursor
+	}
+}
+
 func getRedisKey(namespace, key string) string {
 	return Join(namespace, key)
 }
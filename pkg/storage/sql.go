@@ -443,6 +443,17 @@ func (s *sqlTx) Write(ctx context.Context, namespace, key string, value []byte)
 	return write(ctx, s.tx, namespace, key, value)
 }
 
+var _ TxReader = (*sqlTx)(nil)
+
+func (s *sqlTx) Read(ctx context.Context, namespace, key string) ([]byte, error) {
+	return read(ctx, s.tx, namespace, key)
+}
+
+func (s *sqlTx) Delete(ctx context.Context, namespace, key string) error {
+	_, err := s.tx.ExecContext(ctx, "DELETE FROM key_values WHERE key = $1", Join(namespace, key))
+	return err
+}
+
 func (s *SQLDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc, _ []WatchKey) (any, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -458,6 +469,9 @@ func (s *SQLDB) Execute(ctx context.Context, businessLogicFunc BusinessLogicFunc
 	bTx := sqlTx{tx: tx}
 
 	result, err := businessLogicFunc(ctx, &bTx)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, AbortedError{Cause: ctxErr}
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "executing business logic func")
 	}
@@ -21,6 +21,64 @@ type WatchKey struct {
 
 type Tx interface {
 	Write(ctx context.Context, namespace, key string, value []byte) error
+	Delete(ctx context.Context, namespace, key string) error
+}
+
+// TxReader is implemented by the transactions of storage providers which can read within the transaction, seeing the
+// values it commits against, such as those of its watched keys. It is optional, so callers go through ReadTx.
+type TxReader interface {
+	Read(ctx context.Context, namespace, key string) ([]byte, error)
+}
+
+// ReadTx reads a value within the transaction when it can, or from the storage otherwise.
+func ReadTx(ctx context.Context, s ServiceStorage, tx Tx, namespace, key string) ([]byte, error) {
+	if reader, ok := tx.(TxReader); ok {
+		return reader.Read(ctx, namespace, key)
+	}
+	return s.Read(ctx, namespace, key)
+}
+
+// ErrConflict is returned by Execute when one of the watched keys was changed by a concurrent transaction, so that the
+// transaction was not committed. Unlike other failures, retrying the transaction may succeed.
+var ErrConflict = errors.New("conflicting concurrent update")
+
+// ConflictError is an ErrConflict, along with the keys watched by the transaction which was not committed.
+type ConflictError struct {
+	WatchKeys []WatchKey
+}
+
+func (e ConflictError) Error() string {
+	keys := make([]string, 0, len(e.WatchKeys))
+	for _, watchKey := range e.WatchKeys {
+		keys = append(keys, Join(watchKey.Namespace, watchKey.Key))
+	}
+	return fmt.Sprintf("%s of keys %v", ErrConflict, keys)
+}
+
+func (e ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ErrAborted is returned by Execute when its context is done before the transaction is committed, so that none of its
+// writes were committed.
+var ErrAborted = errors.New("transaction aborted")
+
+// AbortedError is an ErrAborted, along with the error of the context which aborted the transaction, either
+// context.Canceled or context.DeadlineExceeded.
+type AbortedError struct {
+	Cause error
+}
+
+func (e AbortedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAborted, e.Cause)
+}
+
+func (e AbortedError) Is(target error) bool {
+	return target == ErrAborted
+}
+
+func (e AbortedError) Unwrap() error {
+	return e.Cause
 }
 
 const (
@@ -44,10 +102,15 @@ type (
 
 // Option represents a single option that may be required for a storage provider
 type Option struct {
-	ID     OptionKey `json:"id,omitempty"`
+	ID     OptionKey `json:"id,omitempty" sensitive:"false"`
 	Option any       `json:"option,omitempty"`
 }
 
+// IsSensitive returns true when the option's value holds a secret, such as a password, which must never be displayed.
+func (o Option) IsSensitive() bool {
+	return o.ID == PasswordOption || o.ID == SQLConnectionString
+}
+
 // ServiceStorage describes the api for storage independent of DB providers
 type ServiceStorage interface {
 	Init(opts ...Option) error